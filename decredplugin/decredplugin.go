@@ -14,11 +14,15 @@ const (
 	CmdNewComment            = "newcomment"
 	CmdLikeComment           = "likecomment"
 	CmdCensorComment         = "censorcomment"
+	CmdEditComment           = "editcomment"
+	CmdDeleteComment         = "deletecomment"
+	CmdReactComment          = "reactcomment"
 	CmdGetComment            = "getcomment"
 	CmdGetComments           = "getcomments"
 	CmdProposalVotes         = "proposalvotes"
 	CmdCommentLikes          = "commentlikes"
 	CmdProposalCommentsLikes = "proposalcommentslikes"
+	CmdCommentReactions      = "commentreactions"
 	CmdInventory             = "inventory"
 	MDStreamAuthorizeVote    = 13 // Vote authorization by proposal author
 	MDStreamVoteBits         = 14 // Vote bits and mask
@@ -26,6 +30,10 @@ const (
 
 	VoteDurationMin = 2016 // Minimum vote duration (in blocks)
 	VoteDurationMax = 4032 // Maximum vote duration (in blocks)
+
+	// Comment reaction actions
+	ReactionActionAdd    = "add"    // Add a reaction to a comment
+	ReactionActionRemove = "remove" // Remove a reaction from a comment
 )
 
 // CastVote is a signed vote.
@@ -347,24 +355,36 @@ func DecodeVoteResultsReply(payload []byte) (*VoteResultsReply, error) {
 	return &v, nil
 }
 
+// CommentFile is a file that is attached to a comment.
+type CommentFile struct {
+	Name    string `json:"name"`    // Suggested filename
+	MIME    string `json:"mime"`    // Mime type
+	Digest  string `json:"digest"`  // SHA256 digest of unencoded payload
+	Payload string `json:"payload"` // File content, base64 encoded
+}
+
 // Comment is the structure that describes the full server side content.  It
 // includes server side meta-data as well. Note that the receipt is the server
 // side.
 type Comment struct {
 	// Data generated by client
-	Token     string `json:"token"`     // Censorship token
-	ParentID  string `json:"parentid"`  // Parent comment ID
-	Comment   string `json:"comment"`   // Comment
-	Signature string `json:"signature"` // Client Signature of Token+ParentID+Comment
-	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Token     string        `json:"token"`           // Censorship token
+	ParentID  string        `json:"parentid"`        // Parent comment ID
+	Comment   string        `json:"comment"`         // Comment
+	Signature string        `json:"signature"`       // Client Signature of Token+ParentID+Comment+file digests or Token+CommentID+Comment+file digests if edited
+	PublicKey string        `json:"publickey"`       // Pubkey used for Signature
+	Files     []CommentFile `json:"files,omitempty"` // Attached files
 
 	// Metadata generated by decred plugin
-	CommentID   string `json:"commentid"`   // Comment ID
-	Receipt     string `json:"receipt"`     // Server signature of the client Signature
-	Timestamp   int64  `json:"timestamp"`   // Received UNIX timestamp
-	TotalVotes  uint64 `json:"totalvotes"`  // Total number of up/down votes
-	ResultVotes int64  `json:"resultvotes"` // Vote score
-	Censored    bool   `json:"censored"`    // Has this comment been censored
+	CommentID    string `json:"commentid"`              // Comment ID
+	Receipt      string `json:"receipt"`                // Server signature of the client Signature
+	Timestamp    int64  `json:"timestamp"`              // Received UNIX timestamp
+	TotalVotes   uint64 `json:"totalvotes"`             // Total number of up/down votes
+	ResultVotes  int64  `json:"resultvotes"`            // Vote score
+	Censored     bool   `json:"censored"`               // Has this comment been censored
+	Edited       int64  `json:"edited"`                 // UNIX timestamp of last edit, 0 if never edited
+	CensorReason string `json:"censorreason,omitempty"` // Reason the comment was censored
+	Deleted      int64  `json:"deleted"`                // UNIX timestamp the author deleted the comment, 0 if not deleted
 }
 
 // EncodeComment encodes Comment into a JSON byte slice.
@@ -387,11 +407,12 @@ func DecodeComment(payload []byte) (*Comment, error) {
 // NewComment sends a comment from a user to a specific proposal.  Note that
 // the user is implied by the session.
 type NewComment struct {
-	Token     string `json:"token"`     // Censorship token
-	ParentID  string `json:"parentid"`  // Parent comment ID
-	Comment   string `json:"comment"`   // Comment
-	Signature string `json:"signature"` // Signature of Token+ParentID+Comment
-	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Token     string        `json:"token"`           // Censorship token
+	ParentID  string        `json:"parentid"`        // Parent comment ID
+	Comment   string        `json:"comment"`         // Comment
+	Signature string        `json:"signature"`       // Signature of Token+ParentID+Comment+file digests
+	PublicKey string        `json:"publickey"`       // Pubkey used for Signature
+	Files     []CommentFile `json:"files,omitempty"` // Attached files
 }
 
 // EncodeNewComment encodes NewComment into a JSON byte slice.
@@ -491,6 +512,61 @@ func DecodeLikeCommentReply(payload []byte) (*LikeCommentReply, error) {
 	return &lcr, nil
 }
 
+// ReactComment adds or removes a reaction of a user to a comment.  Reactions
+// are kept separate from the up and down votes of LikeComment.  A user either
+// has or does not have each reaction type on a comment; the reactions journal
+// is replayed in order to determine the current reactions.
+type ReactComment struct {
+	Token     string `json:"token"`     // Censorship token
+	CommentID string `json:"commentid"` // Comment ID
+	Reaction  string `json:"reaction"`  // Reaction type
+	Action    string `json:"action"`    // Add or remove
+	Signature string `json:"signature"` // Client Signature of Token+CommentID+Reaction+Action
+	PublicKey string `json:"publickey"` // Pubkey used for Signature
+
+	// Only used on disk
+	Receipt   string `json:"receipt,omitempty"`   // Signature of Signature
+	Timestamp int64  `json:"timestamp,omitempty"` // Received UNIX timestamp
+}
+
+// EncodeReactComment encodes ReactComment into a JSON byte slice.
+func EncodeReactComment(rc ReactComment) ([]byte, error) {
+	return json.Marshal(rc)
+}
+
+// DecodeReactComment decodes a JSON byte slice into a ReactComment.
+func DecodeReactComment(payload []byte) (*ReactComment, error) {
+	var rc ReactComment
+	err := json.Unmarshal(payload, &rc)
+	if err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
+
+// ReactCommentReply returns the receipt and timestamp of a comment reaction.
+// The receipt is the server side signature of ReactComment.Signature.
+type ReactCommentReply struct {
+	Receipt   string `json:"receipt"`   // Server signature of client signature
+	Timestamp int64  `json:"timestamp"` // Received UNIX timestamp
+}
+
+// EncodeReactCommentReply encodes ReactCommentReply into a JSON byte slice.
+func EncodeReactCommentReply(rcr ReactCommentReply) ([]byte, error) {
+	return json.Marshal(rcr)
+}
+
+// DecodeReactCommentReply decodes a JSON byte slice into a
+// ReactCommentReply.
+func DecodeReactCommentReply(payload []byte) (*ReactCommentReply, error) {
+	var rcr ReactCommentReply
+	err := json.Unmarshal(payload, &rcr)
+	if err != nil {
+		return nil, err
+	}
+	return &rcr, nil
+}
+
 // CensorComment is a journal entry for a censored comment.  The signature and
 // public key are from the admin that censored this comment.
 type CensorComment struct {
@@ -541,6 +617,111 @@ func DecodeCensorCommentReply(payload []byte) (*CensorCommentReply, error) {
 	return &ccr, nil
 }
 
+// EditComment is a journal entry for an edited comment.  The edit replaces
+// the comment text.  The original comment remains in the comments journal so
+// that the edit history can be audited.
+type EditComment struct {
+	Token     string `json:"token"`     // Proposal censorship token
+	CommentID string `json:"commentid"` // Comment ID
+	Comment   string `json:"comment"`   // New comment text
+	Signature string `json:"signature"` // Client signature of Token+CommentID+Comment
+	PublicKey string `json:"publickey"` // Pubkey used for signature
+
+	// Generated by decredplugin
+	Receipt   string `json:"receipt,omitempty"`   // Server signature of client signature
+	Timestamp int64  `json:"timestamp,omitempty"` // Received UNIX timestamp
+}
+
+// EncodeEditComment encodes EditComment into a JSON byte slice.
+func EncodeEditComment(ec EditComment) ([]byte, error) {
+	return json.Marshal(ec)
+}
+
+// DecodeEditComment decodes a JSON byte slice into an EditComment.
+func DecodeEditComment(payload []byte) (*EditComment, error) {
+	var ec EditComment
+	err := json.Unmarshal(payload, &ec)
+	if err != nil {
+		return nil, err
+	}
+	return &ec, nil
+}
+
+// EditCommentReply returns the receipt and timestamp of the edit.  The
+// receipt is the server side signature of EditComment.Signature.
+type EditCommentReply struct {
+	Receipt   string `json:"receipt"`   // Server signature of client signature
+	Timestamp int64  `json:"timestamp"` // Received UNIX timestamp
+}
+
+// EncodeEditCommentReply encodes EditCommentReply into a JSON byte slice.
+func EncodeEditCommentReply(ecr EditCommentReply) ([]byte, error) {
+	return json.Marshal(ecr)
+}
+
+// DecodeEditCommentReply decodes a JSON byte slice into an EditCommentReply.
+func DecodeEditCommentReply(payload []byte) (*EditCommentReply, error) {
+	var ecr EditCommentReply
+	err := json.Unmarshal(payload, &ecr)
+	if err != nil {
+		return nil, err
+	}
+	return &ecr, nil
+}
+
+// DeleteComment is a journal entry for a comment that was deleted by its
+// author.  The deletion removes the comment text and files but keeps the
+// comment so that its replies keep their parent.  The original comment
+// remains in the comments journal so that it can be audited.
+type DeleteComment struct {
+	Token     string `json:"token"`     // Proposal censorship token
+	CommentID string `json:"commentid"` // Comment ID
+	Signature string `json:"signature"` // Client signature of Token+CommentID
+	PublicKey string `json:"publickey"` // Pubkey used for signature
+
+	// Generated by decredplugin
+	Receipt   string `json:"receipt,omitempty"`   // Server signature of client signature
+	Timestamp int64  `json:"timestamp,omitempty"` // Received UNIX timestamp
+}
+
+// EncodeDeleteComment encodes DeleteComment into a JSON byte slice.
+func EncodeDeleteComment(dc DeleteComment) ([]byte, error) {
+	return json.Marshal(dc)
+}
+
+// DecodeDeleteComment decodes a JSON byte slice into a DeleteComment.
+func DecodeDeleteComment(payload []byte) (*DeleteComment, error) {
+	var dc DeleteComment
+	err := json.Unmarshal(payload, &dc)
+	if err != nil {
+		return nil, err
+	}
+	return &dc, nil
+}
+
+// DeleteCommentReply returns the receipt and timestamp of the deletion.  The
+// receipt is the server side signature of DeleteComment.Signature.
+type DeleteCommentReply struct {
+	Receipt   string `json:"receipt"`   // Server signature of client signature
+	Timestamp int64  `json:"timestamp"` // Received UNIX timestamp
+}
+
+// EncodeDeleteCommentReply encodes DeleteCommentReply into a JSON byte slice.
+func EncodeDeleteCommentReply(dcr DeleteCommentReply) ([]byte, error) {
+	return json.Marshal(dcr)
+}
+
+// DecodeDeleteCommentReply decodes a JSON byte slice into a
+// DeleteCommentReply.
+func DecodeDeleteCommentReply(payload []byte) (*DeleteCommentReply, error) {
+	var dcr DeleteCommentReply
+	err := json.Unmarshal(payload, &dcr)
+	if err != nil {
+		return nil, err
+	}
+	return &dcr, nil
+}
+
 // GetComment retrieves a single comment.
 type GetComment struct {
 	Token     string `json:"token"`     // Proposal ID
@@ -679,6 +860,51 @@ func DecodeCommentLikesReply(payload []byte) (*CommentLikesReply, error) {
 	return &clr, nil
 }
 
+// CommentReactions is used to retrieve all of the reactions to a single
+// comment.
+type CommentReactions struct {
+	Token     string `json:"token"`     // Censorship token
+	CommentID string `json:"commentid"` // Comment ID
+}
+
+// EncodeCommentReactions encodes CommentReactions into a JSON byte slice.
+func EncodeCommentReactions(cr CommentReactions) ([]byte, error) {
+	return json.Marshal(cr)
+}
+
+// DecodeCommentReactions decodes a JSON byte slice into a CommentReactions.
+func DecodeCommentReactions(payload []byte) (*CommentReactions, error) {
+	var cr CommentReactions
+	err := json.Unmarshal(payload, &cr)
+	if err != nil {
+		return nil, err
+	}
+	return &cr, nil
+}
+
+// CommentReactionsReply is the reply to CommentReactions and returns all of
+// the reactions to the comment in chronological order.
+type CommentReactionsReply struct {
+	CommentReactions []ReactComment `json:"commentreactions"`
+}
+
+// EncodeCommentReactionsReply encodes CommentReactionsReply into a JSON byte
+// slice.
+func EncodeCommentReactionsReply(crr CommentReactionsReply) ([]byte, error) {
+	return json.Marshal(crr)
+}
+
+// DecodeCommentReactionsReply decodes a JSON byte slice into a
+// CommentReactionsReply.
+func DecodeCommentReactionsReply(payload []byte) (*CommentReactionsReply, error) {
+	var crr CommentReactionsReply
+	err := json.Unmarshal(payload, &crr)
+	if err != nil {
+		return nil, err
+	}
+	return &crr, nil
+}
+
 // GetProposalCommentsLikes is a command to fetch all vote actions
 // on the comments of a given proposal
 type GetProposalCommentsLikes struct {
@@ -758,6 +984,7 @@ type StartVoteTuple struct {
 type InventoryReply struct {
 	Comments             []Comment            `json:"comments"`             // Comments
 	LikeComments         []LikeComment        `json:"likecomments"`         // Like comments
+	ReactComments        []ReactComment       `json:"reactcomments"`        // Comment reactions
 	AuthorizeVotes       []AuthorizeVote      `json:"authorizevotes"`       // Authorize votes
 	AuthorizeVoteReplies []AuthorizeVoteReply `json:"authorizevotereplies"` // Authorize vote replies
 	StartVoteTuples      []StartVoteTuple     `json:"startvotetuples"`      // Start vote tuples
@@ -49,6 +49,9 @@ const (
 	journalActionAdd     = "add"     // Add entry
 	journalActionDel     = "del"     // Delete entry
 	journalActionAddLike = "addlike" // Add comment like
+	journalActionEdit    = "edit"    // Edit entry
+	journalActionRetract = "retract" // Author delete entry
+	journalActionReact   = "react"   // Add comment reaction
 
 	flushRecordVersion = "1" // Version 1 of the flush journal
 
@@ -74,9 +77,12 @@ type FlushRecord struct {
 // journalActionAdd -> Add entry
 // journalActionDel -> Delete entry
 // journalActionAddLike -> Add comment like structure (comments only)
+// journalActionEdit -> Edit entry (comments only)
+// journalActionRetract -> Author delete entry (comments only)
+// journalActionReact -> Add comment reaction structure (comments only)
 type JournalAction struct {
 	Version string `json:"version"` // Version
-	Action  string `json:"action"`  // Add/Del
+	Action  string `json:"action"`  // Add/Del/AddLike/Edit/Retract/React
 }
 
 type CastVoteJournal struct {
@@ -117,6 +123,9 @@ var (
 	journalAdd     []byte
 	journalDel     []byte
 	journalAddLike []byte
+	journalEdit    []byte
+	journalRetract []byte
+	journalReact   []byte
 
 	// Plugin specific data that CANNOT be treated as metadata
 	pluginDataDir = filepath.Join("plugins", "decred")
@@ -128,6 +137,8 @@ var (
 	decredPluginCommentsCache      = make(map[string]map[string]decredplugin.Comment) // [token][commentid]comment
 	decredPluginCommentsLikesCache = make(map[string][]decredplugin.LikeComment)      // [token]LikeComment
 
+	decredPluginCommentsReactionsCache = make(map[string][]decredplugin.ReactComment) // [token]ReactComment
+
 	journalsReplayed bool = false
 )
 
@@ -156,6 +167,27 @@ func init() {
 	if err != nil {
 		panic(err.Error())
 	}
+	journalEdit, err = json.Marshal(JournalAction{
+		Version: journalVersion,
+		Action:  journalActionEdit,
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+	journalRetract, err = json.Marshal(JournalAction{
+		Version: journalVersion,
+		Action:  journalActionRetract,
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+	journalReact, err = json.Marshal(JournalAction{
+		Version: journalVersion,
+		Action:  journalActionReact,
+	})
+	if err != nil {
+		panic(err.Error())
+	}
 }
 
 func getDecredPlugin(testnet bool) backend.Plugin {
@@ -1015,6 +1047,7 @@ func (g *gitBackEnd) pluginNewComment(payload string) (string, error) {
 		Comment:   comment.Comment,
 		Signature: comment.Signature,
 		PublicKey: comment.PublicKey,
+		Files:     comment.Files,
 		CommentID: cid,
 		Receipt:   receipt,
 		Timestamp: time.Now().Unix(),
@@ -1184,6 +1217,123 @@ func (g *gitBackEnd) pluginLikeComment(payload string) (string, error) {
 	return string(lcrb), nil
 }
 
+// pluginReactComment adds or removes a reaction of a user to a comment.  The
+// reaction is appended to the comments journal.  Verifying that the reaction
+// type is allowed is left to the caller.
+func (g *gitBackEnd) pluginReactComment(payload string) (string, error) {
+	log.Tracef("pluginReactComment")
+
+	// Check if journals were replayed
+	if !journalsReplayed {
+		return "", backend.ErrJournalsNotReplayed
+	}
+
+	// XXX this should become part of some sort of context
+	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	if !ok {
+		return "", fmt.Errorf("full identity not set")
+	}
+	fi, err := identity.UnmarshalFullIdentity([]byte(fiJSON))
+	if err != nil {
+		return "", fmt.Errorf("UnmarshalFullIdentity: %v", err)
+	}
+
+	// Decode reaction
+	react, err := decredplugin.DecodeReactComment([]byte(payload))
+	if err != nil {
+		return "", fmt.Errorf("DecodeReactComment: %v", err)
+	}
+
+	// Make sure action makes sense
+	if react.Action != decredplugin.ReactionActionAdd &&
+		react.Action != decredplugin.ReactionActionRemove {
+		return "", fmt.Errorf("invalid action")
+	}
+	if react.Reaction == "" {
+		return "", fmt.Errorf("invalid reaction")
+	}
+
+	// Verify proposal exists, we can run this lockless
+	if !g.propExists(g.vetted, react.Token) {
+		return "", fmt.Errorf("unknown proposal: %v", react.Token)
+	}
+
+	// Sign signature
+	r := fi.SignMessage([]byte(react.Signature))
+	receipt := hex.EncodeToString(r[:])
+	timestamp := time.Now().Unix()
+
+	// Comment journal filename
+	flushFilename := pijoin(g.journals, react.Token,
+		defaultCommentsFlushed)
+
+	g.Lock()
+
+	// Mark comment journal dirty
+	_ = os.Remove(flushFilename)
+
+	// Verify cache
+	_, ok = decredPluginCommentsCache[react.Token][react.CommentID]
+	if !ok {
+		g.Unlock()
+		return "", fmt.Errorf("comment not found %v:%v",
+			react.Token, react.CommentID)
+	}
+
+	// Create Journal entry
+	rc := decredplugin.ReactComment{
+		Token:     react.Token,
+		CommentID: react.CommentID,
+		Reaction:  react.Reaction,
+		Action:    react.Action,
+		Signature: react.Signature,
+		PublicKey: react.PublicKey,
+		Receipt:   receipt,
+		Timestamp: timestamp,
+	}
+
+	// Update cache
+	cr := decredPluginCommentsReactionsCache[react.Token]
+	decredPluginCommentsReactionsCache[react.Token] = append(cr, rc)
+	g.Unlock()
+
+	// We create an unwind function that MUST be called from all error
+	// paths. If everything works ok it is a no-op.
+	unwind := func() {
+		g.Lock()
+		decredPluginCommentsReactionsCache[react.Token] = cr
+		g.Unlock()
+	}
+
+	blob, err := decredplugin.EncodeReactComment(rc)
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("EncodeReactComment: %v", err)
+	}
+
+	// Add reaction to journal
+	cfilename := pijoin(g.journals, react.Token,
+		defaultCommentFilename)
+	err = g.journal.Journal(cfilename, string(journalReact)+string(blob))
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("could not journal %v: %v", rc.Token, err)
+	}
+
+	// Encode reply
+	rcr := decredplugin.ReactCommentReply{
+		Receipt:   rc.Receipt,
+		Timestamp: rc.Timestamp,
+	}
+	rcrb, err := decredplugin.EncodeReactCommentReply(rcr)
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("EncodeReactCommentReply: %v", err)
+	}
+
+	return string(rcrb), nil
+}
+
 func (g *gitBackEnd) pluginCensorComment(payload string) (string, error) {
 	log.Tracef("pluginCensorComment")
 
@@ -1251,7 +1401,9 @@ func (g *gitBackEnd) pluginCensorComment(payload string) (string, error) {
 	// Update comments cache
 	oc := c
 	c.Comment = ""
+	c.Files = nil
 	c.Censored = true
+	c.CensorReason = censor.Reason
 	decredPluginCommentsCache[censor.Token][censor.CommentID] = c
 
 	g.Unlock()
@@ -1302,6 +1454,252 @@ func (g *gitBackEnd) pluginCensorComment(payload string) (string, error) {
 	return string(ccrb), nil
 }
 
+// pluginEditComment replaces the text of an existing comment.  The edit is
+// appended to the comments journal so the original comment text is preserved.
+// Verifying that the edit is allowed, e.g. that it was made by the comment
+// author, is left to the caller.
+func (g *gitBackEnd) pluginEditComment(payload string) (string, error) {
+	log.Tracef("pluginEditComment")
+
+	// Check if journals were replayed
+	if !journalsReplayed {
+		return "", backend.ErrJournalsNotReplayed
+	}
+
+	// XXX this should become part of some sort of context
+	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	if !ok {
+		return "", fmt.Errorf("full identity not set")
+	}
+	fi, err := identity.UnmarshalFullIdentity([]byte(fiJSON))
+	if err != nil {
+		return "", fmt.Errorf("UnmarshalFullIdentity: %v", err)
+	}
+
+	// Decode edit comment
+	edit, err := decredplugin.DecodeEditComment([]byte(payload))
+	if err != nil {
+		return "", fmt.Errorf("DecodeEditComment: %v", err)
+	}
+
+	// Verify proposal exists, we can run this lockless
+	if !g.propExists(g.vetted, edit.Token) {
+		return "", fmt.Errorf("unknown proposal: %v", edit.Token)
+	}
+
+	// Sign signature
+	r := fi.SignMessage([]byte(edit.Signature))
+	receipt := hex.EncodeToString(r[:])
+	timestamp := time.Now().Unix()
+
+	// Comment journal filename
+	flushFilename := pijoin(g.journals, edit.Token,
+		defaultCommentsFlushed)
+
+	g.Lock()
+
+	// Mark comment journal dirty
+	_ = os.Remove(flushFilename)
+
+	// Ensure comment exists in comments cache and has not been
+	// censored or deleted
+	c, ok := decredPluginCommentsCache[edit.Token][edit.CommentID]
+	if !ok {
+		g.Unlock()
+		return "", fmt.Errorf("comment not found %v:%v",
+			edit.Token, edit.CommentID)
+	}
+	if c.Censored {
+		g.Unlock()
+		return "", fmt.Errorf("comment censored %v: %v",
+			edit.Token, edit.CommentID)
+	}
+	if c.Deleted != 0 {
+		g.Unlock()
+		return "", fmt.Errorf("comment deleted %v: %v",
+			edit.Token, edit.CommentID)
+	}
+
+	// Update comments cache
+	oc := c
+	c.Comment = edit.Comment
+	c.Signature = edit.Signature
+	c.PublicKey = edit.PublicKey
+	c.Receipt = receipt
+	c.Edited = timestamp
+	decredPluginCommentsCache[edit.Token][edit.CommentID] = c
+
+	g.Unlock()
+
+	// We create an unwind function that MUST be called from all error
+	// paths. If everything works ok it is a no-op.
+	unwind := func() {
+		g.Lock()
+		decredPluginCommentsCache[edit.Token][edit.CommentID] = oc
+		g.Unlock()
+	}
+
+	// Create Journal entry
+	ec := decredplugin.EditComment{
+		Token:     edit.Token,
+		CommentID: edit.CommentID,
+		Comment:   edit.Comment,
+		Signature: edit.Signature,
+		PublicKey: edit.PublicKey,
+		Receipt:   receipt,
+		Timestamp: timestamp,
+	}
+	blob, err := decredplugin.EncodeEditComment(ec)
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("EncodeEditComment: %v", err)
+	}
+
+	// Add edit comment to journal
+	cfilename := pijoin(g.journals, edit.Token,
+		defaultCommentFilename)
+	err = g.journal.Journal(cfilename, string(journalEdit)+string(blob))
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("could not journal %v: %v", ec.Token, err)
+	}
+
+	// Encode reply
+	ecr := decredplugin.EditCommentReply{
+		Receipt:   ec.Receipt,
+		Timestamp: ec.Timestamp,
+	}
+	ecrb, err := decredplugin.EncodeEditCommentReply(ecr)
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("EncodeEditCommentReply: %v", err)
+	}
+
+	return string(ecrb), nil
+}
+
+// pluginDeleteComment deletes a comment on behalf of its author.  The comment
+// text and files are removed but the comment itself is kept so that its
+// replies keep their parent.  The deletion is appended to the comments journal
+// so the original comment is preserved.  Verifying that the deletion is
+// allowed, e.g. that it was made by the comment author, is left to the caller.
+func (g *gitBackEnd) pluginDeleteComment(payload string) (string, error) {
+	log.Tracef("pluginDeleteComment")
+
+	// Check if journals were replayed
+	if !journalsReplayed {
+		return "", backend.ErrJournalsNotReplayed
+	}
+
+	// XXX this should become part of some sort of context
+	fiJSON, ok := decredPluginSettings[decredPluginIdentity]
+	if !ok {
+		return "", fmt.Errorf("full identity not set")
+	}
+	fi, err := identity.UnmarshalFullIdentity([]byte(fiJSON))
+	if err != nil {
+		return "", fmt.Errorf("UnmarshalFullIdentity: %v", err)
+	}
+
+	// Decode delete comment
+	del, err := decredplugin.DecodeDeleteComment([]byte(payload))
+	if err != nil {
+		return "", fmt.Errorf("DecodeDeleteComment: %v", err)
+	}
+
+	// Verify proposal exists, we can run this lockless
+	if !g.propExists(g.vetted, del.Token) {
+		return "", fmt.Errorf("unknown proposal: %v", del.Token)
+	}
+
+	// Sign signature
+	r := fi.SignMessage([]byte(del.Signature))
+	receipt := hex.EncodeToString(r[:])
+	timestamp := time.Now().Unix()
+
+	// Comment journal filename
+	flushFilename := pijoin(g.journals, del.Token,
+		defaultCommentsFlushed)
+
+	g.Lock()
+
+	// Mark comment journal dirty
+	_ = os.Remove(flushFilename)
+
+	// Ensure comment exists in comments cache and has not been
+	// censored or deleted
+	c, ok := decredPluginCommentsCache[del.Token][del.CommentID]
+	if !ok {
+		g.Unlock()
+		return "", fmt.Errorf("comment not found %v:%v",
+			del.Token, del.CommentID)
+	}
+	if c.Censored {
+		g.Unlock()
+		return "", fmt.Errorf("comment censored %v: %v",
+			del.Token, del.CommentID)
+	}
+	if c.Deleted != 0 {
+		g.Unlock()
+		return "", fmt.Errorf("comment already deleted %v: %v",
+			del.Token, del.CommentID)
+	}
+
+	// Update comments cache
+	oc := c
+	c.Comment = ""
+	c.Files = nil
+	c.Deleted = timestamp
+	decredPluginCommentsCache[del.Token][del.CommentID] = c
+
+	g.Unlock()
+
+	// We create an unwind function that MUST be called from all error
+	// paths. If everything works ok it is a no-op.
+	unwind := func() {
+		g.Lock()
+		decredPluginCommentsCache[del.Token][del.CommentID] = oc
+		g.Unlock()
+	}
+
+	// Create Journal entry
+	dc := decredplugin.DeleteComment{
+		Token:     del.Token,
+		CommentID: del.CommentID,
+		Signature: del.Signature,
+		PublicKey: del.PublicKey,
+		Receipt:   receipt,
+		Timestamp: timestamp,
+	}
+	blob, err := decredplugin.EncodeDeleteComment(dc)
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("EncodeDeleteComment: %v", err)
+	}
+
+	// Add delete comment to journal
+	cfilename := pijoin(g.journals, del.Token,
+		defaultCommentFilename)
+	err = g.journal.Journal(cfilename, string(journalRetract)+string(blob))
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("could not journal %v: %v", dc.Token, err)
+	}
+
+	// Encode reply
+	dcr := decredplugin.DeleteCommentReply{
+		Receipt:   dc.Receipt,
+		Timestamp: dc.Timestamp,
+	}
+	dcrb, err := decredplugin.EncodeDeleteCommentReply(dcr)
+	if err != nil {
+		unwind()
+		return "", fmt.Errorf("EncodeDeleteCommentReply: %v", err)
+	}
+
+	return string(dcrb), nil
+}
+
 // encodeGetCommentsReply converts a comment map into a JSON string that can be
 // returned as a decredplugin reply. If the comment map is nil it returns a
 // valid empty reply structure.
@@ -1357,6 +1755,7 @@ func (g *gitBackEnd) replayComments(token string) (map[string]decredplugin.Comme
 
 	comments := make(map[string]decredplugin.Comment)
 	commentsLikes := make([]decredplugin.LikeComment, 0, 1024)
+	commentsReactions := make([]decredplugin.ReactComment, 0, 1024)
 
 	for {
 		err = g.journal.Replay(cfilename, func(s string) error {
@@ -1407,7 +1806,9 @@ func (g *gitBackEnd) replayComments(token string) (map[string]decredplugin.Comme
 
 				// Delete comment
 				c.Comment = ""
+				c.Files = nil
 				c.Censored = true
+				c.CensorReason = cc.Reason
 				comments[cc.CommentID] = c
 
 			case journalActionAddLike:
@@ -1420,6 +1821,68 @@ func (g *gitBackEnd) replayComments(token string) (map[string]decredplugin.Comme
 
 				commentsLikes = append(commentsLikes, lc)
 
+			case journalActionReact:
+				var rc decredplugin.ReactComment
+				err = d.Decode(&rc)
+				if err != nil {
+					return fmt.Errorf("journal react: %v",
+						err)
+				}
+
+				commentsReactions = append(commentsReactions, rc)
+
+			case journalActionEdit:
+				var ec decredplugin.EditComment
+				err = d.Decode(&ec)
+				if err != nil {
+					return fmt.Errorf("journal edit: %v",
+						err)
+				}
+
+				// Ensure comment has been added
+				c, ok := comments[ec.CommentID]
+				if !ok {
+					// Complain but we can't do anything
+					// about it. Can't return error or we'd
+					// abort journal loop.
+					log.Errorf("comment not found: %v",
+						ec.CommentID)
+					return nil
+				}
+
+				// Edit comment
+				c.Comment = ec.Comment
+				c.Signature = ec.Signature
+				c.PublicKey = ec.PublicKey
+				c.Receipt = ec.Receipt
+				c.Edited = ec.Timestamp
+				comments[ec.CommentID] = c
+
+			case journalActionRetract:
+				var dc decredplugin.DeleteComment
+				err = d.Decode(&dc)
+				if err != nil {
+					return fmt.Errorf("journal retract: %v",
+						err)
+				}
+
+				// Ensure comment has been added
+				c, ok := comments[dc.CommentID]
+				if !ok {
+					// Complain but we can't do anything
+					// about it. Can't return error or we'd
+					// abort journal loop.
+					log.Errorf("comment not found: %v",
+						dc.CommentID)
+					return nil
+				}
+
+				// Delete comment on behalf of its author
+				c.Comment = ""
+				c.Files = nil
+				c.Deleted = dc.Timestamp
+				comments[dc.CommentID] = c
+
 			default:
 				return fmt.Errorf("invalid action: %v",
 					action.Action)
@@ -1436,6 +1899,7 @@ func (g *gitBackEnd) replayComments(token string) (map[string]decredplugin.Comme
 	g.Lock()
 	decredPluginCommentsCache[token] = comments
 	decredPluginCommentsLikesCache[token] = commentsLikes
+	decredPluginCommentsReactionsCache[token] = commentsReactions
 	g.Unlock()
 
 	return comments, nil
@@ -2304,8 +2768,8 @@ nodata:
 }
 
 // pluginInventory returns the decred plugin inventory for all proposals.  The
-// inventory consists of comments, like comments, vote authorizations, vote
-// details, and cast votes.
+// inventory consists of comments, like comments, comment reactions, vote
+// authorizations, vote details, and cast votes.
 func (g *gitBackEnd) pluginInventory() (string, error) {
 	log.Tracef("pluginInventory")
 
@@ -2340,6 +2804,17 @@ func (g *gitBackEnd) pluginInventory() (string, error) {
 		likes = append(likes, v...)
 	}
 
+	// Walk in-memory comment reactions cache and compile all
+	// comment reactions
+	count = 0
+	for _, v := range decredPluginCommentsReactionsCache {
+		count += len(v)
+	}
+	reactions := make([]decredplugin.ReactComment, 0, count)
+	for _, v := range decredPluginCommentsReactionsCache {
+		reactions = append(reactions, v...)
+	}
+
 	// Walk vetted repo and compile all file paths
 	paths := make([]string, 0, 2048) // PNOOMA
 	err := filepath.Walk(g.vetted,
@@ -2486,6 +2961,7 @@ func (g *gitBackEnd) pluginInventory() (string, error) {
 	ir := decredplugin.InventoryReply{
 		Comments:             comments,
 		LikeComments:         likes,
+		ReactComments:        reactions,
 		AuthorizeVotes:       av,
 		AuthorizeVoteReplies: avr,
 		StartVoteTuples:      svt,
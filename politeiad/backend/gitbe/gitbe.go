@@ -2367,9 +2367,18 @@ func (g *gitBackEnd) Plugin(command, payload string) (string, string, error) {
 	case decredplugin.CmdLikeComment:
 		payload, err := g.pluginLikeComment(payload)
 		return decredplugin.CmdLikeComment, payload, err
+	case decredplugin.CmdReactComment:
+		payload, err := g.pluginReactComment(payload)
+		return decredplugin.CmdReactComment, payload, err
 	case decredplugin.CmdCensorComment:
 		payload, err := g.pluginCensorComment(payload)
 		return decredplugin.CmdCensorComment, payload, err
+	case decredplugin.CmdEditComment:
+		payload, err := g.pluginEditComment(payload)
+		return decredplugin.CmdEditComment, payload, err
+	case decredplugin.CmdDeleteComment:
+		payload, err := g.pluginDeleteComment(payload)
+		return decredplugin.CmdDeleteComment, payload, err
 	case decredplugin.CmdGetComments:
 		payload, err := g.pluginGetComments(payload)
 		return decredplugin.CmdGetComments, payload, err
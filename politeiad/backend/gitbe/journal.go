@@ -20,6 +20,10 @@ var (
 	ErrSameFile = fmt.Errorf("source same as destination")
 )
 
+// maxJournalLineSize is the maximum size of a single journal entry that can be
+// replayed.  It must fit comments that carry attachments.
+const maxJournalLineSize = 1024 * 1024
+
 type journalFile struct {
 	file    *os.File
 	scanner *bufio.Scanner
@@ -42,7 +46,7 @@ func NewJournal() *Journal {
 }
 
 // Journal writes content to a journal file. Note that content should not be
-// bigger than bufio.Scanner can read per line. If the user does not provide
+// bigger than maxJournalLineSize. If the user does not provide
 // "\n" at the end of content string, this function appends it.
 func (j *Journal) Journal(filename, content string) error {
 	j.Lock()
@@ -83,9 +87,11 @@ func (j *Journal) Open(filename string) error {
 		return err
 	}
 
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxJournalLineSize)
 	j.journals[filename] = &journalFile{
 		file:    f,
-		scanner: bufio.NewScanner(f),
+		scanner: scanner,
 	}
 
 	return nil
@@ -85,6 +85,37 @@ func convertRecordToCache(r Record) cache.Record {
 	}
 }
 
+func convertCommentFilesFromDecred(files []decredplugin.CommentFile) []CommentFile {
+	cf := make([]CommentFile, 0, len(files))
+	for _, f := range files {
+		cf = append(cf,
+			CommentFile{
+				Name:    f.Name,
+				MIME:    f.MIME,
+				Digest:  f.Digest,
+				Payload: f.Payload,
+			})
+	}
+	return cf
+}
+
+func convertCommentFilesToDecred(files []CommentFile) []decredplugin.CommentFile {
+	if len(files) == 0 {
+		return nil
+	}
+	df := make([]decredplugin.CommentFile, 0, len(files))
+	for _, f := range files {
+		df = append(df,
+			decredplugin.CommentFile{
+				Name:    f.Name,
+				MIME:    f.MIME,
+				Digest:  f.Digest,
+				Payload: f.Payload,
+			})
+	}
+	return df
+}
+
 func convertNewCommentFromDecred(nc decredplugin.NewComment, ncr decredplugin.NewCommentReply) Comment {
 	return Comment{
 		Key:       nc.Token + ncr.CommentID,
@@ -97,37 +128,46 @@ func convertNewCommentFromDecred(nc decredplugin.NewComment, ncr decredplugin.Ne
 		Receipt:   ncr.Receipt,
 		Timestamp: ncr.Timestamp,
 		Censored:  false,
+		Files:     convertCommentFilesFromDecred(nc.Files),
 	}
 }
 
 func convertCommentFromDecred(c decredplugin.Comment) Comment {
 	return Comment{
-		Key:       c.Token + c.CommentID,
-		Token:     c.Token,
-		ParentID:  c.ParentID,
-		Comment:   c.Comment,
-		Signature: c.Signature,
-		PublicKey: c.PublicKey,
-		CommentID: c.CommentID,
-		Receipt:   c.Receipt,
-		Timestamp: c.Timestamp,
-		Censored:  false,
+		Key:          c.Token + c.CommentID,
+		Token:        c.Token,
+		ParentID:     c.ParentID,
+		Comment:      c.Comment,
+		Signature:    c.Signature,
+		PublicKey:    c.PublicKey,
+		CommentID:    c.CommentID,
+		Receipt:      c.Receipt,
+		Timestamp:    c.Timestamp,
+		Censored:     c.Censored,
+		Edited:       c.Edited,
+		CensorReason: c.CensorReason,
+		Deleted:      c.Deleted,
+		Files:        convertCommentFilesFromDecred(c.Files),
 	}
 }
 
 func convertCommentToDecred(c Comment) decredplugin.Comment {
 	return decredplugin.Comment{
-		Token:       c.Token,
-		ParentID:    c.ParentID,
-		Comment:     c.Comment,
-		Signature:   c.Signature,
-		PublicKey:   c.PublicKey,
-		CommentID:   c.CommentID,
-		Receipt:     c.Receipt,
-		Timestamp:   c.Timestamp,
-		TotalVotes:  0,
-		ResultVotes: 0,
-		Censored:    c.Censored,
+		Token:        c.Token,
+		ParentID:     c.ParentID,
+		Comment:      c.Comment,
+		Signature:    c.Signature,
+		PublicKey:    c.PublicKey,
+		CommentID:    c.CommentID,
+		Receipt:      c.Receipt,
+		Timestamp:    c.Timestamp,
+		TotalVotes:   0,
+		ResultVotes:  0,
+		Censored:     c.Censored,
+		Edited:       c.Edited,
+		CensorReason: c.CensorReason,
+		Deleted:      c.Deleted,
+		Files:        convertCommentFilesToDecred(c.Files),
 	}
 }
 
@@ -151,6 +191,32 @@ func convertLikeCommentToDecred(lc LikeComment) decredplugin.LikeComment {
 	}
 }
 
+func convertReactCommentFromDecred(rc decredplugin.ReactComment, rcr decredplugin.ReactCommentReply) CommentReaction {
+	return CommentReaction{
+		Token:     rc.Token,
+		CommentID: rc.CommentID,
+		Reaction:  rc.Reaction,
+		Action:    rc.Action,
+		Signature: rc.Signature,
+		PublicKey: rc.PublicKey,
+		Receipt:   rcr.Receipt,
+		Timestamp: rcr.Timestamp,
+	}
+}
+
+func convertCommentReactionToDecred(cr CommentReaction) decredplugin.ReactComment {
+	return decredplugin.ReactComment{
+		Token:     cr.Token,
+		CommentID: cr.CommentID,
+		Reaction:  cr.Reaction,
+		Action:    cr.Action,
+		Signature: cr.Signature,
+		PublicKey: cr.PublicKey,
+		Receipt:   cr.Receipt,
+		Timestamp: cr.Timestamp,
+	}
+}
+
 func convertAuthorizeVoteFromDecred(av decredplugin.AuthorizeVote, avr decredplugin.AuthorizeVoteReply) AuthorizeVote {
 	return AuthorizeVote{
 		Key:       av.Token + avr.RecordVersion,
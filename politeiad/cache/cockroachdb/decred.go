@@ -17,15 +17,17 @@ const (
 	// decredVersion is the version of the cache implementation of
 	// decred plugin. This may differ from the decredplugin package
 	// version.
-	decredVersion = "1"
+	decredVersion = "6"
 
 	// Decred plugin table names
-	tableComments       = "comments"
-	tableCommentLikes   = "comment_likes"
-	tableCastVotes      = "cast_votes"
-	tableAuthorizeVotes = "authorize_votes"
-	tableVoteOptions    = "vote_options"
-	tableStartVotes     = "start_votes"
+	tableComments         = "comments"
+	tableCommentFiles     = "comment_files"
+	tableCommentLikes     = "comment_likes"
+	tableCommentReactions = "comment_reactions"
+	tableCastVotes        = "cast_votes"
+	tableAuthorizeVotes   = "authorize_votes"
+	tableVoteOptions      = "vote_options"
+	tableStartVotes       = "start_votes"
 )
 
 // decred implements the PluginDriver interface.
@@ -85,8 +87,36 @@ func (d *decred) cmdLikeComment(cmdPayload, replyPayload string) (string, error)
 	return replyPayload, err
 }
 
+// newCommentReaction inserts a CommentReaction record into the database.
+// This function has a database parameter so that it can be called inside of a
+// transaction when required.
+func (d *decred) newCommentReaction(db *gorm.DB, cr CommentReaction) error {
+	return db.Create(&cr).Error
+}
+
+// cmdReactComment creates a CommentReaction record using the passed in
+// payloads and inserts it into the database.
+func (d *decred) cmdReactComment(cmdPayload, replyPayload string) (string, error) {
+	log.Tracef("decred cmdReactComment")
+
+	rc, err := decredplugin.DecodeReactComment([]byte(cmdPayload))
+	if err != nil {
+		return "", err
+	}
+	rcr, err := decredplugin.DecodeReactCommentReply([]byte(replyPayload))
+	if err != nil {
+		return "", err
+	}
+
+	cr := convertReactCommentFromDecred(*rc, *rcr)
+	err = d.newCommentReaction(d.recordsdb, cr)
+
+	return replyPayload, err
+}
+
 // cmdCensorComment censors an existing comment.  A censored comment has its
-// comment message removed and is marked as censored.
+// comment message removed, is marked as censored, and records the reason that
+// it was censored.
 func (d *decred) cmdCensorComment(cmdPayload, replyPayload string) (string, error) {
 	log.Tracef("decred cmdCensorComment")
 
@@ -95,18 +125,115 @@ func (d *decred) cmdCensorComment(cmdPayload, replyPayload string) (string, erro
 		return "", err
 	}
 
+	// Run update in a transaction so that the comment attachments
+	// are deleted along with the comment text.
 	c := Comment{
 		Key: cc.Token + cc.CommentID,
 	}
+	tx := d.recordsdb.Begin()
+	err = tx.Model(&c).
+		Updates(map[string]interface{}{
+			"comment":       "",
+			"censored":      true,
+			"censor_reason": cc.Reason,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("censor comment: %v", err)
+	}
+	err = tx.Where("comment_key = ?", c.Key).
+		Delete(CommentFile{}).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("delete comment files: %v", err)
+	}
+
+	// Commit transaction
+	err = tx.Commit().Error
+	if err != nil {
+		return "", fmt.Errorf("commit transaction: %v", err)
+	}
+
+	return replyPayload, nil
+}
+
+// cmdEditComment replaces the text of an existing comment and records the
+// time of the edit.
+func (d *decred) cmdEditComment(cmdPayload, replyPayload string) (string, error) {
+	log.Tracef("decred cmdEditComment")
+
+	ec, err := decredplugin.DecodeEditComment([]byte(cmdPayload))
+	if err != nil {
+		return "", err
+	}
+	ecr, err := decredplugin.DecodeEditCommentReply([]byte(replyPayload))
+	if err != nil {
+		return "", err
+	}
+
+	c := Comment{
+		Key: ec.Token + ec.CommentID,
+	}
 	err = d.recordsdb.Model(&c).
 		Updates(map[string]interface{}{
-			"comment":  "",
-			"censored": true,
+			"comment":    ec.Comment,
+			"signature":  ec.Signature,
+			"public_key": ec.PublicKey,
+			"receipt":    ecr.Receipt,
+			"edited":     ecr.Timestamp,
 		}).Error
 
 	return replyPayload, err
 }
 
+// cmdDeleteComment deletes a comment on behalf of its author.  A deleted
+// comment has its comment message and attachments removed and records the
+// time of the deletion.
+func (d *decred) cmdDeleteComment(cmdPayload, replyPayload string) (string, error) {
+	log.Tracef("decred cmdDeleteComment")
+
+	dc, err := decredplugin.DecodeDeleteComment([]byte(cmdPayload))
+	if err != nil {
+		return "", err
+	}
+	dcr, err := decredplugin.DecodeDeleteCommentReply([]byte(replyPayload))
+	if err != nil {
+		return "", err
+	}
+
+	// Run update in a transaction so that the comment attachments
+	// are deleted along with the comment text.
+	c := Comment{
+		Key: dc.Token + dc.CommentID,
+	}
+	tx := d.recordsdb.Begin()
+	err = tx.Model(&c).
+		Updates(map[string]interface{}{
+			"comment": "",
+			"deleted": dcr.Timestamp,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("delete comment: %v", err)
+	}
+	err = tx.Where("comment_key = ?", c.Key).
+		Delete(CommentFile{}).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("delete comment files: %v", err)
+	}
+
+	// Commit transaction
+	err = tx.Commit().Error
+	if err != nil {
+		return "", fmt.Errorf("commit transaction: %v", err)
+	}
+
+	return replyPayload, nil
+}
+
 // cmdGetComment retreives the passed in comment from the database.
 func (d *decred) cmdGetComment(payload string) (string, error) {
 	log.Tracef("decred cmdGetComment")
@@ -119,7 +246,10 @@ func (d *decred) cmdGetComment(payload string) (string, error) {
 	c := Comment{
 		Key: gc.Token + gc.CommentID,
 	}
-	err = d.recordsdb.Find(&c).Error
+	err = d.recordsdb.
+		Preload("Files").
+		Find(&c).
+		Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			err = cache.ErrRecordNotFound
@@ -150,6 +280,7 @@ func (d *decred) cmdGetComments(payload string) (string, error) {
 	comments := make([]Comment, 0, 1024) // PNOOMA
 	err = d.recordsdb.
 		Where("token = ?", gc.Token).
+		Preload("Files").
 		Find(&comments).
 		Error
 	if err != nil {
@@ -206,6 +337,42 @@ func (d *decred) cmdCommentLikes(payload string) (string, error) {
 	return string(clrb), nil
 }
 
+// cmdCommentReactions returns all of the reactions for the passed in comment
+// in the order that they were received.
+func (d *decred) cmdCommentReactions(payload string) (string, error) {
+	log.Tracef("decred cmdCommentReactions")
+
+	cr, err := decredplugin.DecodeCommentReactions([]byte(payload))
+	if err != nil {
+		return "", err
+	}
+
+	reactions := make([]CommentReaction, 0, 1024) // PNOOMA
+	err = d.recordsdb.
+		Where("token = ? AND comment_id = ?", cr.Token, cr.CommentID).
+		Order("timestamp asc, key asc").
+		Find(&reactions).
+		Error
+	if err != nil {
+		return "", err
+	}
+
+	rc := make([]decredplugin.ReactComment, 0, len(reactions))
+	for _, v := range reactions {
+		rc = append(rc, convertCommentReactionToDecred(v))
+	}
+
+	crr := decredplugin.CommentReactionsReply{
+		CommentReactions: rc,
+	}
+	crrb, err := decredplugin.EncodeCommentReactionsReply(crr)
+	if err != nil {
+		return "", err
+	}
+
+	return string(crrb), nil
+}
+
 // cmdProposalLikes returns all of the comment likes for all comments of the
 // passed in record token.
 func (d *decred) cmdProposalCommentsLikes(payload string) (string, error) {
@@ -489,13 +656,16 @@ func (d *decred) cmdProposalVotes(payload string) (string, error) {
 func (d *decred) cmdInventory() (string, error) {
 	log.Tracef("decred cmdInventory")
 
-	// XXX the only part of the decred plugin inventory that we return
-	// at the moment is comments. This is because comments are the only
-	// thing politeiawww currently needs on startup.
+	// XXX the only parts of the decred plugin inventory that we return
+	// at the moment are comments and comment reactions. This is because
+	// they are the only things politeiawww currently needs on startup.
 
 	// Get all comments
 	var c []Comment
-	err := d.recordsdb.Find(&c).Error
+	err := d.recordsdb.
+		Preload("Files").
+		Find(&c).
+		Error
 	if err != nil {
 		return "", err
 	}
@@ -505,9 +675,25 @@ func (d *decred) cmdInventory() (string, error) {
 		dc = append(dc, convertCommentToDecred(v))
 	}
 
+	// Get all comment reactions in the order that they were received
+	var cr []CommentReaction
+	err = d.recordsdb.
+		Order("timestamp asc, key asc").
+		Find(&cr).
+		Error
+	if err != nil {
+		return "", err
+	}
+
+	rc := make([]decredplugin.ReactComment, 0, len(cr))
+	for _, v := range cr {
+		rc = append(rc, convertCommentReactionToDecred(v))
+	}
+
 	// Prepare inventory reply
 	ir := decredplugin.InventoryReply{
-		Comments: dc,
+		Comments:      dc,
+		ReactComments: rc,
 	}
 	irb, err := decredplugin.EncodeInventoryReply(ir)
 	if err != nil {
@@ -539,8 +725,14 @@ func (d *decred) Exec(cmd, cmdPayload, replyPayload string) (string, error) {
 		return d.cmdNewComment(cmdPayload, replyPayload)
 	case decredplugin.CmdLikeComment:
 		return d.cmdLikeComment(cmdPayload, replyPayload)
+	case decredplugin.CmdReactComment:
+		return d.cmdReactComment(cmdPayload, replyPayload)
 	case decredplugin.CmdCensorComment:
 		return d.cmdCensorComment(cmdPayload, replyPayload)
+	case decredplugin.CmdEditComment:
+		return d.cmdEditComment(cmdPayload, replyPayload)
+	case decredplugin.CmdDeleteComment:
+		return d.cmdDeleteComment(cmdPayload, replyPayload)
 	case decredplugin.CmdGetComment:
 		return d.cmdGetComment(cmdPayload)
 	case decredplugin.CmdGetComments:
@@ -551,6 +743,8 @@ func (d *decred) Exec(cmd, cmdPayload, replyPayload string) (string, error) {
 		return d.cmdCommentLikes(cmdPayload)
 	case decredplugin.CmdProposalCommentsLikes:
 		return d.cmdProposalCommentsLikes(cmdPayload)
+	case decredplugin.CmdCommentReactions:
+		return d.cmdCommentReactions(cmdPayload)
 	case decredplugin.CmdInventory:
 		return d.cmdInventory()
 	}
@@ -573,12 +767,24 @@ func createDecredTables(tx *gorm.DB) error {
 			return err
 		}
 	}
+	if !tx.HasTable(tableCommentFiles) {
+		err := tx.CreateTable(&CommentFile{}).Error
+		if err != nil {
+			return err
+		}
+	}
 	if !tx.HasTable(tableCommentLikes) {
 		err := tx.CreateTable(&LikeComment{}).Error
 		if err != nil {
 			return err
 		}
 	}
+	if !tx.HasTable(tableCommentReactions) {
+		err := tx.CreateTable(&CommentReaction{}).Error
+		if err != nil {
+			return err
+		}
+	}
 	if !tx.HasTable(tableCastVotes) {
 		err := tx.CreateTable(&CastVote{}).Error
 		if err != nil {
@@ -660,6 +866,20 @@ func (d *decred) build(tx *gorm.DB, ir *decredplugin.InventoryReply) error {
 		}
 	}
 
+	// Build comment reactions cache
+	for _, v := range ir.ReactComments {
+		cr := convertReactCommentFromDecred(v,
+			decredplugin.ReactCommentReply{
+				Receipt:   v.Receipt,
+				Timestamp: v.Timestamp,
+			})
+		err := d.newCommentReaction(tx, cr)
+		if err != nil {
+			log.Debugf("newCommentReaction failed on '%v'", cr)
+			return fmt.Errorf("newCommentReaction: %v", err)
+		}
+	}
+
 	// Put authorize vote replies in a map for quick lookups
 	avr := make(map[string]decredplugin.AuthorizeVoteReply,
 		len(ir.AuthorizeVoteReplies)) // [receipt]AuthorizeVote
@@ -719,8 +939,9 @@ func (d *decred) Build(payload string) error {
 
 	// Drop all decred plugin tables
 	err = d.recordsdb.DropTableIfExists(tableComments,
-		tableCommentLikes, tableCastVotes, tableAuthorizeVotes,
-		tableVoteOptions, tableStartVotes).Error
+		tableCommentFiles, tableCommentLikes, tableCommentReactions,
+		tableCastVotes,
+		tableAuthorizeVotes, tableVoteOptions, tableStartVotes).Error
 	if err != nil {
 		return fmt.Errorf("drop decred tables failed: %v", err)
 	}
@@ -64,19 +64,39 @@ func (Record) TableName() string {
 	return tableRecords
 }
 
+// CommentFile is a file that is attached to a decred plugin comment.
+type CommentFile struct {
+	Key        uint   `gorm:"primary_key"`      // Primary key
+	CommentKey string `gorm:"not null"`         // Comment foreign key
+	Name       string `gorm:"not null"`         // Basename of the file
+	MIME       string `gorm:"not null"`         // MIME type
+	Digest     string `gorm:"not null;size:64"` // SHA256 of decoded Payload
+	Payload    string `gorm:"not null"`         // base64 encoded file
+}
+
+// TableName returns the name of the CommentFile database table.
+func (CommentFile) TableName() string {
+	return tableCommentFiles
+}
+
 // Comment is a decred plugin comment, including all of the server side
 // metadata.
 type Comment struct {
-	Key       string `gorm:"primary_key"`       // Primary key (token+commentID)
-	Token     string `gorm:"not null;size:64"`  // Censorship token
-	ParentID  string `gorm:"not null"`          // Parent comment ID
-	Comment   string `gorm:"not null"`          // Comment
-	Signature string `gorm:"not null;size:128"` // Client Signature of Token+ParentID+Comment
-	PublicKey string `gorm:"not null;size:64"`  // Pubkey used for Signature
-	CommentID string `gorm:"not null"`          // Comment ID
-	Receipt   string `gorm:"not null"`          // Server signature of the client Signature
-	Timestamp int64  `gorm:"not null"`          // Received UNIX timestamp
-	Censored  bool   `gorm:"not null"`          // Has this comment been censored
+	Key          string `gorm:"primary_key"`       // Primary key (token+commentID)
+	Token        string `gorm:"not null;size:64"`  // Censorship token
+	ParentID     string `gorm:"not null"`          // Parent comment ID
+	Comment      string `gorm:"not null"`          // Comment
+	Signature    string `gorm:"not null;size:128"` // Client Signature of Token+ParentID+Comment+file digests
+	PublicKey    string `gorm:"not null;size:64"`  // Pubkey used for Signature
+	CommentID    string `gorm:"not null"`          // Comment ID
+	Receipt      string `gorm:"not null"`          // Server signature of the client Signature
+	Timestamp    int64  `gorm:"not null"`          // Received UNIX timestamp
+	Censored     bool   `gorm:"not null"`          // Has this comment been censored
+	Edited       int64  `gorm:"not null"`          // UNIX timestamp of last edit, 0 if never edited
+	CensorReason string `gorm:"not null"`          // Reason the comment was censored
+	Deleted      int64  `gorm:"not null"`          // UNIX timestamp the author deleted the comment, 0 if not deleted
+
+	Files []CommentFile `gorm:"foreignkey:CommentKey"` // Attached files
 }
 
 // TableName returns the name of the Comment database table.
@@ -100,6 +120,26 @@ func (LikeComment) TableName() string {
 	return tableCommentLikes
 }
 
+// CommentReaction is a decred plugin comment reaction.  Reactions are stored
+// in the order that they were received so that they can be replayed to
+// determine the current reactions to a comment.
+type CommentReaction struct {
+	Key       uint   `gorm:"primary_key"`       // Primary key
+	Token     string `gorm:"not null;size:64"`  // Censorship token
+	CommentID string `gorm:"not null"`          // Comment ID
+	Reaction  string `gorm:"not null"`          // Reaction type
+	Action    string `gorm:"not null"`          // Add or remove
+	Signature string `gorm:"not null;size:128"` // Client Signature of Token+CommentID+Reaction+Action
+	PublicKey string `gorm:"not null;size:64"`  // Public key used for Signature
+	Receipt   string `gorm:"not null;size:128"` // Server signature of client signature
+	Timestamp int64  `gorm:"not null"`          // Received UNIX timestamp
+}
+
+// TableName returns the name of the CommentReaction database table.
+func (CommentReaction) TableName() string {
+	return tableCommentReactions
+}
+
 // AuthorizeVote is a decred plugin metadata stream that is created by a
 // proposal author and is used to indicate that the proposal has been finalized
 // and is ready to be voted on.
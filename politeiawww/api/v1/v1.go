@@ -2,6 +2,7 @@ package v1
 
 import (
 	"fmt"
+	"strconv"
 )
 
 type ErrorStatusT int
@@ -9,13 +10,24 @@ type PropStateT int
 type PropStatusT int
 type PropVoteStatusT int
 type UserManageActionT int
+type UserEmailT int
+type APITokenScopeT int
 type EmailNotificationT int
+type RescanStatusT int
 
 const (
 	PoliteiaWWWAPIVersion = 1 // API version this backend understands
 
 	CsrfToken = "X-CSRF-Token"    // CSRF token for replies
 	Forward   = "X-Forwarded-For" // Proxy header
+	RequestID = "X-Request-ID"    // Correlation ID of a request
+
+	// Authorization is the header that carries the API token of a
+	// request in the form "Bearer <token>".  Requests that carry an API
+	// token are authenticated by the token instead of the session
+	// cookie and do not require a CSRF token.
+	Authorization  = "Authorization"
+	APITokenScheme = "Bearer"
 
 	RouteUserMe                   = "/user/me"
 	RouteNewUser                  = "/user/new"
@@ -23,18 +35,32 @@ const (
 	RouteResendVerification       = "/user/new/resend"
 	RouteUpdateUserKey            = "/user/key"
 	RouteVerifyUpdateUserKey      = "/user/key/verify"
+	RouteResendUpdateUserKey      = "/user/key/resend"
 	RouteChangeUsername           = "/user/username/change"
+	RouteUsernameAvailable        = "/user/username/available"
 	RouteChangePassword           = "/user/password/change"
+	RouteChangeEmail              = "/user/email/change"
+	RouteVerifyChangeEmail        = "/user/email/verify"
 	RouteResetPassword            = "/user/password/reset"
+	RouteVerifyResetPassword      = "/user/password/reset/verify"
 	RouteUserProposals            = "/user/proposals"
 	RouteUserProposalCredits      = "/user/proposals/credits"
 	RouteUserCommentsLikes        = "/user/proposals/{token:[A-z0-9]{64}}/commentslikes"
+	RouteUserComments             = "/user/comments"
+	RouteUserCommentedProposals   = "/user/comments/proposals"
 	RouteVerifyUserPayment        = "/user/verifypayment"
+	RouteVerifyUserPaymentTx      = "/user/verifypayment/tx"
 	RouteUserPaymentsRescan       = "/user/payments/rescan"
+	RouteUserPaymentsRescanStatus = "/user/payments/rescan/{jobid:[0-9a-f]{32}}"
 	RouteUserDetails              = "/user/{userid:[0-9a-zA-Z-]{36}}"
+	RouteBatchUserDetails         = "/users/details"
 	RouteManageUser               = "/user/manage"
+	RouteUserLogoutAll            = "/user/logoutall"
+	RouteResendUserEmail          = "/user/resendemail"
+	RouteImpersonateUser          = "/user/impersonate"
 	RouteEditUser                 = "/user/edit"
 	RouteUsers                    = "/users"
+	RoutePurgeUnverifiedUsers     = "/users/purgeunverified"
 	RouteLogin                    = "/login"
 	RouteLogout                   = "/logout"
 	RouteSecret                   = "/secret"
@@ -46,12 +72,21 @@ const (
 	RouteEditProposal             = "/proposals/edit"
 	RouteProposalDetails          = "/proposals/{token:[A-z0-9]{64}}"
 	RouteSetProposalStatus        = "/proposals/{token:[A-z0-9]{64}}/status"
+	RouteAbandonProposal          = "/proposals/abandon"
+	RouteBatchSetProposalStatus   = "/proposals/batchstatus"
 	RoutePolicy                   = "/policy"
 	RouteVersion                  = "/version"
+	RouteHealth                   = "/health"
+	RouteBlockHeight              = "/blockheight"
 	RouteNewComment               = "/comments/new"
 	RouteLikeComment              = "/comments/like"
 	RouteCensorComment            = "/comments/censor"
+	RouteEditComment              = "/comments/edit"
+	RouteDeleteComment            = "/comments/delete"
+	RouteReactComment             = "/comments/react"
 	RouteCommentsGet              = "/proposals/{token:[A-z0-9]{64}}/comments"
+	RouteCommentCount             = "/proposals/{token:[A-z0-9]{64}}/commentcount"
+	RouteBatchCommentCounts       = "/proposals/commentcounts"
 	RouteAuthorizeVote            = "/proposals/authorizevote"
 	RouteStartVote                = "/proposals/startvote"
 	RouteActiveVote               = "/proposals/activevote" // XXX rename to ActiveVotes
@@ -60,6 +95,27 @@ const (
 	RouteAllVoteStatus            = "/proposals/votestatus"
 	RouteVoteStatus               = "/proposals/{token:[A-z0-9]{64}}/votestatus"
 	RoutePropsStats               = "/proposals/stats"
+	RouteStatsHistory             = "/proposals/stats/history"
+	RouteInventoryStream          = "/proposals/inventory/stream"
+	RouteProposalBilling          = "/proposals/{token:[A-z0-9]{64}}/billing"
+	RouteProposalMetadata         = "/proposals/{token:[A-z0-9]{64}}/metadata"
+	RouteProposalBundle           = "/proposals/{token:[A-z0-9]{64}}/bundle"
+	RouteSetProposalBudget        = "/proposals/budget"
+	RouteRecordProposalSpend      = "/proposals/spend"
+	RouteNewDraft                 = "/drafts/new"
+	RouteEditDraft                = "/drafts/edit"
+	RouteDeleteDraft              = "/drafts/delete"
+	RouteDraftDetails             = "/drafts/{draftid:[0-9a-f]{32}}"
+	RouteUserDrafts               = "/user/drafts"
+	RouteNewUpload                = "/uploads/new"
+	RouteUploadChunk              = "/uploads/chunk"
+	RouteUploadStatus             = "/uploads/{uploadid:[0-9a-f]{32}}"
+	RouteWebhooks                 = "/webhooks"
+	RouteNewWebhook               = "/webhooks/new"
+	RouteDeleteWebhook            = "/webhooks/delete"
+	RouteAPITokens                = "/apitokens"
+	RouteNewAPIToken              = "/apitokens/new"
+	RouteRevokeAPIToken           = "/apitokens/revoke"
 	RouteUnauthenticatedWebSocket = "/ws"
 	RouteAuthenticatedWebSocket   = "/aws"
 
@@ -86,8 +142,9 @@ const (
 	// accepted when creating a new proposal
 	PolicyMaxMDSize = 512 * 1024
 
-	// PolicyMinPasswordLength is the minimum number of characters
-	// accepted for user passwords
+	// PolicyMinPasswordLength is the default minimum number of
+	// characters accepted for user passwords.  The minimum that a server
+	// accepts is returned by Policy.
 	PolicyMinPasswordLength = 8
 
 	// PolicyMaxUsernameLength is the max length of a username
@@ -106,6 +163,42 @@ const (
 	// accepted for comments
 	PolicyMaxCommentLength = 8000
 
+	// PolicyCommentEditPeriod is the number of seconds after a comment
+	// was submitted during which its author may edit it
+	PolicyCommentEditPeriod = 60 * 15
+
+	// PolicyCommentDeletePeriod is the number of seconds after a comment
+	// was submitted during which its author may delete it
+	PolicyCommentDeletePeriod = 60 * 60
+
+	// PolicyMinCensorReasonLength is the minimum number of characters
+	// accepted for the reason that a comment is censored
+	PolicyMinCensorReasonLength = 8
+
+	// PolicyMaxCommentFiles is the maximum number of files that can be
+	// attached to a comment
+	PolicyMaxCommentFiles = 2
+
+	// PolicyMaxCommentFileSize is the maximum size in bytes of a file
+	// that is attached to a comment
+	PolicyMaxCommentFileSize = 128 * 1024
+
+	// PolicyMaxDrafts is the maximum number of proposal drafts that a
+	// user can have saved at the same time
+	PolicyMaxDrafts = 10
+
+	// PolicyMaxDraftSize is the maximum combined size in bytes of the
+	// files of a proposal draft
+	PolicyMaxDraftSize = PolicyMaxMDSize + PolicyMaxImages*PolicyMaxImageSize
+
+	// UploadChunkSize is the maximum size in bytes of a single chunk of
+	// a staged upload
+	UploadChunkSize = 64 * 1024
+
+	// UploadExpiry is the number of seconds that a staged upload is
+	// kept after its most recent chunk was received
+	UploadExpiry = 60 * 60
+
 	// ProposalListPageSize is the maximum number of proposals returned
 	// for the routes that return lists of proposals
 	ProposalListPageSize = 20
@@ -114,6 +207,30 @@ const (
 	// for the routes that return lists of users
 	UserListPageSize = 20
 
+	// UserCommentsPageSize is the maximum number of comments returned
+	// by the UserComments route
+	UserCommentsPageSize = 20
+
+	// ProposalStatusBatchSize is the maximum number of status changes
+	// that can be sent in a single BatchSetProposalStatus command
+	ProposalStatusBatchSize = 20
+
+	// UserDetailsBatchSize is the maximum number of users that can be
+	// requested in a single BatchUserDetails command
+	UserDetailsBatchSize = 50
+
+	// CommentCountsBatchSize is the maximum number of proposals that
+	// can be requested in a single BatchCommentCounts command
+	CommentCountsBatchSize = 50
+
+	// StatsHistoryMaxBuckets is the maximum number of intervals that
+	// can be requested in a single StatsHistory command
+	StatsHistoryMaxBuckets = 1000
+
+	// ErrorMessageInternal is the error message that is returned for
+	// internal server errors
+	ErrorMessageInternal = "internal server error"
+
 	// Error status codes
 	ErrorStatusInvalid                     ErrorStatusT = 0
 	ErrorStatusInvalidEmailOrPassword      ErrorStatusT = 1
@@ -174,6 +291,52 @@ const (
 	ErrorStatusInvalidUUID                 ErrorStatusT = 56
 	ErrorStatusInvalidLikeCommentAction    ErrorStatusT = 57
 	ErrorStatusInvalidCensorshipToken      ErrorStatusT = 58
+	ErrorStatusSessionExpired              ErrorStatusT = 59
+	ErrorStatusUserNotCommentAuthor        ErrorStatusT = 60
+	ErrorStatusCommentEditPeriodExpired    ErrorStatusT = 61
+	ErrorStatusDuplicateEmail              ErrorStatusT = 62
+	ErrorStatusInvalidWebhookURL           ErrorStatusT = 63
+	ErrorStatusInvalidWebhookEvent         ErrorStatusT = 64
+	ErrorStatusWebhookNotFound             ErrorStatusT = 65
+	ErrorStatusRateLimitExceeded           ErrorStatusT = 66
+	ErrorStatusProposalNotApproved         ErrorStatusT = 67
+	ErrorStatusProposalBudgetNotSet        ErrorStatusT = 68
+	ErrorStatusProposalBudgetExceeded      ErrorStatusT = 69
+	ErrorStatusInvalidSpendAmount          ErrorStatusT = 70
+	ErrorStatusNoPendingUpdateUserKey      ErrorStatusT = 71
+	ErrorStatusInvalidUserEmailType        ErrorStatusT = 72
+	ErrorStatusEmailAlreadyVerified        ErrorStatusT = 73
+	ErrorStatusPaymentTxNotFound           ErrorStatusT = 74
+	ErrorStatusMaxCommentFilesExceeded     ErrorStatusT = 75
+	ErrorStatusMaxCommentFileSizeExceeded  ErrorStatusT = 76
+	ErrorStatusImpersonatedSession         ErrorStatusT = 77
+	ErrorStatusMaxDraftsExceeded           ErrorStatusT = 78
+	ErrorStatusMaxDraftSizeExceeded        ErrorStatusT = 79
+	ErrorStatusDraftNotFound               ErrorStatusT = 80
+	ErrorStatusInvalidProposalEdit         ErrorStatusT = 81
+	ErrorStatusUserPurgeDisabled           ErrorStatusT = 82
+	ErrorStatusInvalidAPIToken             ErrorStatusT = 83
+	ErrorStatusAPITokenScopeNotAllowed     ErrorStatusT = 84
+	ErrorStatusAPITokenNotFound            ErrorStatusT = 85
+	ErrorStatusInvalidAPITokenScope        ErrorStatusT = 86
+	ErrorStatusUploadNotFound              ErrorStatusT = 87
+	ErrorStatusInvalidUploadOffset         ErrorStatusT = 88
+	ErrorStatusUploadIncomplete            ErrorStatusT = 89
+	ErrorStatusInvalidUploadDigest         ErrorStatusT = 90
+	ErrorStatusMaxUploadsExceeded          ErrorStatusT = 91
+	ErrorStatusInvalidLocale               ErrorStatusT = 92
+	ErrorStatusPasswordMissingUpper        ErrorStatusT = 93
+	ErrorStatusPasswordMissingLower        ErrorStatusT = 94
+	ErrorStatusPasswordMissingDigit        ErrorStatusT = 95
+	ErrorStatusPasswordMissingSymbol       ErrorStatusT = 96
+	ErrorStatusPasswordBanned              ErrorStatusT = 97
+	ErrorStatusPasswordTooWeak             ErrorStatusT = 98
+	ErrorStatusRescanNotFound              ErrorStatusT = 99
+	ErrorStatusCommentDeletePeriodExpired  ErrorStatusT = 100
+	ErrorStatusInvalidCommentReaction      ErrorStatusT = 101
+	ErrorStatusInvalidLinkTo               ErrorStatusT = 102
+	ErrorStatusInvalidLinkBy               ErrorStatusT = 103
+	ErrorStatusRFPSubmissionsClosed        ErrorStatusT = 104
 
 	// Proposal state codes
 	//
@@ -218,11 +381,38 @@ const (
 	UserManageDeactivate                      UserManageActionT = 6
 	UserManageReactivate                      UserManageActionT = 7
 
+	// User email types that an admin can resend
+	UserEmailInvalid             UserEmailT = 0 // Invalid email type
+	UserEmailNewUserVerification UserEmailT = 1 // New user verification email
+	UserEmailResetPassword       UserEmailT = 2 // Reset password email
+
+	// API token scopes.  Every scope includes the requests that are
+	// allowed by the scopes before it.
+	APITokenScopeInvalid APITokenScopeT = 0 // Invalid scope
+	APITokenScopeRead    APITokenScopeT = 1 // GET requests
+	APITokenScopeSubmit  APITokenScopeT = 2 // All user requests
+	APITokenScopeAdmin   APITokenScopeT = 3 // All user and admin requests
+
+	// User payments rescan statuses
+	RescanStatusInvalid  RescanStatusT = 0 // Invalid status
+	RescanStatusRunning  RescanStatusT = 1 // Rescan is in progress
+	RescanStatusComplete RescanStatusT = 2 // Rescan has completed
+	RescanStatusFailed   RescanStatusT = 3 // Rescan has failed
+
 	// Authorize vote actions
 	// XXX these should be in decredplugin
 	AuthVoteActionAuthorize = "authorize" // Authorize a proposal vote
 	AuthVoteActionRevoke    = "revoke"    // Revoke a proposal vote authorization
 
+	// Comment reaction actions
+	ReactionActionAdd    = "add"    // Add a reaction to a comment
+	ReactionActionRemove = "remove" // Remove a reaction from a comment
+
+	// Comment reactions that are reserved for the up and down votes of
+	// LikeComment
+	ReactionUp   = "up"
+	ReactionDown = "down"
+
 	// Email notification types
 	NotificationEmailMyProposalStatusChange      EmailNotificationT = 1 << 0
 	NotificationEmailMyProposalVoteStarted       EmailNotificationT = 1 << 1
@@ -315,6 +505,52 @@ var (
 		ErrorStatusInvalidUUID:                 "invalid user UUID",
 		ErrorStatusInvalidLikeCommentAction:    "invalid like comment action",
 		ErrorStatusInvalidCensorshipToken:      "invalid proposal censorship token",
+		ErrorStatusSessionExpired:              "session expired",
+		ErrorStatusUserNotCommentAuthor:        "user is not the comment author",
+		ErrorStatusCommentEditPeriodExpired:    "comment edit period has expired",
+		ErrorStatusDuplicateEmail:              "email address is already in use",
+		ErrorStatusInvalidWebhookURL:           "invalid webhook URL",
+		ErrorStatusInvalidWebhookEvent:         "invalid webhook event",
+		ErrorStatusWebhookNotFound:             "webhook not found",
+		ErrorStatusRateLimitExceeded:           "rate limit exceeded",
+		ErrorStatusProposalNotApproved:         "proposal has not been approved",
+		ErrorStatusProposalBudgetNotSet:        "proposal budget has not been set",
+		ErrorStatusProposalBudgetExceeded:      "spend exceeds the remaining proposal budget",
+		ErrorStatusInvalidSpendAmount:          "invalid spend amount",
+		ErrorStatusNoPendingUpdateUserKey:      "no pending user key update",
+		ErrorStatusInvalidUserEmailType:        "invalid user email type",
+		ErrorStatusEmailAlreadyVerified:        "email already verified",
+		ErrorStatusPaymentTxNotFound:           "payment transaction not found",
+		ErrorStatusMaxCommentFilesExceeded:     "maximum number of comment files exceeded",
+		ErrorStatusMaxCommentFileSizeExceeded:  "maximum comment file size exceeded",
+		ErrorStatusImpersonatedSession:         "action is not allowed in an impersonated session",
+		ErrorStatusMaxDraftsExceeded:           "maximum number of drafts exceeded",
+		ErrorStatusMaxDraftSizeExceeded:        "maximum draft size exceeded",
+		ErrorStatusDraftNotFound:               "draft not found",
+		ErrorStatusInvalidProposalEdit:         "proposal edit does not continue the current version",
+		ErrorStatusUserPurgeDisabled:           "purging unverified users is disabled",
+		ErrorStatusInvalidAPIToken:             "invalid API token",
+		ErrorStatusAPITokenScopeNotAllowed:     "API token scope does not allow request",
+		ErrorStatusAPITokenNotFound:            "API token not found",
+		ErrorStatusInvalidAPITokenScope:        "invalid API token scope",
+		ErrorStatusUploadNotFound:              "upload not found",
+		ErrorStatusInvalidUploadOffset:         "invalid upload offset",
+		ErrorStatusUploadIncomplete:            "upload incomplete",
+		ErrorStatusInvalidUploadDigest:         "upload digest does not match",
+		ErrorStatusMaxUploadsExceeded:          "maximum number of uploads exceeded",
+		ErrorStatusInvalidLocale:               "invalid locale",
+		ErrorStatusPasswordMissingUpper:        "password must contain an upper case letter",
+		ErrorStatusPasswordMissingLower:        "password must contain a lower case letter",
+		ErrorStatusPasswordMissingDigit:        "password must contain a digit",
+		ErrorStatusPasswordMissingSymbol:       "password must contain a symbol",
+		ErrorStatusPasswordBanned:              "password is too common",
+		ErrorStatusPasswordTooWeak:             "password is too weak",
+		ErrorStatusRescanNotFound:              "rescan not found",
+		ErrorStatusCommentDeletePeriodExpired:  "comment delete period has expired",
+		ErrorStatusInvalidCommentReaction:      "invalid comment reaction",
+		ErrorStatusInvalidLinkTo:               "invalid proposal linkto",
+		ErrorStatusInvalidLinkBy:               "invalid proposal linkby",
+		ErrorStatusRFPSubmissionsClosed:        "rfp submission period has closed",
 	}
 
 	// PropStatus converts propsal status codes to human readable text
@@ -348,6 +584,30 @@ var (
 		UserManageDeactivate:                      "deactivate user",
 		UserManageReactivate:                      "reactivate user",
 	}
+
+	// UserEmail converts user email types to human readable text
+	UserEmail = map[UserEmailT]string{
+		UserEmailInvalid:             "invalid email type",
+		UserEmailNewUserVerification: "new user verification",
+		UserEmailResetPassword:       "reset password",
+	}
+
+	// APITokenScope converts API token scopes to human readable text
+	APITokenScope = map[APITokenScopeT]string{
+		APITokenScopeInvalid: "invalid scope",
+		APITokenScopeRead:    "read",
+		APITokenScopeSubmit:  "submit",
+		APITokenScopeAdmin:   "admin",
+	}
+
+	// RescanStatus converts user payments rescan statuses to human
+	// readable text
+	RescanStatus = map[RescanStatusT]string{
+		RescanStatusInvalid:  "invalid rescan status",
+		RescanStatusRunning:  "running",
+		RescanStatusComplete: "complete",
+		RescanStatusFailed:   "failed",
+	}
 )
 
 // File describes an individual file that is part of the proposal.  The
@@ -355,12 +615,17 @@ var (
 // and Digest.
 type File struct {
 	// Meta-data
-	Name   string `json:"name"`   // Suggested filename
-	MIME   string `json:"mime"`   // Mime type
-	Digest string `json:"digest"` // Digest of unencoded payload
+	Name   string `json:"name" validate:"required"`       // Suggested filename
+	MIME   string `json:"mime" validate:"required"`       // Mime type
+	Digest string `json:"digest" validate:"required,hex"` // Digest of unencoded payload
 
 	// Data
-	Payload string `json:"payload"` // File content, base64 encoded
+	Payload string `json:"payload" validate:"required_without=UploadID"` // File content, base64 encoded
+
+	// UploadID is the ID of a completed staged upload that contains the
+	// file content.  It replaces the payload of large files when a
+	// proposal is submitted; see NewUpload.
+	UploadID string `json:"uploadid,omitempty"`
 }
 
 // CensorshipRecord contains the proof that a proposal was accepted for review.
@@ -393,6 +658,8 @@ type ProposalRecord struct {
 	PublishedAt         int64       `json:"publishedat,omitempty"`         // The timestamp of when the proposal has been published
 	CensoredAt          int64       `json:"censoredat,omitempty"`          // The timestamp of when the proposal has been censored
 	AbandonedAt         int64       `json:"abandonedat,omitempty"`         // The timestamp of when the proposal has been abandoned
+	LinkTo              string      `json:"linkto,omitempty"`              // Token of the RFP that the proposal responds to
+	LinkBy              int64       `json:"linkby,omitempty"`              // Unix timestamp of the RFP submission deadline
 
 	CensorshipRecord CensorshipRecord `json:"censorshiprecord"`
 }
@@ -441,9 +708,12 @@ type PDErrorReply struct {
 // ErrorReply are replies that the server returns a when it encounters an
 // unrecoverable problem while executing a command.  The HTTP Error Code
 // shall be 500 if it's an internal server error or 4xx if it's a user error.
+// The ErrorCode of an internal server error is a tracking code that
+// identifies the error in the server log.
 type ErrorReply struct {
 	ErrorCode    int64    `json:"errorcode,omitempty"`
 	ErrorContext []string `json:"errorcontext,omitempty"`
+	ErrorMessage string   `json:"errormessage,omitempty"` // Generic description of the error code
 }
 
 // Version command is used to determine the version of the API this backend
@@ -462,13 +732,56 @@ type VersionReply struct {
 	TestNet bool   `json:"testnet"` // Network indicator
 }
 
+// Names of the components whose health is reported by the Health call.
+const (
+	HealthComponentUserDB = "userdb" // User database
+	HealthComponentCache  = "cache"  // Records cache
+)
+
+// Health requests the health of the server and of the services it depends
+// on.  It is meant to be used by load balancer health checks and readiness
+// probes.
+type Health struct{}
+
+// ComponentHealth is the health of a single service the server depends on.
+// The details of a failure are only written to the server log.
+type ComponentHealth struct {
+	Name    string `json:"name"`    // Component name
+	Healthy bool   `json:"healthy"` // Whether the component is reachable
+}
+
+// HealthReply is the reply to the Health call.  Healthy is only set when all
+// components are healthy.
+type HealthReply struct {
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentHealth `json:"components"`
+}
+
+// BlockHeight requests the best block of the Decred blockchain as seen by the
+// server.
+type BlockHeight struct{}
+
+// BlockHeightReply is the reply to the BlockHeight call.  Synced is unset
+// when the best block has not changed for longer than the server expects,
+// which means that the server is syncing or its view of the blockchain is
+// stale.  Vote and payment confirmation heights should not be relied on in
+// that case.
+type BlockHeightReply struct {
+	Height    uint64 `json:"height"`    // Best block height
+	Timestamp int64  `json:"timestamp"` // Unix time at which the server first saw the best block
+	Synced    bool   `json:"synced"`    // Whether the best block is current
+}
+
 // NewUser is used to request that a new user be created within the db.
 // If successful, the user will require verification before being able to login.
+// The password is sent in plain text so that the password policy can be
+// enforced; the user logs in with its hex encoded SHA3-256 digest.
 type NewUser struct {
-	Email     string `json:"email"`
-	Password  string `json:"password"`
-	PublicKey string `json:"publickey"`
-	Username  string `json:"username"`
+	Email     string `json:"email" validate:"required"`
+	Password  string `json:"password" validate:"required"`
+	PublicKey string `json:"publickey" validate:"required,hex"`
+	Username  string `json:"username" validate:"required"`
+	Locale    string `json:"locale,omitempty"` // Locale that emails are sent in
 }
 
 // NewUserReply is used to reply to the NewUser command with an error
@@ -501,7 +814,7 @@ type ResendVerificationReply struct {
 
 // UpdateUserKey is used to request a new active key.
 type UpdateUserKey struct {
-	PublicKey string `json:"publickey"`
+	PublicKey string `json:"publickey" validate:"required,hex"`
 }
 
 // UpdateUserKeyReply replies to the UpdateUserKey command.
@@ -518,6 +831,15 @@ type VerifyUpdateUserKey struct {
 // VerifyUpdateUserKeyReply replies to the VerifyUpdateUserKey command.
 type VerifyUpdateUserKeyReply struct{}
 
+// ResendUpdateUserKey is used to resend the verification email of a pending
+// key update.
+type ResendUpdateUserKey struct{}
+
+// ResendUpdateUserKeyReply replies to the ResendUpdateUserKey command.
+type ResendUpdateUserKeyReply struct {
+	VerificationToken string `json:"verificationtoken"` // Server verification token
+}
+
 // ChangeUsername is used to perform a username change while the user
 // is logged in.
 type ChangeUsername struct {
@@ -530,18 +852,43 @@ type ChangeUsername struct {
 type ChangeUsernameReply struct{}
 
 // ChangePassword is used to perform a password change while the user
-// is logged in.
+// is logged in.  The current password is sent the way it is sent on login;
+// the new password is sent in plain text so that the password policy can be
+// enforced.
 type ChangePassword struct {
-	CurrentPassword string `json:"currentpassword"`
-	NewPassword     string `json:"newpassword"`
+	CurrentPassword string `json:"currentpassword" validate:"required"`
+	NewPassword     string `json:"newpassword" validate:"required"`
 }
 
 // ChangePasswordReply is used to perform a password change while the user
 // is logged in.
 type ChangePasswordReply struct{}
 
+// ChangeEmail is used to request an email address change while the user is
+// logged in.  A verification token is sent to the new email address; the
+// email address is not changed until the token is verified.
+type ChangeEmail struct {
+	NewEmail string `json:"newemail"` // New email address
+	Password string `json:"password"` // Current password
+}
+
+// ChangeEmailReply replies to the ChangeEmail command.
+type ChangeEmailReply struct {
+	VerificationToken string `json:"verificationtoken"` // Server verification token
+}
+
+// VerifyChangeEmail is used to verify the new email address of a pending
+// email address change.
+type VerifyChangeEmail struct {
+	VerificationToken string `json:"verificationtoken"` // Server provided verification token
+}
+
+// VerifyChangeEmailReply replies to the VerifyChangeEmail command.
+type VerifyChangeEmailReply struct{}
+
 // ResetPassword is used to perform a password change when the
-// user is not logged in.
+// user is not logged in.  The new password is sent in plain text so that the
+// password policy can be enforced.
 type ResetPassword struct {
 	Email             string `json:"email"`
 	VerificationToken string `json:"verificationtoken"`
@@ -554,6 +901,19 @@ type ResetPasswordReply struct {
 	VerificationToken string `json:"verificationtoken"`
 }
 
+// VerifyResetPassword is used to check that a reset password verification
+// token is valid before the user enters a new password.  The token is not
+// consumed; it must still be submitted with ResetPassword to change the
+// password.
+type VerifyResetPassword struct {
+	Email             string `json:"email"`
+	VerificationToken string `json:"verificationtoken"`
+}
+
+// VerifyResetPasswordReply replies to the VerifyResetPassword command.  An
+// error is returned when the verification token is invalid or has expired.
+type VerifyResetPasswordReply struct{}
+
 // UserProposalCredits is used to request a list of all the user's unspent
 // proposal credits and a list of all of the user's spent proposal credits.
 // A spent credit means that the credit was used to submit a proposal.  Spent
@@ -568,17 +928,43 @@ type UserProposalCreditsReply struct {
 }
 
 // UserPaymentsRescan allows an admin to rescan a user's paywall address to
-// check for any payments that may have been missed by paywall polling. Any
-// proposal credits that are created as a result of the rescan are returned in
-// the UserPaymentsRescanReply. This call isn't RESTful, but a PUT request is
-// used since it's idempotent.
+// check for any payments that may have been missed by paywall polling.  The
+// rescan runs in the background; its progress and the proposal credits that
+// it creates are retrieved with UserPaymentsRescanStatus using the returned
+// job ID.  This call isn't RESTful, but a PUT request is used since it's
+// idempotent: the job ID of the running rescan is returned if the user is
+// already being rescanned and payments are never credited twice.
 type UserPaymentsRescan struct {
 	UserID string `json:"userid"` // ID of user to rescan
 }
 
 // UserPaymentsRescanReply is used to reply to the UserPaymentsRescan command.
+// The job ID is empty when the paywall is disabled.
 type UserPaymentsRescanReply struct {
-	NewCredits []ProposalCredit `json:"newcredits"` // Credits that were created by the rescan
+	JobID string `json:"jobid"` // Rescan job ID
+}
+
+// UserPaymentsRescanStatus retrieves the progress of a user payments rescan.
+// The job ID is part of the route.  The status of a rescan is kept for an hour
+// after it has ended.
+type UserPaymentsRescanStatus struct {
+	JobID string `json:"jobid"` // Rescan job ID
+}
+
+// UserPaymentsRescanStatusReply returns the progress of a user payments
+// rescan.  NewCredits is only set once the rescan is complete and Error is
+// only set when the rescan has failed.
+type UserPaymentsRescanStatusReply struct {
+	JobID            string           `json:"jobid"`            // Rescan job ID
+	UserID           string           `json:"userid"`           // ID of the user being rescanned
+	Status           RescanStatusT    `json:"status"`           // Rescan status
+	AddressesTotal   uint64           `json:"addressestotal"`   // Number of paywall addresses to scan
+	AddressesScanned uint64           `json:"addressesscanned"` // Number of paywall addresses scanned
+	PaymentsFound    uint64           `json:"paymentsfound"`    // Number of payments found so far
+	NewCredits       []ProposalCredit `json:"newcredits"`       // Credits that were created by the rescan
+	Error            string           `json:"error,omitempty"`  // Reason the rescan failed
+	Started          int64            `json:"started"`          // Start timestamp
+	Ended            int64            `json:"ended"`            // End timestamp; 0 while running
 }
 
 // UserProposals is used to request a list of proposals that the
@@ -593,6 +979,46 @@ type UserProposals struct {
 	After  string `schema:"after"`
 }
 
+// UserComments retrieves a page of the comments that a user has made across
+// all proposals.  The comments are sorted by timestamp, newest first, and the
+// page size is dictated by UserCommentsPageSize.  Pages start at 1; page 0 is
+// treated as page 1.  Only admins may retrieve the comments of other users.
+type UserComments struct {
+	UserID string `schema:"userid"`
+	Page   uint64 `schema:"page"`
+}
+
+// UserCommentsReply replies to the UserComments command with a page of the
+// comments that the user has made and the total number of comments that the
+// user has made.  The token of each comment identifies the proposal that it
+// was made on.
+type UserCommentsReply struct {
+	Comments      []Comment `json:"comments"`      // Page of user comments
+	TotalComments uint64    `json:"totalcomments"` // Number of comments made by the user
+}
+
+// UserCommentedProposals retrieves the proposals that a user has commented
+// on.  Only admins may retrieve the proposals that other users have commented
+// on.
+type UserCommentedProposals struct {
+	UserID string `schema:"userid"`
+}
+
+// UserCommentedProposal is a proposal that a user has commented on along with
+// the number of comments that the user has made on it.
+type UserCommentedProposal struct {
+	Token         string `json:"token"`         // Censorship token
+	NumComments   uint64 `json:"numcomments"`   // Number of comments made by the user
+	LatestComment int64  `json:"latestcomment"` // UNIX timestamp of the user's latest comment
+}
+
+// UserCommentedProposalsReply replies to the UserCommentedProposals command
+// with the proposals that the user has commented on, sorted by the timestamp
+// of the user's latest comment, newest first.
+type UserCommentedProposalsReply struct {
+	Proposals []UserCommentedProposal `json:"proposals"`
+}
+
 // UserProposalsReply replies to the UserProposals command with
 // a list of proposals that the user has submitted and the total
 // amount of proposals
@@ -609,15 +1035,59 @@ type VerifyUserPayment struct {
 
 type VerifyUserPaymentReply struct {
 	HasPaid            bool   `json:"haspaid"`
-	PaywallAddress     string `json:"paywalladdress"`     // Registration paywall address
-	PaywallAmount      uint64 `json:"paywallamount"`      // Registration paywall amount in atoms
-	PaywallTxNotBefore int64  `json:"paywalltxnotbefore"` // Minimum timestamp for paywall tx
-}
-
-// Users is used to request a list of users given a filter.
+	PaywallAddress     string `json:"paywalladdress"`          // Registration paywall address
+	PaywallAmount      uint64 `json:"paywallamount"`           // Registration paywall amount in atoms
+	PaywallTxNotBefore int64  `json:"paywalltxnotbefore"`      // Minimum timestamp for paywall tx
+	TxID               string `json:"txid,omitempty"`          // Payment tx that does not have enough confirmations yet
+	Confirmations      uint64 `json:"confirmations,omitempty"` // Number of confirmations of the payment tx
+}
+
+// VerifyUserPaymentTx is used to request the server to verify that the
+// provided transaction pays the registration fee of the logged in user.
+type VerifyUserPaymentTx struct {
+	TxID string `schema:"txid"` // Payment transaction ID
+}
+
+// VerifyUserPaymentTxReply replies to the VerifyUserPaymentTx command with a
+// breakdown of the provided transaction.  The user is marked as paid when the
+// transaction meets all of the paywall requirements; otherwise Errors lists
+// the requirements that were not met.
+type VerifyUserPaymentTxReply struct {
+	HasPaid            bool     `json:"haspaid"`
+	TxID               string   `json:"txid"`               // Payment transaction ID
+	PaywallAddress     string   `json:"paywalladdress"`     // Registration paywall address
+	PaywallAmount      uint64   `json:"paywallamount"`      // Registration paywall amount in atoms
+	PaywallTxNotBefore int64    `json:"paywalltxnotbefore"` // Minimum timestamp for paywall tx
+	AmountPaid         uint64   `json:"amountpaid"`         // Amount sent to the paywall address in atoms
+	Timestamp          int64    `json:"timestamp"`          // Transaction timestamp
+	Confirmations      uint64   `json:"confirmations"`      // Number of confirmations of the tx
+	MinConfirmations   uint64   `json:"minconfirmations"`   // Number of confirmations required
+	Errors             []string `json:"errors,omitempty"`   // Requirements that were not met
+}
+
+// UsernameAvailable is used to check whether a username can be used to
+// register a new user without attempting the registration.  The username is
+// normalized the same way that it is when a user is created.
+type UsernameAvailable struct {
+	Username string `schema:"username"`
+}
+
+// UsernameAvailableReply replies to the UsernameAvailable command.  When the
+// username is not available, Reason is ErrorStatusMalformedUsername if the
+// username does not adhere to the username policy and
+// ErrorStatusDuplicateUsername if it is already taken.
+type UsernameAvailableReply struct {
+	Available bool         `json:"available"`        // Whether the username can be registered
+	Reason    ErrorStatusT `json:"reason,omitempty"` // Why the username is not available
+}
+
+// Users is used to request a list of users given a filter.  Limit optionally
+// lowers the number of users returned; it is capped at UserListPageSize.
 type Users struct {
-	Username string `json:"username"` // String which should match or partially match a username
-	Email    string `json:"email"`    // String which should match or partially match an email
+	Username  string `json:"username"`                       // String which should match or partially match a username
+	Email     string `json:"email"`                          // String which should match or partially match an email
+	PublicKey string `json:"publickey"`                      // Active or past public key of the user
+	Limit     uint   `json:"limit" schema:"limit,omitempty"` // Maximum number of users to return
 }
 
 // UsersReply is a reply to the Users command, replying with a list of users.
@@ -637,8 +1107,8 @@ type AbridgedUser struct {
 // Login attempts to login the user.  Note that by necessity the password
 // travels in the clear.
 type Login struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 // LoginReply is used to reply to the Login command.
@@ -654,7 +1124,12 @@ type LoginReply struct {
 	PaywallTxID        string `json:"paywalltxid"`        // Paywall payment tx ID
 	ProposalCredits    uint64 `json:"proposalcredits"`    // Number of the proposal credits the user has available to spend
 	LastLoginTime      int64  `json:"lastlogintime"`      // Unix timestamp of last login date
-	SessionMaxAge      int64  `json:"sessionmaxage"`      // Unix timestamp of session max age
+	SessionMaxAge      int64  `json:"sessionmaxage"`      // Session max age in seconds
+	Locale             string `json:"locale,omitempty"`   // Locale that emails are sent in
+
+	// ImpersonatedBy is set to the user ID of the admin when the session
+	// is an impersonation session that was started by an admin.
+	ImpersonatedBy string `json:"impersonatedby,omitempty"`
 }
 
 //Logout attempts to log the user out.
@@ -694,10 +1169,30 @@ type ProposalPaywallPaymentReply struct {
 }
 
 // NewProposal attempts to submit a new proposal.
+//
+// A proposal that sets LinkBy is a request for proposals (RFP) and can only
+// be submitted by admins.  Proposals that are submitted in response to an RFP
+// set LinkTo to the token of the RFP.  A proposal can not set both.  The links
+// are covered by the signature; see ProposalSignatureMessage.
 type NewProposal struct {
-	Files     []File `json:"files"`     // Proposal files
-	PublicKey string `json:"publickey"` // Key used for signature.
-	Signature string `json:"signature"` // Signature of merkle root
+	Files     []File `json:"files" validate:"required"`         // Proposal files
+	PublicKey string `json:"publickey" validate:"required,hex"` // Key used for signature.
+	Signature string `json:"signature" validate:"required,hex"` // Signature of ProposalSignatureMessage
+	LinkTo    string `json:"linkto,omitempty"`                  // Token of the RFP that the proposal responds to
+	LinkBy    int64  `json:"linkby,omitempty"`                  // Unix timestamp of the RFP submission deadline
+}
+
+// ProposalSignatureMessage returns the message that the author of a proposal
+// signs: the hex encoded merkle root of the proposal files followed by the
+// RFP links of the proposal.  The links are only appended when the proposal
+// sets one of them, so proposals without links are signed over the merkle
+// root alone.  Edits are signed over the links of the proposal that is
+// edited since the links can not be changed.
+func ProposalSignatureMessage(merkle, linkTo string, linkBy int64) string {
+	if linkTo == "" && linkBy == 0 {
+		return merkle
+	}
+	return merkle + linkTo + strconv.FormatInt(linkBy, 10)
 }
 
 // NewProposalReply is used to reply to the NewProposal command
@@ -718,6 +1213,72 @@ type ProposalDetailsReply struct {
 	Proposal ProposalRecord `json:"proposal"`
 }
 
+// MetadataStream is a metadata stream of a proposal record as it is stored by
+// politeiad.  The payload is JSON encoded; see the API documentation for the
+// format of each stream.
+type MetadataStream struct {
+	ID      uint64 `json:"id"`      // Stream identity
+	Payload string `json:"payload"` // JSON encoded metadata
+}
+
+// ProposalMetadata retrieves all metadata streams of the latest version of a
+// proposal.
+type ProposalMetadata struct {
+	Token string `json:"token"` // Censorship token
+}
+
+// ProposalMetadataReply returns the metadata streams of a proposal, ordered
+// by stream ID.
+type ProposalMetadataReply struct {
+	Metadata []MetadataStream `json:"metadata"`
+}
+
+// ProposalBundleVersion is the version of the proposal bundle format.
+const ProposalBundleVersion = 2
+
+// Names of the entries of a proposal bundle.
+const (
+	BundleEntryManifest          = "manifest.json" // ProposalBundleManifest
+	BundleEntryManifestSignature = "manifest.sig"  // Server signature of the manifest
+	BundleEntryProposal          = "proposal.json" // ProposalRecord
+	BundleEntryComments          = "comments.json" // GetCommentsReply
+	BundleEntryVotes             = "votes.json"    // VoteResultsReply
+	BundleFilesDir               = "files/"        // Decoded proposal files
+	BundleMetadataDir            = "metadata/"     // Metadata stream payloads
+)
+
+// ProposalBundle retrieves a public proposal as a verifiable archive.
+type ProposalBundle struct {
+	Token string `json:"token"` // Censorship token
+}
+
+// ProposalBundleReply returns a tar archive that contains the latest version
+// of a proposal, its metadata streams, comments and vote results, and a
+// manifest of the digests of all entries.  The manifest is signed by the
+// server so that the archive can be verified offline.
+type ProposalBundleReply struct {
+	Bundle string `json:"bundle"` // Base64 encoded tar archive
+}
+
+// ProposalBundleManifest lists the SHA256 digest of every entry of a proposal
+// bundle other than the manifest and its signature.  The manifest signature
+// entry contains the hex encoded server signature of the SHA256 digest of the
+// manifest entry.
+type ProposalBundleManifest struct {
+	Version         uint                  `json:"version"`         // Bundle format version
+	Token           string                `json:"token"`           // Censorship token
+	ServerPublicKey string                `json:"serverpublickey"` // Server public key
+	Timestamp       int64                 `json:"timestamp"`       // Export UNIX timestamp
+	Entries         []ProposalBundleEntry `json:"entries"`         // Bundle entries
+}
+
+// ProposalBundleEntry describes a single entry of a proposal bundle.
+type ProposalBundleEntry struct {
+	Name   string `json:"name"`   // Entry name
+	Digest string `json:"digest"` // SHA256 digest of the contents
+	Size   int64  `json:"size"`   // Size of the contents in bytes
+}
+
 // SetProposalStatus is used to publish or censor an unreviewed proposal.
 type SetProposalStatus struct {
 	Token               string      `json:"token"`
@@ -732,6 +1293,47 @@ type SetProposalStatusReply struct {
 	Proposal ProposalRecord `json:"proposal"`
 }
 
+// BatchSetProposalStatus is used to set the status of multiple proposals.
+// Each status change is signed, validated and applied as if it were sent
+// using SetProposalStatus.  The maximum number of status changes is dictated
+// by ProposalStatusBatchSize.
+type BatchSetProposalStatus struct {
+	StatusChanges []SetProposalStatus `json:"statuschanges"`
+}
+
+// SetProposalStatusResult is the result of a single status change of a
+// BatchSetProposalStatus command.  Proposal is set when the status change
+// succeeded.  Error, and ErrorCode when the failure was caused by a user
+// error, are set when the status change failed.
+type SetProposalStatusResult struct {
+	Token     string          `json:"token"`               // Censorship token
+	Proposal  *ProposalRecord `json:"proposal,omitempty"`  // Updated proposal
+	ErrorCode ErrorStatusT    `json:"errorcode,omitempty"` // User error code
+	Error     string          `json:"error,omitempty"`     // Error message
+}
+
+// BatchSetProposalStatusReply is used to reply to a BatchSetProposalStatus
+// command.  The results are in the same order as the status changes.
+type BatchSetProposalStatusReply struct {
+	Results []SetProposalStatusResult `json:"results"`
+}
+
+// AbandonProposal is used by the author of a proposal to withdraw it.  A
+// public proposal is abandoned as long as its vote has not been authorized or
+// started.  An unreviewed proposal is censored.  The status change records the
+// public key of the author instead of an admin key.
+type AbandonProposal struct {
+	Token     string `json:"token"`     // Censorship token
+	Message   string `json:"message"`   // Reason for abandoning the proposal
+	Signature string `json:"signature"` // Signature of Token+Message
+	PublicKey string `json:"publickey"` // Public key used for signature
+}
+
+// AbandonProposalReply is used to reply to an AbandonProposal command.
+type AbandonProposalReply struct {
+	Proposal ProposalRecord `json:"proposal"`
+}
+
 // GetAllUnvetted retrieves all unvetted proposals; the maximum number returned
 // is dictated by ProposalListPageSize. This command optionally takes either
 // a Before or After parameter, which specify a proposal's censorship token.
@@ -755,10 +1357,12 @@ type GetAllUnvettedReply struct {
 // parameter, which specify a proposal's censorship token. If After is specified,
 // the "page" returned starts after the proposal whose censorship token is provided.
 // If Before is specified, the "page" returned starts before the proposal whose
-// censorship token is provided.
+// censorship token is provided.  Limit optionally lowers the number of proposals
+// returned; it is capped at ProposalListPageSize.
 type GetAllVetted struct {
 	Before string `schema:"before"`
 	After  string `schema:"after"`
+	Limit  uint   `schema:"limit,omitempty"`
 }
 
 // GetAllVettedReply is used to reply with a list of vetted proposals.
@@ -774,6 +1378,11 @@ type Policy struct{}
 // the file upload restrictions set for Politeia.
 type PolicyReply struct {
 	MinPasswordLength          uint     `json:"minpasswordlength"`
+	PasswordRequireUpper       bool     `json:"passwordrequireupper"`
+	PasswordRequireLower       bool     `json:"passwordrequirelower"`
+	PasswordRequireDigit       bool     `json:"passwordrequiredigit"`
+	PasswordRequireSymbol      bool     `json:"passwordrequiresymbol"`
+	PasswordMinStrength        uint     `json:"passwordminstrength"`
 	MinUsernameLength          uint     `json:"minusernamelength"`
 	MaxUsernameLength          uint     `json:"maxusernamelength"`
 	UsernameSupportedChars     []string `json:"usernamesupportedchars"`
@@ -788,6 +1397,15 @@ type PolicyReply struct {
 	MaxProposalNameLength      uint     `json:"maxproposalnamelength"`
 	ProposalNameSupportedChars []string `json:"proposalnamesupportedchars"`
 	MaxCommentLength           uint     `json:"maxcommentlength"`
+	CommentEditPeriod          uint     `json:"commenteditperiod"`
+	CommentDeletePeriod        uint     `json:"commentdeleteperiod"`
+	CommentReactions           []string `json:"commentreactions"`
+	MinCensorReasonLength      uint     `json:"mincensorreasonlength"`
+	MaxCommentFiles            uint     `json:"maxcommentfiles"`
+	MaxCommentFileSize         uint     `json:"maxcommentfilesize"`
+	MaxDrafts                  uint     `json:"maxdrafts"`
+	MaxDraftSize               uint     `json:"maxdraftsize"`
+	ListLimit                  bool     `json:"listlimit"`
 	BackendPublicKey           string   `json:"backendpublickey"`
 }
 
@@ -830,10 +1448,10 @@ type ActiveVoteReply struct {
 // proposal author.  The author can revoke a previously sent vote authorization
 // by setting the Action field to revoke.
 type AuthorizeVote struct {
-	Action    string `json:"action"`    // Authorize or revoke
-	Token     string `json:"token"`     // Proposal token
-	Signature string `json:"signature"` // Signature of token+version+action
-	PublicKey string `json:"publickey"` // Key used for signature
+	Action    string `json:"action" validate:"required"`           // Authorize or revoke
+	Token     string `json:"token" validate:"required,hex,len=64"` // Proposal token
+	Signature string `json:"signature" validate:"required,hex"`    // Signature of token+version+action
+	PublicKey string `json:"publickey" validate:"required,hex"`    // Key used for signature
 }
 
 // AuthorizeVoteReply returns a receipt if the action was successfully
@@ -898,23 +1516,28 @@ type VoteResultsReply struct {
 // includes server side meta-data as well.
 type Comment struct {
 	// Data generated by client
-	Token     string `json:"token"`     // Censorship token
-	ParentID  string `json:"parentid"`  // Parent comment ID
-	Comment   string `json:"comment"`   // Comment
-	Signature string `json:"signature"` // Client Signature of Token+ParentID+Comment
-	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Token     string `json:"token"`           // Censorship token
+	ParentID  string `json:"parentid"`        // Parent comment ID
+	Comment   string `json:"comment"`         // Comment
+	Signature string `json:"signature"`       // Client Signature of Token+ParentID+Comment+file digests or Token+CommentID+Comment+file digests if edited
+	PublicKey string `json:"publickey"`       // Pubkey used for Signature
+	Files     []File `json:"files,omitempty"` // Attached files
 
 	// Metadata generated by decred plugin
-	CommentID   string `json:"commentid"`   // Comment ID
-	Receipt     string `json:"receipt"`     // Server signature of the client Signature
-	Timestamp   int64  `json:"timestamp"`   // Received UNIX timestamp
-	TotalVotes  uint64 `json:"totalvotes"`  // Total number of up/down votes
-	ResultVotes int64  `json:"resultvotes"` // Vote score
-	Censored    bool   `json:"censored"`    // Has this comment been censored
+	CommentID    string `json:"commentid"`              // Comment ID
+	Receipt      string `json:"receipt"`                // Server signature of the client Signature
+	Timestamp    int64  `json:"timestamp"`              // Received UNIX timestamp
+	TotalVotes   uint64 `json:"totalvotes"`             // Total number of up/down votes
+	ResultVotes  int64  `json:"resultvotes"`            // Vote score
+	Censored     bool   `json:"censored"`               // Has this comment been censored
+	Edited       int64  `json:"edited"`                 // UNIX timestamp of last edit, 0 if never edited
+	CensorReason string `json:"censorreason,omitempty"` // Reason the comment was censored
+	Deleted      int64  `json:"deleted"`                // UNIX timestamp the author deleted the comment, 0 if not deleted
 
 	// Metadata generated by www
-	UserID   string `json:"userid"`   // User id
-	Username string `json:"username"` // Username
+	UserID    string            `json:"userid"`              // User id
+	Username  string            `json:"username"`            // Username
+	Reactions map[string]uint64 `json:"reactions,omitempty"` // Number of users per reaction type
 }
 
 // NewComment sends a comment from a user to a specific proposal.  Note that
@@ -922,11 +1545,12 @@ type Comment struct {
 // comment does not have a parent.  A non-zero parent ID indicates that the
 // comment is a reply to an existing comment.
 type NewComment struct {
-	Token     string `json:"token"`     // Censorship token
-	ParentID  string `json:"parentid"`  // Parent comment ID
-	Comment   string `json:"comment"`   // Comment
-	Signature string `json:"signature"` // Client Signature of Token+ParentID+Comment
-	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Token     string `json:"token" validate:"required,hex,len=64"` // Censorship token
+	ParentID  string `json:"parentid"`                             // Parent comment ID
+	Comment   string `json:"comment" validate:"required"`          // Comment
+	Signature string `json:"signature" validate:"required,hex"`    // Client Signature of Token+ParentID+Comment+file digests
+	PublicKey string `json:"publickey" validate:"required,hex"`    // Pubkey used for Signature
+	Files     []File `json:"files,omitempty"`                      // Attached files
 }
 
 // NewCommentReply returns the site generated Comment ID or an error if
@@ -935,9 +1559,13 @@ type NewCommentReply struct {
 	Comment Comment `json:"comment"` // Comment + receipt
 }
 
-// GetComments retrieve all comments for a given proposal.
+// GetComments retrieve all comments for a given proposal.  The token is part
+// of the route.  When Since is set, only the comments that were created or
+// edited after the given UNIX timestamp are returned.  Censorship and vote
+// score changes do not update a comment's timestamps.
 type GetComments struct {
-	Token string `json:"token"` // Censorship token
+	Token string `json:"token" schema:"-"`                         // Censorship token
+	Since int64  `json:"since,omitempty" schema:"since,omitempty"` // UNIX timestamp
 }
 
 // GetCommentsReply returns the provided number of comments.
@@ -946,13 +1574,40 @@ type GetCommentsReply struct {
 	AccessTime int64     `json:"accesstime,omitempty"` // User Access Time
 }
 
+// CommentCount describes the number of comments of a proposal without the
+// comments themselves.  The number of comments includes censored and deleted
+// comments, the same as ProposalRecord.NumComments.
+type CommentCount struct {
+	NumComments uint  `json:"numcomments"` // Number of comments
+	LastComment int64 `json:"lastcomment"` // UNIX timestamp of the most recent comment, 0 if there are no comments
+}
+
+// CommentCountReply returns the comment count of the proposal whose token is
+// part of the route.
+type CommentCountReply struct {
+	CommentCount CommentCount `json:"commentcount"`
+}
+
+// BatchCommentCounts fetches the comment counts of multiple proposals.  The
+// maximum number of proposals is dictated by CommentCountsBatchSize.
+type BatchCommentCounts struct {
+	Tokens []string `json:"tokens"` // Censorship tokens
+}
+
+// BatchCommentCountsReply returns the comment counts of the requested
+// proposals keyed by censorship token.  Proposals without comments have a
+// zero comment count.
+type BatchCommentCountsReply struct {
+	CommentCounts map[string]CommentCount `json:"commentcounts"` // [token]CommentCount
+}
+
 // LikeComment allows a user to up or down vote a comment.
 type LikeComment struct {
-	Token     string `json:"token"`     // Censorship token
-	CommentID string `json:"commentid"` // Comment ID
-	Action    string `json:"action"`    // Up or downvote (1, -1)
-	Signature string `json:"signature"` // Client Signature of Token+CommentID+Action
-	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Token     string `json:"token" validate:"required,hex,len=64"` // Censorship token
+	CommentID string `json:"commentid" validate:"required"`        // Comment ID
+	Action    string `json:"action" validate:"required"`           // Up or downvote (1, -1)
+	Signature string `json:"signature" validate:"required,hex"`    // Client Signature of Token+CommentID+Action
+	PublicKey string `json:"publickey" validate:"required,hex"`    // Pubkey used for Signature
 }
 
 // LikeCommentReply returns the current up/down vote result.
@@ -967,11 +1622,11 @@ type LikeCommentReply struct {
 // CensorComment allows an admin to censor a comment. The signature and
 // public key are from the admin that censored this comment.
 type CensorComment struct {
-	Token     string `json:"token"`     // Proposal censorship token
-	CommentID string `json:"commentid"` // Comment ID
-	Reason    string `json:"reason"`    // Reason the comment was censored
-	Signature string `json:"signature"` // Client signature of Token+CommentID+Reason
-	PublicKey string `json:"publickey"` // Pubkey used for signature
+	Token     string `json:"token" validate:"required,hex,len=64"` // Proposal censorship token
+	CommentID string `json:"commentid" validate:"required"`        // Comment ID
+	Reason    string `json:"reason"`                               // Reason the comment was censored
+	Signature string `json:"signature" validate:"required,hex"`    // Client signature of Token+CommentID+Reason
+	PublicKey string `json:"publickey" validate:"required,hex"`    // Pubkey used for signature
 }
 
 // CensorCommentReply returns a receipt if the comment was successfully
@@ -980,6 +1635,62 @@ type CensorCommentReply struct {
 	Receipt string `json:"receipt"` // Server signature of client signature
 }
 
+// EditComment allows the author of a comment to replace the comment text.
+// A comment may only be edited within PolicyCommentEditPeriod seconds of
+// being submitted.
+type EditComment struct {
+	Token     string `json:"token" validate:"required,hex,len=64"` // Proposal censorship token
+	CommentID string `json:"commentid" validate:"required"`        // Comment ID
+	Comment   string `json:"comment" validate:"required"`          // New comment text
+	Signature string `json:"signature" validate:"required,hex"`    // Client signature of Token+CommentID+Comment+file digests
+	PublicKey string `json:"publickey" validate:"required,hex"`    // Pubkey used for signature
+}
+
+// EditCommentReply returns the edited comment.
+type EditCommentReply struct {
+	Comment Comment `json:"comment"` // Comment + receipt
+}
+
+// DeleteComment allows the author of a comment to delete it.  A comment may
+// only be deleted within PolicyCommentDeletePeriod seconds of being
+// submitted.  A deleted comment remains in the comment thread with its text
+// and files removed so that its replies keep their parent.  A comment that
+// was deleted by its author has Deleted set whereas a comment that was
+// removed by an admin has Censored set.
+type DeleteComment struct {
+	Token     string `json:"token" validate:"required,hex,len=64"` // Proposal censorship token
+	CommentID string `json:"commentid" validate:"required"`        // Comment ID
+	Signature string `json:"signature" validate:"required,hex"`    // Client signature of Token+CommentID
+	PublicKey string `json:"publickey" validate:"required,hex"`    // Pubkey used for signature
+}
+
+// DeleteCommentReply returns the deleted comment and the receipt of the
+// deletion.
+type DeleteCommentReply struct {
+	Comment Comment `json:"comment"` // Deleted comment
+	Receipt string  `json:"receipt"` // Server signature of client signature
+}
+
+// ReactComment allows a user to add or remove a reaction to a comment.  The
+// reaction must be one of the reaction types returned by the policy route.
+// Reactions are kept separate from the up and down votes of LikeComment; a
+// user either has or does not have each reaction type on a comment.
+type ReactComment struct {
+	Token     string `json:"token" validate:"required,hex,len=64"` // Censorship token
+	CommentID string `json:"commentid" validate:"required"`        // Comment ID
+	Reaction  string `json:"reaction" validate:"required"`         // Reaction type
+	Action    string `json:"action" validate:"required"`           // Add or remove
+	Signature string `json:"signature" validate:"required,hex"`    // Client Signature of Token+CommentID+Reaction+Action
+	PublicKey string `json:"publickey" validate:"required,hex"`    // Pubkey used for Signature
+}
+
+// ReactCommentReply returns the number of users that reacted to the comment
+// with each reaction type after the reaction was applied.
+type ReactCommentReply struct {
+	Reactions map[string]uint64 `json:"reactions"` // Number of users per reaction type
+	Receipt   string            `json:"receipt"`   // Server signature of client signature
+}
+
 // CommentLike describes the voting action an user has given
 // to a comment (e.g: up or down vote)
 type CommentLike struct {
@@ -1039,6 +1750,20 @@ type UserDetailsReply struct {
 	User User `json:"user"`
 }
 
+// BatchUserDetails fetches the details of multiple users.  The maximum number
+// of users is dictated by UserDetailsBatchSize.  Users are filtered the same
+// way as UserDetails; only the public fields are returned unless the caller
+// is an admin or is the user.
+type BatchUserDetails struct {
+	UserIDs []string `json:"userids"` // User ids
+}
+
+// BatchUserDetailsReply returns the details of the requested users keyed by
+// user id.  Users that do not exist are not included.
+type BatchUserDetailsReply struct {
+	Users map[string]User `json:"users"` // [userID]User
+}
+
 // ManageUser performs the given action on a user.
 type ManageUser struct {
 	UserID string            `json:"userid"` // User id
@@ -1049,9 +1774,64 @@ type ManageUser struct {
 // ManageUserReply is the reply for the ManageUserReply command.
 type ManageUserReply struct{}
 
+// UserLogoutAll logs a user out of all of their sessions.  This is an admin
+// only command.
+type UserLogoutAll struct {
+	UserID string `json:"userid"` // User id
+}
+
+// UserLogoutAllReply is the reply for the UserLogoutAll command.
+type UserLogoutAllReply struct {
+	SessionsRemoved int `json:"sessionsremoved"` // Number of sessions removed
+}
+
+// ImpersonateUser starts an impersonation session for the specified user so
+// that an admin can see exactly what the user sees for support purposes.  The
+// session replaces the session of the admin, expires after a short, fixed
+// amount of time and only allows read-only requests.  The admin and the reason
+// are recorded in the admin log.
+type ImpersonateUser struct {
+	UserID string `json:"userid"` // User id
+	Reason string `json:"reason"` // Admin reason for action
+}
+
+// ImpersonateUserReply is the reply for the ImpersonateUser command.
+type ImpersonateUserReply struct {
+	User      LoginReply `json:"user"`      // Impersonated user
+	ExpiresAt int64      `json:"expiresat"` // Unix timestamp of session expiry
+}
+
+// ResendUserEmail regenerates the verification token of the given email type
+// and sends the email to the address of the user, ignoring the cooldown that
+// applies when the user requests the email.  The token is never returned to
+// the admin.  This is an admin only command.
+type ResendUserEmail struct {
+	UserID string     `json:"userid"` // User id
+	Email  UserEmailT `json:"email"`  // Email type
+	Reason string     `json:"reason"` // Admin reason for action
+}
+
+// ResendUserEmailReply is the reply for the ResendUserEmail command.
+type ResendUserEmailReply struct{}
+
+// PurgeUnverifiedUsers permanently deletes the users that never verified
+// their email address and whose verification token expired more than the
+// configured unverified user age ago.  Users that have submitted proposals or
+// comments, have made a payment or are admins are never deleted.  The email
+// address and username of a deleted user become available again.  This is an
+// admin only command.
+type PurgeUnverifiedUsers struct{}
+
+// PurgeUnverifiedUsersReply is the reply for the PurgeUnverifiedUsers
+// command.
+type PurgeUnverifiedUsersReply struct {
+	Users []AbridgedUser `json:"users"` // Users that were deleted
+}
+
 // EditUser edits a user's preferences.
 type EditUser struct {
 	EmailNotifications *uint64 `json:"emailnotifications"` // Notify the user via emails
+	Locale             *string `json:"locale,omitempty"`   // Locale that emails are sent in
 }
 
 // EditUserReply is the reply for the EditUser command.
@@ -1081,20 +1861,23 @@ type User struct {
 	Identities                      []UserIdentity `json:"identities"`
 	ProposalCredits                 uint64         `json:"proposalcredits"`
 	EmailNotifications              uint64         `json:"emailnotifications"` // Notify the user via emails
+	Locale                          string         `json:"locale,omitempty"`   // Locale that emails are sent in
 }
 
 // UserIdentity represents a user's unique identity.
 type UserIdentity struct {
-	Pubkey string `json:"pubkey"`
-	Active bool   `json:"isactive"`
+	Pubkey      string `json:"pubkey"`
+	Active      bool   `json:"isactive"`
+	Activated   int64  `json:"activated"`   // Unix timestamp of key activation
+	Deactivated int64  `json:"deactivated"` // Unix timestamp of key deactivation
 }
 
 // EditProposal attempts to edit a proposal
 type EditProposal struct {
-	Token     string `json:"token"`
-	Files     []File `json:"files"`
-	PublicKey string `json:"publickey"`
-	Signature string `json:"signature"`
+	Token     string `json:"token" validate:"required,hex,len=64"`
+	Files     []File `json:"files" validate:"required"`
+	PublicKey string `json:"publickey" validate:"required,hex"`
+	Signature string `json:"signature" validate:"required,hex"`
 }
 
 // EditProposalReply is used to reply to the EditProposal command
@@ -1114,6 +1897,350 @@ type ProposalsStatsReply struct {
 	NumOfAbandoned       int `json:"numofabandoned"`       // Counting number of abandoned proposals
 }
 
+// StatsHistory is a command to fetch the proposal and user activity stats
+// bucketed by time.  The time range from From up to, but not including, To is
+// divided into buckets of Interval seconds.  The last bucket is cut short
+// when the range is not a multiple of the interval.
+type StatsHistory struct {
+	From     int64 `schema:"from"`     // Start of the range (UNIX timestamp)
+	To       int64 `schema:"to"`       // End of the range (UNIX timestamp)
+	Interval int64 `schema:"interval"` // Bucket size in seconds
+}
+
+// StatsBucket contains the stats of a single time interval.  A proposal vote
+// is counted in the interval that the vote ended in.  Vote end times are
+// estimated from the vote end block height.
+type StatsBucket struct {
+	Start            int64   `json:"start"`            // Start of the interval (UNIX timestamp)
+	End              int64   `json:"end"`              // End of the interval (UNIX timestamp)
+	NumOfSubmitted   int     `json:"numofsubmitted"`   // Number of proposals submitted
+	NumOfApproved    int     `json:"numofapproved"`    // Number of proposal votes that passed
+	NumOfRejected    int     `json:"numofrejected"`    // Number of proposal votes that failed
+	ApprovalRate     float64 `json:"approvalrate"`     // Share of finished votes that passed
+	NumOfActiveUsers int     `json:"numofactiveusers"` // Users that submitted a proposal or comment
+}
+
+// StatsHistoryReply returns the stats buckets in chronological order.
+type StatsHistoryReply struct {
+	Buckets []StatsBucket `json:"buckets"`
+}
+
+// Inventory stream events
+const (
+	InventoryEventProposalSubmitted    = "proposalsubmitted"    // Proposal was submitted
+	InventoryEventProposalStatusChange = "proposalstatuschange" // Proposal status changed
+)
+
+// InventoryEvent is sent on the inventory stream when a proposal is submitted
+// or its status changes.  The stream is a text/event-stream in which the event
+// field of each server-sent event contains the inventory event and the data
+// field contains the JSON encoded InventoryEvent.
+//
+// Users that are not admins only receive status changes of vetted proposals.
+type InventoryEvent struct {
+	Event     string      `json:"event"`     // Event that occurred
+	Timestamp int64       `json:"timestamp"` // Event timestamp
+	Token     string      `json:"token"`     // Proposal censorship token
+	Name      string      `json:"name"`      // Proposal name
+	Status    PropStatusT `json:"status"`    // Proposal status
+}
+
+// Webhook events
+const (
+	WebhookEventProposalSubmitted = "proposalsubmitted" // Proposal was submitted
+	WebhookEventProposalPublic    = "proposalpublic"    // Proposal was made public
+	WebhookEventProposalCensored  = "proposalcensored"  // Proposal was censored
+	WebhookEventProposalAbandoned = "proposalabandoned" // Proposal was abandoned
+	WebhookEventProposalWithdrawn = "proposalwithdrawn" // Proposal was withdrawn by its author
+	WebhookEventVoteStarted       = "votestarted"       // Proposal vote was started
+	WebhookEventProposalApproved  = "proposalapproved"  // Proposal vote finished approved
+
+	// WebhookEventHeader is the HTTP header that contains the event of
+	// a webhook delivery.
+	WebhookEventHeader = "X-Politeia-Event"
+
+	// WebhookSignatureHeader is the HTTP header that contains the hex
+	// encoded HMAC-SHA256 of the webhook delivery body, keyed with the
+	// webhook secret string.
+	WebhookSignatureHeader = "X-Politeia-Signature"
+)
+
+// ProposalSpend is a payment that was made against the budget of an approved
+// proposal.  Amounts are in US cents.
+type ProposalSpend struct {
+	ID          string `json:"id"`          // Unique spend ID
+	Amount      uint64 `json:"amount"`      // Amount spent in US cents
+	Description string `json:"description"` // What the amount was spent on
+	AdminID     string `json:"adminid"`     // ID of the admin that recorded the spend
+	Timestamp   int64  `json:"timestamp"`   // Time the spend was recorded
+}
+
+// ProposalBilling retrieves the billing status of an approved proposal.
+type ProposalBilling struct {
+	Token string `json:"token"` // Censorship token
+}
+
+// ProposalBillingReply returns the approved budget of a proposal along with
+// the spends that were recorded against it.  Remaining is negative when the
+// proposal has been overspent.
+type ProposalBillingReply struct {
+	Token     string          `json:"token"`     // Censorship token
+	Budget    uint64          `json:"budget"`    // Approved budget in US cents
+	Spent     uint64          `json:"spent"`     // Total amount spent in US cents
+	Remaining int64           `json:"remaining"` // Budget minus spent in US cents
+	Spends    []ProposalSpend `json:"spends"`    // Spends, oldest first
+}
+
+// SetProposalBudget sets the approved budget of a proposal.  The proposal
+// vote must have been approved.  This command requires admin privileges.
+type SetProposalBudget struct {
+	Token  string `json:"token"`  // Censorship token
+	Budget uint64 `json:"budget"` // Approved budget in US cents
+}
+
+// SetProposalBudgetReply returns the billing status of the proposal with the
+// new budget.
+type SetProposalBudgetReply struct {
+	Billing ProposalBillingReply `json:"billing"`
+}
+
+// RecordProposalSpend records a spend against the budget of an approved
+// proposal.  The spend is rejected if it exceeds the remaining budget unless
+// AllowOverspend is set.  This command requires admin privileges.
+type RecordProposalSpend struct {
+	Token          string `json:"token"`          // Censorship token
+	Amount         uint64 `json:"amount"`         // Amount spent in US cents
+	Description    string `json:"description"`    // What the amount was spent on
+	AllowOverspend bool   `json:"allowoverspend"` // Allow exceeding the budget
+}
+
+// RecordProposalSpendReply returns the recorded spend and the billing status
+// of the proposal that includes it.
+type RecordProposalSpendReply struct {
+	Spend   ProposalSpend        `json:"spend"`
+	Billing ProposalBillingReply `json:"billing"`
+}
+
+// Webhook is an endpoint that is notified, using a POST request with a
+// WebhookDelivery body, when one of the subscribed events occurs.
+type Webhook struct {
+	ID        string   `json:"id"`        // Webhook ID
+	URL       string   `json:"url"`       // Endpoint URL
+	Events    []string `json:"events"`    // Subscribed events
+	Timestamp int64    `json:"timestamp"` // Creation timestamp
+}
+
+// WebhookDelivery is the body of the POST request that is sent to a webhook
+// endpoint when a subscribed event occurs.
+type WebhookDelivery struct {
+	ID        string      `json:"id"`        // Unique delivery ID
+	Event     string      `json:"event"`     // Event that occurred
+	Timestamp int64       `json:"timestamp"` // Event timestamp
+	Token     string      `json:"token"`     // Proposal censorship token
+	Name      string      `json:"name"`      // Proposal name
+	Status    PropStatusT `json:"status"`    // Proposal status
+}
+
+// NewWebhook registers a new webhook.  Events must contain at least one
+// valid webhook event.
+type NewWebhook struct {
+	URL    string   `json:"url"`    // Endpoint URL; must be http or https
+	Events []string `json:"events"` // Events to subscribe to
+}
+
+// NewWebhookReply returns the new webhook along with the secret that is used
+// to sign its deliveries.  The secret is only returned once.
+type NewWebhookReply struct {
+	Webhook Webhook `json:"webhook"`
+	Secret  string  `json:"secret"` // HMAC-SHA256 key
+}
+
+// Webhooks retrieves all registered webhooks.
+type Webhooks struct{}
+
+// WebhooksReply returns all registered webhooks.
+type WebhooksReply struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// DeleteWebhook deletes a registered webhook.
+type DeleteWebhook struct {
+	ID string `json:"id"` // Webhook ID
+}
+
+// DeleteWebhookReply replies to the DeleteWebhook command.
+type DeleteWebhookReply struct{}
+
+// APIToken is a long-lived token that authenticates the requests of a user
+// as an alternative to a session.  The token is sent in the Authorization
+// header and only allows the requests that its scope allows.
+type APIToken struct {
+	ID          string         `json:"id"`          // API token ID
+	UserID      string         `json:"userid"`      // User the token authenticates
+	Scope       APITokenScopeT `json:"scope"`       // Allowed requests
+	Description string         `json:"description"` // What the token is used for
+	CreatedBy   string         `json:"createdby"`   // ID of the admin that issued the token
+	Timestamp   int64          `json:"timestamp"`   // Creation timestamp
+}
+
+// NewAPIToken issues a new API token for a user.  Only read tokens can be
+// issued for other users; the submit and admin scopes can only be given to
+// the admin that issues the token.  This is an admin only command.
+type NewAPIToken struct {
+	UserID      string         `json:"userid"`      // User id
+	Scope       APITokenScopeT `json:"scope"`       // Allowed requests
+	Description string         `json:"description"` // What the token is used for
+}
+
+// NewAPITokenReply returns the new API token along with the token itself.
+// The token is only returned once; politeiawww only stores its hash.
+type NewAPITokenReply struct {
+	APIToken APIToken `json:"apitoken"`
+	Token    string   `json:"token"` // Token to send in the Authorization header
+}
+
+// APITokens retrieves all API tokens.  This is an admin only command.
+type APITokens struct{}
+
+// APITokensReply returns all API tokens.
+type APITokensReply struct {
+	APITokens []APIToken `json:"apitokens"`
+}
+
+// RevokeAPIToken revokes an API token.  Requests that carry the token are
+// rejected immediately.  This is an admin only command.
+type RevokeAPIToken struct {
+	ID string `json:"id"` // API token ID
+}
+
+// RevokeAPITokenReply replies to the RevokeAPIToken command.
+type RevokeAPITokenReply struct{}
+
+// Draft is a proposal that is being composed by its author.  Drafts are
+// private to their author and are stored by politeiawww as is; they are not
+// signed, timestamped or sent to politeiad.  A draft is published by
+// submitting its files with NewProposal.  The digest and MIME type of the
+// files are set by politeiawww.
+type Draft struct {
+	DraftID   string `json:"draftid"`   // Draft ID
+	Name      string `json:"name"`      // Proposal name from the index file
+	Files     []File `json:"files"`     // Draft files
+	Timestamp int64  `json:"timestamp"` // UNIX timestamp of creation
+	Updated   int64  `json:"updated"`   // UNIX timestamp of last update
+}
+
+// DraftSummary describes a draft without its files.
+type DraftSummary struct {
+	DraftID   string `json:"draftid"`   // Draft ID
+	Name      string `json:"name"`      // Proposal name from the index file
+	NumFiles  int    `json:"numfiles"`  // Number of files
+	Size      int64  `json:"size"`      // Combined size of the files in bytes
+	Timestamp int64  `json:"timestamp"` // UNIX timestamp of creation
+	Updated   int64  `json:"updated"`   // UNIX timestamp of last update
+}
+
+// NewDraft saves a new proposal draft for the logged in user.  The maximum
+// number of drafts is dictated by PolicyMaxDrafts and the combined size of
+// the files of a draft by PolicyMaxDraftSize.
+type NewDraft struct {
+	Files []File `json:"files"` // Draft files
+}
+
+// NewDraftReply returns the saved draft.
+type NewDraftReply struct {
+	Draft DraftSummary `json:"draft"`
+}
+
+// EditDraft replaces the files of an existing draft.
+type EditDraft struct {
+	DraftID string `json:"draftid"` // Draft ID
+	Files   []File `json:"files"`   // Draft files
+}
+
+// EditDraftReply returns the updated draft.
+type EditDraftReply struct {
+	Draft DraftSummary `json:"draft"`
+}
+
+// DeleteDraft deletes a draft of the logged in user.
+type DeleteDraft struct {
+	DraftID string `json:"draftid"` // Draft ID
+}
+
+// DeleteDraftReply is used to reply to a DeleteDraft command.
+type DeleteDraftReply struct{}
+
+// DraftDetails retrieves a draft of the logged in user along with its files.
+// The draft ID is part of the route.
+type DraftDetails struct {
+	DraftID string `json:"draftid"` // Draft ID
+}
+
+// DraftDetailsReply returns the requested draft.
+type DraftDetailsReply struct {
+	Draft Draft `json:"draft"`
+}
+
+// UserDrafts retrieves the drafts of the logged in user.
+type UserDrafts struct{}
+
+// UserDraftsReply returns the drafts of the logged in user, most recently
+// updated first.  The files are not included; use DraftDetails to fetch them.
+type UserDraftsReply struct {
+	Drafts []DraftSummary `json:"drafts"`
+}
+
+// NewUpload stages a proposal file that is uploaded in chunks.  Large files
+// can be uploaded using multiple UploadChunk commands, which can be resumed
+// when the connection is lost, instead of in a single NewProposal command.
+// The completed upload is referenced by its upload ID in the files of the
+// NewProposal command.  The size limits of the proposal policy apply.
+type NewUpload struct {
+	Name   string `json:"name"`   // Suggested filename
+	MIME   string `json:"mime"`   // Mime type
+	Digest string `json:"digest"` // Digest of the file content
+	Size   int64  `json:"size"`   // Size of the file content in bytes
+}
+
+// NewUploadReply returns the ID of the staged upload.
+type NewUploadReply struct {
+	UploadID  string `json:"uploadid"`  // Upload ID
+	ChunkSize int64  `json:"chunksize"` // Maximum chunk size in bytes
+	Expires   int64  `json:"expires"`   // Expiration timestamp
+}
+
+// UploadChunk appends a chunk to a staged upload.  Offset must be equal to
+// the number of bytes that have been received so far.  The digest of the
+// file content is verified once the last chunk has been received.
+type UploadChunk struct {
+	UploadID string `json:"uploadid"` // Upload ID
+	Offset   int64  `json:"offset"`   // Offset of the chunk in the file
+	Payload  string `json:"payload"`  // Chunk content, base64 encoded
+}
+
+// UploadChunkReply returns the progress of the upload.
+type UploadChunkReply struct {
+	Received int64 `json:"received"` // Number of bytes received
+	Complete bool  `json:"complete"` // Whether all bytes were received
+}
+
+// UploadStatus retrieves the progress of a staged upload of the logged in
+// user.  It is used to resume an interrupted upload.  The upload ID is part
+// of the route.
+type UploadStatus struct {
+	UploadID string `json:"uploadid"` // Upload ID
+}
+
+// UploadStatusReply returns the progress of a staged upload.
+type UploadStatusReply struct {
+	UploadID string `json:"uploadid"` // Upload ID
+	Name     string `json:"name"`     // Suggested filename
+	Size     int64  `json:"size"`     // Size of the file content in bytes
+	Received int64  `json:"received"` // Number of bytes received
+	Complete bool   `json:"complete"` // Whether all bytes were received
+	Expires  int64  `json:"expires"`  // Expiration timestamp
+}
+
 // Websocket commands
 const (
 	WSCError     = "error"
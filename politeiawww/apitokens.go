@@ -0,0 +1,321 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// apiTokensFilename is the name of the file, relative to the data
+	// directory, that the API tokens are stored in.
+	apiTokensFilename = "apitokens.json"
+
+	// apiTokenSize is the size of an API token in bytes.
+	apiTokenSize = 32
+
+	// apiTokenMaxDescriptionLength is the maximum length of the
+	// description of an API token.
+	apiTokenMaxDescriptionLength = 200
+)
+
+// apiToken is an API token along with the hash of the token.  The token
+// itself is never stored.
+type apiToken struct {
+	www.APIToken
+	Hash string `json:"hash"`
+}
+
+// apiTokenHash returns the hex encoded SHA256 hash of the passed in token.
+func apiTokenHash(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// apiTokenAllows returns whether an API token with the passed in scope is
+// allowed to make a request with the passed in method to a route that
+// requires a login.  Admin routes require the admin scope, GET requests
+// require the read scope and all other requests require the submit scope.
+func apiTokenAllows(scope www.APITokenScopeT, method string, admin bool) bool {
+	switch {
+	case admin:
+		return scope >= www.APITokenScopeAdmin
+	case method == http.MethodGet:
+		return scope >= www.APITokenScopeRead
+	default:
+		return scope >= www.APITokenScopeSubmit
+	}
+}
+
+// hasAPIToken returns whether the passed in request carries an API token.
+// Only Authorization headers that use the API token scheme are API tokens;
+// requests with any other Authorization header remain CSRF protected.
+func hasAPIToken(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get(www.Authorization),
+		www.APITokenScheme+" ")
+}
+
+// csrfExemptAPIToken routes the requests that carry an API token to next and
+// all other requests to the CSRF protected handler.  Requests that carry an
+// API token are never authenticated by the session cookie, so they can not
+// be forged by a third party site.
+func csrfExemptAPIToken(protected, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hasAPIToken(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		protected.ServeHTTP(w, r)
+	})
+}
+
+// getAPITokenUser returns the API token that the passed in request carries
+// along with the user that it authenticates.
+func (p *politeiawww) getAPITokenUser(r *http.Request) (*apiToken, *user.User, error) {
+	invalid := www.UserError{
+		ErrorCode: www.ErrorStatusInvalidAPIToken,
+	}
+
+	token := strings.TrimPrefix(r.Header.Get(www.Authorization),
+		www.APITokenScheme+" ")
+	if token == r.Header.Get(www.Authorization) {
+		return nil, nil, invalid
+	}
+
+	p.RLock()
+	t, ok := p.apiTokens[apiTokenHash(token)]
+	p.RUnlock()
+	if !ok {
+		return nil, nil, invalid
+	}
+
+	// Tokens of users that no longer exist or that have been
+	// deactivated are rejected.
+	u, err := p.getUserByIDStr(t.UserID)
+	if _, ok := err.(www.UserError); ok {
+		return nil, nil, invalid
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if u.Deactivated {
+		return nil, nil, invalid
+	}
+
+	requestLog(r).Debugf("API token %v of user %v: %v %v", t.ID,
+		u.Username, r.Method, r.URL)
+
+	return &t, u, nil
+}
+
+// apiTokensFile returns the path of the file that the API tokens are stored
+// in.
+func (p *politeiawww) apiTokensFile() string {
+	return filepath.Join(p.cfg.DataDir, apiTokensFilename)
+}
+
+// initAPITokens loads the API tokens from disk.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) initAPITokens() error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.apiTokens = make(map[string]apiToken)
+	b, err := ioutil.ReadFile(p.apiTokensFile())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var tokens []apiToken
+	err = json.Unmarshal(b, &tokens)
+	if err != nil {
+		return fmt.Errorf("unmarshal %v: %v", p.apiTokensFile(), err)
+	}
+	for _, v := range tokens {
+		p.apiTokens[v.Hash] = v
+	}
+
+	log.Infof("Loaded %v API tokens", len(p.apiTokens))
+
+	return nil
+}
+
+// _saveAPITokens writes the API tokens to disk.
+//
+// This function must be called WITH the lock held.
+func (p *politeiawww) _saveAPITokens() error {
+	tokens := make([]apiToken, 0, len(p.apiTokens))
+	for _, v := range p.apiTokens {
+		tokens = append(tokens, v)
+	}
+	b, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	return util.WriteFileAtomic(p.apiTokensFile(), b, 0600)
+}
+
+// processNewAPIToken issues a new API token for a user.  Tokens that allow
+// more than reading can only be issued for the account of the admin that
+// issues them, since they allow acting as the user.  The token is only
+// returned in the reply; only its hash is stored.
+func (p *politeiawww) processNewAPIToken(nat www.NewAPIToken, adminUser *user.User) (*www.NewAPITokenReply, error) {
+	log.Tracef("processNewAPIToken: %v %v", nat.UserID, nat.Scope)
+
+	u, err := p.getUserByIDStr(nat.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Deactivated {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserDeactivated,
+		}
+	}
+
+	switch nat.Scope {
+	case www.APITokenScopeRead:
+	case www.APITokenScopeSubmit, www.APITokenScopeAdmin:
+		if u.ID != adminUser.ID {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusInvalidAPITokenScope,
+				ErrorContext: []string{"only read tokens can be issued " +
+					"for other users"},
+			}
+		}
+	default:
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidAPITokenScope,
+		}
+	}
+
+	description := strings.TrimSpace(nat.Description)
+	if len(description) > apiTokenMaxDescriptionLength {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("description is longer "+
+				"than %v characters", apiTokenMaxDescriptionLength)},
+		}
+	}
+
+	b, err := util.Random(apiTokenSize)
+	if err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(b)
+	t := apiToken{
+		APIToken: www.APIToken{
+			ID:          uuid.New().String(),
+			UserID:      u.ID.String(),
+			Scope:       nat.Scope,
+			Description: description,
+			CreatedBy:   adminUser.ID.String(),
+			Timestamp:   time.Now().Unix(),
+		},
+		Hash: apiTokenHash(token),
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.apiTokens[t.Hash] = t
+	err = p._saveAPITokens()
+	if err != nil {
+		delete(p.apiTokens, t.Hash)
+		return nil, err
+	}
+
+	err = p._logAdminAction(adminUser, fmt.Sprintf("new api token,%v,%v,%v,%v",
+		t.ID, u.ID, u.Username, www.APITokenScope[t.Scope]))
+	if err != nil {
+		log.Errorf("could not log action to file: %v", err)
+	}
+
+	return &www.NewAPITokenReply{
+		APIToken: t.APIToken,
+		Token:    token,
+	}, nil
+}
+
+// processAPITokens returns all API tokens ordered by creation time.  The
+// token hashes are not returned.
+func (p *politeiawww) processAPITokens() (*www.APITokensReply, error) {
+	log.Tracef("processAPITokens")
+
+	p.RLock()
+	tokens := make([]www.APIToken, 0, len(p.apiTokens))
+	for _, v := range p.apiTokens {
+		tokens = append(tokens, v.APIToken)
+	}
+	p.RUnlock()
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Timestamp != tokens[j].Timestamp {
+			return tokens[i].Timestamp < tokens[j].Timestamp
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+
+	return &www.APITokensReply{
+		APITokens: tokens,
+	}, nil
+}
+
+// processRevokeAPIToken revokes an API token.
+func (p *politeiawww) processRevokeAPIToken(rat www.RevokeAPIToken, adminUser *user.User) (*www.RevokeAPITokenReply, error) {
+	log.Tracef("processRevokeAPIToken: %v", rat.ID)
+
+	p.Lock()
+	defer p.Unlock()
+
+	var (
+		t     apiToken
+		found bool
+	)
+	for _, v := range p.apiTokens {
+		if v.ID == rat.ID {
+			t = v
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusAPITokenNotFound,
+		}
+	}
+
+	delete(p.apiTokens, t.Hash)
+	err := p._saveAPITokens()
+	if err != nil {
+		p.apiTokens[t.Hash] = t
+		return nil, err
+	}
+
+	err = p._logAdminAction(adminUser, fmt.Sprintf("revoke api token,%v,%v",
+		t.ID, t.UserID))
+	if err != nil {
+		log.Errorf("could not log action to file: %v", err)
+	}
+
+	return &www.RevokeAPITokenReply{}, nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestAPITokenAllows(t *testing.T) {
+	// Setup tests
+	var tests = []struct {
+		name   string
+		scope  www.APITokenScopeT
+		method string
+		admin  bool
+		want   bool
+	}{
+		{"read get", www.APITokenScopeRead, http.MethodGet, false, true},
+		{"read post", www.APITokenScopeRead, http.MethodPost, false, false},
+		{"read admin", www.APITokenScopeRead, http.MethodGet, true, false},
+		{"submit post", www.APITokenScopeSubmit, http.MethodPost, false, true},
+		{"submit admin", www.APITokenScopeSubmit, http.MethodGet, true, false},
+		{"admin post", www.APITokenScopeAdmin, http.MethodPost, false, true},
+		{"admin admin", www.APITokenScopeAdmin, http.MethodPost, true, true},
+		{"invalid get", www.APITokenScopeInvalid, http.MethodGet, false, false},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := apiTokenAllows(v.scope, v.method, v.admin)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestHasAPIToken(t *testing.T) {
+	// Setup tests
+	var tests = []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"no header", "", false},
+		{"api token", www.APITokenScheme + " token", true},
+		{"basic auth", "Basic dXNlcjpwYXNz", false},
+		{"scheme only", www.APITokenScheme, false},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, www.RouteUserMe, nil)
+			if v.header != "" {
+				r.Header.Set(www.Authorization, v.header)
+			}
+			got := hasAPIToken(r)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestProcessAPITokens(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+	usr, _ := newUser(t, p, false)
+
+	// Only read tokens can be issued for other users
+	for _, scope := range []www.APITokenScopeT{www.APITokenScopeSubmit,
+		www.APITokenScopeAdmin} {
+		_, err := p.processNewAPIToken(www.NewAPIToken{
+			UserID: usr.ID.String(),
+			Scope:  scope,
+		}, admin)
+		if got, want := errToStr(err),
+			www.ErrorStatus[www.ErrorStatusInvalidAPITokenScope]; got != want {
+			t.Fatalf("scope %v: got error %v, want %v", scope, got, want)
+		}
+	}
+	_, err := p.processNewAPIToken(www.NewAPIToken{
+		UserID: admin.ID.String(),
+		Scope:  www.APITokenScopeAdmin,
+	}, admin)
+	if err != nil {
+		t.Fatalf("processNewAPIToken own account: %v", err)
+	}
+	_, err = p.processNewAPIToken(www.NewAPIToken{
+		UserID: usr.ID.String(),
+		Scope:  www.APITokenScopeInvalid,
+	}, admin)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusInvalidAPITokenScope]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+
+	natr, err := p.processNewAPIToken(www.NewAPIToken{
+		UserID:      usr.ID.String(),
+		Scope:       www.APITokenScopeRead,
+		Description: "ci",
+	}, admin)
+	if err != nil {
+		t.Fatalf("processNewAPIToken: %v", err)
+	}
+
+	// Handler that records the user that the request was made as
+	var got string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		got = getContextUser(r).ID.String()
+	}
+	request := func(method, token string, adminRoute bool) int {
+		t.Helper()
+		got = ""
+		r := httptest.NewRequest(method, www.RouteUserMe, nil)
+		r.Header.Set(www.Authorization, www.APITokenScheme+" "+token)
+		w := httptest.NewRecorder()
+		p.withSessionUser(handler, adminRoute)(w, r)
+		return w.Result().StatusCode
+	}
+
+	// The token authenticates GET requests as the user
+	status := request(http.MethodGet, natr.Token, false)
+	if status != http.StatusOK || got != usr.ID.String() {
+		t.Fatalf("got status %v user %v, want %v %v", status, got,
+			http.StatusOK, usr.ID)
+	}
+
+	// The read scope does not allow other requests
+	status = request(http.MethodPost, natr.Token, false)
+	if status != http.StatusForbidden || got != "" {
+		t.Fatalf("got status %v, want %v", status, http.StatusForbidden)
+	}
+	status = request(http.MethodGet, natr.Token, true)
+	if status != http.StatusForbidden || got != "" {
+		t.Fatalf("got status %v, want %v", status, http.StatusForbidden)
+	}
+
+	// Unknown tokens are rejected
+	status = request(http.MethodGet, natr.Token+"00", false)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("got status %v, want %v", status, http.StatusUnauthorized)
+	}
+
+	// The tokens are persisted
+	err = p.initAPITokens()
+	if err != nil {
+		t.Fatalf("initAPITokens: %v", err)
+	}
+	atr, err := p.processAPITokens()
+	if err != nil {
+		t.Fatalf("processAPITokens: %v", err)
+	}
+	var found bool
+	for _, v := range atr.APITokens {
+		if v.ID == natr.APIToken.ID {
+			found = true
+		}
+	}
+	if len(atr.APITokens) != 2 || !found {
+		t.Fatalf("got tokens %v, want %v", atr.APITokens, natr.APIToken)
+	}
+
+	// Revoked tokens are rejected
+	_, err = p.processRevokeAPIToken(www.RevokeAPIToken{
+		ID: natr.APIToken.ID,
+	}, admin)
+	if err != nil {
+		t.Fatalf("processRevokeAPIToken: %v", err)
+	}
+	status = request(http.MethodGet, natr.Token, false)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("got status %v, want %v", status, http.StatusUnauthorized)
+	}
+	_, err = p.processRevokeAPIToken(www.RevokeAPIToken{
+		ID: natr.APIToken.ID,
+	}, admin)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusAPITokenNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+}
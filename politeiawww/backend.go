@@ -46,6 +46,7 @@ const (
 type MDStreamChanges struct {
 	Version             uint             `json:"version"`                       // Version of the struct
 	AdminPubKey         string           `json:"adminpubkey"`                   // Identity of the administrator
+	AuthorPubKey        string           `json:"authorpubkey,omitempty"`        // Identity of the author when the author withdrew the proposal
 	NewStatus           pd.RecordStatusT `json:"newstatus"`                     // NewStatus
 	StatusChangeMessage string           `json:"statuschangemessage,omitempty"` // Status change message
 	Timestamp           int64            `json:"timestamp"`                     // Timestamp of the change
@@ -57,11 +58,13 @@ type loginReplyWithError struct {
 }
 
 type BackendProposalMetadata struct {
-	Version   uint64 `json:"version"`   // BackendProposalMetadata version
-	Timestamp int64  `json:"timestamp"` // Last update of proposal
-	Name      string `json:"name"`      // Generated proposal name
-	PublicKey string `json:"publickey"` // Key used for signature.
-	Signature string `json:"signature"` // Signature of merkle root
+	Version   uint64 `json:"version"`          // BackendProposalMetadata version
+	Timestamp int64  `json:"timestamp"`        // Last update of proposal
+	Name      string `json:"name"`             // Generated proposal name
+	PublicKey string `json:"publickey"`        // Key used for signature.
+	Signature string `json:"signature"`        // Signature of merkle root
+	LinkTo    string `json:"linkto,omitempty"` // Token of the linked RFP
+	LinkBy    int64  `json:"linkby,omitempty"` // RFP submission deadline
 }
 
 var (
@@ -275,7 +278,9 @@ func validateProposal(np www.NewProposal, u *user.User) error {
 
 	// Note that we need validate the string representation of the merkle
 	mr := merkle.Root(hashes)
-	if !pk.VerifyMessage([]byte(hex.EncodeToString(mr[:])), sig) {
+	msg := www.ProposalSignatureMessage(hex.EncodeToString(mr[:]),
+		np.LinkTo, np.LinkBy)
+	if !pk.VerifyMessage([]byte(msg), sig) {
 		return www.UserError{
 			ErrorCode: www.ErrorStatusInvalidSignature,
 		}
@@ -284,6 +289,157 @@ func validateProposal(np www.NewProposal, u *user.User) error {
 	return nil
 }
 
+// validateLinkBy verifies the submission deadline of a new RFP.  The
+// deadline must be in the future at the time of submission.
+func validateLinkBy(linkBy, now int64) error {
+	if linkBy <= now {
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidLinkBy,
+			ErrorContext: []string{"linkby must be in the future"},
+		}
+	}
+	return nil
+}
+
+// validateLinkTo verifies that the passed in proposal is an RFP that accepts
+// submissions at the passed in time.  The RFP must be public and its
+// submission deadline must not have passed.
+func validateLinkTo(rfp www.ProposalRecord, now int64) error {
+	switch {
+	case rfp.LinkBy == 0:
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidLinkTo,
+			ErrorContext: []string{"linked proposal is not an rfp"},
+		}
+	case rfp.Status != www.PropStatusPublic:
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidLinkTo,
+			ErrorContext: []string{"rfp is not public"},
+		}
+	case rfp.LinkBy <= now:
+		return www.UserError{
+			ErrorCode: www.ErrorStatusRFPSubmissionsClosed,
+		}
+	}
+	return nil
+}
+
+// validateProposalLinks verifies the RFP fields of a new proposal submitted by
+// the passed in user.  A proposal is either an RFP, a submission to an RFP or
+// neither.  Only admins can submit RFPs.
+func (p *politeiawww) validateProposalLinks(np www.NewProposal, u *user.User) error {
+	now := time.Now().Unix()
+	switch {
+	case np.LinkTo != "" && np.LinkBy != 0:
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidLinkTo,
+			ErrorContext: []string{"an rfp can not link to another rfp"},
+		}
+	case np.LinkBy != 0:
+		if !u.Admin {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidLinkBy,
+				ErrorContext: []string{"only admins can submit an rfp"},
+			}
+		}
+		return validateLinkBy(np.LinkBy, now)
+	case np.LinkTo != "":
+		rfp, err := p.getProp(np.LinkTo)
+		if err != nil {
+			if err == cache.ErrRecordNotFound {
+				err = www.UserError{
+					ErrorCode:    www.ErrorStatusInvalidLinkTo,
+					ErrorContext: []string{"rfp not found"},
+				}
+			}
+			return err
+		}
+		return validateLinkTo(*rfp, now)
+	}
+	return nil
+}
+
+// proposalMerkleRoot returns the hex encoded merkle root of the digests of
+// the decoded payloads of the passed in files.  This is the message that the
+// author of a proposal signs.
+func proposalMerkleRoot(files []www.File) (string, error) {
+	hashes := make([]*[sha256.Size]byte, 0, len(files))
+	for _, v := range files {
+		data, err := base64.StdEncoding.DecodeString(v.Payload)
+		if err != nil {
+			return "", err
+		}
+		var d [sha256.Size]byte
+		copy(d[:], util.Digest(data))
+		hashes = append(hashes, &d)
+	}
+	mr := merkle.Root(hashes)
+	return hex.EncodeToString(mr[:]), nil
+}
+
+// validateProposalEdit verifies that the passed in edit is a valid
+// continuation of the current version of the proposal.  validateProposal
+// has already verified that the edit is signed by the author over the merkle
+// root of the edit; this verifies that the merkle root is new.  An edit that
+// does not change the proposal files would create a version that is
+// identical to its predecessor.
+func validateProposalEdit(ep www.EditProposal, cur www.ProposalRecord) error {
+	mr, err := proposalMerkleRoot(ep.Files)
+	if err != nil {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusInvalidBase64,
+		}
+	}
+	if mr == cur.CensorshipRecord.Merkle {
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidProposalEdit,
+			ErrorContext: []string{"proposal files are unchanged"},
+		}
+	}
+	return nil
+}
+
+// verifyProposalEditChain verifies that the passed in updated proposal is the
+// version that was created by the passed in edit of the passed in previous
+// version: the token is unchanged, the version directly follows the previous
+// version and the censorship record and author signature are those of the
+// edit.
+func verifyProposalEditChain(ep www.EditProposal, prev, updated www.ProposalRecord) error {
+	if updated.CensorshipRecord.Token != prev.CensorshipRecord.Token {
+		return fmt.Errorf("token changed from %v to %v",
+			prev.CensorshipRecord.Token, updated.CensorshipRecord.Token)
+	}
+
+	prevVersion, err := strconv.ParseUint(prev.Version, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid previous version %v", prev.Version)
+	}
+	version, err := strconv.ParseUint(updated.Version, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid version %v", updated.Version)
+	}
+	if version != prevVersion+1 {
+		return fmt.Errorf("version went from %v to %v", prevVersion,
+			version)
+	}
+
+	mr, err := proposalMerkleRoot(ep.Files)
+	if err != nil {
+		return err
+	}
+	if updated.CensorshipRecord.Merkle != mr {
+		return fmt.Errorf("merkle root %v is not the merkle root of "+
+			"the edit %v", updated.CensorshipRecord.Merkle, mr)
+	}
+	if updated.PublicKey != ep.PublicKey ||
+		updated.Signature != ep.Signature {
+		return fmt.Errorf("author signature is not the signature of " +
+			"the edit")
+	}
+
+	return nil
+}
+
 // voteIsAuthorized returns whether the author of the proposal has authorized
 // an admin to start the voting period for the proposal.
 func voteIsAuthorized(avr www.AuthorizeVoteReply) bool {
@@ -366,7 +522,8 @@ func validateVoteBit(vote www.Vote, bit uint64) error {
 	return fmt.Errorf("bit not found 0x%x", bit)
 }
 
-// initCommentScores populates the comment scores cache.
+// initCommentScores populates the comment scores and comment reactions
+// caches.
 func (p *politeiawww) initCommentScores() error {
 	log.Tracef("initCommentScores")
 
@@ -388,6 +545,28 @@ func (p *politeiawww) initCommentScores() error {
 		}
 	}
 
+	// The comment reactions are already in the inventory in the
+	// order that they were received so they are replayed directly.
+	reactions := make(map[string][]decredplugin.ReactComment) // [token+commentID]
+	for _, v := range ir.ReactComments {
+		reactions[v.Token+v.CommentID] = append(
+			reactions[v.Token+v.CommentID], v)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	for k, v := range reactions {
+		users, err := replayCommentReactions(v, p.userPubkeys)
+		if err != nil {
+			return fmt.Errorf("replayCommentReactions: %v", err)
+		}
+		counts := countCommentReactions(users)
+		if len(counts) != 0 {
+			p.commentReactions[k] = counts
+		}
+	}
+
 	return nil
 }
 
@@ -480,9 +659,11 @@ func (p *politeiawww) ProcessAuthorizeVote(av www.AuthorizeVote, u *user.User) (
 			ErrorCode: www.ErrorStatusWrongStatus,
 		}
 	case vd.StartVoteReply.StartBlockHeight != "":
-		// Vote has already started
+		// Vote has already started. A vote authorization
+		// cannot be revoked once the vote has started.
 		return nil, www.UserError{
-			ErrorCode: www.ErrorStatusWrongVoteStatus,
+			ErrorCode:    www.ErrorStatusWrongVoteStatus,
+			ErrorContext: []string{"vote has already started"},
 		}
 	case av.Action != www.AuthVoteActionAuthorize &&
 		av.Action != www.AuthVoteActionRevoke:
@@ -693,6 +874,14 @@ func (p *politeiawww) ProcessStartVote(sv www.StartVote, u *user.User) (*www.Sta
 		return nil, err
 	}
 
+	// The vote has been started so a failure to track it must not fail
+	// the request.
+	err = p.addVoteEnd(sv.Vote.Token, vr.EndHeight)
+	if err != nil {
+		log.Errorf("ProcessStartVote: addVoteEnd %v: %v",
+			sv.Vote.Token, err)
+	}
+
 	if !p.test {
 		p.eventManager._fireEvent(EventTypeProposalVoteStarted,
 			EventDataProposalVoteStarted{
@@ -745,6 +934,7 @@ func (p *politeiawww) getBestBlock() (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
+	p.recordBestBlock(bestBlock, time.Now())
 
 	return bestBlock, nil
 }
@@ -0,0 +1,259 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiad/cache"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+// billingSpent returns the total amount that was spent against the budget of
+// the passed in billing record.
+func billingSpent(b user.ProposalBilling) uint64 {
+	var spent uint64
+	for _, v := range b.Spends {
+		spent += v.Amount
+	}
+	return spent
+}
+
+// convertSpendToWWW converts a proposal spend from the user database into the
+// spend that is returned by the API.
+func convertSpendToWWW(s user.ProposalSpend) www.ProposalSpend {
+	return www.ProposalSpend{
+		ID:          s.ID,
+		Amount:      s.Amount,
+		Description: s.Description,
+		AdminID:     s.AdminID.String(),
+		Timestamp:   s.Timestamp,
+	}
+}
+
+// convertBillingToWWW converts the billing record of a proposal into the
+// billing status that is returned by the API.
+func convertBillingToWWW(b user.ProposalBilling) www.ProposalBillingReply {
+	spent := billingSpent(b)
+	spends := make([]www.ProposalSpend, 0, len(b.Spends))
+	for _, v := range b.Spends {
+		spends = append(spends, convertSpendToWWW(v))
+	}
+	return www.ProposalBillingReply{
+		Token:     b.Token,
+		Budget:    b.Budget,
+		Spent:     spent,
+		Remaining: int64(b.Budget) - int64(spent),
+		Spends:    spends,
+	}
+}
+
+// initBilling loads the proposal billing records from the user database.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) initBilling() error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.billing = make(map[string]user.ProposalBilling)
+	err := p.db.AllProposalBillings(func(b *user.ProposalBilling) {
+		p.billing[b.Token] = *b
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Loaded %v proposal billing records", len(p.billing))
+
+	return nil
+}
+
+// _saveBilling writes the passed in billing record to the user database and
+// updates the in memory copy once the write succeeded.
+//
+// This function must be called WITH the lock held.
+func (p *politeiawww) _saveBilling(b user.ProposalBilling) error {
+	err := p.db.ProposalBillingSave(b)
+	if err != nil {
+		return err
+	}
+	p.billing[b.Token] = b
+	return nil
+}
+
+// ensureProposalApproved returns an error if the passed in proposal is not a
+// public proposal whose vote has been approved.
+func (p *politeiawww) ensureProposalApproved(token string) error {
+	pr, err := p.getProp(token)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusProposalNotFound,
+			}
+		}
+		return err
+	}
+	if pr.Status != www.PropStatusPublic {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusProposalNotApproved,
+		}
+	}
+
+	bestBlock, err := p.getBestBlock()
+	if err != nil {
+		return fmt.Errorf("getBestBlock: %v", err)
+	}
+	vs, err := p.getVoteStatus(token, bestBlock)
+	if err != nil {
+		return fmt.Errorf("getVoteStatus: %v", err)
+	}
+	if !voteApproved(vs) {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusProposalNotApproved,
+		}
+	}
+
+	return nil
+}
+
+// processProposalBilling returns the billing status of an approved proposal.
+func (p *politeiawww) processProposalBilling(token string) (*www.ProposalBillingReply, error) {
+	log.Tracef("processProposalBilling: %v", token)
+
+	p.RLock()
+	defer p.RUnlock()
+
+	b, ok := p.billing[token]
+	if !ok {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusProposalBudgetNotSet,
+		}
+	}
+
+	reply := convertBillingToWWW(b)
+	return &reply, nil
+}
+
+// processSetProposalBudget sets the approved budget of a proposal whose vote
+// has been approved.  The budget may be changed after it has been set but not
+// to less than the amount that has already been spent.
+func (p *politeiawww) processSetProposalBudget(spb www.SetProposalBudget, adminUser *user.User) (*www.SetProposalBudgetReply, error) {
+	log.Tracef("processSetProposalBudget: %v %v", spb.Token, spb.Budget)
+
+	if spb.Budget == 0 {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"budget must be greater than zero"},
+		}
+	}
+
+	err := p.ensureProposalApproved(spb.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	b := p.billing[spb.Token]
+	if spent := billingSpent(b); spent > spb.Budget {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("budget is less than "+
+				"the amount already spent: %v", spent)},
+		}
+	}
+
+	b.Token = spb.Token
+	b.Budget = spb.Budget
+	err = p._saveBilling(b)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p._logAdminAction(adminUser, fmt.Sprintf("set proposal budget,%v,%v",
+		spb.Token, spb.Budget))
+	if err != nil {
+		log.Errorf("could not log action to file: %v", err)
+	}
+
+	return &www.SetProposalBudgetReply{
+		Billing: convertBillingToWWW(b),
+	}, nil
+}
+
+// processRecordProposalSpend records a spend against the budget of an approved
+// proposal.  Spends that exceed the remaining budget are rejected unless the
+// admin explicitly allows overspending.
+func (p *politeiawww) processRecordProposalSpend(rps www.RecordProposalSpend, adminUser *user.User) (*www.RecordProposalSpendReply, error) {
+	log.Tracef("processRecordProposalSpend: %v %v", rps.Token, rps.Amount)
+
+	if rps.Amount == 0 {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidSpendAmount,
+		}
+	}
+	description := strings.TrimSpace(rps.Description)
+	if description == "" {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"description cannot be blank"},
+		}
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	old, ok := p.billing[rps.Token]
+	if !ok {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusProposalBudgetNotSet,
+		}
+	}
+	spent := billingSpent(old)
+	if !rps.AllowOverspend && spent+rps.Amount > old.Budget {
+		var remaining uint64
+		if spent < old.Budget {
+			remaining = old.Budget - spent
+		}
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusProposalBudgetExceeded,
+			ErrorContext: []string{fmt.Sprintf("remaining budget: %v",
+				remaining)},
+		}
+	}
+
+	spend := user.ProposalSpend{
+		ID:          uuid.New().String(),
+		Amount:      rps.Amount,
+		Description: description,
+		AdminID:     adminUser.ID,
+		Timestamp:   time.Now().Unix(),
+	}
+	b := user.ProposalBilling{
+		Token:  rps.Token,
+		Budget: old.Budget,
+		Spends: append(append([]user.ProposalSpend{}, old.Spends...), spend),
+	}
+	err := p._saveBilling(b)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p._logAdminAction(adminUser, fmt.Sprintf("record proposal spend,"+
+		"%v,%v,%v", rps.Token, spend.ID, spend.Amount))
+	if err != nil {
+		log.Errorf("could not log action to file: %v", err)
+	}
+
+	return &www.RecordProposalSpendReply{
+		Spend:   convertSpendToWWW(spend),
+		Billing: convertBillingToWWW(b),
+	}, nil
+}
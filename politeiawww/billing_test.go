@@ -0,0 +1,120 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+func TestProcessRecordProposalSpend(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+	token := "abf0fd1fc1b8c1c9535685373dce6c54948b7eb018e17e3a8cea26a3c9b85684"
+	p.billing[token] = user.ProposalBilling{
+		Token:  token,
+		Budget: 1000,
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name          string
+		spend         www.RecordProposalSpend
+		wantErr       error
+		wantRemaining int64
+	}{
+		{"budget not set",
+			www.RecordProposalSpend{
+				Token:       "invalid",
+				Amount:      100,
+				Description: "design work",
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusProposalBudgetNotSet,
+			}, 1000},
+		{"zero amount",
+			www.RecordProposalSpend{
+				Token:       token,
+				Description: "design work",
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidSpendAmount,
+			}, 1000},
+		{"blank description",
+			www.RecordProposalSpend{
+				Token:       token,
+				Amount:      100,
+				Description: " ",
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			}, 1000},
+		{"within budget",
+			www.RecordProposalSpend{
+				Token:       token,
+				Amount:      600,
+				Description: "design work",
+			}, nil, 400},
+		{"exceeds budget",
+			www.RecordProposalSpend{
+				Token:       token,
+				Amount:      500,
+				Description: "development",
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusProposalBudgetExceeded,
+			}, 400},
+		{"overspend allowed",
+			www.RecordProposalSpend{
+				Token:          token,
+				Amount:         500,
+				Description:    "development",
+				AllowOverspend: true,
+			}, nil, -100},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processRecordProposalSpend(v.spend, admin)
+			got := errToStr(err)
+			want := errToStr(v.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+
+			pbr, err := p.processProposalBilling(token)
+			if err != nil {
+				t.Fatalf("processProposalBilling: %v", err)
+			}
+			if pbr.Remaining != v.wantRemaining {
+				t.Fatalf("got remaining %v, want %v",
+					pbr.Remaining, v.wantRemaining)
+			}
+		})
+	}
+
+	// Verify that the billing records were persisted in the user database
+	err := p.initBilling()
+	if err != nil {
+		t.Fatalf("initBilling: %v", err)
+	}
+	pbr, err := p.processProposalBilling(token)
+	if err != nil {
+		t.Fatalf("processProposalBilling: %v", err)
+	}
+	if pbr.Spent != 1100 || len(pbr.Spends) != 2 {
+		t.Fatalf("got spent %v in %v spends, want 1100 in 2 spends",
+			pbr.Spent, len(pbr.Spends))
+	}
+	if pbr.Spends[0].AdminID != admin.ID.String() {
+		t.Fatalf("got admin id %v, want %v", pbr.Spends[0].AdminID,
+			admin.ID.String())
+	}
+}
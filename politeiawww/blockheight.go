@@ -0,0 +1,62 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// bestBlockStaleIntervals is the number of target block intervals after which
+// a best block that has not changed is considered stale.  The time between
+// blocks regularly exceeds the target interval so a best block is only stale
+// once it is well past it.
+const bestBlockStaleIntervals = 6
+
+// recordBestBlock records the best block that politeiad reported at the
+// passed in time.  The time is only updated when the best block changes.
+func (p *politeiawww) recordBestBlock(height uint64, now time.Time) {
+	p.bestBlockMtx.Lock()
+	defer p.bestBlockMtx.Unlock()
+
+	if height != p.bestBlock || p.bestBlockSeen.IsZero() {
+		p.bestBlock = height
+		p.bestBlockSeen = now
+	}
+}
+
+// bestBlockStatus returns the last recorded best block and whether it is
+// current at the passed in time.  A best block that has not changed for
+// bestBlockStaleIntervals target block intervals means that politeiad is
+// syncing or has lost its connection to the Decred network.  The best block
+// is considered current after politeiawww starts until that much time has
+// passed.
+func (p *politeiawww) bestBlockStatus(now time.Time) www.BlockHeightReply {
+	p.bestBlockMtx.Lock()
+	defer p.bestBlockMtx.Unlock()
+
+	stale := p.params.TargetTimePerBlock * bestBlockStaleIntervals
+	return www.BlockHeightReply{
+		Height:    p.bestBlock,
+		Timestamp: p.bestBlockSeen.Unix(),
+		Synced:    now.Sub(p.bestBlockSeen) < stale,
+	}
+}
+
+// processBlockHeight asks politeiad for the current best block and returns
+// it along with whether it is current.
+func (p *politeiawww) processBlockHeight() (*www.BlockHeightReply, error) {
+	log.Tracef("processBlockHeight")
+
+	_, err := p.getBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("getBestBlock: %v", err)
+	}
+
+	bhr := p.bestBlockStatus(time.Now())
+	return &bhr, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBestBlockStatus(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	start := time.Unix(1550000000, 0)
+	stale := p.params.TargetTimePerBlock * bestBlockStaleIntervals
+
+	// Setup tests.  The tests depend on each other; every test records
+	// its best block before checking the status.
+	var tests = []struct {
+		name       string
+		height     uint64
+		at         time.Time
+		wantSeen   time.Time
+		wantSynced bool
+	}{
+		{"first block", 1000, start, start, true},
+		{"unchanged block", 1000, start.Add(stale / 2), start, true},
+		{"stale block", 1000, start.Add(stale), start, false},
+		{"new block", 1001, start.Add(stale + time.Minute),
+			start.Add(stale + time.Minute), true},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			p.recordBestBlock(v.height, v.at)
+			bhr := p.bestBlockStatus(v.at)
+			if bhr.Height != v.height {
+				t.Errorf("got height %v, want %v", bhr.Height, v.height)
+			}
+			if bhr.Timestamp != v.wantSeen.Unix() {
+				t.Errorf("got timestamp %v, want %v", bhr.Timestamp,
+					v.wantSeen.Unix())
+			}
+			if bhr.Synced != v.wantSynced {
+				t.Errorf("got synced %v, want %v", bhr.Synced,
+					v.wantSynced)
+			}
+		})
+	}
+}
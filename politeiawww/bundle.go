@@ -0,0 +1,243 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiad/cache"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// proposalBundle contains the records that make up a proposal bundle.
+type proposalBundle struct {
+	proposal www.ProposalRecord
+	metadata []www.MetadataStream
+	comments www.GetCommentsReply
+	votes    www.VoteResultsReply
+}
+
+// bundleWriter writes the entries of a proposal bundle and records them for
+// the manifest.
+type bundleWriter struct {
+	tw      *tar.Writer
+	modTime time.Time
+	entries []www.ProposalBundleEntry
+}
+
+// write writes a single entry to the bundle.  The entry is added to the
+// manifest when addToManifest is set.
+func (bw *bundleWriter) write(name string, b []byte, addToManifest bool) error {
+	err := bw.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(b)),
+		ModTime:  bw.modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = bw.tw.Write(b)
+	if err != nil {
+		return err
+	}
+
+	if addToManifest {
+		bw.entries = append(bw.entries, www.ProposalBundleEntry{
+			Name:   name,
+			Digest: hex.EncodeToString(util.Digest(b)),
+			Size:   int64(len(b)),
+		})
+	}
+	return nil
+}
+
+// writeJSON writes the passed in value to the bundle as indented JSON.
+func (bw *bundleWriter) writeJSON(name string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return bw.write(name, b, true)
+}
+
+// bundleFileName returns the name of the bundle entry of the proposal file
+// with the passed in name.  Names that would escape the files directory are
+// rejected.
+func bundleFileName(name string) (string, error) {
+	base := path.Base(name)
+	if base != name || base == "." || base == ".." || base == "/" {
+		return "", fmt.Errorf("invalid proposal file name %q", name)
+	}
+	return www.BundleFilesDir + base, nil
+}
+
+// writeProposalBundle returns a tar archive of the passed in proposal bundle.
+// The archive contains the proposal record, the decoded proposal files, the
+// raw metadata streams, the comments and the vote results, followed by a
+// manifest of the digests of all entries and the signature of the manifest.
+// The manifest is signed by passing its SHA256 digest to sign.
+func writeProposalBundle(pb proposalBundle, serverPubKey string, now time.Time, sign func(digest []byte) (string, error)) ([]byte, error) {
+	var buf bytes.Buffer
+	bw := bundleWriter{
+		tw:      tar.NewWriter(&buf),
+		modTime: now,
+	}
+	err := bw.writeJSON(www.BundleEntryProposal, pb.proposal)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range pb.proposal.Files {
+		name, err := bundleFileName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		b, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode file %v: %v", f.Name, err)
+		}
+		err = bw.write(name, b, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range pb.metadata {
+		err = bw.write(fmt.Sprintf("%v%v", www.BundleMetadataDir, v.ID),
+			[]byte(v.Payload), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = bw.writeJSON(www.BundleEntryComments, pb.comments)
+	if err != nil {
+		return nil, err
+	}
+	err = bw.writeJSON(www.BundleEntryVotes, pb.votes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The manifest is written after all other entries since it contains
+	// their digests.  It is followed by its own signature.
+	m, err := json.MarshalIndent(www.ProposalBundleManifest{
+		Version:         www.ProposalBundleVersion,
+		Token:           pb.proposal.CensorshipRecord.Token,
+		ServerPublicKey: serverPubKey,
+		Timestamp:       now.Unix(),
+		Entries:         bw.entries,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(util.Digest(m))
+	if err != nil {
+		return nil, fmt.Errorf("sign manifest: %v", err)
+	}
+	err = bw.write(www.BundleEntryManifest, m, false)
+	if err != nil {
+		return nil, err
+	}
+	err = bw.write(www.BundleEntryManifestSignature, []byte(sig), false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bw.tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// signDigest has politeiad sign the passed in SHA256 digest with the server
+// identity.  The digest is sent as the challenge of an identity request;
+// politeiad replies with its signature of the challenge.
+func (p *politeiawww) signDigest(digest []byte) (string, error) {
+	if len(digest) != pd.ChallengeSize {
+		return "", fmt.Errorf("invalid digest size %v", len(digest))
+	}
+
+	responseBody, err := p.makeRequest(http.MethodPost, pd.IdentityRoute,
+		pd.Identity{
+			Challenge: hex.EncodeToString(digest),
+		})
+	if err != nil {
+		return "", err
+	}
+
+	var reply pd.IdentityReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return "", fmt.Errorf("Unmarshal IdentityReply: %v", err)
+	}
+
+	err = util.VerifyChallenge(p.cfg.Identity, digest, reply.Response)
+	if err != nil {
+		return "", err
+	}
+
+	return reply.Response, nil
+}
+
+// processProposalBundle returns the latest version of a public proposal as a
+// tar archive whose manifest is signed by the server.
+func (p *politeiawww) processProposalBundle(token string) (*www.ProposalBundleReply, error) {
+	log.Tracef("processProposalBundle: %v", token)
+
+	pr, err := p.getProp(token)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusProposalNotFound,
+			}
+		}
+		return nil, err
+	}
+	if pr.Status != www.PropStatusPublic {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongStatus,
+		}
+	}
+
+	r, err := p.cache.Record(token)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := p.getPropComments(token)
+	if err != nil {
+		return nil, err
+	}
+	vrr, err := p.ProcessVoteResults(token)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := writeProposalBundle(proposalBundle{
+		proposal: *pr,
+		metadata: convertMetadataStreamsFromCache(r.Metadata),
+		comments: www.GetCommentsReply{
+			Comments: comments,
+		},
+		votes: *vrr,
+	}, hex.EncodeToString(p.cfg.Identity.Key[:]), time.Now(), p.signDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.ProposalBundleReply{
+		Bundle: base64.StdEncoding.EncodeToString(b),
+	}, nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+func TestWriteProposalBundle(t *testing.T) {
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(digest []byte) (string, error) {
+		sig := id.SignMessage(digest)
+		return hex.EncodeToString(sig[:]), nil
+	}
+
+	payload := []byte("title\ndescription")
+	pb := proposalBundle{
+		proposal: www.ProposalRecord{
+			Files: []www.File{{
+				Name:    indexFile,
+				Payload: base64.StdEncoding.EncodeToString(payload),
+			}},
+			CensorshipRecord: www.CensorshipRecord{
+				Token: "token",
+			},
+		},
+		metadata: []www.MetadataStream{{
+			ID:      0,
+			Payload: `{"version":1}`,
+		}},
+	}
+
+	b, err := writeProposalBundle(pb, "pubkey", time.Now(), sign)
+	if err != nil {
+		t.Fatalf("writeProposalBundle: %v", err)
+	}
+
+	// Read the bundle entries in order
+	var names []string
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(bytes.NewReader(b))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		entries[hdr.Name] = data
+	}
+
+	// The manifest lists every entry other than itself and its signature
+	// and is followed by its signature
+	want := []string{
+		www.BundleEntryProposal,
+		www.BundleFilesDir + indexFile,
+		www.BundleMetadataDir + "0",
+		www.BundleEntryComments,
+		www.BundleEntryVotes,
+		www.BundleEntryManifest,
+		www.BundleEntryManifestSignature,
+	}
+	if len(names) != len(want) {
+		t.Fatalf("got entries %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got entries %v, want %v", names, want)
+		}
+	}
+
+	var m www.ProposalBundleManifest
+	err = json.Unmarshal(entries[www.BundleEntryManifest], &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Entries) != len(want)-2 {
+		t.Fatalf("got %v manifest entries, want %v", len(m.Entries),
+			len(want)-2)
+	}
+	for _, v := range m.Entries {
+		digest := hex.EncodeToString(util.Digest(entries[v.Name]))
+		if v.Digest != digest {
+			t.Errorf("entry %v: got digest %v, want %v", v.Name,
+				v.Digest, digest)
+		}
+	}
+	if !bytes.Equal(entries[www.BundleFilesDir+indexFile], payload) {
+		t.Errorf("proposal file was not decoded")
+	}
+
+	sig, err := util.ConvertSignature(
+		string(entries[www.BundleEntryManifestSignature]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !id.Public.VerifyMessage(
+		util.Digest(entries[www.BundleEntryManifest]), sig) {
+		t.Errorf("invalid manifest signature")
+	}
+
+	// Proposal file names must not escape the files directory
+	pb.proposal.Files[0].Name = "../" + indexFile
+	_, err = writeProposalBundle(pb, "pubkey", time.Now(), sign)
+	if err == nil {
+		t.Errorf("got nil error for invalid file name, want error")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"sync"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// activityConcurrency is the maximum number of per proposal requests that are
+// made concurrently when summarizing a user's activity.
+const activityConcurrency = 4
+
+// UserActivity summarizes the activity of a user.
+//
+// The completeness of some fields depends on who is requesting the summary:
+//   - ProposalsSubmitted only includes unvetted and censored proposals when
+//     the logged in user is an admin or is the user being summarized.
+//   - LikesGiven is only populated when the logged in user is the user being
+//     summarized, since politeiawww only returns the comment likes of the
+//     logged in user.  LikesAvailable reports whether it was populated.
+type UserActivity struct {
+	UserID             string `json:"userid"`             // User ID
+	Username           string `json:"username"`           // Username
+	ProposalsSubmitted int    `json:"proposalssubmitted"` // Proposals submitted
+	CommentsMade       int    `json:"commentsmade"`       // Comments made on vetted proposals
+	CommentsCensored   int    `json:"commentscensored"`   // Comments that were censored
+	LikesGiven         int    `json:"likesgiven"`         // Comment likes given
+	LikesAvailable     bool   `json:"likesavailable"`     // Whether LikesGiven is populated
+}
+
+// UserActivitySummary returns a summary of the proposals, comments and comment
+// likes of the specified user.  See UserActivity for the fields whose
+// completeness depends on the privileges of the logged in user.
+//
+// politeiawww does not provide an aggregate user activity route so the
+// summary is composed from existing routes.  Comments are counted by fetching
+// the comments of every vetted proposal, which makes one request per vetted
+// proposal.  The requests are made concurrently.  Ticket votes are not linked
+// to users so votes cast are not included in the summary.
+func (c *Client) UserActivitySummary(userID string) (*UserActivity, error) {
+	// The logged in user determines whether the comment likes can be
+	// summarized.  Not being logged in is not an error.
+	var self bool
+	lr, err := c.Me()
+	if err == nil {
+		self = lr.UserID == userID
+	}
+
+	var (
+		wg   sync.WaitGroup
+		udr  *v1.UserDetailsReply
+		upr  *v1.UserProposalsReply
+		prs  []v1.ProposalRecord
+		errs = make([]error, 3)
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		udr, errs[0] = c.UserDetails(userID)
+	}()
+	go func() {
+		defer wg.Done()
+		upr, errs[1] = c.UserProposals(&v1.UserProposals{
+			UserId: userID,
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		prs, errs[2] = c.allVettedProposals()
+	}()
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ua := UserActivity{
+		UserID:             userID,
+		Username:           udr.User.Username,
+		ProposalsSubmitted: upr.NumOfProposals,
+		LikesAvailable:     self,
+	}
+	err = c.tallyUserComments(&ua, prs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ua, nil
+}
+
+// tallyUserComments adds the comments, and when available the comment likes,
+// that the user made on the passed in proposals to the user activity.
+func (c *Client) tallyUserComments(ua *UserActivity, prs []v1.ProposalRecord) error {
+	var (
+		wg       sync.WaitGroup
+		mtx      sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, activityConcurrency)
+	)
+	for _, v := range prs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(token string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			var made, censored, likes int
+			gcr, err := c.GetComments(token)
+			if err == nil {
+				for _, v := range gcr.Comments {
+					if v.UserID != ua.UserID {
+						continue
+					}
+					made++
+					if v.Censored {
+						censored++
+					}
+				}
+			}
+			if err == nil && ua.LikesAvailable {
+				var uclr *v1.UserCommentsLikesReply
+				uclr, err = c.UserCommentsLikes(token)
+				if err == nil {
+					likes = len(uclr.CommentsLikes)
+				}
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			ua.CommentsMade += made
+			ua.CommentsCensored += censored
+			ua.LikesGiven += likes
+		}(v.CensorshipRecord.Token)
+	}
+	wg.Wait()
+
+	return firstErr
+}
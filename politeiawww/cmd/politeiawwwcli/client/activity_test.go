@@ -0,0 +1,142 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// newActivityTestServer returns a TLS test server with two vetted proposals,
+// "token1" and "token2", that have comments from the users "user1" and
+// "user2".  The logged in user is loggedIn.
+func newActivityTestServer(loggedIn string) *httptest.Server {
+	comments := map[string][]v1.Comment{
+		"token1": {
+			{CommentID: "1", UserID: "user1"},
+			{CommentID: "2", UserID: "user2"},
+			{CommentID: "3", UserID: "user1", Censored: true},
+		},
+		"token2": {
+			{CommentID: "1", UserID: "user1"},
+		},
+	}
+	likes := map[string][]v1.CommentLike{
+		"token1": {{CommentID: "2"}},
+		"token2": {{CommentID: "1"}, {CommentID: "2"}},
+	}
+
+	return httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			route := strings.TrimPrefix(r.URL.Path, v1.PoliteiaWWWAPIRoute)
+			parts := strings.Split(strings.Trim(route, "/"), "/")
+			var reply interface{}
+			switch {
+			case route == v1.RoutePolicy:
+				reply = v1.PolicyReply{
+					ProposalListPageSize: 2,
+				}
+			case route == v1.RouteUserMe:
+				if loggedIn == "" {
+					w.WriteHeader(http.StatusForbidden)
+					reply = v1.UserError{
+						ErrorCode: v1.ErrorStatusNotLoggedIn,
+					}
+					break
+				}
+				reply = v1.LoginReply{
+					UserID: loggedIn,
+				}
+			case route == v1.RouteUserProposals:
+				reply = v1.UserProposalsReply{
+					NumOfProposals: 2,
+				}
+			case route == v1.RouteAllVetted:
+				var props []v1.ProposalRecord
+				if r.URL.Query().Get("after") == "" {
+					props = []v1.ProposalRecord{
+						{CensorshipRecord: v1.CensorshipRecord{Token: "token1"}},
+						{CensorshipRecord: v1.CensorshipRecord{Token: "token2"}},
+					}
+				}
+				reply = v1.GetAllVettedReply{
+					Proposals: props,
+				}
+			case len(parts) == 3 && parts[0] == "proposals" &&
+				parts[2] == "comments":
+				reply = v1.GetCommentsReply{
+					Comments: comments[parts[1]],
+				}
+			case len(parts) == 4 && parts[3] == "commentslikes":
+				reply = v1.UserCommentsLikesReply{
+					CommentsLikes: likes[parts[2]],
+				}
+			case len(parts) == 2 && parts[0] == "user":
+				reply = v1.UserDetailsReply{
+					User: v1.User{
+						ID:       parts[1],
+						Username: "username",
+					},
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(reply)
+		}))
+}
+
+func TestUserActivitySummary(t *testing.T) {
+	var tests = []struct {
+		name     string
+		loggedIn string
+		want     UserActivity
+	}{
+		{"not logged in", "", UserActivity{
+			UserID:             "user1",
+			Username:           "username",
+			ProposalsSubmitted: 2,
+			CommentsMade:       3,
+			CommentsCensored:   1,
+		}},
+		{"logged in as other user", "user2", UserActivity{
+			UserID:             "user1",
+			Username:           "username",
+			ProposalsSubmitted: 2,
+			CommentsMade:       3,
+			CommentsCensored:   1,
+		}},
+		{"logged in as user", "user1", UserActivity{
+			UserID:             "user1",
+			Username:           "username",
+			ProposalsSubmitted: 2,
+			CommentsMade:       3,
+			CommentsCensored:   1,
+			LikesGiven:         3,
+			LikesAvailable:     true,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newActivityTestServer(test.loggedIn)
+			defer s.Close()
+			c := newTestClient(t, s, true)
+
+			ua, err := c.UserActivitySummary("user1")
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if *ua != test.want {
+				t.Fatalf("got %+v, want %+v", *ua, test.want)
+			}
+		})
+	}
+}
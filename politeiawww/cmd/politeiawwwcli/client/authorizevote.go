@@ -0,0 +1,98 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// checkAuthorizeVoteStatus returns an error if the passed in authorize vote
+// action can not be applied to a proposal with the passed in vote status.  A
+// vote can only be authorized when it has not been authorized yet and an
+// authorization can only be revoked before the vote has started.
+func checkAuthorizeVoteStatus(action string, status v1.PropVoteStatusT) error {
+	switch status {
+	case v1.PropVoteStatusNotAuthorized:
+		if action == v1.AuthVoteActionRevoke {
+			return fmt.Errorf("vote has not been authorized")
+		}
+	case v1.PropVoteStatusAuthorized:
+		if action == v1.AuthVoteActionAuthorize {
+			return fmt.Errorf("vote has already been authorized")
+		}
+	case v1.PropVoteStatusStarted:
+		return fmt.Errorf("vote has already started")
+	case v1.PropVoteStatusFinished:
+		return fmt.Errorf("vote has already finished")
+	case v1.PropVoteStatusDoesntExist:
+		return fmt.Errorf("proposal not found")
+	default:
+		return fmt.Errorf("invalid vote status %v", status)
+	}
+	return nil
+}
+
+// authorizeVoteAction signs and sends an authorize vote request with the
+// passed in action for the specified proposal.  The vote status of the
+// proposal is checked before the request is sent and the server receipt is
+// verified once it has been received.
+func (c *Client) authorizeVoteAction(token, action string, id *identity.FullIdentity) (*v1.AuthorizeVoteReply, error) {
+	if id == nil {
+		return nil, fmt.Errorf("user identity not found")
+	}
+
+	vsr, err := c.VoteStatus(token)
+	if err != nil {
+		return nil, err
+	}
+	err = checkAuthorizeVoteStatus(action, vsr.Status)
+	if err != nil {
+		return nil, fmt.Errorf("cannot %v vote: %v", action, err)
+	}
+
+	// The signature covers the proposal version
+	pdr, err := c.ProposalDetails(token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := id.SignMessage([]byte(token + pdr.Proposal.Version + action))
+	av := &v1.AuthorizeVote{
+		Action:    action,
+		Token:     token,
+		PublicKey: hex.EncodeToString(id.Public.Key[:]),
+		Signature: hex.EncodeToString(sig[:]),
+	}
+	avr, err := c.AuthorizeVote(av)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.VerifyServerSignature(av.Signature, avr.Receipt)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify authorize vote receipt: %v",
+			err)
+	}
+
+	return avr, nil
+}
+
+// AuthorizeProposalVote authorizes the vote of the specified proposal using
+// the passed in identity, which must belong to the proposal author.  An error
+// is returned if the vote has already been authorized or started.
+func (c *Client) AuthorizeProposalVote(token string, id *identity.FullIdentity) (*v1.AuthorizeVoteReply, error) {
+	return c.authorizeVoteAction(token, v1.AuthVoteActionAuthorize, id)
+}
+
+// RevokeVoteAuthorization revokes the vote authorization of the specified
+// proposal using the passed in identity, which must belong to the proposal
+// author.  An authorization can only be revoked before the vote has started.
+func (c *Client) RevokeVoteAuthorization(token string, id *identity.FullIdentity) (*v1.AuthorizeVoteReply, error) {
+	return c.authorizeVoteAction(token, v1.AuthVoteActionRevoke, id)
+}
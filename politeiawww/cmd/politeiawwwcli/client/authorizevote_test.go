@@ -0,0 +1,88 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestCheckAuthorizeVoteStatus(t *testing.T) {
+	var tests = []struct {
+		name    string
+		action  string
+		status  v1.PropVoteStatusT
+		wantErr bool
+	}{
+		{"authorize", v1.AuthVoteActionAuthorize,
+			v1.PropVoteStatusNotAuthorized, false},
+		{"authorize authorized", v1.AuthVoteActionAuthorize,
+			v1.PropVoteStatusAuthorized, true},
+		{"revoke", v1.AuthVoteActionRevoke,
+			v1.PropVoteStatusAuthorized, false},
+		{"revoke not authorized", v1.AuthVoteActionRevoke,
+			v1.PropVoteStatusNotAuthorized, true},
+		{"revoke started", v1.AuthVoteActionRevoke,
+			v1.PropVoteStatusStarted, true},
+		{"revoke finished", v1.AuthVoteActionRevoke,
+			v1.PropVoteStatusFinished, true},
+		{"authorize started", v1.AuthVoteActionAuthorize,
+			v1.PropVoteStatusStarted, true},
+		{"proposal not found", v1.AuthVoteActionAuthorize,
+			v1.PropVoteStatusDoesntExist, true},
+		{"invalid status", v1.AuthVoteActionAuthorize,
+			v1.PropVoteStatusInvalid, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkAuthorizeVoteStatus(test.action, test.status)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %v", err,
+					test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokeVoteAuthorizationStarted(t *testing.T) {
+	var authorizeRequests int
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case v1.PoliteiaWWWAPIRoute + "/proposals/token/votestatus":
+				json.NewEncoder(w).Encode(v1.VoteStatusReply{
+					Token:  "token",
+					Status: v1.PropVoteStatusStarted,
+				})
+			case v1.PoliteiaWWWAPIRoute + v1.RouteAuthorizeVote:
+				authorizeRequests++
+				w.WriteHeader(http.StatusBadRequest)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.RevokeVoteAuthorization("token", id)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+	if authorizeRequests != 0 {
+		t.Errorf("got %v authorize vote requests, want 0",
+			authorizeRequests)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// isSessionError returns whether the passed in response was caused by the
+// session of the user being missing or expired.
+func isSessionError(statusCode int, body []byte) bool {
+	if statusCode != http.StatusUnauthorized {
+		return false
+	}
+	var ue v1.UserError
+	err := json.Unmarshal(body, &ue)
+	if err != nil {
+		return false
+	}
+	return ue.ErrorCode == v1.ErrorStatusNotLoggedIn ||
+		ue.ErrorCode == v1.ErrorStatusSessionExpired
+}
+
+// canRelogin returns whether a request that failed because of a session error
+// can be retried after logging in again.
+func (c *Client) canRelogin() bool {
+	return c.cfg.AutoLogin && c.cfg.Credentials != nil
+}
+
+// relogin logs the user in again using the stored credentials and resends the
+// passed in request.  The stored credentials are cleared when they are
+// rejected by the server or when the resent request still fails because of a
+// session error, so that subsequent commands fail fast instead of repeatedly
+// attempting to log in.
+func (c *Client) relogin(method, fullRoute string, requestBody []byte) (*http.Response, []byte, error) {
+	if c.cfg.Verbose {
+		fmt.Printf("Session expired; logging in again\n")
+	}
+
+	cr := c.cfg.Credentials
+	_, err := c.Login(&v1.Login{
+		Email:    cr.Email,
+		Password: cr.Password,
+	})
+	if err != nil {
+		if _, ok := err.(*APIError); ok {
+			if err := c.cfg.ClearCredentials(); err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, nil, fmt.Errorf("auto login: %v", err)
+	}
+
+	r, responseBody, err := c.sendRequest(method, fullRoute, requestBody, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if isSessionError(r.StatusCode, responseBody) {
+		err = c.cfg.ClearCredentials()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return r, responseBody, nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// newSessionTestServer returns a TLS test server that only responds to the
+// me route when the request contains the session cookie that is set by the
+// login route.  Logging in only succeeds with the passed in password.  The
+// returned counter is the number of login requests that were received.
+func newSessionTestServer(password string) (*httptest.Server, *int64) {
+	var logins int64
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case v1.PoliteiaWWWAPIRoute + v1.RouteLogin:
+				atomic.AddInt64(&logins, 1)
+				var l v1.Login
+				json.NewDecoder(r.Body).Decode(&l)
+				if l.Password != password {
+					w.WriteHeader(http.StatusUnauthorized)
+					json.NewEncoder(w).Encode(v1.UserError{
+						ErrorCode: v1.ErrorStatusInvalidEmailOrPassword,
+					})
+					return
+				}
+				http.SetCookie(w, &http.Cookie{
+					Name:  "session",
+					Value: "valid",
+					Path:  "/",
+				})
+				json.NewEncoder(w).Encode(v1.LoginReply{
+					Email: l.Email,
+				})
+			case v1.PoliteiaWWWAPIRoute + v1.RouteUserMe:
+				ck, err := r.Cookie("session")
+				if err != nil || ck.Value != "valid" {
+					w.WriteHeader(http.StatusUnauthorized)
+					json.NewEncoder(w).Encode(v1.UserError{
+						ErrorCode: v1.ErrorStatusSessionExpired,
+					})
+					return
+				}
+				json.NewEncoder(w).Encode(v1.LoginReply{
+					Email: "user@example.com",
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	return s, &logins
+}
+
+// newAutoLoginTestClient returns a test client with auto login enabled whose
+// profile data is stored in a temporary directory.  The stored credentials
+// use the passed in password.
+func newAutoLoginTestClient(t *testing.T, s *httptest.Server, password string) (*Client, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	err = os.MkdirAll(filepath.Join(dir, "profiles", "test"), 0700)
+	if err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	c := newTestClient(t, s, true)
+	c.cfg.DataDir = dir
+	c.cfg.Profile = "test"
+	c.cfg.AutoLogin = true
+	err = c.cfg.SaveCredentials("user@example.com", password)
+	if err != nil {
+		t.Fatalf("SaveCredentials: %v", err)
+	}
+
+	return c, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestAutoLogin(t *testing.T) {
+	s, logins := newSessionTestServer("password")
+	defer s.Close()
+	c, cleanup := newAutoLoginTestClient(t, s, "password")
+	defer cleanup()
+
+	// The expired session is renewed by logging in again
+	lr, err := c.Me()
+	if err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	if lr.Email != "user@example.com" {
+		t.Fatalf("got email %v, want user@example.com", lr.Email)
+	}
+	if n := atomic.LoadInt64(logins); n != 1 {
+		t.Fatalf("got %v logins, want 1", n)
+	}
+
+	// The renewed session is reused
+	_, err = c.Me()
+	if err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	if n := atomic.LoadInt64(logins); n != 1 {
+		t.Fatalf("got %v logins, want 1", n)
+	}
+}
+
+func TestAutoLoginInvalidCredentials(t *testing.T) {
+	s, logins := newSessionTestServer("password")
+	defer s.Close()
+	c, cleanup := newAutoLoginTestClient(t, s, "wrong")
+	defer cleanup()
+
+	_, err := c.Me()
+	if err == nil {
+		t.Fatalf("got nil error, want auto login error")
+	}
+	if c.cfg.Credentials != nil {
+		t.Fatalf("rejected credentials were not cleared")
+	}
+
+	// Logging in is not attempted again without credentials
+	_, err = c.Me()
+	if err == nil {
+		t.Fatalf("got nil error, want session error")
+	}
+	if n := atomic.LoadInt64(logins); n != 1 {
+		t.Fatalf("got %v logins, want 1", n)
+	}
+}
+
+func TestAutoLoginRetryFails(t *testing.T) {
+	// Logging in succeeds but the session is never accepted
+	var logins int64
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case v1.PoliteiaWWWAPIRoute + v1.RouteLogin:
+				atomic.AddInt64(&logins, 1)
+				json.NewEncoder(w).Encode(v1.LoginReply{
+					Email: "user@example.com",
+				})
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusSessionExpired,
+				})
+			}
+		}))
+	defer s.Close()
+	c, cleanup := newAutoLoginTestClient(t, s, "password")
+	defer cleanup()
+
+	_, err := c.Me()
+	e, ok := err.(*APIError)
+	if !ok || e.ErrorCode != v1.ErrorStatusSessionExpired {
+		t.Fatalf("got error %v, want session expired", err)
+	}
+	if c.cfg.Credentials != nil {
+		t.Fatalf("credentials were not cleared after the retry failed")
+	}
+	f := filepath.Join(c.cfg.DataDir, "profiles", "test", "credentials.json")
+	if _, err := os.Stat(f); !os.IsNotExist(err) {
+		t.Fatalf("stored credentials were not removed: %v", err)
+	}
+
+	// The request is retried once and logging in is not attempted again
+	// without credentials.
+	_, err = c.Me()
+	if err == nil {
+		t.Fatalf("got nil error, want session error")
+	}
+	if n := atomic.LoadInt64(&logins); n != 1 {
+		t.Fatalf("got %v logins, want 1", n)
+	}
+}
+
+func TestAutoLoginDisabled(t *testing.T) {
+	s, logins := newSessionTestServer("password")
+	defer s.Close()
+	c, cleanup := newAutoLoginTestClient(t, s, "password")
+	defer cleanup()
+	c.cfg.AutoLogin = false
+
+	_, err := c.Me()
+	e, ok := err.(*APIError)
+	if !ok || e.ErrorCode != v1.ErrorStatusSessionExpired {
+		t.Fatalf("got error %v, want session expired", err)
+	}
+	if n := atomic.LoadInt64(logins); n != 0 {
+		t.Fatalf("got %v logins, want 0", n)
+	}
+}
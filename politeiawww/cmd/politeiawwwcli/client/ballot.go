@@ -0,0 +1,219 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// ballotCSVHeader is the header row of a ballot file in CSV format.
+var ballotCSVHeader = []string{"token", "ticket", "votebit", "signature"}
+
+// isCSVFile returns whether the passed in path has a .csv extension.  Ballot
+// files with any other extension are JSON encoded.
+func isCSVFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+// LoadBallotFromFile reads a signed ballot from the file at the passed in
+// path and validates its structure so that it can be passed to CastVotes.
+// Files with a .csv extension contain a header row followed by one row per
+// vote with the columns token, ticket, votebit and signature.  All other files
+// contain a JSON encoded v1.Ballot.
+//
+// Only the structure of the votes is validated.  The ticket signatures are
+// verified by politeiawww when the ballot is cast.
+func LoadBallotFromFile(path string) (*v1.Ballot, error) {
+	path = util.CleanAndExpandPath(path)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var b *v1.Ballot
+	if isCSVFile(path) {
+		b, err = readBallotCSV(f)
+	} else {
+		b, err = readBallotJSON(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	err = validateBallot(b)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	return b, nil
+}
+
+// SaveBallotToFile validates the passed in signed ballot and writes it to the
+// file at the passed in path so that it can be cast later, possibly from a
+// different machine.  The file format is chosen by the extension of the path
+// the same way as in LoadBallotFromFile.
+func SaveBallotToFile(path string, b *v1.Ballot) error {
+	err := validateBallot(b)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if isCSVFile(path) {
+		var sb strings.Builder
+		err = writeBallotCSV(&sb, b)
+		data = []byte(sb.String())
+	} else {
+		data, err = json.MarshalIndent(b, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a failed write does not
+	// leave a partial ballot behind.
+	path = util.CleanAndExpandPath(path)
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readBallotJSON decodes a JSON encoded ballot from r.  Unknown fields are
+// rejected so that files in an unexpected format are not silently accepted.
+func readBallotJSON(r io.Reader) (*v1.Ballot, error) {
+	var b v1.Ballot
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	err := d.Decode(&b)
+	if err != nil {
+		return nil, fmt.Errorf("decode Ballot: %v", err)
+	}
+	return &b, nil
+}
+
+// readBallotCSV decodes a CSV encoded ballot from r.  The first row must be
+// the ballot header row.
+func readBallotCSV(r io.Reader) (*v1.Ballot, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(ballotCSVHeader)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing header row")
+	} else if err != nil {
+		return nil, err
+	}
+	for i, v := range ballotCSVHeader {
+		if strings.TrimSpace(header[i]) != v {
+			return nil, fmt.Errorf("invalid header row: got %v, want %v",
+				strings.Join(header, ","),
+				strings.Join(ballotCSVHeader, ","))
+		}
+	}
+
+	var b v1.Ballot
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		b.Votes = append(b.Votes, v1.CastVote{
+			Token:     row[0],
+			Ticket:    row[1],
+			VoteBit:   row[2],
+			Signature: row[3],
+		})
+	}
+
+	return &b, nil
+}
+
+// writeBallotCSV writes the passed in ballot to w as CSV.
+func writeBallotCSV(w io.Writer, b *v1.Ballot) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(ballotCSVHeader)
+	if err != nil {
+		return err
+	}
+	for _, v := range b.Votes {
+		err = cw.Write([]string{v.Token, v.Ticket, v.VoteBit, v.Signature})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// validateBallot validates the structure of the votes of a ballot.  A ballot
+// must contain at least one vote and a ticket may only vote once on a
+// proposal.
+func validateBallot(b *v1.Ballot) error {
+	if b == nil || len(b.Votes) == 0 {
+		return fmt.Errorf("ballot contains no votes")
+	}
+
+	voted := make(map[string]struct{}, len(b.Votes)) // [token+ticket]
+	for i, v := range b.Votes {
+		err := validateCastVote(v)
+		if err != nil {
+			return fmt.Errorf("vote %v: %v", i, err)
+		}
+		if _, ok := voted[v.Token+v.Ticket]; ok {
+			return fmt.Errorf("vote %v: duplicate vote for ticket %v",
+				i, v.Ticket)
+		}
+		voted[v.Token+v.Ticket] = struct{}{}
+	}
+
+	return nil
+}
+
+// validateCastVote validates the structure of a single signed vote.
+func validateCastVote(cv v1.CastVote) error {
+	if !isHexOfLength(cv.Token, 64) {
+		return fmt.Errorf("invalid token %q", cv.Token)
+	}
+	if !isHexOfLength(cv.Ticket, 64) {
+		return fmt.Errorf("invalid ticket %q", cv.Ticket)
+	}
+	if _, err := strconv.ParseUint(cv.VoteBit, 16, 64); err != nil {
+		return fmt.Errorf("invalid vote bit %q", cv.VoteBit)
+	}
+	if _, err := hex.DecodeString(cv.Signature); err != nil ||
+		cv.Signature == "" {
+		return fmt.Errorf("invalid signature %q", cv.Signature)
+	}
+	return nil
+}
+
+// isHexOfLength returns whether s is a hex encoded string of exactly n
+// characters.
+func isHexOfLength(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
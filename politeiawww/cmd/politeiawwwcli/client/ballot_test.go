@@ -0,0 +1,106 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestSaveAndLoadBallot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	token := strings.Repeat("ab", 32)
+	b := &v1.Ballot{
+		Votes: []v1.CastVote{
+			{
+				Token:     token,
+				Ticket:    strings.Repeat("01", 32),
+				VoteBit:   "1",
+				Signature: strings.Repeat("1f", 65),
+			},
+			{
+				Token:     token,
+				Ticket:    strings.Repeat("02", 32),
+				VoteBit:   "2",
+				Signature: strings.Repeat("20", 65),
+			},
+		},
+	}
+
+	for _, name := range []string{"ballot.json", "ballot.csv"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			err := SaveBallotToFile(path, b)
+			if err != nil {
+				t.Fatalf("SaveBallotToFile: %v", err)
+			}
+			got, err := LoadBallotFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadBallotFromFile: %v", err)
+			}
+			if !reflect.DeepEqual(got, b) {
+				t.Fatalf("got %v, want %v", got, b)
+			}
+		})
+	}
+}
+
+func TestLoadBallotFromFileInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	token := strings.Repeat("ab", 32)
+	ticket := strings.Repeat("01", 32)
+	sig := strings.Repeat("1f", 65)
+	row := token + "," + ticket + ",1," + sig + "\n"
+	header := "token,ticket,votebit,signature\n"
+
+	var tests = []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"empty ballot", "ballot.json", `{"votes":[]}`},
+		{"unknown field", "ballot.json", `{"votes":[],"token":"x"}`},
+		{"missing header", "ballot.csv", row},
+		{"missing column", "ballot.csv",
+			header + token + "," + ticket + ",1\n"},
+		{"invalid ticket", "ballot.csv",
+			header + token + ",abc,1," + sig + "\n"},
+		{"invalid vote bit", "ballot.csv",
+			header + token + "," + ticket + ",x," + sig + "\n"},
+		{"invalid signature", "ballot.csv",
+			header + token + "," + ticket + ",1,zz\n"},
+		{"duplicate ticket", "ballot.csv", header + row + row},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.file)
+			err := ioutil.WriteFile(path, []byte(test.content), 0600)
+			if err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			_, err = LoadBallotFromFile(path)
+			if err == nil {
+				t.Fatalf("got nil error, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// BlockHeight returns the best block of the Decred blockchain as seen by
+// politeiawww.  Synced is unset when politeiawww is syncing or its view of the
+// blockchain is stale, in which case the vote heights and payment
+// confirmations that it reports lag behind the network.
+func (c *Client) BlockHeight() (*v1.BlockHeightReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteBlockHeight, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var bhr v1.BlockHeightReply
+	err = json.Unmarshal(responseBody, &bhr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal BlockHeightReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(bhr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &bhr, nil
+}
+
+// checkSync fetches the best block of politeiawww and prints a warning when
+// politeiawww is not synced.  The warning is only printed when the passed in
+// previous reply is nil or synced so that polling does not repeat it.
+func (c *Client) checkSync(prev *v1.BlockHeightReply) (*v1.BlockHeightReply, error) {
+	bhr, err := c.BlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.Silent || c.cfg.JSONOnly || bhr.Synced ||
+		(prev != nil && !prev.Synced) {
+		return bhr, nil
+	}
+	fmt.Printf("Warning: politeiawww is not synced; its best block %v "+
+		"was first seen at %v\n", bhr.Height,
+		time.Unix(bhr.Timestamp, 0).Format(time.RFC1123))
+	return bhr, nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestBlockHeight(t *testing.T) {
+	var tests = []struct {
+		name  string
+		reply v1.BlockHeightReply
+	}{
+		{"synced", v1.BlockHeightReply{
+			Height:    321604,
+			Timestamp: 1550243710,
+			Synced:    true,
+		}},
+		{"stale", v1.BlockHeightReply{
+			Height:    321600,
+			Timestamp: 1550240000,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+						v1.RouteBlockHeight {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(test.reply)
+				}))
+			defer s.Close()
+			c := newTestClient(t, s, true)
+			c.cfg.Silent = true
+
+			bhr, err := c.checkSync(nil)
+			if err != nil {
+				t.Fatalf("checkSync: %v", err)
+			}
+			if *bhr != test.reply {
+				t.Errorf("got %+v, want %+v", *bhr, test.reply)
+			}
+		})
+	}
+}
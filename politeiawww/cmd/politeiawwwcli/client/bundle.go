@@ -0,0 +1,234 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// ExportProposalBundle writes the latest version of the specified public
+// proposal to w as a tar archive that can be verified offline using
+// VerifyProposalBundle.  The archive is created by politeiawww and contains
+// the proposal record, the decoded proposal files, the raw metadata streams,
+// the comments and the vote results, followed by a manifest of the digests of
+// all entries that is signed by the server.
+//
+// The bundle is verified against the server public key before it is written
+// so that a bundle that does not verify is never exported.
+func (c *Client) ExportProposalBundle(token string, w io.Writer) error {
+	serverID, err := c.ServerPublicKey()
+	if err != nil {
+		return err
+	}
+
+	responseBody, err := c.makeRequest("GET", "/proposals/"+token+"/bundle",
+		nil)
+	if err != nil {
+		return err
+	}
+	var pbr v1.ProposalBundleReply
+	err = json.Unmarshal(responseBody, &pbr)
+	if err != nil {
+		return fmt.Errorf("unmarshal ProposalBundleReply: %v", err)
+	}
+	b, err := base64.StdEncoding.DecodeString(pbr.Bundle)
+	if err != nil {
+		return fmt.Errorf("decode bundle: %v", err)
+	}
+
+	m, err := VerifyProposalBundle(bytes.NewReader(b),
+		hex.EncodeToString(serverID.Key[:]))
+	if err != nil {
+		return fmt.Errorf("verify proposal bundle %v: %v", token, err)
+	}
+	if m.Token != token {
+		return fmt.Errorf("got bundle of proposal %v, want %v", m.Token,
+			token)
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// bundleFileName returns the name of the bundle entry of the proposal file
+// with the passed in name.  Names that would escape the files directory are
+// rejected.
+func bundleFileName(name string) (string, error) {
+	base := path.Base(name)
+	if base != name || base == "." || base == ".." || base == "/" {
+		return "", fmt.Errorf("invalid proposal file name %q", name)
+	}
+	return v1.BundleFilesDir + base, nil
+}
+
+// VerifyProposalBundle verifies a proposal bundle that was written by
+// ExportProposalBundle without contacting politeiawww.  The manifest
+// signature is verified, the digests of all entries are checked against the
+// manifest, the proposal files are checked against the proposal record, and
+// the author signatures of the proposal and of all comments that have not
+// been censored or deleted are verified, as are the server receipts of all
+// comments.  The manifest, the censorship record and the comment receipts
+// must have been signed by the passed in hex encoded server public key; the
+// key in the manifest is not trusted.  The manifest is returned on success.
+func VerifyProposalBundle(r io.Reader, serverPubKey string) (*v1.ProposalBundleManifest, error) {
+	serverID, err := util.IdentityFromString(serverPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server public key %v: %v",
+			serverPubKey, err)
+	}
+
+	// Read all entries
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("entry %v: not a regular file",
+				hdr.Name)
+		}
+		if _, ok := entries[hdr.Name]; ok {
+			return nil, fmt.Errorf("entry %v: duplicate entry", hdr.Name)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("entry %v: %v", hdr.Name, err)
+		}
+		entries[hdr.Name] = b
+	}
+
+	// Verify the manifest signature before the manifest is trusted
+	mb, ok := entries[v1.BundleEntryManifest]
+	if !ok {
+		return nil, fmt.Errorf("entry %v: not found", v1.BundleEntryManifest)
+	}
+	sig, ok := entries[v1.BundleEntryManifestSignature]
+	if !ok {
+		return nil, fmt.Errorf("entry %v: not found",
+			v1.BundleEntryManifestSignature)
+	}
+	err = verifyServerSignature(serverID, string(util.Digest(mb)),
+		string(sig))
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %v", err)
+	}
+
+	// Verify the entries against the manifest
+	var m v1.ProposalBundleManifest
+	err = decodeBundleEntry(entries, v1.BundleEntryManifest, &m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Version != v1.ProposalBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %v", m.Version)
+	}
+	if len(m.Entries) != len(entries)-2 {
+		return nil, fmt.Errorf("manifest lists %v entries, bundle "+
+			"contains %v", len(m.Entries), len(entries)-2)
+	}
+	digests := make(map[string]string, len(m.Entries)) // [name]digest
+	for _, v := range m.Entries {
+		b, ok := entries[v.Name]
+		if !ok || v.Name == v1.BundleEntryManifest ||
+			v.Name == v1.BundleEntryManifestSignature {
+			return nil, fmt.Errorf("entry %v: not found", v.Name)
+		}
+		digest := hex.EncodeToString(util.Digest(b))
+		if digest != v.Digest {
+			return nil, fmt.Errorf("entry %v: digest mismatch: got %v, "+
+				"want %v", v.Name, digest, v.Digest)
+		}
+		digests[v.Name] = digest
+	}
+
+	// Verify the proposal record
+	var p v1.ProposalRecord
+	err = decodeBundleEntry(entries, v1.BundleEntryProposal, &p)
+	if err != nil {
+		return nil, err
+	}
+	if p.CensorshipRecord.Token != m.Token {
+		return nil, fmt.Errorf("proposal token %v does not match "+
+			"manifest token %v", p.CensorshipRecord.Token, m.Token)
+	}
+	err = verifyProposalAuthor(p)
+	if err != nil {
+		return nil, fmt.Errorf("proposal: %v", err)
+	}
+	err = verifyServerSignature(serverID, p.CensorshipRecord.Merkle+
+		p.CensorshipRecord.Token, p.CensorshipRecord.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("censorship record: %v", err)
+	}
+	for _, f := range p.Files {
+		name, err := bundleFileName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if digests[name] != f.Digest {
+			return nil, fmt.Errorf("entry %v: does not match proposal "+
+				"file digest %v", name, f.Digest)
+		}
+	}
+
+	// Verify the comments.  The content of censored and deleted comments
+	// has been removed so only their receipts can be verified.
+	var gcr v1.GetCommentsReply
+	err = decodeBundleEntry(entries, v1.BundleEntryComments, &gcr)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range gcr.Comments {
+		if v.Token != p.CensorshipRecord.Token {
+			return nil, fmt.Errorf("comment %v: token %v does not match "+
+				"proposal token", v.CommentID, v.Token)
+		}
+		err = verifyServerSignature(serverID, v.Signature, v.Receipt)
+		if err != nil {
+			return nil, fmt.Errorf("comment %v receipt: %v", v.CommentID,
+				err)
+		}
+		if v.Censored || v.Deleted != 0 {
+			continue
+		}
+		err = VerifyComment(v)
+		if err != nil {
+			return nil, fmt.Errorf("comment %v: %v", v.CommentID, err)
+		}
+	}
+
+	return &m, nil
+}
+
+// decodeBundleEntry decodes the JSON encoded bundle entry with the passed in
+// name into v.
+func decodeBundleEntry(entries map[string][]byte, name string, v interface{}) error {
+	b, ok := entries[name]
+	if !ok {
+		return fmt.Errorf("entry %v: not found", name)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	err := d.Decode(v)
+	if err != nil {
+		return fmt.Errorf("entry %v: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// bundleEntry is a single entry of a test proposal bundle.
+type bundleEntry struct {
+	name string
+	data []byte
+}
+
+// writeTestBundle returns a tar archive of the passed in entries followed by
+// a manifest of the entries that is signed by the passed in identity.
+func writeTestBundle(t *testing.T, id *identity.FullIdentity, token string, entries []bundleEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	write := func(name string, b []byte) {
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(b)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tw.Write(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := v1.ProposalBundleManifest{
+		Version:         v1.ProposalBundleVersion,
+		Token:           token,
+		ServerPublicKey: hex.EncodeToString(id.Public.Key[:]),
+	}
+	for _, v := range entries {
+		write(v.name, v.data)
+		m.Entries = append(m.Entries, v1.ProposalBundleEntry{
+			Name:   v.name,
+			Digest: hex.EncodeToString(util.Digest(v.data)),
+			Size:   int64(len(v.data)),
+		})
+	}
+	mb, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := id.SignMessage(util.Digest(mb))
+	write(v1.BundleEntryManifest, mb)
+	write(v1.BundleEntryManifestSignature, []byte(hex.EncodeToString(sig[:])))
+
+	err = tw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProposalBundle(t *testing.T) {
+	serverID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverPubKey := hex.EncodeToString(serverID.Public.Key[:])
+
+	// Setup a proposal that is signed by the author and has a censorship
+	// record that is signed by the server
+	payload := []byte("title\ndescription")
+	files := []v1.File{{
+		Name:    indexFile,
+		MIME:    "text/plain; charset=utf-8",
+		Digest:  hex.EncodeToString(util.Digest(payload)),
+		Payload: base64.StdEncoding.EncodeToString(payload),
+	}}
+	mr, err := merkleRoot(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := hex.EncodeToString(util.Digest([]byte("token")))
+	sig := authorID.SignMessage([]byte(mr))
+	crSig := serverID.SignMessage([]byte(mr + token))
+	p := v1.ProposalRecord{
+		Files:     files,
+		PublicKey: hex.EncodeToString(authorID.Public.Key[:]),
+		Signature: hex.EncodeToString(sig[:]),
+		CensorshipRecord: v1.CensorshipRecord{
+			Token:     token,
+			Merkle:    mr,
+			Signature: hex.EncodeToString(crSig[:]),
+		},
+	}
+
+	// Setup an original, an edited and a censored comment with server
+	// receipts
+	signComment := func(c v1.Comment, msg string) v1.Comment {
+		sig := authorID.SignMessage([]byte(msg))
+		c.Signature = hex.EncodeToString(sig[:])
+		c.PublicKey = hex.EncodeToString(authorID.Public.Key[:])
+		receipt := serverID.SignMessage([]byte(c.Signature))
+		c.Receipt = hex.EncodeToString(receipt[:])
+		return c
+	}
+	comments := []v1.Comment{
+		signComment(v1.Comment{
+			Token:     token,
+			ParentID:  "0",
+			Comment:   "comment",
+			CommentID: "1",
+		}, token+"0"+"comment"),
+		signComment(v1.Comment{
+			Token:     token,
+			ParentID:  "1",
+			Comment:   "edited",
+			CommentID: "2",
+			Edited:    1,
+		}, token+"2"+"edited"),
+		signComment(v1.Comment{
+			Token:     token,
+			ParentID:  "0",
+			CommentID: "3",
+			Censored:  true,
+		}, token+"0"+"censored"),
+	}
+
+	// bundle returns a bundle of the proposal with the passed in comments
+	// that is signed by the passed in identity
+	bundle := func(id *identity.FullIdentity, comments []v1.Comment) []byte {
+		pb, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb, err := json.Marshal(v1.GetCommentsReply{Comments: comments})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vb, err := json.Marshal(v1.VoteResultsReply{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return writeTestBundle(t, id, token, []bundleEntry{
+			{v1.BundleEntryProposal, pb},
+			{v1.BundleFilesDir + indexFile, payload},
+			{v1.BundleMetadataDir + "0", []byte(`{"version":1}`)},
+			{v1.BundleEntryComments, cb},
+			{v1.BundleEntryVotes, vb},
+		})
+	}
+	valid := bundle(serverID, comments)
+
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.ProposalBundleReply{
+				Bundle: base64.StdEncoding.EncodeToString(valid),
+			})
+		}))
+	defer s.Close()
+
+	c := newTestClient(t, s, true)
+	c.serverPubKey = serverPubKey
+
+	t.Run("export", func(t *testing.T) {
+		var b bytes.Buffer
+		err := c.ExportProposalBundle(token, &b)
+		if err != nil {
+			t.Fatalf("ExportProposalBundle: %v", err)
+		}
+		if !bytes.Equal(b.Bytes(), valid) {
+			t.Fatalf("exported bundle does not match the server bundle")
+		}
+	})
+
+	t.Run("export wrong token", func(t *testing.T) {
+		var b bytes.Buffer
+		err := c.ExportProposalBundle(strings.Repeat("0", 64), &b)
+		if err == nil {
+			t.Fatalf("got nil error, want error")
+		}
+		if b.Len() != 0 {
+			t.Fatalf("bundle was written on error")
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		m, err := VerifyProposalBundle(bytes.NewReader(valid),
+			serverPubKey)
+		if err != nil {
+			t.Fatalf("VerifyProposalBundle: %v", err)
+		}
+		if m.Token != token {
+			t.Fatalf("got token %v, want %v", m.Token, token)
+		}
+		if len(m.Entries) != 5 {
+			t.Fatalf("got %v entries, want 5", len(m.Entries))
+		}
+	})
+
+	t.Run("wrong server key", func(t *testing.T) {
+		_, err := VerifyProposalBundle(bytes.NewReader(valid),
+			hex.EncodeToString(authorID.Public.Key[:]))
+		if err == nil {
+			t.Fatalf("got nil error, want error")
+		}
+	})
+
+	t.Run("manifest not signed by server", func(t *testing.T) {
+		_, err := VerifyProposalBundle(bytes.NewReader(
+			bundle(authorID, comments)), serverPubKey)
+		if err == nil || !strings.Contains(err.Error(), "manifest") {
+			t.Fatalf("got error %v, want manifest error", err)
+		}
+	})
+
+	t.Run("invalid comment receipt", func(t *testing.T) {
+		bad := append([]v1.Comment(nil), comments...)
+		bad[2].Receipt = bad[1].Receipt
+		_, err := VerifyProposalBundle(bytes.NewReader(
+			bundle(serverID, bad)), serverPubKey)
+		if err == nil || !strings.Contains(err.Error(), "receipt") {
+			t.Fatalf("got error %v, want receipt error", err)
+		}
+	})
+
+	t.Run("tampered entry", func(t *testing.T) {
+		// Rewrite the bundle with a modified proposal file
+		var tampered bytes.Buffer
+		tw := tar.NewWriter(&tampered)
+		tr := tar.NewReader(bytes.NewReader(valid))
+		for {
+			hdr, err := tr.Next()
+			if err != nil {
+				break
+			}
+			b, err := ioutil.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hdr.Name == v1.BundleFilesDir+indexFile {
+				b = []byte("tampered")
+				hdr.Size = int64(len(b))
+			}
+			err = tw.WriteHeader(hdr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = tw.Write(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		tw.Close()
+
+		_, err := VerifyProposalBundle(&tampered, serverPubKey)
+		if err == nil {
+			t.Fatalf("got nil error, want error")
+		}
+	})
+}
+
+func TestBundleFileName(t *testing.T) {
+	var tests = []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"index.md", "files/index.md", false},
+		{"../index.md", "", true},
+		{"dir/index.md", "", true},
+		{"..", "", true},
+		{"", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := bundleFileName(test.name)
+		switch {
+		case test.wantErr && err == nil:
+			t.Errorf("%q: got nil error, want error", test.name)
+		case !test.wantErr && err != nil:
+			t.Errorf("%q: got error %v, want nil", test.name, err)
+		case got != test.want:
+			t.Errorf("%q: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
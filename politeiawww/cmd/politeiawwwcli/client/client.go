@@ -15,12 +15,18 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/decred/dcrwallet/rpc/walletrpc"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
 	"github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
 	"github.com/gorilla/schema"
+	"golang.org/x/net/http2"
 	"golang.org/x/net/publicsuffix"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -33,22 +39,161 @@ type Client struct {
 	http *http.Client
 	cfg  *config.Config
 
-	// wallet grpc
-	ctx    context.Context
-	creds  credentials.TransportCredentials
-	conn   *grpc.ClientConn
-	wallet walletrpc.WalletServiceClient
+	// lastTrace is the connection trace of the most recent request.  It
+	// is only set when tracing is enabled.
+	lastTrace *RequestTrace
+	traceMtx  sync.Mutex
+
+	// etags caches the responses of GET requests that returned an ETag
+	// so that unchanged responses do not need to be refetched.
+	etags etagCache
+
+	// users caches user details replies by user ID.
+	users *userCache
+
+	// Server public key from the most recent Version reply and its
+	// parsed identity.  See ServerPublicKey.
+	serverPubKey string
+	serverID     *identity.PublicIdentity
+
+	// listPolicy is the policy reply that the list page sizes are
+	// derived from.  It is fetched once; see listPolicyReply.
+	listPolicy *v1.PolicyReply
+
+	// wallet grpc.  The connection is replaced when it is redialed so
+	// walletMtx must be held when accessing conn and wallet.
+	ctx       context.Context
+	creds     credentials.TransportCredentials
+	walletMtx sync.Mutex
+	conn      *grpc.ClientConn
+	wallet    walletrpc.WalletServiceClient
+
+	// walletPassphrase returns the private passphrase of the wallet.  See
+	// SetWalletPassphrase.
+	walletPassphrase func() ([]byte, error)
+
+	// apiToken is sent in the Authorization header of every request when
+	// it is set.  See WithAPIToken.
+	apiToken string
 }
 
-func prettyPrintJSON(v interface{}) error {
-	b, err := json.MarshalIndent(v, "", "  ")
+// apiRoute returns the route prefix of the politeiawww API version that the
+// client sends requests to.
+func (c *Client) apiRoute() string {
+	return fmt.Sprintf("/v%v", c.cfg.APIVersion)
+}
+
+// prettyPrintJSON prints the passed in value as JSON using the configured
+// indentation and key ordering.
+func (c *Client) prettyPrintJSON(v interface{}) error {
+	b, err := c.cfg.EncodeJSON(v, true)
 	if err != nil {
-		return fmt.Errorf("MarshalIndent: %v", err)
+		return err
 	}
 	fmt.Fprintf(os.Stdout, "%s\n", b)
 	return nil
 }
 
+// APIError is returned when politeiawww responds to a request with a non 200
+// HTTP status code.  The error code, message and context are only populated
+// when the reply body contains a politeiawww user error.  The request ID can
+// be quoted to the politeiawww administrators to find the request in the
+// server logs.
+type APIError struct {
+	HTTPCode     int             `json:"httpcode"`
+	ErrorCode    v1.ErrorStatusT `json:"errorcode,omitempty"`
+	ErrorMessage string          `json:"errormessage,omitempty"`
+	ErrorContext []string        `json:"errorcontext,omitempty"`
+	RequestID    string          `json:"requestid,omitempty"`
+
+	// Response describes the reply body when it is not JSON, e.g. when
+	// a reverse proxy replied with an error page.
+	Response string `json:"response,omitempty"`
+}
+
+// Error satisfies the error interface.
+func (e *APIError) Error() string {
+	var s string
+	switch {
+	case e.ErrorCode == 0 && e.Response != "":
+		s = fmt.Sprintf("%v, %v", e.HTTPCode, e.Response)
+	case e.ErrorCode == 0:
+		s = fmt.Sprintf("%v", e.HTTPCode)
+	default:
+		s = fmt.Sprintf("%v, %v %v", e.HTTPCode, e.ErrorMessage,
+			strings.Join(e.ErrorContext, ", "))
+	}
+	if e.RequestID != "" {
+		s += fmt.Sprintf(" (request id %v)", e.RequestID)
+	}
+	return s
+}
+
+// newAPIError returns an APIError for the passed in response and reply body.
+// The request ID is taken from the response header that politeiawww echoes it
+// back in.
+func newAPIError(r *http.Response, body []byte) error {
+	e := &APIError{
+		HTTPCode:  r.StatusCode,
+		RequestID: r.Header.Get(v1.RequestID),
+	}
+	var ue v1.UserError
+	err := json.Unmarshal(body, &ue)
+	if err == nil && ue.ErrorCode != 0 {
+		e.ErrorCode = ue.ErrorCode
+		e.ErrorMessage = v1.ErrorStatus[ue.ErrorCode]
+		e.ErrorContext = ue.ErrorContext
+	}
+	if !json.Valid(body) {
+		e.Response = describeResponse(r, body)
+	}
+	return e
+}
+
+// maxBodySnippet is the maximum number of characters of an unexpected
+// response body that are included in errors.
+const maxBodySnippet = 200
+
+// checkJSONResponse returns an error if the passed in reply body of a
+// successful request is not JSON.  Without this check the error would only
+// surface as a confusing unmarshal error in the caller.
+func checkJSONResponse(r *http.Response, body []byte) error {
+	if json.Valid(body) {
+		return nil
+	}
+	return fmt.Errorf("unexpected response to %v %v: %v", r.Request.Method,
+		r.Request.URL, describeResponse(r, body))
+}
+
+// describeResponse describes a reply body that is not JSON using its content
+// type and the start of the body.  Whitespace in the body is collapsed so
+// that HTML error pages fit on a single line.
+func describeResponse(r *http.Response, body []byte) string {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "none"
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return fmt.Sprintf("empty response body (content type %v)",
+			contentType)
+	}
+
+	snippet := []rune(strings.Join(strings.Fields(string(body)), " "))
+	s := string(snippet)
+	if len(snippet) > maxBodySnippet {
+		s = string(snippet[:maxBodySnippet]) + "..."
+	}
+	return fmt.Sprintf("response is not JSON (content type %v): %q",
+		contentType, s)
+}
+
+// setRequestID sets a newly generated correlation ID in the request ID header
+// of the passed in request.  Every request that is sent to politeiawww gets
+// its own ID so that it can be found in the server logs.
+func setRequestID(req *http.Request) {
+	req.Header.Set(v1.RequestID, uuid.New().String())
+}
+
 func (c *Client) makeRequest(method, route string, body interface{}) ([]byte, error) {
 	// Setup request
 	var requestBody []byte
@@ -80,7 +225,7 @@ func (c *Client) makeRequest(method, route string, body interface{}) ([]byte, er
 		}
 	}
 
-	fullRoute := c.cfg.Host + v1.PoliteiaWWWAPIRoute + route + queryParams
+	fullRoute := c.cfg.Host + c.apiRoute() + route + queryParams
 
 	// Print request details
 	switch {
@@ -88,59 +233,159 @@ func (c *Client) makeRequest(method, route string, body interface{}) ([]byte, er
 		fmt.Printf("Request: GET %v\n", fullRoute)
 	case c.cfg.Verbose && method == http.MethodPost:
 		fmt.Printf("Request: POST %v\n", fullRoute)
-		err := prettyPrintJSON(body)
+		err := c.prettyPrintJSON(body)
 		if err != nil {
 			return nil, err
 		}
 	case c.cfg.Verbose && method == http.MethodPut:
 		fmt.Printf("Request: PUT %v\n", fullRoute)
-		err := prettyPrintJSON(body)
+		err := c.prettyPrintJSON(body)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// Create http request
-	req, err := http.NewRequest(method, fullRoute, bytes.NewReader(requestBody))
+	// Make the request conditional when there is a cached response
+	var etag string
+	cached, ok := c.etags.get(fullRoute)
+	if ok && method == http.MethodGet {
+		etag = cached.etag
+	}
+
+	// Send request.  Rate limited requests are retried after the
+	// amount of time requested by the server when retries are enabled.
+	var (
+		retries int
+		waited  time.Duration
+	)
+	start := time.Now()
+	r, responseBody, err := c.sendRequest(method, fullRoute, requestBody, etag)
+	for err == nil {
+		d, ok := c.shouldRetry(r, retries, waited)
+		if !ok {
+			break
+		}
+		if c.cfg.Verbose {
+			fmt.Printf("Response: %v; retrying in %v\n", r.StatusCode, d)
+		}
+		time.Sleep(d)
+		retries++
+		waited += d
+		start = time.Now()
+		r, responseBody, err = c.sendRequest(method, fullRoute, requestBody,
+			etag)
+	}
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add(v1.CsrfToken, c.cfg.CSRF)
+
+	// Log in again and resend the request once when the session has
+	// expired and auto login is enabled.
+	if c.canRelogin() && isSessionError(r.StatusCode, responseBody) {
+		start = time.Now()
+		r, responseBody, err = c.relogin(method, fullRoute, requestBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate response status.  A 304 means that the cached response
+	// is still current.
+	switch {
+	case r.StatusCode == http.StatusNotModified && etag != "":
+		responseBody = cached.body
+	case r.StatusCode != http.StatusOK:
+		return nil, newAPIError(r, responseBody)
+	default:
+		err = checkJSONResponse(r, responseBody)
+		if err != nil {
+			return nil, err
+		}
+		if method == http.MethodGet {
+			c.etags.put(fullRoute, r.Header.Get("ETag"), responseBody)
+		}
+	}
+
+	// Print response details
+	if c.cfg.Verbose {
+		fmt.Printf("Response: %v %v\n", r.StatusCode,
+			formatElapsed(time.Since(start), c.cfg.SlowRequest))
+	}
+
+	return responseBody, nil
+}
+
+// formatElapsed formats the response time of a request for the verbose
+// output.  Requests that took longer than the slow request threshold are
+// flagged as slow.  A threshold of 0 disables the flag.
+func formatElapsed(elapsed, threshold time.Duration) string {
+	s := fmt.Sprintf("(%v)", elapsed.Round(time.Millisecond))
+	if threshold > 0 && elapsed > threshold {
+		s += " [SLOW]"
+	}
+	return s
+}
+
+// sendRequest sends a single http request with the passed in request body and
+// returns the response along with the decompressed response body.  The
+// request is made conditional on the passed in ETag when it is not empty.  The
+// response body has already been closed when sendRequest returns.
+func (c *Client) sendRequest(method, fullRoute string, requestBody []byte, etag string) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, fullRoute, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	c.addCSRFHeader(req)
+	if c.apiToken != "" {
+		req.Header.Set(v1.Authorization, v1.APITokenScheme+" "+c.apiToken)
+	}
+	setRequestID(req)
+	req.Header.Set("Accept-Encoding", "gzip")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	err = c.logRequest(req, requestBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("debug log: %v", err)
+	}
+
+	// Attach the connection trace
+	var rt *requestTracer
+	if c.cfg.Trace {
+		rt, req = newRequestTracer(req)
+	}
 
 	// Send request
 	r, err := c.http.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer func() {
-		r.Body.Close()
-	}()
+	defer r.Body.Close()
 
-	responseBody := util.ConvertBodyToByteArray(r.Body, false)
+	responseBody, err := readResponseBody(r)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Validate response status
-	if r.StatusCode != http.StatusOK {
-		var ue v1.UserError
-		err = json.Unmarshal(responseBody, &ue)
-		if err == nil && ue.ErrorCode != 0 {
-			return nil, fmt.Errorf("%v, %v %v", r.StatusCode,
-				v1.ErrorStatus[ue.ErrorCode], strings.Join(ue.ErrorContext, ", "))
+	if rt != nil {
+		err = c.finishTrace(rt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("trace: %v", err)
 		}
-
-		return nil, fmt.Errorf("%v", r.StatusCode)
 	}
 
-	// Print response details
-	if c.cfg.Verbose {
-		fmt.Printf("Response: %v\n", r.StatusCode)
+	err = c.logResponse(r.StatusCode, responseBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("debug log: %v", err)
 	}
 
-	return responseBody, nil
+	return r, responseBody, nil
 }
 
 // Version returns the version information for the politeiawww instance.
 func (c *Client) Version() (*v1.VersionReply, error) {
-	fullRoute := c.cfg.Host + v1.PoliteiaWWWAPIRoute + v1.RouteVersion
+	fullRoute := c.cfg.Host + c.apiRoute() + v1.RouteVersion
 
 	// Print request details
 	if c.cfg.Verbose {
@@ -153,7 +398,13 @@ func (c *Client) Version() (*v1.VersionReply, error) {
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add(v1.CsrfToken, c.cfg.CSRF)
+	c.addCSRFHeader(req)
+	setRequestID(req)
+
+	err = c.logRequest(req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
 
 	// Send request
 	r, err := c.http.Do(req)
@@ -166,16 +417,18 @@ func (c *Client) Version() (*v1.VersionReply, error) {
 
 	responseBody := util.ConvertBodyToByteArray(r.Body, false)
 
+	err = c.logResponse(r.StatusCode, responseBody)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
+
 	// Validate response status
 	if r.StatusCode != http.StatusOK {
-		var ue v1.UserError
-		err = json.Unmarshal(responseBody, &ue)
-		if err == nil {
-			return nil, fmt.Errorf("%v, %v %v", r.StatusCode,
-				v1.ErrorStatus[ue.ErrorCode], strings.Join(ue.ErrorContext, ", "))
-		}
-
-		return nil, fmt.Errorf("%v", r.StatusCode)
+		return nil, newAPIError(r, responseBody)
+	}
+	err = checkJSONResponse(r, responseBody)
+	if err != nil {
+		return nil, err
 	}
 
 	// Unmarshal response
@@ -184,11 +437,22 @@ func (c *Client) Version() (*v1.VersionReply, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unmarshal VersionReply: %v", err)
 	}
+	if vr.Version != c.cfg.APIVersion {
+		return nil, fmt.Errorf("server replied with API version %v, "+
+			"requested version %v", vr.Version, c.cfg.APIVersion)
+	}
+
+	// Cache the server public key.  It is validated when it is
+	// requested using ServerPublicKey.
+	if vr.PubKey != c.serverPubKey {
+		c.serverPubKey = vr.PubKey
+		c.serverID = nil
+	}
 
 	// Print response details
 	if c.cfg.Verbose {
 		fmt.Printf("Response: %v\n", r.StatusCode)
-		err := prettyPrintJSON(vr)
+		err := c.prettyPrintJSON(vr)
 		if err != nil {
 			return nil, err
 		}
@@ -198,6 +462,9 @@ func (c *Client) Version() (*v1.VersionReply, error) {
 	// One token is sent in the cookie. A second token is
 	// sent in the header. Both tokens must be persisted
 	// between CLI commands.
+	if !c.UsesCSRF() {
+		return &vr, nil
+	}
 
 	// Persist CSRF header token
 	c.cfg.CSRF = r.Header.Get(v1.CsrfToken)
@@ -223,12 +490,12 @@ func (c *Client) Login(l *v1.Login) (*v1.LoginReply, error) {
 		return nil, err
 	}
 
-	fullRoute := c.cfg.Host + v1.PoliteiaWWWAPIRoute + v1.RouteLogin
+	fullRoute := c.cfg.Host + c.apiRoute() + v1.RouteLogin
 
 	// Print request details
 	if c.cfg.Verbose {
 		fmt.Printf("Request: POST %v\n", fullRoute)
-		err := prettyPrintJSON(l)
+		err := c.prettyPrintJSON(l)
 		if err != nil {
 			return nil, err
 		}
@@ -241,7 +508,13 @@ func (c *Client) Login(l *v1.Login) (*v1.LoginReply, error) {
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add(v1.CsrfToken, c.cfg.CSRF)
+	c.addCSRFHeader(req)
+	setRequestID(req)
+
+	err = c.logRequest(req, requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
 
 	// Send request
 	r, err := c.http.Do(req)
@@ -254,16 +527,14 @@ func (c *Client) Login(l *v1.Login) (*v1.LoginReply, error) {
 
 	responseBody := util.ConvertBodyToByteArray(r.Body, false)
 
+	err = c.logResponse(r.StatusCode, responseBody)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
+
 	// Validate response status
 	if r.StatusCode != http.StatusOK {
-		var ue v1.UserError
-		err = json.Unmarshal(responseBody, &ue)
-		if err == nil {
-			return nil, fmt.Errorf("%v, %v %v", r.StatusCode,
-				v1.ErrorStatus[ue.ErrorCode], strings.Join(ue.ErrorContext, ", "))
-		}
-
-		return nil, fmt.Errorf("%v", r.StatusCode)
+		return nil, newAPIError(r, responseBody)
 	}
 
 	// Unmarshal response
@@ -276,24 +547,33 @@ func (c *Client) Login(l *v1.Login) (*v1.LoginReply, error) {
 	// Print response details
 	if c.cfg.Verbose {
 		fmt.Printf("Response: %v\n", r.StatusCode)
-		err := prettyPrintJSON(lr)
+		err := c.prettyPrintJSON(lr)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	// The cached user details depend on the logged in user
+	c.users.clear()
+
 	// Persist session data
 	ck := c.http.Jar.Cookies(req.URL)
 	if err = c.cfg.SaveCookies(ck); err != nil {
 		return nil, err
 	}
+	if c.cfg.AutoLogin {
+		err = c.cfg.SaveCredentials(l.Email, l.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &lr, nil
 }
 
 // Logout logs out a user from politeiawww.
 func (c *Client) Logout() (*v1.LogoutReply, error) {
-	fullRoute := c.cfg.Host + v1.PoliteiaWWWAPIRoute + v1.RouteLogout
+	fullRoute := c.cfg.Host + c.apiRoute() + v1.RouteLogout
 
 	// Print request details
 	if c.cfg.Verbose {
@@ -306,7 +586,13 @@ func (c *Client) Logout() (*v1.LogoutReply, error) {
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add(v1.CsrfToken, c.cfg.CSRF)
+	c.addCSRFHeader(req)
+	setRequestID(req)
+
+	err = c.logRequest(req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
 
 	// Send request
 	r, err := c.http.Do(req)
@@ -317,18 +603,19 @@ func (c *Client) Logout() (*v1.LogoutReply, error) {
 		r.Body.Close()
 	}()
 
+	// The cached user details depend on the logged in user
+	c.users.clear()
+
 	responseBody := util.ConvertBodyToByteArray(r.Body, false)
 
+	err = c.logResponse(r.StatusCode, responseBody)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
+
 	// Validate response status
 	if r.StatusCode != http.StatusOK {
-		var ue v1.UserError
-		err = json.Unmarshal(responseBody, &ue)
-		if err == nil {
-			return nil, fmt.Errorf("%v, %v %v", r.StatusCode,
-				v1.ErrorStatus[ue.ErrorCode], strings.Join(ue.ErrorContext, ", "))
-		}
-
-		return nil, fmt.Errorf("%v", r.StatusCode)
+		return nil, newAPIError(r, responseBody)
 	}
 
 	// Unmarshal response
@@ -341,7 +628,7 @@ func (c *Client) Logout() (*v1.LogoutReply, error) {
 	// Print response details
 	if c.cfg.Verbose {
 		fmt.Printf("Response: %v\n", r.StatusCode)
-		err := prettyPrintJSON(lr)
+		err := c.prettyPrintJSON(lr)
 		if err != nil {
 			return nil, err
 		}
@@ -353,6 +640,11 @@ func (c *Client) Logout() (*v1.LogoutReply, error) {
 		return nil, err
 	}
 
+	// A user that logged out must not be logged in again automatically
+	if err = c.cfg.ClearCredentials(); err != nil {
+		return nil, err
+	}
+
 	return &lr, nil
 }
 
@@ -370,7 +662,7 @@ func (c *Client) Policy() (*v1.PolicyReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(pr)
+		err := c.prettyPrintJSON(pr)
 		if err != nil {
 			return nil, err
 		}
@@ -380,7 +672,21 @@ func (c *Client) Policy() (*v1.PolicyReply, error) {
 }
 
 // NewUser creates a new politeiawww user.
+//
+// The email and username are validated against the server policy before the
+// request is sent unless validation is skipped.
 func (c *Client) NewUser(nu *v1.NewUser) (*v1.NewUserReply, error) {
+	if !c.cfg.SkipValidation {
+		pr, err := c.Policy()
+		if err != nil {
+			return nil, err
+		}
+		err = ValidateNewUser(nu, pr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	responseBody, err := c.makeRequest("POST", v1.RouteNewUser, nu)
 	if err != nil {
 		return nil, err
@@ -393,7 +699,7 @@ func (c *Client) NewUser(nu *v1.NewUser) (*v1.NewUserReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(nur)
+		err := c.prettyPrintJSON(nur)
 		if err != nil {
 			return nil, err
 		}
@@ -416,7 +722,7 @@ func (c *Client) VerifyNewUser(vnu *v1.VerifyNewUser) (*v1.VerifyNewUserReply, e
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(vnur)
+		err := c.prettyPrintJSON(vnur)
 		if err != nil {
 			return nil, err
 		}
@@ -439,7 +745,7 @@ func (c *Client) Me() (*v1.LoginReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(lr)
+		err := c.prettyPrintJSON(lr)
 		if err != nil {
 			return nil, err
 		}
@@ -462,7 +768,7 @@ func (c *Client) Secret() (*v1.UserError, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(ue)
+		err := c.prettyPrintJSON(ue)
 		if err != nil {
 			return nil, err
 		}
@@ -485,7 +791,7 @@ func (c *Client) ChangeUsername(cu *v1.ChangeUsername) (*v1.ChangeUsernameReply,
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(cur)
+		err := c.prettyPrintJSON(cur)
 		if err != nil {
 			return nil, err
 		}
@@ -508,7 +814,7 @@ func (c *Client) ChangePassword(cp *v1.ChangePassword) (*v1.ChangePasswordReply,
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(cpr)
+		err := c.prettyPrintJSON(cpr)
 		if err != nil {
 			return nil, err
 		}
@@ -517,6 +823,56 @@ func (c *Client) ChangePassword(cp *v1.ChangePassword) (*v1.ChangePasswordReply,
 	return &cpr, nil
 }
 
+// ChangeEmail requests an email address change for the logged in user.  A
+// verification token is sent to the new email address.  The email address is
+// not changed until the token is verified using VerifyChangeEmail.
+func (c *Client) ChangeEmail(ce *v1.ChangeEmail) (*v1.ChangeEmailReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteChangeEmail, ce)
+	if err != nil {
+		return nil, err
+	}
+
+	var cer v1.ChangeEmailReply
+	err = json.Unmarshal(responseBody, &cer)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ChangeEmailReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(cer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &cer, nil
+}
+
+// VerifyChangeEmail verifies the new email address of a pending email address
+// change for the logged in user.
+func (c *Client) VerifyChangeEmail(vce *v1.VerifyChangeEmail) (*v1.VerifyChangeEmailReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteVerifyChangeEmail,
+		vce)
+	if err != nil {
+		return nil, err
+	}
+
+	var vcer v1.VerifyChangeEmailReply
+	err = json.Unmarshal(responseBody, &vcer)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal VerifyChangeEmailReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(vcer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vcer, nil
+}
+
 // ResetPassword resets the password of the specified user.
 func (c *Client) ResetPassword(rp *v1.ResetPassword) (*v1.ResetPasswordReply, error) {
 	responseBody, err := c.makeRequest("POST", v1.RouteResetPassword, rp)
@@ -531,7 +887,7 @@ func (c *Client) ResetPassword(rp *v1.ResetPassword) (*v1.ResetPasswordReply, er
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(rpr)
+		err := c.prettyPrintJSON(rpr)
 		if err != nil {
 			return nil, err
 		}
@@ -556,7 +912,7 @@ func (c *Client) ProposalPaywallDetails() (*v1.ProposalPaywallDetailsReply, erro
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(ppdr)
+		err := c.prettyPrintJSON(ppdr)
 		if err != nil {
 			return nil, err
 		}
@@ -566,9 +922,24 @@ func (c *Client) ProposalPaywallDetails() (*v1.ProposalPaywallDetailsReply, erro
 }
 
 // NewProposal submits the specified proposal to politeiawww for the logged in
-// user.
+// user.  The file digests and the signature of the merkle root of the files
+// are verified before the proposal is sent.  Files that are larger than the
+// configured chunked upload size are uploaded in chunks first.
 func (c *Client) NewProposal(np *v1.NewProposal) (*v1.NewProposalReply, error) {
-	responseBody, err := c.makeRequest("POST", v1.RouteNewProposal, np)
+	err := verifyProposalFiles(np.Files, np.PublicKey, np.Signature,
+		np.LinkTo, np.LinkBy)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := c.stageProposalFiles(np.Files)
+	if err != nil {
+		return nil, err
+	}
+	req := *np
+	req.Files = files
+
+	responseBody, err := c.makeRequest("POST", v1.RouteNewProposal, &req)
 	if err != nil {
 		return nil, err
 	}
@@ -580,7 +951,7 @@ func (c *Client) NewProposal(np *v1.NewProposal) (*v1.NewProposalReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(npr)
+		err := c.prettyPrintJSON(npr)
 		if err != nil {
 			return nil, err
 		}
@@ -589,8 +960,37 @@ func (c *Client) NewProposal(np *v1.NewProposal) (*v1.NewProposalReply, error) {
 	return &npr, nil
 }
 
-// EditProposal edits the specified proposal with the logged in user.
+// EditProposal edits the specified proposal with the logged in user.  The
+// file digests and the signature of the merkle root of the files are verified
+// before the edit is sent.  The current version of the proposal is fetched
+// first so that the returned proposal can be verified to be the version that
+// the edit created on top of it; see verifyProposalEdit.  The signature of an
+// edit covers the RFP links of the current version.
 func (c *Client) EditProposal(ep *v1.EditProposal) (*v1.EditProposalReply, error) {
+	pdr, err := c.ProposalDetails(ep.Token, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyProposalFiles(ep.Files, ep.PublicKey, ep.Signature,
+		pdr.Proposal.LinkTo, pdr.Proposal.LinkBy)
+	if err != nil {
+		return nil, err
+	}
+	mr, err := merkleRoot(ep.Files)
+	if err != nil {
+		return nil, err
+	}
+	if mr == pdr.Proposal.CensorshipRecord.Merkle {
+		return nil, ValidationError{
+			Field:  "files",
+			Reason: "the files of the current version are unchanged",
+		}
+	}
+	serverID, err := c.ServerPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
 	responseBody, err := c.makeRequest("POST", v1.RouteEditProposal, ep)
 	if err != nil {
 		return nil, err
@@ -602,8 +1002,13 @@ func (c *Client) EditProposal(ep *v1.EditProposal) (*v1.EditProposalReply, error
 		return nil, fmt.Errorf("unmarshal EditProposalReply: %v", err)
 	}
 
+	err = verifyProposalEdit(serverID, ep, pdr.Proposal, epr.Proposal)
+	if err != nil {
+		return nil, fmt.Errorf("proposal %v edit: %v", ep.Token, err)
+	}
+
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(epr)
+		err := c.prettyPrintJSON(epr)
 		if err != nil {
 			return nil, err
 		}
@@ -626,7 +1031,7 @@ func (c *Client) ProposalDetails(token string, pd *v1.ProposalsDetails) (*v1.Pro
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(pr)
+		err := c.prettyPrintJSON(pr)
 		if err != nil {
 			return nil, err
 		}
@@ -635,6 +1040,31 @@ func (c *Client) ProposalDetails(token string, pd *v1.ProposalsDetails) (*v1.Pro
 	return &pr, nil
 }
 
+// ProposalMetadata retrieves the raw metadata streams of the latest version of
+// the specified proposal.
+func (c *Client) ProposalMetadata(token string) (*v1.ProposalMetadataReply, error) {
+	responseBody, err := c.makeRequest("GET", "/proposals/"+token+"/metadata",
+		nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pmr v1.ProposalMetadataReply
+	err = json.Unmarshal(responseBody, &pmr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ProposalMetadataReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(pmr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pmr, nil
+}
+
 // UserProposals retrieves the proposals that have been submitted by the
 // specified user.
 func (c *Client) UserProposals(up *v1.UserProposals) (*v1.UserProposalsReply, error) {
@@ -650,7 +1080,7 @@ func (c *Client) UserProposals(up *v1.UserProposals) (*v1.UserProposalsReply, er
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(upr)
+		err := c.prettyPrintJSON(upr)
 		if err != nil {
 			return nil, err
 		}
@@ -674,7 +1104,7 @@ func (c *Client) SetProposalStatus(sps *v1.SetProposalStatus) (*v1.SetProposalSt
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(spsr)
+		err := c.prettyPrintJSON(spsr)
 		if err != nil {
 			return nil, err
 		}
@@ -683,8 +1113,69 @@ func (c *Client) SetProposalStatus(sps *v1.SetProposalStatus) (*v1.SetProposalSt
 	return &spsr, nil
 }
 
+// BatchSetProposalStatus changes the status of multiple proposals.  The
+// reply contains the result of each status change.
+func (c *Client) BatchSetProposalStatus(bsps *v1.BatchSetProposalStatus) (*v1.BatchSetProposalStatusReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteBatchSetProposalStatus,
+		bsps)
+	if err != nil {
+		return nil, err
+	}
+
+	var bspsr v1.BatchSetProposalStatusReply
+	err = json.Unmarshal(responseBody, &bspsr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal BatchSetProposalStatusReply: %v",
+			err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(bspsr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &bspsr, nil
+}
+
+// AbandonProposal withdraws a proposal that was submitted by the logged in
+// user.
+func (c *Client) AbandonProposal(ap *v1.AbandonProposal) (*v1.AbandonProposalReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteAbandonProposal, ap)
+	if err != nil {
+		return nil, err
+	}
+
+	var apr v1.AbandonProposalReply
+	err = json.Unmarshal(responseBody, &apr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal AbandonProposalReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(apr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &apr, nil
+}
+
 // GetAllVetted retrieves a page of vetted proposals.
 func (c *Client) GetAllVetted(gav *v1.GetAllVetted) (*v1.GetAllVettedReply, error) {
+	if gav != nil && gav.Limit == 0 && c.cfg.PageSize != 0 {
+		pr, err := c.listPolicyReply()
+		if err != nil {
+			return nil, err
+		}
+		req := *gav
+		req.Limit = listLimit(c.cfg.PageSize, pr.ProposalListPageSize,
+			pr.ListLimit)
+		gav = &req
+	}
+
 	responseBody, err := c.makeRequest("GET", v1.RouteAllVetted, gav)
 	if err != nil {
 		return nil, err
@@ -697,7 +1188,7 @@ func (c *Client) GetAllVetted(gav *v1.GetAllVetted) (*v1.GetAllVettedReply, erro
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(gavr)
+		err := c.prettyPrintJSON(gavr)
 		if err != nil {
 			return nil, err
 		}
@@ -720,7 +1211,7 @@ func (c *Client) GetAllUnvetted(gau *v1.GetAllUnvetted) (*v1.GetAllUnvettedReply
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(gaur)
+		err := c.prettyPrintJSON(gaur)
 		if err != nil {
 			return nil, err
 		}
@@ -729,11 +1220,13 @@ func (c *Client) GetAllUnvetted(gau *v1.GetAllUnvetted) (*v1.GetAllUnvettedReply
 	return &gaur, nil
 }
 
-// NewComment submits a new proposal comment for the logged in user.
+// NewComment submits a new proposal comment for the logged in user.  A
+// CommentRateLimitError is returned when the user has exceeded the comment
+// rate limit of the proposal.
 func (c *Client) NewComment(nc *v1.NewComment) (*v1.NewCommentReply, error) {
 	responseBody, err := c.makeRequest("POST", v1.RouteNewComment, nc)
 	if err != nil {
-		return nil, err
+		return nil, commentRateLimitError(nc.Token, err)
 	}
 
 	var ncr v1.NewCommentReply
@@ -743,7 +1236,7 @@ func (c *Client) NewComment(nc *v1.NewComment) (*v1.NewCommentReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(ncr)
+		err := c.prettyPrintJSON(ncr)
 		if err != nil {
 			return nil, err
 		}
@@ -767,7 +1260,7 @@ func (c *Client) GetComments(token string) (*v1.GetCommentsReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(gcr)
+		err := c.prettyPrintJSON(gcr)
 		if err != nil {
 			return nil, err
 		}
@@ -792,7 +1285,7 @@ func (c *Client) UserCommentsLikes(token string) (*v1.UserCommentsLikesReply, er
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(uclr)
+		err := c.prettyPrintJSON(uclr)
 		if err != nil {
 			return nil, err
 		}
@@ -801,54 +1294,188 @@ func (c *Client) UserCommentsLikes(token string) (*v1.UserCommentsLikesReply, er
 	return &uclr, nil
 }
 
-// LikeComment casts a like comment action (upvote/downvote) for the logged in
-// user.
-func (c *Client) LikeComment(lc *v1.LikeComment) (*v1.LikeCommentReply, error) {
-	responseBody, err := c.makeRequest("POST", v1.RouteLikeComment, lc)
+// UserComments retrieves a page of the comments that the specified user has
+// made on public proposals.
+func (c *Client) UserComments(uc *v1.UserComments) (*v1.UserCommentsReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteUserComments, uc)
 	if err != nil {
 		return nil, err
 	}
 
-	var lcr v1.LikeCommentReply
-	err = json.Unmarshal(responseBody, &lcr)
+	var ucr v1.UserCommentsReply
+	err = json.Unmarshal(responseBody, &ucr)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshal LikeCommentReply: %v", err)
+		return nil, fmt.Errorf("unmarshal UserCommentsReply: %v", err)
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(lcr)
+		err := c.prettyPrintJSON(ucr)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return &lcr, nil
+	return &ucr, nil
 }
 
-// CensorComment censors the specified proposal comment.
-func (c *Client) CensorComment(cc *v1.CensorComment) (*v1.CensorCommentReply, error) {
-	responseBody, err := c.makeRequest("POST", v1.RouteCensorComment, cc)
+// GetUserCommentedProposals retrieves the proposals that the specified user
+// has commented on along with the number of comments that the user has made
+// on each of them.  Users may only retrieve their own commented proposals
+// unless they are an admin.
+func (c *Client) GetUserCommentedProposals(userID string) (*v1.UserCommentedProposalsReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteUserCommentedProposals,
+		&v1.UserCommentedProposals{
+			UserID: userID,
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	var ccr v1.CensorCommentReply
-	err = json.Unmarshal(responseBody, &ccr)
+	var ucpr v1.UserCommentedProposalsReply
+	err = json.Unmarshal(responseBody, &ucpr)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshal CensorCommentReply: %v", err)
+		return nil, fmt.Errorf("unmarshal UserCommentedProposalsReply: %v",
+			err)
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(ccr)
+		err := c.prettyPrintJSON(ucpr)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return &ccr, nil
+	return &ucpr, nil
 }
 
-// StartVote starts the voting period for the specified proposal.
+// LikeComment casts a like comment action (upvote/downvote) for the logged in
+// user.
+func (c *Client) LikeComment(lc *v1.LikeComment) (*v1.LikeCommentReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteLikeComment, lc)
+	if err != nil {
+		return nil, err
+	}
+
+	var lcr v1.LikeCommentReply
+	err = json.Unmarshal(responseBody, &lcr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal LikeCommentReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(lcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &lcr, nil
+}
+
+// CensorComment censors the specified proposal comment.
+//
+// The censor reason is validated before the request is sent since politeiawww
+// rejects censor comment requests without a meaningful reason.
+func (c *Client) CensorComment(cc *v1.CensorComment) (*v1.CensorCommentReply, error) {
+	err := ValidateCensorComment(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := c.makeRequest("POST", v1.RouteCensorComment, cc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ccr v1.CensorCommentReply
+	err = json.Unmarshal(responseBody, &ccr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal CensorCommentReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ccr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ccr, nil
+}
+
+// EditComment edits a proposal comment.
+func (c *Client) EditComment(ec *v1.EditComment) (*v1.EditCommentReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteEditComment, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	var ecr v1.EditCommentReply
+	err = json.Unmarshal(responseBody, &ecr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal EditCommentReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ecr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ecr, nil
+}
+
+// DeleteComment deletes a proposal comment on behalf of its author.  The
+// returned comment has Deleted set, which distinguishes it from a comment
+// that was censored by an admin.
+func (c *Client) DeleteComment(dc *v1.DeleteComment) (*v1.DeleteCommentReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteDeleteComment, dc)
+	if err != nil {
+		return nil, err
+	}
+
+	var dcr v1.DeleteCommentReply
+	err = json.Unmarshal(responseBody, &dcr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal DeleteCommentReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(dcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &dcr, nil
+}
+
+// ReactToComment adds or removes a reaction to a comment.  The reaction must
+// be one of the comment reactions of the policy.
+func (c *Client) ReactToComment(rc *v1.ReactComment) (*v1.ReactCommentReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteReactComment, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	var rcr v1.ReactCommentReply
+	err = json.Unmarshal(responseBody, &rcr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ReactCommentReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(rcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &rcr, nil
+}
+
+// StartVote starts the voting period for the specified proposal.
 func (c *Client) StartVote(sv *v1.StartVote) (*v1.StartVoteReply, error) {
 	responseBody, err := c.makeRequest("POST", v1.RouteStartVote, sv)
 	if err != nil {
@@ -862,7 +1489,7 @@ func (c *Client) StartVote(sv *v1.StartVote) (*v1.StartVoteReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(svr)
+		err := c.prettyPrintJSON(svr)
 		if err != nil {
 			return nil, err
 		}
@@ -886,7 +1513,7 @@ func (c *Client) VerifyUserPayment() (*v1.VerifyUserPaymentReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(vupr)
+		err := c.prettyPrintJSON(vupr)
 		if err != nil {
 			return nil, err
 		}
@@ -909,7 +1536,7 @@ func (c *Client) VoteResults(token string) (*v1.VoteResultsReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(vrr)
+		err := c.prettyPrintJSON(vrr)
 		if err != nil {
 			return nil, err
 		}
@@ -919,7 +1546,15 @@ func (c *Client) VoteResults(token string) (*v1.VoteResultsReply, error) {
 }
 
 // UserDetails retrieves the user details for the specified user.
+//
+// Replies are cached by user ID for the configured user cache TTL so that
+// rendering comment threads and activity summaries does not request the
+// details of the same user repeatedly.
 func (c *Client) UserDetails(userID string) (*v1.UserDetailsReply, error) {
+	if udr, ok := c.users.get(userID); ok {
+		return udr, nil
+	}
+
 	responseBody, err := c.makeRequest("GET", "/user/"+userID, nil)
 	if err != nil {
 		return nil, err
@@ -930,9 +1565,10 @@ func (c *Client) UserDetails(userID string) (*v1.UserDetailsReply, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unmarshal UserDetailsReply: %v", err)
 	}
+	c.users.put(userID, udr)
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(udr)
+		err := c.prettyPrintJSON(udr)
 		if err != nil {
 			return nil, err
 		}
@@ -941,9 +1577,103 @@ func (c *Client) UserDetails(userID string) (*v1.UserDetailsReply, error) {
 	return &udr, nil
 }
 
+// UserKey is a public key that has been used by a user along with the unix
+// timestamps of when the key was activated and deactivated.  A Deactivated
+// value of 0 means the key is still active.
+type UserKey struct {
+	PublicKey   string `json:"publickey"`
+	Activated   int64  `json:"activated"`
+	Deactivated int64  `json:"deactivated"`
+}
+
+// GetUserKeyHistory retrieves all of the public keys that the specified user
+// has ever used.  Keys that were never verified are not included.  The
+// returned keys are sorted by activation time, oldest first.
+func (c *Client) GetUserKeyHistory(userID string) ([]UserKey, error) {
+	udr, err := c.UserDetails(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]UserKey, 0, len(udr.User.Identities))
+	for _, v := range udr.User.Identities {
+		if v.Activated == 0 {
+			// Identity is pending verification
+			continue
+		}
+		keys = append(keys, UserKey{
+			PublicKey:   v.Pubkey,
+			Activated:   v.Activated,
+			Deactivated: v.Deactivated,
+		})
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Activated < keys[j].Activated
+	})
+
+	return keys, nil
+}
+
+// ActiveKeyAt returns the public key that was active at the specified unix
+// timestamp.  This is the key that must be used to verify a signature that
+// was created at that time.  A key is considered active from its activation
+// time up until, but not including, its deactivation time.
+func ActiveKeyAt(keys []UserKey, timestamp int64) (string, bool) {
+	for _, v := range keys {
+		if timestamp < v.Activated {
+			continue
+		}
+		if v.Deactivated != 0 && timestamp >= v.Deactivated {
+			continue
+		}
+		return v.PublicKey, true
+	}
+	return "", false
+}
+
+// CheckUsernameAvailable checks whether the passed in username can be used to
+// register a new user.  When it can not, the reason is either that the
+// username does not adhere to the username policy or that it is already
+// taken.  politeiawww rate limits the username checks.
+func (c *Client) CheckUsernameAvailable(username string) (*v1.UsernameAvailableReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteUsernameAvailable,
+		&v1.UsernameAvailable{
+			Username: username,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var uar v1.UsernameAvailableReply
+	err = json.Unmarshal(responseBody, &uar)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal UsernameAvailableReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(uar)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &uar, nil
+}
+
 // Users retrieves a list of users that adhere to the specified filtering
 // parameters.
 func (c *Client) Users(u *v1.Users) (*v1.UsersReply, error) {
+	if u != nil && u.Limit == 0 && c.cfg.PageSize != 0 {
+		pr, err := c.listPolicyReply()
+		if err != nil {
+			return nil, err
+		}
+		req := *u
+		req.Limit = listLimit(c.cfg.PageSize, pr.UserListPageSize,
+			pr.ListLimit)
+		u = &req
+	}
+
 	responseBody, err := c.makeRequest("GET", v1.RouteUsers, u)
 	if err != nil {
 		return nil, err
@@ -956,7 +1686,7 @@ func (c *Client) Users(u *v1.Users) (*v1.UsersReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(ur)
+		err := c.prettyPrintJSON(ur)
 		if err != nil {
 			return nil, err
 		}
@@ -978,8 +1708,11 @@ func (c *Client) ManageUser(mu *v1.ManageUser) (*v1.ManageUserReply, error) {
 		return nil, fmt.Errorf("unmarshal ManageUserReply: %v", err)
 	}
 
+	// The details of the user have changed
+	c.users.del(mu.UserID)
+
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(mur)
+		err := c.prettyPrintJSON(mur)
 		if err != nil {
 			return nil, err
 		}
@@ -988,6 +1721,195 @@ func (c *Client) ManageUser(mu *v1.ManageUser) (*v1.ManageUserReply, error) {
 	return &mur, nil
 }
 
+// UserLogoutAll logs the specified user out of all of their sessions.  This
+// call requires admin privileges.
+func (c *Client) UserLogoutAll(ula *v1.UserLogoutAll) (*v1.UserLogoutAllReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteUserLogoutAll, ula)
+	if err != nil {
+		return nil, err
+	}
+
+	var ulr v1.UserLogoutAllReply
+	err = json.Unmarshal(responseBody, &ulr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal UserLogoutAllReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ulr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ulr, nil
+}
+
+// ResendUserEmail sends a new verification or reset password email to the
+// specified user.  This route requires admin privileges.
+func (c *Client) ResendUserEmail(rue *v1.ResendUserEmail) (*v1.ResendUserEmailReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteResendUserEmail, rue)
+	if err != nil {
+		return nil, err
+	}
+
+	var ruer v1.ResendUserEmailReply
+	err = json.Unmarshal(responseBody, &ruer)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ResendUserEmailReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ruer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ruer, nil
+}
+
+// PurgeUnverifiedUsers permanently deletes the users that never verified
+// their email address and whose verification token expired long ago.  The
+// deleted users are returned.  This route requires admin privileges.
+func (c *Client) PurgeUnverifiedUsers() (*v1.PurgeUnverifiedUsersReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RoutePurgeUnverifiedUsers,
+		v1.PurgeUnverifiedUsers{})
+	if err != nil {
+		return nil, err
+	}
+
+	var puur v1.PurgeUnverifiedUsersReply
+	err = json.Unmarshal(responseBody, &puur)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal PurgeUnverifiedUsersReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(puur)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &puur, nil
+}
+
+// NewAPIToken issues an API token for the specified user.  This route requires
+// admin privileges.
+func (c *Client) NewAPIToken(nat *v1.NewAPIToken) (*v1.NewAPITokenReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteNewAPIToken, nat)
+	if err != nil {
+		return nil, err
+	}
+
+	var natr v1.NewAPITokenReply
+	err = json.Unmarshal(responseBody, &natr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal NewAPITokenReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(natr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &natr, nil
+}
+
+// APITokens retrieves all API tokens.  This route requires admin privileges.
+func (c *Client) APITokens() (*v1.APITokensReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteAPITokens, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var atr v1.APITokensReply
+	err = json.Unmarshal(responseBody, &atr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal APITokensReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(atr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &atr, nil
+}
+
+// RevokeAPIToken revokes the specified API token.  This route requires admin
+// privileges.
+func (c *Client) RevokeAPIToken(id string) (*v1.RevokeAPITokenReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteRevokeAPIToken,
+		v1.RevokeAPIToken{
+			ID: id,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var ratr v1.RevokeAPITokenReply
+	err = json.Unmarshal(responseBody, &ratr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal RevokeAPITokenReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ratr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ratr, nil
+}
+
+// ImpersonateUser starts a read-only impersonation session for the specified
+// user.  This route requires admin privileges.
+//
+// WARNING: the session of the admin is replaced by the impersonation session.
+// All subsequent requests made by the client are made as the impersonated
+// user until the session expires or the client logs out, after which the
+// admin must log in again.  Every request made during the impersonation is
+// recorded in the server logs along with the admin.
+func (c *Client) ImpersonateUser(iu *v1.ImpersonateUser) (*v1.ImpersonateUserReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteImpersonateUser, iu)
+	if err != nil {
+		return nil, err
+	}
+
+	var iur v1.ImpersonateUserReply
+	err = json.Unmarshal(responseBody, &iur)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ImpersonateUserReply: %v", err)
+	}
+
+	// Persist the impersonation session so that subsequent commands
+	// use it
+	u, err := url.Parse(c.cfg.Host + c.apiRoute())
+	if err != nil {
+		return nil, err
+	}
+	err = c.cfg.SaveCookies(c.http.Jar.Cookies(u))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(iur)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &iur, nil
+}
+
 // EditUser allows the logged in user to update their user settings.
 func (c *Client) EditUser(eu *v1.EditUser) (*v1.EditUserReply, error) {
 	responseBody, err := c.makeRequest("POST", v1.RouteEditUser, eu)
@@ -1002,7 +1924,7 @@ func (c *Client) EditUser(eu *v1.EditUser) (*v1.EditUserReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(eur)
+		err := c.prettyPrintJSON(eur)
 		if err != nil {
 			return nil, err
 		}
@@ -1026,7 +1948,7 @@ func (c *Client) AuthorizeVote(av *v1.AuthorizeVote) (*v1.AuthorizeVoteReply, er
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(avr)
+		err := c.prettyPrintJSON(avr)
 		if err != nil {
 			return nil, err
 		}
@@ -1050,7 +1972,7 @@ func (c *Client) VoteStatus(token string) (*v1.VoteStatusReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(vsr)
+		err := c.prettyPrintJSON(vsr)
 		if err != nil {
 			return nil, err
 		}
@@ -1059,6 +1981,79 @@ func (c *Client) VoteStatus(token string) (*v1.VoteStatusReply, error) {
 	return &vsr, nil
 }
 
+// GetProposalBilling retrieves the approved budget of a proposal and the
+// spends that were recorded against it.
+func (c *Client) GetProposalBilling(token string) (*v1.ProposalBillingReply, error) {
+	route := "/proposals/" + token + "/billing"
+	responseBody, err := c.makeRequest("GET", route, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pbr v1.ProposalBillingReply
+	err = json.Unmarshal(responseBody, &pbr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ProposalBillingReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(pbr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pbr, nil
+}
+
+// SetProposalBudget sets the approved budget of a proposal whose vote has been
+// approved.  This call requires admin privileges.
+func (c *Client) SetProposalBudget(spb *v1.SetProposalBudget) (*v1.SetProposalBudgetReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteSetProposalBudget, spb)
+	if err != nil {
+		return nil, err
+	}
+
+	var spbr v1.SetProposalBudgetReply
+	err = json.Unmarshal(responseBody, &spbr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal SetProposalBudgetReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(spbr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &spbr, nil
+}
+
+// RecordProposalSpend records a spend against the budget of an approved
+// proposal.  This call requires admin privileges.
+func (c *Client) RecordProposalSpend(rps *v1.RecordProposalSpend) (*v1.RecordProposalSpendReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteRecordProposalSpend, rps)
+	if err != nil {
+		return nil, err
+	}
+
+	var rpsr v1.RecordProposalSpendReply
+	err = json.Unmarshal(responseBody, &rpsr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal RecordProposalSpendReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(rpsr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &rpsr, nil
+}
+
 // GetAllVoteStatus retreives the vote status of all public proposals.
 func (c *Client) GetAllVoteStatus() (*v1.GetAllVoteStatusReply, error) {
 	responseBody, err := c.makeRequest("GET", v1.RouteAllVoteStatus, nil)
@@ -1073,7 +2068,7 @@ func (c *Client) GetAllVoteStatus() (*v1.GetAllVoteStatusReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(avsr)
+		err := c.prettyPrintJSON(avsr)
 		if err != nil {
 			return nil, fmt.Errorf("prettyPrintJSON: %v", err)
 		}
@@ -1096,7 +2091,7 @@ func (c *Client) ActiveVotes() (*v1.ActiveVoteReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(avr)
+		err := c.prettyPrintJSON(avr)
 		if err != nil {
 			return nil, err
 		}
@@ -1119,7 +2114,7 @@ func (c *Client) CastVotes(b *v1.Ballot) (*v1.BallotReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(br)
+		err := c.prettyPrintJSON(br)
 		if err != nil {
 			return nil, err
 		}
@@ -1142,7 +2137,7 @@ func (c *Client) UpdateUserKey(uuk *v1.UpdateUserKey) (*v1.UpdateUserKeyReply, e
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(uukr)
+		err := c.prettyPrintJSON(uukr)
 		if err != nil {
 			return nil, err
 		}
@@ -1166,7 +2161,7 @@ func (c *Client) VerifyUpdateUserKey(vuuk *v1.VerifyUpdateUserKey) (*v1.VerifyUp
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(vuukr)
+		err := c.prettyPrintJSON(vuukr)
 		if err != nil {
 			return nil, err
 		}
@@ -1175,6 +2170,34 @@ func (c *Client) VerifyUpdateUserKey(vuuk *v1.VerifyUpdateUserKey) (*v1.VerifyUp
 	return &vuukr, nil
 }
 
+// ResendUpdateUserKeyVerification resends the verification email of the
+// pending key update of the logged in user.  politeiawww sends the existing
+// verification token again if it has not expired or generates a new one if it
+// has.  The verification token is only returned when politeiawww has email
+// disabled.
+func (c *Client) ResendUpdateUserKeyVerification() (*v1.ResendUpdateUserKeyReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteResendUpdateUserKey,
+		v1.ResendUpdateUserKey{})
+	if err != nil {
+		return nil, err
+	}
+
+	var ruukr v1.ResendUpdateUserKeyReply
+	err = json.Unmarshal(responseBody, &ruukr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal ResendUpdateUserKeyReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ruukr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ruukr, nil
+}
+
 // ProposalPaywallPayment retrieves payment details of any pending proposal
 // credit payment from the logged in user.
 func (c *Client) ProposalPaywallPayment() (*v1.ProposalPaywallPaymentReply, error) {
@@ -1191,7 +2214,7 @@ func (c *Client) ProposalPaywallPayment() (*v1.ProposalPaywallPaymentReply, erro
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(pppr)
+		err := c.prettyPrintJSON(pppr)
 		if err != nil {
 			return nil, err
 		}
@@ -1215,7 +2238,7 @@ func (c *Client) UserPaymentsRescan(upr *v1.UserPaymentsRescan) (*v1.UserPayment
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(uprr)
+		err := c.prettyPrintJSON(uprr)
 		if err != nil {
 			return nil, err
 		}
@@ -1239,7 +2262,7 @@ func (c *Client) ProposalsStats() (*v1.ProposalsStatsReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(psr)
+		err := c.prettyPrintJSON(psr)
 		if err != nil {
 			return nil, err
 		}
@@ -1248,6 +2271,36 @@ func (c *Client) ProposalsStats() (*v1.ProposalsStatsReply, error) {
 	return &psr, nil
 }
 
+// GetStatsHistory retrieves the proposal submissions, vote outcomes and active
+// users of the time range from up to, but not including, to bucketed by
+// interval.
+func (c *Client) GetStatsHistory(from, to time.Time, interval time.Duration) (*v1.StatsHistoryReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteStatsHistory,
+		&v1.StatsHistory{
+			From:     from.Unix(),
+			To:       to.Unix(),
+			Interval: int64(interval / time.Second),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var shr v1.StatsHistoryReply
+	err = json.Unmarshal(responseBody, &shr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal StatsHistoryReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(shr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &shr, nil
+}
+
 // UserProposalCredits retrieves the proposal credit history for the logged
 // in user.
 func (c *Client) UserProposalCredits() (*v1.UserProposalCreditsReply, error) {
@@ -1263,7 +2316,7 @@ func (c *Client) UserProposalCredits() (*v1.UserProposalCreditsReply, error) {
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(upcr)
+		err := c.prettyPrintJSON(upcr)
 		if err != nil {
 			return nil, err
 		}
@@ -1274,19 +2327,75 @@ func (c *Client) UserProposalCredits() (*v1.UserProposalCreditsReply, error) {
 
 // Close all client connections.
 func (c *Client) Close() {
+	c.walletMtx.Lock()
+	defer c.walletMtx.Unlock()
+
 	if c.conn != nil {
 		c.conn.Close()
+		c.conn = nil
+		c.wallet = nil
 	}
 }
 
 // New returns a new politeiawww client.
 func New(cfg *config.Config) (*Client, error) {
+	// Default to the API version that this client was built against
+	if cfg.APIVersion == 0 {
+		cfg.APIVersion = v1.PoliteiaWWWAPIVersion
+	}
+	if !config.IsSupportedAPIVersion(cfg.APIVersion) {
+		return nil, fmt.Errorf("unsupported API version %v",
+			cfg.APIVersion)
+	}
+
+	// Use the configured proxy, if any, instead of the proxy
+	// environment variables
+	proxy := http.ProxyFromEnvironment
+	proxyURL, err := cfg.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	// Load the TLS client certificate
+	var certs []tls.Certificate
+	switch {
+	case cfg.ClientCert != "" && cfg.ClientKey != "":
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	case cfg.ClientCert != "" || cfg.ClientKey != "":
+		return nil, fmt.Errorf("a client certificate requires both a " +
+			"certificate and a key")
+	}
+
 	// Create http client
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: cfg.SkipVerify,
+		Certificates:       certs,
 	}
 	tr := &http.Transport{
+		Proxy:           proxy,
 		TLSClientConfig: tlsConfig,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		IdleConnTimeout: cfg.IdleConnTimeout,
+		MaxConnsPerHost: cfg.MaxConnsPerHost,
+
+		// All requests are made to a single host so allow every
+		// idle connection to be kept for that host.
+		MaxIdleConnsPerHost: cfg.MaxIdleConns,
+	}
+
+	// Setting a custom TLS config disables the transport's automatic
+	// HTTP/2 support so it must be enabled explicitly.  HTTP/2
+	// multiplexes requests over a single connection.
+	err = http2.ConfigureTransport(tr)
+	if err != nil {
+		return nil, fmt.Errorf("configure http2: %v", err)
 	}
 
 	// Set cookies
@@ -1306,8 +2415,39 @@ func New(cfg *config.Config) (*Client, error) {
 		Jar:       jar,
 	}
 
-	return &Client{
-		http: httpClient,
-		cfg:  cfg,
-	}, nil
+	c := &Client{
+		http:  httpClient,
+		cfg:   cfg,
+		users: newUserCache(cfg.UserCacheSize, cfg.UserCacheTTL),
+	}
+	return c.WithAPIToken(cfg.APIToken), nil
+}
+
+// UsesCSRF returns whether the requests carry the CSRF token.  The server only
+// applies CSRF protection to cookie sessions so the token is not used when
+// the requests are authenticated with an API token and NoCSRF is set.
+func (c *Client) UsesCSRF() bool {
+	return c.apiToken == "" || !c.cfg.NoCSRF
+}
+
+// addCSRFHeader adds the CSRF header token to the passed in request when the
+// client uses CSRF tokens.
+func (c *Client) addCSRFHeader(req *http.Request) {
+	if !c.UsesCSRF() {
+		return
+	}
+	req.Header.Add(v1.CsrfToken, c.cfg.CSRF)
+}
+
+// WithAPIToken sets the API token that the requests are authenticated with.
+// The server ignores the session cookie of requests that carry an API token.
+// An empty token authenticates the requests with the session again.  The
+// cached user details are cleared when the token changes since they depend on
+// the user that the requests are authenticated as.
+func (c *Client) WithAPIToken(token string) *Client {
+	if token != c.apiToken {
+		c.users.clear()
+	}
+	c.apiToken = token
+	return c
 }
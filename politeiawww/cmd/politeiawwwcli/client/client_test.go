@@ -0,0 +1,488 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/elliptic"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/config"
+	"github.com/decred/politeia/util"
+)
+
+// newTestServer returns a TLS test server that responds to the policy route.
+func newTestServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.PolicyReply{
+				MinPasswordLength: 8,
+			})
+		}))
+}
+
+// newTestClient returns a client that is configured to talk to the passed in
+// test server.
+func newTestClient(t testing.TB, s *httptest.Server, keepAlive bool) *Client {
+	t.Helper()
+
+	cfg := &config.Config{
+		Host:            s.URL,
+		SkipVerify:      true,
+		MaxIdleConns:    100,
+		IdleConnTimeout: 90 * time.Second,
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c.http.Transport.(*http.Transport).DisableKeepAlives = !keepAlive
+
+	return c
+}
+
+func TestNewTransport(t *testing.T) {
+	cfg := &config.Config{
+		Host:            "https://127.0.0.1",
+		MaxIdleConns:    10,
+		IdleConnTimeout: time.Minute,
+		MaxConnsPerHost: 5,
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tr, ok := c.http.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("unexpected transport type %T", c.http.Transport)
+	}
+	if tr.MaxIdleConns != cfg.MaxIdleConns {
+		t.Errorf("MaxIdleConns got %v, want %v",
+			tr.MaxIdleConns, cfg.MaxIdleConns)
+	}
+	if tr.MaxIdleConnsPerHost != cfg.MaxIdleConns {
+		t.Errorf("MaxIdleConnsPerHost got %v, want %v",
+			tr.MaxIdleConnsPerHost, cfg.MaxIdleConns)
+	}
+	if tr.IdleConnTimeout != cfg.IdleConnTimeout {
+		t.Errorf("IdleConnTimeout got %v, want %v",
+			tr.IdleConnTimeout, cfg.IdleConnTimeout)
+	}
+	if tr.MaxConnsPerHost != cfg.MaxConnsPerHost {
+		t.Errorf("MaxConnsPerHost got %v, want %v",
+			tr.MaxConnsPerHost, cfg.MaxConnsPerHost)
+	}
+	if _, ok := tr.TLSNextProto["h2"]; !ok {
+		t.Errorf("http2 has not been enabled on the transport")
+	}
+}
+
+func benchmarkPolicy(b *testing.B, keepAlive bool) {
+	s := newTestServer()
+	defer s.Close()
+
+	c := newTestClient(b, s, keepAlive)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := c.Policy()
+		if err != nil {
+			b.Fatalf("Policy: %v", err)
+		}
+	}
+}
+
+// BenchmarkPolicyKeepAlive measures the per request latency when connections
+// are reused between requests.
+func BenchmarkPolicyKeepAlive(b *testing.B) {
+	benchmarkPolicy(b, true)
+}
+
+// BenchmarkPolicyNoKeepAlive measures the per request latency when a new
+// connection, including the TLS handshake, is established for every request.
+func BenchmarkPolicyNoKeepAlive(b *testing.B) {
+	benchmarkPolicy(b, false)
+}
+
+func TestAPIVersion(t *testing.T) {
+	var paths []string
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.VersionReply{
+				Version: 2,
+			})
+		}))
+	defer s.Close()
+
+	// Unsupported versions are rejected
+	_, err := New(&config.Config{
+		Host:       s.URL,
+		APIVersion: 2,
+	})
+	if err == nil {
+		t.Fatalf("got nil error, want unsupported API version")
+	}
+
+	// Requests use the route prefix of the requested version and a
+	// version reply for a different version is rejected
+	c := newTestClient(t, s, true)
+	if c.cfg.APIVersion != v1.PoliteiaWWWAPIVersion {
+		t.Fatalf("got API version %v, want %v", c.cfg.APIVersion,
+			v1.PoliteiaWWWAPIVersion)
+	}
+	_, err = c.Version()
+	if err == nil {
+		t.Fatalf("got nil error, want API version mismatch")
+	}
+	want := v1.PoliteiaWWWAPIRoute + v1.RouteVersion
+	if len(paths) != 1 || paths[0] != want {
+		t.Fatalf("got paths %v, want [%v]", paths, want)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var ids []string
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(v1.RequestID)
+			ids = append(ids, id)
+			w.Header().Set(v1.RequestID, id)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		}))
+	defer s.Close()
+
+	c := newTestClient(t, s, true)
+	for i := 0; i < 2; i++ {
+		_, err := c.Policy()
+		e, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("got error %v, want *APIError", err)
+		}
+
+		// The error contains the ID that was sent with the request
+		if e.RequestID == "" || e.RequestID != ids[i] {
+			t.Fatalf("got request id %q, want %q", e.RequestID, ids[i])
+		}
+		if !strings.Contains(e.Error(), e.RequestID) {
+			t.Fatalf("error %q does not contain the request id", e.Error())
+		}
+	}
+
+	// Every request gets its own ID
+	if ids[0] == ids[1] {
+		t.Fatalf("got the same request id for both requests: %v", ids[0])
+	}
+}
+
+func TestWithAPIToken(t *testing.T) {
+	var headers []string
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			headers = append(headers, r.Header.Get(v1.Authorization))
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.PolicyReply{})
+		}))
+	defer s.Close()
+
+	// No API token is sent by default
+	c := newTestClient(t, s, true)
+	_, err := c.Policy()
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+
+	_, err = c.WithAPIToken("abc").Policy()
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+
+	want := []string{"", v1.APITokenScheme + " abc"}
+	if len(headers) != len(want) {
+		t.Fatalf("got %v requests, want %v", len(headers), len(want))
+	}
+	for i := range want {
+		if headers[i] != want[i] {
+			t.Errorf("request %v: got Authorization %q, want %q", i,
+				headers[i], want[i])
+		}
+	}
+}
+
+func TestNoCSRF(t *testing.T) {
+	var headers []string
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			headers = append(headers, r.Header.Get(v1.CsrfToken))
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.PolicyReply{})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.CSRF = "csrf"
+	c.cfg.NoCSRF = true
+
+	// The CSRF token is still sent for cookie sessions
+	_, err := c.Policy()
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+	if !c.UsesCSRF() {
+		t.Errorf("cookie session does not use CSRF")
+	}
+
+	_, err = c.WithAPIToken("abc").Policy()
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+	if c.UsesCSRF() {
+		t.Errorf("API token with nocsrf uses CSRF")
+	}
+
+	want := []string{"csrf", ""}
+	if len(headers) != len(want) {
+		t.Fatalf("got %v requests, want %v", len(headers), len(want))
+	}
+	for i := range want {
+		if headers[i] != want[i] {
+			t.Errorf("request %v: got CSRF token %q, want %q", i,
+				headers[i], want[i])
+		}
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	var tests = []struct {
+		elapsed   time.Duration
+		threshold time.Duration
+		want      string
+	}{
+		{1800 * time.Millisecond, time.Second, "(1.8s) [SLOW]"},
+		{250 * time.Millisecond, time.Second, "(250ms)"},
+		{time.Second, time.Second, "(1s)"},
+		{time.Minute, 0, "(1m0s)"},
+	}
+
+	for _, test := range tests {
+		got := formatElapsed(test.elapsed, test.threshold)
+		if got != test.want {
+			t.Errorf("formatElapsed(%v, %v): got %q, want %q",
+				test.elapsed, test.threshold, got, test.want)
+		}
+	}
+}
+
+func TestNonJSONResponse(t *testing.T) {
+	page := "<html>\n  <body>\n    <h1>502 Bad Gateway</h1>\n" +
+		strings.Repeat("padding ", 50) + "\n  </body>\n</html>"
+
+	var status int
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(status)
+			w.Write([]byte(page))
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	t.Run("success status", func(t *testing.T) {
+		status = http.StatusOK
+		_, err := c.Policy()
+		if err == nil {
+			t.Fatalf("got nil error, want error")
+		}
+		for _, want := range []string{"not JSON", "text/html",
+			"<html> <body> <h1>502 Bad Gateway</h1>", "..."} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("got error %q, want it to contain %q", err, want)
+			}
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		status = http.StatusBadGateway
+		_, err := c.Policy()
+		e, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("got error %v, want *APIError", err)
+		}
+		if e.HTTPCode != http.StatusBadGateway {
+			t.Errorf("got http code %v, want %v", e.HTTPCode,
+				http.StatusBadGateway)
+		}
+		if !strings.Contains(e.Response, "502 Bad Gateway") {
+			t.Errorf("got response %q", e.Response)
+		}
+	})
+}
+
+func TestProxy(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	// Setup a proxy that tunnels CONNECT requests to the test server
+	// and records the proxy credentials of each tunnel
+	var (
+		authsMtx sync.Mutex
+		auths    []string
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodConnect {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			authsMtx.Lock()
+			auths = append(auths, r.Header.Get("Proxy-Authorization"))
+			authsMtx.Unlock()
+
+			dst, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer dst.Close()
+			src, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer src.Close()
+			_, err = src.Write([]byte("HTTP/1.1 200 Connection " +
+				"established\r\n\r\n"))
+			if err != nil {
+				return
+			}
+
+			done := make(chan struct{}, 2)
+			go func() {
+				io.Copy(dst, src)
+				done <- struct{}{}
+			}()
+			go func() {
+				io.Copy(src, dst)
+				done <- struct{}{}
+			}()
+			<-done
+		}))
+	defer proxy.Close()
+
+	cfg := &config.Config{
+		Host:       s.URL,
+		SkipVerify: true,
+		Proxy:      proxy.URL,
+		ProxyUser:  "user",
+		ProxyPass:  "pass",
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = c.Policy()
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	authsMtx.Lock()
+	defer authsMtx.Unlock()
+	if len(auths) != 1 || auths[0] != want {
+		t.Fatalf("got proxy authorizations %v, want [%v]", auths, want)
+	}
+
+	// An invalid proxy is rejected
+	cfg.Proxy = "ftp://127.0.0.1:21"
+	_, err = New(cfg)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "client.cert")
+	keyFile := filepath.Join(dir, "client.key")
+	err = util.GenCertPair(elliptic.P256(), "politeiawwwcli test",
+		certFile, keyFile)
+	if err != nil {
+		t.Fatalf("GenCertPair: %v", err)
+	}
+
+	// Setup a server that requires a client certificate
+	s := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.PolicyReply{})
+		}))
+	s.TLS = &tls.Config{
+		ClientAuth: tls.RequireAnyClientCert,
+	}
+	s.StartTLS()
+	defer s.Close()
+
+	var tests = []struct {
+		name     string
+		certFile string
+		keyFile  string
+		wantErr  bool
+	}{
+		{"certificate", certFile, keyFile, false},
+		{"no certificate", "", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := New(&config.Config{
+				Host:       s.URL,
+				SkipVerify: true,
+				ClientCert: test.certFile,
+				ClientKey:  test.keyFile,
+			})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			_, err = c.Policy()
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !test.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			}
+		})
+	}
+
+	// A certificate without a key is rejected
+	_, err = New(&config.Config{
+		Host:       s.URL,
+		ClientCert: certFile,
+	})
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
@@ -0,0 +1,469 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// CommentRateLimitError is returned by NewComment when the user has exceeded
+// the comment rate limit of the proposal.  RetryAfter is the amount of time
+// until the user can comment on the proposal again, which can be shown to the
+// user as a cooldown.
+type CommentRateLimitError struct {
+	Token      string        // Censorship token
+	RetryAfter time.Duration // Time until the user can comment again
+	Err        *APIError     // Error that was returned by politeiawww
+}
+
+// Error satisfies the error interface.
+func (e *CommentRateLimitError) Error() string {
+	return fmt.Sprintf("comment rate limit exceeded on proposal %v; "+
+		"retry in %v", e.Token, e.RetryAfter)
+}
+
+// commentRateLimitError returns a CommentRateLimitError for the passed in
+// error if it is a rate limit error.  All other errors are returned
+// unchanged.  politeiawww includes the number of seconds to wait in the error
+// context.
+func commentRateLimitError(token string, err error) error {
+	e, ok := err.(*APIError)
+	if !ok || e.ErrorCode != v1.ErrorStatusRateLimitExceeded {
+		return err
+	}
+	var wait time.Duration
+	if len(e.ErrorContext) > 0 {
+		secs, err := strconv.ParseUint(e.ErrorContext[0], 10, 32)
+		if err == nil {
+			wait = time.Duration(secs) * time.Second
+		}
+	}
+	return &CommentRateLimitError{
+		Token:      token,
+		RetryAfter: wait,
+		Err:        e,
+	}
+}
+
+// messageWithFiles returns the concatenation of the passed in elements
+// followed by the digests of the passed in files.  With files every element is
+// prefixed with its length and a colon so that the end of the comment text can
+// not be confused with a digest.
+func messageWithFiles(files []v1.File, elements ...string) string {
+	if len(files) == 0 {
+		return strings.Join(elements, "")
+	}
+	for _, f := range files {
+		elements = append(elements, f.Digest)
+	}
+	var b strings.Builder
+	for _, v := range elements {
+		b.WriteString(strconv.Itoa(len(v)) + ":" + v)
+	}
+	return b.String()
+}
+
+// commentMessage returns the message that the author of a new comment signs:
+// the token, the parent ID and the comment followed by the digests of the
+// attached files.
+func commentMessage(token, parentID, comment string, files []v1.File) string {
+	return messageWithFiles(files, token, parentID, comment)
+}
+
+// EditCommentMessage returns the message that the author of a comment signs
+// to edit it: the token, the comment ID and the new comment followed by the
+// digests of the files that are attached to the comment.
+func EditCommentMessage(token, commentID, comment string, files []v1.File) string {
+	return messageWithFiles(files, token, commentID, comment)
+}
+
+// commentFilesFromPaths reads the files at the passed in paths and converts
+// them into comment attachments.  An error is returned if the files violate
+// the comment file policy of the passed in policy reply.
+func commentFilesFromPaths(paths []string, pr *v1.PolicyReply) ([]v1.File, error) {
+	if uint(len(paths)) > pr.MaxCommentFiles {
+		return nil, fmt.Errorf("a comment can have at most %v attachments",
+			pr.MaxCommentFiles)
+	}
+
+	validMIME := make(map[string]struct{}, len(pr.ValidMIMETypes))
+	for _, v := range pr.ValidMIMETypes {
+		validMIME[v] = struct{}{}
+	}
+
+	files := make([]v1.File, 0, len(paths))
+	names := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		path = util.CleanAndExpandPath(path)
+		name := filepath.Base(path)
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf("file %v: duplicate filename", name)
+		}
+		names[name] = struct{}{}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if uint(len(b)) > pr.MaxCommentFileSize {
+			return nil, fmt.Errorf("file %v: attachment exceeds max "+
+				"size of %v bytes", name, pr.MaxCommentFileSize)
+		}
+		m := mime.DetectMimeType(b)
+		if _, ok := validMIME[m]; !ok {
+			return nil, fmt.Errorf("file %v: unsupported MIME type %v",
+				name, m)
+		}
+
+		files = append(files, v1.File{
+			Name:    name,
+			MIME:    m,
+			Digest:  hex.EncodeToString(util.Digest(b)),
+			Payload: base64.StdEncoding.EncodeToString(b),
+		})
+	}
+
+	return files, nil
+}
+
+// NewCommentWithAttachments submits a new comment on the passed in proposal
+// with the files at the passed in paths attached.  The parent ID is empty for
+// top-level comments.  The attachments are validated against the server
+// policy and the comment, including the digests of the attachments, is signed
+// using the identity of the logged in user.
+func (c *Client) NewCommentWithAttachments(token, parentID, comment string, paths []string) (*v1.NewCommentReply, error) {
+	if c.cfg.Identity == nil {
+		return nil, ErrIdentityNotFound
+	}
+
+	var files []v1.File
+	if len(paths) > 0 {
+		pr, err := c.Policy()
+		if err != nil {
+			return nil, err
+		}
+		files, err = commentFilesFromPaths(paths, pr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	msg := commentMessage(token, parentID, comment, files)
+	sig := c.cfg.Identity.SignMessage([]byte(msg))
+	return c.NewComment(&v1.NewComment{
+		Token:     token,
+		ParentID:  parentID,
+		Comment:   comment,
+		Signature: hex.EncodeToString(sig[:]),
+		PublicKey: hex.EncodeToString(c.cfg.Identity.Public.Key[:]),
+		Files:     files,
+	})
+}
+
+// CommentSortT is the order that GetCommentsSorted returns comments in.
+type CommentSortT string
+
+// Comment sort orders.  Comments that are equal under the selected order are
+// ordered newest first and then by comment ID.
+const (
+	// CommentSortNewest orders comments by timestamp, newest first.
+	CommentSortNewest CommentSortT = "newest"
+
+	// CommentSortOldest orders comments by timestamp, oldest first.
+	CommentSortOldest CommentSortT = "oldest"
+
+	// CommentSortTop orders comments by vote score, the number of upvotes
+	// minus the number of downvotes, highest first.
+	CommentSortTop CommentSortT = "top"
+
+	// CommentSortControversial orders comments by controversy, highest
+	// first.  The controversy of a comment is (up + down) ^ (min / max),
+	// where up and down are its number of upvotes and downvotes and min and
+	// max are the smaller and larger of the two.  Comments with many votes
+	// that are evenly split are the most controversial.  A comment that has
+	// no upvotes or no downvotes has a controversy of zero.
+	CommentSortControversial CommentSortT = "controversial"
+)
+
+// LikeCommentResult is the result of a single comment like that was submitted
+// as part of a bulk comment like submission.  Error is set if the like failed.
+type LikeCommentResult struct {
+	CommentID string               `json:"commentid"`       // Comment ID
+	Reply     *v1.LikeCommentReply `json:"reply,omitempty"` // Server reply
+	Error     string               `json:"error,omitempty"` // Failure reason
+}
+
+// BulkLikeComments submits the passed in comment likes for the specified
+// proposal.  A result is returned for every like, in the same order as the
+// likes were passed in.  A failed like does not prevent the remaining likes
+// from being submitted.  An error is returned without submitting any likes if
+// a like is not for the specified proposal.
+//
+// politeiawww does not provide a batch route for comment likes so the likes
+// are submitted one at a time.
+func (c *Client) BulkLikeComments(token string, likes []v1.LikeComment) ([]LikeCommentResult, error) {
+	for _, v := range likes {
+		if v.Token != token {
+			return nil, fmt.Errorf("comment %v: like is for proposal %v, "+
+				"not %v", v.CommentID, v.Token, token)
+		}
+	}
+
+	return c.likeCommentsSequential(likes), nil
+}
+
+// likeCommentsSequential submits the passed in comment likes one at a time
+// and returns the result of each like.
+func (c *Client) likeCommentsSequential(likes []v1.LikeComment) []LikeCommentResult {
+	results := make([]LikeCommentResult, 0, len(likes))
+	for i := range likes {
+		r := LikeCommentResult{
+			CommentID: likes[i].CommentID,
+		}
+		lcr, err := c.LikeComment(&likes[i])
+		switch {
+		case err != nil:
+			r.Error = err.Error()
+		case lcr.Error != "":
+			r.Reply = lcr
+			r.Error = lcr.Error
+		default:
+			r.Reply = lcr
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// GetCommentsSorted retrieves the comments of the specified proposal and
+// returns them in the passed in order.
+//
+// politeiawww does not support sorting comments so they are sorted client
+// side.  The sort is applied to the flat list of comments; replies are not
+// kept next to their parent comment.  The vote based orders use the vote
+// totals that are included with each comment.
+func (c *Client) GetCommentsSorted(token string, sortBy CommentSortT) (*v1.GetCommentsReply, error) {
+	switch sortBy {
+	case CommentSortNewest, CommentSortOldest, CommentSortTop,
+		CommentSortControversial:
+	default:
+		return nil, fmt.Errorf("invalid comment sort order '%v'", sortBy)
+	}
+
+	gcr, err := c.GetComments(token)
+	if err != nil {
+		return nil, err
+	}
+	sortComments(gcr.Comments, sortBy)
+
+	return gcr, nil
+}
+
+// GetCommentsSince retrieves the comments for a proposal that were created or
+// edited after the given time.  The filtering is done by the server so only
+// the new and edited comments are sent over the wire.  Comments that have
+// since been censored or had their vote score change are not returned; use
+// GetComments to refresh the full thread when that matters.
+func (c *Client) GetCommentsSince(token string, since time.Time) (*v1.GetCommentsReply, error) {
+	gc := &v1.GetComments{
+		Since: since.Unix(),
+	}
+	responseBody, err := c.makeRequest("GET", "/proposals/"+token+"/comments",
+		gc)
+	if err != nil {
+		return nil, err
+	}
+
+	var gcr v1.GetCommentsReply
+	err = json.Unmarshal(responseBody, &gcr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal GetCommentsReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(gcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &gcr, nil
+}
+
+// GetCommentCount retrieves the number of comments of a proposal and the time
+// of its most recent comment without retrieving the comments.
+func (c *Client) GetCommentCount(token string) (*v1.CommentCountReply, error) {
+	responseBody, err := c.makeRequest("GET",
+		"/proposals/"+token+"/commentcount", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ccr v1.CommentCountReply
+	err = json.Unmarshal(responseBody, &ccr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal CommentCountReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ccr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ccr, nil
+}
+
+// BatchCommentCounts retrieves the comment counts of multiple proposals, for
+// example the proposals of an inventory view, keyed by censorship token.
+// Duplicate tokens are only requested once and the tokens are split into as
+// many requests as the server's batch size requires.
+func (c *Client) BatchCommentCounts(tokens []string) (map[string]v1.CommentCount, error) {
+	counts := make(map[string]v1.CommentCount, len(tokens))
+	seen := make(map[string]struct{}, len(tokens))
+	missing := make([]string, 0, len(tokens))
+	for _, v := range tokens {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		missing = append(missing, v)
+	}
+
+	for len(missing) > 0 {
+		n := len(missing)
+		if n > v1.CommentCountsBatchSize {
+			n = v1.CommentCountsBatchSize
+		}
+		bcc := v1.BatchCommentCounts{
+			Tokens: missing[:n],
+		}
+		missing = missing[n:]
+
+		responseBody, err := c.makeRequest("POST",
+			v1.RouteBatchCommentCounts, bcc)
+		if err != nil {
+			return nil, err
+		}
+
+		var bccr v1.BatchCommentCountsReply
+		err = json.Unmarshal(responseBody, &bccr)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal BatchCommentCountsReply: %v",
+				err)
+		}
+
+		if c.cfg.Verbose {
+			err := c.prettyPrintJSON(bccr)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for token, cc := range bccr.CommentCounts {
+			counts[token] = cc
+		}
+	}
+
+	return counts, nil
+}
+
+// sortComments sorts the passed in comments in place using the passed in
+// order.
+func sortComments(comments []v1.Comment, sortBy CommentSortT) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		a, b := comments[i], comments[j]
+		switch sortBy {
+		case CommentSortOldest:
+			if a.Timestamp != b.Timestamp {
+				return a.Timestamp < b.Timestamp
+			}
+		case CommentSortTop:
+			if a.ResultVotes != b.ResultVotes {
+				return a.ResultVotes > b.ResultVotes
+			}
+		case CommentSortControversial:
+			ca, cb := commentControversy(a), commentControversy(b)
+			if ca != cb {
+				return ca > cb
+			}
+		}
+
+		// Newest first
+		if a.Timestamp != b.Timestamp {
+			return a.Timestamp > b.Timestamp
+		}
+		return lessCommentID(a.CommentID, b.CommentID)
+	})
+}
+
+// commentLikes returns the number of upvotes and downvotes of a comment.
+// They are derived from the total number of votes and the vote score.
+func commentLikes(c v1.Comment) (int64, int64) {
+	up := (int64(c.TotalVotes) + c.ResultVotes) / 2
+	down := (int64(c.TotalVotes) - c.ResultVotes) / 2
+	return up, down
+}
+
+// CommentReactionCounts returns the number of users that reacted to a
+// comment with each reaction type.  The upvotes and downvotes of the comment
+// are counted as the up and down reactions.  Reaction types without any
+// reactions are not included.
+func CommentReactionCounts(c v1.Comment) map[string]uint64 {
+	counts := make(map[string]uint64, len(c.Reactions)+2)
+	up, down := commentLikes(c)
+	if up > 0 {
+		counts[v1.ReactionUp] = uint64(up)
+	}
+	if down > 0 {
+		counts[v1.ReactionDown] = uint64(down)
+	}
+	for k, v := range c.Reactions {
+		if v > 0 {
+			counts[k] = v
+		}
+	}
+	return counts
+}
+
+// commentControversy returns the controversy of a comment.  See
+// CommentSortControversial.
+func commentControversy(c v1.Comment) float64 {
+	up, down := commentLikes(c)
+	if up <= 0 || down <= 0 {
+		return 0
+	}
+
+	balance := float64(down) / float64(up)
+	if up < down {
+		balance = float64(up) / float64(down)
+	}
+	return math.Pow(float64(up+down), balance)
+}
+
+// lessCommentID returns whether comment ID a comes before comment ID b.
+// Comment IDs are decimal numbers so shorter IDs come first.
+func lessCommentID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
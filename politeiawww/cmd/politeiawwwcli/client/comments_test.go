@@ -0,0 +1,554 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// newLikeCommentTestServer returns a TLS test server that accepts comment
+// likes.  Likes for comment "2" fail with a user error and likes for comment
+// "3" return a reply with the error field set.
+func newLikeCommentTestServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var lc v1.LikeComment
+			json.NewDecoder(r.Body).Decode(&lc)
+
+			w.Header().Set("Content-Type", "application/json")
+			switch lc.CommentID {
+			case "2":
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusCommentNotFound,
+				})
+			case "3":
+				json.NewEncoder(w).Encode(v1.LikeCommentReply{
+					Error: "like failed",
+				})
+			default:
+				json.NewEncoder(w).Encode(v1.LikeCommentReply{
+					Total:   1,
+					Result:  1,
+					Receipt: "receipt",
+				})
+			}
+		}))
+}
+
+func TestBulkLikeComments(t *testing.T) {
+	s := newLikeCommentTestServer()
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	token := "token"
+	likes := []v1.LikeComment{
+		{Token: token, CommentID: "1", Action: "1"},
+		{Token: token, CommentID: "2", Action: "1"},
+		{Token: token, CommentID: "3", Action: "-1"},
+		{Token: token, CommentID: "4", Action: "1"},
+	}
+
+	t.Run("like for wrong proposal", func(t *testing.T) {
+		_, err := c.BulkLikeComments("other", likes)
+		if err == nil {
+			t.Fatalf("got nil error, want error")
+		}
+	})
+
+	t.Run("aggregated results", func(t *testing.T) {
+		results, err := c.BulkLikeComments(token, likes)
+		if err != nil {
+			t.Fatalf("got error %v, want nil", err)
+		}
+		if len(results) != len(likes) {
+			t.Fatalf("got %v results, want %v", len(results), len(likes))
+		}
+
+		wantFailed := map[string]bool{
+			"1": false,
+			"2": true,
+			"3": true,
+			"4": false,
+		}
+		for i, r := range results {
+			if r.CommentID != likes[i].CommentID {
+				t.Errorf("result %v: got comment %v, want %v",
+					i, r.CommentID, likes[i].CommentID)
+			}
+			failed := r.Error != ""
+			if failed != wantFailed[r.CommentID] {
+				t.Errorf("comment %v: got failed %v, want %v",
+					r.CommentID, failed, wantFailed[r.CommentID])
+			}
+			if !failed && r.Reply == nil {
+				t.Errorf("comment %v: got nil reply", r.CommentID)
+			}
+		}
+	})
+}
+
+func TestSortComments(t *testing.T) {
+	comment := func(id string, timestamp int64, total uint64, result int64) v1.Comment {
+		return v1.Comment{
+			CommentID:   id,
+			Timestamp:   timestamp,
+			TotalVotes:  total,
+			ResultVotes: result,
+		}
+	}
+	comments := []v1.Comment{
+		comment("1", 1, 10, 0), // 5 up, 5 down
+		comment("2", 2, 3, 3),  // 3 up, 0 down
+		comment("3", 3, 4, -2), // 1 up, 3 down
+		comment("10", 3, 0, 0), // No votes
+	}
+
+	var tests = []struct {
+		sortBy CommentSortT
+		want   []string
+	}{
+		{CommentSortNewest, []string{"3", "10", "2", "1"}},
+		{CommentSortOldest, []string{"1", "2", "3", "10"}},
+		{CommentSortTop, []string{"2", "10", "1", "3"}},
+		{CommentSortControversial, []string{"1", "3", "10", "2"}},
+	}
+	for _, test := range tests {
+		t.Run(string(test.sortBy), func(t *testing.T) {
+			c := make([]v1.Comment, len(comments))
+			copy(c, comments)
+			sortComments(c, test.sortBy)
+
+			got := make([]string, 0, len(c))
+			for _, v := range c {
+				got = append(got, v.CommentID)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("got %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentRateLimitError(t *testing.T) {
+	other := &APIError{
+		HTTPCode:  http.StatusBadRequest,
+		ErrorCode: v1.ErrorStatusUserNotPaid,
+	}
+	if err := commentRateLimitError("token", other); err != other {
+		t.Fatalf("got error %v, want %v", err, other)
+	}
+
+	limited := &APIError{
+		HTTPCode:     http.StatusTooManyRequests,
+		ErrorCode:    v1.ErrorStatusRateLimitExceeded,
+		ErrorContext: []string{"42"},
+	}
+	err := commentRateLimitError("token", limited)
+	e, ok := err.(*CommentRateLimitError)
+	if !ok {
+		t.Fatalf("got error %T, want *CommentRateLimitError", err)
+	}
+	if e.Token != "token" || e.RetryAfter != 42*time.Second {
+		t.Errorf("got token %v retry after %v, want token 42s",
+			e.Token, e.RetryAfter)
+	}
+	if e.Err != limited {
+		t.Errorf("got api error %v, want %v", e.Err, limited)
+	}
+}
+
+func TestNewCommentWithAttachments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawwwcli.test")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	files := map[string][]byte{
+		"a.png":     png,
+		"b.png":     png,
+		"c.png":     png,
+		"large.png": append(png, make([]byte, 512)...),
+		"a.pdf":     []byte("%PDF-1.4"),
+	}
+	for name, b := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), b, 0600)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	var got v1.NewComment
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case v1.PoliteiaWWWAPIRoute + v1.RoutePolicy:
+				json.NewEncoder(w).Encode(v1.PolicyReply{
+					MaxCommentFiles:    2,
+					MaxCommentFileSize: 512,
+					ValidMIMETypes:     []string{"image/png"},
+				})
+			case v1.PoliteiaWWWAPIRoute + v1.RouteNewComment:
+				json.NewDecoder(r.Body).Decode(&got)
+				json.NewEncoder(w).Encode(v1.NewCommentReply{})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	// A comment can not be signed without an identity
+	_, err = c.NewCommentWithAttachments("token", "", "comment", nil)
+	if err != ErrIdentityNotFound {
+		t.Fatalf("got error %v, want %v", err, ErrIdentityNotFound)
+	}
+	c.cfg.Identity, err = identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name    string
+		paths   []string
+		wantErr bool
+	}{
+		{"no attachments", nil, false},
+		{"attachments", []string{"a.png", "b.png"}, false},
+		{"too many attachments", []string{"a.png", "b.png", "c.png"},
+			true},
+		{"attachment too large", []string{"large.png"}, true},
+		{"unsupported mime type", []string{"a.pdf"}, true},
+		{"duplicate filenames", []string{"a.png", "a.png"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got = v1.NewComment{}
+			paths := make([]string, 0, len(test.paths))
+			for _, v := range test.paths {
+				paths = append(paths, filepath.Join(dir, v))
+			}
+			_, err := c.NewCommentWithAttachments("token", "1",
+				"comment", paths)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err,
+					test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(got.Files) != len(test.paths) {
+				t.Fatalf("got %v files, want %v", len(got.Files),
+					len(test.paths))
+			}
+			err = VerifyComment(v1.Comment{
+				Token:     got.Token,
+				ParentID:  got.ParentID,
+				Comment:   got.Comment,
+				Signature: got.Signature,
+				PublicKey: got.PublicKey,
+				Files:     got.Files,
+			})
+			if err != nil {
+				t.Errorf("VerifyComment: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetCommentsSince(t *testing.T) {
+	var gotSince string
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			gotSince = r.URL.Query().Get("since")
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.GetCommentsReply{
+				Comments: []v1.Comment{{CommentID: "2"}},
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	since := time.Unix(1571316271, 0)
+	gcr, err := c.GetCommentsSince("token", since)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if gotSince != "1571316271" {
+		t.Errorf("got since %q, want %q", gotSince, "1571316271")
+	}
+	if len(gcr.Comments) != 1 || gcr.Comments[0].CommentID != "2" {
+		t.Errorf("got comments %v, want comment 2", gcr.Comments)
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+				v1.RouteDeleteComment {
+				http.NotFound(w, r)
+				return
+			}
+			var dc v1.DeleteComment
+			json.NewDecoder(r.Body).Decode(&dc)
+
+			w.Header().Set("Content-Type", "application/json")
+			if dc.CommentID == "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusCommentDeletePeriodExpired,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(v1.DeleteCommentReply{
+				Comment: v1.Comment{
+					Token:     dc.Token,
+					CommentID: dc.CommentID,
+					Deleted:   1,
+				},
+				Receipt: "receipt",
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	dcr, err := c.DeleteComment(&v1.DeleteComment{
+		Token:     "token",
+		CommentID: "1",
+	})
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if dcr.Comment.Deleted == 0 || dcr.Comment.Censored {
+		t.Errorf("got deleted %v censored %v, want author deleted",
+			dcr.Comment.Deleted, dcr.Comment.Censored)
+	}
+	if dcr.Receipt != "receipt" {
+		t.Errorf("got receipt %v, want receipt", dcr.Receipt)
+	}
+	err = VerifyComment(dcr.Comment)
+	if err != ErrCommentDeleted {
+		t.Errorf("got verify error %v, want %v", err, ErrCommentDeleted)
+	}
+
+	_, err = c.DeleteComment(&v1.DeleteComment{
+		Token:     "token",
+		CommentID: "2",
+	})
+	e, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("got error %T, want *APIError", err)
+	}
+	if e.ErrorCode != v1.ErrorStatusCommentDeletePeriodExpired {
+		t.Errorf("got error code %v, want %v", e.ErrorCode,
+			v1.ErrorStatusCommentDeletePeriodExpired)
+	}
+}
+
+func TestReactToComment(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+				v1.RouteReactComment {
+				http.NotFound(w, r)
+				return
+			}
+			var rc v1.ReactComment
+			json.NewDecoder(r.Body).Decode(&rc)
+
+			w.Header().Set("Content-Type", "application/json")
+			if rc.Action == v1.ReactionActionRemove {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusInvalidCommentReaction,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(v1.ReactCommentReply{
+				Reactions: map[string]uint64{
+					rc.Reaction: 1,
+				},
+				Receipt: "receipt",
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	rcr, err := c.ReactToComment(&v1.ReactComment{
+		Token:     "token",
+		CommentID: "1",
+		Reaction:  "heart",
+		Action:    v1.ReactionActionAdd,
+	})
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if rcr.Reactions["heart"] != 1 {
+		t.Errorf("got reactions %v, want heart", rcr.Reactions)
+	}
+	if rcr.Receipt != "receipt" {
+		t.Errorf("got receipt %v, want receipt", rcr.Receipt)
+	}
+
+	_, err = c.ReactToComment(&v1.ReactComment{
+		Token:     "token",
+		CommentID: "1",
+		Reaction:  "heart",
+		Action:    v1.ReactionActionRemove,
+	})
+	e, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("got error %T, want *APIError", err)
+	}
+	if e.ErrorCode != v1.ErrorStatusInvalidCommentReaction {
+		t.Errorf("got error code %v, want %v", e.ErrorCode,
+			v1.ErrorStatusInvalidCommentReaction)
+	}
+}
+
+func TestCommentReactionCounts(t *testing.T) {
+	var tests = []struct {
+		name    string
+		comment v1.Comment
+		want    map[string]uint64
+	}{
+		{"no reactions", v1.Comment{}, map[string]uint64{}},
+		{"likes only",
+			v1.Comment{
+				TotalVotes:  5,
+				ResultVotes: 1,
+			},
+			map[string]uint64{
+				v1.ReactionUp:   3,
+				v1.ReactionDown: 2,
+			}},
+		{"likes and reactions",
+			v1.Comment{
+				TotalVotes:  2,
+				ResultVotes: 2,
+				Reactions: map[string]uint64{
+					"heart": 4,
+					"laugh": 0,
+				},
+			},
+			map[string]uint64{
+				v1.ReactionUp: 2,
+				"heart":       4,
+			}},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := CommentReactionCounts(v.comment)
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestGetCommentCount(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+				"/proposals/token/commentcount" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.CommentCountReply{
+				CommentCount: v1.CommentCount{
+					NumComments: 42,
+					LastComment: 1550000000,
+				},
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	ccr, err := c.GetCommentCount("token")
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if ccr.CommentCount.NumComments != 42 ||
+		ccr.CommentCount.LastComment != 1550000000 {
+		t.Errorf("got comment count %v, want 42 comments",
+			ccr.CommentCount)
+	}
+}
+
+func TestBatchCommentCounts(t *testing.T) {
+	// The test server returns a comment count equal to the length of
+	// the token for every requested token.
+	var batches []int
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+				v1.RouteBatchCommentCounts {
+				http.NotFound(w, r)
+				return
+			}
+			var bcc v1.BatchCommentCounts
+			json.NewDecoder(r.Body).Decode(&bcc)
+			batches = append(batches, len(bcc.Tokens))
+
+			counts := make(map[string]v1.CommentCount, len(bcc.Tokens))
+			for _, v := range bcc.Tokens {
+				counts[v] = v1.CommentCount{
+					NumComments: uint(len(v)),
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.BatchCommentCountsReply{
+				CommentCounts: counts,
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	// Token 1 is requested twice and the rest fill up more than one
+	// batch.
+	tokens := []string{"1", "1"}
+	for i := 2; i <= v1.CommentCountsBatchSize+1; i++ {
+		tokens = append(tokens, strconv.Itoa(i))
+	}
+
+	counts, err := c.BatchCommentCounts(tokens)
+	if err != nil {
+		t.Fatalf("BatchCommentCounts: %v", err)
+	}
+	if len(batches) != 2 || batches[0] != v1.CommentCountsBatchSize ||
+		batches[1] != 1 {
+		t.Fatalf("got batches %v, want [%v 1]", batches,
+			v1.CommentCountsBatchSize)
+	}
+	if len(counts) != v1.CommentCountsBatchSize+1 {
+		t.Fatalf("got %v counts, want %v", len(counts),
+			v1.CommentCountsBatchSize+1)
+	}
+	if counts["10"].NumComments != 2 {
+		t.Errorf("got count %v for token 10, want 2", counts["10"])
+	}
+}
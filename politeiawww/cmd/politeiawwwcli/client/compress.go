@@ -0,0 +1,44 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/decred/politeia/util"
+)
+
+// readResponseBody reads the body of the passed in response and decompresses
+// it if it is gzip encoded.  The http transport only decompresses responses
+// transparently when it set the Accept-Encoding header itself, in which case
+// the Content-Encoding header is removed from the response.  Checking the
+// Content-Encoding header ensures that a response is decompressed exactly once
+// regardless of who requested the compression.
+func readResponseBody(r *http.Response) ([]byte, error) {
+	if r.Uncompressed ||
+		!strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		return util.ConvertBodyToByteArray(r.Body, false), nil
+	}
+
+	zr, err := gzip.NewReader(r.Body)
+	if err == io.EOF {
+		// Empty body
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("gzip: %v", err)
+	}
+	defer zr.Close()
+
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %v", err)
+	}
+	return b, nil
+}
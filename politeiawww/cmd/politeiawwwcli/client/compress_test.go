@@ -0,0 +1,106 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// gzipBytes returns the gzip compressed passed in bytes.
+func gzipBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadResponseBody(t *testing.T) {
+	body := []byte(`{"minpasswordlength":8}`)
+
+	var tests = []struct {
+		name         string
+		encoding     string
+		uncompressed bool
+		body         []byte
+		want         []byte
+		wantErr      bool
+	}{
+		{"not compressed", "", false, body, body, false},
+		{"gzip", "gzip", false, gzipBytes(t, body), body, false},
+		{"decompressed by transport", "", true, body, body, false},
+		{"empty gzip body", "gzip", false, nil, nil, false},
+		{"invalid gzip", "gzip", false, body, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &http.Response{
+				Header:       http.Header{},
+				Body:         ioutil.NopCloser(bytes.NewReader(test.body)),
+				Uncompressed: test.uncompressed,
+			}
+			if test.encoding != "" {
+				r.Header.Set("Content-Encoding", test.encoding)
+			}
+			got, err := readResponseBody(r)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if !bytes.Equal(got, test.want) {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMakeRequestGzip(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			b, _ := json.Marshal(v1.PolicyReply{
+				MinPasswordLength: 8,
+			})
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+				w.Write(b)
+				return
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gzipBytes(t, b))
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	pr, err := c.Policy()
+	if err != nil {
+		t.Fatalf("Policy: %v", err)
+	}
+	if pr.MinPasswordLength != 8 {
+		t.Fatalf("got min password length %v, want 8",
+			pr.MinPasswordLength)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"sort"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// CreditStatusT represents whether a proposal credit has been used to submit
+// a proposal.
+type CreditStatusT int
+
+const (
+	CreditStatusAll     CreditStatusT = 0 // Spent and unspent credits
+	CreditStatusUnspent CreditStatusT = 1 // Credits that have not been used
+	CreditStatusSpent   CreditStatusT = 2 // Credits used to submit a proposal
+)
+
+// ProposalCreditsOpts contains the options that are used to filter and page
+// the proposal credits of a user.
+type ProposalCreditsOpts struct {
+	Status CreditStatusT // Filter by whether the credit has been spent
+	After  int64         // Only include credits purchased at or after this unix timestamp; 0 means no lower bound
+	Before int64         // Only include credits purchased before this unix timestamp; 0 means no upper bound
+	Offset int           // Number of matching credits to skip
+	Limit  int           // Maximum number of credits to return; 0 means no limit
+}
+
+// ProposalCreditEntry is a proposal credit along with whether it has been
+// spent.
+type ProposalCreditEntry struct {
+	v1.ProposalCredit
+	Spent bool `json:"spent"` // Whether the credit has been used to submit a proposal
+}
+
+// ProposalCreditsSummary summarizes the proposal credits that were purchased
+// during the requested date range.
+type ProposalCreditsSummary struct {
+	TotalPurchased uint64 `json:"totalpurchased"` // Number of credits purchased
+	TotalSpent     uint64 `json:"totalspent"`     // Number of purchased credits that have been spent
+	Remaining      uint64 `json:"remaining"`      // Number of purchased credits that have not been spent
+	AtomsPurchased uint64 `json:"atomspurchased"` // Sum of the purchase price of the credits in atoms
+}
+
+// UserProposalCreditsFilteredReply contains the requested page of the
+// filtered proposal credits and a summary of the credits.
+type UserProposalCreditsFilteredReply struct {
+	Credits      []ProposalCreditEntry  `json:"credits"`      // Requested page of the matching credits
+	TotalMatches uint64                 `json:"totalmatches"` // Number of matching credits before paging
+	Summary      ProposalCreditsSummary `json:"summary"`      // Summary of the credits in the date range
+}
+
+// UserProposalCreditsFiltered retrieves the proposal credit history of the
+// logged in user and returns the credits that match the passed in options,
+// ordered from oldest to newest purchase.  The summary covers all credits that
+// were purchased during the requested date range, regardless of the status
+// filter and the paging options.
+//
+// politeiawww does not support filtering or paging the proposal credits so
+// the full credit history is fetched and filtered client side.
+func (c *Client) UserProposalCreditsFiltered(opts ProposalCreditsOpts) (*UserProposalCreditsFilteredReply, error) {
+	upcr, err := c.UserProposalCredits()
+	if err != nil {
+		return nil, err
+	}
+
+	return filterProposalCredits(upcr, opts), nil
+}
+
+// filterProposalCredits filters, summarizes and pages the passed in proposal
+// credits using the passed in options.
+func filterProposalCredits(upcr *v1.UserProposalCreditsReply, opts ProposalCreditsOpts) *UserProposalCreditsFilteredReply {
+	inRange := func(pc v1.ProposalCredit) bool {
+		if opts.After != 0 && pc.DatePurchased < opts.After {
+			return false
+		}
+		if opts.Before != 0 && pc.DatePurchased >= opts.Before {
+			return false
+		}
+		return true
+	}
+
+	var (
+		summary ProposalCreditsSummary
+		credits = make([]ProposalCreditEntry, 0,
+			len(upcr.UnspentCredits)+len(upcr.SpentCredits))
+	)
+	add := func(pcs []v1.ProposalCredit, spent bool) {
+		for _, pc := range pcs {
+			if !inRange(pc) {
+				continue
+			}
+
+			summary.TotalPurchased++
+			summary.AtomsPurchased += pc.Price
+			if spent {
+				summary.TotalSpent++
+			} else {
+				summary.Remaining++
+			}
+
+			if (opts.Status == CreditStatusUnspent && spent) ||
+				(opts.Status == CreditStatusSpent && !spent) {
+				continue
+			}
+			credits = append(credits, ProposalCreditEntry{
+				ProposalCredit: pc,
+				Spent:          spent,
+			})
+		}
+	}
+	add(upcr.UnspentCredits, false)
+	add(upcr.SpentCredits, true)
+
+	sort.SliceStable(credits, func(i, j int) bool {
+		return credits[i].DatePurchased < credits[j].DatePurchased
+	})
+
+	total := len(credits)
+	start := opts.Offset
+	switch {
+	case start < 0:
+		start = 0
+	case start > total:
+		start = total
+	}
+	end := total
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+
+	return &UserProposalCreditsFilteredReply{
+		Credits:      credits[start:end],
+		TotalMatches: uint64(total),
+		Summary:      summary,
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestFilterProposalCredits(t *testing.T) {
+	credit := func(txID string, date int64) v1.ProposalCredit {
+		return v1.ProposalCredit{
+			Price:         10,
+			DatePurchased: date,
+			TxID:          txID,
+		}
+	}
+	upcr := &v1.UserProposalCreditsReply{
+		UnspentCredits: []v1.ProposalCredit{
+			credit("u3", 300),
+			credit("u1", 100),
+		},
+		SpentCredits: []v1.ProposalCredit{
+			credit("s2", 200),
+			credit("s4", 400),
+		},
+	}
+
+	var tests = []struct {
+		name    string
+		opts    ProposalCreditsOpts
+		want    []string // Expected txids in order
+		total   uint64
+		summary ProposalCreditsSummary
+	}{
+		{"all", ProposalCreditsOpts{},
+			[]string{"u1", "s2", "u3", "s4"}, 4,
+			ProposalCreditsSummary{4, 2, 2, 40}},
+		{"unspent", ProposalCreditsOpts{Status: CreditStatusUnspent},
+			[]string{"u1", "u3"}, 2,
+			ProposalCreditsSummary{4, 2, 2, 40}},
+		{"spent", ProposalCreditsOpts{Status: CreditStatusSpent},
+			[]string{"s2", "s4"}, 2,
+			ProposalCreditsSummary{4, 2, 2, 40}},
+		{"date range", ProposalCreditsOpts{After: 200, Before: 400},
+			[]string{"s2", "u3"}, 2,
+			ProposalCreditsSummary{2, 1, 1, 20}},
+		{"page", ProposalCreditsOpts{Offset: 1, Limit: 2},
+			[]string{"s2", "u3"}, 4,
+			ProposalCreditsSummary{4, 2, 2, 40}},
+		{"offset past end", ProposalCreditsOpts{Offset: 10},
+			[]string{}, 4,
+			ProposalCreditsSummary{4, 2, 2, 40}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := filterProposalCredits(upcr, test.opts)
+			if len(got.Credits) != len(test.want) {
+				t.Fatalf("got %v credits, want %v", len(got.Credits),
+					len(test.want))
+			}
+			for i, v := range got.Credits {
+				if v.TxID != test.want[i] {
+					t.Fatalf("credit %v: got %v, want %v", i, v.TxID,
+						test.want[i])
+				}
+				if v.Spent != (v.TxID[0] == 's') {
+					t.Fatalf("credit %v: got spent %v", v.TxID, v.Spent)
+				}
+			}
+			if got.TotalMatches != test.total {
+				t.Fatalf("got total matches %v, want %v", got.TotalMatches,
+					test.total)
+			}
+			if got.Summary != test.summary {
+				t.Fatalf("got summary %+v, want %+v", got.Summary,
+					test.summary)
+			}
+		})
+	}
+}
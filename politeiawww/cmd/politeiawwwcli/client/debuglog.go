@@ -0,0 +1,142 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// redacted replaces secrets in the debug log.
+const redacted = "[redacted]"
+
+// Debug log entry types.
+const (
+	debugLogRequest  = "request"
+	debugLogResponse = "response"
+)
+
+// redactedHeaders are the HTTP headers whose values are redacted in the debug
+// log.
+var redactedHeaders = []string{
+	v1.CsrfToken,
+	v1.Authorization,
+	"Cookie",
+}
+
+// debugLogEntry is a single line of the debug log.
+type debugLogEntry struct {
+	Timestamp int64               `json:"timestamp"`         // UNIX timestamp
+	Type      string              `json:"type"`              // Request or response
+	Method    string              `json:"method,omitempty"`  // Request method
+	URL       string              `json:"url,omitempty"`     // Request URL
+	Headers   map[string][]string `json:"headers,omitempty"` // Request headers
+	Status    int                 `json:"status,omitempty"`  // Response status code
+	Body      interface{}         `json:"body,omitempty"`    // Request or response body
+}
+
+// logRequest appends the passed in request to the debug log.  It is a no-op
+// if the debug log is not enabled.
+func (c *Client) logRequest(req *http.Request, body []byte) error {
+	if c.cfg.DebugLog == "" {
+		return nil
+	}
+
+	headers := make(map[string][]string, len(req.Header))
+	for k, v := range req.Header {
+		headers[k] = v
+	}
+	for _, v := range redactedHeaders {
+		k := http.CanonicalHeaderKey(v)
+		if _, ok := headers[k]; ok {
+			headers[k] = []string{redacted}
+		}
+	}
+
+	return c.writeDebugLog(debugLogEntry{
+		Timestamp: time.Now().Unix(),
+		Type:      debugLogRequest,
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		Headers:   headers,
+		Body:      redactBody(body),
+	})
+}
+
+// logResponse appends the passed in response status and body to the debug
+// log.  It is a no-op if the debug log is not enabled.
+func (c *Client) logResponse(statusCode int, body []byte) error {
+	if c.cfg.DebugLog == "" {
+		return nil
+	}
+
+	return c.writeDebugLog(debugLogEntry{
+		Timestamp: time.Now().Unix(),
+		Type:      debugLogResponse,
+		Status:    statusCode,
+		Body:      redactBody(body),
+	})
+}
+
+// writeDebugLog appends the passed in entry to the debug log file as a single
+// JSON line.
+func (c *Client) writeDebugLog(e debugLogEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(c.cfg.DebugLog,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// redactBody decodes the passed in JSON body and redacts the values of all
+// password fields.  Bodies that are not valid JSON are returned as a string.
+func redactBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var v interface{}
+	err := json.Unmarshal(body, &v)
+	if err != nil {
+		return string(body)
+	}
+
+	return redactValue(v)
+}
+
+// redactValue recursively redacts the values of all object keys that contain
+// the word password.
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			if strings.Contains(strings.ToLower(k), "password") {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactValue(v)
+		}
+	case []interface{}:
+		for i, v := range t {
+			t[i] = redactValue(v)
+		}
+	}
+	return v
+}
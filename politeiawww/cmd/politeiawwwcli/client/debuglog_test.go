@@ -0,0 +1,146 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestRedactBody(t *testing.T) {
+	var tests = []struct {
+		name string
+		body string
+		want interface{}
+	}{
+		{"empty body", "", nil},
+		{"not json", "not json", "not json"},
+		{"password fields",
+			`{"email":"a@b.c","password":"p","newpassword":"n"}`,
+			map[string]interface{}{
+				"email":       "a@b.c",
+				"password":    redacted,
+				"newpassword": redacted,
+			}},
+		{"nested password field",
+			`{"users":[{"currentpassword":"p","username":"u"}]}`,
+			map[string]interface{}{
+				"users": []interface{}{
+					map[string]interface{}{
+						"currentpassword": redacted,
+						"username":        "u",
+					},
+				},
+			}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := redactBody([]byte(test.body))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDebugLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestServer()
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.DebugLog = filepath.Join(dir, "debug.log")
+	c.cfg.CSRF = "csrftoken"
+
+	_, err = c.makeRequest("POST", v1.RouteChangePassword,
+		v1.ChangePassword{
+			CurrentPassword: "secretcurrent",
+			NewPassword:     "secretnew",
+		})
+	if err != nil {
+		t.Fatalf("makeRequest: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(c.cfg.DebugLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secretcurrent", "secretnew", "csrftoken"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("debug log contains secret %q", secret)
+		}
+	}
+
+	// The log must contain one request and one response JSON line
+	types := debugLogTypes(t, b)
+	want := []string{debugLogRequest, debugLogResponse}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("got entries %v, want %v", types, want)
+	}
+}
+
+func TestDebugLogLogout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = os.MkdirAll(filepath.Join(dir, "profiles", "test"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestServer()
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.DataDir = dir
+	c.cfg.Profile = "test"
+	c.cfg.DebugLog = filepath.Join(dir, "debug.log")
+
+	_, err = c.Logout()
+	if err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(c.cfg.DebugLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := debugLogTypes(t, b)
+	want := []string{debugLogRequest, debugLogResponse}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("got entries %v, want %v", types, want)
+	}
+}
+
+// debugLogTypes returns the types of the entries of the passed in debug log.
+func debugLogTypes(t *testing.T, b []byte) []string {
+	t.Helper()
+
+	var types []string
+	sc := bufio.NewScanner(strings.NewReader(string(b)))
+	for sc.Scan() {
+		var e debugLogEntry
+		err := json.Unmarshal(sc.Bytes(), &e)
+		if err != nil {
+			t.Fatalf("invalid log line %q: %v", sc.Text(), err)
+		}
+		types = append(types, e.Type)
+	}
+	return types
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffContextLines is the number of unchanged lines that are included around
+// each change in a unified diff.
+const diffContextLines = 3
+
+// DiffProposalVersions returns a unified diff of the changes that were made
+// to a proposal between the two specified versions.  Text files, such as the
+// index.md, are diffed line by line.  Binary files, such as images, are not
+// diffed; only whether they were added, removed or changed is reported.  An
+// empty string is returned if the versions have identical files.
+func (c *Client) DiffProposalVersions(token string, from, to uint64) (string, error) {
+	a, err := c.ProposalVersionDetails(token, from)
+	if err != nil {
+		return "", fmt.Errorf("version %v: %v", from, err)
+	}
+	b, err := c.ProposalVersionDetails(token, to)
+	if err != nil {
+		return "", fmt.Errorf("version %v: %v", to, err)
+	}
+
+	return diffProposalFiles(a.Files, b.Files)
+}
+
+// diffProposalFiles returns a unified diff of the changes between two sets of
+// proposal files.  The files are matched by name.
+func diffProposalFiles(from, to []v1.File) (string, error) {
+	fromFiles := make(map[string]v1.File, len(from))
+	toFiles := make(map[string]v1.File, len(to))
+	names := make([]string, 0, len(from)+len(to))
+	for _, f := range from {
+		fromFiles[f.Name] = f
+		names = append(names, f.Name)
+	}
+	for _, f := range to {
+		toFiles[f.Name] = f
+		if _, ok := fromFiles[f.Name]; !ok {
+			names = append(names, f.Name)
+		}
+	}
+	sort.Strings(names)
+
+	var out bytes.Buffer
+	for _, name := range names {
+		a, inFrom := fromFiles[name]
+		b, inTo := toFiles[name]
+		if inFrom && inTo && a.Digest == b.Digest {
+			continue
+		}
+
+		// Binary files are not diffed
+		if (inFrom && !isTextFile(a)) || (inTo && !isTextFile(b)) {
+			switch {
+			case !inFrom:
+				fmt.Fprintf(&out, "Binary file %v added\n", name)
+			case !inTo:
+				fmt.Fprintf(&out, "Binary file %v removed\n", name)
+			default:
+				fmt.Fprintf(&out, "Binary files a/%v and b/%v differ\n",
+					name, name)
+			}
+			continue
+		}
+
+		d := difflib.UnifiedDiff{
+			FromFile: "/dev/null",
+			ToFile:   "/dev/null",
+			Context:  diffContextLines,
+		}
+		if inFrom {
+			text, err := decodeFilePayload(a)
+			if err != nil {
+				return "", err
+			}
+			d.A = difflib.SplitLines(text)
+			d.FromFile = "a/" + name
+		}
+		if inTo {
+			text, err := decodeFilePayload(b)
+			if err != nil {
+				return "", err
+			}
+			d.B = difflib.SplitLines(text)
+			d.ToFile = "b/" + name
+		}
+		err := difflib.WriteUnifiedDiff(&out, d)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return out.String(), nil
+}
+
+// isTextFile returns whether the file is a text file that can be diffed.
+func isTextFile(f v1.File) bool {
+	return strings.HasPrefix(f.MIME, "text/")
+}
+
+// decodeFilePayload returns the decoded file payload as a string.
+func decodeFilePayload(f v1.File) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(f.Payload)
+	if err != nil {
+		return "", fmt.Errorf("decode payload for file %v: %v", f.Name, err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// newFile returns a proposal file with the provided name, MIME type and
+// payload.
+func newFile(name, mime, payload string) v1.File {
+	return v1.File{
+		Name:    name,
+		MIME:    mime,
+		Digest:  hex.EncodeToString(util.Digest([]byte(payload))),
+		Payload: base64.StdEncoding.EncodeToString([]byte(payload)),
+	}
+}
+
+func TestDiffProposalFiles(t *testing.T) {
+	const textMIME = "text/plain; charset=utf-8"
+	index := newFile("index.md", textMIME, "title\nline one\n")
+	png := newFile("a.png", "image/png", "\x89PNG\r\n\x1a\nA")
+
+	var tests = []struct {
+		name string
+		from []v1.File
+		to   []v1.File
+		want []string // Substrings expected in the diff
+	}{
+		{"no changes",
+			[]v1.File{index, png},
+			[]v1.File{index, png},
+			nil},
+
+		{"text changed",
+			[]v1.File{index},
+			[]v1.File{newFile("index.md", textMIME, "title\nline two\n")},
+			[]string{"--- a/index.md", "+++ b/index.md", "-line one",
+				"+line two"}},
+
+		{"text added",
+			[]v1.File{index},
+			[]v1.File{index, newFile("b.txt", textMIME, "new\n")},
+			[]string{"--- /dev/null", "+++ b/b.txt", "+new"}},
+
+		{"binary added",
+			[]v1.File{index},
+			[]v1.File{index, png},
+			[]string{"Binary file a.png added"}},
+
+		{"binary removed",
+			[]v1.File{index, png},
+			[]v1.File{index},
+			[]string{"Binary file a.png removed"}},
+
+		{"binary changed",
+			[]v1.File{index, png},
+			[]v1.File{index, newFile("a.png", "image/png", "\x89PNG\r\n\x1a\nB")},
+			[]string{"Binary files a/a.png and b/a.png differ"}},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			diff, err := diffProposalFiles(v.from, v.to)
+			if err != nil {
+				t.Fatalf("diffProposalFiles: %v", err)
+			}
+			if len(v.want) == 0 && diff != "" {
+				t.Fatalf("got diff %q, want empty diff", diff)
+			}
+			for _, w := range v.want {
+				if !strings.Contains(diff, w) {
+					t.Errorf("diff %q does not contain %q", diff, w)
+				}
+			}
+		})
+	}
+}
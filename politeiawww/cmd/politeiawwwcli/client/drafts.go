@@ -0,0 +1,170 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// NewDraft saves the passed in files as a new proposal draft of the logged
+// in user.  Drafts are private to the user and are neither signed nor
+// anchored; they must be submitted using NewProposal to become a proposal.
+func (c *Client) NewDraft(files []v1.File) (*v1.NewDraftReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteNewDraft,
+		v1.NewDraft{Files: files})
+	if err != nil {
+		return nil, err
+	}
+
+	var ndr v1.NewDraftReply
+	err = json.Unmarshal(responseBody, &ndr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal NewDraftReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ndr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ndr, nil
+}
+
+// NewDraftFromDir saves the files in the passed in directory as a new
+// proposal draft.  The directory is read the same way as by
+// NewProposalFromDir.
+func (c *Client) NewDraftFromDir(dir string) (*v1.NewDraftReply, error) {
+	pr, err := c.Policy()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := proposalFilesFromDir(dir, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewDraft(files)
+}
+
+// EditDraft replaces the files of the specified draft of the logged in user.
+func (c *Client) EditDraft(draftID string, files []v1.File) (*v1.EditDraftReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteEditDraft,
+		v1.EditDraft{
+			DraftID: draftID,
+			Files:   files,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var edr v1.EditDraftReply
+	err = json.Unmarshal(responseBody, &edr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal EditDraftReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(edr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &edr, nil
+}
+
+// EditDraftFromDir replaces the files of the specified draft with the files
+// in the passed in directory.
+func (c *Client) EditDraftFromDir(draftID, dir string) (*v1.EditDraftReply, error) {
+	pr, err := c.Policy()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := proposalFilesFromDir(dir, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.EditDraft(draftID, files)
+}
+
+// DeleteDraft deletes the specified draft of the logged in user.
+func (c *Client) DeleteDraft(draftID string) (*v1.DeleteDraftReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteDeleteDraft,
+		v1.DeleteDraft{DraftID: draftID})
+	if err != nil {
+		return nil, err
+	}
+
+	var ddr v1.DeleteDraftReply
+	err = json.Unmarshal(responseBody, &ddr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal DeleteDraftReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ddr, nil
+}
+
+// DraftDetails retrieves the specified draft of the logged in user along
+// with its files.
+func (c *Client) DraftDetails(draftID string) (*v1.DraftDetailsReply, error) {
+	responseBody, err := c.makeRequest("GET", "/drafts/"+draftID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ddr v1.DraftDetailsReply
+	err = json.Unmarshal(responseBody, &ddr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal DraftDetailsReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ddr, nil
+}
+
+// UserDrafts retrieves the summaries of the drafts of the logged in user,
+// most recently updated first.
+func (c *Client) UserDrafts() (*v1.UserDraftsReply, error) {
+	responseBody, err := c.makeRequest("GET", v1.RouteUserDrafts, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var udr v1.UserDraftsReply
+	err = json.Unmarshal(responseBody, &udr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal UserDraftsReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(udr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &udr, nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// newDraftsTestServer returns a TLS test server that stores the drafts of a
+// single user in memory.
+func newDraftsTestServer() *httptest.Server {
+	drafts := make(map[string]v1.Draft)
+	notFound := func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(v1.UserError{
+			ErrorCode: v1.ErrorStatusDraftNotFound,
+		})
+	}
+
+	return httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			route := strings.TrimPrefix(r.URL.Path, v1.PoliteiaWWWAPIRoute)
+			switch route {
+			case v1.RouteNewDraft:
+				var nd v1.NewDraft
+				json.NewDecoder(r.Body).Decode(&nd)
+				d := v1.Draft{
+					DraftID: "draft",
+					Files:   nd.Files,
+				}
+				drafts[d.DraftID] = d
+				json.NewEncoder(w).Encode(v1.NewDraftReply{
+					Draft: v1.DraftSummary{
+						DraftID:  d.DraftID,
+						NumFiles: len(d.Files),
+					},
+				})
+			case v1.RouteEditDraft:
+				var ed v1.EditDraft
+				json.NewDecoder(r.Body).Decode(&ed)
+				d, ok := drafts[ed.DraftID]
+				if !ok {
+					notFound(w)
+					return
+				}
+				d.Files = ed.Files
+				drafts[d.DraftID] = d
+				json.NewEncoder(w).Encode(v1.EditDraftReply{
+					Draft: v1.DraftSummary{
+						DraftID:  d.DraftID,
+						NumFiles: len(d.Files),
+					},
+				})
+			case v1.RouteDeleteDraft:
+				var dd v1.DeleteDraft
+				json.NewDecoder(r.Body).Decode(&dd)
+				if _, ok := drafts[dd.DraftID]; !ok {
+					notFound(w)
+					return
+				}
+				delete(drafts, dd.DraftID)
+				json.NewEncoder(w).Encode(v1.DeleteDraftReply{})
+			case v1.RouteUserDrafts:
+				var udr v1.UserDraftsReply
+				for _, d := range drafts {
+					udr.Drafts = append(udr.Drafts, v1.DraftSummary{
+						DraftID:  d.DraftID,
+						NumFiles: len(d.Files),
+					})
+				}
+				json.NewEncoder(w).Encode(udr)
+			default:
+				d, ok := drafts[strings.TrimPrefix(route, "/drafts/")]
+				if !ok {
+					notFound(w)
+					return
+				}
+				json.NewEncoder(w).Encode(v1.DraftDetailsReply{
+					Draft: d,
+				})
+			}
+		}))
+}
+
+func TestDrafts(t *testing.T) {
+	s := newDraftsTestServer()
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	index := v1.File{
+		Name:    indexFile,
+		Payload: "dGl0bGU=",
+	}
+	image := v1.File{
+		Name:    "image.png",
+		Payload: "aW1hZ2U=",
+	}
+
+	ndr, err := c.NewDraft([]v1.File{index})
+	if err != nil {
+		t.Fatalf("NewDraft: %v", err)
+	}
+	draftID := ndr.Draft.DraftID
+
+	edr, err := c.EditDraft(draftID, []v1.File{index, image})
+	if err != nil {
+		t.Fatalf("EditDraft: %v", err)
+	}
+	if edr.Draft.NumFiles != 2 {
+		t.Fatalf("got %v files, want 2", edr.Draft.NumFiles)
+	}
+
+	ddr, err := c.DraftDetails(draftID)
+	if err != nil {
+		t.Fatalf("DraftDetails: %v", err)
+	}
+	if len(ddr.Draft.Files) != 2 || ddr.Draft.Files[1].Name != image.Name {
+		t.Fatalf("got files %v, want %v", ddr.Draft.Files,
+			[]v1.File{index, image})
+	}
+
+	udr, err := c.UserDrafts()
+	if err != nil {
+		t.Fatalf("UserDrafts: %v", err)
+	}
+	if len(udr.Drafts) != 1 || udr.Drafts[0].DraftID != draftID {
+		t.Fatalf("got drafts %v, want %v", udr.Drafts, draftID)
+	}
+
+	_, err = c.DeleteDraft(draftID)
+	if err != nil {
+		t.Fatalf("DeleteDraft: %v", err)
+	}
+
+	_, err = c.DraftDetails(draftID)
+	e, ok := err.(*APIError)
+	if !ok || e.ErrorCode != v1.ErrorStatusDraftNotFound {
+		t.Fatalf("got error %v, want draft not found", err)
+	}
+}
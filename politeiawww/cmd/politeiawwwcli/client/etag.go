@@ -0,0 +1,53 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"sync"
+)
+
+// etagEntry is a cached response body along with the ETag that the server
+// returned for it.
+type etagEntry struct {
+	etag string
+	body []byte
+}
+
+// etagCache caches the most recent response of GET routes that return an
+// ETag, keyed by the full route including the query params.  The cached
+// response is returned when the server replies with 304 Not Modified to a
+// conditional request.
+type etagCache struct {
+	sync.Mutex
+	entries map[string]etagEntry
+}
+
+// get returns the cached response for the route.
+func (e *etagCache) get(route string) (etagEntry, bool) {
+	e.Lock()
+	defer e.Unlock()
+
+	entry, ok := e.entries[route]
+	return entry, ok
+}
+
+// put caches the response for the route.  Responses without an ETag are not
+// cached and remove any existing entry for the route.
+func (e *etagCache) put(route, etag string, body []byte) {
+	e.Lock()
+	defer e.Unlock()
+
+	if etag == "" {
+		delete(e.entries, route)
+		return
+	}
+	if e.entries == nil {
+		e.entries = make(map[string]etagEntry)
+	}
+	e.entries[route] = etagEntry{
+		etag: etag,
+		body: body,
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+func TestConditionalGet(t *testing.T) {
+	var (
+		token       atomic.Value
+		notModified int64
+	)
+	token.Store("token1")
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			rw := httptest.NewRecorder()
+			util.RespondWithJSONETag(rw, r, v1.GetAllVettedReply{
+				Proposals: []v1.ProposalRecord{{
+					CensorshipRecord: v1.CensorshipRecord{
+						Token: token.Load().(string),
+					},
+				}},
+			})
+			if rw.Code == http.StatusNotModified {
+				atomic.AddInt64(&notModified, 1)
+			}
+			for k, v := range rw.Header() {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(rw.Code)
+			w.Write(rw.Body.Bytes())
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	getToken := func() string {
+		t.Helper()
+		gavr, err := c.GetAllVetted(&v1.GetAllVetted{})
+		if err != nil {
+			t.Fatalf("GetAllVetted: %v", err)
+		}
+		if len(gavr.Proposals) != 1 {
+			t.Fatalf("got %v proposals, want 1", len(gavr.Proposals))
+		}
+		return gavr.Proposals[0].CensorshipRecord.Token
+	}
+
+	// The first request is not conditional
+	if got := getToken(); got != "token1" {
+		t.Fatalf("got token %v, want token1", got)
+	}
+	if n := atomic.LoadInt64(&notModified); n != 0 {
+		t.Fatalf("got %v not modified replies, want 0", n)
+	}
+
+	// The unchanged inventory is returned from the cache
+	if got := getToken(); got != "token1" {
+		t.Fatalf("got token %v, want token1", got)
+	}
+	if n := atomic.LoadInt64(&notModified); n != 1 {
+		t.Fatalf("got %v not modified replies, want 1", n)
+	}
+
+	// The changed inventory is refetched
+	token.Store("token2")
+	if got := getToken(); got != "token2" {
+		t.Fatalf("got token %v, want token2", got)
+	}
+	if n := atomic.LoadInt64(&notModified); n != 1 {
+		t.Fatalf("got %v not modified replies, want 1", n)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+const (
+	// mdStreamGeneral is the ID of the metadata stream that contains the
+	// ProposalGeneralMetadata of a proposal.
+	mdStreamGeneral = 0
+
+	// mdStreamStatusChanges is the ID of the metadata stream that contains
+	// the ProposalStatusChanges of a proposal.
+	mdStreamStatusChanges = 2
+)
+
+// ProposalGeneralMetadata is the general metadata of a proposal (stream 0).
+// It is written by politeiawww when a proposal is submitted and replaced when
+// the proposal is edited.
+type ProposalGeneralMetadata struct {
+	Version   uint64 `json:"version"`   // Version of the structure
+	Timestamp int64  `json:"timestamp"` // Last update of the proposal
+	Name      string `json:"name"`      // Proposal name
+	PublicKey string `json:"publickey"` // Author key used for the signature
+	Signature string `json:"signature"` // Author signature of the merkle root
+}
+
+// ProposalStatusChange is a status change of a proposal (stream 2).  A new
+// status change is appended to the stream every time an admin changes the
+// status of the proposal or the author withdraws it.
+type ProposalStatusChange struct {
+	Version             uint             `json:"version"`                       // Version of the structure
+	AdminPubKey         string           `json:"adminpubkey"`                   // Key of the admin that changed the status
+	AuthorPubKey        string           `json:"authorpubkey,omitempty"`        // Key of the author that withdrew the proposal
+	NewStatus           pd.RecordStatusT `json:"newstatus"`                     // New politeiad record status
+	StatusChangeMessage string           `json:"statuschangemessage,omitempty"` // Reason for the change
+	Timestamp           int64            `json:"timestamp"`                     // Time of the change
+}
+
+// ProposalFullRecord is a proposal along with all of the metadata streams
+// that politeiad stores for it, parsed into their types.  Metadata streams
+// that have not been written yet are nil.  Streams with an unknown ID are
+// returned unparsed in Unknown.
+//
+// The vote streams are written by the decred plugin.  AuthorizeVote (stream
+// 13) is the latest vote authorization or revocation by the proposal author.
+// StartVote (stream 14) contains the parameters and options that an admin
+// started the vote with.  StartVoteReply (stream 15) contains the start and
+// end heights of the vote and the snapshot of the eligible tickets.
+type ProposalFullRecord struct {
+	Proposal       v1.ProposalRecord            `json:"proposal"`          // Latest version of the proposal
+	General        *ProposalGeneralMetadata     `json:"general"`           // Stream 0
+	StatusChanges  []ProposalStatusChange       `json:"statuschanges"`     // Stream 2, oldest first
+	AuthorizeVote  *decredplugin.AuthorizeVote  `json:"authorizevote"`     // Stream 13
+	StartVote      *decredplugin.StartVote      `json:"startvote"`         // Stream 14
+	StartVoteReply *decredplugin.StartVoteReply `json:"startvotereply"`    // Stream 15
+	Unknown        []v1.MetadataStream          `json:"unknown,omitempty"` // Unknown streams
+}
+
+// GetProposalFullRecord retrieves the latest version of the specified
+// proposal along with all of its metadata streams parsed into their types.
+// This allows the complete lifecycle of a proposal to be verified
+// independently of politeiawww.
+func (c *Client) GetProposalFullRecord(token string) (*ProposalFullRecord, error) {
+	pdr, err := c.ProposalDetails(token, nil)
+	if err != nil {
+		return nil, err
+	}
+	pmr, err := c.ProposalMetadata(token)
+	if err != nil {
+		return nil, err
+	}
+
+	fr, err := parseProposalMetadata(pmr.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("proposal %v: %v", token, err)
+	}
+	fr.Proposal = pdr.Proposal
+
+	return fr, nil
+}
+
+// parseProposalMetadata parses the passed in metadata streams into a
+// ProposalFullRecord.  The proposal of the returned record is not set.
+func parseProposalMetadata(mds []v1.MetadataStream) (*ProposalFullRecord, error) {
+	var fr ProposalFullRecord
+	for _, v := range mds {
+		var err error
+		payload := []byte(v.Payload)
+		switch v.ID {
+		case mdStreamGeneral:
+			var md ProposalGeneralMetadata
+			err = json.Unmarshal(payload, &md)
+			fr.General = &md
+		case mdStreamStatusChanges:
+			fr.StatusChanges, err = decodeStatusChanges(v.Payload)
+		case decredplugin.MDStreamAuthorizeVote:
+			fr.AuthorizeVote, err = decredplugin.DecodeAuthorizeVote(payload)
+		case decredplugin.MDStreamVoteBits:
+			fr.StartVote, err = decredplugin.DecodeStartVote(payload)
+		case decredplugin.MDStreamVoteSnapshot:
+			fr.StartVoteReply, err = decredplugin.DecodeStartVoteReply(payload)
+		default:
+			fr.Unknown = append(fr.Unknown, v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("metadata stream %v: %v", v.ID, err)
+		}
+	}
+	return &fr, nil
+}
+
+// decodeStatusChanges decodes the status changes stream.  The stream is a
+// sequence of JSON encoded status changes, oldest first.
+func decodeStatusChanges(payload string) ([]ProposalStatusChange, error) {
+	var changes []ProposalStatusChange
+	d := json.NewDecoder(strings.NewReader(payload))
+	for {
+		var sc ProposalStatusChange
+		err := d.Decode(&sc)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		changes = append(changes, sc)
+	}
+	return changes, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestGetProposalFullRecord(t *testing.T) {
+	token := strings.Repeat("ab", 32)
+	metadata := []v1.MetadataStream{
+		{
+			ID:      mdStreamGeneral,
+			Payload: `{"version":1,"name":"proposal","timestamp":1}`,
+		},
+		{
+			ID: mdStreamStatusChanges,
+			Payload: `{"version":1,"newstatus":4,"timestamp":2}` +
+				`{"version":1,"newstatus":6,"timestamp":3}`,
+		},
+		{
+			ID:      decredplugin.MDStreamAuthorizeVote,
+			Payload: `{"version":1,"action":"authorize","token":"` + token + `"}`,
+		},
+		{
+			ID:      decredplugin.MDStreamVoteBits,
+			Payload: `{"version":1,"vote":{"token":"` + token + `","mask":3}}`,
+		},
+		{
+			ID:      decredplugin.MDStreamVoteSnapshot,
+			Payload: `{"version":1,"endheight":"100","eligibletickets":["t1"]}`,
+		},
+		{
+			ID:      99,
+			Payload: `{}`,
+		},
+	}
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasSuffix(r.URL.Path, "/metadata") {
+				json.NewEncoder(w).Encode(v1.ProposalMetadataReply{
+					Metadata: metadata,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(v1.ProposalDetailsReply{
+				Proposal: v1.ProposalRecord{
+					Name: "proposal",
+					CensorshipRecord: v1.CensorshipRecord{
+						Token: token,
+					},
+				},
+			})
+		}))
+	defer s.Close()
+
+	c := newTestClient(t, s, true)
+	fr, err := c.GetProposalFullRecord(token)
+	if err != nil {
+		t.Fatalf("GetProposalFullRecord: %v", err)
+	}
+
+	if fr.Proposal.CensorshipRecord.Token != token {
+		t.Errorf("got proposal %v, want %v",
+			fr.Proposal.CensorshipRecord.Token, token)
+	}
+	if fr.General == nil || fr.General.Name != "proposal" {
+		t.Errorf("got general metadata %v", fr.General)
+	}
+	if len(fr.StatusChanges) != 2 ||
+		fr.StatusChanges[1].NewStatus != pd.RecordStatusArchived {
+		t.Errorf("got status changes %v", fr.StatusChanges)
+	}
+	if fr.AuthorizeVote == nil || fr.AuthorizeVote.Action != "authorize" {
+		t.Errorf("got authorize vote %v", fr.AuthorizeVote)
+	}
+	if fr.StartVote == nil || fr.StartVote.Vote.Mask != 3 {
+		t.Errorf("got start vote %v", fr.StartVote)
+	}
+	if fr.StartVoteReply == nil || fr.StartVoteReply.EndHeight != "100" {
+		t.Errorf("got start vote reply %v", fr.StartVoteReply)
+	}
+	if len(fr.Unknown) != 1 || fr.Unknown[0].ID != 99 {
+		t.Errorf("got unknown streams %v", fr.Unknown)
+	}
+}
+
+func TestParseProposalMetadataInvalid(t *testing.T) {
+	_, err := parseProposalMetadata([]v1.MetadataStream{
+		{
+			ID:      mdStreamStatusChanges,
+			Payload: `{"version":1}{`,
+		},
+	})
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
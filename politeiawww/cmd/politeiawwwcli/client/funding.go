@@ -0,0 +1,78 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// FundingStatus is the funding status of an approved proposal.  Amounts are in
+// US cents.  Proposals that have not been given a budget have Budgeted unset
+// and all amounts set to zero.
+type FundingStatus struct {
+	Token      string  `json:"token"`      // Censorship token
+	Budgeted   bool    `json:"budgeted"`   // Whether a budget has been set
+	Requested  uint64  `json:"requested"`  // Approved budget
+	Spent      uint64  `json:"spent"`      // Total amount disbursed
+	Remaining  int64   `json:"remaining"`  // Requested minus spent; negative when overspent
+	Percentage float64 `json:"percentage"` // Percentage of the budget that has been spent
+}
+
+// String returns a human readable summary of the funding status.
+func (f FundingStatus) String() string {
+	if !f.Budgeted {
+		return fmt.Sprintf("proposal %v has no budget", f.Token)
+	}
+	return fmt.Sprintf("%v of %v spent (%.1f%%), %v remaining",
+		formatCents(int64(f.Spent)), formatCents(int64(f.Requested)),
+		f.Percentage, formatCents(f.Remaining))
+}
+
+// formatCents formats an amount of US cents as dollars.
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%v$%v.%02d", sign, cents/100, cents%100)
+}
+
+// ProposalFundingStatus retrieves the billing status of the specified
+// proposal and returns how much of its approved budget has been disbursed and
+// how much remains.  A proposal that has not been given a budget is not an
+// error; its funding status has Budgeted unset.
+func (c *Client) ProposalFundingStatus(token string) (*FundingStatus, error) {
+	pbr, err := c.GetProposalBilling(token)
+	if e, ok := err.(*APIError); ok &&
+		e.ErrorCode == v1.ErrorStatusProposalBudgetNotSet {
+		return &FundingStatus{
+			Token: token,
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	fs := fundingStatus(pbr)
+	return &fs, nil
+}
+
+// fundingStatus computes the funding status from the passed in billing
+// status.
+func fundingStatus(pbr *v1.ProposalBillingReply) FundingStatus {
+	fs := FundingStatus{
+		Token:     pbr.Token,
+		Budgeted:  true,
+		Requested: pbr.Budget,
+		Spent:     pbr.Spent,
+		Remaining: int64(pbr.Budget) - int64(pbr.Spent),
+	}
+	if pbr.Budget > 0 {
+		fs.Percentage = float64(pbr.Spent) / float64(pbr.Budget) * 100
+	}
+	return fs
+}
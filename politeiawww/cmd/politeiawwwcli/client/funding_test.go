@@ -0,0 +1,98 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestProposalFundingStatus(t *testing.T) {
+	billing := map[string]v1.ProposalBillingReply{
+		"partial": {
+			Token:     "partial",
+			Budget:    100100,
+			Spent:     50050,
+			Remaining: 50050,
+		},
+		"overspent": {
+			Token:     "overspent",
+			Budget:    1000,
+			Spent:     1500,
+			Remaining: -500,
+		},
+	}
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			token := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path,
+				v1.PoliteiaWWWAPIRoute+"/proposals/"), "/billing")
+			pbr, ok := billing[token]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusProposalBudgetNotSet,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(pbr)
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	// Setup tests
+	var tests = []struct {
+		token string
+		want  FundingStatus
+		str   string
+	}{
+		{"partial",
+			FundingStatus{
+				Token:      "partial",
+				Budgeted:   true,
+				Requested:  100100,
+				Spent:      50050,
+				Remaining:  50050,
+				Percentage: 50,
+			},
+			"$500.50 of $1001.00 spent (50.0%), $500.50 remaining"},
+		{"overspent",
+			FundingStatus{
+				Token:      "overspent",
+				Budgeted:   true,
+				Requested:  1000,
+				Spent:      1500,
+				Remaining:  -500,
+				Percentage: 150,
+			},
+			"$15.00 of $10.00 spent (150.0%), -$5.00 remaining"},
+		{"nobudget",
+			FundingStatus{
+				Token: "nobudget",
+			},
+			"proposal nobudget has no budget"},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.token, func(t *testing.T) {
+			fs, err := c.ProposalFundingStatus(v.token)
+			if err != nil {
+				t.Fatalf("ProposalFundingStatus: %v", err)
+			}
+			if *fs != v.want {
+				t.Errorf("got %+v, want %+v", *fs, v.want)
+			}
+			if fs.String() != v.str {
+				t.Errorf("got %q, want %q", fs.String(), v.str)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// Health returns the health of politeiawww and of the services it depends on.
+// politeiawww replies with 503 Service Unavailable when a component is
+// unhealthy; the component statuses are still returned in that case, with
+// Healthy set to false, instead of an error.
+func (c *Client) Health() (*v1.HealthReply, error) {
+	fullRoute := c.cfg.Host + c.apiRoute() + v1.RouteHealth
+
+	// Print request details
+	if c.cfg.Verbose {
+		fmt.Printf("Request: GET %v\n", fullRoute)
+	}
+
+	// Create new http request instead of using makeRequest()
+	// so that the reply of an unhealthy server can be decoded.
+	req, err := http.NewRequest(http.MethodGet, fullRoute, nil)
+	if err != nil {
+		return nil, err
+	}
+	setRequestID(req)
+
+	err = c.logRequest(req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
+
+	// Send request
+	r, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		r.Body.Close()
+	}()
+
+	responseBody := util.ConvertBodyToByteArray(r.Body, false)
+
+	err = c.logResponse(r.StatusCode, responseBody)
+	if err != nil {
+		return nil, fmt.Errorf("debug log: %v", err)
+	}
+
+	// Validate response status
+	if r.StatusCode != http.StatusOK &&
+		r.StatusCode != http.StatusServiceUnavailable {
+		return nil, newAPIError(r, responseBody)
+	}
+
+	var hr v1.HealthReply
+	err = json.Unmarshal(responseBody, &hr)
+	if err != nil {
+		if r.StatusCode != http.StatusOK {
+			return nil, newAPIError(r, responseBody)
+		}
+		return nil, fmt.Errorf("unmarshal HealthReply: %v", err)
+	}
+
+	// Print response details
+	if c.cfg.Verbose {
+		fmt.Printf("Response: %v\n", r.StatusCode)
+		err := c.prettyPrintJSON(hr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &hr, nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestHealth(t *testing.T) {
+	var tests = []struct {
+		name    string
+		status  int
+		reply   interface{}
+		want    bool
+		wantErr bool
+	}{
+		{"healthy", http.StatusOK, v1.HealthReply{Healthy: true},
+			true, false},
+		{"unhealthy", http.StatusServiceUnavailable,
+			v1.HealthReply{Healthy: false}, false, false},
+		{"unavailable without reply", http.StatusServiceUnavailable,
+			"proxy error", false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != v1.PoliteiaWWWAPIRoute+v1.RouteHealth {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(test.status)
+					json.NewEncoder(w).Encode(test.reply)
+				}))
+			defer s.Close()
+			c := newTestClient(t, s, true)
+
+			hr, err := c.Health()
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case test.wantErr:
+				return
+			case err != nil:
+				t.Fatalf("Health: %v", err)
+			}
+			if hr.Healthy != test.want {
+				t.Errorf("got healthy %v, want %v", hr.Healthy, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/util"
+)
+
+// Statuses of an integrity check.
+const (
+	IntegrityPassed  = "passed"
+	IntegrityFailed  = "failed"
+	IntegritySkipped = "skipped"
+)
+
+// IntegrityCheck is the result of a single check of a proposal integrity
+// report.
+type IntegrityCheck struct {
+	Check  string `json:"check"`            // Description of the check
+	Status string `json:"status"`           // Passed, failed or skipped
+	Reason string `json:"reason,omitempty"` // Why the check failed or was skipped
+}
+
+// IntegrityReport is the result of verifying the signature chain of a
+// proposal.  The report is valid when none of the checks failed.
+type IntegrityReport struct {
+	Token   string           `json:"token"`   // Censorship token
+	Version string           `json:"version"` // Proposal version that was verified
+	Valid   bool             `json:"valid"`   // Whether no check failed
+	Checks  []IntegrityCheck `json:"checks"`  // Checks in the order they were made
+}
+
+// add appends the result of a check to the report.  A nil error marks the
+// check as passed.
+func (r *IntegrityReport) add(check string, err error) {
+	ic := IntegrityCheck{
+		Check:  check,
+		Status: IntegrityPassed,
+	}
+	if err != nil {
+		ic.Status = IntegrityFailed
+		ic.Reason = err.Error()
+		r.Valid = false
+	}
+	r.Checks = append(r.Checks, ic)
+}
+
+// skip appends a check that could not be made to the report.
+func (r *IntegrityReport) skip(check, reason string) {
+	r.Checks = append(r.Checks, IntegrityCheck{
+		Check:  check,
+		Status: IntegritySkipped,
+		Reason: reason,
+	})
+}
+
+// VerifyProposalIntegrity fetches the full record of the specified proposal
+// and verifies its complete signature chain against the politeiawww public
+// key.  See VerifyProposalFullRecord for the checks that are made.  An error
+// is only returned when the record could not be retrieved; the result of the
+// verification is returned in the report.
+func (c *Client) VerifyProposalIntegrity(token string) (*IntegrityReport, error) {
+	serverID, err := c.ServerPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	fr, err := c.GetProposalFullRecord(token)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyProposalFullRecord(fr, serverID), nil
+}
+
+// VerifyProposalFullRecord verifies the signature chain of the passed in
+// full record without contacting politeiawww.  The following is verified:
+//
+// The digests of the proposal files match their payloads and the merkle root
+// of the digests matches the censorship record.  The author signature of the
+// merkle root and the censorship record signature of the server are valid.
+// The general metadata contains the key and signature of the author.
+//
+// The status changes only record the public key of the admin, they do not
+// contain a signature.  Only the well formedness of the key is verified and
+// the signature check is reported as skipped.
+//
+// The vote authorization must be signed by the author over
+// token+version+action and its receipt must be signed by the server.  The
+// start vote must be for the proposal and signed over the token.
+func VerifyProposalFullRecord(fr *ProposalFullRecord, serverID *identity.PublicIdentity) *IntegrityReport {
+	p := fr.Proposal
+	cr := p.CensorshipRecord
+	r := IntegrityReport{
+		Token:   cr.Token,
+		Version: p.Version,
+		Valid:   true,
+	}
+
+	// Proposal files
+	if len(p.Files) == 0 {
+		r.skip("file digests", "proposal files were not returned")
+	} else {
+		var err error
+		for _, f := range p.Files {
+			b, e := base64.StdEncoding.DecodeString(f.Payload)
+			if e != nil {
+				err = fmt.Errorf("file %v: invalid payload: %v", f.Name, e)
+				break
+			}
+			if hex.EncodeToString(util.Digest(b)) != f.Digest {
+				err = fmt.Errorf("file %v: digest does not match "+
+					"payload", f.Name)
+				break
+			}
+		}
+		r.add("file digests", err)
+	}
+
+	// Author signature of the merkle root
+	r.add("author signature", verifyProposalAuthor(p))
+
+	// Censorship record
+	r.add("censorship record", verifyServerSignature(serverID,
+		cr.Merkle+cr.Token, cr.Signature))
+
+	// General metadata
+	if fr.General == nil {
+		r.add("general metadata", fmt.Errorf("metadata stream %v "+
+			"not found", mdStreamGeneral))
+	} else {
+		var err error
+		switch {
+		case fr.General.PublicKey != p.PublicKey:
+			err = fmt.Errorf("public key %v does not match the "+
+				"proposal", fr.General.PublicKey)
+		case fr.General.Signature != p.Signature:
+			err = fmt.Errorf("signature %v does not match the "+
+				"proposal", fr.General.Signature)
+		}
+		r.add("general metadata", err)
+	}
+
+	// Status changes
+	for i, v := range fr.StatusChanges {
+		// A status change is made either by an admin or by the
+		// author withdrawing the proposal.
+		check := fmt.Sprintf("status change %v admin key", i)
+		key := v.AdminPubKey
+		if key == "" && v.AuthorPubKey != "" {
+			check = fmt.Sprintf("status change %v author key", i)
+			key = v.AuthorPubKey
+		}
+		_, err := util.IdentityFromString(key)
+		if err != nil {
+			err = fmt.Errorf("invalid public key %v: %v", key, err)
+		}
+		r.add(check, err)
+		r.skip(fmt.Sprintf("status change %v signature", i),
+			"status changes are not signed")
+	}
+
+	// Vote authorization
+	if av := fr.AuthorizeVote; av != nil {
+		var err error
+		switch {
+		case av.Token != cr.Token:
+			err = fmt.Errorf("token %v does not match the proposal",
+				av.Token)
+		case av.PublicKey != p.PublicKey:
+			err = fmt.Errorf("public key %v is not the author key",
+				av.PublicKey)
+		default:
+			err = verifySignature(av.PublicKey,
+				av.Token+p.Version+av.Action, av.Signature)
+		}
+		r.add("vote authorization signature", err)
+		r.add("vote authorization receipt", verifyServerSignature(serverID,
+			av.Signature, av.Receipt))
+	}
+
+	// Vote start
+	if sv := fr.StartVote; sv != nil {
+		var err error
+		if sv.Vote.Token != cr.Token {
+			err = fmt.Errorf("token %v does not match the proposal",
+				sv.Vote.Token)
+		} else {
+			err = verifySignature(sv.PublicKey, sv.Vote.Token,
+				sv.Signature)
+		}
+		r.add("start vote signature", err)
+	}
+
+	return &r
+}
+
+// verifySignature verifies that the passed in hex encoded signature of msg
+// was made using the passed in hex encoded public key.
+func verifySignature(pubKey, msg, signature string) error {
+	id, err := util.IdentityFromString(pubKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %v", err)
+	}
+	sig, err := util.ConvertSignature(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	if !id.VerifyMessage([]byte(msg), sig) {
+		return fmt.Errorf("could not verify signature")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/politeia/decredplugin"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+func TestVerifyProposalFullRecord(t *testing.T) {
+	serverID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorKey := hex.EncodeToString(authorID.Public.Key[:])
+
+	// newFullRecord returns a full record of a proposal with an authorized
+	// and started vote that has a valid signature chain.
+	newFullRecord := func() *ProposalFullRecord {
+		payload := []byte("title\ndescription")
+		files := []v1.File{{
+			Name:    indexFile,
+			MIME:    "text/plain; charset=utf-8",
+			Digest:  hex.EncodeToString(util.Digest(payload)),
+			Payload: base64.StdEncoding.EncodeToString(payload),
+		}}
+		mr, err := merkleRoot(files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := authorID.SignMessage([]byte(mr))
+		token := hex.EncodeToString(util.Digest([]byte("token")))
+		crSig := serverID.SignMessage([]byte(mr + token))
+		avSig := authorID.SignMessage([]byte(token + "1" +
+			v1.AuthVoteActionAuthorize))
+		receipt := serverID.SignMessage([]byte(hex.EncodeToString(avSig[:])))
+		svSig := adminID.SignMessage([]byte(token))
+		return &ProposalFullRecord{
+			Proposal: v1.ProposalRecord{
+				Files:     files,
+				Version:   "1",
+				PublicKey: authorKey,
+				Signature: hex.EncodeToString(sig[:]),
+				CensorshipRecord: v1.CensorshipRecord{
+					Token:     token,
+					Merkle:    mr,
+					Signature: hex.EncodeToString(crSig[:]),
+				},
+			},
+			General: &ProposalGeneralMetadata{
+				PublicKey: authorKey,
+				Signature: hex.EncodeToString(sig[:]),
+			},
+			StatusChanges: []ProposalStatusChange{{
+				AdminPubKey: hex.EncodeToString(adminID.Public.Key[:]),
+			}},
+			AuthorizeVote: &decredplugin.AuthorizeVote{
+				Action:    v1.AuthVoteActionAuthorize,
+				Token:     token,
+				PublicKey: authorKey,
+				Signature: hex.EncodeToString(avSig[:]),
+				Receipt:   hex.EncodeToString(receipt[:]),
+			},
+			StartVote: &decredplugin.StartVote{
+				PublicKey: hex.EncodeToString(adminID.Public.Key[:]),
+				Vote:      decredplugin.Vote{Token: token},
+				Signature: hex.EncodeToString(svSig[:]),
+			},
+		}
+	}
+
+	var tests = []struct {
+		name   string
+		modify func(fr *ProposalFullRecord)
+		failed string // Check that is expected to fail
+	}{
+		{"valid", func(fr *ProposalFullRecord) {}, ""},
+		{"tampered payload", func(fr *ProposalFullRecord) {
+			fr.Proposal.Files[0].Payload = base64.StdEncoding.
+				EncodeToString([]byte("tampered"))
+		}, "file digests"},
+		{"wrong censorship record signature", func(fr *ProposalFullRecord) {
+			cr := fr.Proposal.CensorshipRecord
+			sig := authorID.SignMessage([]byte(cr.Merkle + cr.Token))
+			fr.Proposal.CensorshipRecord.Signature = hex.EncodeToString(sig[:])
+		}, "censorship record"},
+		{"general metadata key", func(fr *ProposalFullRecord) {
+			fr.General.PublicKey = hex.EncodeToString(adminID.Public.Key[:])
+		}, "general metadata"},
+		{"invalid admin key", func(fr *ProposalFullRecord) {
+			fr.StatusChanges[0].AdminPubKey = "00"
+		}, "status change 0 admin key"},
+		{"invalid author key", func(fr *ProposalFullRecord) {
+			fr.StatusChanges[0].AdminPubKey = ""
+			fr.StatusChanges[0].AuthorPubKey = "00"
+		}, "status change 0 author key"},
+		{"authorization of other version", func(fr *ProposalFullRecord) {
+			fr.Proposal.Version = "2"
+		}, "vote authorization signature"},
+		{"forged receipt", func(fr *ProposalFullRecord) {
+			sig := authorID.SignMessage([]byte(fr.AuthorizeVote.Signature))
+			fr.AuthorizeVote.Receipt = hex.EncodeToString(sig[:])
+		}, "vote authorization receipt"},
+		{"start vote of other proposal", func(fr *ProposalFullRecord) {
+			fr.StartVote.Vote.Token = "00"
+		}, "start vote signature"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fr := newFullRecord()
+			test.modify(fr)
+			r := VerifyProposalFullRecord(fr, &serverID.Public)
+			if r.Valid != (test.failed == "") {
+				t.Fatalf("got valid %v, want %v: %v", r.Valid,
+					test.failed == "", r.Checks)
+			}
+			for _, v := range r.Checks {
+				want := IntegrityPassed
+				switch {
+				case v.Check == test.failed:
+					want = IntegrityFailed
+				case v.Check == "status change 0 signature":
+					want = IntegritySkipped
+				}
+				if v.Status != want {
+					t.Errorf("check %v: got %v, want %v", v.Check,
+						v.Status, want)
+				}
+			}
+		})
+	}
+}
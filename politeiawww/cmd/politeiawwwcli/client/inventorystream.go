@@ -0,0 +1,177 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// Reconnect backoff of the inventory stream.  The backoff doubles after every
+// failed connection attempt and is reset once a connection succeeds.  They are
+// variables so that tests can use shorter backoffs.
+var (
+	watchInventoryMinBackoff = time.Second
+	watchInventoryMaxBackoff = time.Minute
+)
+
+// maxInventoryEventSize is the maximum size of a single line of the inventory
+// stream.
+const maxInventoryEventSize = 64 * 1024
+
+// inventoryCallbackError wraps an error that was returned by the callback of
+// WatchInventory so that it can be told apart from stream errors, which cause
+// a reconnect.
+type inventoryCallbackError struct {
+	err error
+}
+
+// Error satisfies the error interface.
+func (e inventoryCallbackError) Error() string {
+	return e.err.Error()
+}
+
+// readInventoryEvents reads server-sent events from r and calls fn with each
+// decoded inventory event.  Comment lines, which are used as keep-alives, and
+// fields other than data are ignored.  Nil is returned when r is exhausted.
+func readInventoryEvents(r io.Reader, fn func(v1.InventoryEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxInventoryEventSize)
+
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line dispatches the event
+			if len(data) == 0 {
+				continue
+			}
+			var e v1.InventoryEvent
+			err := json.Unmarshal([]byte(strings.Join(data, "\n")), &e)
+			if err != nil {
+				return fmt.Errorf("decode inventory event: %v", err)
+			}
+			data = data[:0]
+			err = fn(e)
+			if err != nil {
+				return inventoryCallbackError{err}
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment
+		case strings.HasPrefix(line, "data:"):
+			v := strings.TrimPrefix(line, "data:")
+			data = append(data, strings.TrimPrefix(v, " "))
+		}
+	}
+
+	return scanner.Err()
+}
+
+// streamInventory connects to the inventory stream and calls fn with each
+// inventory event until the stream ends.  It returns whether the connection
+// was established, so that the caller can reset its backoff.
+func (c *Client) streamInventory(ctx context.Context, fn func(v1.InventoryEvent) error) (bool, error) {
+	fullRoute := c.cfg.Host + c.apiRoute() + v1.RouteInventoryStream
+
+	// Print request details
+	if c.cfg.Verbose {
+		fmt.Printf("Request: GET %v\n", fullRoute)
+	}
+
+	// Create new http request instead of using makeRequest()
+	// so that the response body can be streamed.
+	req, err := http.NewRequest(http.MethodGet, fullRoute, nil)
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	c.addCSRFHeader(req)
+	req.Header.Set("Accept", "text/event-stream")
+	setRequestID(req)
+
+	// Send request
+	r, err := c.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		r.Body.Close()
+	}()
+
+	// Validate response status
+	if r.StatusCode != http.StatusOK {
+		return false, newAPIError(r,
+			util.ConvertBodyToByteArray(r.Body, false))
+	}
+
+	// Print response details
+	if c.cfg.Verbose {
+		fmt.Printf("Response: %v\n", r.StatusCode)
+	}
+
+	err = readInventoryEvents(r.Body, fn)
+	if err != nil {
+		return true, err
+	}
+	return true, fmt.Errorf("inventory stream closed")
+}
+
+// WatchInventory streams proposal submissions and status changes from
+// politeiawww and calls fn with each event as it happens.  It blocks until the
+// context is canceled, in which case the context error is returned, or until
+// fn returns an error, which is then returned as is.
+//
+// The stream is reconnected with exponential backoff when the connection is
+// lost.  Events that occur while the stream is disconnected are not replayed,
+// so callers that need a complete view should refetch the inventory after a
+// reconnect.  Only admins receive the events of unvetted proposals.
+func (c *Client) WatchInventory(ctx context.Context, fn func(v1.InventoryEvent) error) error {
+	backoff := watchInventoryMinBackoff
+	for {
+		connected, err := c.streamInventory(ctx, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if e, ok := err.(inventoryCallbackError); ok {
+			return e.err
+		}
+
+		// Client errors other than rate limiting will not go away
+		// by reconnecting.
+		if e, ok := err.(*APIError); ok && e.HTTPCode < 500 &&
+			e.HTTPCode != http.StatusTooManyRequests {
+			return err
+		}
+
+		if connected {
+			backoff = watchInventoryMinBackoff
+		}
+		if c.cfg.Verbose {
+			fmt.Printf("Inventory stream: %v; reconnecting in %v\n",
+				err, backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > watchInventoryMaxBackoff {
+			backoff = watchInventoryMaxBackoff
+		}
+	}
+}
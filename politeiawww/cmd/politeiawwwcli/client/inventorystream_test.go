@@ -0,0 +1,114 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestReadInventoryEvents(t *testing.T) {
+	stream := ": keep-alive\n\n" +
+		"event: proposalstatuschange\n" +
+		"data: {\"event\":\"proposalstatuschange\",\"token\":\"a\"," +
+		"\"status\":4}\n\n" +
+		"event: proposalsubmitted\n" +
+		"data: {\"event\":\"proposalsubmitted\",\n" +
+		"data: \"token\":\"b\"}\n\n"
+
+	var got []v1.InventoryEvent
+	err := readInventoryEvents(strings.NewReader(stream),
+		func(e v1.InventoryEvent) error {
+			got = append(got, e)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("readInventoryEvents: %v", err)
+	}
+	want := []v1.InventoryEvent{
+		{
+			Event:  v1.InventoryEventProposalStatusChange,
+			Token:  "a",
+			Status: v1.PropStatusPublic,
+		},
+		{
+			Event: v1.InventoryEventProposalSubmitted,
+			Token: "b",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Malformed events are errors
+	err = readInventoryEvents(strings.NewReader("data: {\n\n"),
+		func(e v1.InventoryEvent) error {
+			return nil
+		})
+	if err == nil {
+		t.Errorf("malformed event: got nil error, want error")
+	}
+}
+
+func TestWatchInventory(t *testing.T) {
+	min := watchInventoryMinBackoff
+	watchInventoryMinBackoff = time.Millisecond
+	defer func() {
+		watchInventoryMinBackoff = min
+	}()
+
+	// Every connection receives a single event and is then closed
+	var connections int
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+				v1.RouteInventoryStream {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			connections++
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"token\":\"%v\"}\n\n", connections)
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	// The stream is reconnected until the callback returns an error
+	errDone := errors.New("done")
+	var tokens []string
+	err := c.WatchInventory(context.Background(),
+		func(e v1.InventoryEvent) error {
+			tokens = append(tokens, e.Token)
+			if len(tokens) == 2 {
+				return errDone
+			}
+			return nil
+		})
+	if err != errDone {
+		t.Fatalf("got error %v, want %v", err, errDone)
+	}
+	if !reflect.DeepEqual(tokens, []string{"1", "2"}) {
+		t.Errorf("got tokens %v, want [1 2]", tokens)
+	}
+
+	// Canceling the context stops watching
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.WatchInventory(ctx, func(e v1.InventoryEvent) error {
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
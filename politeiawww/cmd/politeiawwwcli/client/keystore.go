@@ -0,0 +1,397 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// keystoreVersion is the version of the keystore file format.
+	keystoreVersion = 1
+
+	// Length of the scrypt salt and of the derived secretbox key.
+	keystoreSaltSize = 32
+	keystoreKeySize  = 32
+)
+
+// Scrypt parameters that are used to derive the secretbox key from the
+// passphrase.  They are variables so that tests can use cheaper parameters.
+var (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	// ErrKeystoreLocked is returned when signing with a keystore that has
+	// not been unlocked.
+	ErrKeystoreLocked = errors.New("keystore is locked")
+
+	// ErrKeystoreEmpty is returned when the keystore does not contain the
+	// requested identity.
+	ErrKeystoreEmpty = errors.New("keystore does not contain an identity")
+
+	// ErrKeystoreExists is returned when creating a keystore that already
+	// contains an identity.
+	ErrKeystoreExists = errors.New("keystore already contains an identity")
+
+	// ErrWrongPassphrase is returned when an identity can not be unsealed
+	// with the passed in passphrase.
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// sealedIdentity is an identity whose private key is encrypted with a
+// secretbox key that is derived from a passphrase using scrypt.  The public
+// key is not encrypted so that it can be read without the passphrase.
+type sealedIdentity struct {
+	PublicKey string `json:"publickey"` // Hex encoded public key
+	Salt      string `json:"salt"`      // Hex encoded scrypt salt
+	Nonce     string `json:"nonce"`     // Hex encoded secretbox nonce
+	Box       string `json:"box"`       // Hex encoded sealed private key
+}
+
+// keystoreFile is the on-disk format of a keystore.
+type keystoreFile struct {
+	Version int             `json:"version"`
+	Active  *sealedIdentity `json:"active,omitempty"`  // Identity used for signing
+	Pending *sealedIdentity `json:"pending,omitempty"` // Identity pending verification
+}
+
+// Keystore stores the identity of a user on disk with its private key
+// encrypted using a passphrase.  The active identity must be unlocked before
+// it can be used for signing and is only kept in memory in plain text until
+// the keystore is locked again.
+//
+// A new identity can be staged while the active identity is being replaced
+// using UpdateUserKey.  The staged identity becomes the active identity once
+// politeiawww has verified it.
+type Keystore struct {
+	mtx      sync.Mutex
+	path     string
+	file     keystoreFile
+	unlocked *identity.FullIdentity
+}
+
+// OpenKeystore opens the keystore at the passed in path.  The keystore file
+// is created once an identity is stored in it.
+func OpenKeystore(path string) (*Keystore, error) {
+	ks := Keystore{
+		path: path,
+		file: keystoreFile{
+			Version: keystoreVersion,
+		},
+	}
+
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &ks, nil
+	case err != nil:
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &ks.file)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal keystore: %v", err)
+	}
+	if ks.file.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %v",
+			ks.file.Version)
+	}
+
+	return &ks, nil
+}
+
+// save writes the keystore to disk.  It must be called with the lock held.
+func (k *Keystore) save() error {
+	b, err := json.MarshalIndent(k.file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a failed write does not
+	// destroy the existing keystore.
+	tmp := k.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, k.path)
+}
+
+// Create stores the passed in identity as the active identity, encrypted with
+// the passed in passphrase.  The keystore is left locked.
+func (k *Keystore) Create(id *identity.FullIdentity, passphrase []byte) error {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if k.file.Active != nil {
+		return ErrKeystoreExists
+	}
+
+	sealed, err := sealIdentity(id, passphrase)
+	if err != nil {
+		return err
+	}
+	k.file.Active = sealed
+
+	return k.save()
+}
+
+// PublicKey returns the hex encoded public key of the active identity.  The
+// keystore does not need to be unlocked.
+func (k *Keystore) PublicKey() (string, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if k.file.Active == nil {
+		return "", ErrKeystoreEmpty
+	}
+	return k.file.Active.PublicKey, nil
+}
+
+// Unlock decrypts the active identity using the passed in passphrase
+// so that it can be used for signing.
+func (k *Keystore) Unlock(passphrase []byte) error {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if k.file.Active == nil {
+		return ErrKeystoreEmpty
+	}
+
+	id, err := unsealIdentity(k.file.Active, passphrase)
+	if err != nil {
+		return err
+	}
+	k.lock()
+	k.unlocked = id
+
+	return nil
+}
+
+// Lock removes the decrypted active identity from memory.
+func (k *Keystore) Lock() {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	k.lock()
+}
+
+// lock removes the decrypted active identity from memory.  It must be
+// called with the lock held.
+func (k *Keystore) lock() {
+	if k.unlocked == nil {
+		return
+	}
+	for i := range k.unlocked.PrivateKey {
+		k.unlocked.PrivateKey[i] = 0
+	}
+	k.unlocked = nil
+}
+
+// SignMessage signs the passed in message with the active identity.  The
+// keystore must be unlocked.
+func (k *Keystore) SignMessage(msg []byte) ([identity.SignatureSize]byte, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if k.unlocked == nil {
+		return [identity.SignatureSize]byte{}, ErrKeystoreLocked
+	}
+	return k.unlocked.SignMessage(msg), nil
+}
+
+// StageIdentity generates a new identity and stores it, encrypted with the
+// passed in passphrase, as the pending identity.  Any previously staged
+// identity is replaced.  The new identity is returned so that it can be
+// submitted to politeiawww using UpdateUserKey.
+func (k *Keystore) StageIdentity(passphrase []byte) (*identity.FullIdentity, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	id, err := identity.New()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealIdentity(id, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	k.file.Pending = sealed
+
+	err = k.save()
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// PendingIdentity decrypts the pending identity using the passed in
+// passphrase.
+func (k *Keystore) PendingIdentity(passphrase []byte) (*identity.FullIdentity, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if k.file.Pending == nil {
+		return nil, ErrKeystoreEmpty
+	}
+	return unsealIdentity(k.file.Pending, passphrase)
+}
+
+// CommitIdentity replaces the active identity with the pending identity.  It
+// must only be called once politeiawww has verified the pending identity.  The
+// keystore is left locked.
+func (k *Keystore) CommitIdentity() error {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if k.file.Pending == nil {
+		return ErrKeystoreEmpty
+	}
+
+	k.lock()
+	k.file.Active = k.file.Pending
+	k.file.Pending = nil
+
+	return k.save()
+}
+
+// sealIdentity encrypts the private key of the passed in identity with a key
+// that is derived from the passphrase.
+func sealIdentity(id *identity.FullIdentity, passphrase []byte) (*sealedIdentity, error) {
+	var salt [keystoreSaltSize]byte
+	_, err := rand.Read(salt[:])
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	_, err = rand.Read(nonce[:])
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKeystoreKey(passphrase, salt[:])
+	if err != nil {
+		return nil, err
+	}
+	box := secretbox.Seal(nil, id.PrivateKey[:], &nonce, key)
+
+	return &sealedIdentity{
+		PublicKey: hex.EncodeToString(id.Public.Key[:]),
+		Salt:      hex.EncodeToString(salt[:]),
+		Nonce:     hex.EncodeToString(nonce[:]),
+		Box:       hex.EncodeToString(box),
+	}, nil
+}
+
+// unsealIdentity decrypts the private key of the passed in sealed identity.
+// ErrWrongPassphrase is returned if the passphrase does not match.
+func unsealIdentity(s *sealedIdentity, passphrase []byte) (*identity.FullIdentity, error) {
+	salt, err := hex.DecodeString(s.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %v", err)
+	}
+	n, err := hex.DecodeString(s.Nonce)
+	if err != nil || len(n) != 24 {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	box, err := hex.DecodeString(s.Box)
+	if err != nil {
+		return nil, fmt.Errorf("invalid box: %v", err)
+	}
+
+	key, err := deriveKeystoreKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], n)
+	privKey, ok := secretbox.Open(nil, box, &nonce, key)
+	if !ok {
+		return nil, ErrWrongPassphrase
+	}
+	if len(privKey) != identity.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key size %v", len(privKey))
+	}
+
+	// The ed25519 private key contains the public key
+	var id identity.FullIdentity
+	copy(id.PrivateKey[:], privKey)
+	copy(id.Public.Key[:], privKey[identity.PrivateKeySize-
+		identity.PublicKeySize:])
+	if hex.EncodeToString(id.Public.Key[:]) != s.PublicKey {
+		return nil, fmt.Errorf("public key mismatch")
+	}
+
+	return &id, nil
+}
+
+// deriveKeystoreKey derives the secretbox key from the passphrase and salt.
+func deriveKeystoreKey(passphrase, salt []byte) (*[keystoreKeySize]byte, error) {
+	b, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP,
+		keystoreKeySize)
+	if err != nil {
+		return nil, err
+	}
+	var key [keystoreKeySize]byte
+	copy(key[:], b)
+	return &key, nil
+}
+
+// StageUserKey generates a new identity in the passed in keystore and submits
+// its public key to politeiawww using UpdateUserKey.  The new identity stays
+// pending in the keystore until it is verified using VerifyStagedUserKey.
+func (c *Client) StageUserKey(ks *Keystore, passphrase []byte) (*v1.UpdateUserKeyReply, error) {
+	id, err := ks.StageIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.UpdateUserKey(&v1.UpdateUserKey{
+		PublicKey: hex.EncodeToString(id.Public.Key[:]),
+	})
+}
+
+// VerifyStagedUserKey signs the passed in verification token with the pending
+// identity of the keystore and verifies it with politeiawww.  The pending
+// identity becomes the active identity of the keystore once politeiawww has
+// accepted it.
+func (c *Client) VerifyStagedUserKey(ks *Keystore, passphrase []byte, token string) (*v1.VerifyUpdateUserKeyReply, error) {
+	id, err := ks.PendingIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := id.SignMessage([]byte(token))
+	vuukr, err := c.VerifyUpdateUserKey(&v1.VerifyUpdateUserKey{
+		VerificationToken: token,
+		Signature:         hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = ks.CommitIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	return vuukr, nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// newTestKeystore returns a keystore in a temporary directory that uses cheap
+// scrypt parameters.
+func newTestKeystore(t *testing.T) (*Keystore, func()) {
+	t.Helper()
+
+	n := scryptN
+	scryptN = 1 << 4
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	ks, err := OpenKeystore(filepath.Join(dir, "keystore.json"))
+	if err != nil {
+		t.Fatalf("OpenKeystore: %v", err)
+	}
+
+	return ks, func() {
+		scryptN = n
+		os.RemoveAll(dir)
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	ks, cleanup := newTestKeystore(t)
+	defer cleanup()
+
+	id, err := identity.New()
+	if err != nil {
+		t.Fatalf("identity.New: %v", err)
+	}
+	passphrase := []byte("passphrase")
+	msg := []byte("message")
+
+	_, err = ks.SignMessage(msg)
+	if err != ErrKeystoreLocked {
+		t.Fatalf("got error %v, want %v", err, ErrKeystoreLocked)
+	}
+	err = ks.Unlock(passphrase)
+	if err != ErrKeystoreEmpty {
+		t.Fatalf("got error %v, want %v", err, ErrKeystoreEmpty)
+	}
+
+	err = ks.Create(id, passphrase)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	err = ks.Create(id, passphrase)
+	if err != ErrKeystoreExists {
+		t.Fatalf("got error %v, want %v", err, ErrKeystoreExists)
+	}
+
+	// The private key must not be stored in plain text
+	b, err := ioutil.ReadFile(ks.path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	privKey := hex.EncodeToString(id.PrivateKey[:])
+	if strings.Contains(string(b), privKey) {
+		t.Fatalf("keystore contains the plain text private key")
+	}
+
+	// Reopen the keystore to verify that it was persisted
+	ks, err = OpenKeystore(ks.path)
+	if err != nil {
+		t.Fatalf("OpenKeystore: %v", err)
+	}
+	pk, err := ks.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	if pk != id.Public.String() {
+		t.Fatalf("got public key %v, want %v", pk, id.Public.String())
+	}
+
+	err = ks.Unlock([]byte("wrong"))
+	if err != ErrWrongPassphrase {
+		t.Fatalf("got error %v, want %v", err, ErrWrongPassphrase)
+	}
+	err = ks.Unlock(passphrase)
+	if err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	sig, err := ks.SignMessage(msg)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if !id.Public.VerifyMessage(msg, sig) {
+		t.Fatalf("invalid signature")
+	}
+
+	ks.Lock()
+	_, err = ks.SignMessage(msg)
+	if err != ErrKeystoreLocked {
+		t.Fatalf("got error %v, want %v", err, ErrKeystoreLocked)
+	}
+}
+
+func TestStageUserKey(t *testing.T) {
+	ks, cleanup := newTestKeystore(t)
+	defer cleanup()
+
+	oldID, err := identity.New()
+	if err != nil {
+		t.Fatalf("identity.New: %v", err)
+	}
+	passphrase := []byte("passphrase")
+	err = ks.Create(oldID, passphrase)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// The test server verifies the signature of the verification token
+	// with the public key that was submitted.
+	var pubKey string
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case v1.PoliteiaWWWAPIRoute + v1.RouteUpdateUserKey:
+				var uuk v1.UpdateUserKey
+				json.NewDecoder(r.Body).Decode(&uuk)
+				pubKey = uuk.PublicKey
+				json.NewEncoder(w).Encode(v1.UpdateUserKeyReply{
+					VerificationToken: "token",
+				})
+			case v1.PoliteiaWWWAPIRoute + v1.RouteVerifyUpdateUserKey:
+				var vuuk v1.VerifyUpdateUserKey
+				json.NewDecoder(r.Body).Decode(&vuuk)
+				err := verifyHexSignature(pubKey, vuuk.VerificationToken,
+					vuuk.Signature)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(v1.UserError{
+						ErrorCode: v1.ErrorStatusInvalidSignature,
+					})
+					return
+				}
+				json.NewEncoder(w).Encode(v1.VerifyUpdateUserKeyReply{})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	uukr, err := c.StageUserKey(ks, passphrase)
+	if err != nil {
+		t.Fatalf("StageUserKey: %v", err)
+	}
+
+	// The active identity is not replaced until it is verified
+	pk, err := ks.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	if pk != oldID.Public.String() {
+		t.Fatalf("active identity was replaced before verification")
+	}
+
+	_, err = c.VerifyStagedUserKey(ks, passphrase, uukr.VerificationToken)
+	if err != nil {
+		t.Fatalf("VerifyStagedUserKey: %v", err)
+	}
+	pk, err = ks.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	if pk != pubKey {
+		t.Fatalf("got public key %v, want %v", pk, pubKey)
+	}
+}
+
+// verifyHexSignature verifies the hex encoded signature of msg using the hex
+// encoded public key.
+func verifyHexSignature(pubKey, msg, signature string) error {
+	id, err := util.IdentityFromString(pubKey)
+	if err != nil {
+		return err
+	}
+	sig, err := util.ConvertSignature(signature)
+	if err != nil {
+		return err
+	}
+	if !id.VerifyMessage([]byte(msg), sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
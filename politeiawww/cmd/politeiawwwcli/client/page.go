@@ -0,0 +1,106 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Page describes the position of a list reply within the paginated list that
+// it is a part of.
+//
+// politeiawww does not report whether more pages exist so HasMore is derived
+// from the reply length.  A full page is assumed to be followed by another
+// page, which means that when the total number of items is a multiple of the
+// page size the final page that is fetched is empty.
+type Page struct {
+	HasMore bool   `json:"hasmore"` // Whether more pages may exist
+	After   string `json:"after"`   // Cursor to use as the after param of the next page
+}
+
+// proposalsPage returns the page of the passed in proposals.  The cursor of
+// a proposal list is the censorship token of the last proposal.
+func proposalsPage(props []v1.ProposalRecord, pageSize uint) Page {
+	if pageSize == 0 || uint(len(props)) < pageSize {
+		return Page{}
+	}
+	return Page{
+		HasMore: true,
+		After:   props[len(props)-1].CensorshipRecord.Token,
+	}
+}
+
+// VettedPage returns the page of a GetAllVetted reply using the page size of
+// the passed in server policy.  The page size of the policy is not the page
+// size of the reply when the pagesize config option is set; see
+// VettedPageSize.
+func VettedPage(gavr *v1.GetAllVettedReply, pr *v1.PolicyReply) Page {
+	return proposalsPage(gavr.Proposals, pr.ProposalListPageSize)
+}
+
+// UnvettedPage returns the page of a GetAllUnvetted reply using the page size
+// of the passed in server policy.
+func UnvettedPage(gaur *v1.GetAllUnvettedReply, pr *v1.PolicyReply) Page {
+	return proposalsPage(gaur.Proposals, pr.ProposalListPageSize)
+}
+
+// UserProposalsPage returns the page of a UserProposals reply using the page
+// size of the passed in server policy.
+func UserProposalsPage(upr *v1.UserProposalsReply, pr *v1.PolicyReply) Page {
+	return proposalsPage(upr.Proposals, pr.ProposalListPageSize)
+}
+
+// UsersPage returns the page of a Users reply.  The reply contains the total
+// number of matching users so HasMore is exact.  The Users route does not
+// accept a cursor so After is never set; the remaining users can only be
+// retrieved by narrowing the filters.
+func UsersPage(ur *v1.UsersReply) Page {
+	return Page{
+		HasMore: uint64(len(ur.Users)) < ur.TotalMatches,
+	}
+}
+
+// listLimit returns the limit to send with a list request given the
+// configured page size and the maximum page size of the server.  No limit is
+// sent, which makes the server use its maximum page size, when no page size
+// is configured, when the server does not accept a limit or when the
+// configured page size is not below the maximum.
+func listLimit(pageSize, max uint, supported bool) uint {
+	if pageSize == 0 || !supported || pageSize >= max {
+		return 0
+	}
+	return pageSize
+}
+
+// listPolicyReply returns the server policy that the list page sizes are
+// derived from.  It is cached for the lifetime of the client so that paging
+// through a list does not request the policy for every page.
+func (c *Client) listPolicyReply() (*v1.PolicyReply, error) {
+	if c.listPolicy != nil {
+		return c.listPolicy, nil
+	}
+	pr, err := c.Policy()
+	if err != nil {
+		return nil, err
+	}
+	c.listPolicy = pr
+	return pr, nil
+}
+
+// VettedPageSize returns the number of proposals that GetAllVetted returns
+// per page, which is the configured page size if the server accepts it and
+// the server page size otherwise.  It must be used instead of the policy page
+// size to tell whether more pages exist when a page size is configured.
+func (c *Client) VettedPageSize() (uint, error) {
+	pr, err := c.listPolicyReply()
+	if err != nil {
+		return 0, err
+	}
+	limit := listLimit(c.cfg.PageSize, pr.ProposalListPageSize, pr.ListLimit)
+	if limit != 0 {
+		return limit, nil
+	}
+	return pr.ProposalListPageSize, nil
+}
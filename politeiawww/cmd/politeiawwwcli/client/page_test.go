@@ -0,0 +1,159 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestVettedPage(t *testing.T) {
+	pr := &v1.PolicyReply{
+		ProposalListPageSize: 2,
+	}
+	prop := func(token string) v1.ProposalRecord {
+		return v1.ProposalRecord{
+			CensorshipRecord: v1.CensorshipRecord{
+				Token: token,
+			},
+		}
+	}
+
+	var tests = []struct {
+		name  string
+		props []v1.ProposalRecord
+		want  Page
+	}{
+		{"empty", nil, Page{}},
+		{"partial page", []v1.ProposalRecord{prop("a")}, Page{}},
+		{"full page", []v1.ProposalRecord{prop("a"), prop("b")},
+			Page{HasMore: true, After: "b"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := VettedPage(&v1.GetAllVettedReply{
+				Proposals: test.props,
+			}, pr)
+			if got != test.want {
+				t.Fatalf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUsersPage(t *testing.T) {
+	var tests = []struct {
+		name  string
+		users int
+		total uint64
+		want  bool
+	}{
+		{"no matches", 0, 0, false},
+		{"all matches", 2, 2, false},
+		{"truncated", 2, 5, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := UsersPage(&v1.UsersReply{
+				TotalMatches: test.total,
+				Users:        make([]v1.AbridgedUser, test.users),
+			})
+			if got.HasMore != test.want {
+				t.Fatalf("got HasMore %v, want %v", got.HasMore, test.want)
+			}
+		})
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	var tests = []struct {
+		name      string
+		pageSize  uint
+		max       uint
+		supported bool
+		want      uint
+	}{
+		{"not configured", 0, 20, true, 0},
+		{"below max", 5, 20, true, 5},
+		{"equal to max", 20, 20, true, 0},
+		{"above max", 50, 20, true, 0},
+		{"not supported", 5, 20, false, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := listLimit(test.pageSize, test.max, test.supported)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetAllVettedPageSize(t *testing.T) {
+	var (
+		supported bool
+		limits    []string
+	)
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case v1.PoliteiaWWWAPIRoute + v1.RoutePolicy:
+				json.NewEncoder(w).Encode(v1.PolicyReply{
+					ProposalListPageSize: 20,
+					ListLimit:            supported,
+				})
+			case v1.PoliteiaWWWAPIRoute + v1.RouteAllVetted:
+				limits = append(limits, r.URL.Query().Get("limit"))
+				json.NewEncoder(w).Encode(v1.GetAllVettedReply{})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer s.Close()
+
+	var tests = []struct {
+		name      string
+		pageSize  uint
+		supported bool
+		wantLimit string
+		wantSize  uint
+	}{
+		{"server page size", 0, true, "", 20},
+		{"configured page size", 5, true, "5", 5},
+		{"clamped to server max", 50, true, "", 20},
+		{"server without limit", 5, false, "", 20},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			supported = test.supported
+			limits = nil
+			c := newTestClient(t, s, true)
+			c.cfg.PageSize = test.pageSize
+
+			_, err := c.GetAllVetted(&v1.GetAllVetted{})
+			if err != nil {
+				t.Fatalf("GetAllVetted: %v", err)
+			}
+			if len(limits) != 1 || limits[0] != test.wantLimit {
+				t.Errorf("got limit %q, want %q", limits,
+					test.wantLimit)
+			}
+
+			size, err := c.VettedPageSize()
+			if err != nil {
+				t.Fatalf("VettedPageSize: %v", err)
+			}
+			if size != test.wantSize {
+				t.Errorf("got page size %v, want %v", size,
+					test.wantSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// WaitForProposalCredits polls the pending proposal credit payment of the
+// logged in user until the payment has at least minConfirmations block
+// confirmations or the context is cancelled.  The payment is checked every
+// PaywallPollInterval.  The last status that was received is returned along
+// with the context error if the context is cancelled.
+//
+// The status is returned immediately if the user does not have a pending
+// payment.  politeiawww stops reporting a payment as pending once it has
+// reached the number of confirmations that the server requires and the
+// proposal credits have been added to the user's account.  If this happens
+// while waiting, the last status that was received while the payment was
+// still pending is returned.
+//
+// A warning is printed when politeiawww is not synced while waiting since the
+// confirmations of the payment do not increase until it is.
+func (c *Client) WaitForProposalCredits(ctx context.Context, minConfirmations int) (*v1.ProposalPaywallPaymentReply, error) {
+	pppr, err := c.ProposalPaywallPayment()
+	if err != nil {
+		return nil, err
+	}
+	if pppr.TxID == "" {
+		// No pending payment
+		return pppr, nil
+	}
+
+	var bhr *v1.BlockHeightReply
+	ticker := time.NewTicker(c.cfg.PaywallPollInterval)
+	defer ticker.Stop()
+
+	for pppr.Confirmations < uint64(minConfirmations) {
+		// Confirmations only increase while politeiawww is synced
+		bhr, err = c.checkSync(bhr)
+		if err != nil {
+			return pppr, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return pppr, ctx.Err()
+		case <-ticker.C:
+		}
+
+		r, err := c.ProposalPaywallPayment()
+		if err != nil {
+			return pppr, err
+		}
+		if r.TxID == "" {
+			// Payment is no longer pending
+			break
+		}
+		pppr = r
+	}
+
+	return pppr, nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// newPaywallTestServer returns a TLS test server that replies to proposal
+// paywall payment requests with the passed in replies, in order.  The last
+// reply is repeated once all replies have been sent.  Block height requests
+// are replied to with a synced best block.
+func newPaywallTestServer(replies []v1.ProposalPaywallPaymentReply) *httptest.Server {
+	var (
+		mtx sync.Mutex
+		i   int
+	)
+	return httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == v1.PoliteiaWWWAPIRoute+v1.RouteBlockHeight {
+				json.NewEncoder(w).Encode(v1.BlockHeightReply{
+					Synced: true,
+				})
+				return
+			}
+
+			mtx.Lock()
+			reply := replies[i]
+			if i < len(replies)-1 {
+				i++
+			}
+			mtx.Unlock()
+
+			json.NewEncoder(w).Encode(reply)
+		}))
+}
+
+func TestWaitForProposalCredits(t *testing.T) {
+	pending := func(confirmations uint64) v1.ProposalPaywallPaymentReply {
+		return v1.ProposalPaywallPaymentReply{
+			TxID:          "txid",
+			TxAmount:      100000000,
+			Confirmations: confirmations,
+		}
+	}
+
+	var tests = []struct {
+		name             string
+		replies          []v1.ProposalPaywallPaymentReply
+		minConfirmations int
+		want             v1.ProposalPaywallPaymentReply
+	}{
+		{
+			"no pending payment",
+			[]v1.ProposalPaywallPaymentReply{{}},
+			2,
+			v1.ProposalPaywallPaymentReply{},
+		},
+		{
+			"already confirmed",
+			[]v1.ProposalPaywallPaymentReply{pending(3)},
+			2,
+			pending(3),
+		},
+		{
+			"confirmations reached",
+			[]v1.ProposalPaywallPaymentReply{pending(0), pending(1),
+				pending(2), pending(3)},
+			2,
+			pending(2),
+		},
+		{
+			"payment no longer pending",
+			[]v1.ProposalPaywallPaymentReply{pending(0), pending(1), {}},
+			6,
+			pending(1),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newPaywallTestServer(test.replies)
+			defer s.Close()
+			c := newTestClient(t, s, true)
+			c.cfg.PaywallPollInterval = time.Millisecond
+
+			got, err := c.WaitForProposalCredits(context.Background(),
+				test.minConfirmations)
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if *got != test.want {
+				t.Errorf("got %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestWaitForProposalCreditsCancel(t *testing.T) {
+	s := newPaywallTestServer([]v1.ProposalPaywallPaymentReply{{
+		TxID:          "txid",
+		Confirmations: 1,
+	}})
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.PaywallPollInterval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		50*time.Millisecond)
+	defer cancel()
+
+	got, err := c.WaitForProposalCredits(ctx, 6)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got == nil || got.Confirmations != 1 {
+		t.Errorf("got %+v, want last received status", got)
+	}
+}
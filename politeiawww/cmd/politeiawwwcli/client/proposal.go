@@ -0,0 +1,456 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrtime/merkle"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// indexFile is the name of the proposal markdown file that contains the
+// proposal title and description.
+const indexFile = "index.md"
+
+var (
+	// ErrIdentityNotFound is returned when a request needs to be signed
+	// but there is no identity for the logged in user.
+	ErrIdentityNotFound = errors.New("user identity not found; you must " +
+		"be logged in and have an identity")
+
+	// ErrIndexFileNotFound is returned when a proposal directory does not
+	// contain an index.md file.
+	ErrIndexFileNotFound = errors.New("proposal directory must contain " +
+		"an " + indexFile + " file")
+)
+
+// NewProposalFromDir submits a new proposal made up of all the files in the
+// passed in directory.  The directory must contain an index.md file, which is
+// the proposal markdown.  All other files are submitted as attachments.
+// Subdirectories and hidden files are ignored.  The files are validated
+// against the server policy and the proposal is signed using the identity of
+// the logged in user.
+func (c *Client) NewProposalFromDir(dir string) (*v1.NewProposalReply, error) {
+	if c.cfg.Identity == nil {
+		return nil, ErrIdentityNotFound
+	}
+
+	pr, err := c.Policy()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := proposalFilesFromDir(dir, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signedMerkleRoot(files, c.cfg.Identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewProposal(&v1.NewProposal{
+		Files:     files,
+		PublicKey: hex.EncodeToString(c.cfg.Identity.Public.Key[:]),
+		Signature: sig,
+	})
+}
+
+// ProposalVersion describes a single version of a proposal.
+type ProposalVersion struct {
+	Version   string `json:"version"`   // Proposal version
+	Timestamp int64  `json:"timestamp"` // Timestamp of the version
+}
+
+// ProposalVersionDetails retrieves the specified version of a proposal.
+func (c *Client) ProposalVersionDetails(token string, version uint64) (*v1.ProposalRecord, error) {
+	pdr, err := c.ProposalDetails(token, &v1.ProposalsDetails{
+		Version: strconv.FormatUint(version, 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pdr.Proposal, nil
+}
+
+// GetProposalVersions returns all of the versions of a proposal, ordered from
+// oldest to newest.  A new proposal version is created each time the proposal
+// is edited.
+//
+// politeiawww does not provide a route that lists the proposal versions so
+// each version is fetched individually.
+func (c *Client) GetProposalVersions(token string) ([]ProposalVersion, error) {
+	pdr, err := c.ProposalDetails(token, nil)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := strconv.ParseUint(pdr.Proposal.Version, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposal version %v: %v",
+			pdr.Proposal.Version, err)
+	}
+
+	versions := make([]ProposalVersion, 0, latest)
+	for i := uint64(1); i < latest; i++ {
+		pr, err := c.ProposalVersionDetails(token, i)
+		if err != nil {
+			return nil, fmt.Errorf("version %v: %v", i, err)
+		}
+		versions = append(versions, ProposalVersion{
+			Version:   pr.Version,
+			Timestamp: pr.Timestamp,
+		})
+	}
+	versions = append(versions, ProposalVersion{
+		Version:   pdr.Proposal.Version,
+		Timestamp: pdr.Proposal.Timestamp,
+	})
+
+	return versions, nil
+}
+
+// DownloadProposalFile fetches the latest version of a proposal and writes the
+// payload of the file with the passed in filename to destPath.  The decoded
+// payload is verified against the file digest of the proposal record before it
+// is written.  An error is returned if the proposal does not contain the file
+// or if the digest does not match.
+func (c *Client) DownloadProposalFile(token, filename, destPath string) error {
+	pdr, err := c.ProposalDetails(token, nil)
+	if err != nil {
+		return err
+	}
+
+	b, err := proposalFilePayload(pdr.Proposal.Files, filename)
+	if err != nil {
+		return fmt.Errorf("proposal %v: %v", token, err)
+	}
+
+	return ioutil.WriteFile(util.CleanAndExpandPath(destPath), b, 0644)
+}
+
+// proposalFilePayload returns the decoded payload of the file with the passed
+// in filename after verifying it against the file digest.
+func proposalFilePayload(files []v1.File, filename string) ([]byte, error) {
+	for _, f := range files {
+		if f.Name != filename {
+			continue
+		}
+
+		b, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode payload for file %v: %v",
+				filename, err)
+		}
+		digest := hex.EncodeToString(util.Digest(b))
+		if digest != f.Digest {
+			return nil, fmt.Errorf("file %v: digest mismatch: got %v, "+
+				"want %v", filename, digest, f.Digest)
+		}
+
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("file %v not found", filename)
+}
+
+// proposalFilesFromDir reads the files in the passed in directory and
+// converts them into proposal files.  The index.md file is always the first
+// file.  An error is returned if any file violates the passed in policy.
+func proposalFilesFromDir(dir string, pr *v1.PolicyReply) ([]v1.File, error) {
+	dir = util.CleanAndExpandPath(dir)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	validMIME := make(map[string]struct{}, len(pr.ValidMIMETypes))
+	for _, v := range pr.ValidMIMETypes {
+		validMIME[v] = struct{}{}
+	}
+
+	var (
+		index     *v1.File
+		files     = make([]v1.File, 0, len(fis))
+		numMDs    uint
+		numImages uint
+	)
+	for _, fi := range fis {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := mime.DetectMimeType(b)
+		if _, ok := validMIME[m]; !ok {
+			return nil, fmt.Errorf("file %v: unsupported MIME type %v",
+				fi.Name(), m)
+		}
+
+		size := uint(len(b))
+		if strings.HasPrefix(m, "image/") {
+			numImages++
+			if size > pr.MaxImageSize {
+				return nil, fmt.Errorf("file %v: image exceeds max "+
+					"size of %v bytes", fi.Name(), pr.MaxImageSize)
+			}
+		} else {
+			numMDs++
+			if size > pr.MaxMDSize {
+				return nil, fmt.Errorf("file %v: markdown exceeds max "+
+					"size of %v bytes", fi.Name(), pr.MaxMDSize)
+			}
+		}
+
+		f := v1.File{
+			Name:    fi.Name(),
+			MIME:    m,
+			Digest:  hex.EncodeToString(util.Digest(b)),
+			Payload: base64.StdEncoding.EncodeToString(b),
+		}
+		if f.Name == indexFile {
+			index = &f
+			continue
+		}
+		files = append(files, f)
+	}
+
+	if index == nil {
+		return nil, ErrIndexFileNotFound
+	}
+	if numMDs > pr.MaxMDs {
+		return nil, fmt.Errorf("number of markdown files exceeds max of %v",
+			pr.MaxMDs)
+	}
+	if numImages > pr.MaxImages {
+		return nil, fmt.Errorf("number of images exceeds max of %v",
+			pr.MaxImages)
+	}
+
+	return append([]v1.File{*index}, files...), nil
+}
+
+// merkleRoot returns the merkle root of the digests of the passed in files.
+func merkleRoot(files []v1.File) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("no proposal files found")
+	}
+
+	digests := make([]*[sha256.Size]byte, 0, len(files))
+	for _, f := range files {
+		d, ok := util.ConvertDigest(f.Digest)
+		if !ok {
+			return "", fmt.Errorf("invalid digest: file:%v digest:%v",
+				f.Name, f.Digest)
+		}
+		digests = append(digests, &d)
+	}
+
+	return hex.EncodeToString(merkle.Root(digests)[:]), nil
+}
+
+// signedMerkleRoot calculates the merkle root of the passed in files, signs it
+// with the passed in identity and returns the hex encoded signature.
+func signedMerkleRoot(files []v1.File, id *identity.FullIdentity) (string, error) {
+	mr, err := merkleRoot(files)
+	if err != nil {
+		return "", err
+	}
+	sig := id.SignMessage([]byte(mr))
+	return hex.EncodeToString(sig[:]), nil
+}
+
+// verifyProposalEdit verifies that the passed in updated proposal is the
+// version that the passed in edit created on top of the passed in previous
+// version.  The token must be unchanged, the version must directly follow the
+// previous version, the merkle root and author signature must be those of the edit and the
+// censorship record must be signed by politeiawww.
+func verifyProposalEdit(serverID *identity.PublicIdentity, ep *v1.EditProposal, prev, updated v1.ProposalRecord) error {
+	cr := updated.CensorshipRecord
+	if cr.Token != ep.Token || prev.CensorshipRecord.Token != ep.Token {
+		return fmt.Errorf("token changed from %v to %v", ep.Token, cr.Token)
+	}
+
+	prevVersion, err := strconv.ParseUint(prev.Version, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid previous version %q", prev.Version)
+	}
+	version, err := strconv.ParseUint(updated.Version, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid version %q", updated.Version)
+	}
+	if version != prevVersion+1 {
+		return fmt.Errorf("version went from %v to %v", prevVersion,
+			version)
+	}
+
+	mr, err := merkleRoot(ep.Files)
+	if err != nil {
+		return err
+	}
+	if cr.Merkle != mr {
+		return fmt.Errorf("merkle root %v is not the merkle root of the "+
+			"edit %v", cr.Merkle, mr)
+	}
+	if updated.PublicKey != ep.PublicKey ||
+		updated.Signature != ep.Signature {
+		return fmt.Errorf("author signature is not the signature of the " +
+			"edit")
+	}
+
+	err = verifyServerSignature(serverID, cr.Merkle+cr.Token, cr.Signature)
+	if err != nil {
+		return fmt.Errorf("censorship record: %v", err)
+	}
+
+	return nil
+}
+
+// verifyProposalFiles verifies that the digest of every passed in file
+// matches its decoded payload and that the passed in signature is a signature
+// of the merkle root of the files and the passed in RFP links made with the
+// passed in public key; see v1.ProposalSignatureMessage.  It is run before a
+// proposal is sent so that client bugs result in a precise local error
+// instead of a server rejection.
+func verifyProposalFiles(files []v1.File, publicKey, signature, linkTo string, linkBy int64) error {
+	for _, f := range files {
+		b, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			return ValidationError{
+				Field:  "payload of file " + f.Name,
+				Reason: fmt.Sprintf("not base64: %v", err),
+			}
+		}
+		digest := hex.EncodeToString(util.Digest(b))
+		if digest != f.Digest {
+			return ValidationError{
+				Field: "digest of file " + f.Name,
+				Reason: fmt.Sprintf("payload digest is %v, file "+
+					"digest is %v", digest, f.Digest),
+			}
+		}
+	}
+
+	mr, err := merkleRoot(files)
+	if err != nil {
+		return err
+	}
+	pid, err := util.IdentityFromString(publicKey)
+	if err != nil {
+		return ValidationError{
+			Field:  "public key",
+			Reason: err.Error(),
+		}
+	}
+	sig, err := util.ConvertSignature(signature)
+	if err != nil {
+		return ValidationError{
+			Field:  "signature",
+			Reason: err.Error(),
+		}
+	}
+	msg := v1.ProposalSignatureMessage(mr, linkTo, linkBy)
+	if !pid.VerifyMessage([]byte(msg), sig) {
+		return ValidationError{
+			Field: "signature",
+			Reason: fmt.Sprintf("not a signature of the merkle root "+
+				"%v of the files and the rfp links by the public key",
+				mr),
+		}
+	}
+
+	return nil
+}
+
+// CheckRFPLink fetches the proposal with the passed in token and returns an
+// error if new proposals can not be linked to it.  It is meant to be called
+// before submitting a proposal that sets LinkTo so that the submission fails
+// with a clear error when the RFP does not accept submissions.
+func (c *Client) CheckRFPLink(token string) error {
+	pdr, err := c.ProposalDetails(token, nil)
+	if err != nil {
+		return fmt.Errorf("rfp %v: %v", token, err)
+	}
+	return checkRFPLink(pdr.Proposal, time.Now())
+}
+
+// checkRFPLink returns an error if the passed in proposal is not an RFP that
+// accepts submissions at the passed in time.
+func checkRFPLink(rfp v1.ProposalRecord, now time.Time) error {
+	token := rfp.CensorshipRecord.Token
+	switch {
+	case rfp.LinkBy == 0:
+		return fmt.Errorf("proposal %v is not an rfp", token)
+	case rfp.Status != v1.PropStatusPublic:
+		return fmt.Errorf("rfp %v is not public; status is %v", token,
+			v1.PropStatus[rfp.Status])
+	case rfp.LinkBy <= now.Unix():
+		return fmt.Errorf("rfp %v: the submission period closed at %v",
+			token, time.Unix(rfp.LinkBy, 0).UTC().Format(time.RFC1123))
+	}
+	return nil
+}
+
+// statusBlockedDuringVote contains the proposal statuses that
+// CheckProposalStatusChange refuses to set while a proposal vote is in
+// progress.
+var statusBlockedDuringVote = map[v1.PropStatusT]bool{
+	v1.PropStatusCensored:  true,
+	v1.PropStatusAbandoned: true,
+}
+
+// CheckProposalStatusChange returns an error if the passed in status change
+// would remove a proposal whose vote is in progress.  It is meant to be called
+// before SetProposalStatus so that such changes are not made by accident
+// during a live vote.  Only public proposals can be voted on so the vote
+// status is only fetched when the proposal is public.
+func (c *Client) CheckProposalStatusChange(sps *v1.SetProposalStatus) error {
+	if !statusBlockedDuringVote[sps.ProposalStatus] {
+		return nil
+	}
+
+	pdr, err := c.ProposalDetails(sps.Token, nil)
+	if err != nil {
+		return err
+	}
+	if pdr.Proposal.Status != v1.PropStatusPublic {
+		return nil
+	}
+
+	vsr, err := c.VoteStatus(sps.Token)
+	if err != nil {
+		return err
+	}
+	return checkStatusChangeVote(sps.ProposalStatus, vsr)
+}
+
+// checkStatusChangeVote returns an error if the passed in proposal status may
+// not be set while the proposal has the passed in vote status.
+func checkStatusChangeVote(status v1.PropStatusT, vsr *v1.VoteStatusReply) error {
+	if !statusBlockedDuringVote[status] ||
+		vsr.Status != v1.PropVoteStatusStarted {
+		return nil
+	}
+	return fmt.Errorf("proposal %v: cannot change status to %v while the "+
+		"vote is in progress; the vote ends at block height %v", vsr.Token,
+		v1.PropStatus[status], vsr.EndHeight)
+}
@@ -0,0 +1,475 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+func TestProposalFilesFromDir(t *testing.T) {
+	policy := &v1.PolicyReply{
+		MaxImages:    1,
+		MaxImageSize: 512,
+		MaxMDs:       1,
+		MaxMDSize:    512,
+		ValidMIMETypes: []string{
+			"image/png",
+			"text/plain; charset=utf-8",
+		},
+	}
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+
+	var tests = []struct {
+		name      string
+		files     map[string][]byte
+		wantNames []string
+		wantErr   bool
+	}{
+		{"success",
+			map[string][]byte{
+				"a.png":    png,
+				"index.md": []byte("title\ndescription"),
+				".hidden":  []byte("ignored"),
+			},
+			[]string{"index.md", "a.png"}, false},
+
+		{"missing index",
+			map[string][]byte{
+				"a.png": png,
+			},
+			nil, true},
+
+		{"unsupported mime type",
+			map[string][]byte{
+				"index.md": []byte("title"),
+				"a.pdf":    []byte("%PDF-1.4"),
+			},
+			nil, true},
+
+		{"too many images",
+			map[string][]byte{
+				"index.md": []byte("title"),
+				"a.png":    png,
+				"b.png":    png,
+			},
+			nil, true},
+
+		{"markdown too large",
+			map[string][]byte{
+				"index.md": bytes.Repeat([]byte("a"), 513),
+			},
+			nil, true},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "politeiawwwcli.test")
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			defer os.RemoveAll(dir)
+
+			for name, b := range v.files {
+				err := ioutil.WriteFile(filepath.Join(dir, name), b, 0600)
+				if err != nil {
+					t.Fatalf("%v", err)
+				}
+			}
+
+			files, err := proposalFilesFromDir(dir, policy)
+			if (err != nil) != v.wantErr {
+				t.Fatalf("got error %v, want error %v", err, v.wantErr)
+			}
+			if len(files) != len(v.wantNames) {
+				t.Fatalf("got %v files, want %v",
+					len(files), len(v.wantNames))
+			}
+			for i, f := range files {
+				if f.Name != v.wantNames[i] {
+					t.Errorf("file %v got %v, want %v",
+						i, f.Name, v.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadProposalFile(t *testing.T) {
+	budget := []byte("item,amount\nhosting,100\n")
+	newFile := func(name string, payload []byte) v1.File {
+		return v1.File{
+			Name:    name,
+			MIME:    "text/plain; charset=utf-8",
+			Digest:  hex.EncodeToString(util.Digest(payload)),
+			Payload: base64.StdEncoding.EncodeToString(payload),
+		}
+	}
+	corrupt := newFile("corrupt.csv", budget)
+	corrupt.Payload = base64.StdEncoding.EncodeToString([]byte("tampered"))
+
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.ProposalDetailsReply{
+				Proposal: v1.ProposalRecord{
+					Files: []v1.File{
+						newFile("index.md", []byte("title")),
+						newFile("budget.csv", budget),
+						corrupt,
+					},
+				},
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var tests = []struct {
+		name     string
+		filename string
+		wantErr  bool
+	}{
+		{"success", "budget.csv", false},
+		{"file not found", "missing.csv", true},
+		{"digest mismatch", "corrupt.csv", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dest := filepath.Join(dir, test.filename)
+			err := c.DownloadProposalFile("token", test.filename, dest)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got nil error, want error")
+				}
+				if _, err := os.Stat(dest); !os.IsNotExist(err) {
+					t.Fatalf("file %v was written", dest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			b, err := ioutil.ReadFile(dest)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if !bytes.Equal(b, budget) {
+				t.Fatalf("got %q, want %q", b, budget)
+			}
+		})
+	}
+}
+
+func TestVerifyProposalFiles(t *testing.T) {
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newFiles returns a valid index file
+	newFiles := func() []v1.File {
+		b := []byte("# Title\n")
+		return []v1.File{{
+			Name:    indexFile,
+			MIME:    "text/plain; charset=utf-8",
+			Digest:  hex.EncodeToString(util.Digest(b)),
+			Payload: base64.StdEncoding.EncodeToString(b),
+		}}
+	}
+	pubKey := hex.EncodeToString(id.Public.Key[:])
+	sig, err := signedMerkleRoot(newFiles(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSig, err := signedMerkleRoot(newFiles(), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrongDigest := newFiles()
+	wrongDigest[0].Digest = hex.EncodeToString(util.Digest([]byte("x")))
+	badPayload := newFiles()
+	badPayload[0].Payload = "!"
+
+	var tests = []struct {
+		name      string
+		files     []v1.File
+		signature string
+		wantErr   bool
+	}{
+		{"valid", newFiles(), sig, false},
+		{"digest mismatch", wrongDigest, sig, true},
+		{"payload not base64", badPayload, sig, true},
+		{"no files", nil, sig, true},
+		{"signed by other key", newFiles(), otherSig, true},
+		{"invalid signature", newFiles(), "zz", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifyProposalFiles(test.files, pubKey,
+				test.signature, "", 0)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %v", err,
+					test.wantErr)
+			}
+		})
+	}
+
+	// The signature must cover the RFP links
+	err = verifyProposalFiles(newFiles(), pubKey, sig, "token", 0)
+	if err == nil {
+		t.Errorf("got nil error for unsigned rfp link, want error")
+	}
+	mr, err := merkleRoot(newFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkSig := id.SignMessage([]byte(v1.ProposalSignatureMessage(mr,
+		"token", 0)))
+	err = verifyProposalFiles(newFiles(), pubKey,
+		hex.EncodeToString(linkSig[:]), "token", 0)
+	if err != nil {
+		t.Errorf("signed rfp link: %v", err)
+	}
+}
+
+func TestVerifyProposalEdit(t *testing.T) {
+	serverID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := []byte("# Edited title\n")
+	files := []v1.File{{
+		Name:    indexFile,
+		MIME:    "text/plain; charset=utf-8",
+		Digest:  hex.EncodeToString(util.Digest(b)),
+		Payload: base64.StdEncoding.EncodeToString(b),
+	}}
+	mr, err := merkleRoot(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signedMerkleRoot(files, authorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := hex.EncodeToString(util.Digest([]byte("token")))
+	ep := &v1.EditProposal{
+		Token:     token,
+		Files:     files,
+		PublicKey: hex.EncodeToString(authorID.Public.Key[:]),
+		Signature: sig,
+	}
+	prev := v1.ProposalRecord{
+		Version: "1",
+		CensorshipRecord: v1.CensorshipRecord{
+			Token: token,
+		},
+	}
+
+	// newUpdated returns the proposal that politeiawww returns for
+	// the edit, with the censorship record signed by the passed in
+	// identity.
+	newUpdated := func(id *identity.FullIdentity) v1.ProposalRecord {
+		crSig := id.SignMessage([]byte(mr + token))
+		return v1.ProposalRecord{
+			Files:     files,
+			PublicKey: ep.PublicKey,
+			Signature: ep.Signature,
+			Version:   "2",
+			CensorshipRecord: v1.CensorshipRecord{
+				Token:     token,
+				Merkle:    mr,
+				Signature: hex.EncodeToString(crSig[:]),
+			},
+		}
+	}
+
+	otherToken := newUpdated(serverID)
+	otherToken.CensorshipRecord.Token = "other"
+	older := newUpdated(serverID)
+	older.Version = "0"
+	skipped := newUpdated(serverID)
+	skipped.Version = "3"
+	staleMerkle := newUpdated(serverID)
+	staleMerkle.CensorshipRecord.Merkle = hex.EncodeToString(
+		util.Digest([]byte("previous")))
+	otherSig := newUpdated(serverID)
+	otherSig.Signature = "00"
+
+	var tests = []struct {
+		name    string
+		updated v1.ProposalRecord
+		wantErr bool
+	}{
+		{"valid", newUpdated(serverID), false},
+		{"token changed", otherToken, true},
+		{"older version", older, true},
+		{"skipped version", skipped, true},
+		{"stale merkle root", staleMerkle, true},
+		{"other author signature", otherSig, true},
+		{"not signed by server", newUpdated(authorID), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifyProposalEdit(&serverID.Public, ep, prev,
+				test.updated)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %v", err,
+					test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckProposalStatusChange(t *testing.T) {
+	token := hex.EncodeToString(util.Digest([]byte("token")))
+
+	var (
+		requests   []string
+		propStatus v1.PropStatusT
+		voteStatus v1.PropVoteStatusT
+	)
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasSuffix(r.URL.Path, "/votestatus") {
+				requests = append(requests, "votestatus")
+				json.NewEncoder(w).Encode(v1.VoteStatusReply{
+					Token:     token,
+					Status:    voteStatus,
+					EndHeight: "100",
+				})
+				return
+			}
+			requests = append(requests, "details")
+			json.NewEncoder(w).Encode(v1.ProposalDetailsReply{
+				Proposal: v1.ProposalRecord{
+					Status: propStatus,
+				},
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	var tests = []struct {
+		name         string
+		propStatus   v1.PropStatusT
+		status       v1.PropStatusT
+		voteStatus   v1.PropVoteStatusT
+		wantRequests []string
+		wantErr      bool
+	}{
+		{"censor during vote", v1.PropStatusPublic, v1.PropStatusCensored,
+			v1.PropVoteStatusStarted,
+			[]string{"details", "votestatus"}, true},
+		{"abandon during vote", v1.PropStatusPublic,
+			v1.PropStatusAbandoned, v1.PropVoteStatusStarted,
+			[]string{"details", "votestatus"}, true},
+		{"abandon before vote", v1.PropStatusPublic,
+			v1.PropStatusAbandoned, v1.PropVoteStatusNotAuthorized,
+			[]string{"details", "votestatus"}, false},
+		{"abandon after vote", v1.PropStatusPublic,
+			v1.PropStatusAbandoned, v1.PropVoteStatusFinished,
+			[]string{"details", "votestatus"}, false},
+		{"censor unvetted", v1.PropStatusNotReviewed,
+			v1.PropStatusCensored, v1.PropVoteStatusStarted,
+			[]string{"details"}, false},
+		{"make public", v1.PropStatusNotReviewed, v1.PropStatusPublic,
+			v1.PropVoteStatusStarted, nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = nil
+			propStatus = test.propStatus
+			voteStatus = test.voteStatus
+			err := c.CheckProposalStatusChange(&v1.SetProposalStatus{
+				Token:          token,
+				ProposalStatus: test.status,
+			})
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !test.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if strings.Join(requests, ",") !=
+				strings.Join(test.wantRequests, ",") {
+				t.Fatalf("got requests %v, want %v", requests,
+					test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestCheckRFPLink(t *testing.T) {
+	now := time.Now()
+	rfp := func(status v1.PropStatusT, linkBy int64) v1.ProposalRecord {
+		return v1.ProposalRecord{
+			Status: status,
+			LinkBy: linkBy,
+		}
+	}
+
+	var tests = []struct {
+		name    string
+		rfp     v1.ProposalRecord
+		wantErr string
+	}{
+		{"open rfp", rfp(v1.PropStatusPublic, now.Unix()+60), ""},
+		{"not an rfp", rfp(v1.PropStatusPublic, 0), "is not an rfp"},
+		{"unvetted rfp", rfp(v1.PropStatusNotReviewed, now.Unix()+60),
+			"is not public"},
+		{"submissions closed", rfp(v1.PropStatusPublic, now.Unix()),
+			"submission period closed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkRFPLink(test.rfp, now)
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			case test.wantErr != "" && err == nil:
+				t.Fatalf("got nil error, want %q", test.wantErr)
+			case test.wantErr != "" &&
+				!strings.Contains(err.Error(), test.wantErr):
+				t.Fatalf("got error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
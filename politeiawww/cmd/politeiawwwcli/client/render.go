@@ -0,0 +1,43 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/microcosm-cc/bluemonday"
+	blackfriday "github.com/russross/blackfriday/v2"
+)
+
+// markdownPolicy is the sanitization policy that is applied to rendered
+// proposal markdown.  It allows the formatting elements that are produced by
+// markdown and strips scripts, styles, event handlers and links with unsafe
+// URL schemes.
+var markdownPolicy = bluemonday.UGCPolicy()
+
+// RenderMarkdown converts the passed in markdown into sanitized HTML.  The
+// markdown is rendered with the common extensions, such as tables and fenced
+// code blocks, and any raw HTML that it contains is sanitized.
+func RenderMarkdown(md []byte) string {
+	unsafe := blackfriday.Run(md)
+	return string(markdownPolicy.SanitizeBytes(unsafe))
+}
+
+// RenderProposalHTML fetches the latest version of a proposal and returns its
+// index.md rendered as sanitized HTML.  The index.md payload is verified
+// against its file digest before it is rendered.
+func (c *Client) RenderProposalHTML(token string) (string, error) {
+	pdr, err := c.ProposalDetails(token, nil)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := proposalFilePayload(pdr.Proposal.Files, indexFile)
+	if err != nil {
+		return "", fmt.Errorf("proposal %v: %v", token, err)
+	}
+
+	return RenderMarkdown(b), nil
+}
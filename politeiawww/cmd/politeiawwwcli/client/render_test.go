@@ -0,0 +1,78 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	var tests = []struct {
+		name    string
+		md      string
+		want    []string // Substrings expected in the HTML
+		notWant []string // Substrings that must not be in the HTML
+	}{
+		{"formatting", "# Title\n\nSome **bold** text",
+			[]string{"<h1>Title</h1>", "<strong>bold</strong>"}, nil},
+		{"table", "a | b\n--- | ---\n1 | 2\n",
+			[]string{"<table>", "<td>1</td>"}, nil},
+		{"script", "text\n\n<script>alert(1)</script>\n",
+			[]string{"text"}, []string{"<script", "alert(1)"}},
+		{"event handler", `<img src="a.png" onerror="alert(1)">`,
+			nil, []string{"onerror", "alert(1)"}},
+		{"javascript link", "[click](javascript:alert(1))",
+			[]string{"click"}, []string{"javascript:"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RenderMarkdown([]byte(test.md))
+			for _, v := range test.want {
+				if !strings.Contains(got, v) {
+					t.Errorf("html does not contain %q:\n%v", v, got)
+				}
+			}
+			for _, v := range test.notWant {
+				if strings.Contains(got, v) {
+					t.Errorf("html contains %q:\n%v", v, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderProposalHTML(t *testing.T) {
+	const textMIME = "text/plain; charset=utf-8"
+	index := newFile("index.md", textMIME, "# Title\n<script>alert(1)</script>")
+
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.ProposalDetailsReply{
+				Proposal: v1.ProposalRecord{
+					Files: []v1.File{index},
+				},
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	got, err := c.RenderProposalHTML("token")
+	if err != nil {
+		t.Fatalf("RenderProposalHTML: %v", err)
+	}
+	if !strings.Contains(got, "<h1>Title</h1>") {
+		t.Fatalf("html does not contain the title:\n%v", got)
+	}
+	if strings.Contains(got, "<script") {
+		t.Fatalf("html contains a script:\n%v", got)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// RescanStatus retrieves the progress of the user payments rescan with the
+// passed in job ID.
+func (c *Client) RescanStatus(jobID string) (*v1.UserPaymentsRescanStatusReply, error) {
+	responseBody, err := c.makeRequest("GET",
+		"/user/payments/rescan/"+jobID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var uprsr v1.UserPaymentsRescanStatusReply
+	err = json.Unmarshal(responseBody, &uprsr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal UserPaymentsRescanStatusReply: %v",
+			err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(uprsr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &uprsr, nil
+}
+
+// WaitForRescan polls the status of the user payments rescan with the passed
+// in job ID every pollInterval until the rescan has ended or the context is
+// cancelled.  PaywallPollInterval is used if pollInterval is not positive.
+// The last status that was received is returned along with the context error
+// if the context is cancelled.  A rescan that failed is not an error; its
+// status contains the reason.
+//
+// The progress of the rescan is reported each time it changes.
+func (c *Client) WaitForRescan(ctx context.Context, jobID string, pollInterval time.Duration) (*v1.UserPaymentsRescanStatusReply, error) {
+	if pollInterval <= 0 {
+		pollInterval = c.cfg.PaywallPollInterval
+	}
+
+	s, err := c.RescanStatus(jobID)
+	if err != nil {
+		return nil, err
+	}
+	c.reportRescan(nil, s)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for s.Status == v1.RescanStatusRunning {
+		select {
+		case <-ctx.Done():
+			return s, ctx.Err()
+		case <-ticker.C:
+		}
+
+		r, err := c.RescanStatus(jobID)
+		if err != nil {
+			return s, err
+		}
+		c.reportRescan(s, r)
+		s = r
+	}
+
+	return s, nil
+}
+
+// reportRescan prints the progress of a running rescan when it differs from
+// the previous progress.
+func (c *Client) reportRescan(prev, cur *v1.UserPaymentsRescanStatusReply) {
+	if c.cfg.Silent || c.cfg.JSONOnly ||
+		cur.Status != v1.RescanStatusRunning {
+		return
+	}
+	if prev != nil && prev.AddressesScanned == cur.AddressesScanned &&
+		prev.PaymentsFound == cur.PaymentsFound {
+		return
+	}
+	fmt.Printf("Scanned %v of %v addresses; %v payments found\n",
+		cur.AddressesScanned, cur.AddressesTotal, cur.PaymentsFound)
+}
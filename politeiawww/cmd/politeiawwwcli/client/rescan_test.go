@@ -0,0 +1,75 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestWaitForRescan(t *testing.T) {
+	const jobID = "0123456789abcdef0123456789abcdef"
+	running := func(scanned, found uint64) v1.UserPaymentsRescanStatusReply {
+		return v1.UserPaymentsRescanStatusReply{
+			JobID:            jobID,
+			Status:           v1.RescanStatusRunning,
+			AddressesTotal:   2,
+			AddressesScanned: scanned,
+			PaymentsFound:    found,
+		}
+	}
+	complete := v1.UserPaymentsRescanStatusReply{
+		JobID:            jobID,
+		Status:           v1.RescanStatusComplete,
+		AddressesTotal:   2,
+		AddressesScanned: 2,
+		PaymentsFound:    3,
+		NewCredits:       []v1.ProposalCredit{{TxID: "txid"}},
+	}
+	replies := []v1.UserPaymentsRescanStatusReply{running(0, 0),
+		running(1, 2), running(1, 2), complete}
+
+	var (
+		mtx sync.Mutex
+		i   int
+	)
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+				"/user/payments/rescan/"+jobID {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			mtx.Lock()
+			reply := replies[i]
+			if i < len(replies)-1 {
+				i++
+			}
+			mtx.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(reply)
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.Silent = true
+
+	got, err := c.WaitForRescan(context.Background(), jobID, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForRescan: %v", err)
+	}
+	if got.Status != v1.RescanStatusComplete ||
+		len(got.NewCredits) != len(complete.NewCredits) {
+		t.Errorf("got %+v, want %+v", *got, complete)
+	}
+}
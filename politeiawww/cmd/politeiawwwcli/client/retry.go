@@ -0,0 +1,59 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryAfter returns the amount of time to wait before retrying a request as
+// specified by the passed in Retry-After header.  The header value may be
+// either a number of seconds or an HTTP-date.  A date in the past results in
+// a zero wait.  False is returned if the header is missing or malformed.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+
+	// Delay in seconds
+	secs, err := strconv.ParseUint(v, 10, 32)
+	if err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	// HTTP-date
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// shouldRetry returns the amount of time to wait before retrying the request
+// that resulted in the passed in response.  Only rate limited requests that
+// include a Retry-After header are retried and only when retries are enabled.
+// False is returned if the request should not be retried, if the request has
+// already been retried the max number of times, or if the wait would push the
+// total time spent waiting, including previous retries, past the max retry
+// wait.
+func (c *Client) shouldRetry(r *http.Response, retries int, waited time.Duration) (time.Duration, bool) {
+	if !c.cfg.Retry || r.StatusCode != http.StatusTooManyRequests ||
+		retries >= c.cfg.MaxRetries {
+		return 0, false
+	}
+	d, ok := retryAfter(r.Header, time.Now())
+	if !ok || waited+d > c.cfg.MaxRetryWait {
+		return 0, false
+	}
+	return d, true
+}
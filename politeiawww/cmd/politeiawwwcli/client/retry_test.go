@@ -0,0 +1,129 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		name   string
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"missing", "", 0, false},
+		{"seconds", "5", 5 * time.Second, true},
+		{"zero seconds", "0", 0, true},
+		{"negative seconds", "-1", 0, false},
+		{"http date", now.Add(10 * time.Second).Format(http.TimeFormat),
+			10 * time.Second, true},
+		{"http date in the past", now.Add(-time.Minute).Format(http.TimeFormat),
+			0, true},
+		{"malformed", "soon", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := make(http.Header)
+			if test.header != "" {
+				h.Set("Retry-After", test.header)
+			}
+			got, ok := retryAfter(h, now)
+			if ok != test.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, test.wantOK)
+			}
+			if got != test.want {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// newRateLimitedServer returns a test server that responds to the first
+// limited requests with a 429 and the passed in Retry-After header and to all
+// requests after that with a policy reply.  A pointer to the number of
+// requests that the server has received is also returned.
+func newRateLimitedServer(limited int, retryAfter string) (*httptest.Server, *int64) {
+	var requests int64
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt64(&requests, 1)
+			if n <= int64(limited) {
+				w.Header().Set("Retry-After", retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.PolicyReply{
+				MinPasswordLength: 8,
+			})
+		}))
+	return s, &requests
+}
+
+func TestMakeRequestRetry(t *testing.T) {
+	var tests = []struct {
+		name         string
+		limited      int
+		retryAfter   string
+		retry        bool
+		maxRetryWait time.Duration
+		maxRetries   int
+		wantErr      bool
+		wantRequests int
+	}{
+		{"retry succeeds", 1, "0", true, time.Minute, 5, false, 2},
+		{"multiple retries", 3, "0", true, time.Minute, 5, false, 4},
+		{"retries disabled", 1, "0", false, time.Minute, 5, true, 1},
+		{"max wait exceeded", 1, "120", true, time.Minute, 5, true, 1},
+		{"no retry after", 1, "", true, time.Minute, 5, true, 1},
+		{"max retries exceeded", 100, "0", true, time.Minute, 3, true, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, requests := newRateLimitedServer(test.limited, test.retryAfter)
+			defer s.Close()
+
+			c := newTestClient(t, s, true)
+			c.cfg.Retry = test.retry
+			c.cfg.MaxRetryWait = test.maxRetryWait
+			c.cfg.MaxRetries = test.maxRetries
+
+			pr, err := c.Policy()
+			n := atomic.LoadInt64(requests)
+			if n != int64(test.wantRequests) {
+				t.Fatalf("got %v requests, want %v", n, test.wantRequests)
+			}
+			if test.wantErr {
+				apiErr, ok := err.(*APIError)
+				if !ok {
+					t.Fatalf("got error %v, want *APIError", err)
+				}
+				if apiErr.HTTPCode != http.StatusTooManyRequests {
+					t.Fatalf("got status %v, want %v", apiErr.HTTPCode,
+						http.StatusTooManyRequests)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if pr.MinPasswordLength != 8 {
+				t.Fatalf("got MinPasswordLength %v, want 8",
+					pr.MinPasswordLength)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// Match scores used to rank proposal search results.  A higher score is a
+// better match.
+const (
+	scoreNone      = 0
+	scoreFuzzy     = 1 // Query characters appear in order
+	scoreSubstring = 2 // Query is a substring
+	scorePrefix    = 3 // Query is a prefix
+	scoreExact     = 4 // Query is an exact match
+)
+
+// SearchOpts contains the options for a proposal search.
+type SearchOpts struct {
+	TitleOnly  bool // Only match against the proposal title
+	AuthorOnly bool // Only match against the author username
+	NoFuzzy    bool // Only return substring matches
+	Limit      int  // Maximum number of results; 0 means no limit
+}
+
+// SearchResult is a proposal that matched a search query along with the
+// score that was used to rank it.
+type SearchResult struct {
+	Proposal v1.ProposalRecord `json:"proposal"`
+	Score    int               `json:"score"`
+}
+
+// SearchProposals searches the vetted proposals for the given query.  The
+// query is matched, case insensitively, against the proposal title and the
+// author username.  The results are ranked from best to worst match.
+//
+// politeiawww does not provide a search route so the search is performed
+// client side by fetching all vetted proposals and filtering them locally.
+// This requires fetching every page of vetted proposals on each search and
+// unvetted proposals are not searched.
+func (c *Client) SearchProposals(query string, opts SearchOpts) ([]SearchResult, error) {
+	props, err := c.allVettedProposals()
+	if err != nil {
+		return nil, err
+	}
+
+	return searchProposals(props, query, opts), nil
+}
+
+// allVettedProposals fetches all pages of vetted proposals.
+func (c *Client) allVettedProposals() ([]v1.ProposalRecord, error) {
+	pageSize, err := c.VettedPageSize()
+	if err != nil {
+		return nil, err
+	}
+
+	props := make([]v1.ProposalRecord, 0)
+	var after string
+	for {
+		gavr, err := c.GetAllVetted(&v1.GetAllVetted{
+			After: after,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		props = append(props, gavr.Proposals...)
+
+		// Guard against a server that ignores the after param
+		page := proposalsPage(gavr.Proposals, pageSize)
+		if !page.HasMore || page.After == after {
+			break
+		}
+		after = page.After
+	}
+
+	return props, nil
+}
+
+// searchProposals returns the proposals that match the given query, ranked
+// from best to worst match.  Proposals with the same score are ordered by
+// timestamp, newest first.
+func searchProposals(props []v1.ProposalRecord, query string, opts SearchOpts) []SearchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := make([]SearchResult, 0)
+	if query == "" {
+		return results
+	}
+
+	for _, v := range props {
+		var score int
+		if !opts.AuthorOnly {
+			score = matchScore(v.Name, query, !opts.NoFuzzy)
+		}
+		if !opts.TitleOnly {
+			s := matchScore(v.Username, query, !opts.NoFuzzy)
+			if s > score {
+				score = s
+			}
+		}
+		if score == scoreNone {
+			continue
+		}
+
+		results = append(results, SearchResult{
+			Proposal: v,
+			Score:    score,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Proposal.Timestamp > results[j].Proposal.Timestamp
+	})
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	return results
+}
+
+// matchScore returns the score of the query matched against s.  The query
+// must already be lower case.
+func matchScore(s, query string, fuzzy bool) int {
+	s = strings.ToLower(s)
+	switch {
+	case s == query:
+		return scoreExact
+	case strings.HasPrefix(s, query):
+		return scorePrefix
+	case strings.Contains(s, query):
+		return scoreSubstring
+	case fuzzy && isSubsequence(s, query):
+		return scoreFuzzy
+	}
+	return scoreNone
+}
+
+// isSubsequence returns whether all runes of sub appear in s in the same
+// order, not necessarily consecutively.
+func isSubsequence(s, sub string) bool {
+	r := []rune(sub)
+	if len(r) == 0 {
+		return true
+	}
+	var i int
+	for _, c := range s {
+		if c == r[i] {
+			i++
+			if i == len(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestSearchProposals(t *testing.T) {
+	props := []v1.ProposalRecord{
+		{Name: "Decred Marketing", Username: "alice", Timestamp: 1},
+		{Name: "Marketing for DCR", Username: "bob", Timestamp: 2},
+		{Name: "marketing", Username: "carol", Timestamp: 3},
+		{Name: "Dev work", Username: "marketer", Timestamp: 4},
+		{Name: "Mobile app", Username: "dave", Timestamp: 5},
+	}
+
+	var tests = []struct {
+		name  string
+		query string
+		opts  SearchOpts
+		want  []string // Expected proposal names in order
+	}{
+		{"empty query", " ", SearchOpts{}, []string{}},
+
+		{"ranked", "Marketing", SearchOpts{},
+			[]string{"marketing", "Marketing for DCR", "Decred Marketing"}},
+
+		{"author", "market", SearchOpts{AuthorOnly: true},
+			[]string{"Dev work"}},
+
+		{"title and author", "market", SearchOpts{},
+			[]string{"Dev work", "marketing", "Marketing for DCR",
+				"Decred Marketing"}},
+
+		{"fuzzy", "mbl", SearchOpts{}, []string{"Mobile app"}},
+
+		{"no fuzzy", "mbl", SearchOpts{NoFuzzy: true}, []string{}},
+
+		{"limit", "marketing", SearchOpts{TitleOnly: true, Limit: 1},
+			[]string{"marketing"}},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			results := searchProposals(props, v.query, v.opts)
+			if len(results) != len(v.want) {
+				t.Fatalf("got %v results, want %v",
+					len(results), len(v.want))
+			}
+			for i, r := range results {
+				if r.Proposal.Name != v.want[i] {
+					t.Errorf("result %v got %v, want %v",
+						i, r.Proposal.Name, v.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// debugLogTrace is the debug log entry type of a request trace.
+const debugLogTrace = "trace"
+
+// RequestTrace contains the connection level timings of a single request.
+// The timings are used to distinguish network latency from server latency.
+// Timings of steps that did not occur, such as the DNS lookup and TLS
+// handshake when an idle connection is reused, are zero.  The durations are
+// encoded in nanoseconds.
+type RequestTrace struct {
+	Method       string        `json:"method"`       // Request method
+	URL          string        `json:"url"`          // Request URL
+	ConnReused   bool          `json:"connreused"`   // Idle connection was reused
+	DNS          time.Duration `json:"dns"`          // DNS lookup
+	Connect      time.Duration `json:"connect"`      // TCP connect
+	TLSHandshake time.Duration `json:"tlshandshake"` // TLS handshake
+	FirstByte    time.Duration `json:"firstbyte"`    // Start of request until first response byte
+	Total        time.Duration `json:"total"`        // Start of request until response body read
+}
+
+// requestTracer records the connection level timings of a request using the
+// httptrace hooks.  The hooks may be called from multiple goroutines so all
+// fields are protected by the mutex.
+type requestTracer struct {
+	sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	trace        RequestTrace
+}
+
+// newRequestTracer returns a requestTracer for the passed in request and the
+// request with the client trace attached.
+func newRequestTracer(req *http.Request) (*requestTracer, *http.Request) {
+	rt := &requestTracer{
+		start: time.Now(),
+		trace: RequestTrace{
+			Method: req.Method,
+			URL:    req.URL.String(),
+		},
+	}
+
+	ct := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			rt.Lock()
+			rt.dnsStart = time.Now()
+			rt.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			rt.Lock()
+			rt.trace.DNS = time.Since(rt.dnsStart)
+			rt.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			rt.Lock()
+			if rt.connectStart.IsZero() {
+				rt.connectStart = time.Now()
+			}
+			rt.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			rt.Lock()
+			if err == nil {
+				rt.trace.Connect = time.Since(rt.connectStart)
+			}
+			rt.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			rt.Lock()
+			rt.tlsStart = time.Now()
+			rt.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			rt.Lock()
+			rt.trace.TLSHandshake = time.Since(rt.tlsStart)
+			rt.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			rt.Lock()
+			rt.trace.ConnReused = info.Reused
+			rt.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			rt.Lock()
+			rt.trace.FirstByte = time.Since(rt.start)
+			rt.Unlock()
+		},
+	}
+
+	ctx := httptrace.WithClientTrace(req.Context(), ct)
+	return rt, req.WithContext(ctx)
+}
+
+// finish completes the trace and returns it.  It must be called once the
+// response body has been read.
+func (rt *requestTracer) finish() RequestTrace {
+	rt.Lock()
+	defer rt.Unlock()
+
+	rt.trace.Total = time.Since(rt.start)
+	return rt.trace
+}
+
+// LastTrace returns the connection level timings of the most recent request
+// that was made using makeRequest.  Nil is returned if tracing is not enabled.
+func (c *Client) LastTrace() *RequestTrace {
+	c.traceMtx.Lock()
+	defer c.traceMtx.Unlock()
+
+	return c.lastTrace
+}
+
+// finishTrace completes the passed in trace and saves it as the last trace.
+// The trace is printed in verbose mode and is appended to the debug log when
+// the debug log is enabled.
+func (c *Client) finishTrace(rt *requestTracer) error {
+	trace := rt.finish()
+	c.traceMtx.Lock()
+	c.lastTrace = &trace
+	c.traceMtx.Unlock()
+
+	if c.cfg.Verbose {
+		fmt.Printf("Trace: dns %v, connect %v, tls %v, first byte %v, "+
+			"total %v, reused %v\n", trace.DNS, trace.Connect,
+			trace.TLSHandshake, trace.FirstByte, trace.Total,
+			trace.ConnReused)
+	}
+
+	if c.cfg.DebugLog == "" {
+		return nil
+	}
+	return c.writeDebugLog(debugLogEntry{
+		Timestamp: time.Now().Unix(),
+		Type:      debugLogTrace,
+		Method:    trace.Method,
+		URL:       trace.URL,
+		Body:      trace,
+	})
+}
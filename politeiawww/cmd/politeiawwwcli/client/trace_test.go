@@ -0,0 +1,63 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestRequestTrace(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	// Tracing is disabled by default
+	_, err := c.makeRequest("GET", v1.RoutePolicy, nil)
+	if err != nil {
+		t.Fatalf("makeRequest: %v", err)
+	}
+	if c.LastTrace() != nil {
+		t.Fatalf("got trace %v, want nil", c.LastTrace())
+	}
+
+	c.cfg.Trace = true
+
+	// The first traced request must open a new connection since the
+	// idle connections are closed.
+	c.http.Transport.(interface{ CloseIdleConnections() }).CloseIdleConnections()
+	_, err = c.makeRequest("GET", v1.RoutePolicy, nil)
+	if err != nil {
+		t.Fatalf("makeRequest: %v", err)
+	}
+	trace := c.LastTrace()
+	switch {
+	case trace == nil:
+		t.Fatalf("got nil trace")
+	case trace.Method != "GET":
+		t.Errorf("got method %v, want GET", trace.Method)
+	case trace.ConnReused:
+		t.Errorf("got reused connection, want new connection")
+	case trace.Connect <= 0 || trace.TLSHandshake <= 0:
+		t.Errorf("missing connection timings: %+v", trace)
+	case trace.FirstByte <= 0 || trace.Total < trace.FirstByte:
+		t.Errorf("invalid response timings: %+v", trace)
+	}
+
+	// The second request reuses the idle connection
+	_, err = c.makeRequest("GET", v1.RoutePolicy, nil)
+	if err != nil {
+		t.Fatalf("makeRequest: %v", err)
+	}
+	trace = c.LastTrace()
+	if !trace.ConnReused {
+		t.Errorf("got new connection, want reused connection")
+	}
+	if trace.TLSHandshake != 0 {
+		t.Errorf("got tls handshake %v on reused connection",
+			trace.TLSHandshake)
+	}
+}
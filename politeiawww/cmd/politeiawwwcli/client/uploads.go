@@ -0,0 +1,236 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// uploadMaxAttempts is the number of consecutive times that uploading a chunk
+// is attempted before a chunked upload is given up on.
+const uploadMaxAttempts = 5
+
+// uploadRetryDelay is the amount of time to wait before the first retry of a
+// chunk.  The delay grows with every consecutive failed attempt.
+var uploadRetryDelay = time.Second
+
+// NewUpload stages a proposal file that is uploaded in chunks.
+func (c *Client) NewUpload(nu *v1.NewUpload) (*v1.NewUploadReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteNewUpload, nu)
+	if err != nil {
+		return nil, err
+	}
+
+	var nur v1.NewUploadReply
+	err = json.Unmarshal(responseBody, &nur)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal NewUploadReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(nur)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &nur, nil
+}
+
+// UploadChunk uploads a chunk of a staged upload.
+func (c *Client) UploadChunk(uc *v1.UploadChunk) (*v1.UploadChunkReply, error) {
+	responseBody, err := c.makeRequest("POST", v1.RouteUploadChunk, uc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ucr v1.UploadChunkReply
+	err = json.Unmarshal(responseBody, &ucr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal UploadChunkReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(ucr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ucr, nil
+}
+
+// UploadStatus retrieves the progress of a staged upload of the logged in
+// user.
+func (c *Client) UploadStatus(uploadID string) (*v1.UploadStatusReply, error) {
+	responseBody, err := c.makeRequest("GET", "/uploads/"+uploadID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var usr v1.UploadStatusReply
+	err = json.Unmarshal(responseBody, &usr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal UploadStatusReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(usr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &usr, nil
+}
+
+// isRetryableUploadError returns whether uploading a chunk should be retried
+// after the passed in error.  Connection errors, server errors and chunks that
+// were sent at the wrong offset are retried; all other errors returned by the
+// server are not.
+func isRetryableUploadError(err error) bool {
+	e, ok := err.(*APIError)
+	if !ok {
+		return true
+	}
+	return e.HTTPCode >= http.StatusInternalServerError ||
+		e.ErrorCode == v1.ErrorStatusInvalidUploadOffset
+}
+
+// ResumeUpload uploads the chunks of the passed in file content that the
+// server has not received yet.  When uploading a chunk fails because of a
+// lost connection, the progress is fetched from the server and the upload is
+// resumed from there.
+func (c *Client) ResumeUpload(uploadID string, data []byte) error {
+	var (
+		offset   int64
+		attempts int
+		err      error
+	)
+	for {
+		if attempts > 0 {
+			if attempts >= uploadMaxAttempts {
+				return fmt.Errorf("upload %v: giving up after %v "+
+					"attempts: %v", uploadID, attempts, err)
+			}
+			time.Sleep(time.Duration(attempts) * uploadRetryDelay)
+		}
+
+		// Fetch the progress of the upload
+		var usr *v1.UploadStatusReply
+		usr, err = c.UploadStatus(uploadID)
+		if err != nil {
+			if !isRetryableUploadError(err) {
+				return err
+			}
+			attempts++
+			continue
+		}
+		if usr.Complete {
+			return nil
+		}
+		offset = usr.Received
+
+		// Upload the remaining chunks
+		for offset < int64(len(data)) {
+			end := offset + v1.UploadChunkSize
+			if end > int64(len(data)) {
+				end = int64(len(data))
+			}
+			var ucr *v1.UploadChunkReply
+			ucr, err = c.UploadChunk(&v1.UploadChunk{
+				UploadID: uploadID,
+				Offset:   offset,
+				Payload:  base64.StdEncoding.EncodeToString(data[offset:end]),
+			})
+			if err != nil {
+				break
+			}
+			attempts = 0
+			offset = ucr.Received
+			if ucr.Complete {
+				return nil
+			}
+		}
+		if err == nil {
+			return fmt.Errorf("upload %v: all %v bytes were sent but the "+
+				"upload is not complete", uploadID, len(data))
+		}
+		if !isRetryableUploadError(err) {
+			return err
+		}
+		attempts++
+	}
+}
+
+// UploadFile uploads the content of the passed in proposal file in chunks.
+// The ID of the completed upload is returned.
+func (c *Client) UploadFile(f v1.File) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(f.Payload)
+	if err != nil {
+		return "", ValidationError{
+			Field:  "payload of file " + f.Name,
+			Reason: fmt.Sprintf("not base64: %v", err),
+		}
+	}
+
+	nur, err := c.NewUpload(&v1.NewUpload{
+		Name:   f.Name,
+		MIME:   f.MIME,
+		Digest: f.Digest,
+		Size:   int64(len(data)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = c.ResumeUpload(nur.UploadID, data)
+	if err != nil {
+		return "", err
+	}
+	return nur.UploadID, nil
+}
+
+// stageProposalFiles uploads the proposal files that are larger than the
+// configured chunked upload size in chunks and replaces their payloads with
+// the IDs of the uploads.  The files are returned unchanged when chunked
+// uploads are disabled or when the server does not support them.
+func (c *Client) stageProposalFiles(files []v1.File) ([]v1.File, error) {
+	if c.cfg.ChunkedUploadSize <= 0 {
+		return files, nil
+	}
+
+	staged := make([]v1.File, 0, len(files))
+	for _, f := range files {
+		size := base64.StdEncoding.DecodedLen(len(f.Payload))
+		if size <= c.cfg.ChunkedUploadSize {
+			staged = append(staged, f)
+			continue
+		}
+
+		id, err := c.UploadFile(f)
+		if e, ok := err.(*APIError); ok && e.HTTPCode == http.StatusNotFound &&
+			e.ErrorCode == 0 {
+			// The server does not support chunked uploads
+			return files, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("upload %v: %v", f.Name, err)
+		}
+		staged = append(staged, v1.File{
+			Name:     f.Name,
+			MIME:     f.MIME,
+			Digest:   f.Digest,
+			UploadID: id,
+		})
+	}
+
+	return staged, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// testUploadServer is a politeiawww server that supports chunked uploads of a
+// single file.  The reply to the chunk that crosses dropAt is replaced by a
+// server error after the chunk has been stored, as if the connection was lost
+// before the reply was received.
+type testUploadServer struct {
+	sync.Mutex
+	dropAt    int
+	dropped   bool
+	supported bool
+	size      int64
+	data      bytes.Buffer
+	proposal  *v1.NewProposal
+}
+
+func (s *testUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	route := v1.PoliteiaWWWAPIRoute
+	switch {
+	case !s.supported && r.URL.Path != route+v1.RouteNewProposal:
+		http.NotFound(w, r)
+	case r.URL.Path == route+v1.RouteNewUpload:
+		var nu v1.NewUpload
+		json.NewDecoder(r.Body).Decode(&nu)
+		s.size = nu.Size
+		json.NewEncoder(w).Encode(v1.NewUploadReply{
+			UploadID:  "abc",
+			ChunkSize: v1.UploadChunkSize,
+		})
+	case r.URL.Path == route+v1.RouteUploadChunk:
+		var uc v1.UploadChunk
+		json.NewDecoder(r.Body).Decode(&uc)
+		if uc.Offset != int64(s.data.Len()) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidUploadOffset,
+			})
+			return
+		}
+		b, _ := base64.StdEncoding.DecodeString(uc.Payload)
+		s.data.Write(b)
+		if !s.dropped && s.data.Len() > s.dropAt {
+			s.dropped = true
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(v1.UploadChunkReply{
+			Received: int64(s.data.Len()),
+			Complete: int64(s.data.Len()) == s.size,
+		})
+	case r.URL.Path == route+"/uploads/abc":
+		json.NewEncoder(w).Encode(v1.UploadStatusReply{
+			UploadID: "abc",
+			Size:     s.size,
+			Received: int64(s.data.Len()),
+			Complete: int64(s.data.Len()) == s.size,
+		})
+	case r.URL.Path == route+v1.RouteNewProposal:
+		var np v1.NewProposal
+		json.NewDecoder(r.Body).Decode(&np)
+		s.proposal = &np
+		json.NewEncoder(w).Encode(v1.NewProposalReply{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newTestUploadProposal returns a signed proposal with an index file of the
+// passed in size along with the content of the index file.
+func newTestUploadProposal(t *testing.T, size int) (*v1.NewProposal, []byte) {
+	t.Helper()
+
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := append([]byte("# Title\n"), bytes.Repeat([]byte("a"), size)...)
+	files := []v1.File{{
+		Name:    indexFile,
+		MIME:    "text/plain; charset=utf-8",
+		Digest:  hex.EncodeToString(util.Digest(b)),
+		Payload: base64.StdEncoding.EncodeToString(b),
+	}}
+	sig, err := signedMerkleRoot(files, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &v1.NewProposal{
+		Files:     files,
+		PublicKey: hex.EncodeToString(id.Public.Key[:]),
+		Signature: sig,
+	}, b
+}
+
+func TestNewProposalChunkedUpload(t *testing.T) {
+	delay := uploadRetryDelay
+	uploadRetryDelay = 0
+	defer func() {
+		uploadRetryDelay = delay
+	}()
+
+	ts := &testUploadServer{
+		dropAt:    v1.UploadChunkSize,
+		supported: true,
+	}
+	s := httptest.NewTLSServer(ts)
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.ChunkedUploadSize = 1024
+
+	np, data := newTestUploadProposal(t, 3*v1.UploadChunkSize)
+	_, err := c.NewProposal(np)
+	if err != nil {
+		t.Fatalf("NewProposal: %v", err)
+	}
+
+	// The upload was resumed after the lost reply
+	if !ts.dropped {
+		t.Fatalf("no reply was dropped")
+	}
+	if !bytes.Equal(ts.data.Bytes(), data) {
+		t.Fatalf("got %v uploaded bytes, want %v", ts.data.Len(), len(data))
+	}
+
+	// The proposal references the upload instead of carrying the payload
+	f := ts.proposal.Files[0]
+	if f.UploadID != "abc" || f.Payload != "" || f.Digest != np.Files[0].Digest {
+		t.Fatalf("got file %v %q %v, want upload abc", f.UploadID,
+			f.Payload, f.Digest)
+	}
+
+	// The proposal of the caller is not modified
+	if np.Files[0].UploadID != "" || np.Files[0].Payload == "" {
+		t.Fatalf("proposal files were modified")
+	}
+}
+
+func TestNewProposalChunkedUploadUnsupported(t *testing.T) {
+	ts := &testUploadServer{}
+	s := httptest.NewTLSServer(ts)
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.ChunkedUploadSize = 1024
+
+	// Servers that do not support chunked uploads receive the payload
+	np, _ := newTestUploadProposal(t, 2048)
+	_, err := c.NewProposal(np)
+	if err != nil {
+		t.Fatalf("NewProposal: %v", err)
+	}
+	f := ts.proposal.Files[0]
+	if f.UploadID != "" || f.Payload != np.Files[0].Payload {
+		t.Fatalf("got upload %q, want the payload", f.UploadID)
+	}
+}
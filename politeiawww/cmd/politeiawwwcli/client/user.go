@@ -0,0 +1,192 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+var (
+	// ErrVerificationTokenNotReturned is returned when a user email is
+	// verified automatically but the server did not return the
+	// verification token.  politeiawww only returns the verification token
+	// when its email server is disabled, i.e. in development and testing.
+	ErrVerificationTokenNotReturned = errors.New("server did not return " +
+		"a verification token; the server must have email disabled")
+)
+
+// TestAutoVerify verifies the email address of a newly created user using the
+// verification token from the passed in NewUserReply.  The token is signed
+// with the passed in identity, which must be the identity the user was
+// created with.  This is meant for test harnesses that run against a
+// development server, which returns the verification token in the reply
+// instead of emailing it.  ErrVerificationTokenNotReturned is returned if the
+// reply does not contain a verification token.
+func (c *Client) TestAutoVerify(email string, id *identity.FullIdentity, nur *v1.NewUserReply) (*v1.VerifyNewUserReply, error) {
+	if nur == nil || nur.VerificationToken == "" {
+		return nil, ErrVerificationTokenNotReturned
+	}
+
+	sig := id.SignMessage([]byte(nur.VerificationToken))
+	return c.VerifyNewUser(&v1.VerifyNewUser{
+		Email:             email,
+		VerificationToken: nur.VerificationToken,
+		Signature:         hex.EncodeToString(sig[:]),
+	})
+}
+
+// SessionVerification is the result of VerifySession.  KeyMatches is only set
+// when the local identity has the same public key as the active identity of
+// the session user; Discrepancy describes why it does not otherwise.
+type SessionVerification struct {
+	User            *v1.LoginReply `json:"user"`                  // Session user
+	LocalPublicKey  string         `json:"localpublickey"`        // Public key of the local identity
+	ServerPublicKey string         `json:"serverpublickey"`       // Active public key of the session user
+	KeyMatches      bool           `json:"keymatches"`            // Local and active public keys match
+	Discrepancy     string         `json:"discrepancy,omitempty"` // Why the keys do not match
+}
+
+// verifySessionKey compares the active public key of the passed in session
+// user with the passed in local public key.
+func verifySessionKey(lr *v1.LoginReply, localKey string) *SessionVerification {
+	sv := SessionVerification{
+		User:            lr,
+		LocalPublicKey:  localKey,
+		ServerPublicKey: lr.PublicKey,
+	}
+	switch {
+	case localKey == "":
+		sv.Discrepancy = "no local identity found"
+	case lr.PublicKey == "":
+		sv.Discrepancy = "the user does not have an active identity"
+	case localKey != lr.PublicKey:
+		sv.Discrepancy = "the local identity is not the active identity " +
+			"of the user; the user key may have been updated from " +
+			"another client"
+	default:
+		sv.KeyMatches = true
+	}
+	return &sv
+}
+
+// VerifySession fetches the session user and verifies that the active public
+// key of the user matches the public key of the local identity.  Requests
+// that are signed with a local identity that does not match are rejected by
+// politeiawww, so the returned result can be used to detect a rotated key
+// before signing anything.  A mismatch is not an error; it is described in
+// the Discrepancy field of the result.
+func (c *Client) VerifySession() (*SessionVerification, error) {
+	lr, err := c.Me()
+	if err != nil {
+		return nil, err
+	}
+
+	var localKey string
+	if c.cfg.Identity != nil {
+		localKey = hex.EncodeToString(c.cfg.Identity.Public.Key[:])
+	}
+
+	return verifySessionKey(lr, localKey), nil
+}
+
+// BatchUserDetails retrieves the details of multiple users keyed by user id.
+// Users that are in the user details cache are not requested again and the
+// rest are fetched with as few requests as the server batch size allows.
+// politeiawww only returns the public fields of each user unless the logged
+// in user is an admin or is the requested user.  Users that do not exist are
+// not included in the returned map.
+func (c *Client) BatchUserDetails(userIDs []string) (map[string]v1.User, error) {
+	users := make(map[string]v1.User, len(userIDs))
+	seen := make(map[string]struct{}, len(userIDs))
+	missing := make([]string, 0, len(userIDs))
+	for _, v := range userIDs {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+
+		if udr, ok := c.users.get(v); ok {
+			users[v] = udr.User
+			continue
+		}
+		missing = append(missing, v)
+	}
+
+	for len(missing) > 0 {
+		n := len(missing)
+		if n > v1.UserDetailsBatchSize {
+			n = v1.UserDetailsBatchSize
+		}
+		bud := v1.BatchUserDetails{
+			UserIDs: missing[:n],
+		}
+		missing = missing[n:]
+
+		responseBody, err := c.makeRequest("POST",
+			v1.RouteBatchUserDetails, bud)
+		if err != nil {
+			return nil, err
+		}
+
+		var budr v1.BatchUserDetailsReply
+		err = json.Unmarshal(responseBody, &budr)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal BatchUserDetailsReply: %v",
+				err)
+		}
+
+		if c.cfg.Verbose {
+			err := c.prettyPrintJSON(budr)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for id, u := range budr.Users {
+			users[id] = u
+			c.users.put(id, v1.UserDetailsReply{User: u})
+		}
+	}
+
+	return users, nil
+}
+
+// VerifyResetToken checks that a reset password verification token is valid
+// without consuming it.  This allows the user to be told to request a new
+// token before they enter a new password.  An *APIError with the
+// ErrorStatusVerificationTokenInvalid or ErrorStatusVerificationTokenExpired
+// error code is returned when the token can not be used.
+func (c *Client) VerifyResetToken(email, token string) (*v1.VerifyResetPasswordReply, error) {
+	vrp := v1.VerifyResetPassword{
+		Email:             email,
+		VerificationToken: token,
+	}
+	responseBody, err := c.makeRequest("POST", v1.RouteVerifyResetPassword,
+		vrp)
+	if err != nil {
+		return nil, err
+	}
+
+	var vrpr v1.VerifyResetPasswordReply
+	err = json.Unmarshal(responseBody, &vrpr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal VerifyResetPasswordReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(vrpr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vrpr, nil
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/schema"
+)
+
+func TestTestAutoVerify(t *testing.T) {
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The test server verifies the token signature the same way that
+	// politeiawww does.
+	var verified v1.VerifyNewUser
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseForm()
+			if err == nil {
+				err = schema.NewDecoder().Decode(&verified, r.Form)
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			sig, err := util.ConvertSignature(verified.Signature)
+			if err != nil ||
+				!id.Public.VerifyMessage([]byte(verified.VerificationToken), sig) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusInvalidSignature,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(v1.VerifyNewUserReply{})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	t.Run("token not returned", func(t *testing.T) {
+		_, err := c.TestAutoVerify("user@example.com", id,
+			&v1.NewUserReply{})
+		if err != ErrVerificationTokenNotReturned {
+			t.Fatalf("got error %v, want %v", err,
+				ErrVerificationTokenNotReturned)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		_, err := c.TestAutoVerify("user@example.com", id,
+			&v1.NewUserReply{
+				VerificationToken: "token",
+			})
+		if err != nil {
+			t.Fatalf("got error %v, want nil", err)
+		}
+		if verified.Email != "user@example.com" ||
+			verified.VerificationToken != "token" {
+			t.Fatalf("unexpected verify request %+v", verified)
+		}
+	})
+}
+
+func TestVerifySession(t *testing.T) {
+	local, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	localKey := hex.EncodeToString(local.Public.Key[:])
+	rotatedKey := hex.EncodeToString(rotated.Public.Key[:])
+
+	var serverKey string
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+v1.RouteUserMe {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.LoginReply{
+				Username:  "user",
+				PublicKey: serverKey,
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	var tests = []struct {
+		name       string
+		local      *identity.FullIdentity
+		serverKey  string
+		keyMatches bool
+	}{
+		{"match", local, localKey, true},
+		{"rotated", local, rotatedKey, false},
+		{"no local identity", nil, localKey, false},
+		{"no active identity", local, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c.cfg.Identity = test.local
+			serverKey = test.serverKey
+
+			sv, err := c.VerifySession()
+			if err != nil {
+				t.Fatalf("VerifySession: %v", err)
+			}
+			if sv.KeyMatches != test.keyMatches {
+				t.Errorf("got key matches %v, want %v",
+					sv.KeyMatches, test.keyMatches)
+			}
+			if (sv.Discrepancy == "") != test.keyMatches {
+				t.Errorf("got discrepancy %q", sv.Discrepancy)
+			}
+			if sv.ServerPublicKey != test.serverKey ||
+				sv.User.Username != "user" {
+				t.Errorf("got %+v", sv)
+			}
+		})
+	}
+}
+
+func TestBatchUserDetails(t *testing.T) {
+	// The test server returns every requested user except for the user
+	// with the id "unknown".
+	var batches []int
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var bud v1.BatchUserDetails
+			json.NewDecoder(r.Body).Decode(&bud)
+			batches = append(batches, len(bud.UserIDs))
+
+			users := make(map[string]v1.User, len(bud.UserIDs))
+			for _, v := range bud.UserIDs {
+				if v == "unknown" {
+					continue
+				}
+				users[v] = v1.User{ID: v, Username: "user" + v}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.BatchUserDetailsReply{
+				Users: users,
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.users = newUserCache(100, time.Minute)
+
+	// User 0 is cached, user 1 is requested twice and the rest fill
+	// up more than one batch.
+	c.users.put("0", v1.UserDetailsReply{
+		User: v1.User{ID: "0", Username: "cached"},
+	})
+	ids := []string{"0", "1", "1", "unknown"}
+	for i := 2; i <= v1.UserDetailsBatchSize; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+
+	users, err := c.BatchUserDetails(ids)
+	if err != nil {
+		t.Fatalf("BatchUserDetails: %v", err)
+	}
+	if len(batches) != 2 || batches[0] != v1.UserDetailsBatchSize ||
+		batches[1] != 1 {
+		t.Fatalf("got batches %v, want [%v 1]", batches,
+			v1.UserDetailsBatchSize)
+	}
+	if len(users) != v1.UserDetailsBatchSize+1 {
+		t.Fatalf("got %v users, want %v", len(users),
+			v1.UserDetailsBatchSize+1)
+	}
+	if users["0"].Username != "cached" {
+		t.Errorf("got user 0 %v, want cached user", users["0"])
+	}
+	if _, ok := users["unknown"]; ok {
+		t.Errorf("unknown user was returned")
+	}
+
+	// The fetched users are cached
+	batches = nil
+	_, err = c.BatchUserDetails([]string{"1", "2"})
+	if err != nil {
+		t.Fatalf("BatchUserDetails: %v", err)
+	}
+	if len(batches) != 0 {
+		t.Errorf("got batches %v, want none", batches)
+	}
+}
+
+func TestVerifyResetToken(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != v1.PoliteiaWWWAPIRoute+
+				v1.RouteVerifyResetPassword {
+				http.NotFound(w, r)
+				return
+			}
+			var vrp v1.VerifyResetPassword
+			json.NewDecoder(r.Body).Decode(&vrp)
+
+			w.Header().Set("Content-Type", "application/json")
+			if vrp.Email != "user@example.com" ||
+				vrp.VerificationToken != "token" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusVerificationTokenExpired,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(v1.VerifyResetPasswordReply{})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	_, err := c.VerifyResetToken("user@example.com", "token")
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+
+	_, err = c.VerifyResetToken("user@example.com", "stale")
+	e, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("got error %T, want *APIError", err)
+	}
+	if e.ErrorCode != v1.ErrorStatusVerificationTokenExpired {
+		t.Errorf("got error code %v, want %v", e.ErrorCode,
+			v1.ErrorStatusVerificationTokenExpired)
+	}
+}
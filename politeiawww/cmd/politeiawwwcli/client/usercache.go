@@ -0,0 +1,127 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// userCacheEntry is a cached user details reply along with the time that it
+// expires.
+type userCacheEntry struct {
+	userID  string
+	udr     v1.UserDetailsReply
+	expires time.Time
+}
+
+// userCache is a least recently used cache of user details replies keyed by
+// user ID.  Entries expire once they are older than the TTL.  A cache with a
+// size of zero does not cache anything.
+type userCache struct {
+	sync.Mutex
+	size    int                      // Maximum number of entries
+	ttl     time.Duration            // Lifetime of an entry
+	lru     *list.List               // Entries; most recently used first
+	entries map[string]*list.Element // [userID]entry
+	now     func() time.Time         // Current time; replaced in tests
+}
+
+// newUserCache returns a user cache that holds up to size entries for the
+// duration of the passed in TTL.
+func newUserCache(size int, ttl time.Duration) *userCache {
+	return &userCache{
+		size:    size,
+		ttl:     ttl,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached user details of the passed in user.  Expired entries
+// are removed and reported as a miss.
+func (uc *userCache) get(userID string) (*v1.UserDetailsReply, bool) {
+	uc.Lock()
+	defer uc.Unlock()
+
+	e, ok := uc.entries[userID]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*userCacheEntry)
+	if !uc.now().Before(entry.expires) {
+		uc.remove(e)
+		return nil, false
+	}
+	uc.lru.MoveToFront(e)
+
+	udr := entry.udr
+	return &udr, true
+}
+
+// put caches the user details of the passed in user.  The least recently used
+// entry is evicted when the cache is full.
+func (uc *userCache) put(userID string, udr v1.UserDetailsReply) {
+	uc.Lock()
+	defer uc.Unlock()
+
+	if uc.size <= 0 {
+		return
+	}
+
+	expires := uc.now().Add(uc.ttl)
+	if e, ok := uc.entries[userID]; ok {
+		entry := e.Value.(*userCacheEntry)
+		entry.udr = udr
+		entry.expires = expires
+		uc.lru.MoveToFront(e)
+		return
+	}
+
+	if uc.lru.Len() >= uc.size {
+		uc.remove(uc.lru.Back())
+	}
+	uc.entries[userID] = uc.lru.PushFront(&userCacheEntry{
+		userID:  userID,
+		udr:     udr,
+		expires: expires,
+	})
+}
+
+// del removes the passed in user from the cache.
+func (uc *userCache) del(userID string) {
+	uc.Lock()
+	defer uc.Unlock()
+
+	if e, ok := uc.entries[userID]; ok {
+		uc.remove(e)
+	}
+}
+
+// clear removes all entries from the cache.
+func (uc *userCache) clear() {
+	uc.Lock()
+	defer uc.Unlock()
+
+	uc.lru.Init()
+	uc.entries = make(map[string]*list.Element)
+}
+
+// remove removes the passed in element from the cache.  It must be called
+// with the lock held.
+func (uc *userCache) remove(e *list.Element) {
+	uc.lru.Remove(e)
+	delete(uc.entries, e.Value.(*userCacheEntry).userID)
+}
+
+// ClearUserCache removes all cached user details so that subsequent calls to
+// UserDetails fetch them from politeiawww again.
+func (c *Client) ClearUserCache() {
+	c.users.clear()
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestUserDetailsCache(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		requests = make(map[string]int) // [userID]requests
+	)
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			userID := path.Base(r.URL.Path)
+			mtx.Lock()
+			requests[userID]++
+			mtx.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.UserDetailsReply{
+				User: v1.User{
+					ID:       userID,
+					Username: "user" + userID,
+				},
+			})
+		}))
+	defer s.Close()
+
+	c := newTestClient(t, s, true)
+	now := time.Now()
+	c.users = newUserCache(2, time.Minute)
+	c.users.now = func() time.Time { return now }
+
+	userDetails := func(userID string, wantRequests int) {
+		t.Helper()
+		udr, err := c.UserDetails(userID)
+		if err != nil {
+			t.Fatalf("UserDetails: %v", err)
+		}
+		if udr.User.ID != userID {
+			t.Fatalf("got user %v, want %v", udr.User.ID, userID)
+		}
+		mtx.Lock()
+		got := requests[userID]
+		mtx.Unlock()
+		if got != wantRequests {
+			t.Fatalf("user %v: got %v requests, want %v", userID, got,
+				wantRequests)
+		}
+	}
+
+	// Cache hit
+	userDetails("1", 1)
+	userDetails("1", 1)
+
+	// Modifying a reply does not modify the cached reply
+	udr, err := c.UserDetails("1")
+	if err != nil {
+		t.Fatalf("UserDetails: %v", err)
+	}
+	udr.User.Username = "modified"
+	udr, err = c.UserDetails("1")
+	if err != nil {
+		t.Fatalf("UserDetails: %v", err)
+	}
+	if udr.User.Username != "user1" {
+		t.Fatalf("cached reply was modified")
+	}
+
+	// The least recently used user is evicted when the cache is full
+	userDetails("2", 1)
+	userDetails("1", 1)
+	userDetails("3", 1)
+	userDetails("1", 1)
+	userDetails("2", 2)
+
+	// Expired entries are fetched again
+	now = now.Add(time.Minute)
+	userDetails("2", 3)
+
+	// Clearing the cache removes all entries
+	c.ClearUserCache()
+	userDetails("2", 4)
+
+	// Changing the API token clears the cache
+	c.WithAPIToken("abc")
+	userDetails("2", 5)
+	c.WithAPIToken("abc")
+	userDetails("2", 5)
+}
+
+func TestUserDetailsCacheDisabled(t *testing.T) {
+	var requests int
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.UserDetailsReply{})
+		}))
+	defer s.Close()
+
+	c := newTestClient(t, s, true)
+	for i := 0; i < 2; i++ {
+		_, err := c.UserDetails("1")
+		if err != nil {
+			t.Fatalf("UserDetails: %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Fatalf("got %v requests, want 2", requests)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// WaitForUserPayment polls the registration payment of the logged in user
+// every pollInterval until the payment has been confirmed or the context is
+// cancelled.  PaywallPollInterval is used if pollInterval is not positive.
+// The last reply that was received is returned along with the context error
+// if the context is cancelled.
+//
+// A payment that has been sent but does not have enough confirmations yet is
+// reported each time its number of confirmations changes.  A warning is
+// printed when politeiawww is not synced while waiting.
+func (c *Client) WaitForUserPayment(ctx context.Context, pollInterval time.Duration) (*v1.VerifyUserPaymentReply, error) {
+	if pollInterval <= 0 {
+		pollInterval = c.cfg.PaywallPollInterval
+	}
+
+	vupr, err := c.VerifyUserPayment()
+	if err != nil {
+		return nil, err
+	}
+	c.reportUserPayment(nil, vupr)
+
+	var bhr *v1.BlockHeightReply
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for !vupr.HasPaid {
+		// Confirmations only increase while politeiawww is synced
+		bhr, err = c.checkSync(bhr)
+		if err != nil {
+			return vupr, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return vupr, ctx.Err()
+		case <-ticker.C:
+		}
+
+		r, err := c.VerifyUserPayment()
+		if err != nil {
+			return vupr, err
+		}
+		c.reportUserPayment(vupr, r)
+		vupr = r
+	}
+
+	return vupr, nil
+}
+
+// reportUserPayment prints the status of a registration payment that has
+// been sent but is not confirmed yet when it differs from the previous status.
+func (c *Client) reportUserPayment(prev, cur *v1.VerifyUserPaymentReply) {
+	if c.cfg.Silent || c.cfg.JSONOnly || cur.HasPaid || cur.TxID == "" {
+		return
+	}
+	if prev != nil && prev.TxID == cur.TxID &&
+		prev.Confirmations == cur.Confirmations {
+		return
+	}
+	fmt.Printf("Payment %v has %v confirmations; waiting\n", cur.TxID,
+		cur.Confirmations)
+}
+
+// validateTxID returns an error if the passed in transaction ID is not a hex
+// encoded transaction hash.
+func validateTxID(txID string) error {
+	b, err := hex.DecodeString(txID)
+	if err != nil || len(b) != chainhash.HashSize {
+		return fmt.Errorf("invalid txid %q: must be %v hex characters",
+			txID, chainhash.MaxHashStringSize)
+	}
+	return nil
+}
+
+// VerifyUserPaymentTx verifies that the specified transaction pays the user
+// registration fee of the logged in user.  The reply contains a breakdown of
+// the transaction and, if the payment was not accepted, the reasons why.
+func (c *Client) VerifyUserPaymentTx(txID string) (*v1.VerifyUserPaymentTxReply, error) {
+	err := validateTxID(txID)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := c.makeRequest("GET", v1.RouteVerifyUserPaymentTx,
+		&v1.VerifyUserPaymentTx{
+			TxID: txID,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var vuptr v1.VerifyUserPaymentTxReply
+	err = json.Unmarshal(responseBody, &vuptr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal VerifyUserPaymentTxReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := c.prettyPrintJSON(vuptr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vuptr, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// newUserPaymentTestServer returns a TLS test server that replies to verify
+// user payment requests with the passed in replies, in order.  The last reply
+// is repeated once all replies have been sent.  Block height requests are
+// replied to with a synced best block.
+func newUserPaymentTestServer(replies []v1.VerifyUserPaymentReply) *httptest.Server {
+	var (
+		mtx sync.Mutex
+		i   int
+	)
+	return httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == v1.PoliteiaWWWAPIRoute+v1.RouteBlockHeight {
+				json.NewEncoder(w).Encode(v1.BlockHeightReply{
+					Synced: true,
+				})
+				return
+			}
+
+			mtx.Lock()
+			reply := replies[i]
+			if i < len(replies)-1 {
+				i++
+			}
+			mtx.Unlock()
+
+			json.NewEncoder(w).Encode(reply)
+		}))
+}
+
+func TestWaitForUserPayment(t *testing.T) {
+	unpaid := v1.VerifyUserPaymentReply{
+		PaywallAddress: "address",
+		PaywallAmount:  10000000,
+	}
+	pending := func(confirmations uint64) v1.VerifyUserPaymentReply {
+		r := unpaid
+		r.TxID = "txid"
+		r.Confirmations = confirmations
+		return r
+	}
+	paid := v1.VerifyUserPaymentReply{
+		HasPaid: true,
+	}
+
+	var tests = []struct {
+		name    string
+		replies []v1.VerifyUserPaymentReply
+	}{
+		{"already paid", []v1.VerifyUserPaymentReply{paid}},
+		{"unpaid", []v1.VerifyUserPaymentReply{unpaid, unpaid, paid}},
+		{"pending", []v1.VerifyUserPaymentReply{unpaid, pending(0),
+			pending(1), pending(1), paid}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newUserPaymentTestServer(test.replies)
+			defer s.Close()
+			c := newTestClient(t, s, true)
+			c.cfg.Silent = true
+
+			got, err := c.WaitForUserPayment(context.Background(),
+				time.Millisecond)
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if *got != paid {
+				t.Errorf("got %+v, want %+v", *got, paid)
+			}
+		})
+	}
+}
+
+func TestWaitForUserPaymentCancel(t *testing.T) {
+	s := newUserPaymentTestServer([]v1.VerifyUserPaymentReply{{
+		TxID:          "txid",
+		Confirmations: 1,
+	}})
+	defer s.Close()
+	c := newTestClient(t, s, true)
+	c.cfg.Silent = true
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		50*time.Millisecond)
+	defer cancel()
+
+	got, err := c.WaitForUserPayment(ctx, time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got == nil || got.Confirmations != 1 {
+		t.Errorf("got %+v, want last received reply", got)
+	}
+}
+
+func TestVerifyUserPaymentTxInvalid(t *testing.T) {
+	// The txid must be validated before a request is sent
+	var requests int
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(v1.VerifyUserPaymentTxReply{})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	var tests = []struct {
+		name    string
+		txid    string
+		wantErr bool
+	}{
+		{"valid", strings.Repeat("ab", 32), false},
+		{"empty", "", true},
+		{"not hex", strings.Repeat("z", 64), true},
+		{"too short", strings.Repeat("ab", 31), true},
+		{"too long", strings.Repeat("ab", 33), true},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			before := requests
+			_, err := c.VerifyUserPaymentTx(v.txid)
+			switch {
+			case v.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !v.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			case v.wantErr && requests != before:
+				t.Fatalf("request sent for invalid txid")
+			}
+		})
+	}
+}
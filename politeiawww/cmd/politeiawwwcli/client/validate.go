@@ -0,0 +1,169 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/badoux/checkmail"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// ValidationError is returned when a request fails client side validation.
+type ValidationError struct {
+	Field  string // Name of the invalid field
+	Reason string // Why the field is invalid
+}
+
+// Error satisfies the error interface.
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid %v: %v", e.Field, e.Reason)
+}
+
+// ValidateNewUser validates the email and username of a new user request
+// against the passed in server policy.  A ValidationError is returned for the
+// first field that is invalid.
+func ValidateNewUser(nu *v1.NewUser, pr *v1.PolicyReply) error {
+	err := validateEmail(nu.Email)
+	if err != nil {
+		return err
+	}
+	return validateUsername(nu.Username, pr)
+}
+
+// ValidatePassword validates a password against the password requirements of
+// the passed in server policy.  politeiawww enforces the same requirements;
+// validating the password first results in a precise local error.  The reason of the
+// returned ValidationError includes the estimated strength of the password.
+func ValidatePassword(password string, pr *v1.PolicyReply) error {
+	if uint(len(password)) < pr.MinPasswordLength {
+		return ValidationError{
+			Field: "password",
+			Reason: fmt.Sprintf("must be at least %v characters",
+				pr.MinPasswordLength),
+		}
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || r == ' ':
+			symbol = true
+		}
+	}
+
+	strength := util.PasswordStrength(password)
+	var reason string
+	switch {
+	case pr.PasswordRequireUpper && !upper:
+		reason = "must contain an upper case letter"
+	case pr.PasswordRequireLower && !lower:
+		reason = "must contain a lower case letter"
+	case pr.PasswordRequireDigit && !digit:
+		reason = "must contain a digit"
+	case pr.PasswordRequireSymbol && !symbol:
+		reason = "must contain a symbol"
+	case strength < int(pr.PasswordMinStrength):
+		reason = fmt.Sprintf("must have a strength of at least %v",
+			pr.PasswordMinStrength)
+	default:
+		return nil
+	}
+
+	return ValidationError{
+		Field: "password",
+		Reason: fmt.Sprintf("%v (strength %v/%v)", reason, strength,
+			util.PasswordStrengthMax),
+	}
+}
+
+// ValidateCensorComment verifies that a censor comment request contains a
+// reason of at least the minimum length that politeiawww accepts.  Leading
+// and trailing spaces are not counted since politeiawww ignores them.
+func ValidateCensorComment(cc *v1.CensorComment) error {
+	reason := strings.TrimSpace(cc.Reason)
+	if len(reason) < v1.PolicyMinCensorReasonLength {
+		return ValidationError{
+			Field: "reason",
+			Reason: fmt.Sprintf("must be at least %v characters",
+				v1.PolicyMinCensorReasonLength),
+		}
+	}
+	return nil
+}
+
+// validateEmail verifies that an email address is well formed.
+func validateEmail(email string) error {
+	err := checkmail.ValidateFormat(email)
+	if err != nil {
+		return ValidationError{
+			Field:  "email",
+			Reason: "not a valid email address",
+		}
+	}
+	return nil
+}
+
+// validateUsername verifies that a username adheres to the passed in server
+// policy.  politeiawww normalizes usernames to lower case without leading and
+// trailing spaces so the username is normalized before it is validated.
+func validateUsername(username string, pr *v1.PolicyReply) error {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if uint(len(username)) < pr.MinUsernameLength ||
+		uint(len(username)) > pr.MaxUsernameLength {
+		return ValidationError{
+			Field: "username",
+			Reason: fmt.Sprintf("must be between %v and %v characters",
+				pr.MinUsernameLength, pr.MaxUsernameLength),
+		}
+	}
+
+	re, err := usernameRegex(pr)
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(username) {
+		return ValidationError{
+			Field: "username",
+			Reason: fmt.Sprintf("must only contain the characters %v",
+				strings.Join(pr.UsernameSupportedChars, " ")),
+		}
+	}
+
+	return nil
+}
+
+// usernameRegex returns the regular expression of a valid username using the
+// supported characters of the passed in policy.  Single characters are
+// escaped; ranges, such as a-z, are used as is.
+func usernameRegex(pr *v1.PolicyReply) (*regexp.Regexp, error) {
+	var buf bytes.Buffer
+	buf.WriteString("^[")
+	for _, v := range pr.UsernameSupportedChars {
+		if len(v) > 1 {
+			buf.WriteString(v)
+		} else {
+			buf.WriteString(`\` + v)
+		}
+	}
+	buf.WriteString("]+$")
+
+	re, err := regexp.Compile(buf.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy username regex: %v", err)
+	}
+	return re, nil
+}
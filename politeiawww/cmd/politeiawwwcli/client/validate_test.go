@@ -0,0 +1,190 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func testPolicy() *v1.PolicyReply {
+	return &v1.PolicyReply{
+		MinUsernameLength:      v1.PolicyMinUsernameLength,
+		MaxUsernameLength:      v1.PolicyMaxUsernameLength,
+		UsernameSupportedChars: v1.PolicyUsernameSupportedChars,
+	}
+}
+
+func TestValidateNewUser(t *testing.T) {
+	var tests = []struct {
+		name     string
+		email    string
+		username string
+		field    string // Invalid field; empty if valid
+	}{
+		{"valid", "user@example.com", "user_1", ""},
+		{"valid not normalized", "user@example.com", " User.1 ", ""},
+		{"email missing at", "userexample.com", "user", "email"},
+		{"email missing domain", "user@", "user", "email"},
+		{"empty email", "", "user", "email"},
+		{"username too short", "user@example.com", "ab", "username"},
+		{"username too long", "user@example.com",
+			"abcdefghijklmnopqrstuvwxyz012345", "username"},
+		{"username invalid char", "user@example.com", "user$", "username"},
+		{"username inner space", "user@example.com", "us er", "username"},
+	}
+
+	pr := testPolicy()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateNewUser(&v1.NewUser{
+				Email:    test.email,
+				Username: test.username,
+			}, pr)
+			if test.field == "" {
+				if err != nil {
+					t.Fatalf("got error %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := err.(ValidationError)
+			if !ok {
+				t.Fatalf("got error %v, want ValidationError", err)
+			}
+			if ve.Field != test.field {
+				t.Fatalf("got field %v, want %v", ve.Field, test.field)
+			}
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	pr := &v1.PolicyReply{
+		MinPasswordLength:     v1.PolicyMinPasswordLength,
+		PasswordRequireUpper:  true,
+		PasswordRequireLower:  true,
+		PasswordRequireDigit:  true,
+		PasswordRequireSymbol: true,
+		PasswordMinStrength:   3,
+	}
+	var tests = []struct {
+		name     string
+		password string
+		reason   string // Reason of the error; empty if valid
+	}{
+		{"valid", "Tr0ub4dor&3", ""},
+		{"too short", "Tr0ub&3", "must be at least 8 characters"},
+		{"missing upper", "tr0ub4dor&3",
+			"must contain an upper case letter (strength 4/4)"},
+		{"missing lower", "TR0UB4DOR&3",
+			"must contain a lower case letter (strength 4/4)"},
+		{"missing digit", "Troubador&!",
+			"must contain a digit (strength 4/4)"},
+		{"missing symbol", "Tr0ub4dor33",
+			"must contain a symbol (strength 4/4)"},
+		{"too weak", "Password1!",
+			"must have a strength of at least 3 (strength 1/4)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePassword(test.password, pr)
+			if test.reason == "" {
+				if err != nil {
+					t.Fatalf("got error %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := err.(ValidationError)
+			if !ok {
+				t.Fatalf("got error %v, want ValidationError", err)
+			}
+			if ve.Field != "password" || ve.Reason != test.reason {
+				t.Fatalf("got %v %q, want password %q", ve.Field,
+					ve.Reason, test.reason)
+			}
+		})
+	}
+}
+
+func TestValidateCensorComment(t *testing.T) {
+	var tests = []struct {
+		name    string
+		reason  string
+		wantErr bool
+	}{
+		{"valid", "off topic spam", false},
+		{"empty", "", true},
+		{"blank", "          ", true},
+		{"too short", "spam", true},
+		{"too short with spaces", "   spam   ", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateCensorComment(&v1.CensorComment{
+				Reason: test.reason,
+			})
+			if !test.wantErr {
+				if err != nil {
+					t.Fatalf("got error %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := err.(ValidationError)
+			if !ok {
+				t.Fatalf("got error %v, want ValidationError", err)
+			}
+			if ve.Field != "reason" {
+				t.Fatalf("got field %v, want reason", ve.Field)
+			}
+		})
+	}
+}
+
+func TestNewUserValidation(t *testing.T) {
+	var newUserCalled bool
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasSuffix(r.URL.Path, v1.RouteNewUser) {
+				newUserCalled = true
+				json.NewEncoder(w).Encode(v1.NewUserReply{})
+				return
+			}
+			json.NewEncoder(w).Encode(testPolicy())
+		}))
+	defer s.Close()
+
+	nu := &v1.NewUser{
+		Email:    "userexample.com",
+		Username: "user",
+	}
+
+	// Requests are validated by default
+	c := newTestClient(t, s, true)
+	_, err := c.NewUser(nu)
+	if _, ok := err.(ValidationError); !ok {
+		t.Fatalf("got error %v, want ValidationError", err)
+	}
+	if newUserCalled {
+		t.Fatalf("new user request was sent for an invalid user")
+	}
+
+	// Skipping validation leaves it to the server
+	c.cfg.SkipValidation = true
+	_, err = c.NewUser(nu)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	if !newUserCalled {
+		t.Fatalf("new user request was not sent")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+var (
+	// ErrCommentCensored is returned when attempting to verify a comment
+	// that has been censored.  The comment text of a censored comment is
+	// removed by the server so the author signature can no longer be
+	// verified.
+	ErrCommentCensored = errors.New("comment has been censored")
+
+	// ErrCommentDeleted is returned when attempting to verify a comment
+	// that has been deleted by its author.  The comment text of a deleted
+	// comment is removed by the server so the author signature can no
+	// longer be verified.
+	ErrCommentDeleted = errors.New("comment has been deleted by its author")
+)
+
+// ServerPublicKey returns the politeiawww identity that is used to sign
+// censorship records and receipts.  The public key is fetched using Version
+// if it has not been fetched yet and is cached for the lifetime of the client.
+// An error is returned if the public key is not a well formed hex encoded
+// ed25519 public key.
+func (c *Client) ServerPublicKey() (*identity.PublicIdentity, error) {
+	if c.serverID != nil {
+		return c.serverID, nil
+	}
+
+	if c.serverPubKey == "" {
+		_, err := c.Version()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := util.IdentityFromString(c.serverPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server public key %v: %v",
+			c.serverPubKey, err)
+	}
+	c.serverID = id
+
+	return id, nil
+}
+
+// VerifyServerSignature verifies that the passed in signature of msg was made
+// by politeiawww.  This is used to verify the receipts that the server
+// returns.
+func (c *Client) VerifyServerSignature(msg, signature string) error {
+	id, err := c.ServerPublicKey()
+	if err != nil {
+		return err
+	}
+	return verifyServerSignature(id, msg, signature)
+}
+
+// verifyServerSignature verifies that the passed in signature of msg was made
+// by the passed in server identity.
+func verifyServerSignature(id *identity.PublicIdentity, msg, signature string) error {
+	sig, err := util.ConvertSignature(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	if !id.VerifyMessage([]byte(msg), sig) {
+		return fmt.Errorf("could not verify server signature")
+	}
+	return nil
+}
+
+// VerifyProposal verifies the merkle root and author signature of the passed
+// in proposal and verifies that the censorship record was signed by
+// politeiawww.
+func (c *Client) VerifyProposal(p v1.ProposalRecord) error {
+	err := verifyProposalAuthor(p)
+	if err != nil {
+		return err
+	}
+
+	// Verify censorship record signature
+	err = c.VerifyServerSignature(p.CensorshipRecord.Merkle+
+		p.CensorshipRecord.Token, p.CensorshipRecord.Signature)
+	if err != nil {
+		return fmt.Errorf("censorship record: %v", err)
+	}
+
+	return nil
+}
+
+// verifyProposalAuthor verifies the merkle root of the files and the author
+// signature of the passed in proposal.  The signature covers the RFP links of
+// the proposal.
+func verifyProposalAuthor(p v1.ProposalRecord) error {
+	// Verify merkle root
+	if len(p.Files) > 0 {
+		mr, err := merkleRoot(p.Files)
+		if err != nil {
+			return err
+		}
+		if mr != p.CensorshipRecord.Merkle {
+			return fmt.Errorf("merkle roots do not match")
+		}
+	}
+
+	// Verify proposal signature
+	pid, err := util.IdentityFromString(p.PublicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := util.ConvertSignature(p.Signature)
+	if err != nil {
+		return err
+	}
+	msg := v1.ProposalSignatureMessage(p.CensorshipRecord.Merkle, p.LinkTo,
+		p.LinkBy)
+	if !pid.VerifyMessage([]byte(msg), sig) {
+		return fmt.Errorf("could not verify proposal signature")
+	}
+
+	return nil
+}
+
+// VerifyComment verifies the author signature of the passed in comment.  The
+// signature is of Token+ParentID+Comment, or of Token+CommentID+Comment if the
+// comment has been edited, followed by the digests of the attached files and
+// must have been made using the comment's public key.
+func VerifyComment(c v1.Comment) error {
+	if c.Censored {
+		return ErrCommentCensored
+	}
+	if c.Deleted != 0 {
+		return ErrCommentDeleted
+	}
+
+	id, err := util.IdentityFromString(c.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %v", err)
+	}
+	sig, err := util.ConvertSignature(c.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	msg := []byte(commentMessage(c.Token, c.ParentID, c.Comment, c.Files))
+	if c.Edited != 0 {
+		msg = []byte(EditCommentMessage(c.Token, c.CommentID, c.Comment,
+			c.Files))
+	}
+	if !id.VerifyMessage(msg, sig) {
+		return fmt.Errorf("could not verify signature of comment %v",
+			c.CommentID)
+	}
+
+	return nil
+}
+
+// VerifyComments verifies the author signatures of the passed in comments.
+// It returns a map of the comment IDs to their verification result.  A nil
+// error indicates that the comment signature is valid.
+func VerifyComments(comments []v1.Comment) map[string]error {
+	results := make(map[string]error, len(comments))
+	for _, v := range comments {
+		results[v.CommentID] = VerifyComment(v)
+	}
+	return results
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+func TestServerPublicKey(t *testing.T) {
+	serverID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name    string
+		pubkey  string
+		wantErr bool
+	}{
+		{"valid", hex.EncodeToString(serverID.Public.Key[:]), false},
+		{"not hex", "zz", true},
+		{"wrong length", hex.EncodeToString(serverID.Public.Key[:16]), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Client{
+				serverPubKey: test.pubkey,
+			}
+			id, err := c.ServerPublicKey()
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if id.Key != serverID.Public.Key {
+				t.Fatalf("got key %x, want %x", id.Key,
+					serverID.Public.Key)
+			}
+			if c.serverID != id {
+				t.Fatalf("server identity was not cached")
+			}
+		})
+	}
+}
+
+func TestVerifyProposal(t *testing.T) {
+	serverID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{
+		serverPubKey: hex.EncodeToString(serverID.Public.Key[:]),
+	}
+
+	// newProposal returns a proposal that is signed by the author and
+	// has a censorship record that is signed by the server.
+	newProposal := func() v1.ProposalRecord {
+		payload := []byte("title\ndescription")
+		files := []v1.File{{
+			Name:    indexFile,
+			MIME:    "text/plain; charset=utf-8",
+			Digest:  hex.EncodeToString(util.Digest(payload)),
+			Payload: base64.StdEncoding.EncodeToString(payload),
+		}}
+		mr, err := merkleRoot(files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := authorID.SignMessage([]byte(mr))
+		token := hex.EncodeToString(util.Digest([]byte("token")))
+		crSig := serverID.SignMessage([]byte(mr + token))
+		return v1.ProposalRecord{
+			Files:     files,
+			PublicKey: hex.EncodeToString(authorID.Public.Key[:]),
+			Signature: hex.EncodeToString(sig[:]),
+			CensorshipRecord: v1.CensorshipRecord{
+				Token:     token,
+				Merkle:    mr,
+				Signature: hex.EncodeToString(crSig[:]),
+			},
+		}
+	}
+
+	var tests = []struct {
+		name    string
+		modify  func(p *v1.ProposalRecord)
+		wantErr bool
+	}{
+		{"valid", func(p *v1.ProposalRecord) {}, false},
+		{"tampered file", func(p *v1.ProposalRecord) {
+			p.Files[0].Digest = hex.EncodeToString(
+				util.Digest([]byte("tampered")))
+		}, true},
+		{"wrong author signature", func(p *v1.ProposalRecord) {
+			sig := serverID.SignMessage([]byte(p.CensorshipRecord.Merkle))
+			p.Signature = hex.EncodeToString(sig[:])
+		}, true},
+		{"wrong censorship record signature", func(p *v1.ProposalRecord) {
+			sig := authorID.SignMessage([]byte(p.CensorshipRecord.Merkle +
+				p.CensorshipRecord.Token))
+			p.CensorshipRecord.Signature = hex.EncodeToString(sig[:])
+		}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := newProposal()
+			test.modify(&p)
+			err := c.VerifyProposal(p)
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !test.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestVerifyComment(t *testing.T) {
+	authorID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(msg string) string {
+		sig := authorID.SignMessage([]byte(msg))
+		return hex.EncodeToString(sig[:])
+	}
+	token := hex.EncodeToString(util.Digest([]byte("token")))
+	files := []v1.File{{
+		Digest: hex.EncodeToString(util.Digest([]byte("file"))),
+	}}
+
+	// newComment returns a comment with an attached file that is signed
+	// by the author.
+	newComment := func() v1.Comment {
+		return v1.Comment{
+			Token:     token,
+			ParentID:  "1",
+			CommentID: "2",
+			Comment:   "comment",
+			PublicKey: hex.EncodeToString(authorID.Public.Key[:]),
+			Signature: sign(commentMessage(token, "1", "comment", files)),
+			Files:     files,
+		}
+	}
+
+	var tests = []struct {
+		name    string
+		modify  func(c *v1.Comment)
+		wantErr bool
+	}{
+		{"valid", func(c *v1.Comment) {}, false},
+		{"file removed", func(c *v1.Comment) {
+			c.Files = nil
+		}, true},
+		{"digest moved into comment", func(c *v1.Comment) {
+			c.Comment += c.Files[0].Digest
+			c.Files = nil
+		}, true},
+		{"edited", func(c *v1.Comment) {
+			c.Edited = 1
+			c.Comment = "edited"
+			c.Signature = sign(EditCommentMessage(token, "2", "edited",
+				files))
+		}, false},
+		{"edited without file digests", func(c *v1.Comment) {
+			c.Edited = 1
+			c.Comment = "edited"
+			c.Signature = sign(token + "2" + "edited")
+		}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newComment()
+			test.modify(&c)
+			err := VerifyComment(c)
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !test.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/rpc/walletrpc"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// voteSignBatchSize is the maximum number of votes that are signed with a
+// single SignMessages call.  The context passed to VoteProposal is checked
+// between batches.
+const voteSignBatchSize = 64
+
+var (
+	// ErrNoEligibleTickets is returned by VoteProposal when the wallet
+	// does not have any tickets that are eligible to vote on the proposal.
+	ErrNoEligibleTickets = errors.New("wallet has no tickets that are " +
+		"eligible to vote on the proposal")
+
+	// ErrWalletPassphraseNotSet is returned by VoteProposal when no
+	// function to obtain the wallet passphrase has been set.
+	ErrWalletPassphraseNotSet = errors.New("wallet passphrase function " +
+		"not set")
+)
+
+// RejectedVote describes a vote that was not accepted by politeiawww.
+type RejectedVote struct {
+	Ticket string `json:"ticket"` // Ticket hash
+	Error  string `json:"error"`  // Reason the vote was rejected
+}
+
+// VoteSummary is the result of casting the votes of all eligible tickets of
+// the wallet on a proposal.
+type VoteSummary struct {
+	Token       string         `json:"token"`       // Censorship token
+	OptionID    string         `json:"optionid"`    // Selected vote option
+	VoteBit     string         `json:"votebit"`     // Vote bit of the option
+	Accepted    []string       `json:"accepted"`    // Tickets whose vote was accepted
+	Rejected    []RejectedVote `json:"rejected"`    // Votes that were rejected
+	BlockHeight uint64         `json:"blockheight"` // Best block of politeiawww when the votes were cast
+	Synced      bool           `json:"synced"`      // Whether politeiawww was synced
+}
+
+// SetWalletPassphrase sets the function that VoteProposal uses to obtain the
+// private passphrase of the wallet.  The function is only called once there
+// are tickets to sign, so no passphrase is asked for when the wallet has no
+// eligible tickets.
+func (c *Client) SetWalletPassphrase(fn func() ([]byte, error)) {
+	c.walletPassphrase = fn
+}
+
+// VoteProposal casts the votes of all tickets of the wallet that are eligible
+// to vote on the specified proposal for the vote option with the passed in
+// ID.  The eligible tickets are found by matching the ticket snapshot of the
+// vote against the committed tickets of the wallet.  Every vote is signed by
+// the wallet, the votes are cast as a single ballot and the receipts are
+// verified against the politeiawww public key.
+//
+// ErrNoEligibleTickets is returned when the wallet has no eligible tickets.
+// The context is checked before every batch of signatures and before the
+// ballot is cast; no votes are cast once it has been cancelled.  The wallet
+// client must be loaded using LoadWalletClient.
+//
+// The summary contains the best block of politeiawww when the votes were cast
+// and whether it was synced; votes that are cast while it is not synced may be
+// accepted after the vote has ended on the network.
+func (c *Client) VoteProposal(ctx context.Context, token, optionID string) (*VoteSummary, error) {
+	pvt, err := c.activeVote(token)
+	if err != nil {
+		return nil, err
+	}
+	voteBit, err := VoteBitForOption(pvt.StartVote.Vote, optionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the tickets of the wallet that are part of the snapshot
+	pool := make([][]byte, 0, len(pvt.StartVoteReply.EligibleTickets))
+	for _, v := range pvt.StartVoteReply.EligibleTickets {
+		h, err := chainhash.NewHashFromStr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid eligible ticket %v: %v", v, err)
+		}
+		pool = append(pool, h[:])
+	}
+	ctr, err := c.CommittedTickets(&walletrpc.CommittedTicketsRequest{
+		Tickets: pool,
+	})
+	if err != nil {
+		return nil, err
+	}
+	addresses := make(map[string]string, len(ctr.TicketAddresses))
+	owned := make([]string, 0, len(ctr.TicketAddresses))
+	for _, v := range ctr.TicketAddresses {
+		h, err := chainhash.NewHash(v.Ticket)
+		if err != nil {
+			return nil, fmt.Errorf("invalid committed ticket: %v", err)
+		}
+		addresses[h.String()] = v.Address
+		owned = append(owned, h.String())
+	}
+	tickets := eligibleTickets(pvt.StartVoteReply.EligibleTickets, owned)
+	if len(tickets) == 0 {
+		return nil, ErrNoEligibleTickets
+	}
+
+	if c.walletPassphrase == nil {
+		return nil, ErrWalletPassphraseNotSet
+	}
+	passphrase, err := c.walletPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign the votes in batches so that a cancelled context stops
+	// the signing early
+	votes := make([]v1.CastVote, 0, len(tickets))
+	for len(votes) < len(tickets) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		batch := tickets[len(votes):]
+		if len(batch) > voteSignBatchSize {
+			batch = batch[:voteSignBatchSize]
+		}
+		messages := make([]*walletrpc.SignMessagesRequest_Message, 0,
+			len(batch))
+		for _, v := range batch {
+			messages = append(messages,
+				&walletrpc.SignMessagesRequest_Message{
+					Address: addresses[v],
+					Message: token + v + voteBit,
+				})
+		}
+		smr, err := c.SignMessages(&walletrpc.SignMessagesRequest{
+			Passphrase: passphrase,
+			Messages:   messages,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(smr.Replies) != len(batch) {
+			return nil, fmt.Errorf("got %v signatures, want %v",
+				len(smr.Replies), len(batch))
+		}
+
+		// The replies use the same index as the messages
+		for i, v := range smr.Replies {
+			if v.Error != "" {
+				return nil, fmt.Errorf("sign vote of ticket %v: %v",
+					batch[i], v.Error)
+			}
+			votes = append(votes, v1.CastVote{
+				Token:     token,
+				Ticket:    batch[i],
+				VoteBit:   voteBit,
+				Signature: hex.EncodeToString(v.Signature),
+			})
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	serverID, err := c.ServerPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// politeiawww decides whether the vote is still active using its
+	// own best block, which lags behind the network when it is not synced
+	bhr, err := c.checkSync(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &v1.Ballot{
+		Votes: votes,
+	}
+	br, err := c.CastVotes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	vs, err := summarizeBallot(serverID, b, br)
+	if err != nil {
+		return nil, err
+	}
+	vs.Token = token
+	vs.OptionID = optionID
+	vs.VoteBit = voteBit
+	vs.BlockHeight = bhr.Height
+	vs.Synced = bhr.Synced
+
+	return vs, nil
+}
+
+// summarizeBallot sorts the votes of the passed in ballot into accepted and
+// rejected votes using the receipts of the passed in ballot reply.  A vote
+// whose receipt can not be verified against the passed in server identity is
+// rejected.
+func summarizeBallot(serverID *identity.PublicIdentity, b *v1.Ballot, br *v1.BallotReply) (*VoteSummary, error) {
+	if len(br.Receipts) != len(b.Votes) {
+		return nil, fmt.Errorf("got %v vote receipts, want %v",
+			len(br.Receipts), len(b.Votes))
+	}
+
+	vs := VoteSummary{
+		Accepted: make([]string, 0, len(b.Votes)),
+		Rejected: make([]RejectedVote, 0),
+	}
+	for i, v := range br.Receipts {
+		// The receipts use the same index as the votes
+		ticket := b.Votes[i].Ticket
+
+		if v.Error != "" {
+			vs.Rejected = append(vs.Rejected, RejectedVote{
+				Ticket: ticket,
+				Error:  v.Error,
+			})
+			continue
+		}
+		err := verifyServerSignature(serverID, v.ClientSignature, v.Signature)
+		if err != nil {
+			vs.Rejected = append(vs.Rejected, RejectedVote{
+				Ticket: ticket,
+				Error:  "invalid receipt: " + err.Error(),
+			})
+			continue
+		}
+		vs.Accepted = append(vs.Accepted, ticket)
+	}
+
+	return &vs, nil
+}
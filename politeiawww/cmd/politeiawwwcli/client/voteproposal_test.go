@@ -0,0 +1,67 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestSummarizeBallot(t *testing.T) {
+	serverID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherID, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := func(id *identity.FullIdentity, clientSig string) v1.CastVoteReply {
+		sig := id.SignMessage([]byte(clientSig))
+		return v1.CastVoteReply{
+			ClientSignature: clientSig,
+			Signature:       hex.EncodeToString(sig[:]),
+		}
+	}
+
+	b := &v1.Ballot{
+		Votes: []v1.CastVote{
+			{Ticket: "accepted", Signature: "sig1"},
+			{Ticket: "failed", Signature: "sig2"},
+			{Ticket: "forged", Signature: "sig3"},
+		},
+	}
+	br := &v1.BallotReply{
+		Receipts: []v1.CastVoteReply{
+			receipt(serverID, "sig1"),
+			{ClientSignature: "sig2", Error: "ticket already voted"},
+			receipt(otherID, "sig3"),
+		},
+	}
+
+	vs, err := summarizeBallot(&serverID.Public, b, br)
+	if err != nil {
+		t.Fatalf("summarizeBallot: %v", err)
+	}
+	if len(vs.Accepted) != 1 || vs.Accepted[0] != "accepted" {
+		t.Errorf("got accepted %v, want [accepted]", vs.Accepted)
+	}
+	if len(vs.Rejected) != 2 ||
+		vs.Rejected[0].Ticket != "failed" ||
+		vs.Rejected[0].Error != "ticket already voted" ||
+		vs.Rejected[1].Ticket != "forged" {
+		t.Errorf("got rejected %v, want failed and forged", vs.Rejected)
+	}
+
+	// The number of receipts must match the number of votes
+	br.Receipts = br.Receipts[:2]
+	_, err = summarizeBallot(&serverID.Public, b, br)
+	if err == nil {
+		t.Errorf("got nil error for missing receipt, want error")
+	}
+}
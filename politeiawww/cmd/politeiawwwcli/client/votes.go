@@ -0,0 +1,287 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// VoteEligibility contains the tickets that are eligible to vote on a
+// proposal.  Tickets is empty when the vote of the proposal is not active;
+// Status then reports why.
+type VoteEligibility struct {
+	Token   string             `json:"token"`   // Censorship token
+	Status  v1.PropVoteStatusT `json:"status"`  // Vote status of the proposal
+	Tickets []string           `json:"tickets"` // Eligible ticket hashes
+}
+
+// GetVoteEligibleTickets returns the subset of the passed in ticket hashes
+// that are eligible to vote on the specified proposal.  The eligible tickets
+// are returned in the order they were passed in, without duplicates.
+//
+// The eligible tickets are taken from the ticket snapshot of the active
+// votes.  If the proposal vote is not active, no tickets are returned and the
+// vote status of the proposal is fetched so that the caller can tell whether
+// the vote has not started yet, has finished or the proposal does not exist.
+func (c *Client) GetVoteEligibleTickets(token string, ticketHashes []string) (*VoteEligibility, error) {
+	avr, err := c.ActiveVotes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range avr.Votes {
+		if v.Proposal.CensorshipRecord.Token != token {
+			continue
+		}
+		return &VoteEligibility{
+			Token:  token,
+			Status: v1.PropVoteStatusStarted,
+			Tickets: eligibleTickets(v.StartVoteReply.EligibleTickets,
+				ticketHashes),
+		}, nil
+	}
+
+	// The proposal vote is not active
+	vsr, err := c.VoteStatus(token)
+	if err != nil {
+		return nil, err
+	}
+	return &VoteEligibility{
+		Token:   token,
+		Status:  vsr.Status,
+		Tickets: []string{},
+	}, nil
+}
+
+// eligibleTickets returns the ticket hashes that are part of the passed in
+// eligible ticket snapshot.
+func eligibleTickets(snapshot, ticketHashes []string) []string {
+	eligible := make(map[string]struct{}, len(snapshot))
+	for _, v := range snapshot {
+		eligible[v] = struct{}{}
+	}
+
+	tickets := make([]string, 0, len(ticketHashes))
+	for _, v := range ticketHashes {
+		if _, ok := eligible[v]; !ok {
+			continue
+		}
+		tickets = append(tickets, v)
+
+		// Skip duplicates
+		delete(eligible, v)
+	}
+
+	return tickets
+}
+
+// GetVoteOptions returns the vote of the specified proposal, including the
+// options that a ticket can select and their vote bits.  An error is returned
+// when the proposal vote is not active since votes can only be cast on active
+// votes.
+func (c *Client) GetVoteOptions(token string) (*v1.Vote, error) {
+	pvt, err := c.activeVote(token)
+	if err != nil {
+		return nil, err
+	}
+	vote := pvt.StartVote.Vote
+	return &vote, nil
+}
+
+// activeVote returns the active vote of the specified proposal.  An error
+// that contains the vote status of the proposal is returned when the vote is
+// not active.
+func (c *Client) activeVote(token string) (*v1.ProposalVoteTuple, error) {
+	avr, err := c.ActiveVotes()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range avr.Votes {
+		if v.Proposal.CensorshipRecord.Token == token {
+			return &v, nil
+		}
+	}
+
+	// The proposal vote is not active
+	vsr, err := c.VoteStatus(token)
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("proposal %v: %v", token,
+		v1.PropVoteStatus[vsr.Status])
+}
+
+// VoteBitForOption returns the hex encoded vote bit that selects the option
+// with the passed in ID in the passed in vote.  The vote bits of the option are
+// validated the same way politeiad validates cast votes: they must be non-zero
+// and within the vote mask.  An error that lists the valid option IDs is
+// returned when the vote does not define the option.
+func VoteBitForOption(vote v1.Vote, optionID string) (string, error) {
+	ids := make([]string, 0, len(vote.Options))
+	for _, v := range vote.Options {
+		ids = append(ids, v.Id)
+		if v.Id != optionID {
+			continue
+		}
+		if v.Bits == 0 || vote.Mask&v.Bits != v.Bits {
+			return "", fmt.Errorf("vote option %v has invalid bits 0x%x "+
+				"for mask 0x%x", v.Id, v.Bits, vote.Mask)
+		}
+		return strconv.FormatUint(v.Bits, 16), nil
+	}
+	return "", fmt.Errorf("vote option %q not found; valid options: %v",
+		optionID, strings.Join(ids, ", "))
+}
+
+// VoteOptionForBit returns the ID of the option of the passed in vote that the
+// passed in hex encoded vote bit selects.
+func VoteOptionForBit(vote v1.Vote, voteBit string) (string, error) {
+	bits, err := strconv.ParseUint(voteBit, 16, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid vote bit %q", voteBit)
+	}
+	for _, v := range vote.Options {
+		if v.Bits == bits {
+			return v.Id, nil
+		}
+	}
+	return "", fmt.Errorf("vote bit %q does not select an option", voteBit)
+}
+
+// voteResultsCSVHeader is the header row of the vote results CSV export.
+var voteResultsCSVHeader = []string{"ticket", "votebit", "signature"}
+
+// ExportVoteResultsCSV fetches the vote results for the specified proposal
+// and writes the cast votes to w as CSV.  The first row is a header row and
+// each following row contains the ticket, vote bit and signature of a single
+// cast vote.  The politeiawww API does not provide the time that a vote was
+// cast so no timestamp column is written.
+//
+// The response is decoded and written one cast vote at a time so the full
+// vote results are never held in memory.
+func (c *Client) ExportVoteResultsCSV(token string, w io.Writer) error {
+	fullRoute := c.cfg.Host + c.apiRoute() + "/proposals/" +
+		token + "/votes"
+
+	// Print request details
+	if c.cfg.Verbose {
+		fmt.Printf("Request: GET %v\n", fullRoute)
+	}
+
+	// Create new http request instead of using makeRequest()
+	// so that the response body can be streamed.
+	req, err := http.NewRequest(http.MethodGet, fullRoute, nil)
+	if err != nil {
+		return err
+	}
+	c.addCSRFHeader(req)
+	setRequestID(req)
+
+	// Send request
+	r, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		r.Body.Close()
+	}()
+
+	// Validate response status
+	if r.StatusCode != http.StatusOK {
+		return newAPIError(r,
+			util.ConvertBodyToByteArray(r.Body, false))
+	}
+
+	// Print response details
+	if c.cfg.Verbose {
+		fmt.Printf("Response: %v\n", r.StatusCode)
+	}
+
+	return writeCastVotesCSV(r.Body, w)
+}
+
+// writeCastVotesCSV decodes a JSON encoded VoteResultsReply from r and writes
+// the cast votes to w as CSV.  The cast votes are decoded and written one at a
+// time.  All other fields of the reply are skipped.
+func writeCastVotesCSV(r io.Reader, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(voteResultsCSVHeader)
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(r)
+	err = expectDelim(d, '{')
+	if err != nil {
+		return fmt.Errorf("decode VoteResultsReply: %v", err)
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return fmt.Errorf("decode VoteResultsReply: %v", err)
+		}
+		if t != "castvotes" {
+			// Skip field
+			var skip json.RawMessage
+			err = d.Decode(&skip)
+			if err != nil {
+				return fmt.Errorf("decode VoteResultsReply: %v", err)
+			}
+			continue
+		}
+
+		// The cast votes are null when no votes have been cast
+		t, err = d.Token()
+		if err != nil {
+			return fmt.Errorf("decode castvotes: %v", err)
+		}
+		if t == nil {
+			continue
+		}
+		if t != json.Delim('[') {
+			return fmt.Errorf("decode castvotes: unexpected token %v", t)
+		}
+		for d.More() {
+			var cv v1.CastVote
+			err = d.Decode(&cv)
+			if err != nil {
+				return fmt.Errorf("decode CastVote: %v", err)
+			}
+			err = cw.Write([]string{cv.Ticket, cv.VoteBit, cv.Signature})
+			if err != nil {
+				return err
+			}
+		}
+		err = expectDelim(d, ']')
+		if err != nil {
+			return fmt.Errorf("decode castvotes: %v", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// expectDelim reads the next JSON token from d and returns an error if it is
+// not the provided delimiter.
+func expectDelim(d *json.Decoder, delim json.Delim) error {
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("expected %v, got %v", delim, t)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestWriteCastVotesCSV(t *testing.T) {
+	vrr := v1.VoteResultsReply{
+		StartVote: v1.StartVote{
+			PublicKey: "pubkey",
+		},
+		CastVotes: []v1.CastVote{
+			{
+				Token:     "token",
+				Ticket:    "ticket1",
+				VoteBit:   "1",
+				Signature: "sig1",
+			},
+			{
+				Token:     "token",
+				Ticket:    "ticket2",
+				VoteBit:   "2",
+				Signature: "sig2",
+			},
+		},
+		StartVoteReply: v1.StartVoteReply{
+			EligibleTickets: []string{"ticket1", "ticket2"},
+		},
+	}
+	b, err := json.Marshal(vrr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name    string
+		reply   string
+		want    string
+		wantErr bool
+	}{
+		{
+			"cast votes",
+			string(b),
+			"ticket,votebit,signature\n" +
+				"ticket1,1,sig1\n" +
+				"ticket2,2,sig2\n",
+			false,
+		},
+		{
+			"no cast votes",
+			`{"startvote":{},"castvotes":null,"startvotereply":{}}`,
+			"ticket,votebit,signature\n",
+			false,
+		},
+		{
+			"malformed reply",
+			`{"castvotes":{}}`,
+			"",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeCastVotesCSV(strings.NewReader(test.reply), &buf)
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !test.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			case test.wantErr:
+				return
+			}
+			if buf.String() != test.want {
+				t.Errorf("got %q, want %q", buf.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestGetVoteEligibleTickets(t *testing.T) {
+	active := "active"
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case v1.PoliteiaWWWAPIRoute + v1.RouteActiveVote:
+				var pvt v1.ProposalVoteTuple
+				pvt.Proposal.CensorshipRecord.Token = active
+				pvt.StartVoteReply.EligibleTickets = []string{"t1",
+					"t2", "t3"}
+				json.NewEncoder(w).Encode(v1.ActiveVoteReply{
+					Votes: []v1.ProposalVoteTuple{pvt},
+				})
+			case v1.PoliteiaWWWAPIRoute + "/proposals/finished/votestatus":
+				json.NewEncoder(w).Encode(v1.VoteStatusReply{
+					Token:  "finished",
+					Status: v1.PropVoteStatusFinished,
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	var tests = []struct {
+		name       string
+		token      string
+		tickets    []string
+		wantStatus v1.PropVoteStatusT
+		want       []string
+	}{
+		{"active vote", active, []string{"t3", "t4", "t1", "t3"},
+			v1.PropVoteStatusStarted, []string{"t3", "t1"}},
+		{"no eligible tickets", active, []string{"t4"},
+			v1.PropVoteStatusStarted, []string{}},
+		{"vote finished", "finished", []string{"t1"},
+			v1.PropVoteStatusFinished, []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ve, err := c.GetVoteEligibleTickets(test.token, test.tickets)
+			if err != nil {
+				t.Fatalf("GetVoteEligibleTickets: %v", err)
+			}
+			if ve.Status != test.wantStatus {
+				t.Errorf("got status %v, want %v", ve.Status,
+					test.wantStatus)
+			}
+			if strings.Join(ve.Tickets, ",") !=
+				strings.Join(test.want, ",") {
+				t.Errorf("got tickets %v, want %v", ve.Tickets,
+					test.want)
+			}
+		})
+	}
+}
+
+func TestVoteBitForOption(t *testing.T) {
+	vote := v1.Vote{
+		Mask: 0x07,
+		Options: []v1.VoteOption{
+			{Id: "approve", Bits: 0x01},
+			{Id: "reject", Bits: 0x02},
+			{Id: "abstain", Bits: 0x04},
+			{Id: "outsidemask", Bits: 0x08},
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		optionID string
+		want     string
+		wantErr  bool
+	}{
+		{"approve", "approve", "1", false},
+		{"abstain", "abstain", "4", false},
+		{"unknown option", "yes", "", true},
+		{"bits outside mask", "outsidemask", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := VoteBitForOption(vote, test.optionID)
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !test.wantErr && err != nil:
+				t.Fatalf("VoteBitForOption: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("got vote bit %q, want %q", got, test.want)
+			}
+			if test.wantErr {
+				return
+			}
+
+			// The vote bit maps back to the option
+			id, err := VoteOptionForBit(vote, got)
+			if err != nil {
+				t.Fatalf("VoteOptionForBit: %v", err)
+			}
+			if id != test.optionID {
+				t.Errorf("got option %v, want %v", id, test.optionID)
+			}
+		})
+	}
+
+	_, err := VoteOptionForBit(vote, "10")
+	if err == nil {
+		t.Fatalf("got nil error for a vote bit without option")
+	}
+}
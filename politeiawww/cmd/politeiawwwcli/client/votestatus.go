@@ -0,0 +1,69 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"sync"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// voteStatusConcurrency is the maximum number of vote status requests that
+// are made concurrently by BatchVoteStatus.
+const voteStatusConcurrency = 4
+
+// VoteStatusResult is the vote status of a single proposal returned by
+// BatchVoteStatus.  Error is set when the vote status could not be retrieved.
+type VoteStatusResult struct {
+	Reply *v1.VoteStatusReply `json:"reply,omitempty"` // Vote status
+	Error string              `json:"error,omitempty"` // Reason the request failed
+}
+
+// BatchVoteStatus retrieves the vote status of the specified proposals.  The
+// returned map is keyed by token and contains an entry for every requested
+// token; a failed request only sets the Error of its own entry.
+//
+// politeiawww does not provide a batch vote status route so one request is
+// made per token.  The requests are made concurrently.
+func (c *Client) BatchVoteStatus(tokens []string) map[string]VoteStatusResult {
+	var (
+		wg      sync.WaitGroup
+		mtx     sync.Mutex
+		sem     = make(chan struct{}, voteStatusConcurrency)
+		seen    = make(map[string]struct{}, len(tokens))
+		results = make(map[string]VoteStatusResult, len(tokens))
+	)
+	for _, v := range tokens {
+		// Skip duplicate tokens
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(token string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			var r VoteStatusResult
+			vsr, err := c.VoteStatus(token)
+			if err != nil {
+				r.Error = err.Error()
+			} else {
+				r.Reply = vsr
+			}
+
+			mtx.Lock()
+			results[token] = r
+			mtx.Unlock()
+		}(v)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestBatchVoteStatus(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			route := strings.TrimPrefix(r.URL.Path,
+				v1.PoliteiaWWWAPIRoute+"/proposals/")
+			token := strings.TrimSuffix(route, "/votestatus")
+			if token == "missing" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(v1.UserError{
+					ErrorCode: v1.ErrorStatusProposalNotFound,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(v1.VoteStatusReply{
+				Token:  token,
+				Status: v1.PropVoteStatusStarted,
+			})
+		}))
+	defer s.Close()
+	c := newTestClient(t, s, true)
+
+	tokens := []string{"a", "b", "missing", "c", "d", "e", "a"}
+	results := c.BatchVoteStatus(tokens)
+	if len(results) != 6 {
+		t.Fatalf("got %v results, want 6", len(results))
+	}
+	for _, v := range tokens {
+		r, ok := results[v]
+		if !ok {
+			t.Fatalf("no result for token %v", v)
+		}
+		if v == "missing" {
+			if r.Error == "" || r.Reply != nil {
+				t.Errorf("got result %v for missing token, want error", r)
+			}
+			continue
+		}
+		if r.Error != "" {
+			t.Errorf("token %v: got error %v", v, r.Error)
+			continue
+		}
+		if r.Reply.Token != v || r.Reply.Status != v1.PropVoteStatusStarted {
+			t.Errorf("token %v: got reply %v", v, r.Reply)
+		}
+	}
+}
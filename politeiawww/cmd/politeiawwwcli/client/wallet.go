@@ -7,10 +7,24 @@ package client
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/decred/dcrwallet/rpc/walletrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Parameters that are used to reconnect to dcrwallet when it becomes
+// unavailable.  The delay between attempts starts at walletBackoffInitial and
+// doubles after every attempt, up to walletBackoffMax.  They are variables so
+// that tests can use shorter delays.
+var (
+	walletReconnectAttempts = 5
+	walletBackoffInitial    = 500 * time.Millisecond
+	walletBackoffMax        = 8 * time.Second
 )
 
 // LoadWalletClient connects to a dcrwallet instance.
@@ -20,36 +34,127 @@ func (c *Client) LoadWalletClient() error {
 		return err
 	}
 
+	c.walletMtx.Lock()
+	defer c.walletMtx.Unlock()
+
+	c.ctx = context.Background()
+	c.creds = creds
+	return c.dialWallet()
+}
+
+// dialWallet dials dcrwallet and replaces the existing connection, if there
+// is one.  It must be called with the wallet lock held.
+func (c *Client) dialWallet() error {
 	conn, err := grpc.Dial(c.cfg.WalletHost,
-		grpc.WithTransportCredentials(creds))
+		grpc.WithTransportCredentials(c.creds))
 	if err != nil {
 		return err
 	}
 
-	c.ctx = context.Background()
-	c.creds = creds
+	if c.conn != nil {
+		c.conn.Close()
+	}
 	c.conn = conn
 	c.wallet = walletrpc.NewWalletServiceClient(conn)
 	return nil
 }
 
-// WalletAccounts retrieves the walletprc accounts.
-func (c *Client) WalletAccounts() (*walletrpc.AccountsResponse, error) {
+// WalletState returns the state of the connection to dcrwallet.
+// connectivity.Shutdown is returned when the wallet client has not been
+// loaded or has been closed.
+func (c *Client) WalletState() connectivity.State {
+	c.walletMtx.Lock()
+	defer c.walletMtx.Unlock()
+
+	if c.conn == nil {
+		return connectivity.Shutdown
+	}
+	return c.conn.GetState()
+}
+
+// walletClient returns the walletrpc client of the current connection.
+func (c *Client) walletClient() (walletrpc.WalletServiceClient, error) {
+	c.walletMtx.Lock()
+	defer c.walletMtx.Unlock()
+
+	if c.wallet == nil {
+		return nil, fmt.Errorf("walletrpc client not loaded")
+	}
+	return c.wallet, nil
+}
+
+// redialWallet replaces the connection to dcrwallet with a new one and
+// returns its walletrpc client.
+func (c *Client) redialWallet() (walletrpc.WalletServiceClient, error) {
+	c.walletMtx.Lock()
+	defer c.walletMtx.Unlock()
+
 	if c.wallet == nil {
 		return nil, fmt.Errorf("walletrpc client not loaded")
 	}
+	err := c.dialWallet()
+	if err != nil {
+		return nil, err
+	}
+	return c.wallet, nil
+}
 
+// walletRPC executes the passed in walletrpc call.  When the call fails
+// because dcrwallet is unavailable, e.g. because it is being restarted, the
+// connection is redialed and the call is retried using exponential backoff.
+// The error of the last attempt is returned once all attempts have failed.
+func (c *Client) walletRPC(fn func(walletrpc.WalletServiceClient) error) error {
+	wallet, err := c.walletClient()
+	if err != nil {
+		return err
+	}
+
+	err = fn(wallet)
+	backoff := walletBackoffInitial
+	for i := 1; i <= walletReconnectAttempts; i++ {
+		if status.Code(err) != codes.Unavailable {
+			return err
+		}
+
+		if c.cfg.Verbose {
+			fmt.Printf("walletrpc %v unavailable: reconnecting in %v "+
+				"(attempt %v/%v)\n", c.cfg.WalletHost, backoff, i,
+				walletReconnectAttempts)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > walletBackoffMax {
+			backoff = walletBackoffMax
+		}
+
+		wallet, err = c.redialWallet()
+		if err != nil {
+			return err
+		}
+		err = fn(wallet)
+	}
+
+	return err
+}
+
+// WalletAccounts retrieves the walletprc accounts.
+func (c *Client) WalletAccounts() (*walletrpc.AccountsResponse, error) {
 	if c.cfg.Verbose {
 		fmt.Printf("walletrpc %v Accounts\n", c.cfg.WalletHost)
 	}
 
-	ar, err := c.wallet.Accounts(c.ctx, &walletrpc.AccountsRequest{})
+	var ar *walletrpc.AccountsResponse
+	err := c.walletRPC(func(wallet walletrpc.WalletServiceClient) error {
+		var err error
+		ar, err = wallet.Accounts(c.ctx, &walletrpc.AccountsRequest{})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(ar)
+		err := c.prettyPrintJSON(ar)
 		if err != nil {
 			return nil, err
 		}
@@ -61,21 +166,22 @@ func (c *Client) WalletAccounts() (*walletrpc.AccountsResponse, error) {
 // CommittedTickets returns the committed tickets that belong to the dcrwallet
 // instance out of the the specified list of tickets.
 func (c *Client) CommittedTickets(ct *walletrpc.CommittedTicketsRequest) (*walletrpc.CommittedTicketsResponse, error) {
-	if c.wallet == nil {
-		return nil, fmt.Errorf("walletrpc client not loaded")
-	}
-
 	if c.cfg.Verbose {
 		fmt.Printf("walletrpc %v CommittedTickets\n", c.cfg.WalletHost)
 	}
 
-	ctr, err := c.wallet.CommittedTickets(c.ctx, ct)
+	var ctr *walletrpc.CommittedTicketsResponse
+	err := c.walletRPC(func(wallet walletrpc.WalletServiceClient) error {
+		var err error
+		ctr, err = wallet.CommittedTickets(c.ctx, ct)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(ctr)
+		err := c.prettyPrintJSON(ctr)
 		if err != nil {
 			return nil, err
 		}
@@ -87,21 +193,22 @@ func (c *Client) CommittedTickets(ct *walletrpc.CommittedTicketsRequest) (*walle
 // SignMessages signs the passed in messages using the private keys from the
 // specified addresses.
 func (c *Client) SignMessages(sm *walletrpc.SignMessagesRequest) (*walletrpc.SignMessagesResponse, error) {
-	if c.wallet == nil {
-		return nil, fmt.Errorf("walletrpc client not loaded")
-	}
-
 	if c.cfg.Verbose {
 		fmt.Printf("walletrpc %v SignMessages\n", c.cfg.WalletHost)
 	}
 
-	smr, err := c.wallet.SignMessages(c.ctx, sm)
+	var smr *walletrpc.SignMessagesResponse
+	err := c.walletRPC(func(wallet walletrpc.WalletServiceClient) error {
+		var err error
+		smr, err = wallet.SignMessages(c.ctx, sm)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	if c.cfg.Verbose {
-		err := prettyPrintJSON(smr)
+		err := c.prettyPrintJSON(smr)
 		if err != nil {
 			return nil, err
 		}
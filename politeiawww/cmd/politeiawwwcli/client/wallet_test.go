@@ -0,0 +1,152 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/elliptic"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrwallet/rpc/walletrpc"
+	"github.com/decred/politeia/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/config"
+)
+
+// testWalletServer is a walletrpc server that only implements the Accounts
+// call.
+type testWalletServer struct {
+	walletrpc.WalletServiceServer
+}
+
+// Accounts satisfies the walletrpc.WalletServiceServer interface.
+func (s *testWalletServer) Accounts(ctx context.Context, r *walletrpc.AccountsRequest) (*walletrpc.AccountsResponse, error) {
+	return &walletrpc.AccountsResponse{
+		CurrentBlockHeight: 1,
+	}, nil
+}
+
+// startTestWalletServer starts a walletrpc server that listens on the passed
+// in address using the passed in TLS key pair.
+func startTestWalletServer(addr, certFile, keyFile string) (*grpc.Server, net.Addr, error) {
+	creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := grpc.NewServer(grpc.Creds(creds))
+	walletrpc.RegisterWalletServiceServer(s, &testWalletServer{})
+	go s.Serve(l)
+	return s, l.Addr(), nil
+}
+
+func TestWalletReconnect(t *testing.T) {
+	attempts, initial, max := walletReconnectAttempts, walletBackoffInitial,
+		walletBackoffMax
+	walletReconnectAttempts = 5
+	walletBackoffInitial = 50 * time.Millisecond
+	walletBackoffMax = 200 * time.Millisecond
+	defer func() {
+		walletReconnectAttempts = attempts
+		walletBackoffInitial = initial
+		walletBackoffMax = max
+	}()
+
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "rpc.cert")
+	keyFile := filepath.Join(dir, "rpc.key")
+	err = util.GenCertPair(elliptic.P256(), "politeiawwwcli", certFile,
+		keyFile)
+	if err != nil {
+		t.Fatalf("GenCertPair: %v", err)
+	}
+
+	s, addr, err := startTestWalletServer("127.0.0.1:0", certFile, keyFile)
+	if err != nil {
+		t.Fatalf("startTestWalletServer: %v", err)
+	}
+	_, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		t.Fatalf("SplitHostPort: %v", err)
+	}
+
+	c, err := New(&config.Config{
+		Host:       "https://127.0.0.1",
+		WalletHost: net.JoinHostPort("localhost", port),
+		WalletCert: certFile,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.WalletState() != connectivity.Shutdown {
+		t.Fatalf("got state %v before loading, want %v", c.WalletState(),
+			connectivity.Shutdown)
+	}
+	err = c.LoadWalletClient()
+	if err != nil {
+		t.Fatalf("LoadWalletClient: %v", err)
+	}
+	defer c.Close()
+
+	_, err = c.WalletAccounts()
+	if err != nil {
+		t.Fatalf("WalletAccounts: %v", err)
+	}
+
+	// Restart the wallet.  The call is retried until the wallet is back.
+	s.Stop()
+	restarted := make(chan error, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		var err error
+		s, _, err = startTestWalletServer(addr.String(), certFile, keyFile)
+		restarted <- err
+	}()
+	ar, err := c.WalletAccounts()
+	if err != nil {
+		t.Fatalf("WalletAccounts after restart: %v", err)
+	}
+	if ar.CurrentBlockHeight != 1 {
+		t.Fatalf("got block height %v, want 1", ar.CurrentBlockHeight)
+	}
+	err = <-restarted
+	if err != nil {
+		t.Fatalf("restart wallet: %v", err)
+	}
+	if c.WalletState() != connectivity.Ready {
+		t.Fatalf("got state %v, want %v", c.WalletState(),
+			connectivity.Ready)
+	}
+
+	// The error is returned once the wallet does not come back
+	s.Stop()
+	_, err = c.WalletAccounts()
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("got error %v, want unavailable", err)
+	}
+
+	c.Close()
+	if c.WalletState() != connectivity.Shutdown {
+		t.Fatalf("got state %v after close, want %v", c.WalletState(),
+			connectivity.Shutdown)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/hex"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// AbandonProposalCmd withdraws a proposal that was submitted by the logged in
+// user.
+type AbandonProposalCmd struct {
+	Args struct {
+		Token   string `positional-arg-name:"token" required:"true"`   // Censorship token
+		Message string `positional-arg-name:"message" required:"true"` // Reason for abandoning
+	} `positional-args:"true"`
+}
+
+// Execute executes the abandon proposal command.
+func (cmd *AbandonProposalCmd) Execute(args []string) error {
+	// Validate user identity
+	if cfg.Identity == nil {
+		return errUserIdentityNotFound
+	}
+
+	// Setup request
+	sig := cfg.Identity.SignMessage([]byte(cmd.Args.Token + cmd.Args.Message))
+	ap := &v1.AbandonProposal{
+		Token:     cmd.Args.Token,
+		Message:   cmd.Args.Message,
+		Signature: hex.EncodeToString(sig[:]),
+		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
+	}
+
+	// Print request details
+	err := printRequestJSON(ap)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	apr, err := client.AbandonProposal(ap)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return printJSON(apr)
+}
+
+// abandonProposalHelpMsg is the output of the help command when
+// "abandonproposal" is specified.
+const abandonProposalHelpMsg = `abandonproposal "token" "message"
+
+Withdraw a proposal that you submitted.  A public proposal is abandoned as
+long as its vote has not been authorized or started.  An unreviewed proposal
+is censored.  Must be the proposal author.
+
+Arguments:
+1. token      (string, required)   Proposal censorship token
+2. message    (string, required)   Reason for abandoning the proposal
+
+Request:
+{
+  "token":      (string)  Censorship token
+  "message":    (string)  Reason for abandoning the proposal
+  "signature":  (string)  Signature of token+message
+  "publickey":  (string)  Public key of the proposal author
+}
+
+Response:
+{
+  "proposal": {
+    "name":          (string)  Suggested short proposal name 
+    "state":         (PropStateT)   Current state of proposal
+    "status":        (PropStatusT)  Current status of proposal
+    "timestamp":     (int64)  Timestamp of last update of proposal
+    "userid":        (string)  ID of user who submitted proposal
+    "username":      (string)  Username of user who submitted proposal
+    "publickey":     (string)  Public key used to sign proposal
+    "signature":     (string)  Signature of merkle root
+    "files": [
+      {
+        "name":      (string)  Filename 
+        "mime":      (string)  Mime type 
+        "digest":    (string)  File digest 
+        "payload":   (string)  File payload 
+      }
+    ],
+    "numcomments":   (uint)  Number of comments on proposal
+    "version":       (string)  Version of proposal
+    "censorshiprecord": {
+      "token":       (string)  Censorship token
+      "merkle":      (string)  Merkle root of proposal
+      "signature":   (string)  Server side signature of []byte(Merkle+Token)
+    }
+  }
+}`
@@ -0,0 +1,40 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// APITokensCmd retrieves all API tokens.
+type APITokensCmd struct{}
+
+// Execute executes the API tokens command.
+func (cmd *APITokensCmd) Execute(args []string) error {
+	atr, err := client.APITokens()
+	if err != nil {
+		return err
+	}
+	return printJSON(atr)
+}
+
+// apiTokensHelpMsg is the output of the help command when 'apitokens' is
+// specified.
+var apiTokensHelpMsg = `apitokens
+
+Fetch all API tokens, ordered by creation time.  The tokens themselves are not
+returned.  Requires admin privileges.
+
+Arguments: None
+
+Result:
+{
+  "apitokens": [
+    {
+      "id":           (string)  API token id
+      "userid":       (string)  User id
+      "scope":        (int)     Token scope
+      "description":  (string)  What the token is used for
+      "createdby":    (string)  Id of the admin that issued the token
+      "timestamp":    (int64)   Unix timestamp of when the token was issued
+    }
+  ]
+}`
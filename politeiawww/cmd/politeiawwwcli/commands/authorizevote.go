@@ -5,11 +5,9 @@
 package commands
 
 import (
-	"encoding/hex"
 	"fmt"
 
 	"github.com/decred/politeia/politeiawww/api/v1"
-	"github.com/decred/politeia/util"
 )
 
 // AuthorizeVoteCmd authorizes a proposal vote.  The AuthorizeVoteCmd must be
@@ -43,52 +41,21 @@ func (cmd *AuthorizeVoteCmd) Execute(args []string) error {
 			"revoke     revoke a vote authorization")
 	}
 
-	// Get server public key
-	vr, err := client.Version()
-	if err != nil {
-		return err
-	}
-
-	// Get proposal version
-	pdr, err := client.ProposalDetails(token, nil)
-	if err != nil {
-		return err
-	}
-
-	// Setup authorize vote request
-	sig := cfg.Identity.SignMessage([]byte(token + pdr.Proposal.Version +
-		cmd.Args.Action))
-	av := &v1.AuthorizeVote{
-		Action:    cmd.Args.Action,
-		Token:     token,
-		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
-		Signature: hex.EncodeToString(sig[:]),
-	}
-
-	// Print request details
-	err = printJSON(av)
-	if err != nil {
-		return err
-	}
-
-	// Send request
-	avr, err := client.AuthorizeVote(av)
-	if err != nil {
-		return err
-	}
-
-	// Validate authorize vote receipt
-	serverID, err := util.IdentityFromString(vr.PubKey)
-	if err != nil {
-		return err
+	// Send request.  The client checks the vote status of the proposal
+	// and verifies the server receipt.
+	var (
+		avr *v1.AuthorizeVoteReply
+		err error
+	)
+	switch cmd.Args.Action {
+	case v1.AuthVoteActionAuthorize:
+		avr, err = client.AuthorizeProposalVote(token, cfg.Identity)
+	case v1.AuthVoteActionRevoke:
+		avr, err = client.RevokeVoteAuthorization(token, cfg.Identity)
 	}
-	s, err := util.ConvertSignature(avr.Receipt)
 	if err != nil {
 		return err
 	}
-	if !serverID.VerifyMessage([]byte(av.Signature), s) {
-		return fmt.Errorf("could not verify authorize vote receipt")
-	}
 
 	// Print response details
 	return printJSON(avr)
@@ -99,7 +66,8 @@ func (cmd *AuthorizeVoteCmd) Execute(args []string) error {
 const authorizeVoteHelpMsg = `authorizevote "token" "action"
 
 Authorize or revoke proposal vote. Only the proposal author (owner of 
-censorship token) can authorize or revoke vote. 
+censorship token) can authorize or revoke vote. A vote authorization can only
+be revoked before the vote has started.
 
 Arguments:
 1. token      (string, required)   Proposal censorship token
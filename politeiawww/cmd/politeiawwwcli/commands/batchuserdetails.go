@@ -0,0 +1,48 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// BatchUserDetailsCmd gets the user details for multiple users.
+type BatchUserDetailsCmd struct {
+	Args struct {
+		UserIDs []string `positional-arg-name:"userids"` // User IDs
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the batch user details command.
+func (cmd *BatchUserDetailsCmd) Execute(args []string) error {
+	users, err := client.BatchUserDetails(cmd.Args.UserIDs)
+	if err != nil {
+		return err
+	}
+	return printJSON(users)
+}
+
+// batchUserDetailsHelpMsg is the output of the help command when
+// 'batchuserdetails' is specified.
+const batchUserDetailsHelpMsg = `batchuserdetails "userids..."
+
+Fetch the details of multiple users by user id.  Users that are cached by the
+client are not requested again.  Only the public fields of a user are returned
+unless you are an admin or are the user.  Users that do not exist are left
+out.
+
+Arguments:
+1. userids     ([]string, required)   User ids
+
+Result:
+{
+  "userid": {
+    "id":          (string)  Unique user id
+    "username":    (string)  Unique username
+    "isadmin":     (bool)    Is user an admin
+    "identities": [
+      {
+        "pubkey":     (string)  User's public key
+        "isactive":   (bool)    Whether user's identity is active or not
+      }
+    ]
+  }
+}`
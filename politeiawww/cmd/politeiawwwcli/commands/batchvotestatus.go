@@ -0,0 +1,37 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// BatchVoteStatusCmd gets the vote status of multiple proposals.
+type BatchVoteStatusCmd struct {
+	Args struct {
+		Tokens []string `positional-arg-name:"tokens"` // Censorship tokens
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the batch vote status command.
+func (cmd *BatchVoteStatusCmd) Execute(args []string) error {
+	return printJSON(client.BatchVoteStatus(cmd.Args.Tokens))
+}
+
+// batchVoteStatusHelpMsg is the output of the help command when
+// 'batchvotestatus' is specified.
+const batchVoteStatusHelpMsg = `batchvotestatus "tokens..."
+
+Fetch the vote status of multiple proposals.  The vote status of every token is
+fetched separately; a token whose vote status can not be fetched is returned
+with an error instead of a vote status.  See 'votestatus' for the vote status
+fields.
+
+Arguments:
+1. tokens      ([]string, required)   Proposal censorship tokens
+
+Result:
+{
+  "token": {
+    "reply":   (object)  Vote status of the proposal
+    "error":   (string)  Reason the vote status could not be fetched
+  }
+}`
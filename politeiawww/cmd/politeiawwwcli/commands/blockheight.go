@@ -0,0 +1,36 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// BlockHeightCmd retrieves the best block of the Decred blockchain as seen by
+// politeiawww.
+type BlockHeightCmd struct{}
+
+// Execute executes the block height command.
+func (cmd *BlockHeightCmd) Execute(args []string) error {
+	bhr, err := client.BlockHeight()
+	if err != nil {
+		return err
+	}
+	return printJSON(bhr)
+}
+
+// blockHeightHelpMsg is the output for the help command when 'blockheight' is
+// specified.
+const blockHeightHelpMsg = `blockheight
+
+Get the best block of the Decred blockchain as seen by politeiawww and whether
+it is current.  Vote heights and payment confirmations are computed using this
+block, so they lag behind the network when synced is false.
+
+Arguments: none
+
+Result:
+{
+  "height":     (uint64)  Height of the best block
+  "timestamp":  (int64)   Unix timestamp of when politeiawww first saw the
+                          best block
+  "synced":     (bool)    Whether the best block is current
+}`
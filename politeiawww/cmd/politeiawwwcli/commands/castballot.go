@@ -0,0 +1,53 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"fmt"
+
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
+)
+
+// CastBallotCmd casts the votes of a signed ballot that was saved to a file.
+type CastBallotCmd struct {
+	Args struct {
+		File string `positional-arg-name:"file"` // Ballot file
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the cast ballot command.
+func (cmd *CastBallotCmd) Execute(args []string) error {
+	b, err := wwwclient.LoadBallotFromFile(cmd.Args.File)
+	if err != nil {
+		return fmt.Errorf("LoadBallotFromFile: %v", err)
+	}
+
+	// Get server public key
+	serverID, err := client.ServerPublicKey()
+	if err != nil {
+		return fmt.Errorf("ServerPublicKey: %v", err)
+	}
+
+	return castBallot(serverID, b)
+}
+
+// castBallotHelpMsg is the output of the help command when 'castballot' is
+// specified.
+const castBallotHelpMsg = `castballot "file"
+
+Cast the ticket votes of a signed ballot that was saved to a file, e.g. with
+the --save flag of the vote command.  This allows the ballot to be signed and
+cast on different machines.
+
+Arguments:
+1. file        (string, required)   Ballot file.  Files with a .csv extension
+                                    contain a header row followed by one row
+                                    per vote with the columns token, ticket,
+                                    votebit and signature.  All other files
+                                    contain a JSON encoded ballot.
+
+Result:
+Votes succeeded:  (int)  Number of successful votes
+Votes failed   :  (int)  Number of failed votes`
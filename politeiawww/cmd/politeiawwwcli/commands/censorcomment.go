@@ -9,7 +9,6 @@ import (
 	"fmt"
 
 	"github.com/decred/politeia/politeiawww/api/v1"
-	"github.com/decred/politeia/util"
 )
 
 // CensorCommentCmd censors a proposal comment.
@@ -33,7 +32,7 @@ func (cmd *CensorCommentCmd) Execute(args []string) error {
 	}
 
 	// Get server public key
-	vr, err := client.Version()
+	_, err := client.ServerPublicKey()
 	if err != nil {
 		return err
 	}
@@ -50,7 +49,7 @@ func (cmd *CensorCommentCmd) Execute(args []string) error {
 	}
 
 	// Print request details
-	err = printJSON(cc)
+	err = printRequestJSON(cc)
 	if err != nil {
 		return err
 	}
@@ -62,16 +61,9 @@ func (cmd *CensorCommentCmd) Execute(args []string) error {
 	}
 
 	// Validate censor comment receipt
-	serverID, err := util.IdentityFromString(vr.PubKey)
+	err = client.VerifyServerSignature(signature, ccr.Receipt)
 	if err != nil {
-		return err
-	}
-	receiptB, err := util.ConvertSignature(ccr.Receipt)
-	if err != nil {
-		return err
-	}
-	if !serverID.VerifyMessage([]byte(signature), receiptB) {
-		return fmt.Errorf("could not verify receipt signature")
+		return fmt.Errorf("could not verify receipt signature: %v", err)
 	}
 
 	// Print response details
@@ -82,7 +74,8 @@ func (cmd *CensorCommentCmd) Execute(args []string) error {
 // is specified.
 const censorCommentHelpMsg = `censorcomment "token" "commentID" "reason"
 
-Censor a user comment. Requires admin privileges.
+Censor a user comment. Requires admin privileges. The reason must be at least
+8 characters long, not counting leading and trailing spaces.
 
 Arguments:
 1. token       (string, required)   Proposal censorship token
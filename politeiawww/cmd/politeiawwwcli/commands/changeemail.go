@@ -0,0 +1,62 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import "github.com/decred/politeia/politeiawww/api/v1"
+
+// ChangeEmailCmd requests an email address change for the logged in user.
+type ChangeEmailCmd struct {
+	Args struct {
+		Password string `positional-arg-name:"password"` // User password
+		NewEmail string `positional-arg-name:"newemail"` // New email address
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the change email command.
+func (cmd *ChangeEmailCmd) Execute(args []string) error {
+	ce := &v1.ChangeEmail{
+		Password: digestSHA3(cmd.Args.Password),
+		NewEmail: cmd.Args.NewEmail,
+	}
+
+	// Print request details
+	err := printRequestJSON(ce)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	cer, err := client.ChangeEmail(ce)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return printJSON(cer)
+}
+
+// changeEmailHelpMsg is the output of the help command when 'changeemail' is
+// specified.
+var changeEmailHelpMsg = `changeemail "password" "newemail"
+
+Request an email address change for the currently logged in user. A
+verification token is sent to the new email address. The email address is not
+changed until the token is verified using the verifychangeemail command.
+
+Arguments:
+1. password      (string, required)   Current password
+2. newemail      (string, required)   New email address
+
+Request:
+{
+  "newemail":   (string)  New email address
+  "password":   (string)  Current password
+}
+
+Response:
+{
+  "verificationtoken":   (string)  Server verification token (only set if
+                                   email is disabled on the server)
+}`
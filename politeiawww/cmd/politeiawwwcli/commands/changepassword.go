@@ -5,9 +5,8 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/decred/politeia/politeiawww/api/v1"
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
 )
 
 // ChangePasswordCmd changes the password for the logged in user.
@@ -27,19 +26,20 @@ func (cmd *ChangePasswordCmd) Execute(args []string) error {
 	}
 
 	// Validate new password
-	if uint(len(cmd.Args.NewPassword)) < pr.MinPasswordLength {
-		return fmt.Errorf("password must be %v characters long",
-			pr.MinPasswordLength)
+	err = wwwclient.ValidatePassword(cmd.Args.NewPassword, pr)
+	if err != nil {
+		return err
 	}
 
-	// Setup change password request
+	// Setup change password request.  The new password is sent in plain
+	// text so that politeiawww can enforce the password policy.
 	cp := &v1.ChangePassword{
 		CurrentPassword: digestSHA3(cmd.Args.Password),
-		NewPassword:     digestSHA3(cmd.Args.NewPassword),
+		NewPassword:     cmd.Args.NewPassword,
 	}
 
 	// Print request details
-	err = printJSON(cp)
+	err = printRequestJSON(cp)
 	if err != nil {
 		return err
 	}
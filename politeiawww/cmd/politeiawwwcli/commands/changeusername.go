@@ -22,7 +22,7 @@ func (cmd *ChangeUsernameCmd) Execute(args []string) error {
 	}
 
 	// Print request details
-	err := printJSON(cu)
+	err := printRequestJSON(cu)
 	if err != nil {
 		return err
 	}
@@ -9,7 +9,6 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -46,24 +45,44 @@ var (
 	// 'before' and 'after' are used at the same time.
 	errInvalidBeforeAfterUsage = errors.New("the 'before' and 'after' flags " +
 		"cannot be used at the same time")
+
+	// errInvalidTitleAuthorUsage is emitted when the command flags
+	// 'titleonly' and 'authoronly' are used at the same time.
+	errInvalidTitleAuthorUsage = errors.New("the 'titleonly' and " +
+		"'authoronly' flags cannot be used at the same time")
 )
 
 // Cmds is used to represent all of the politeiawwwcli commands.
 type Cmds struct {
+	AbandonProposal    AbandonProposalCmd    `command:"abandonproposal" description:"(user)   withdraw a proposal (must be proposal author)"`
 	ActiveVotes        ActiveVotesCmd        `command:"activevotes" description:"(public) get the proposals that are being voted on"`
+	APITokens          APITokensCmd          `command:"apitokens" description:"(admin)  get all API tokens"`
 	AuthorizeVote      AuthorizeVoteCmd      `command:"authorizevote" description:"(user)   authorize a proposal vote (must be proposal author)"`
+	BatchUserDetails   BatchUserDetailsCmd   `command:"batchuserdetails" description:"(public) get the details of multiple user profiles"`
+	BatchVoteStatus    BatchVoteStatusCmd    `command:"batchvotestatus" description:"(public) get the vote status of multiple proposals"`
+	BlockHeight        BlockHeightCmd        `command:"blockheight" description:"(public) get the best block of the Decred blockchain as seen by politeiawww"`
+	CastBallot         CastBallotCmd         `command:"castballot" description:"(public) cast the votes of a signed ballot file"`
 	CensorComment      CensorCommentCmd      `command:"censorcomment" description:"(admin)  censor a proposal comment"`
+	ChangeEmail        ChangeEmailCmd        `command:"changeemail" description:"(user)   change the email address for the logged in user"`
 	ChangePassword     ChangePasswordCmd     `command:"changepassword" description:"(user)   change the password for the logged in user"`
 	ChangeUsername     ChangeUsernameCmd     `command:"changeusername" description:"(user)   change the username for the logged in user"`
+	CommentCounts      CommentCountsCmd      `command:"commentcounts" description:"(public) get the number of comments of multiple proposals"`
+	DeleteComment      DeleteCommentCmd      `command:"deletecomment" description:"(user)   delete a proposal comment (must be comment author)"`
+	DeleteDraft        DeleteDraftCmd        `command:"deletedraft" description:"(user)   delete a proposal draft"`
+	EditComment        EditCommentCmd        `command:"editcomment" description:"(user)   edit a proposal comment (must be comment author)"`
 	EditProposal       EditProposalCmd       `command:"editproposal" description:"(user)   edit a proposal"`
 	ManageUser         ManageUserCmd         `command:"manageuser" description:"(admin)  edit certain properties of the specified user"`
 	EditUser           EditUserCmd           `command:"edituser" description:"(user)   edit the  preferences of the logged in user"`
+	FundingStatus      FundingStatusCmd      `command:"fundingstatus" description:"(public) get how much of the budget of an approved proposal has been disbursed"`
 	Help               HelpCmd               `command:"help" description:"         print a detailed help message for a specific command"`
+	ImpersonateUser    ImpersonateUserCmd    `command:"impersonateuser" description:"(admin)  act as a user in a read-only session for support"`
 	Inventory          InventoryCmd          `command:"inventory" description:"(public) get the proposals that are being voted on"`
 	LikeComment        LikeCommentCmd        `command:"likecomment" description:"(user)   upvote/downvote a comment"`
 	Login              LoginCmd              `command:"login" description:"(public) login to Politeia"`
 	Logout             LogoutCmd             `command:"logout" description:"(public) logout of Politeia"`
 	Me                 MeCmd                 `command:"me" description:"(user)   get user details for the logged in user"`
+	NewAPIToken        NewAPITokenCmd        `command:"newapitoken" description:"(admin)  issue an API token for a user"`
+	NewDraft           NewDraftCmd           `command:"newdraft" description:"(user)   save a private proposal draft"`
 	NewProposal        NewProposalCmd        `command:"newproposal" description:"(user)   create a new proposal"`
 	NewComment         NewCommentCmd         `command:"newcomment" description:"(user)   create a new proposal comment"`
 	NewUser            NewUserCmd            `command:"newuser" description:"(public) create a new user"`
@@ -74,28 +93,40 @@ type Cmds struct {
 	ProposalStats      ProposalStatsCmd      `command:"proposalstats" description:"(public) get statistics on the proposal inventory"`
 	UnvettedProposals  UnvettedProposalsCmd  `command:"unvettedproposals" description:"(admin)  get a page of unvetted proposals"`
 	VettedProposals    VettedProposalsCmd    `command:"vettedproposals" description:"(public) get a page of vetted proposals"`
+	PurgeUnverified    PurgeUnverifiedCmd    `command:"purgeunverified" description:"(admin)  delete the users that never verified their email address"`
+	ReactComment       ReactCommentCmd       `command:"reactcomment" description:"(user)   add or remove a reaction to a comment"`
+	RescanStatus       RescanStatusCmd       `command:"rescanstatus" description:"(admin)  get the progress of a user payments rescan"`
 	RescanUserPayments RescanUserPaymentsCmd `command:"rescanuserpayments" description:"(admin)  rescan a user's payments to check for missed payments"`
 	ResetPassword      ResetPasswordCmd      `command:"resetpassword" description:"(public) reset the password for a user that is not logged in"`
+	RevokeAPIToken     RevokeAPITokenCmd     `command:"revokeapitoken" description:"(admin)  revoke an API token"`
+	SearchProposals    SearchProposalsCmd    `command:"searchproposals" description:"(public) search the vetted proposals by title and author"`
 	Secret             SecretCmd             `command:"secret" description:"(user)   ping politeiawww"`
 	SendFaucetTx       SendFaucetTxCmd       `command:"sendfaucettx" description:"         send a DCR transaction using the Decred tesnet faucet"`
 	SetProposalStatus  SetProposalStatusCmd  `command:"setproposalstatus" description:"(admin)  set the status of a proposal"`
 	StartVote          StartVoteCmd          `command:"startvote" description:"(admin)  start the voting period on a proposal"`
+	StatsHistory       StatsHistoryCmd       `command:"statshistory" description:"(public) get proposal and user activity statistics over time"`
 	Subscribe          SubscribeCmd          `command:"subscribe" description:"(public) subscribe to all websocket commands and do not exit tool"`
 	Tally              TallyCmd              `command:"tally" description:"(public) get the vote tally for a proposal"`
 	TestRun            TestRunCmd            `command:"testrun" description:"         run a series of tests on the politeiawww routes (dev use only)"`
 	UpdateUserKey      UpdateUserKeyCmd      `command:"updateuserkey" description:"(user)   generate a new identity for the logged in user"`
 	UserDetails        UserDetailsCmd        `command:"userdetails" description:"(public) get the details of a user profile"`
+	UserDrafts         UserDraftsCmd         `command:"userdrafts" description:"(user)   get the proposal drafts of the logged in user"`
+	UserLogoutAll      UserLogoutAllCmd      `command:"userlogoutall" description:"(admin)  log a user out of all of their sessions"`
 	UserLikeComments   UserLikeCommentsCmd   `command:"userlikecomments" description:"(user)   get the logged in user's comment upvotes/downvotes for a proposal"`
 	UserPendingPayment UserPendingPaymentCmd `command:"userpendingpayment" description:"(user)   get details for a pending payment for the logged in user"`
 	UserProposals      UserProposalsCmd      `command:"userproposals" description:"(public) get all proposals submitted by a specific user"`
 	Users              UsersCmd              `command:"users" description:"(admin)  get a list of users"`
+	VerifyChangeEmail  VerifyChangeEmailCmd  `command:"verifychangeemail" description:"(user)   verify the new email address of the logged in user"`
+	VerifyIntegrity    VerifyIntegrityCmd    `command:"verifyintegrity" description:"(public) verify the complete signature chain of a proposal"`
 	VerifyUserEmail    VerifyUserEmailCmd    `command:"verifyuseremail" description:"(public) verify a user's email address"`
 	VerifyUserPayment  VerifyUserPaymentCmd  `command:"verifyuserpayment" description:"(user)   check if the logged in user has paid their user registration fee"`
 	Version            VersionCmd            `command:"version" description:"(public) get server info and CSRF token"`
 	Vote               VoteCmd               `command:"vote" description:"(public) cast votes for a proposal"`
+	VoteOptions        VoteOptionsCmd        `command:"voteoptions" description:"(public) get the options of an active proposal vote"`
 	VoteResults        VoteResultsCmd        `command:"voteresults" description:"(public) get vote results for a proposal"`
 	VoteStatus         VoteStatusCmd         `command:"votestatus" description:"(public) get the vote status of a proposal"`
 	VoteStatuses       VoteStatusesCmd       `command:"votestatuses" description:"(public) get the vote status for all public proposals"`
+	WatchInventory     WatchInventoryCmd     `command:"watchinventory" description:"(public) print proposal submissions and status changes as they happen"`
 }
 
 // SetConfig sets the global config variable.
@@ -118,16 +149,16 @@ func printJSON(body interface{}) error {
 		// Verbose printing is handled in the client
 	case cfg.RawJSON:
 		// Print raw JSON with no formatting
-		b, err := json.Marshal(body)
+		b, err := cfg.EncodeJSON(body, false)
 		if err != nil {
-			return fmt.Errorf("Marshal: %v", err)
+			return err
 		}
 		fmt.Printf("%v\n", string(b))
 	default:
 		// Pretty print the body
-		b, err := json.MarshalIndent(body, "", "  ")
+		b, err := cfg.EncodeJSON(body, true)
 		if err != nil {
-			return fmt.Errorf("MarshalIndent: %v", err)
+			return err
 		}
 		fmt.Fprintf(os.Stdout, "%s\n", b)
 	}
@@ -135,6 +166,16 @@ func printJSON(body interface{}) error {
 	return nil
 }
 
+// printRequestJSON prints the passed in request body.  Request bodies are not
+// printed in json-only mode so that the reply is the only JSON that is written
+// to stdout.
+func printRequestJSON(body interface{}) error {
+	if cfg.JSONOnly {
+		return nil
+	}
+	return printJSON(body)
+}
+
 // PromptPassphrase is used to prompt the user for the private passphrase to
 // their wallet.
 func promptPassphrase() ([]byte, error) {
@@ -222,6 +263,13 @@ func merkleRoot(files []v1.File) (string, error) {
 // signedMerkleRoot calculates the merkle root of the passed in list of files,
 // signs the merkle root with the passed in identity and returns the signature.
 func signedMerkleRoot(files []v1.File, id *identity.FullIdentity) (string, error) {
+	return signedProposal(files, "", 0, id)
+}
+
+// signedProposal calculates the merkle root of the passed in list of files,
+// signs the merkle root and the passed in RFP links with the passed in
+// identity and returns the signature.  See v1.ProposalSignatureMessage.
+func signedProposal(files []v1.File, linkTo string, linkBy int64, id *identity.FullIdentity) (string, error) {
 	if len(files) == 0 {
 		return "", fmt.Errorf("no proposal files found")
 	}
@@ -229,54 +277,11 @@ func signedMerkleRoot(files []v1.File, id *identity.FullIdentity) (string, error
 	if err != nil {
 		return "", err
 	}
-	sig := id.SignMessage([]byte(mr))
+	msg := v1.ProposalSignatureMessage(mr, linkTo, linkBy)
+	sig := id.SignMessage([]byte(msg))
 	return hex.EncodeToString(sig[:]), nil
 }
 
-// verifyProposal verifies a proposal's merkle root, author signature, and
-// censorship record.
-func verifyProposal(p v1.ProposalRecord, serverPubKey string) error {
-	// Verify merkle root
-	if len(p.Files) > 0 {
-		mr, err := merkleRoot(p.Files)
-		if err != nil {
-			return err
-		}
-		if mr != p.CensorshipRecord.Merkle {
-			return fmt.Errorf("merkle roots do not match")
-		}
-	}
-
-	// Verify proposal signature
-	pid, err := util.IdentityFromString(p.PublicKey)
-	if err != nil {
-		return err
-	}
-	sig, err := util.ConvertSignature(p.Signature)
-	if err != nil {
-		return err
-	}
-	if !pid.VerifyMessage([]byte(p.CensorshipRecord.Merkle), sig) {
-		return fmt.Errorf("could not verify proposal signature")
-	}
-
-	// Verify censorship record signature
-	id, err := util.IdentityFromString(serverPubKey)
-	if err != nil {
-		return err
-	}
-	s, err := util.ConvertSignature(p.CensorshipRecord.Signature)
-	if err != nil {
-		return err
-	}
-	msg := []byte(p.CensorshipRecord.Merkle + p.CensorshipRecord.Token)
-	if !id.VerifyMessage(msg, s) {
-		return fmt.Errorf("could not verify censorship record signature")
-	}
-
-	return nil
-}
-
 // convertTicketHashes converts a slice of hexadecimal ticket hashes into
 // a slice of byte slices.
 func convertTicketHashes(h []string) ([][]byte, error) {
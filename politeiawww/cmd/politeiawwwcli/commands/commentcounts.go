@@ -0,0 +1,40 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// CommentCountsCmd gets the comment counts of multiple proposals.
+type CommentCountsCmd struct {
+	Args struct {
+		Tokens []string `positional-arg-name:"tokens"` // Censorship tokens
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the comment counts command.
+func (cmd *CommentCountsCmd) Execute(args []string) error {
+	counts, err := client.BatchCommentCounts(cmd.Args.Tokens)
+	if err != nil {
+		return err
+	}
+	return printJSON(counts)
+}
+
+// commentCountsHelpMsg is the output of the help command when
+// 'commentcounts' is specified.
+const commentCountsHelpMsg = `commentcounts "tokens..."
+
+Fetch the number of comments of multiple proposals without fetching the
+comments.  The number of comments includes censored and deleted comments.
+
+Arguments:
+1. tokens      ([]string, required)   Proposal censorship tokens
+
+Result:
+{
+  "token": {
+    "numcomments":  (uint)   Number of comments
+    "lastcomment":  (int64)  UNIX timestamp of the most recent comment, 0 if
+                             there are no comments
+  }
+}`
@@ -0,0 +1,112 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// DeleteCommentCmd deletes a proposal comment on behalf of its author.
+type DeleteCommentCmd struct {
+	Args struct {
+		Token     string `positional-arg-name:"token"`     // Censorship token
+		CommentID string `positional-arg-name:"commentID"` // Comment ID
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the delete comment command.
+func (cmd *DeleteCommentCmd) Execute(args []string) error {
+	token := cmd.Args.Token
+	commentID := cmd.Args.CommentID
+
+	// Check for user identity
+	if cfg.Identity == nil {
+		return errUserIdentityNotFound
+	}
+
+	// Get server public key
+	_, err := client.ServerPublicKey()
+	if err != nil {
+		return err
+	}
+
+	// Setup delete comment request
+	s := cfg.Identity.SignMessage([]byte(token + commentID))
+	signature := hex.EncodeToString(s[:])
+	dc := &v1.DeleteComment{
+		Token:     token,
+		CommentID: commentID,
+		Signature: signature,
+		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
+	}
+
+	// Print request details
+	err = printRequestJSON(dc)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	dcr, err := client.DeleteComment(dc)
+	if err != nil {
+		return err
+	}
+
+	// Validate delete comment receipt
+	err = client.VerifyServerSignature(signature, dcr.Receipt)
+	if err != nil {
+		return fmt.Errorf("could not verify receipt signature: %v", err)
+	}
+
+	// Print response details
+	return printJSON(dcr)
+}
+
+// deleteCommentHelpMsg is the output of the help command when 'deletecomment'
+// is specified.
+const deleteCommentHelpMsg = `deletecomment "token" "commentID"
+
+Delete a comment.  Only the comment author can delete a comment and only
+within the comment delete period (see the policy command).  The comment text
+and files are removed but the comment remains in the comment thread so that
+its replies keep their parent.  A deleted comment has its deleted field set
+whereas a comment that was removed by an admin is marked as censored.
+
+Arguments:
+1. token       (string, required)   Proposal censorship token
+2. commentID   (string, required)   Id of the comment
+
+Request:
+{
+  "token":      (string)  Censorship token
+  "commentid":  (string)  Id of comment
+  "signature":  (string)  Signature of delete comment (Token+CommentID)
+  "publickey":  (string)  Public key used for signature
+}
+
+Response:
+{
+  "comment": {
+    "token":        (string)  Censorship token
+    "parentid":     (string)  Id of the parent comment
+    "comment":      (string)  Empty comment text
+    "signature":    (string)  Signature of the comment
+    "publickey":    (string)  Public key of user
+    "commentid":    (string)  Id of the comment
+    "receipt":      (string)  Server signature of the comment signature
+    "timestamp":    (int64)   Received UNIX timestamp
+    "totalvotes":   (uint64)  Total number of up/down votes
+    "resultvotes":  (int64)   Vote score
+    "censored":     (bool)    If comment has been censored
+    "edited":       (int64)   UNIX timestamp of the last edit
+    "deleted":      (int64)   UNIX timestamp of the deletion
+    "userid":       (string)  User id
+    "username":     (string)  Username
+  },
+  "receipt":        (string)  Server signature of the delete comment signature
+}`
@@ -0,0 +1,33 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// DeleteDraftCmd deletes a proposal draft of the logged in user.
+type DeleteDraftCmd struct {
+	Args struct {
+		DraftID string `positional-arg-name:"draftid"` // Draft ID
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the delete draft command.
+func (cmd *DeleteDraftCmd) Execute(args []string) error {
+	ddr, err := client.DeleteDraft(cmd.Args.DraftID)
+	if err != nil {
+		return err
+	}
+	return printJSON(ddr)
+}
+
+// deleteDraftHelpMsg is the output of the help command when 'deletedraft' is
+// specified.
+const deleteDraftHelpMsg = `deletedraft "draftid"
+
+Delete a proposal draft of the logged in user.
+
+Arguments:
+1. draftid     (string, required)   Draft id
+
+Result:
+{}`
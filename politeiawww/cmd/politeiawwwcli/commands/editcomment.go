@@ -0,0 +1,128 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
+)
+
+// EditCommentCmd edits a proposal comment.
+type EditCommentCmd struct {
+	Args struct {
+		Token     string `positional-arg-name:"token"`     // Censorship token
+		CommentID string `positional-arg-name:"commentID"` // Comment ID
+		Comment   string `positional-arg-name:"comment"`   // New comment text
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the edit comment command.
+func (cmd *EditCommentCmd) Execute(args []string) error {
+	token := cmd.Args.Token
+	commentID := cmd.Args.CommentID
+	comment := cmd.Args.Comment
+
+	// Check for user identity
+	if cfg.Identity == nil {
+		return errUserIdentityNotFound
+	}
+
+	// Get server public key
+	_, err := client.ServerPublicKey()
+	if err != nil {
+		return err
+	}
+
+	// Get the files that are attached to the comment since their
+	// digests are signed along with the new comment text
+	gcr, err := client.GetComments(token)
+	if err != nil {
+		return err
+	}
+	var files []v1.File
+	for _, v := range gcr.Comments {
+		if v.CommentID == commentID {
+			files = v.Files
+			break
+		}
+	}
+
+	// Setup edit comment request
+	msg := wwwclient.EditCommentMessage(token, commentID, comment, files)
+	s := cfg.Identity.SignMessage([]byte(msg))
+	signature := hex.EncodeToString(s[:])
+	ec := &v1.EditComment{
+		Token:     token,
+		CommentID: commentID,
+		Comment:   comment,
+		Signature: signature,
+		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
+	}
+
+	// Print request details
+	err = printRequestJSON(ec)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	ecr, err := client.EditComment(ec)
+	if err != nil {
+		return err
+	}
+
+	// Validate edit comment receipt
+	err = client.VerifyServerSignature(signature, ecr.Comment.Receipt)
+	if err != nil {
+		return fmt.Errorf("could not verify receipt signature: %v", err)
+	}
+
+	// Print response details
+	return printJSON(ecr)
+}
+
+// editCommentHelpMsg is the output of the help command when 'editcomment' is
+// specified.
+const editCommentHelpMsg = `editcomment "token" "commentID" "comment"
+
+Edit a comment.  Only the comment author can edit a comment and only within
+the comment edit period (see the policy command).
+
+Arguments:
+1. token       (string, required)   Proposal censorship token
+2. commentID   (string, required)   Id of the comment
+3. comment     (string, required)   New comment text
+
+Request:
+{
+  "token":      (string)  Censorship token
+  "commentid":  (string)  Id of comment
+  "comment":    (string)  New comment text
+  "signature":  (string)  Signature of edit comment (Token+CommentID+Comment+file digests)
+  "publickey":  (string)  Public key used for signature
+}
+
+Response:
+{
+  "comment": {
+    "token":        (string)  Censorship token
+    "parentid":     (string)  Id of the parent comment
+    "comment":      (string)  New comment text
+    "signature":    (string)  Signature of edit comment (Token+CommentID+Comment+file digests)
+    "publickey":    (string)  Public key of user
+    "commentid":    (string)  Id of the comment
+    "receipt":      (string)  Server signature of the edit comment signature
+    "timestamp":    (int64)   Received UNIX timestamp
+    "totalvotes":   (uint64)  Total number of up/down votes
+    "resultvotes":  (int64)   Vote score
+    "censored":     (bool)    If comment has been censored
+    "edited":       (int64)   UNIX timestamp of the last edit
+    "userid":       (string)  User id
+    "username":     (string)  Username
+  }
+}`
@@ -39,7 +39,13 @@ func (cmd *EditProposalCmd) Execute(args []string) error {
 	}
 
 	// Get server public key
-	vr, err := client.Version()
+	_, err := client.ServerPublicKey()
+	if err != nil {
+		return err
+	}
+
+	// The signature of the edit covers the RFP links of the proposal
+	pdr, err := client.ProposalDetails(token, nil)
 	if err != nil {
 		return err
 	}
@@ -98,8 +104,9 @@ func (cmd *EditProposalCmd) Execute(args []string) error {
 		files = append(files, f)
 	}
 
-	// Compute merkle root and sign it
-	sig, err := signedMerkleRoot(files, cfg.Identity)
+	// Compute merkle root and sign it along with the RFP links
+	sig, err := signedProposal(files, pdr.Proposal.LinkTo,
+		pdr.Proposal.LinkBy, cfg.Identity)
 	if err != nil {
 		return fmt.Errorf("SignMerkleRoot: %v", err)
 	}
@@ -113,7 +120,7 @@ func (cmd *EditProposalCmd) Execute(args []string) error {
 	}
 
 	// Print request details
-	err = printJSON(ep)
+	err = printRequestJSON(ep)
 	if err != nil {
 		return err
 	}
@@ -125,7 +132,7 @@ func (cmd *EditProposalCmd) Execute(args []string) error {
 	}
 
 	// Verify proposal censorship record
-	err = verifyProposal(epr.Proposal, vr.PubKey)
+	err = client.VerifyProposal(epr.Proposal)
 	if err != nil {
 		return fmt.Errorf("unable to verify proposal %v: %v",
 			epr.Proposal.CensorshipRecord.Token, err)
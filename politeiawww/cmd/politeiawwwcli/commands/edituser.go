@@ -16,7 +16,10 @@ import (
 type EditUserCmd struct {
 	Args struct {
 		NotifType string `long:"emailnotifications"` // Email notification bit field
-	} `positional-args:"true" required:"true"`
+	} `positional-args:"true"`
+
+	// Locale is the locale that emails are sent to the user in
+	Locale *string `long:"locale" optional:"true"`
 }
 
 // Execute executes the edit user command.
@@ -33,9 +36,16 @@ func (cmd *EditUserCmd) Execute(args []string) error {
 		"commentoncomment":          v1.NotificationEmailCommentOnMyComment,
 	}
 
+	if cmd.Args.NotifType == "" && cmd.Locale == nil {
+		return fmt.Errorf("Invalid edituser option. Type 'help edituser' " +
+			"for list of valid options")
+	}
+
 	var notif v1.EmailNotificationT
 	a, err := strconv.ParseUint(cmd.Args.NotifType, 10, 64)
-	if err == nil {
+	if cmd.Args.NotifType == "" {
+		// Only the locale is edited
+	} else if err == nil {
 		// Numeric action code found
 		notif = v1.EmailNotificationT(a)
 	} else if a, ok := emailNotifs[cmd.Args.NotifType]; ok {
@@ -64,13 +74,16 @@ func (cmd *EditUserCmd) Execute(args []string) error {
 	}
 
 	// Setup request
-	helper := uint64(notif)
 	eu := &v1.EditUser{
-		EmailNotifications: &helper,
+		Locale: cmd.Locale,
+	}
+	if cmd.Args.NotifType != "" {
+		helper := uint64(notif)
+		eu.EmailNotifications = &helper
 	}
 
 	// Print request details
-	err = printJSON(eu)
+	err = printRequestJSON(eu)
 	if err != nil {
 		return err
 	}
@@ -87,12 +100,17 @@ func (cmd *EditUserCmd) Execute(args []string) error {
 
 // editUserHelpMsg is the output of the help command when 'edituser' is
 // specified.
-const editUserHelpMsg = `edituser "emailnotifications"
+const editUserHelpMsg = `edituser [flags] "emailnotifications"
 
 Edit user settings for the logged in user.
  
 Arguments:
-1. emailnotifications       (string, required)   Email notification bit field
+1. emailnotifications       (string, optional)   Email notification bit field
+
+Flags:
+  --locale                  (string, optional)   Locale that emails are sent
+                                                 in, e.g. pt-BR; an empty
+                                                 locale resets it to English
 
 Valid options are:
 
@@ -109,6 +127,7 @@ Valid options are:
 Request:
 {
   "emailnotifications":  (uint64)  Bit field
+  "locale":              (string)  Locale that emails are sent in
 }
 
 Response:
@@ -0,0 +1,44 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// FundingStatusCmd retrieves how much of the budget of an approved proposal
+// has been disbursed.
+type FundingStatusCmd struct {
+	Args struct {
+		Token string `positional-arg-name:"token" required:"true"` // Censorship token
+	} `positional-args:"true"`
+}
+
+// Execute executes the funding status command.
+func (cmd *FundingStatusCmd) Execute(args []string) error {
+	fs, err := client.ProposalFundingStatus(cmd.Args.Token)
+	if err != nil {
+		return err
+	}
+	return printJSON(fs)
+}
+
+// fundingStatusHelpMsg is the output for the help command when
+// 'fundingstatus' is specified.
+const fundingStatusHelpMsg = `fundingstatus "token"
+
+Get the funding status of an approved proposal.  The funding status is computed
+from the approved budget of the proposal and the spends that were recorded
+against it.  Proposals that have not been given a budget are reported with
+budgeted set to false.  Amounts are in US cents.
+
+Arguments:
+1. token      (string, required)   Censorship token
+
+Result:
+{
+  "token":       (string)   Censorship token
+  "budgeted":    (bool)     Whether a budget has been set
+  "requested":   (uint64)   Approved budget
+  "spent":       (uint64)   Total amount disbursed
+  "remaining":   (int64)    Requested minus spent; negative when overspent
+  "percentage":  (float64)  Percentage of the budget that has been spent
+}`
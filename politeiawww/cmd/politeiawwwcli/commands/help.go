@@ -28,6 +28,8 @@ func (cmd *HelpCmd) Execute(args []string) error {
 		fmt.Printf("%s\n", logoutHelpMsg)
 	case "authorizevote":
 		fmt.Printf("%s\n", authorizeVoteHelpMsg)
+	case "abandonproposal":
+		fmt.Printf("%s\n", abandonProposalHelpMsg)
 	case "newuser":
 		fmt.Printf("%s\n", newUserHelpMsg)
 	case "newproposal":
@@ -36,12 +38,30 @@ func (cmd *HelpCmd) Execute(args []string) error {
 		fmt.Printf("%s\n", changePasswordHelpMsg)
 	case "changeusername":
 		fmt.Printf("%s\n", changeUsernameHelpMsg)
+	case "changeemail":
+		fmt.Printf("%s\n", changeEmailHelpMsg)
+	case "verifychangeemail":
+		fmt.Printf("%s\n", verifyChangeEmailHelpMsg)
 	case "sendfaucettx":
 		fmt.Printf("%s\n", sendFaucetTxHelpMsg)
 	case "userdetails":
 		fmt.Printf("%s\n", userDetailsHelpMsg)
+	case "batchuserdetails":
+		fmt.Printf("%s\n", batchUserDetailsHelpMsg)
+	case "commentcounts":
+		fmt.Printf("%s\n", commentCountsHelpMsg)
 	case "proposaldetails":
 		fmt.Printf("%s\n", proposalDetailsHelpMsg)
+	case "fundingstatus":
+		fmt.Printf("%s\n", fundingStatusHelpMsg)
+	case "verifyintegrity":
+		fmt.Printf("%s\n", verifyIntegrityHelpMsg)
+	case "newdraft":
+		fmt.Printf("%s\n", newDraftHelpMsg)
+	case "userdrafts":
+		fmt.Printf("%s\n", userDraftsHelpMsg)
+	case "deletedraft":
+		fmt.Printf("%s\n", deleteDraftHelpMsg)
 	case "userproposals":
 		fmt.Printf("%s\n", userProposalsHelpMsg)
 	case "unvettedproposals":
@@ -54,10 +74,18 @@ func (cmd *HelpCmd) Execute(args []string) error {
 		fmt.Printf("%s\n", newCommentHelpMsg)
 	case "proposalcomments":
 		fmt.Printf("%s\n", proposalCommentsHelpMsg)
+	case "castballot":
+		fmt.Printf("%s\n", castBallotHelpMsg)
 	case "censorcomment":
 		fmt.Printf("%s\n", censorCommentHelpMsg)
+	case "deletecomment":
+		fmt.Printf("%s\n", deleteCommentHelpMsg)
+	case "editcomment":
+		fmt.Printf("%s\n", editCommentHelpMsg)
 	case "likecomment":
 		fmt.Printf("%s\n", likeCommentHelpMsg)
+	case "reactcomment":
+		fmt.Printf("%s\n", reactCommentHelpMsg)
 	case "editproposal":
 		fmt.Printf("%s\n", editProposalHelpMsg)
 	case "manageuser":
@@ -84,8 +112,24 @@ func (cmd *HelpCmd) Execute(args []string) error {
 		fmt.Printf("%s\n", userPendingPaymentHelpMsg)
 	case "proposalpaywall":
 		fmt.Printf("%s\n", proposalPaywallHelpMsg)
+	case "searchproposals":
+		fmt.Printf("%s\n", searchProposalsHelpMsg)
 	case "rescanuserpayments":
 		fmt.Printf("%s\n", rescanUserPaymentsHelpMsg)
+	case "rescanstatus":
+		fmt.Printf("%s\n", rescanStatusHelpMsg)
+	case "purgeunverified":
+		fmt.Printf("%s\n", purgeUnverifiedHelpMsg)
+	case "userlogoutall":
+		fmt.Printf("%s\n", userLogoutAllHelpMsg)
+	case "impersonateuser":
+		fmt.Printf("%s\n", impersonateUserHelpMsg)
+	case "newapitoken":
+		fmt.Printf("%s\n", newAPITokenHelpMsg)
+	case "apitokens":
+		fmt.Printf("%s\n", apiTokensHelpMsg)
+	case "revokeapitoken":
+		fmt.Printf("%s\n", revokeAPITokenHelpMsg)
 	case "verifyuserpayment":
 		fmt.Printf("%s\n", verifyUserPaymentHelpMsg)
 	case "startvote":
@@ -100,12 +144,22 @@ func (cmd *HelpCmd) Execute(args []string) error {
 		fmt.Printf("%s\n", userLikeCommentsHelpMsg)
 	case "activevotes":
 		fmt.Printf("%s\n", activeVotesHelpMsg)
+	case "voteoptions":
+		fmt.Printf("%s\n", voteOptionsHelpMsg)
 	case "votestatus":
 		fmt.Printf("%s\n", voteStatusHelpMsg)
+	case "batchvotestatus":
+		fmt.Printf("%s\n", batchVoteStatusHelpMsg)
+	case "blockheight":
+		fmt.Printf("%s\n", blockHeightHelpMsg)
 	case "votestatuses":
 		fmt.Printf("%s\n", voteStatusesHelpMsg)
 	case "proposalstats":
 		fmt.Printf("%s\n", proposalStatsHelpMsg)
+	case "statshistory":
+		fmt.Printf("%s\n", statsHistoryHelpMsg)
+	case "watchinventory":
+		fmt.Printf("%s\n", watchInventoryHelpMsg)
 	case "vote":
 		fmt.Printf("%s\n", voteHelpMsg)
 	case "testrun":
@@ -0,0 +1,85 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// impersonationWarning is printed before and after an impersonation session is
+// started so that the admin can not miss that the CLI is acting as another
+// user.
+const impersonationWarning = `
+********************************************************************************
+WARNING: you are about to act as another user.  Your admin session is replaced
+by a read-only impersonation session and every request is logged along with
+your identity.  Run 'logout' to end the impersonation and 'login' to log back
+in as yourself.
+********************************************************************************
+`
+
+// ImpersonateUserCmd starts an impersonation session for the specified user.
+type ImpersonateUserCmd struct {
+	Args struct {
+		UserID string `positional-arg-name:"userid"` // User ID
+		Reason string `positional-arg-name:"reason"` // Reason for impersonation
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the impersonate user command.
+func (cmd *ImpersonateUserCmd) Execute(args []string) error {
+	fmt.Fprint(os.Stderr, impersonationWarning)
+
+	iu := &v1.ImpersonateUser{
+		UserID: cmd.Args.UserID,
+		Reason: cmd.Args.Reason,
+	}
+
+	err := printRequestJSON(iu)
+	if err != nil {
+		return err
+	}
+
+	iur, err := client.ImpersonateUser(iu)
+	if err != nil {
+		return err
+	}
+
+	// Update the logged in username that we store on disk
+	err = cfg.SaveLoggedInUsername(iur.User.Username)
+	if err != nil {
+		return fmt.Errorf("SaveLoggedInUsername: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "WARNING: now impersonating user %v until %v\n",
+		iur.User.Username, time.Unix(iur.ExpiresAt, 0).Format(time.RFC1123))
+
+	return printJSON(iur)
+}
+
+// impersonateUserHelpMsg is the output of the help command when
+// 'impersonateuser' is specified.
+var impersonateUserHelpMsg = `impersonateuser "userid" "reason"
+
+Act as a user for support purposes.  The admin session is replaced by a
+read-only impersonation session for the user that expires after a short,
+fixed amount of time.  Only requests that do not modify anything are allowed.
+The impersonation and every request made during it are logged along with the
+admin.  Admins and deactivated users can not be impersonated.  Use logout to
+end the impersonation.  Requires admin privileges.
+
+Arguments:
+1. userid        (string, required)   User id
+2. reason        (string, required)   Reason for the impersonation
+
+Result:
+{
+  "user"         (LoginReply)  Details of the impersonated user
+  "expiresat"    (int64)       Unix timestamp of the session expiry
+}`
@@ -60,7 +60,7 @@ func (cmd *LikeCommentCmd) Execute(args []string) error {
 	}
 
 	// Print request details
-	err := printJSON(lc)
+	err := printRequestJSON(lc)
 	if err != nil {
 		return err
 	}
@@ -36,7 +36,7 @@ func (cmd *LoginCmd) Execute(args []string) error {
 	}
 
 	// Print request details
-	err = printJSON(l)
+	err = printRequestJSON(l)
 	if err != nil {
 		return err
 	}
@@ -62,7 +62,7 @@ func (cmd *ManageUserCmd) Execute(args []string) error {
 	}
 
 	// Print request details
-	err = printJSON(mu)
+	err = printRequestJSON(mu)
 	if err != nil {
 		return err
 	}
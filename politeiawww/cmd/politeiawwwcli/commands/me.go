@@ -4,16 +4,38 @@
 
 package commands
 
+import (
+	"fmt"
+	"os"
+)
+
 // MeCmd gets the user details of the logged in user.
-type MeCmd struct{}
+type MeCmd struct {
+	Verify bool `long:"verify" optional:"true"` // Verify the local identity
+}
 
 // Execute executes the me command.
 func (cmd *MeCmd) Execute(args []string) error {
-	lr, err := client.Me()
+	if !cmd.Verify {
+		lr, err := client.Me()
+		if err != nil {
+			return err
+		}
+		if lr.ImpersonatedBy != "" {
+			fmt.Fprintf(os.Stderr, "WARNING: this is an impersonation "+
+				"session started by admin %v\n", lr.ImpersonatedBy)
+		}
+		return printJSON(lr)
+	}
+
+	sv, err := client.VerifySession()
 	if err != nil {
 		return err
 	}
-	return printJSON(lr)
+	if !sv.KeyMatches {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", sv.Discrepancy)
+	}
+	return printJSON(sv)
 }
 
 // meHelpMsg is the output of the help command when 'me' is specified.
@@ -24,6 +46,12 @@ Fetch details for the currently logged in user.
 Arguments:
 None
 
+Flags:
+  --verify   (bool, optional)   Verify that the local identity is the active
+                                identity of the user.  The user details are
+                                returned in the user field of the result, next
+                                to the verification result.
+
 Response:
 {
   "isadmin":                 (bool)        Is user an admin
@@ -38,4 +66,15 @@ Response:
   "proposalcredits":         (uint64)      Proposal credits available to spend
   "lastlogintime":           (int64)       Unix timestamp of last login date
   "sessionmaxage":           (int64)       Unix timestamp of session max age
+  "impersonatedby":          (string)      Admin user id if the session is an
+                                           impersonation session
+}
+
+Response (--verify):
+{
+  "user":                    (object)      User details as listed above
+  "localpublickey":          (string)      Public key of the local identity
+  "serverpublickey":         (string)      Active public key of the user
+  "keymatches":              (bool)        Whether the public keys match
+  "discrepancy":             (string)      Why the public keys do not match
 }`
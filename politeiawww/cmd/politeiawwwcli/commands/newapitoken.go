@@ -0,0 +1,89 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// NewAPITokenCmd issues an API token for the specified user.
+type NewAPITokenCmd struct {
+	Args struct {
+		UserID      string `positional-arg-name:"userid"`      // User ID
+		Scope       string `positional-arg-name:"scope"`       // Token scope
+		Description string `positional-arg-name:"description"` // Token description
+	} `positional-args:"true"`
+}
+
+// Execute executes the new API token command.
+func (cmd *NewAPITokenCmd) Execute(args []string) error {
+	if cmd.Args.UserID == "" || cmd.Args.Scope == "" {
+		return fmt.Errorf("userid and scope are required")
+	}
+
+	// Parse the scope
+	var scope v1.APITokenScopeT
+	for k, v := range v1.APITokenScope {
+		if k != v1.APITokenScopeInvalid && v == cmd.Args.Scope {
+			scope = k
+			break
+		}
+	}
+	if scope == v1.APITokenScopeInvalid {
+		return fmt.Errorf("invalid scope %q; must be read, submit or admin",
+			cmd.Args.Scope)
+	}
+
+	nat := &v1.NewAPIToken{
+		UserID:      cmd.Args.UserID,
+		Scope:       scope,
+		Description: cmd.Args.Description,
+	}
+
+	err := printRequestJSON(nat)
+	if err != nil {
+		return err
+	}
+
+	natr, err := client.NewAPIToken(nat)
+	if err != nil {
+		return err
+	}
+	return printJSON(natr)
+}
+
+// newAPITokenHelpMsg is the output of the help command when 'newapitoken' is
+// specified.
+var newAPITokenHelpMsg = `newapitoken "userid" "scope" "description"
+
+Issue a long-lived API token for a user.  The token authenticates requests as
+the user without logging in; set it using the apitoken config option.  The
+scope limits the requests that the token is allowed to make:
+
+  read    Only requests that fetch data
+  submit  All user requests; can only be issued for your own account
+  admin   All user and admin requests; can only be issued for your own account
+
+The token is only returned once.  Requires admin privileges.
+
+Arguments:
+1. userid        (string, required)   User id
+2. scope         (string, required)   Token scope: read, submit or admin
+3. description   (string, optional)   What the token is used for
+
+Result:
+{
+  "apitoken": {
+    "id":           (string)  API token id
+    "userid":       (string)  User id
+    "scope":        (int)     Token scope
+    "description":  (string)  What the token is used for
+    "createdby":    (string)  Id of the admin that issued the token
+    "timestamp":    (int64)   Unix timestamp of when the token was issued
+  },
+  "token":          (string)  Token to send in the Authorization header
+}`
@@ -4,12 +4,6 @@
 
 package commands
 
-import (
-	"encoding/hex"
-
-	"github.com/decred/politeia/politeiawww/api/v1"
-)
-
 // NewCommentCmd submits a new proposal comment.
 type NewCommentCmd struct {
 	Args struct {
@@ -17,6 +11,7 @@ type NewCommentCmd struct {
 		Comment  string `positional-arg-name:"comment" required:"true"` // Comment text
 		ParentID string `positional-arg-name:"parentID"`                // Comment parent ID
 	} `positional-args:"true"`
+	Attachments []string `long:"attach" optional:"true"` // Files to attach
 }
 
 // Execute executes the new comment command.
@@ -30,24 +25,10 @@ func (cmd *NewCommentCmd) Execute(args []string) error {
 		return errUserIdentityNotFound
 	}
 
-	// Setup new comment request
-	sig := cfg.Identity.SignMessage([]byte(token + parentID + comment))
-	nc := &v1.NewComment{
-		Token:     token,
-		ParentID:  parentID,
-		Comment:   comment,
-		Signature: hex.EncodeToString(sig[:]),
-		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
-	}
-
-	// Print request details
-	err := printJSON(nc)
-	if err != nil {
-		return err
-	}
-
-	// Send request
-	ncr, err := client.NewComment(nc)
+	// Send request.  The client encodes the attachments, checks them
+	// against the server policy and signs the comment.
+	ncr, err := client.NewCommentWithAttachments(token, parentID, comment,
+		cmd.Attachments)
 	if err != nil {
 		return err
 	}
@@ -58,7 +39,7 @@ func (cmd *NewCommentCmd) Execute(args []string) error {
 
 // newCommentHelpMsg is the output of the help command when 'newcomment' is
 // specified.
-const newCommentHelpMsg = `newcomment "token" "comment"
+const newCommentHelpMsg = `newcomment [flags] "token" "comment"
 
 Comment on proposal as logged in user. 
 
@@ -67,13 +48,18 @@ Arguments:
 2. comment     (string, required)   Comment
 3. parentID    (string, required if replying to comment)  Id of commment
 
+Flags:
+  --attach     (string, optional)   Path to a file to attach to the comment.
+                                    Can be repeated up to the policy maximum.
+
 Request:
 {
   "token":       (string)  Censorship token
   "parentid":    (string)  Id of comment (defaults to '0' (top-level comment))
   "comment":     (string)  Comment
-  "signature":   (string)  Signature of comment (token+parentID+comment)
+  "signature":   (string)  Signature of token+parentID+comment+file digests
   "publickey":   (string)  Public key of user commenting
+  "files":       ([]File)  Attached files
 }
 
 Response:
@@ -82,8 +68,9 @@ Response:
     "token":        (string)  Censorship token
     "parentid":     (string)  Id of comment (defaults to '0' (top-level))
     "comment":      (string)  Comment
-    "signature":    (string)  Signature of token+parentID+comment
+    "signature":    (string)  Signature of token+parentID+comment+file digests
     "publickey":    (string)  Public key of user 
+    "files":        ([]File)  Attached files
     "commentid":    (string)  Id of the comment
     "receipt":      (string)  Server signature of the comment signature
     "timestamp":    (int64)   Received UNIX timestamp
@@ -0,0 +1,57 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// NewDraftCmd saves the files in a directory as a proposal draft.
+type NewDraftCmd struct {
+	Args struct {
+		Dir string `positional-arg-name:"dir"` // Proposal directory
+	} `positional-args:"true" required:"true"`
+	DraftID string `long:"draftid" optional:"true"` // Draft to update
+}
+
+// Execute executes the new draft command.
+func (cmd *NewDraftCmd) Execute(args []string) error {
+	if cmd.DraftID == "" {
+		ndr, err := client.NewDraftFromDir(cmd.Args.Dir)
+		if err != nil {
+			return err
+		}
+		return printJSON(ndr)
+	}
+
+	edr, err := client.EditDraftFromDir(cmd.DraftID, cmd.Args.Dir)
+	if err != nil {
+		return err
+	}
+	return printJSON(edr)
+}
+
+// newDraftHelpMsg is the output of the help command when 'newdraft' is
+// specified.
+const newDraftHelpMsg = `newdraft "dir"
+
+Save the files in a proposal directory as a private draft of the logged in
+user.  The directory is read the same way as by newproposal.  Drafts are not
+signed or anchored and can only be seen by their author.  Use newproposal to
+submit the proposal once it is done.
+
+Arguments:
+1. dir         (string, required)   Proposal directory
+
+Flags:
+  --draftid    (string, optional)   Replace the files of an existing draft
+
+Result:
+{
+  "draft": {
+    "draftid":     (string)  Unique draft id
+    "name":        (string)  Proposal name, empty without an index file
+    "numfiles":    (int)     Number of files
+    "size":        (int64)   Size of the decoded files in bytes
+    "timestamp":   (int64)   UNIX timestamp of creation
+    "updated":     (int64)   UNIX timestamp of the last update
+  }
+}`
@@ -19,7 +19,9 @@ type NewProposalCmd struct {
 		Markdown    string   `positional-arg-name:"markdownfile"`    // Proposal MD file
 		Attachments []string `positional-arg-name:"attachmentfiles"` // Proposal attachment files
 	} `positional-args:"true" optional:"true"`
-	Random bool `long:"random" optional:"true"` // Generate random proposal data
+	Random bool   `long:"random" optional:"true"` // Generate random proposal data
+	LinkTo string `long:"linkto" optional:"true"` // Token of the RFP to submit to
+	LinkBy int64  `long:"linkby" optional:"true"` // RFP submission deadline
 }
 
 // Execute executes the new proposal command.
@@ -37,11 +39,19 @@ func (cmd *NewProposalCmd) Execute(args []string) error {
 	}
 
 	// Get server public key
-	vr, err := client.Version()
+	_, err := client.ServerPublicKey()
 	if err != nil {
 		return err
 	}
 
+	// Ensure the RFP accepts submissions before the proposal is signed
+	if cmd.LinkTo != "" {
+		err = client.CheckRFPLink(cmd.LinkTo)
+		if err != nil {
+			return err
+		}
+	}
+
 	var md []byte
 	files := make([]v1.File, 0, v1.PolicyMaxImages+1)
 	if cmd.Random {
@@ -96,8 +106,8 @@ func (cmd *NewProposalCmd) Execute(args []string) error {
 		files = append(files, f)
 	}
 
-	// Compute merkle root and sign it
-	sig, err := signedMerkleRoot(files, cfg.Identity)
+	// Compute merkle root and sign it along with the RFP links
+	sig, err := signedProposal(files, cmd.LinkTo, cmd.LinkBy, cfg.Identity)
 	if err != nil {
 		return fmt.Errorf("SignMerkleRoot: %v", err)
 	}
@@ -107,10 +117,12 @@ func (cmd *NewProposalCmd) Execute(args []string) error {
 		Files:     files,
 		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
 		Signature: sig,
+		LinkTo:    cmd.LinkTo,
+		LinkBy:    cmd.LinkBy,
 	}
 
 	// Print request details
-	err = printJSON(np)
+	err = printRequestJSON(np)
 	if err != nil {
 		return err
 	}
@@ -126,9 +138,11 @@ func (cmd *NewProposalCmd) Execute(args []string) error {
 		Files:            np.Files,
 		PublicKey:        np.PublicKey,
 		Signature:        np.Signature,
+		LinkTo:           np.LinkTo,
+		LinkBy:           np.LinkBy,
 		CensorshipRecord: npr.CensorshipRecord,
 	}
-	err = verifyProposal(pr, vr.PubKey)
+	err = client.VerifyProposal(pr)
 	if err != nil {
 		return fmt.Errorf("unable to verify proposal %v: %v",
 			pr.CensorshipRecord.Token, err)
@@ -149,6 +163,12 @@ Arguments:
 
 Flags:
   --random           (bool, optional)     Generate a random proposal
+  --linkto           (string, optional)   Token of the RFP that the proposal
+                                          is submitted to. The RFP must be
+                                          public and accept submissions.
+  --linkby           (int64, optional)    Unix timestamp of the submission
+                                          deadline. Makes the proposal an RFP;
+                                          requires admin privileges.
 
 Result:
 {
@@ -162,4 +182,6 @@ Result:
   ],
   "publickey":   (string)  Public key of user
   "signature":   (string)  Signed merkel root of files in proposal 
+  "linkto":      (string)  Token of the linked RFP 
+  "linkby":      (int64)   RFP submission deadline 
 }`
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/decred/politeia/politeiawww/api/v1"
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
 	"github.com/decred/politeia/util"
 )
 
@@ -19,6 +20,9 @@ type NewUserCmd struct {
 	Paywall bool `long:"paywall" optional:"true"` // Use faucet to pay paywall (tesnet only)
 	Verify  bool `long:"verify" optional:"true"`  // Verify user email address (testnet only)
 	NoSave  bool `long:"nosave" optional:"true"`  // Don't save user identity to disk
+
+	// Locale is the locale that emails are sent to the user in
+	Locale string `long:"locale" optional:"true"`
 }
 
 // Execute executes the new user command.
@@ -57,9 +61,9 @@ func (cmd *NewUserCmd) Execute(args []string) error {
 	}
 
 	// Validate password
-	if uint(len(password)) < pr.MinPasswordLength {
-		return fmt.Errorf("password must be %v characters long",
-			pr.MinPasswordLength)
+	err = wwwclient.ValidatePassword(password, pr)
+	if err != nil {
+		return err
 	}
 
 	// Create user identity and save it to disk
@@ -68,16 +72,18 @@ func (cmd *NewUserCmd) Execute(args []string) error {
 		return err
 	}
 
-	// Setup new user request
+	// Setup new user request.  The password is sent in plain text so that
+	// politeiawww can enforce the password policy.
 	nu := &v1.NewUser{
 		Email:     email,
 		Username:  username,
-		Password:  digestSHA3(password),
+		Password:  password,
 		PublicKey: hex.EncodeToString(id.Public.Key[:]),
+		Locale:    cmd.Locale,
 	}
 
 	// Print request details
-	err = printJSON(nu)
+	err = printRequestJSON(nu)
 	if err != nil {
 		return err
 	}
@@ -104,12 +110,7 @@ func (cmd *NewUserCmd) Execute(args []string) error {
 
 	// Verify user's email address
 	if cmd.Verify {
-		sig := id.SignMessage([]byte(nur.VerificationToken))
-		vnur, err := client.VerifyNewUser(&v1.VerifyNewUser{
-			Email:             email,
-			VerificationToken: nur.VerificationToken,
-			Signature:         hex.EncodeToString(sig[:]),
-		})
+		vnur, err := client.TestAutoVerify(email, id, nur)
 		if err != nil {
 			return fmt.Errorf("VerifyNewUser: %v", err)
 		}
@@ -163,6 +164,7 @@ Flags:
   --paywall   (bool, optional)   Satisfy the paywall fee using testnet faucet
   --verify    (bool, optional)   Verify the user's email address
   --nosave    (bool, optional)   Do not save the user identity to disk 
+  --locale    (string, optional) Locale that emails are sent in, e.g. pt-BR
 
 Request:
 {
@@ -170,6 +172,7 @@ Request:
   "password":   (string)  Password
   "publickey":  (string)  Active public key
   "username":   (string)  Username
+  "locale":     (string)  Locale that emails are sent in
 }
 
 Response:
@@ -4,16 +4,41 @@
 
 package commands
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
+)
+
 // ProposalCommentsCmd retreives the comments for the specified proposal.
 type ProposalCommentsCmd struct {
 	Args struct {
 		Token string `positional-arg-name:"token"` // Censorship token
 	} `positional-args:"true" required:"true"`
+	Sort  string `long:"sort" optional:"true" choice:"newest" choice:"oldest" choice:"top" choice:"controversial"` // Comment sort order
+	Since int64  `long:"since" optional:"true"`                                                                    // UNIX timestamp
 }
 
 // Execute executes the proposal comments command.
 func (cmd *ProposalCommentsCmd) Execute(args []string) error {
-	gcr, err := client.GetComments(cmd.Args.Token)
+	var (
+		gcr *v1.GetCommentsReply
+		err error
+	)
+	switch {
+	case cmd.Since != 0 && cmd.Sort != "":
+		return fmt.Errorf("--since and --sort cannot be used together")
+	case cmd.Since != 0:
+		gcr, err = client.GetCommentsSince(cmd.Args.Token,
+			time.Unix(cmd.Since, 0))
+	case cmd.Sort != "":
+		gcr, err = client.GetCommentsSorted(cmd.Args.Token,
+			wwwclient.CommentSortT(cmd.Sort))
+	default:
+		gcr, err = client.GetComments(cmd.Args.Token)
+	}
 	if err != nil {
 		return err
 	}
@@ -29,6 +54,22 @@ Get the comments for a proposal.
 Arguments:
 1. token       (string, required)   Proposal censorship token
 
+Flags:
+  --sort       (string, optional)   Sort the comments client side.  Replies
+                                    are not kept next to their parent.
+                                    newest:         Newest first
+                                    oldest:         Oldest first
+                                    top:            Highest vote score first
+                                    controversial:  Most evenly split votes
+                                                    first; the controversy is
+                                                    (up+down)^(min/max) and is
+                                                    zero without both upvotes
+                                                    and downvotes
+  --since      (int64, optional)    Only get the comments that were created
+                                    or edited after this UNIX timestamp.
+                                    Censorship and vote changes are not
+                                    included.  Can not be used with --sort
+
 Result:
 {
   "comments": [
@@ -44,8 +85,14 @@ Result:
       "totalvotes":   (uint64)  Total number of up/down votes
       "resultvotes":  (int64)   Vote score
       "censored":     (bool)    If comment has been censored
+      "edited":       (int64)   UNIX timestamp of last edit, 0 if never
+                                edited
+      "deleted":      (int64)   UNIX timestamp the author deleted the
+                                comment, 0 if not deleted
       "userid":       (string)  User id
       "username":     (string)  Username
+      "reactions":    (map)     Number of users per reaction type, omitted
+                                when there are no reactions
     }
   ]
 }`
@@ -20,12 +20,6 @@ type ProposalDetailsCmd struct {
 
 // Execute executes the proposal details command.
 func (cmd *ProposalDetailsCmd) Execute(args []string) error {
-	// Get server's public key
-	vr, err := client.Version()
-	if err != nil {
-		return err
-	}
-
 	// Get proposal
 	pdr, err := client.ProposalDetails(cmd.Args.Token,
 		&v1.ProposalsDetails{
@@ -36,7 +30,7 @@ func (cmd *ProposalDetailsCmd) Execute(args []string) error {
 	}
 
 	// Verify proposal censorship record
-	err = verifyProposal(pdr.Proposal, vr.PubKey)
+	err = client.VerifyProposal(pdr.Proposal)
 	if err != nil {
 		return fmt.Errorf("unable to verify proposal %v: %v",
 			pdr.Proposal.CensorshipRecord.Token, err)
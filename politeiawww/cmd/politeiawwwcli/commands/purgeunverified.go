@@ -0,0 +1,41 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// PurgeUnverifiedCmd deletes the users that never verified their email
+// address.
+type PurgeUnverifiedCmd struct{}
+
+// Execute executes the purge unverified command.
+func (cmd *PurgeUnverifiedCmd) Execute(args []string) error {
+	puur, err := client.PurgeUnverifiedUsers()
+	if err != nil {
+		return err
+	}
+	return printJSON(puur)
+}
+
+// purgeUnverifiedHelpMsg is the output of the help command when
+// 'purgeunverified' is specified.
+var purgeUnverifiedHelpMsg = `purgeunverified
+
+Permanently delete the users that never verified their email address and whose
+verification token expired more than the purgeunverifiedage of politeiawww
+ago.  Users that submitted proposals or comments, made a payment or are admins
+are never deleted.  The email address and username of a deleted user can be
+used to register again.  Requires admin privileges.
+
+Arguments: None
+
+Result:
+{
+  "users": [
+    {
+      "id":        (string)  User id
+      "email":     (string)  User email address
+      "username":  (string)  Username
+    }
+  ]
+}`
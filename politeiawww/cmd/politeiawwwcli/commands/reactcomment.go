@@ -0,0 +1,112 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// ReactCommentCmd adds or removes a reaction to a proposal comment.
+type ReactCommentCmd struct {
+	Args struct {
+		Token     string `positional-arg-name:"token"`     // Censorship token
+		CommentID string `positional-arg-name:"commentID"` // Comment ID
+		Reaction  string `positional-arg-name:"reaction"`  // Reaction type
+	} `positional-args:"true" required:"true"`
+	Remove bool `long:"remove" optional:"true"` // Remove the reaction
+}
+
+// Execute executes the react comment command.
+func (cmd *ReactCommentCmd) Execute(args []string) error {
+	token := cmd.Args.Token
+	commentID := cmd.Args.CommentID
+	reaction := cmd.Args.Reaction
+
+	action := v1.ReactionActionAdd
+	if cmd.Remove {
+		action = v1.ReactionActionRemove
+	}
+
+	// Check for user identity
+	if cfg.Identity == nil {
+		return errUserIdentityNotFound
+	}
+
+	// Get server public key
+	_, err := client.ServerPublicKey()
+	if err != nil {
+		return err
+	}
+
+	// Setup react comment request
+	s := cfg.Identity.SignMessage([]byte(token + commentID + reaction +
+		action))
+	signature := hex.EncodeToString(s[:])
+	rc := &v1.ReactComment{
+		Token:     token,
+		CommentID: commentID,
+		Reaction:  reaction,
+		Action:    action,
+		Signature: signature,
+		PublicKey: hex.EncodeToString(cfg.Identity.Public.Key[:]),
+	}
+
+	// Print request details
+	err = printRequestJSON(rc)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	rcr, err := client.ReactToComment(rc)
+	if err != nil {
+		return err
+	}
+
+	// Validate react comment receipt
+	err = client.VerifyServerSignature(signature, rcr.Receipt)
+	if err != nil {
+		return fmt.Errorf("could not verify receipt signature: %v", err)
+	}
+
+	// Print response details
+	return printJSON(rcr)
+}
+
+// reactCommentHelpMsg is the output of the help command when 'reactcomment'
+// is specified.
+const reactCommentHelpMsg = `reactcomment "token" "commentID" "reaction"
+
+Add a reaction to a comment or, with --remove, remove it.  The reaction must
+be one of the comment reactions of the policy command.  Use likecomment to
+upvote or downvote a comment; up and down can not be used as reactions.
+
+Arguments:
+1. token       (string, required)   Proposal censorship token
+2. commentID   (string, required)   Id of the comment
+3. reaction    (string, required)   Reaction type
+
+Flags:
+  --remove     (bool, optional)     Remove the reaction instead of adding it
+
+Request:
+{
+  "token":      (string)  Censorship token
+  "commentid":  (string)  Id of comment
+  "reaction":   (string)  Reaction type
+  "action":     (string)  add or remove
+  "signature":  (string)  Signature of react comment
+                          (Token+CommentID+Reaction+Action)
+  "publickey":  (string)  Public key used for signature
+}
+
+Response:
+{
+  "reactions":  (map)     Number of users per reaction type
+  "receipt":    (string)  Server signature of the react comment signature
+}`
@@ -0,0 +1,52 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// RescanStatusCmd retrieves the progress of a user payments rescan.
+type RescanStatusCmd struct {
+	Args struct {
+		JobID string `positional-arg-name:"jobid"` // Rescan job ID
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the rescan status command.
+func (cmd *RescanStatusCmd) Execute(args []string) error {
+	uprsr, err := client.RescanStatus(cmd.Args.JobID)
+	if err != nil {
+		return err
+	}
+	return printJSON(uprsr)
+}
+
+// rescanStatusHelpMsg is the output of the help command when 'rescanstatus'
+// is specified.
+const rescanStatusHelpMsg = `rescanstatus "jobid"
+
+Get the progress of a user payments rescan that was started with
+rescanuserpayments.  The status of a rescan is kept for an hour after it has
+ended.
+
+Rescan statuses:
+
+'1' - Rescan is in progress
+'2' - Rescan has completed
+'3' - Rescan has failed
+
+Arguments:
+1. jobid      (string, required)   Rescan job ID
+
+Result:
+{
+  "jobid":             (string)    Rescan job ID
+  "userid":            (string)    ID of the user being rescanned
+  "status":            (int)       Rescan status
+  "addressestotal":    (uint64)    Number of paywall addresses to scan
+  "addressesscanned":  (uint64)    Number of paywall addresses scanned
+  "paymentsfound":     (uint64)    Number of payments found so far
+  "newcredits":        ([]object)  Credits that were created by the rescan
+  "error":             (string)    Reason the rescan failed
+  "started":           (int64)     Start timestamp
+  "ended":             (int64)     End timestamp; 0 while running
+}`
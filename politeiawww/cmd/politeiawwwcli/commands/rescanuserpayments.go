@@ -4,7 +4,11 @@
 
 package commands
 
-import "github.com/decred/politeia/politeiawww/api/v1"
+import (
+	"context"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
 
 // RescanUserPaymentsCmd rescans the logged in user's paywall address and
 // makes sure that all payments have been credited to the user's account.
@@ -12,6 +16,7 @@ type RescanUserPaymentsCmd struct {
 	Args struct {
 		UserID string `positional-arg-name:"userid"` // User ID
 	} `positional-args:"true" required:"true"`
+	Wait bool `long:"wait" optional:"true"` // Wait for the rescan to end
 }
 
 // Execute executes the rescan user payments command.
@@ -20,7 +25,7 @@ func (cmd *RescanUserPaymentsCmd) Execute(args []string) error {
 		UserID: cmd.Args.UserID,
 	}
 
-	err := printJSON(upr)
+	err := printRequestJSON(upr)
 	if err != nil {
 		return err
 	}
@@ -29,20 +34,36 @@ func (cmd *RescanUserPaymentsCmd) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	if !cmd.Wait || uprr.JobID == "" {
+		return printJSON(uprr)
+	}
 
-	return printJSON(uprr)
+	uprsr, err := client.WaitForRescan(context.Background(), uprr.JobID, 0)
+	if err != nil {
+		return err
+	}
+	return printJSON(uprsr)
 }
 
 // rescanUserPaymentsHelpMsg is the output of the help command when
 // 'rescanuserpayments' is specified.
 var rescanUserPaymentsHelpMsg = `rescanuserpayments 
 
-Rescan user payments to check for missed payments.
+Rescan user payments to check for missed payments.  The rescan runs in the
+background on the server; use rescanstatus with the returned job ID to check
+its progress.  Starting a rescan of a user that is already being rescanned
+returns the job ID of the running rescan.  Payments are never credited twice.
 
 Arguments:
 1. userid        (string, required)   User id 
 
+Flags:
+  --wait    (bool, optional)    Wait until the rescan has ended and print its
+                                status.  The status is checked every
+                                paywallpollinterval and the progress of the
+                                rescan is printed each time it changes.
+
 Result:
 {
-  "newcredits"   ([]uint64)  Credits that were created by the rescan
+  "jobid"        (string)  Rescan job ID; empty when the paywall is disabled
 }`
@@ -5,9 +5,8 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/decred/politeia/politeiawww/api/v1"
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
 )
 
 // ResetPasswordCmd resets the password of the specified user.
@@ -30,9 +29,9 @@ func (cmd *ResetPasswordCmd) Execute(args []string) error {
 	}
 
 	// Validate new password
-	if uint(len(newPassword)) < pr.MinPasswordLength {
-		return fmt.Errorf("password must be %v characters long",
-			pr.MinPasswordLength)
+	err = wwwclient.ValidatePassword(newPassword, pr)
+	if err != nil {
+		return err
 	}
 
 	// The reset password command is special.  It must be called twice with
@@ -45,13 +44,14 @@ func (cmd *ResetPasswordCmd) Execute(args []string) error {
 	//
 	// politeiawwwcli assumes the email server is disabled.
 
-	// 1st reset password call
+	// 1st reset password call.  The new password is sent in plain text so
+	// that politeiawww can enforce the password policy.
 	rp := &v1.ResetPassword{
 		Email:       email,
-		NewPassword: digestSHA3(newPassword),
+		NewPassword: newPassword,
 	}
 
-	err = printJSON(rp)
+	err = printRequestJSON(rp)
 	if err != nil {
 		return err
 	}
@@ -66,14 +66,21 @@ func (cmd *ResetPasswordCmd) Execute(args []string) error {
 		return err
 	}
 
+	// Make sure the verification token can be used before submitting
+	// the new password
+	_, err = client.VerifyResetToken(email, rpr.VerificationToken)
+	if err != nil {
+		return err
+	}
+
 	// 2nd reset password call
 	rp = &v1.ResetPassword{
 		Email:             email,
-		NewPassword:       digestSHA3(newPassword),
+		NewPassword:       newPassword,
 		VerificationToken: rpr.VerificationToken,
 	}
 
-	err = printJSON(rp)
+	err = printRequestJSON(rp)
 	if err != nil {
 		return err
 	}
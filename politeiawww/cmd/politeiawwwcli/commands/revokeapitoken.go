@@ -0,0 +1,34 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// RevokeAPITokenCmd revokes an API token.
+type RevokeAPITokenCmd struct {
+	Args struct {
+		ID string `positional-arg-name:"id"` // API token ID
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the revoke API token command.
+func (cmd *RevokeAPITokenCmd) Execute(args []string) error {
+	ratr, err := client.RevokeAPIToken(cmd.Args.ID)
+	if err != nil {
+		return err
+	}
+	return printJSON(ratr)
+}
+
+// revokeAPITokenHelpMsg is the output of the help command when
+// 'revokeapitoken' is specified.
+var revokeAPITokenHelpMsg = `revokeapitoken "id"
+
+Revoke an API token.  Requests that carry the token are rejected immediately.
+Requires admin privileges.
+
+Arguments:
+1. id            (string, required)   API token id
+
+Result:
+{}`
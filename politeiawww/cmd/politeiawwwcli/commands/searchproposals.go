@@ -0,0 +1,69 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
+)
+
+// SearchProposalsCmd searches the vetted proposals by title and author.
+type SearchProposalsCmd struct {
+	Args struct {
+		Query string `positional-arg-name:"query"` // Search query
+	} `positional-args:"true" required:"true"`
+	TitleOnly  bool `long:"titleonly"`  // Only match proposal titles
+	AuthorOnly bool `long:"authoronly"` // Only match author usernames
+	NoFuzzy    bool `long:"nofuzzy"`    // Only return substring matches
+	Limit      int  `long:"limit"`      // Maximum number of results
+}
+
+// Execute executes the search proposals command.
+func (cmd *SearchProposalsCmd) Execute(args []string) error {
+	if cmd.TitleOnly && cmd.AuthorOnly {
+		return errInvalidTitleAuthorUsage
+	}
+
+	results, err := client.SearchProposals(cmd.Args.Query,
+		wwwclient.SearchOpts{
+			TitleOnly:  cmd.TitleOnly,
+			AuthorOnly: cmd.AuthorOnly,
+			NoFuzzy:    cmd.NoFuzzy,
+			Limit:      cmd.Limit,
+		})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(results)
+}
+
+// searchProposalsHelpMsg is the output for the help command when
+// 'searchproposals' is specified.
+const searchProposalsHelpMsg = `searchproposals [flags] "query"
+
+Search the vetted proposals by title and author username.  Results are ranked
+from best to worst match: exact, prefix, substring and finally fuzzy matches,
+where the query characters appear in order.
+
+The search is performed client side by fetching all vetted proposals so it
+may be slow when there are many proposals.  Unvetted proposals are not
+searched.
+
+Arguments:
+1. query          (string, required)   Search query
+
+Flags:
+  --titleonly     (bool, optional)     Only match against proposal titles
+  --authoronly    (bool, optional)     Only match against author usernames
+  --nofuzzy       (bool, optional)     Only return substring matches
+  --limit         (int, optional)      Maximum number of results
+
+Result:
+[
+  {
+    "proposal":   (ProposalRecord)  Matching proposal
+    "score":      (int)             Match score; higher is a better match
+  }
+]`
@@ -19,6 +19,7 @@ type SetProposalStatusCmd struct {
 		Status  string `positional-arg-name:"status" required:"true"` // New status
 		Message string `positional-arg-name:"message"`                // Change message
 	} `positional-args:"true"`
+	Force bool `long:"force" optional:"true"` // Skip the vote in progress check
 }
 
 // Execute executes the set proposal status command.
@@ -64,8 +65,18 @@ func (cmd *SetProposalStatusCmd) Execute(args []string) error {
 		PublicKey:           hex.EncodeToString(cfg.Identity.Public.Key[:]),
 	}
 
+	// Make sure the proposal is not being voted on.  The status
+	// change is sent regardless when force is set.
+	if !cmd.Force {
+		err = client.CheckProposalStatusChange(sps)
+		if err != nil {
+			return fmt.Errorf("%v; use --force to send the request "+
+				"anyway", err)
+		}
+	}
+
 	// Print request details
-	err = printJSON(sps)
+	err = printRequestJSON(sps)
 	if err != nil {
 		return err
 	}
@@ -82,15 +93,22 @@ func (cmd *SetProposalStatusCmd) Execute(args []string) error {
 
 // setProposalStatusHelpMsg is the output of the help command when
 // "setproposalstatus" is specified.
-const setProposalStatusHelpMsg = `setproposalstatus "token" "status"
+const setProposalStatusHelpMsg = `setproposalstatus [flags] "token" "status"
 
 Set the status of a proposal. Requires admin privileges.
 
+Censoring or abandoning a proposal whose vote is in progress is refused unless
+the --force flag is used.
+
 Arguments:
 1. token      (string, required)   Proposal censorship token
 2. status     (string, required)   New status (censored, public, abandoned)
 3. message    (string, required if censoring proposal)  Status change message
 
+Flags:
+  --force     (bool, optional)     Skip checking that the proposal vote is not
+                                   in progress
+
 Request:
 {
   "token":           (string)  Censorship token
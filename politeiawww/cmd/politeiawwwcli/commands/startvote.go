@@ -81,7 +81,7 @@ func (cmd *StartVoteCmd) Execute(args []string) error {
 	}
 
 	// Print request details
-	err = printJSON(sv)
+	err = printRequestJSON(sv)
 	if err != nil {
 		return err
 	}
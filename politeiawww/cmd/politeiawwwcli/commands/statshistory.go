@@ -0,0 +1,88 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"fmt"
+	"time"
+)
+
+// statsHistoryDateFormat is the format of the from and to flags of the stats
+// history command.
+const statsHistoryDateFormat = "2006-01-02"
+
+// StatsHistoryCmd retrieves the proposal and user activity stats bucketed by
+// time.
+type StatsHistoryCmd struct {
+	From     string        `long:"from" optional:"true"`     // Start date
+	To       string        `long:"to" optional:"true"`       // End date
+	Interval time.Duration `long:"interval" optional:"true"` // Bucket size
+}
+
+// Execute executes the stats history command.
+func (cmd *StatsHistoryCmd) Execute(args []string) error {
+	to := time.Now()
+	if cmd.To != "" {
+		t, err := time.Parse(statsHistoryDateFormat, cmd.To)
+		if err != nil {
+			return fmt.Errorf("invalid to date: %v", err)
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -30)
+	if cmd.From != "" {
+		t, err := time.Parse(statsHistoryDateFormat, cmd.From)
+		if err != nil {
+			return fmt.Errorf("invalid from date: %v", err)
+		}
+		from = t
+	}
+	interval := cmd.Interval
+	if interval == 0 {
+		interval = 24 * time.Hour
+	}
+	if interval < time.Second {
+		return fmt.Errorf("interval must be at least one second")
+	}
+
+	shr, err := client.GetStatsHistory(from, to, interval)
+	if err != nil {
+		return err
+	}
+	return printJSON(shr)
+}
+
+// statsHistoryHelpMsg is the output of the help command when 'statshistory'
+// is specified.
+const statsHistoryHelpMsg = `statshistory
+
+Get the number of submitted proposals, the vote outcomes and the number of
+active users bucketed by time.  A user is active during an interval when they
+submitted a proposal or a comment.  Vote end times are estimated from the vote
+end block height.
+
+Arguments: None
+
+Flags:
+  --from        (string, optional)    Start date (YYYY-MM-DD, UTC).  Defaults
+                                      to 30 days before the end date.
+  --to          (string, optional)    End date (YYYY-MM-DD, UTC), exclusive.
+                                      Defaults to now.
+  --interval    (duration, optional)  Bucket size, e.g. 24h.  Defaults to 24h.
+
+Result:
+{
+  "buckets": [
+    {
+      "start":            (int64)    Start of the interval (UNIX timestamp)
+      "end":              (int64)    End of the interval (UNIX timestamp)
+      "numofsubmitted":   (int)      Number of proposals submitted
+      "numofapproved":    (int)      Number of proposal votes that passed
+      "numofrejected":    (int)      Number of proposal votes that failed
+      "approvalrate":     (float64)  Share of finished votes that passed
+      "numofactiveusers": (int)      Number of active users
+    }
+  ]
+}`
@@ -56,9 +56,11 @@ func (cmd *SubscribeCmd) Execute(args []string) error {
 	uu := url.URL{
 		Scheme: "wss",
 		Host:   u.Host,
-		Path:   v1.PoliteiaWWWAPIRoute + route,
+		Path:   fmt.Sprintf("/v%v", cfg.APIVersion) + route,
+	}
+	if !cfg.JSONOnly {
+		fmt.Printf("connecting to %s\n", uu.String())
 	}
-	fmt.Printf("connecting to %s\n", uu.String())
 
 	ws, _, err := d.Dial(uu.String(), nil)
 	if err != nil {
@@ -66,11 +68,11 @@ func (cmd *SubscribeCmd) Execute(args []string) error {
 	}
 	defer ws.Close()
 
-	err = printJSON(v1.WSHeader{Command: v1.WSCSubscribe, ID: "1"})
+	err = printRequestJSON(v1.WSHeader{Command: v1.WSCSubscribe, ID: "1"})
 	if err != nil {
 		return err
 	}
-	err = printJSON(v1.WSSubscribe{RPCS: subscribe})
+	err = printRequestJSON(v1.WSSubscribe{RPCS: subscribe})
 	if err != nil {
 		return err
 	}
@@ -6,7 +6,8 @@ package commands
 
 import (
 	"fmt"
-	"strconv"
+
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
 )
 
 // TallyCmd retrieves all of the cast votes for a proposal, tallies the votes,
@@ -25,15 +26,15 @@ func (cmd *TallyCmd) Execute(args []string) error {
 		return fmt.Errorf("ProposalVotes: %v", err)
 	}
 
-	// Tally votes
+	// Tally votes by vote option
 	var total uint
-	tally := make(map[uint64]uint)
+	tally := make(map[string]uint) // [optionID]votes
 	for _, v := range vrr.CastVotes {
-		bits, err := strconv.ParseUint(v.VoteBit, 10, 64)
+		id, err := wwwclient.VoteOptionForBit(vrr.StartVote.Vote, v.VoteBit)
 		if err != nil {
-			return err
+			return fmt.Errorf("ticket %v: %v", v.Ticket, err)
 		}
-		tally[bits]++
+		tally[id]++
 		total++
 	}
 
@@ -43,7 +44,7 @@ func (cmd *TallyCmd) Execute(args []string) error {
 
 	// Print results
 	for _, vo := range vrr.StartVote.Vote.Options {
-		votes := tally[vo.Bits]
+		votes := tally[vo.Id]
 		fmt.Printf("Vote Option:\n")
 		fmt.Printf("  ID                   : %v\n", vo.Id)
 		fmt.Printf("  Description          : %v\n", vo.Description)
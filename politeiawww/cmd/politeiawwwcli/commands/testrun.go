@@ -279,12 +279,12 @@ func (cmd *TestRunCmd) Execute(args []string) error {
 		if pppr.TxID == "" {
 			// Verify that the correct number of proposal credits
 			// have been added to the user's account.
-			upcr, err := client.UserProposalCredits()
+			me, err := client.Me()
 			if err != nil {
 				return err
 			}
 
-			if !paywallEnabled || len(upcr.UnspentCredits) == numCredits {
+			if !paywallEnabled || int(me.ProposalCredits) == numCredits {
 				break
 			}
 		}
@@ -363,7 +363,7 @@ func (cmd *TestRunCmd) Execute(args []string) error {
 		Signature:        np.Signature,
 		CensorshipRecord: npr.CensorshipRecord,
 	}
-	err = verifyProposal(pr, version.PubKey)
+	err = client.VerifyProposal(pr)
 	if err != nil {
 		return fmt.Errorf("verify proposal failed: %v", err)
 	}
@@ -441,7 +441,7 @@ func (cmd *TestRunCmd) Execute(args []string) error {
 		return err
 	}
 
-	err = verifyProposal(pdr.Proposal, version.PubKey)
+	err = client.VerifyProposal(pdr.Proposal)
 	if err != nil {
 		return fmt.Errorf("verify proposal failed: %v", err)
 	}
@@ -977,7 +977,7 @@ func (cmd *TestRunCmd) Execute(args []string) error {
 	}
 
 	for _, v := range unvetted {
-		err = verifyProposal(v, version.PubKey)
+		err = client.VerifyProposal(v)
 		if err != nil {
 			return fmt.Errorf("verify proposal failed %v: %v",
 				v.CensorshipRecord.Token, err)
@@ -1233,7 +1233,7 @@ func (cmd *TestRunCmd) Execute(args []string) error {
 	}
 
 	for _, v := range upr.Proposals {
-		err := verifyProposal(v, version.PubKey)
+		err := client.VerifyProposal(v)
 		if err != nil {
 			return fmt.Errorf("verify proposal failed %v: %v",
 				v.CensorshipRecord.Token, err)
@@ -1253,7 +1253,7 @@ func (cmd *TestRunCmd) Execute(args []string) error {
 	}
 
 	for _, v := range gavr.Proposals {
-		err = verifyProposal(v, version.PubKey)
+		err = client.VerifyProposal(v)
 		if err != nil {
 			return fmt.Errorf("verify proposal failed %v: %v",
 				v.CensorshipRecord.Token, err)
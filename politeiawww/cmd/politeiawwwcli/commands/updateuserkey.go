@@ -32,7 +32,7 @@ func (cmd *UpdateUserKeyCmd) Execute(args []string) error {
 		PublicKey: hex.EncodeToString(id.Public.Key[:]),
 	}
 
-	err = printJSON(uuk)
+	err = printRequestJSON(uuk)
 	if err != nil {
 		return err
 	}
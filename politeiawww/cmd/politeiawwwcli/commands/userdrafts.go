@@ -0,0 +1,54 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// UserDraftsCmd retrieves the proposal drafts of the logged in user.  A
+// single draft, including its files, is retrieved when a draft ID is given.
+type UserDraftsCmd struct {
+	Args struct {
+		DraftID string `positional-arg-name:"draftid"` // Draft ID
+	} `positional-args:"true" optional:"true"`
+}
+
+// Execute executes the user drafts command.
+func (cmd *UserDraftsCmd) Execute(args []string) error {
+	if cmd.Args.DraftID != "" {
+		ddr, err := client.DraftDetails(cmd.Args.DraftID)
+		if err != nil {
+			return err
+		}
+		return printJSON(ddr)
+	}
+
+	udr, err := client.UserDrafts()
+	if err != nil {
+		return err
+	}
+	return printJSON(udr)
+}
+
+// userDraftsHelpMsg is the output of the help command when 'userdrafts' is
+// specified.
+const userDraftsHelpMsg = `userdrafts "draftid"
+
+Get the proposal drafts of the logged in user, most recently updated first.
+The files of a draft are returned when a draft id is given.
+
+Arguments:
+1. draftid     (string, optional)   Draft id
+
+Result:
+{
+  "drafts": [
+    {
+      "draftid":     (string)  Unique draft id
+      "name":        (string)  Proposal name, empty without an index file
+      "numfiles":    (int)     Number of files
+      "size":        (int64)   Size of the decoded files in bytes
+      "timestamp":   (int64)   UNIX timestamp of creation
+      "updated":     (int64)   UNIX timestamp of the last update
+    }
+  ]
+}`
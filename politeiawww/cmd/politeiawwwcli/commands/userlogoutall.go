@@ -0,0 +1,48 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import "github.com/decred/politeia/politeiawww/api/v1"
+
+// UserLogoutAllCmd logs the specified user out of all of their sessions.
+type UserLogoutAllCmd struct {
+	Args struct {
+		UserID string `positional-arg-name:"userid"` // User ID
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the user logout all command.
+func (cmd *UserLogoutAllCmd) Execute(args []string) error {
+	ula := &v1.UserLogoutAll{
+		UserID: cmd.Args.UserID,
+	}
+
+	err := printRequestJSON(ula)
+	if err != nil {
+		return err
+	}
+
+	ulr, err := client.UserLogoutAll(ula)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(ulr)
+}
+
+// userLogoutAllHelpMsg is the output of the help command when
+// 'userlogoutall' is specified.
+var userLogoutAllHelpMsg = `userlogoutall "userid"
+
+Log a user out of all of their sessions.  The sessions are revoked
+immediately.  Requires admin privileges.
+
+Arguments:
+1. userid        (string, required)   User id
+
+Result:
+{
+  "sessionsremoved"   (int)  Number of sessions that were removed
+}`
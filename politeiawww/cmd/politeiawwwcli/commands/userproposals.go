@@ -19,12 +19,6 @@ type UserProposalsCmd struct {
 
 // Execute executes the user proposals command.
 func (cmd *UserProposalsCmd) Execute(args []string) error {
-	// Get server public key
-	vr, err := client.Version()
-	if err != nil {
-		return err
-	}
-
 	// Get user proposals
 	upr, err := client.UserProposals(
 		&v1.UserProposals{
@@ -36,7 +30,7 @@ func (cmd *UserProposalsCmd) Execute(args []string) error {
 
 	// Verify proposal censorship records
 	for _, p := range upr.Proposals {
-		err := verifyProposal(p, vr.PubKey)
+		err := client.VerifyProposal(p)
 		if err != nil {
 			return fmt.Errorf("unable to verify proposal %v: %v",
 				p.CensorshipRecord.Token, err)
@@ -11,15 +11,17 @@ import (
 // UsersCmd retreives a list of users that have been filtered using the
 // specified filtering params.
 type UsersCmd struct {
-	Email    string `long:"email"`    // Email filter
-	Username string `long:"username"` // Username filter
+	Email     string `long:"email"`     // Email filter
+	Username  string `long:"username"`  // Username filter
+	PublicKey string `long:"publickey"` // Public key filter
 }
 
 // Execute executes the users command.
 func (cmd *UsersCmd) Execute(args []string) error {
 	u := v1.Users{
-		Email:    cmd.Email,
-		Username: cmd.Username,
+		Email:     cmd.Email,
+		Username:  cmd.Username,
+		PublicKey: cmd.PublicKey,
 	}
 
 	ur, err := client.Users(&u)
@@ -32,13 +34,16 @@ func (cmd *UsersCmd) Execute(args []string) error {
 // usersHelpMsg is the output of the help command when 'users' is specified.
 const usersHelpMsg = `users [flags]
 
-Fetch a list of users, optionally filtering by email and/or username.
+Fetch a list of users, optionally filtering by email, username and/or public
+key.  The public key filter matches the user that owns the key, including keys
+that the user has since replaced.
 
 Arguments: None
 
 Flags:
   --email       (string, optional)   Email filter
   --username    (string, optional)   Username filter
+  --publickey   (string, optional)   Public key filter
 
 Example:
 users --email=user@example.com --username=user
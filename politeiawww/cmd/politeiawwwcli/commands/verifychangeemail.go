@@ -0,0 +1,54 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import "github.com/decred/politeia/politeiawww/api/v1"
+
+// VerifyChangeEmailCmd verifies the new email address of the logged in user.
+type VerifyChangeEmailCmd struct {
+	Args struct {
+		Token string `positional-arg-name:"token"` // Verification token
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the verify change email command.
+func (cmd *VerifyChangeEmailCmd) Execute(args []string) error {
+	vce := &v1.VerifyChangeEmail{
+		VerificationToken: cmd.Args.Token,
+	}
+
+	// Print request details
+	err := printRequestJSON(vce)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	vcer, err := client.VerifyChangeEmail(vce)
+	if err != nil {
+		return err
+	}
+
+	// Print response details
+	return printJSON(vcer)
+}
+
+// verifyChangeEmailHelpMsg is the output of the help command when
+// 'verifychangeemail' is specified.
+var verifyChangeEmailHelpMsg = `verifychangeemail "token"
+
+Verify the new email address of the currently logged in user. The email
+address is changed once the verification token is verified.
+
+Arguments:
+1. token      (string, required)   Verification token
+
+Request:
+{
+  "verificationtoken":   (string)  Verification token
+}
+
+Response:
+{}`
@@ -0,0 +1,66 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import "fmt"
+
+// VerifyIntegrityCmd verifies the complete signature chain of a proposal.
+type VerifyIntegrityCmd struct {
+	Args struct {
+		Token string `positional-arg-name:"token" required:"true"` // Censorship token
+	} `positional-args:"true"`
+}
+
+// Execute executes the verify integrity command.
+func (cmd *VerifyIntegrityCmd) Execute(args []string) error {
+	r, err := client.VerifyProposalIntegrity(cmd.Args.Token)
+	if err != nil {
+		return err
+	}
+
+	err = printJSON(r)
+	if err != nil {
+		return err
+	}
+	if !r.Valid {
+		return fmt.Errorf("proposal %v failed verification", r.Token)
+	}
+	return nil
+}
+
+// verifyIntegrityHelpMsg is the output for the help command when
+// 'verifyintegrity' is specified.
+const verifyIntegrityHelpMsg = `verifyintegrity "token"
+
+Verify the complete signature chain of a proposal.  The latest version of the
+proposal is fetched along with all of its metadata streams and the following
+is verified:
+
+- the digests of the proposal files and the merkle root
+- the author signature of the merkle root
+- the censorship record signature of the server
+- the author key and signature in the general metadata
+- the admin keys of the status changes (status changes are not signed)
+- the author signature and server receipt of the vote authorization
+- the signature of the start vote
+
+The command fails if any of the checks failed.
+
+Arguments:
+1. token      (string, required)   Censorship token
+
+Result:
+{
+  "token":       (string)  Censorship token
+  "version":     (string)  Proposal version that was verified
+  "valid":       (bool)    Whether none of the checks failed
+  "checks": [
+    {
+      "check":   (string)  Description of the check
+      "status":  (string)  passed, failed or skipped
+      "reason":  (string)  Why the check failed or was skipped
+    }
+  ]
+}`
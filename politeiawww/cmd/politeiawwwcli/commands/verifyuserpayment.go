@@ -4,13 +4,42 @@
 
 package commands
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
 // VerifyUserPaymentCmd checks on the status of the logged in user's
 // registration payment.
-type VerifyUserPaymentCmd struct{}
+type VerifyUserPaymentCmd struct {
+	Wait bool   `long:"wait" optional:"true"` // Wait for payment to confirm
+	TxID string `long:"txid" optional:"true"` // Verify a specific payment tx
+}
 
 // Execute executes the verify user payment command.
 func (cmd *VerifyUserPaymentCmd) Execute(args []string) error {
-	vupr, err := client.VerifyUserPayment()
+	if cmd.TxID != "" {
+		if cmd.Wait {
+			return fmt.Errorf("--wait cannot be used with --txid")
+		}
+		vuptr, err := client.VerifyUserPaymentTx(cmd.TxID)
+		if err != nil {
+			return err
+		}
+		return printJSON(vuptr)
+	}
+
+	var (
+		vupr *v1.VerifyUserPaymentReply
+		err  error
+	)
+	if cmd.Wait {
+		vupr, err = client.WaitForUserPayment(context.Background(), 0)
+	} else {
+		vupr, err = client.VerifyUserPayment()
+	}
 	if err != nil {
 		return err
 	}
@@ -25,10 +54,36 @@ Check if the currently logged in user has paid their user registration fee.
 
 Arguments: None
 
+Flags:
+  --wait    (bool, optional)    Wait until the payment has been confirmed.  The
+                                payment is checked every paywallpollinterval
+                                and the number of confirmations of a payment
+                                that was sent is printed while waiting.
+  --txid    (string, optional)  Verify that the specified transaction pays the
+                                registration fee.  A breakdown of the
+                                transaction is returned along with the reasons
+                                the payment was not accepted, if any.
+
 Result:
 {
   "haspaid"                (bool)    Has paid or not
   "paywalladdress"         (string)  Registration paywall address
   "paywallamount"          (uint64)  Registration paywall amount in atoms
   "paywalltxnotbefore"     (int64)   Minimum timestamp for paywall tx
+  "txid"                   (string)  Payment tx that is not confirmed yet
+  "confirmations"          (uint64)  Number of confirmations of the payment tx
+}
+
+Result (--txid):
+{
+  "haspaid"                (bool)      Has paid or not
+  "txid"                   (string)    Payment tx
+  "paywalladdress"         (string)    Registration paywall address
+  "paywallamount"          (uint64)    Registration paywall amount in atoms
+  "paywalltxnotbefore"     (int64)     Minimum timestamp for paywall tx
+  "amountpaid"             (uint64)    Amount sent to the paywall address
+  "timestamp"              (int64)     Timestamp of the payment tx
+  "confirmations"          (uint64)    Number of confirmations of the payment tx
+  "minconfirmations"       (uint64)    Number of confirmations required
+  "errors"                 ([]string)  Requirements that were not met
 }`
@@ -22,12 +22,6 @@ func (cmd *VettedProposalsCmd) Execute(args []string) error {
 		return errInvalidBeforeAfterUsage
 	}
 
-	// Get server's public key
-	vr, err := client.Version()
-	if err != nil {
-		return err
-	}
-
 	// Get a page of vetted proposals
 	gavr, err := client.GetAllVetted(&v1.GetAllVetted{
 		Before: cmd.Before,
@@ -39,7 +33,7 @@ func (cmd *VettedProposalsCmd) Execute(args []string) error {
 
 	// Verify proposal censorship records
 	for _, p := range gavr.Proposals {
-		err = verifyProposal(p, vr.PubKey)
+		err = client.VerifyProposal(p)
 		if err != nil {
 			return fmt.Errorf("unable to verify proposal %v: %v",
 				p.CensorshipRecord.Token, err)
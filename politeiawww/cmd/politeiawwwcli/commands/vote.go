@@ -7,13 +7,12 @@ package commands
 import (
 	"encoding/hex"
 	"fmt"
-	"strconv"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrwallet/rpc/walletrpc"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	"github.com/decred/politeia/politeiawww/api/v1"
-	"github.com/decred/politeia/util"
+	wwwclient "github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
 )
 
 // VoteCmd casts a proposal ballot for the specified proposal.
@@ -22,6 +21,7 @@ type VoteCmd struct {
 		Token  string `positional-arg-name:"token"`  // Censorship token
 		VoteID string `positional-arg-name:"voteid"` // Vote choice ID
 	} `positional-args:"true" required:"true"`
+	Save string `long:"save" optional:"true"` // Save the ballot instead of casting it
 }
 
 // Execute executes the vote command.
@@ -37,14 +37,9 @@ func (cmd *VoteCmd) Execute(args []string) error {
 	defer client.Close()
 
 	// Get server public key
-	vr, err := client.Version()
+	serverID, err := client.ServerPublicKey()
 	if err != nil {
-		return fmt.Errorf("Version: %v", err)
-	}
-
-	serverID, err := util.IdentityFromString(vr.PubKey)
-	if err != nil {
-		return err
+		return fmt.Errorf("ServerPublicKey: %v", err)
 	}
 
 	// Get all active proposal votes
@@ -68,16 +63,9 @@ func (cmd *VoteCmd) Execute(args []string) error {
 
 	// Ensure that the passed in voteID is one of the
 	// proposal's voting options and save the vote bits
-	var voteBits string
-	for _, option := range pvt.StartVote.Vote.Options {
-		if voteID == option.Id {
-			voteBits = strconv.FormatUint(option.Bits, 16)
-			break
-		}
-	}
-
-	if voteBits == "" {
-		return fmt.Errorf("vote id not found: %v", voteID)
+	voteBits, err := wwwclient.VoteBitForOption(pvt.StartVote.Vote, voteID)
+	if err != nil {
+		return err
 	}
 
 	// Find user's tickets that are eligible to vote on this
@@ -156,10 +144,32 @@ func (cmd *VoteCmd) Execute(args []string) error {
 		})
 	}
 
-	// Cast proposal votes
-	br, err := client.CastVotes(&v1.Ballot{
+	b := &v1.Ballot{
 		Votes: votes,
-	})
+	}
+
+	// Save the signed ballot so that it can be cast later
+	if cmd.Save != "" {
+		err = wwwclient.SaveBallotToFile(cmd.Save, b)
+		if err != nil {
+			return fmt.Errorf("SaveBallotToFile: %v", err)
+		}
+		if !cfg.Silent {
+			fmt.Printf("Ballot saved   : %v (%v votes)\n", cmd.Save,
+				len(votes))
+		}
+		return nil
+	}
+
+	return castBallot(serverID, b)
+}
+
+// castBallot casts the votes of the passed in signed ballot and prints the
+// results.  The receipts of the votes are verified against the passed in
+// server identity.
+func castBallot(serverID *identity.PublicIdentity, b *v1.Ballot) error {
+	// Cast proposal votes
+	br, err := client.CastVotes(b)
 	if err != nil {
 		return fmt.Errorf("CastVotes: %v", err)
 	}
@@ -169,11 +179,11 @@ func (cmd *VoteCmd) Execute(args []string) error {
 	// receipt with a specific ticket, we need  to lookup the
 	// ticket hash and store it separately.
 	failedReceipts := make([]v1.CastVoteReply, 0, len(br.Receipts))
-	failedTickets := make([]string, 0, len(eligibleTickets))
+	failedTickets := make([]string, 0, len(b.Votes))
 	for i, v := range br.Receipts {
 		// Lookup ticket hash
-		// br.Receipts and eligibleTickets use the same index
-		h := eligibleTickets[i]
+		// br.Receipts and b.Votes use the same index
+		h := b.Votes[i].Ticket
 
 		// Check for voting error
 		if v.Error != "" {
@@ -199,7 +209,12 @@ func (cmd *VoteCmd) Execute(args []string) error {
 	}
 
 	// Print results
-	if !cfg.Silent {
+	switch {
+	case cfg.Silent:
+		// Keep quiet
+	case cfg.JSONOnly:
+		return printJSON(br)
+	default:
 		fmt.Printf("Votes succeeded: %v\n", len(br.Receipts)-len(failedReceipts))
 		fmt.Printf("Votes failed   : %v\n", len(failedReceipts))
 		for i, v := range failedReceipts {
@@ -217,7 +232,16 @@ Cast ticket votes for a proposal.
 
 Arguments:
 1. token       (string, optional)   Proposal censorship token
-2. voteid      (string, optional)   A single word identifying vote (e.g. yes)
+2. voteid      (string, optional)   ID of the vote option to select (e.g. yes).
+                                    Must be one of the options of the vote;
+                                    see activevotes.
+
+Flags:
+  --save       (string, optional)   Save the signed ballot to the passed in file
+                                    instead of casting it.  The ballot is saved
+                                    as CSV if the file has a .csv extension and
+                                    as JSON otherwise.  Use the castballot
+                                    command to cast the saved ballot.
 
 Result:
 Enter the private passphrase of your wallet:
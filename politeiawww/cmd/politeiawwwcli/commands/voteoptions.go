@@ -0,0 +1,47 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+// VoteOptionsCmd gets the options of an active proposal vote.
+type VoteOptionsCmd struct {
+	Args struct {
+		Token string `positional-arg-name:"token"` // Censorship token
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the vote options command.
+func (cmd *VoteOptionsCmd) Execute(args []string) error {
+	vote, err := client.GetVoteOptions(cmd.Args.Token)
+	if err != nil {
+		return err
+	}
+	return printJSON(vote)
+}
+
+// voteOptionsHelpMsg is the output of the help command when 'voteoptions' is
+// specified.
+const voteOptionsHelpMsg = `voteoptions "token"
+
+Fetch the options of an active proposal vote.  The option IDs can be passed to
+the vote command.
+
+Arguments:
+1. token       (string, required)  Proposal censorship token
+
+Result:
+{
+  "token":               (string)  Censorship token
+  "mask"                 (uint64)  Valid votebits
+  "duration":            (uint32)  Duration of vote in blocks
+  "quorumpercentage"     (uint32)  Percent of votes required for quorum
+  "passpercentage":      (uint32)  Percent of votes required to pass
+  "options": [
+    {
+      "id"               (string)  Unique word identifying vote (e.g. yes)
+      "description"      (string)  Longer description of the vote
+      "bits":            (uint64)  Bits used for this option
+    },
+  ]
+}`
@@ -4,16 +4,23 @@
 
 package commands
 
+import "os"
+
 // VoteResultsCmd gets the votes that have been cast for the specified
 // proposal.
 type VoteResultsCmd struct {
 	Args struct {
 		Token string `positional-arg-name:"token"` // Censorship token
 	} `positional-args:"true" required:"true"`
+	CSV bool `long:"csv" optional:"true"` // Print cast votes as CSV
 }
 
 // Execute executes the proposal votes command.
 func (cmd *VoteResultsCmd) Execute(args []string) error {
+	if cmd.CSV {
+		return client.ExportVoteResultsCSV(cmd.Args.Token, os.Stdout)
+	}
+
 	vrr, err := client.VoteResults(cmd.Args.Token)
 	if err != nil {
 		return err
@@ -39,6 +46,10 @@ Fetch vote results for a proposal.
 Arguments:
 1. token       (string, required)  Proposal censorship token
 
+Flags:
+  --csv        (bool, optional)    Print the cast votes as CSV with the columns
+                                   ticket, votebit and signature
+
 Request:
 {
   "token":     (string)  Proposal censorship token
@@ -0,0 +1,64 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// WatchInventoryCmd prints proposal submissions and status changes as they
+// happen until it is interrupted.
+type WatchInventoryCmd struct{}
+
+// Execute executes the watch inventory command.
+func (cmd *WatchInventoryCmd) Execute(args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Stop watching on interrupt
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	go func() {
+		select {
+		case <-sigs:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := client.WatchInventory(ctx, func(e v1.InventoryEvent) error {
+		return printJSON(e)
+	})
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// watchInventoryHelpMsg is the output of the help command when
+// 'watchinventory' is specified.
+const watchInventoryHelpMsg = `watchinventory
+
+Print proposal submissions and status changes as they happen until
+interrupted.  The connection to politeiawww is reestablished when it is lost;
+events that happen while it is down are not printed.  Only admins receive the
+events of unvetted proposals.
+
+Arguments: None
+
+Result (printed for every event):
+{
+  "event":      (string)  Event that occurred (proposalsubmitted or
+                          proposalstatuschange)
+  "timestamp":  (int64)   Event timestamp
+  "token":      (string)  Proposal censorship token
+  "name":       (string)  Proposal name
+  "status":     (int)     Proposal status
+}`
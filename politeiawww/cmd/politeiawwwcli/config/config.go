@@ -13,29 +13,43 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/decred/dcrd/dcrutil"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
+	v1 "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/sharedconfig"
 	"github.com/decred/politeia/util/version"
 	flags "github.com/jessevdk/go-flags"
 )
 
 const (
-	defaultHomeDirname       = "cli"
-	defaultDataDirname       = "data"
-	defaultConfigFilename    = "politeiawwwcli.conf"
-	defaultHost              = "https://proposals.decred.org/api"
-	defaultFaucetHost        = "https://faucet.decred.org/requestfaucet"
-	defaultWalletHost        = "127.0.0.1"
-	defaultWalletTestnetPort = "19111"
-
-	userFile     = "user.txt"
-	csrfFile     = "csrf.txt"
-	cookieFile   = "cookies.json"
-	identityFile = "identity.json"
+	defaultHomeDirname         = "cli"
+	defaultDataDirname         = "data"
+	defaultProfilesDirname     = "profiles"
+	defaultConfigFilename      = "politeiawwwcli.conf"
+	defaultHost                = "https://proposals.decred.org/api"
+	defaultFaucetHost          = "https://faucet.decred.org/requestfaucet"
+	defaultWalletHost          = "127.0.0.1"
+	defaultWalletTestnetPort   = "19111"
+	defaultMaxIdleConns        = 100
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultPaywallPollInterval = 30 * time.Second
+	defaultChunkedUploadSize   = 128 * 1024
+	defaultMaxRetryWait        = time.Minute
+	defaultMaxRetries          = 5
+	defaultSlowRequest         = time.Second
+	defaultUserCacheSize       = 100
+	defaultUserCacheTTL        = 5 * time.Minute
+
+	userFile        = "user.txt"
+	csrfFile        = "csrf.txt"
+	cookieFile      = "cookies.json"
+	identityFile    = "identity.json"
+	credentialsFile = "credentials.json"
 )
 
 var (
@@ -43,8 +57,33 @@ var (
 	defaultDataDir        = filepath.Join(defaultHomeDir, defaultDataDirname)
 	dcrwalletHomeDir      = dcrutil.AppDataDir("dcrwallet", false)
 	defaultWalletCertFile = filepath.Join(dcrwalletHomeDir, "rpc.cert")
+
+	// validProfile matches the characters that are allowed in a profile
+	// name.  The profile name is used as a directory name.
+	validProfile = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+	// invalidProfileChars matches the hostname characters that are not
+	// allowed in a profile name.
+	invalidProfileChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
 )
 
+// SupportedAPIVersions are the politeiawww API versions that the client is
+// able to talk to.
+var SupportedAPIVersions = []uint{
+	v1.PoliteiaWWWAPIVersion,
+}
+
+// IsSupportedAPIVersion returns whether the passed in politeiawww API version
+// is supported by the client.
+func IsSupportedAPIVersion(version uint) bool {
+	for _, v := range SupportedAPIVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
 // Config represents the politeiawwwcli configuration settings.
 type Config struct {
 	HomeDir     string `long:"appdata" description:"Path to application home directory"`
@@ -54,6 +93,104 @@ type Config struct {
 	SkipVerify  bool   `long:"skipverify" description:"Skip verifying the server's certifcate chain and host name"`
 	Verbose     bool   `short:"v" long:"verbose" description:"Print verbose output"`
 	Silent      bool   `long:"silent" description:"Suppress all output"`
+	JSONOnly    bool   `long:"json-only" description:"Only print the JSON reply as raw JSON; errors are printed as JSON to stderr"`
+	DebugLog    string `long:"debuglog" description:"Append every request and response to this file as JSON lines; secrets are redacted"`
+	Trace       bool   `long:"trace" description:"Record the DNS, connect, TLS and first byte timings of each request; printed in verbose mode"`
+	Profile     string `long:"profile" description:"Name of the profile that the session data is stored under; defaults to the hostname"`
+
+	// JSON output settings.  Sorting the keys of JSON objects produces
+	// stable output that can be diffed, e.g. when committing replies to
+	// version control or comparing them in snapshot tests.
+	JSONIndent string `long:"jsonindent" description:"Indentation of pretty printed JSON; either \"tab\" or a number of spaces"`
+	SortKeys   bool   `long:"sortkeys" description:"Sort the keys of JSON objects in the printed output"`
+
+	// APIVersion is the politeiawww API version that requests are sent
+	// to.  It selects the route prefix, e.g. /v1, so that a single client
+	// can talk to servers that expose different API versions.
+	APIVersion uint `long:"apiversion" description:"politeiawww API version to send requests to"`
+
+	// SlowRequest is the response time above which a request is flagged
+	// as slow in verbose mode.
+	SlowRequest time.Duration `long:"slowrequest" description:"Response time above which a request is flagged as slow in verbose mode; 0 disables the warning"`
+
+	// SkipValidation disables the client side validation of requests
+	// against the server policy before they are sent.
+	SkipValidation bool `long:"skipvalidation" description:"Skip validating requests against the server policy before sending them"`
+
+	// HTTP transport connection settings.  Keeping idle connections
+	// around allows sequential requests to reuse an already established
+	// TCP/TLS connection instead of paying for a new handshake on every
+	// request, at the cost of holding open file descriptors and server
+	// resources while the connections sit idle.
+	MaxIdleConns    int           `long:"maxidleconns" description:"Maximum number of idle (keep-alive) connections to keep open; 0 means no limit"`
+	IdleConnTimeout time.Duration `long:"idleconntimeout" description:"Amount of time an idle connection is kept open before being closed; 0 means no limit"`
+	MaxConnsPerHost int           `long:"maxconnsperhost" description:"Maximum number of connections per host, including active and idle connections; 0 means no limit"`
+
+	// Proxy settings.  A configured proxy takes precedence over the
+	// proxy environment variables.  See ProxyURL.
+	Proxy     string `long:"proxy" description:"Connect to politeiawww through this proxy; an http, https or socks5 URL including the port"`
+	ProxyUser string `long:"proxyuser" description:"Username for the proxy"`
+	ProxyPass string `long:"proxypass" description:"Password for the proxy"`
+
+	// TLS client certificate that is presented to politeiawww, or to the
+	// gateway in front of it, when the server requires mutual TLS.
+	ClientCert string `long:"clientcert" description:"Path to the TLS client certificate to authenticate with"`
+	ClientKey  string `long:"clientkey" description:"Path to the key of the TLS client certificate"`
+
+	// APIToken is an API token that authenticates the requests instead of
+	// the session cookie.  API tokens are issued by admins.
+	APIToken string `long:"apitoken" description:"API token to authenticate requests with instead of the session"`
+
+	// NoCSRF disables fetching, storing and sending the CSRF token.  The
+	// server only applies CSRF protection to cookie sessions so the token
+	// is not needed when the requests are authenticated with an API
+	// token.  It requires APIToken to be set.
+	NoCSRF bool `long:"nocsrf" description:"Do not fetch, store or send the CSRF token; requires apitoken"`
+
+	// PageSize is the number of items to request per page of the vetted
+	// proposals and users lists.  It is capped at the maximum page size
+	// of the server policy and is only sent to servers that accept a
+	// list limit.  0 uses the page size of the server.
+	PageSize uint `long:"pagesize" description:"Number of items to request per page of the vetted proposals and users lists; 0 uses the server page size"`
+
+	// ChunkedUploadSize is the size in bytes above which proposal files
+	// are uploaded in chunks before the proposal is submitted.  Chunked
+	// uploads are resumed when the connection is lost instead of the
+	// whole proposal being sent again.  0 disables chunked uploads.
+	ChunkedUploadSize int `long:"chunkeduploadsize" description:"Upload proposal files larger than this many bytes in resumable chunks; 0 disables chunked uploads"`
+
+	// PaywallPollInterval is the amount of time to wait between checks
+	// when waiting for a paywall payment to be confirmed.
+	PaywallPollInterval time.Duration `long:"paywallpollinterval" description:"Amount of time to wait between checks when waiting for a paywall payment to be confirmed"`
+
+	// Retry enables retrying requests that were rate limited by the
+	// server.  The client waits for the amount of time specified by the
+	// Retry-After header before retrying, as long as the total time spent
+	// waiting on a request does not exceed MaxRetryWait and the request
+	// has not already been retried MaxRetries times.
+	Retry        bool          `long:"retry" description:"Retry rate limited requests after the amount of time requested by the server"`
+	MaxRetryWait time.Duration `long:"maxretrywait" description:"Maximum total amount of time to wait when retrying a rate limited request"`
+	MaxRetries   int           `long:"maxretries" description:"Maximum number of times a rate limited request is retried"`
+
+	// AutoLogin stores the login credentials when logging in and uses
+	// them to log in again when a request fails because the session has
+	// expired.  The credentials are kept in the session store, which must
+	// be the keyring or the encrypted file store so that the password is
+	// never written to disk in plain text.
+	AutoLogin bool `long:"autologin" description:"Store the login credentials and use them to log in again when the session expires"`
+
+	// Session storage settings.  The session cookies and CSRF token are
+	// stored in plain text files by default.  They can instead be stored
+	// in the keyring of the operating system or in files that are
+	// encrypted with a passphrase.  See setupSessionStore.
+	SessionStore string `long:"sessionstore" description:"Where to store the session cookies and CSRF token; file, keyring or encrypted"`
+	SessionPass  string `long:"sessionpass" description:"Passphrase of the encrypted session files; prompted for when not set"`
+
+	// User details cache settings.  User details replies are cached by
+	// user ID so that the same user is not requested repeatedly, e.g.
+	// when showing the usernames of a comment thread.
+	UserCacheSize int           `long:"usercachesize" description:"Maximum number of user details replies to cache; 0 disables the cache"`
+	UserCacheTTL  time.Duration `long:"usercachettl" description:"Amount of time a cached user details reply is used before it is fetched again"`
 
 	DataDir    string // Application data dir
 	Version    string // CLI version
@@ -62,8 +199,18 @@ type Config struct {
 	FaucetHost string // Testnet faucet host
 	CSRF       string // CSRF header token
 
-	Identity *identity.FullIdentity // User identity
-	Cookies  []*http.Cookie         // User cookies
+	Identity    *identity.FullIdentity // User identity
+	Cookies     []*http.Cookie         // User cookies
+	Credentials *Credentials           // Stored login credentials
+
+	session sessionStore // Session cookie and CSRF token store
+}
+
+// Credentials are the login credentials that are stored when auto login is
+// enabled.
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
 // Load initializes and parses the config using a config file and command line
@@ -88,6 +235,24 @@ func Load() (*Config, error) {
 		WalletCert: defaultWalletCertFile,
 		FaucetHost: defaultFaucetHost,
 		Version:    version.String(),
+		APIVersion: v1.PoliteiaWWWAPIVersion,
+
+		MaxIdleConns:    defaultMaxIdleConns,
+		IdleConnTimeout: defaultIdleConnTimeout,
+
+		PaywallPollInterval: defaultPaywallPollInterval,
+
+		ChunkedUploadSize: defaultChunkedUploadSize,
+
+		MaxRetryWait: defaultMaxRetryWait,
+		MaxRetries:   defaultMaxRetries,
+
+		SlowRequest: defaultSlowRequest,
+
+		UserCacheSize: defaultUserCacheSize,
+		UserCacheTTL:  defaultUserCacheTTL,
+
+		SessionStore: SessionStoreFile,
 	}
 
 	// Pre-parse the command line options to see if an alternative config
@@ -151,6 +316,10 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("MkdirAll %v:  %v", cfg.DataDir, err)
 	}
 
+	if cfg.DebugLog != "" {
+		cfg.DebugLog = cleanAndExpandPath(cfg.DebugLog)
+	}
+
 	// Validate host
 	u, err := url.Parse(cfg.Host)
 	if err != nil {
@@ -160,6 +329,94 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("host scheme must be http or https")
 	}
 
+	// Setup the profile directory and move any session data that
+	// was stored before profiles existed into it.
+	if cfg.Profile != "" && !isValidProfile(cfg.Profile) {
+		return nil, fmt.Errorf("profile may only contain letters, " +
+			"numbers, '.', '_' and '-' and may not be '.' or '..'")
+	}
+	profileDir, err := cfg.profileDir()
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(profileDir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("MkdirAll %v:  %v", profileDir, err)
+	}
+	err = cfg.migrateHostFiles()
+	if err != nil {
+		return nil, fmt.Errorf("migrate session data: %v", err)
+	}
+
+	// The json-only mode prints only the raw JSON reply so it takes
+	// precedence over the verbose output.
+	if cfg.JSONOnly {
+		cfg.RawJSON = true
+		cfg.Verbose = false
+	}
+
+	// Validate connection settings
+	if cfg.MaxIdleConns < 0 {
+		return nil, fmt.Errorf("maxidleconns cannot be negative")
+	}
+	if cfg.IdleConnTimeout < 0 {
+		return nil, fmt.Errorf("idleconntimeout cannot be negative")
+	}
+	if cfg.MaxConnsPerHost < 0 {
+		return nil, fmt.Errorf("maxconnsperhost cannot be negative")
+	}
+	_, err = cfg.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		return nil, fmt.Errorf("clientcert and clientkey must be " +
+			"specified together")
+	}
+	if cfg.ClientCert != "" {
+		cfg.ClientCert = cleanAndExpandPath(cfg.ClientCert)
+		cfg.ClientKey = cleanAndExpandPath(cfg.ClientKey)
+	}
+	if cfg.NoCSRF && cfg.APIToken == "" {
+		return nil, fmt.Errorf("nocsrf requires apitoken")
+	}
+	if cfg.PaywallPollInterval <= 0 {
+		return nil, fmt.Errorf("paywallpollinterval must be positive")
+	}
+	if cfg.MaxRetryWait < 0 {
+		return nil, fmt.Errorf("maxretrywait cannot be negative")
+	}
+	if cfg.MaxRetries < 0 {
+		return nil, fmt.Errorf("maxretries cannot be negative")
+	}
+	if cfg.UserCacheSize < 0 {
+		return nil, fmt.Errorf("usercachesize cannot be negative")
+	}
+	if cfg.UserCacheTTL < 0 {
+		return nil, fmt.Errorf("usercachettl cannot be negative")
+	}
+	_, err = parseJSONIndent(cfg.JSONIndent)
+	if err != nil {
+		return nil, err
+	}
+	if !IsSupportedAPIVersion(cfg.APIVersion) {
+		return nil, fmt.Errorf("unsupported apiversion %v; supported "+
+			"versions: %v", cfg.APIVersion, SupportedAPIVersions)
+	}
+
+	// Auto login keeps the password in the session store so it requires a
+	// session store that does not write plain text files.
+	if cfg.AutoLogin && cfg.SessionStore == SessionStoreFile {
+		return nil, fmt.Errorf("autologin requires sessionstore %v or %v",
+			SessionStoreKeyring, SessionStoreEncrypted)
+	}
+
+	// Setup the session store
+	err = cfg.setupSessionStore()
+	if err != nil {
+		return nil, fmt.Errorf("setupSessionStore: %v", err)
+	}
+
 	// Load cookies
 	cookies, err := cfg.loadCookies()
 	if err != nil {
@@ -168,11 +425,13 @@ func Load() (*Config, error) {
 	cfg.Cookies = cookies
 
 	// Load CSRF tokens
-	csrf, err := cfg.loadCSRF()
-	if err != nil {
-		return nil, fmt.Errorf("loadCSRF: %v", err)
+	if !cfg.NoCSRF {
+		csrf, err := cfg.loadCSRF()
+		if err != nil {
+			return nil, fmt.Errorf("loadCSRF: %v", err)
+		}
+		cfg.CSRF = csrf
 	}
-	cfg.CSRF = csrf
 
 	// Load identity for the logged in user
 	username, err := cfg.loadLoggedInUsername()
@@ -185,37 +444,119 @@ func Load() (*Config, error) {
 	}
 	cfg.Identity = id
 
+	// Load login credentials
+	if cfg.AutoLogin {
+		cr, err := cfg.loadCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("loadCredentials: %v", err)
+		}
+		cfg.Credentials = cr
+	}
+
 	return &cfg, nil
 }
 
-// hostFilePath returns the host specific file path for the passed in file.
-// This means that the hostname is prepended to the filename.  politeiawwwcli
-// data is segmented by host so that we can interact with multiple hosts
-// simultaneously.
-func (cfg *Config) hostFilePath(filename string) (string, error) {
-	u, err := url.Parse(cfg.Host)
+// isValidProfile returns whether the passed in profile name can be used as
+// the name of the profile directory.  The names "." and ".." are rejected
+// since they refer to the profiles directory and its parent.
+func isValidProfile(name string) bool {
+	return validProfile.MatchString(name) && name != "." && name != ".."
+}
+
+// profileName returns the name of the active profile.  The profile name is
+// the profile option if it was set, otherwise it is derived from the hostname
+// so that switching between, for example, a testnet and a mainnet politeiawww
+// does not destroy the session data of the other.
+func (cfg *Config) profileName() (string, error) {
+	name := cfg.Profile
+	if name == "" {
+		u, err := url.Parse(cfg.Host)
+		if err != nil {
+			return "", fmt.Errorf("parse host: %v", err)
+		}
+		name = invalidProfileChars.ReplaceAllString(u.Hostname(), "_")
+	}
+	if !isValidProfile(name) {
+		return "", fmt.Errorf("invalid profile name '%v'", name)
+	}
+	return name, nil
+}
+
+// profileDir returns the directory that the data of the active profile is
+// stored in.
+func (cfg *Config) profileDir() (string, error) {
+	name, err := cfg.profileName()
 	if err != nil {
-		return "", fmt.Errorf("parse host: %v", err)
+		return "", err
 	}
+	return filepath.Join(cfg.DataDir, defaultProfilesDirname, name), nil
+}
 
-	f := fmt.Sprintf("%v_%v", u.Hostname(), filename)
-	return filepath.Join(cfg.DataDir, f), nil
+// profileFilePath returns the profile specific file path for the passed in
+// file.  politeiawwwcli data is segmented by profile so that we can interact
+// with multiple hosts simultaneously.
+func (cfg *Config) profileFilePath(filename string) (string, error) {
+	dir, err := cfg.profileDir()
+	if err != nil {
+		return "", fmt.Errorf("profileDir: %v", err)
+	}
+	return filepath.Join(dir, filename), nil
 }
 
-func (cfg *Config) loadCookies() ([]*http.Cookie, error) {
-	f, err := cfg.hostFilePath(cookieFile)
+// migrateHostFiles moves the data that was stored before profiles existed
+// into the active profile.  The data was previously stored in the data
+// directory with the hostname prepended to the filename.  Files that already
+// exist in the profile are not overwritten.
+func (cfg *Config) migrateHostFiles() error {
+	u, err := url.Parse(cfg.Host)
 	if err != nil {
-		return nil, fmt.Errorf("hostFilePath: %v", err)
+		return fmt.Errorf("parse host: %v", err)
 	}
+	prefix := u.Hostname() + "_"
 
-	if !fileExists(f) {
-		// Nothing to load
-		return nil, nil
+	files, err := ioutil.ReadDir(cfg.DataDir)
+	if err != nil {
+		return err
 	}
+	for _, v := range files {
+		name := v.Name()
+		if v.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
 
-	b, err := ioutil.ReadFile(f)
+		filename := strings.TrimPrefix(name, prefix)
+		switch {
+		case filename == userFile, filename == csrfFile,
+			filename == cookieFile,
+			strings.HasSuffix(filename, "_"+identityFile):
+		default:
+			continue
+		}
+
+		newPath, err := cfg.profileFilePath(filename)
+		if err != nil {
+			return err
+		}
+		if fileExists(newPath) {
+			continue
+		}
+		err = os.Rename(filepath.Join(cfg.DataDir, name), newPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg *Config) loadCookies() ([]*http.Cookie, error) {
+	b, err := cfg.sessionStore().load(cookieFile)
 	if err != nil {
-		return nil, fmt.Errorf("read file %v: %v", f, err)
+		return nil, err
+	}
+	if b == nil {
+		// Nothing to load
+		return nil, nil
 	}
 
 	var c []*http.Cookie
@@ -227,56 +568,91 @@ func (cfg *Config) loadCookies() ([]*http.Cookie, error) {
 	return c, nil
 }
 
-// SaveCookies writes the passed in cookies to the host specific cookie file.
+// SaveCookies writes the passed in cookies to the session store of the
+// profile.
 func (cfg *Config) SaveCookies(cookies []*http.Cookie) error {
 	b, err := json.Marshal(cookies)
 	if err != nil {
 		return fmt.Errorf("marshal cookies: %v", err)
 	}
 
-	f, err := cfg.hostFilePath(cookieFile)
+	err = cfg.sessionStore().save(cookieFile, b)
+	if err != nil {
+		return err
+	}
+
+	cfg.Cookies = cookies
+	return nil
+}
+
+func (cfg *Config) loadCredentials() (*Credentials, error) {
+	b, err := cfg.sessionStore().load(credentialsFile)
 	if err != nil {
-		return fmt.Errorf("hostFilePath: %v", err)
+		return nil, err
+	}
+	if b == nil {
+		// Nothing to load
+		return nil, nil
 	}
 
-	err = ioutil.WriteFile(f, b, 0600)
+	var cr Credentials
+	err = json.Unmarshal(b, &cr)
 	if err != nil {
-		return fmt.Errorf("write file %v: %v", f, err)
+		return nil, fmt.Errorf("unmarshal credentials: %v", err)
 	}
 
-	cfg.Cookies = cookies
-	return nil
+	return &cr, nil
 }
 
-func (cfg *Config) loadCSRF() (string, error) {
-	f, err := cfg.hostFilePath(csrfFile)
+// SaveCredentials writes the passed in login credentials to the session store
+// of the profile so that they can be used to log in again when the session
+// expires.
+func (cfg *Config) SaveCredentials(email, password string) error {
+	cr := Credentials{
+		Email:    email,
+		Password: password,
+	}
+	b, err := json.Marshal(cr)
 	if err != nil {
-		return "", fmt.Errorf("hostFilePath: %v", err)
+		return fmt.Errorf("marshal credentials: %v", err)
 	}
 
-	if !fileExists(f) {
-		// Nothing to load
-		return "", nil
+	err = cfg.sessionStore().save(credentialsFile, b)
+	if err != nil {
+		return err
 	}
 
-	b, err := ioutil.ReadFile(f)
+	cfg.Credentials = &cr
+	return nil
+}
+
+// ClearCredentials removes the stored login credentials.  It is not an error
+// if no credentials are stored.
+func (cfg *Config) ClearCredentials() error {
+	err := cfg.sessionStore().remove(credentialsFile)
 	if err != nil {
-		return "", fmt.Errorf("read file %v: %v", f, err)
+		return err
 	}
 
-	return string(b), nil
+	cfg.Credentials = nil
+	return nil
 }
 
-// SaveCSRF writes the passed in CSRF token to the host specific CSRF file.
-func (cfg *Config) SaveCSRF(csrf string) error {
-	f, err := cfg.hostFilePath(csrfFile)
+func (cfg *Config) loadCSRF() (string, error) {
+	b, err := cfg.sessionStore().load(csrfFile)
 	if err != nil {
-		return fmt.Errorf("hostFilePath: %v", err)
+		return "", err
 	}
 
-	err = ioutil.WriteFile(f, []byte(csrf), 0600)
+	return string(b), nil
+}
+
+// SaveCSRF writes the passed in CSRF token to the session store of the
+// profile.
+func (cfg *Config) SaveCSRF(csrf string) error {
+	err := cfg.sessionStore().save(csrfFile, []byte(csrf))
 	if err != nil {
-		return fmt.Errorf("write file %v: %v", f, err)
+		return err
 	}
 
 	cfg.CSRF = csrf
@@ -287,7 +663,7 @@ func (cfg *Config) SaveCSRF(csrf string) error {
 // store identities in a user specific file so that we can keep track of the
 // identities of multiple users.
 func (cfg *Config) identityFilePath(username string) (string, error) {
-	return cfg.hostFilePath(fmt.Sprintf("%v_%v", username, identityFile))
+	return cfg.profileFilePath(fmt.Sprintf("%v_%v", username, identityFile))
 }
 
 func (cfg *Config) loadIdentity(username string) (*identity.FullIdentity, error) {
@@ -315,9 +691,9 @@ func (cfg *Config) loadIdentity(username string) (*identity.FullIdentity, error)
 }
 
 // SaveIdentity writes the passed in user identity to disk so that it can be
-// persisted between commands.  The prepend the hostname and the username onto
-// the idenity filename so that we can keep track of the identities for
-// multiple users per host.
+// persisted between commands.  The username is prepended onto the identity
+// filename so that we can keep track of the identities for multiple users per
+// profile.
 func (cfg *Config) SaveIdentity(user string, id *identity.FullIdentity) error {
 	f, err := cfg.identityFilePath(user)
 	if err != nil {
@@ -334,9 +710,9 @@ func (cfg *Config) SaveIdentity(user string, id *identity.FullIdentity) error {
 }
 
 func (cfg *Config) loadLoggedInUsername() (string, error) {
-	f, err := cfg.hostFilePath(userFile)
+	f, err := cfg.profileFilePath(userFile)
 	if err != nil {
-		return "", fmt.Errorf("hostFilePath: %v", err)
+		return "", fmt.Errorf("profileFilePath: %v", err)
 	}
 
 	if !fileExists(f) {
@@ -356,9 +732,9 @@ func (cfg *Config) loadLoggedInUsername() (string, error) {
 // We persist the logged in username between commands so that we know which
 // identity to load.
 func (cfg *Config) SaveLoggedInUsername(username string) error {
-	f, err := cfg.hostFilePath(userFile)
+	f, err := cfg.profileFilePath(userFile)
 	if err != nil {
-		return fmt.Errorf("hostFilePath: %v", err)
+		return fmt.Errorf("profileFilePath: %v", err)
 	}
 
 	err = ioutil.WriteFile(f, []byte(username), 0600)
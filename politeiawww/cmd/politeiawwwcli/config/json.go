@@ -0,0 +1,91 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultJSONIndent is the indentation that is used to pretty print
+	// JSON when no indentation has been configured.
+	defaultJSONIndent = "  "
+
+	// maxJSONIndentSpaces is the maximum number of spaces that JSON can be
+	// indented with.
+	maxJSONIndentSpaces = 8
+)
+
+// parseJSONIndent parses the jsonindent setting into the string that JSON is
+// indented with.  The setting is either "tab" or a number of spaces.  An
+// empty setting returns the default indentation.
+func parseJSONIndent(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return defaultJSONIndent, nil
+	case "tab":
+		return "\t", nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > maxJSONIndentSpaces {
+		return "", fmt.Errorf("jsonindent must be \"tab\" or a number of "+
+			"spaces between 0 and %v", maxJSONIndentSpaces)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+// sortJSONKeys returns the passed in JSON with the keys of every object
+// sorted.  encoding/json writes struct fields in the order that they are
+// declared so the JSON is decoded into generic maps, whose keys encoding/json
+// always writes in sorted order, and encoded again.  Numbers are decoded as
+// json.Number so that they are written back unchanged.
+func sortJSONKeys(b []byte) ([]byte, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err := d.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// EncodeJSON encodes the passed in value using the configured JSON style.
+// The keys of JSON objects are sorted when the sortkeys option is set so that
+// the output is stable and can be diffed.  Pretty printed JSON is indented
+// using the jsonindent setting.
+func (cfg *Config) EncodeJSON(v interface{}, pretty bool) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("Marshal: %v", err)
+	}
+
+	if cfg.SortKeys {
+		b, err = sortJSONKeys(b)
+		if err != nil {
+			return nil, fmt.Errorf("sortJSONKeys: %v", err)
+		}
+	}
+
+	if !pretty {
+		return b, nil
+	}
+
+	indent, err := parseJSONIndent(cfg.JSONIndent)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = json.Indent(&buf, b, "", indent)
+	if err != nil {
+		return nil, fmt.Errorf("Indent: %v", err)
+	}
+	return buf.Bytes(), nil
+}
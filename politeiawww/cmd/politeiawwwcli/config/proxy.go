@@ -0,0 +1,54 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ProxyURL returns the URL of the proxy that requests to politeiawww are sent
+// through.  The proxy user and password are added to the URL when they are
+// set.  Nil is returned when no proxy has been configured, in which case the
+// proxy environment variables apply.
+//
+// HTTP and HTTPS proxies are used for https hosts by tunneling the connection
+// with CONNECT.  SOCKS5 proxies resolve the host name on the proxy, which is
+// required when connecting to onion services through Tor.
+func (cfg *Config) ProxyURL() (*url.URL, error) {
+	if cfg.Proxy == "" {
+		if cfg.ProxyUser != "" || cfg.ProxyPass != "" {
+			return nil, fmt.Errorf("proxyuser and proxypass require " +
+				"a proxy")
+		}
+		return nil, nil
+	}
+
+	u, err := url.Parse(cfg.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("parse proxy: %v", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy scheme must be http, https or " +
+			"socks5")
+	}
+	if u.Host == "" || u.Port() == "" {
+		return nil, fmt.Errorf("proxy must include a host and port")
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("proxy must not include a path")
+	}
+
+	switch {
+	case cfg.ProxyUser != "":
+		u.User = url.UserPassword(cfg.ProxyUser, cfg.ProxyPass)
+	case cfg.ProxyPass != "":
+		return nil, fmt.Errorf("proxypass requires proxyuser")
+	}
+
+	return u, nil
+}
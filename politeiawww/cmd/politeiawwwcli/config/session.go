@@ -0,0 +1,381 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	keyring "github.com/zalando/go-keyring"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Session store backends.  The session store holds the session cookies, the
+// CSRF token and the auto login credentials of a profile.
+const (
+	// SessionStoreFile stores the session data in plain text files in the
+	// profile directory.
+	SessionStoreFile = "file"
+
+	// SessionStoreKeyring stores the session data in the keyring of the
+	// operating system.  The encrypted file store is used when no keyring
+	// is available.
+	SessionStoreKeyring = "keyring"
+
+	// SessionStoreEncrypted stores the session data in files in the
+	// profile directory that are encrypted with a key that is derived from
+	// the session passphrase.
+	SessionStoreEncrypted = "encrypted"
+)
+
+const (
+	// keyringService is the service name that session data is stored
+	// under in the keyring.
+	keyringService = "politeiawwwcli"
+
+	// encryptedFileExt is appended to the name of the encrypted session
+	// files.
+	encryptedFileExt = ".enc"
+
+	// sessionBoxVersion is the version of the encrypted session file
+	// format.
+	sessionBoxVersion = 1
+)
+
+// Scrypt parameters that are used to derive the key of the encrypted session
+// files from the session passphrase.  They are variables so that tests can use
+// cheaper parameters.
+var (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrWrongSessionPass is returned when an encrypted session file can not be
+// decrypted with the session passphrase.
+var ErrWrongSessionPass = errors.New("wrong session passphrase")
+
+// sessionStore persists the session data of the active profile.  The load
+// method returns nil data and no error when nothing has been stored under the
+// passed in name.  The remove method does not return an error when nothing has
+// been stored under the passed in name.
+type sessionStore interface {
+	load(name string) ([]byte, error)
+	save(name string, b []byte) error
+	remove(name string) error
+}
+
+// fileStore stores session data in plain text files in the profile directory.
+type fileStore struct {
+	cfg *Config
+}
+
+func (s *fileStore) load(name string) ([]byte, error) {
+	f, err := s.cfg.profileFilePath(name)
+	if err != nil {
+		return nil, fmt.Errorf("profileFilePath: %v", err)
+	}
+
+	if !fileExists(f) {
+		// Nothing to load
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("read file %v: %v", f, err)
+	}
+
+	return b, nil
+}
+
+func (s *fileStore) save(name string, b []byte) error {
+	f, err := s.cfg.profileFilePath(name)
+	if err != nil {
+		return fmt.Errorf("profileFilePath: %v", err)
+	}
+
+	err = ioutil.WriteFile(f, b, 0600)
+	if err != nil {
+		return fmt.Errorf("write file %v: %v", f, err)
+	}
+
+	return nil
+}
+
+func (s *fileStore) remove(name string) error {
+	f, err := s.cfg.profileFilePath(name)
+	if err != nil {
+		return fmt.Errorf("profileFilePath: %v", err)
+	}
+
+	err = os.Remove(f)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove %v: %v", f, err)
+	}
+
+	return nil
+}
+
+// keyringStore stores session data in the keyring of the operating system.
+// Entries are keyed by profile so that profiles do not share session data.
+type keyringStore struct {
+	profile string
+}
+
+func (s *keyringStore) key(name string) string {
+	return s.profile + "/" + name
+}
+
+func (s *keyringStore) load(name string) ([]byte, error) {
+	v, err := keyring.Get(keyringService, s.key(name))
+	switch {
+	case err == keyring.ErrNotFound:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("keyring get %v: %v", s.key(name), err)
+	}
+	return []byte(v), nil
+}
+
+func (s *keyringStore) save(name string, b []byte) error {
+	err := keyring.Set(keyringService, s.key(name), string(b))
+	if err != nil {
+		return fmt.Errorf("keyring set %v: %v", s.key(name), err)
+	}
+	return nil
+}
+
+func (s *keyringStore) remove(name string) error {
+	err := keyring.Delete(keyringService, s.key(name))
+	if err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("keyring delete %v: %v", s.key(name), err)
+	}
+	return nil
+}
+
+// checkKeyring returns an error if the keyring of the operating system can not
+// be used.  A lookup that fails for any reason other than the entry not
+// existing means that there is no usable keyring, e.g. because no secret
+// service is running.
+func checkKeyring(profile string) error {
+	s := keyringStore{profile: profile}
+	_, err := s.load(cookieFile)
+	return err
+}
+
+// sessionBox is the on-disk format of an encrypted session file.
+type sessionBox struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`  // Scrypt salt
+	Nonce   []byte `json:"nonce"` // Secretbox nonce
+	Box     []byte `json:"box"`   // Sealed session data
+}
+
+// encryptedFileStore stores session data in files in the profile directory
+// that are encrypted with a secretbox key that is derived from the session
+// passphrase using scrypt.
+type encryptedFileStore struct {
+	files      fileStore
+	passphrase []byte
+}
+
+func (s *encryptedFileStore) load(name string) ([]byte, error) {
+	b, err := s.files.load(name + encryptedFileExt)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	var sb sessionBox
+	err = json.Unmarshal(b, &sb)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal %v: %v", name, err)
+	}
+	if sb.Version != sessionBoxVersion {
+		return nil, fmt.Errorf("%v: unsupported version %v", name,
+			sb.Version)
+	}
+	if len(sb.Nonce) != 24 {
+		return nil, fmt.Errorf("%v: invalid nonce", name)
+	}
+
+	key, err := deriveSessionKey(s.passphrase, sb.Salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], sb.Nonce)
+	data, ok := secretbox.Open(nil, sb.Box, &nonce, key)
+	if !ok {
+		return nil, ErrWrongSessionPass
+	}
+
+	return data, nil
+}
+
+func (s *encryptedFileStore) save(name string, b []byte) error {
+	sb := sessionBox{
+		Version: sessionBoxVersion,
+		Salt:    make([]byte, 32),
+		Nonce:   make([]byte, 24),
+	}
+	_, err := rand.Read(sb.Salt)
+	if err != nil {
+		return err
+	}
+	_, err = rand.Read(sb.Nonce)
+	if err != nil {
+		return err
+	}
+
+	key, err := deriveSessionKey(s.passphrase, sb.Salt)
+	if err != nil {
+		return err
+	}
+	var nonce [24]byte
+	copy(nonce[:], sb.Nonce)
+	sb.Box = secretbox.Seal(nil, b, &nonce, key)
+
+	eb, err := json.Marshal(sb)
+	if err != nil {
+		return fmt.Errorf("marshal %v: %v", name, err)
+	}
+	return s.files.save(name+encryptedFileExt, eb)
+}
+
+func (s *encryptedFileStore) remove(name string) error {
+	return s.files.remove(name + encryptedFileExt)
+}
+
+// deriveSessionKey derives the secretbox key of an encrypted session file from
+// the passphrase and salt.
+func deriveSessionKey(passphrase, salt []byte) (*[32]byte, error) {
+	b, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], b)
+	return &key, nil
+}
+
+// promptSessionPass prompts the user for the session passphrase.
+func promptSessionPass() ([]byte, error) {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("sessionpass must be set when stdin is " +
+			"not a terminal")
+	}
+	for {
+		fmt.Printf("Enter the session passphrase: ")
+		pass, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("\n")
+
+		pass = bytes.TrimSpace(pass)
+		if len(pass) == 0 {
+			continue
+		}
+
+		return pass, nil
+	}
+}
+
+// setupSessionStore sets up the session store that was selected by the
+// sessionstore option.  The encrypted file store is used in place of the
+// keyring when no keyring is available.  Session data that was stored in
+// plain text files is moved into the keyring or encrypted store so that it
+// does not remain on disk unprotected.
+func (cfg *Config) setupSessionStore() error {
+	files := fileStore{cfg: cfg}
+
+	switch cfg.SessionStore {
+	case SessionStoreFile:
+		cfg.session = &files
+		return nil
+	case SessionStoreKeyring:
+		profile, err := cfg.profileName()
+		if err != nil {
+			return err
+		}
+		err = checkKeyring(profile)
+		if err == nil {
+			cfg.session = &keyringStore{profile: profile}
+			break
+		}
+		fmt.Fprintf(os.Stderr, "Keyring not available, using encrypted "+
+			"session files: %v\n", err)
+		fallthrough
+	case SessionStoreEncrypted:
+		pass := []byte(cfg.SessionPass)
+		if len(pass) == 0 {
+			var err error
+			pass, err = promptSessionPass()
+			if err != nil {
+				return err
+			}
+		}
+		cfg.session = &encryptedFileStore{
+			files:      files,
+			passphrase: pass,
+		}
+	default:
+		return fmt.Errorf("invalid sessionstore %q; must be %v, %v or %v",
+			cfg.SessionStore, SessionStoreFile, SessionStoreKeyring,
+			SessionStoreEncrypted)
+	}
+
+	return migrateSessionFiles(&files, cfg.session)
+}
+
+// migrateSessionFiles moves the session data that is stored in plain text
+// files into the passed in store.  Data that already exists in the store is
+// not overwritten; the plain text file is removed either way.
+func migrateSessionFiles(files *fileStore, s sessionStore) error {
+	for _, name := range []string{cookieFile, csrfFile, credentialsFile} {
+		b, err := files.load(name)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			continue
+		}
+
+		existing, err := s.load(name)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			err = s.save(name, b)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = files.remove(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sessionStore returns the session store of the profile.  Session data is
+// stored in plain text files if no session store has been set up.
+func (cfg *Config) sessionStore() sessionStore {
+	if cfg.session == nil {
+		return &fileStore{cfg: cfg}
+	}
+	return cfg.session
+}
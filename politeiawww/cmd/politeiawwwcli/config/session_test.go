@@ -0,0 +1,242 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	keyring "github.com/zalando/go-keyring"
+)
+
+// newSessionTestConfig returns a config whose profile directory is a
+// temporary directory.  The returned function removes the directory.
+func newSessionTestConfig(t *testing.T, store string) (*Config, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "politeiawwwcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{
+		Host:         "https://127.0.0.1:4443",
+		DataDir:      dir,
+		SessionStore: store,
+		SessionPass:  "passphrase",
+	}
+	profileDir, err := cfg.profileDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(profileDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use cheap scrypt parameters
+	n := scryptN
+	scryptN = 1 << 4
+
+	return &cfg, func() {
+		scryptN = n
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSessionStore(t *testing.T) {
+	keyring.MockInit()
+
+	for _, store := range []string{SessionStoreFile, SessionStoreKeyring,
+		SessionStoreEncrypted} {
+		t.Run(store, func(t *testing.T) {
+			cfg, cleanup := newSessionTestConfig(t, store)
+			defer cleanup()
+
+			err := cfg.setupSessionStore()
+			if err != nil {
+				t.Fatalf("setupSessionStore: %v", err)
+			}
+			cookies := []*http.Cookie{{Name: "session", Value: "secret"}}
+			err = cfg.SaveCookies(cookies)
+			if err != nil {
+				t.Fatalf("SaveCookies: %v", err)
+			}
+			err = cfg.SaveCSRF("csrf")
+			if err != nil {
+				t.Fatalf("SaveCSRF: %v", err)
+			}
+
+			gotCookies, err := cfg.loadCookies()
+			if err != nil {
+				t.Fatalf("loadCookies: %v", err)
+			}
+			if len(gotCookies) != 1 || gotCookies[0].Value != "secret" {
+				t.Errorf("got cookies %v, want %v", gotCookies, cookies)
+			}
+			gotCSRF, err := cfg.loadCSRF()
+			if err != nil {
+				t.Fatalf("loadCSRF: %v", err)
+			}
+			if gotCSRF != "csrf" {
+				t.Errorf("got csrf %v, want csrf", gotCSRF)
+			}
+
+			// The session data must only be stored in plain text
+			// by the file store.
+			f, err := cfg.profileFilePath(cookieFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fileExists(f) != (store == SessionStoreFile) {
+				t.Errorf("plain text cookie file exists: %v",
+					fileExists(f))
+			}
+		})
+	}
+}
+
+func TestSessionStoreCredentials(t *testing.T) {
+	keyring.MockInit()
+
+	for _, store := range []string{SessionStoreKeyring,
+		SessionStoreEncrypted} {
+		t.Run(store, func(t *testing.T) {
+			cfg, cleanup := newSessionTestConfig(t, store)
+			defer cleanup()
+
+			err := cfg.setupSessionStore()
+			if err != nil {
+				t.Fatalf("setupSessionStore: %v", err)
+			}
+			err = cfg.SaveCredentials("user@example.com", "password")
+			if err != nil {
+				t.Fatalf("SaveCredentials: %v", err)
+			}
+
+			cr, err := cfg.loadCredentials()
+			if err != nil {
+				t.Fatalf("loadCredentials: %v", err)
+			}
+			if cr == nil || cr.Password != "password" {
+				t.Errorf("got credentials %v, want password", cr)
+			}
+			f, err := cfg.profileFilePath(credentialsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fileExists(f) {
+				t.Errorf("plain text credentials file exists")
+			}
+
+			err = cfg.ClearCredentials()
+			if err != nil {
+				t.Fatalf("ClearCredentials: %v", err)
+			}
+			cr, err = cfg.loadCredentials()
+			if err != nil {
+				t.Fatalf("loadCredentials: %v", err)
+			}
+			if cr != nil {
+				t.Errorf("credentials were not cleared")
+			}
+
+			// Clearing credentials that are not stored is not
+			// an error.
+			err = cfg.ClearCredentials()
+			if err != nil {
+				t.Fatalf("ClearCredentials: %v", err)
+			}
+		})
+	}
+}
+
+func TestEncryptedSessionWrongPass(t *testing.T) {
+	cfg, cleanup := newSessionTestConfig(t, SessionStoreEncrypted)
+	defer cleanup()
+
+	err := cfg.setupSessionStore()
+	if err != nil {
+		t.Fatalf("setupSessionStore: %v", err)
+	}
+	err = cfg.SaveCSRF("csrf")
+	if err != nil {
+		t.Fatalf("SaveCSRF: %v", err)
+	}
+
+	// The encrypted file must not contain the plain text token
+	f, err := cfg.profileFilePath(csrfFile + encryptedFileExt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("csrf")) {
+		t.Fatalf("encrypted file contains the plain text token")
+	}
+
+	cfg.SessionPass = "wrong"
+	err = cfg.setupSessionStore()
+	if err != nil {
+		t.Fatalf("setupSessionStore: %v", err)
+	}
+	_, err = cfg.loadCSRF()
+	if err != ErrWrongSessionPass {
+		t.Fatalf("got error %v, want %v", err, ErrWrongSessionPass)
+	}
+}
+
+func TestMigrateSessionFiles(t *testing.T) {
+	keyring.MockInit()
+
+	cfg, cleanup := newSessionTestConfig(t, SessionStoreKeyring)
+	defer cleanup()
+
+	// Store the session data in plain text files first
+	cfg.SessionStore = SessionStoreFile
+	err := cfg.setupSessionStore()
+	if err != nil {
+		t.Fatalf("setupSessionStore: %v", err)
+	}
+	err = cfg.SaveCSRF("csrf")
+	if err != nil {
+		t.Fatalf("SaveCSRF: %v", err)
+	}
+
+	// Switching to the keyring moves the session data into it
+	cfg.SessionStore = SessionStoreKeyring
+	err = cfg.setupSessionStore()
+	if err != nil {
+		t.Fatalf("setupSessionStore: %v", err)
+	}
+	got, err := cfg.loadCSRF()
+	if err != nil {
+		t.Fatalf("loadCSRF: %v", err)
+	}
+	if got != "csrf" {
+		t.Errorf("got csrf %v, want csrf", got)
+	}
+	f, err := cfg.profileFilePath(csrfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(f) {
+		t.Errorf("plain text csrf file was not removed")
+	}
+}
+
+func TestInvalidSessionStore(t *testing.T) {
+	cfg, cleanup := newSessionTestConfig(t, "invalid")
+	defer cleanup()
+
+	err := cfg.setupSessionStore()
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
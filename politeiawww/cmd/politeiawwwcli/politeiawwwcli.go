@@ -5,12 +5,14 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 
 	flags "github.com/jessevdk/go-flags"
 
+	"github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/client"
 	"github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/commands"
 	"github.com/decred/politeia/politeiawww/cmd/politeiawwwcli/config"
@@ -21,6 +23,49 @@ type politeiawwwcli struct {
 	Commands commands.Cmds
 }
 
+// jsonOnly is set when the json-only option has been specified.  In json-only
+// mode errors are printed to stderr as JSON.
+var jsonOnly bool
+
+// jsonError is the JSON representation of an error that is printed to stderr
+// in json-only mode.  The APIError fields are included when the error was
+// returned by politeiawww.
+type jsonError struct {
+	Error        string          `json:"error"`
+	HTTPCode     int             `json:"httpcode,omitempty"`
+	ErrorCode    v1.ErrorStatusT `json:"errorcode,omitempty"`
+	ErrorMessage string          `json:"errormessage,omitempty"`
+	ErrorContext []string        `json:"errorcontext,omitempty"`
+	RequestID    string          `json:"requestid,omitempty"`
+	Response     string          `json:"response,omitempty"`
+}
+
+// printError prints the passed in error to stderr.
+func printError(err error) {
+	if !jsonOnly {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
+	}
+
+	je := jsonError{
+		Error: err.Error(),
+	}
+	if e, ok := err.(*client.APIError); ok {
+		je.HTTPCode = e.HTTPCode
+		je.ErrorCode = e.ErrorCode
+		je.ErrorMessage = e.ErrorMessage
+		je.ErrorContext = e.ErrorContext
+		je.RequestID = e.RequestID
+		je.Response = e.Response
+	}
+	b, err := json.Marshal(je)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", je.Error)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", b)
+}
+
 func _main() error {
 	// Load config
 	cfg, err := config.Load()
@@ -28,6 +73,7 @@ func _main() error {
 		return fmt.Errorf("loading config: %v", err)
 	}
 	commands.SetConfig(cfg)
+	jsonOnly = cfg.JSONOnly
 
 	// Load client
 	c, err := client.New(cfg)
@@ -36,8 +82,9 @@ func _main() error {
 	}
 	commands.SetClient(c)
 
-	// Get politeiawww CSRF token
-	if cfg.CSRF == "" {
+	// Get politeiawww CSRF token.  The token is not needed when the
+	// requests are authenticated with an API token and CSRF is disabled.
+	if cfg.CSRF == "" && c.UsesCSRF() {
 		_, err := c.Version()
 		if err != nil {
 			if _, ok := err.(*url.Error); !ok {
@@ -50,14 +97,25 @@ func _main() error {
 		}
 	}
 
-	// Parse subcommand and execute
+	// Parse subcommand and execute.  Errors are printed by the parser
+	// unless they need to be printed as JSON.
+	opts := flags.Default
+	if jsonOnly {
+		opts &^= flags.PrintErrors
+	}
 	var cli politeiawwwcli
-	var parser = flags.NewParser(&cli, flags.Default)
+	var parser = flags.NewParser(&cli, opts)
 	if _, err := parser.Parse(); err != nil {
 		flagsErr, ok := err.(*flags.Error)
 		if ok && flagsErr.Type == flags.ErrHelp {
+			if jsonOnly {
+				fmt.Fprintf(os.Stdout, "%v\n", flagsErr.Message)
+			}
 			os.Exit(0)
 		} else {
+			if jsonOnly {
+				printError(err)
+			}
 			os.Exit(1)
 		}
 	}
@@ -68,7 +126,7 @@ func _main() error {
 func main() {
 	err := _main()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
+		printError(err)
 		os.Exit(1)
 	}
 }
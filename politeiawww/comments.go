@@ -5,15 +5,22 @@
 package main
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/decred/politeia/decredplugin"
 	pd "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiad/api/v1/mime"
 	"github.com/decred/politeia/politeiad/cache"
 	www "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/user"
@@ -41,6 +48,9 @@ func (p *politeiawww) getComment(token, commentID string) (*www.Comment, error)
 	}
 	c.ResultVotes = score
 
+	// Lookup comment reactions
+	c.Reactions = p.commentReactions[token+commentID]
+
 	// Lookup author info
 	userID, ok := p.userPubkeys[c.PublicKey]
 	if !ok {
@@ -137,12 +147,150 @@ func validateComment(c www.NewComment) error {
 	}
 	// validate token
 	_, err := util.ConvertStringToken(c.Token)
-	if err != nil && err.Error() == "invalid censorship token size" {
-		err = www.UserError{
-			ErrorCode: www.ErrorStatusInvalidCensorshipToken,
+	if err != nil {
+		if err.Error() == "invalid censorship token size" {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusInvalidCensorshipToken,
+			}
+		}
+		return err
+	}
+	return validateCommentFiles(c.Files)
+}
+
+// validateCommentFiles verifies that the files attached to a comment follow
+// the comment file policy and that their digests and MIME types match their
+// payloads.  The limits are stricter than the proposal limits since comment
+// files are only meant for small screenshots and the like.
+func validateCommentFiles(files []www.File) error {
+	if len(files) > www.PolicyMaxCommentFiles {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusMaxCommentFilesExceeded,
+		}
+	}
+
+	filenames := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		// Filenames may not contain a path and must be unique
+		if f.Name == "" || filepath.Base(f.Name) != f.Name {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidFilename,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		if _, ok := filenames[f.Name]; ok {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusProposalDuplicateFilenames,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		filenames[f.Name] = struct{}{}
+
+		// Check the payload size before doing anything else with it
+		if len(f.Payload) >
+			base64.StdEncoding.EncodedLen(www.PolicyMaxCommentFileSize) {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusMaxCommentFileSizeExceeded,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		data, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidBase64,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		if len(data) == 0 {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidInput,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		if len(data) > www.PolicyMaxCommentFileSize {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusMaxCommentFileSizeExceeded,
+				ErrorContext: []string{f.Name},
+			}
+		}
+
+		d, err := hex.DecodeString(f.Digest)
+		if err != nil || !bytes.Equal(d, util.Digest(data)) {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidFileDigest,
+				ErrorContext: []string{f.Name},
+			}
+		}
+
+		detected := mime.DetectMimeType(data)
+		if detected != f.MIME {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidMIMEType,
+				ErrorContext: []string{f.Name, detected},
+			}
+		}
+		if !mime.MimeValid(f.MIME) {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusUnsupportedMIMEType,
+				ErrorContext: []string{f.Name, f.MIME},
+			}
 		}
 	}
-	return err
+
+	return nil
+}
+
+// signatureElementsWithFiles returns the passed in elements followed by the
+// digests of the passed in files.  Without files the elements are returned as
+// is.  With files every element is prefixed with its length and a colon so
+// that the end of the comment text can not be confused with a digest.  The
+// length prefix also sets these messages apart from the messages without
+// files since those start with the hex encoded token.
+func signatureElementsWithFiles(files []www.File, elements ...string) []string {
+	if len(files) == 0 {
+		return elements
+	}
+	for _, f := range files {
+		elements = append(elements, f.Digest)
+	}
+	prefixed := make([]string, 0, len(elements))
+	for _, v := range elements {
+		prefixed = append(prefixed, strconv.Itoa(len(v))+":"+v)
+	}
+	return prefixed
+}
+
+// commentSignatureElements returns the elements that are signed by the author
+// of a new comment: the token, the parent ID, the comment and the digests of
+// the attached files, in order.
+func commentSignatureElements(nc www.NewComment) []string {
+	return signatureElementsWithFiles(nc.Files, nc.Token, nc.ParentID,
+		nc.Comment)
+}
+
+// editCommentSignatureElements returns the elements that are signed by the
+// author of a comment edit: the token, the comment ID, the new comment and the
+// digests of the files that are attached to the comment, in order.  The files
+// are not changed by an edit so they are passed in from the existing comment.
+func editCommentSignatureElements(ec www.EditComment, files []www.File) []string {
+	return signatureElementsWithFiles(files, ec.Token, ec.CommentID,
+		ec.Comment)
+}
+
+// allowComment records a comment by the passed in user on the passed in
+// proposal and returns a rate limit error if the user has exceeded the comment
+// rate limit of the proposal.  The error context contains the number of
+// seconds until the user can comment on the proposal again.
+func (p *politeiawww) allowComment(userID, token string) error {
+	ok, wait := p.commentLimiter.allow(userID + token)
+	if ok {
+		return nil
+	}
+	secs := int64(math.Ceil(wait.Seconds()))
+	return www.UserError{
+		ErrorCode:    www.ErrorStatusRateLimitExceeded,
+		ErrorContext: []string{strconv.FormatInt(secs, 10)},
+	}
 }
 
 // ProcessNewComment sends a new comment decred plugin command to politeaid
@@ -159,7 +307,7 @@ func (p *politeiawww) ProcessNewComment(nc www.NewComment, u *user.User) (*www.N
 
 	// Verify authenticity
 	err := checkPublicKeyAndSignature(u, nc.PublicKey, nc.Signature,
-		nc.Token, nc.ParentID, nc.Comment)
+		commentSignatureElements(nc)...)
 	if err != nil {
 		return nil, err
 	}
@@ -206,6 +354,13 @@ func (p *politeiawww) ProcessNewComment(nc www.NewComment, u *user.User) (*www.N
 		}
 	}
 
+	// Ensure the user has not exceeded the comment rate limit of the
+	// proposal
+	err = p.allowComment(u.ID.String(), nc.Token)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup plugin command
 	challenge, err := util.Random(pd.ChallengeSize)
 	if err != nil {
@@ -420,12 +575,21 @@ func (p *politeiawww) ProcessCensorComment(cc www.CensorComment, u *user.User) (
 		return nil, err
 	}
 
-	// Ensure censor reason is present
-	if cc.Reason == "" {
+	// Ensure censor reason is present and long enough to be
+	// meaningful
+	reason := strings.TrimSpace(cc.Reason)
+	if reason == "" {
 		return nil, www.UserError{
 			ErrorCode: www.ErrorStatusCensorReasonCannotBeBlank,
 		}
 	}
+	if len(reason) < www.PolicyMinCensorReasonLength {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("censor reason must be "+
+				"at least %v characters", www.PolicyMinCensorReasonLength)},
+		}
+	}
 
 	// Ensure comment exists and has not already been censored
 	c, err := p.decredGetComment(cc.Token, cc.CommentID)
@@ -505,3 +669,525 @@ func (p *politeiawww) ProcessCensorComment(cc www.CensorComment, u *user.User) (
 		Receipt: ccr.Receipt,
 	}, nil
 }
+
+// ProcessEditComment sends an edit comment decred plugin command to politeiad
+// then fetches the edited comment from the cache and returns it.  Only the
+// comment author may edit a comment and only within the comment edit period.
+// politeiad preserves the original comment text in the comments journal.
+func (p *politeiawww) ProcessEditComment(ec www.EditComment, u *user.User) (*www.EditCommentReply, error) {
+	log.Tracef("ProcessEditComment: %v %v %v", ec.Token, ec.CommentID, u.ID)
+
+	// Verify public key
+	_, err := checkPublicKey(u, ec.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate comment
+	err = validateComment(www.NewComment{
+		Token:   ec.Token,
+		Comment: ec.Comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure comment exists and has not been censored or deleted
+	dc, err := p.decredGetComment(ec.Token, ec.CommentID)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusCommentNotFound,
+			}
+		}
+		return nil, err
+	}
+	if dc.Censored || dc.Deleted != 0 {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusCommentNotFound,
+		}
+	}
+
+	// Verify authenticity.  The signature covers the digests of the
+	// files that are attached to the comment.
+	err = checkPublicKeyAndSignature(u, ec.PublicKey, ec.Signature,
+		editCommentSignatureElements(ec,
+			convertCommentFilesFromDecred(dc.Files))...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure user is the comment author
+	p.RLock()
+	authorID := p.userPubkeys[dc.PublicKey]
+	p.RUnlock()
+	if authorID != u.ID.String() {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserNotCommentAuthor,
+		}
+	}
+
+	// Ensure the comment edit period has not expired
+	if commentEditPeriodExpired(dc.Timestamp, time.Now()) {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusCommentEditPeriodExpired,
+		}
+	}
+
+	// Ensure proposal exists and is public
+	pr, err := p.getProp(ec.Token)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusProposalNotFound,
+			}
+		}
+		return nil, err
+	}
+
+	if pr.Status != www.PropStatusPublic {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusCannotCommentOnProp,
+		}
+	}
+
+	// Ensure proposal voting has not ended
+	vdr, err := p.decredVoteDetails(ec.Token)
+	if err != nil {
+		return nil, fmt.Errorf("decredVoteDetails: %v", err)
+	}
+	vd := convertVoteDetailsReplyFromDecred(*vdr)
+
+	bb, err := p.getBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("getBestBlock: %v", err)
+	}
+
+	s := getVoteStatus(vd.AuthorizeVoteReply, vd.StartVoteReply, bb)
+	if s == www.PropVoteStatusFinished {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongVoteStatus,
+		}
+	}
+
+	// Setup plugin command
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := convertEditCommentToDecred(ec)
+	payload, err := decredplugin.EncodeEditComment(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := pd.PluginCommand{
+		Challenge: hex.EncodeToString(challenge),
+		ID:        decredplugin.ID,
+		Command:   decredplugin.CmdEditComment,
+		CommandID: decredplugin.CmdEditComment,
+		Payload:   string(payload),
+	}
+
+	// Send plugin request
+	responseBody, err := p.makeRequest(http.MethodPost,
+		pd.PluginCommandRoute, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle response
+	var reply pd.PluginCommandReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal "+
+			"PluginCommandReply: %v", err)
+	}
+
+	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = decredplugin.DecodeEditCommentReply([]byte(reply.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	// Get edited comment from cache
+	c, err := p.getComment(ec.Token, ec.CommentID)
+	if err != nil {
+		return nil, fmt.Errorf("getComment: %v", err)
+	}
+
+	return &www.EditCommentReply{
+		Comment: *c,
+	}, nil
+}
+
+// commentEditPeriodExpired returns whether the period during which the author
+// may edit a comment that was submitted at the given UNIX timestamp has
+// expired.
+func commentEditPeriodExpired(timestamp int64, now time.Time) bool {
+	return now.Unix() > timestamp+www.PolicyCommentEditPeriod
+}
+
+// ProcessDeleteComment sends a delete comment decred plugin command to
+// politeiad then fetches the deleted comment from the cache and returns it.
+// Only the comment author may delete a comment and only within the comment
+// delete period.  The deleted comment keeps its place in the comment thread
+// with its text and files removed.  politeiad preserves the original comment
+// in the comments journal.
+func (p *politeiawww) ProcessDeleteComment(dc www.DeleteComment, u *user.User) (*www.DeleteCommentReply, error) {
+	log.Tracef("ProcessDeleteComment: %v %v %v", dc.Token, dc.CommentID,
+		u.ID)
+
+	// Verify authenticity
+	err := checkPublicKeyAndSignature(u, dc.PublicKey, dc.Signature,
+		dc.Token, dc.CommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure comment exists and has not been censored or deleted
+	c, err := p.decredGetComment(dc.Token, dc.CommentID)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusCommentNotFound,
+			}
+		}
+		return nil, err
+	}
+	if c.Censored || c.Deleted != 0 {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusCommentNotFound,
+		}
+	}
+
+	// Ensure user is the comment author
+	p.RLock()
+	authorID := p.userPubkeys[c.PublicKey]
+	p.RUnlock()
+	if authorID != u.ID.String() {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserNotCommentAuthor,
+		}
+	}
+
+	// Ensure the comment delete period has not expired
+	if commentDeletePeriodExpired(c.Timestamp, time.Now()) {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusCommentDeletePeriodExpired,
+		}
+	}
+
+	// Ensure proposal voting has not ended
+	vdr, err := p.decredVoteDetails(dc.Token)
+	if err != nil {
+		return nil, fmt.Errorf("decredVoteDetails: %v", err)
+	}
+	vd := convertVoteDetailsReplyFromDecred(*vdr)
+
+	bb, err := p.getBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("getBestBlock: %v", err)
+	}
+
+	s := getVoteStatus(vd.AuthorizeVoteReply, vd.StartVoteReply, bb)
+	if s == www.PropVoteStatusFinished {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongVoteStatus,
+		}
+	}
+
+	// Setup plugin command
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ddc := convertDeleteCommentToDecred(dc)
+	payload, err := decredplugin.EncodeDeleteComment(ddc)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := pd.PluginCommand{
+		Challenge: hex.EncodeToString(challenge),
+		ID:        decredplugin.ID,
+		Command:   decredplugin.CmdDeleteComment,
+		CommandID: decredplugin.CmdDeleteComment,
+		Payload:   string(payload),
+	}
+
+	// Send plugin request
+	responseBody, err := p.makeRequest(http.MethodPost,
+		pd.PluginCommandRoute, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle response
+	var reply pd.PluginCommandReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal "+
+			"PluginCommandReply: %v", err)
+	}
+
+	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	dcr, err := decredplugin.DecodeDeleteCommentReply([]byte(reply.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	// Get deleted comment from cache
+	wc, err := p.getComment(dc.Token, dc.CommentID)
+	if err != nil {
+		return nil, fmt.Errorf("getComment: %v", err)
+	}
+
+	return &www.DeleteCommentReply{
+		Comment: *wc,
+		Receipt: dcr.Receipt,
+	}, nil
+}
+
+// commentDeletePeriodExpired returns whether the period during which the
+// author may delete a comment that was submitted at the given UNIX timestamp
+// has expired.
+func commentDeletePeriodExpired(timestamp int64, now time.Time) bool {
+	return now.Unix() > timestamp+www.PolicyCommentDeletePeriod
+}
+
+// commentCount returns the number of the passed in comments and the
+// timestamp of the most recent one.
+func commentCount(comments []decredplugin.Comment) www.CommentCount {
+	var last int64
+	for _, v := range comments {
+		if v.Timestamp > last {
+			last = v.Timestamp
+		}
+	}
+	return www.CommentCount{
+		NumComments: uint(len(comments)),
+		LastComment: last,
+	}
+}
+
+// getCommentCount returns the comment count of the specified proposal.  The
+// comments never leave politeiawww so only the count is sent to the client.
+func (p *politeiawww) getCommentCount(token string) (*www.CommentCount, error) {
+	dc, err := p.decredGetComments(token)
+	if err != nil {
+		return nil, fmt.Errorf("decredGetComments: %v", err)
+	}
+	cc := commentCount(dc)
+	return &cc, nil
+}
+
+// processCommentCount returns the number of comments of a proposal and the
+// time of the most recent comment without returning the comments.
+func (p *politeiawww) processCommentCount(token string) (*www.CommentCountReply, error) {
+	log.Tracef("processCommentCount: %v", token)
+
+	cc, err := p.getCommentCount(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.CommentCountReply{
+		CommentCount: *cc,
+	}, nil
+}
+
+// processBatchCommentCounts returns the comment counts of multiple proposals
+// so that inventory views do not have to fetch the comments of every
+// proposal that they show.
+func (p *politeiawww) processBatchCommentCounts(bcc www.BatchCommentCounts) (*www.BatchCommentCountsReply, error) {
+	log.Tracef("processBatchCommentCounts: %v", len(bcc.Tokens))
+
+	if len(bcc.Tokens) == 0 ||
+		len(bcc.Tokens) > www.CommentCountsBatchSize {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("batch must contain "+
+				"between 1 and %v tokens", www.CommentCountsBatchSize)},
+		}
+	}
+
+	// Validate all tokens before fetching anything
+	for _, v := range bcc.Tokens {
+		_, err := util.ConvertStringToken(v)
+		if err != nil {
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidCensorshipToken,
+				ErrorContext: []string{v},
+			}
+		}
+	}
+
+	counts := make(map[string]www.CommentCount, len(bcc.Tokens))
+	for _, v := range bcc.Tokens {
+		if _, ok := counts[v]; ok {
+			continue
+		}
+
+		cc, err := p.getCommentCount(v)
+		if err != nil {
+			return nil, err
+		}
+		counts[v] = *cc
+	}
+
+	return &www.BatchCommentCountsReply{
+		CommentCounts: counts,
+	}, nil
+}
+
+// ProcessUserComments returns a page of the comments that the specified user
+// has made across all proposals.  Users may only retrieve their own comments
+// unless they are an admin.
+func (p *politeiawww) ProcessUserComments(uc www.UserComments, u *user.User) (*www.UserCommentsReply, error) {
+	log.Tracef("ProcessUserComments: %v", uc.UserID)
+
+	if uc.UserID != u.ID.String() && !u.Admin {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserActionNotAllowed,
+		}
+	}
+
+	comments, err := p.getUserComments(uc.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.UserCommentsReply{
+		Comments:      userCommentsPage(comments, uc.Page),
+		TotalComments: uint64(len(comments)),
+	}, nil
+}
+
+// ProcessUserCommentedProposals returns the proposals that the specified user
+// has commented on along with the number of comments that the user has made
+// on each of them.  Users may only retrieve their own commented proposals
+// unless they are an admin.
+func (p *politeiawww) ProcessUserCommentedProposals(ucp www.UserCommentedProposals, u *user.User) (*www.UserCommentedProposalsReply, error) {
+	log.Tracef("ProcessUserCommentedProposals: %v", ucp.UserID)
+
+	if ucp.UserID != u.ID.String() && !u.Admin {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserActionNotAllowed,
+		}
+	}
+
+	comments, err := p.getUserComments(ucp.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.UserCommentedProposalsReply{
+		Proposals: userCommentedProposals(comments),
+	}, nil
+}
+
+// getUserComments returns all of the comments that the specified user has
+// made across all proposals.  An error is returned if the user does not
+// exist.
+//
+// The cache does not index comments by author so the comments of every
+// vetted proposal are retrieved and filtered.  Comments can only be made on
+// vetted proposals.
+func (p *politeiawww) getUserComments(userID string) ([]www.Comment, error) {
+	// Verify user exists
+	_, err := p.getUserByIDStr(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := p.getAllProps()
+	if err != nil {
+		return nil, fmt.Errorf("getAllProps: %v", err)
+	}
+
+	comments := make([]www.Comment, 0)
+	for _, pr := range props {
+		if pr.State != www.PropStateVetted {
+			continue
+		}
+		pc, err := p.getPropComments(pr.CensorshipRecord.Token)
+		if err != nil {
+			return nil, fmt.Errorf("getPropComments %v: %v",
+				pr.CensorshipRecord.Token, err)
+		}
+		for _, c := range pc {
+			if c.UserID == userID {
+				comments = append(comments, c)
+			}
+		}
+	}
+
+	return comments, nil
+}
+
+// userCommentedProposals groups the passed in comments of a single user by
+// proposal.  The proposals are sorted by the timestamp of the latest comment,
+// newest first.
+func userCommentedProposals(comments []www.Comment) []www.UserCommentedProposal {
+	props := make(map[string]*www.UserCommentedProposal) // [token]proposal
+	for _, c := range comments {
+		ucp, ok := props[c.Token]
+		if !ok {
+			ucp = &www.UserCommentedProposal{
+				Token: c.Token,
+			}
+			props[c.Token] = ucp
+		}
+		ucp.NumComments++
+		if c.Timestamp > ucp.LatestComment {
+			ucp.LatestComment = c.Timestamp
+		}
+	}
+
+	reply := make([]www.UserCommentedProposal, 0, len(props))
+	for _, v := range props {
+		reply = append(reply, *v)
+	}
+	sort.Slice(reply, func(i, j int) bool {
+		if reply[i].LatestComment != reply[j].LatestComment {
+			return reply[i].LatestComment > reply[j].LatestComment
+		}
+		return reply[i].Token < reply[j].Token
+	})
+
+	return reply
+}
+
+// userCommentsPage sorts the passed in comments by timestamp, newest first,
+// and returns the requested page.  Pages start at 1; page 0 is treated as
+// page 1.
+func userCommentsPage(comments []www.Comment, page uint64) []www.Comment {
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].Timestamp > comments[j].Timestamp
+	})
+
+	if page == 0 {
+		page = 1
+	}
+	start := (page - 1) * www.UserCommentsPageSize
+	if start >= uint64(len(comments)) {
+		return []www.Comment{}
+	}
+	end := start + www.UserCommentsPageSize
+	if end > uint64(len(comments)) {
+		end = uint64(len(comments))
+	}
+
+	return comments[start:end]
+}
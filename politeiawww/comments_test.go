@@ -0,0 +1,523 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/decredplugin"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+func TestUserCommentsPage(t *testing.T) {
+	// Create one and a half pages of comments in ascending
+	// timestamp order
+	n := www.UserCommentsPageSize + www.UserCommentsPageSize/2
+	comments := make([]www.Comment, 0, n)
+	for i := 0; i < n; i++ {
+		comments = append(comments, www.Comment{
+			CommentID: strconv.Itoa(i),
+			Timestamp: int64(i),
+		})
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name      string
+		page      uint64
+		wantLen   int
+		wantFirst int64
+	}{
+		{"page zero", 0, www.UserCommentsPageSize, int64(n - 1)},
+		{"first page", 1, www.UserCommentsPageSize, int64(n - 1)},
+		{"partial page", 2, n - www.UserCommentsPageSize,
+			int64(n - 1 - www.UserCommentsPageSize)},
+		{"out of range", 3, 0, 0},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			page := userCommentsPage(comments, v.page)
+			if len(page) != v.wantLen {
+				t.Fatalf("got %v comments, want %v",
+					len(page), v.wantLen)
+			}
+			if len(page) > 0 && page[0].Timestamp != v.wantFirst {
+				t.Fatalf("got first timestamp %v, want %v",
+					page[0].Timestamp, v.wantFirst)
+			}
+		})
+	}
+}
+
+func TestProcessUserComments(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+	other, _ := newUser(t, p, false)
+	admin, _ := newUser(t, p, true)
+
+	// Setup tests
+	var tests = []struct {
+		name   string
+		userID string
+		user   *user.User
+		want   error
+	}{
+		{"other user", other.ID.String(), usr,
+			www.UserError{
+				ErrorCode: www.ErrorStatusUserActionNotAllowed,
+			}},
+		{"invalid user id", "invalid", admin,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidUUID,
+			}},
+		{"user not found", uuid.New().String(), admin,
+			www.UserError{
+				ErrorCode: www.ErrorStatusUserNotFound,
+			}},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.ProcessUserComments(www.UserComments{
+				UserID: v.userID,
+			}, v.user)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+}
+
+func TestUserCommentedProposals(t *testing.T) {
+	comments := []www.Comment{
+		{Token: "a", Timestamp: 1},
+		{Token: "b", Timestamp: 2},
+		{Token: "a", Timestamp: 5},
+		{Token: "c", Timestamp: 2},
+	}
+	want := []www.UserCommentedProposal{
+		{Token: "a", NumComments: 2, LatestComment: 5},
+		{Token: "b", NumComments: 1, LatestComment: 2},
+		{Token: "c", NumComments: 1, LatestComment: 2},
+	}
+
+	got := userCommentedProposals(comments)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	got = userCommentedProposals(nil)
+	if got == nil || len(got) != 0 {
+		t.Fatalf("got %v, want empty slice", got)
+	}
+}
+
+func TestProcessUserCommentedProposals(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+	other, _ := newUser(t, p, false)
+	admin, _ := newUser(t, p, true)
+
+	// Setup tests
+	var tests = []struct {
+		name   string
+		userID string
+		user   *user.User
+		want   error
+	}{
+		{"other user", other.ID.String(), usr,
+			www.UserError{
+				ErrorCode: www.ErrorStatusUserActionNotAllowed,
+			}},
+		{"invalid user id", "invalid", admin,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidUUID,
+			}},
+		{"user not found", uuid.New().String(), admin,
+			www.UserError{
+				ErrorCode: www.ErrorStatusUserNotFound,
+			}},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.ProcessUserCommentedProposals(
+				www.UserCommentedProposals{
+					UserID: v.userID,
+				}, v.user)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+}
+
+func TestProcessCensorComment(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, id := newUser(t, p, true)
+
+	// Setup tests
+	var tests = []struct {
+		name   string
+		reason string
+		want   error
+	}{
+		{"blank reason", "   ",
+			www.UserError{
+				ErrorCode: www.ErrorStatusCensorReasonCannotBeBlank,
+			}},
+		{"reason too short", "spam",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			}},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			cc := www.CensorComment{
+				Token:     "token",
+				CommentID: "1",
+				Reason:    v.reason,
+				PublicKey: hex.EncodeToString(id.Public.Key[:]),
+			}
+			sig := id.SignMessage([]byte(cc.Token + cc.CommentID +
+				cc.Reason))
+			cc.Signature = hex.EncodeToString(sig[:])
+
+			_, err := p.ProcessCensorComment(cc, admin)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+}
+
+func TestAllowComment(t *testing.T) {
+	now := time.Now()
+	p := politeiawww{
+		commentLimiter: newRateLimiter(2, time.Minute),
+	}
+	p.commentLimiter.now = func() time.Time {
+		return now
+	}
+
+	// The limit is tracked per user per proposal
+	for i := 0; i < 2; i++ {
+		err := p.allowComment("user", "token")
+		if err != nil {
+			t.Fatalf("comment %v: got error %v, want nil", i, err)
+		}
+	}
+	err := p.allowComment("user", "token")
+	want := www.UserError{
+		ErrorCode:    www.ErrorStatusRateLimitExceeded,
+		ErrorContext: []string{"60"},
+	}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+	err = p.allowComment("user", "other")
+	if err != nil {
+		t.Fatalf("other proposal: got error %v, want nil", err)
+	}
+	err = p.allowComment("other", "token")
+	if err != nil {
+		t.Fatalf("other user: got error %v, want nil", err)
+	}
+
+	// The limit is reset once the window ends
+	now = now.Add(time.Minute)
+	err = p.allowComment("user", "token")
+	if err != nil {
+		t.Fatalf("new window: got error %v, want nil", err)
+	}
+}
+
+func TestValidateCommentFiles(t *testing.T) {
+	png := *createFilePNG(t, false)
+	large := *createFilePNG(t, true)
+
+	badDigest := png
+	badDigest.Digest = hex.EncodeToString(make([]byte, 32))
+
+	badMIME := png
+	badMIME.MIME = "text/plain; charset=utf-8"
+
+	badPath := png
+	badPath.Name = "../" + png.Name
+
+	var tests = []struct {
+		name  string
+		files []www.File
+		want  error
+	}{
+		{"no files", nil, nil},
+		{"valid file", []www.File{png}, nil},
+		{"too many files",
+			[]www.File{png, *createFilePNG(t, false),
+				*createFilePNG(t, false)},
+			www.UserError{
+				ErrorCode: www.ErrorStatusMaxCommentFilesExceeded,
+			}},
+		{"duplicate filenames", []www.File{png, png},
+			www.UserError{
+				ErrorCode:    www.ErrorStatusProposalDuplicateFilenames,
+				ErrorContext: []string{png.Name},
+			}},
+		{"invalid filename", []www.File{badPath},
+			www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidFilename,
+				ErrorContext: []string{badPath.Name},
+			}},
+		{"file too large", []www.File{large},
+			www.UserError{
+				ErrorCode:    www.ErrorStatusMaxCommentFileSizeExceeded,
+				ErrorContext: []string{large.Name},
+			}},
+		{"invalid digest", []www.File{badDigest},
+			www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidFileDigest,
+				ErrorContext: []string{png.Name},
+			}},
+		{"invalid mime type", []www.File{badMIME},
+			www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidMIMEType,
+				ErrorContext: []string{png.Name, png.MIME},
+			}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateCommentFiles(test.files)
+			got := errToStr(err)
+			want := errToStr(test.want)
+			if got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCommentSignatureElements(t *testing.T) {
+	msg := func(nc www.NewComment) string {
+		return strings.Join(commentSignatureElements(nc), "")
+	}
+	digest := strings.Repeat("ab", 32)
+
+	// Comments without files sign the plain elements
+	got := msg(www.NewComment{Token: "token", ParentID: "1", Comment: "hi"})
+	if got != "token1hi" {
+		t.Fatalf("got %q, want %q", got, "token1hi")
+	}
+
+	// Elements are length prefixed when files are attached
+	got = msg(www.NewComment{
+		Token:    "token",
+		ParentID: "1",
+		Comment:  "hi",
+		Files:    []www.File{{Digest: digest}},
+	})
+	want := "5:token1:12:hi64:" + digest
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// The digest of a file can not be moved into the comment text
+	moved := msg(www.NewComment{
+		Token:    "token",
+		ParentID: "1",
+		Comment:  "hi64:",
+		Files:    []www.File{{Digest: digest}},
+	})
+	if moved == want {
+		t.Fatalf("comment text and digest are ambiguous")
+	}
+
+	// Edits sign the digests of the existing files
+	got = strings.Join(editCommentSignatureElements(www.EditComment{
+		Token:     "token",
+		CommentID: "2",
+		Comment:   "edited",
+	}, []www.File{{Digest: digest}}), "")
+	want = "5:token1:26:edited64:" + digest
+	if got != want {
+		t.Fatalf("edit: got %q, want %q", got, want)
+	}
+}
+
+func TestProcessDeleteComment(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	u, id := newUser(t, p, false)
+	_, otherID := newUser(t, p, false)
+
+	token := "abf0fd1fc1b8c1c9535685373dce6c54948b7eb018e17e3a8cea26a3c9b85684"
+	sign := func(msg string) string {
+		sig := id.SignMessage([]byte(msg))
+		return hex.EncodeToString(sig[:])
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		dc   www.DeleteComment
+		want error
+	}{
+		{"wrong signing key",
+			www.DeleteComment{
+				Token:     token,
+				CommentID: "1",
+				Signature: sign(token + "1"),
+				PublicKey: hex.EncodeToString(otherID.Public.Key[:]),
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidSigningKey,
+			}},
+		{"signature of other comment",
+			www.DeleteComment{
+				Token:     token,
+				CommentID: "1",
+				Signature: sign(token + "2"),
+				PublicKey: hex.EncodeToString(id.Public.Key[:]),
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidSignature,
+			}},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.ProcessDeleteComment(v.dc, u)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+}
+
+func TestCommentDeletePeriodExpired(t *testing.T) {
+	submitted := int64(1550000000)
+	deadline := time.Unix(submitted+www.PolicyCommentDeletePeriod, 0)
+
+	var tests = []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"just submitted", time.Unix(submitted, 0), false},
+		{"end of period", deadline, false},
+		{"after period", deadline.Add(time.Second), true},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := commentDeletePeriodExpired(submitted, v.now)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestCommentCount(t *testing.T) {
+	var tests = []struct {
+		name     string
+		comments []decredplugin.Comment
+		want     www.CommentCount
+	}{
+		{"no comments", nil, www.CommentCount{}},
+		{"most recent comment is not last",
+			[]decredplugin.Comment{
+				{CommentID: "1", Timestamp: 100},
+				{CommentID: "2", Timestamp: 300},
+				{CommentID: "3", Timestamp: 200},
+			},
+			www.CommentCount{
+				NumComments: 3,
+				LastComment: 300,
+			}},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := commentCount(v.comments)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestProcessBatchCommentCounts(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	token := "abf0fd1fc1b8c1c9535685373dce6c54948b7eb018e17e3a8cea26a3c9b85684"
+	tooMany := make([]string, www.CommentCountsBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = token
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name     string
+		tokens   []string
+		wantCode www.ErrorStatusT
+	}{
+		{"no tokens", nil, www.ErrorStatusInvalidInput},
+		{"too many tokens", tooMany, www.ErrorStatusInvalidInput},
+		{"invalid token", []string{token, "invalid"},
+			www.ErrorStatusInvalidCensorshipToken},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processBatchCommentCounts(www.BatchCommentCounts{
+				Tokens: v.tokens,
+			})
+			e, ok := err.(www.UserError)
+			if !ok {
+				t.Fatalf("got error %v, want UserError", err)
+			}
+			if e.ErrorCode != v.wantCode {
+				t.Errorf("got error code %v, want %v", e.ErrorCode,
+					v.wantCode)
+			}
+		})
+	}
+}
@@ -22,12 +22,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/decred/dcrd/hdkeychain"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	"github.com/decred/politeia/util/version"
 
 	"github.com/decred/politeia/politeiad/api/v1"
+	www "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/sharedconfig"
 	"github.com/decred/politeia/util"
 	flags "github.com/jessevdk/go-flags"
@@ -53,6 +55,54 @@ const (
 
 	defaultMailAddress = "Politeia <noreply@example.org>"
 
+	defaultSessionMaxAge         = 86400  // One day
+	defaultSessionAbsoluteMaxAge = 604800 // One week
+
+	// Impersonation sessions are only meant for short support sessions
+	// and can not be kept alive for longer than an hour.
+	defaultImpersonationMaxAge = 900  // 15 minutes
+	maxImpersonationMaxAge     = 3600 // One hour
+
+	// Request body size limits in bytes.  Proposal requests carry the
+	// base64 encoded proposal files, new comments can carry attachments
+	// and ballots can contain a vote for every eligible ticket, all other
+	// requests are small.
+	defaultMaxRequestSize         = 64 * 1024       // 64 KiB
+	defaultMaxProposalRequestSize = 5 * 1024 * 1024 // 5 MiB
+	defaultMaxCommentRequestSize  = 512 * 1024      // 512 KiB
+	defaultMaxBallotRequestSize   = 8 * 1024 * 1024 // 8 MiB
+
+	// defaultUsernameAvailableRateLimit is the number of username
+	// availability checks that a client can make per minute.
+	defaultUsernameAvailableRateLimit = 30
+
+	// defaultCommentRateLimit is the number of comments that a user can
+	// submit on a single proposal per comment rate interval.  It is high
+	// enough to not get in the way of a quick back and forth discussion.
+	defaultCommentRateLimit = 10
+
+	// defaultCommentRateInterval is the length of the window that the
+	// comment rate limit applies to.
+	defaultCommentRateInterval = time.Minute
+
+	// defaultShutdownTimeout is the amount of time that in-flight
+	// requests are given to complete on shutdown.
+	defaultShutdownTimeout = 30 * time.Second
+
+	// Paywall addresses are polled frequently right after a paywall is
+	// issued, when a payment is most likely to arrive, and then less
+	// often.  The interval is multiplied by the backoff after every poll
+	// that does not find a payment, up to the max interval.
+	defaultPaywallPollInterval    = 10 * time.Second
+	defaultPaywallPollMaxInterval = 10 * time.Minute
+	defaultPaywallPollBackoff     = 2.0
+
+	// defaultPurgeUnverifiedAge is the amount of time after the
+	// verification token of a user that never verified their email
+	// address expired after which the user is purged.  Purging deletes
+	// users permanently so it is disabled unless it is configured.
+	defaultPurgeUnverifiedAge = 0
+
 	// dust value can be found increasing the amount value until we get false
 	// from IsDustAmount function. Amounts can not be lower than dust
 	// func IsDustAmount(amount int64, relayFeePerKb int64) bool {
@@ -120,6 +170,65 @@ type config struct {
 	VoteDurationMax          uint32 `long:"votedurationmax" description:"Maximum duration of a proposal vote in blocks"`
 	AdminLogFile             string `long:"adminlogfile" description:"admin log filename (Default: admin.log)"`
 	Mode                     string `long:"mode" description:"Mode www runs as. Supported values: piwww"`
+	SessionMaxAge            int64  `long:"sessionmaxage" description:"Number of seconds of inactivity after which a session expires; each authenticated request extends the session"`
+	SessionAbsoluteMaxAge    int64  `long:"sessionabsolutemaxage" description:"Maximum number of seconds a session can be kept alive for, regardless of activity"`
+	ImpersonationMaxAge      int64  `long:"impersonationmaxage" description:"Number of seconds after which an admin impersonation session expires, regardless of activity"`
+	MaxRequestSize           int64  `long:"maxrequestsize" description:"Maximum size of a request body in bytes"`
+	MaxProposalRequestSize   int64  `long:"maxproposalrequestsize" description:"Maximum size of a new or edit proposal request body in bytes"`
+	MaxCommentRequestSize    int64  `long:"maxcommentrequestsize" description:"Maximum size of a new comment request body in bytes"`
+	MaxBallotRequestSize     int64  `long:"maxballotrequestsize" description:"Maximum size of a cast votes request body in bytes"`
+
+	// UsernameAvailableRateLimit is the number of username availability
+	// checks that a client can make per minute.
+	UsernameAvailableRateLimit int `long:"usernameavailableratelimit" description:"Number of username availability checks a client can make per minute"`
+
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header
+	// is honored when identifying the client of a rate limited request.
+	TrustedProxies []string `long:"trustedproxy" description:"Add the IP address or CIDR network of a reverse proxy whose X-Forwarded-For header is trusted when rate limiting"`
+
+	// CommentRateLimit is the number of comments that a user can submit
+	// on a single proposal per CommentRateInterval.
+	CommentRateLimit    int           `long:"commentratelimit" description:"Number of comments a user can submit on a single proposal per comment rate interval"`
+	CommentRateInterval time.Duration `long:"commentrateinterval" description:"Length of the window that the comment rate limit applies to"`
+
+	// CommentReactions are the reaction types that users can add to
+	// comments in addition to the up and down votes.  Only up and down
+	// votes are available when no reactions are configured.
+	CommentReactions []string `long:"commentreaction" description:"Add a reaction type that users can add to comments in addition to up and down votes"`
+
+	// ShutdownTimeout is the amount of time that in-flight requests are
+	// given to complete on shutdown.
+	ShutdownTimeout time.Duration `long:"shutdowntimeout" description:"Amount of time in-flight requests are given to complete on shutdown before their connections are closed"`
+
+	// PaywallPollInterval is the interval between the first polls of a
+	// paywall address.  The interval is multiplied by PaywallPollBackoff
+	// after each poll that does not find a payment, up to
+	// PaywallPollMaxInterval.
+	PaywallPollInterval    time.Duration `long:"paywallpollinterval" description:"Interval between the first polls of a paywall address"`
+	PaywallPollMaxInterval time.Duration `long:"paywallpollmaxinterval" description:"Maximum interval between polls of a paywall address"`
+	PaywallPollBackoff     float64       `long:"paywallpollbackoff" description:"Factor the paywall poll interval is multiplied by after each poll that does not find a payment; 1 disables the backoff"`
+
+	// PurgeUnverifiedAge is the amount of time after the verification
+	// token of a user that never verified their email address expired
+	// after which the user is purged.  Purging is disabled when it is 0.
+	PurgeUnverifiedAge time.Duration `long:"purgeunverifiedage" description:"Time after the verification token of an unverified user expired after which the user is deleted; 0 disables purging"`
+
+	// EmailTemplatesDir is the directory that translations of the user
+	// email templates are loaded from.  It contains a directory for every
+	// locale.
+	EmailTemplatesDir string `long:"emailtemplatesdir" description:"Directory containing a directory of translated email templates for every locale"`
+
+	// Password policy.  Passwords must have at least PasswordMinLength
+	// characters, contain the required character classes, have an
+	// estimated strength of at least PasswordMinStrength and must not be
+	// listed in PasswordBannedFile.
+	PasswordMinLength     uint   `long:"passwordminlength" description:"Minimum number of characters of a password"`
+	PasswordRequireUpper  bool   `long:"passwordrequireupper" description:"Require passwords to contain an upper case letter"`
+	PasswordRequireLower  bool   `long:"passwordrequirelower" description:"Require passwords to contain a lower case letter"`
+	PasswordRequireDigit  bool   `long:"passwordrequiredigit" description:"Require passwords to contain a digit"`
+	PasswordRequireSymbol bool   `long:"passwordrequiresymbol" description:"Require passwords to contain a symbol"`
+	PasswordMinStrength   uint   `long:"passwordminstrength" description:"Minimum estimated strength of a password from 0 (too guessable) to 4 (very unguessable)"`
+	PasswordBannedFile    string `long:"passwordbannedfile" description:"File containing passwords that are not accepted, one per line"`
 }
 
 // serviceOptions defines the configuration options for the rpc as a service
@@ -371,6 +480,26 @@ func loadConfig() (*config, []string, error) {
 		VoteDurationMin:          defaultVoteDurationMin,
 		VoteDurationMax:          defaultVoteDurationMax,
 		MailAddress:              defaultMailAddress,
+		SessionMaxAge:            defaultSessionMaxAge,
+		SessionAbsoluteMaxAge:    defaultSessionAbsoluteMaxAge,
+		ImpersonationMaxAge:      defaultImpersonationMaxAge,
+		MaxRequestSize:           defaultMaxRequestSize,
+		MaxProposalRequestSize:   defaultMaxProposalRequestSize,
+		MaxCommentRequestSize:    defaultMaxCommentRequestSize,
+		MaxBallotRequestSize:     defaultMaxBallotRequestSize,
+		ShutdownTimeout:          defaultShutdownTimeout,
+
+		UsernameAvailableRateLimit: defaultUsernameAvailableRateLimit,
+		CommentRateLimit:           defaultCommentRateLimit,
+		CommentRateInterval:        defaultCommentRateInterval,
+
+		PaywallPollInterval:    defaultPaywallPollInterval,
+		PaywallPollMaxInterval: defaultPaywallPollMaxInterval,
+		PaywallPollBackoff:     defaultPaywallPollBackoff,
+
+		PurgeUnverifiedAge: defaultPurgeUnverifiedAge,
+
+		PasswordMinLength: www.PolicyMinPasswordLength,
 	}
 
 	// Service options which are only added on Windows.
@@ -489,6 +618,116 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Verify session max ages
+	if cfg.SessionMaxAge <= 0 {
+		err := fmt.Errorf("sessionmaxage must be positive")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.SessionAbsoluteMaxAge < cfg.SessionMaxAge {
+		err := fmt.Errorf("sessionabsolutemaxage must be greater than " +
+			"or equal to sessionmaxage")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.ImpersonationMaxAge <= 0 ||
+		cfg.ImpersonationMaxAge > maxImpersonationMaxAge {
+		err := fmt.Errorf("impersonationmaxage must be positive and "+
+			"at most %v seconds", maxImpersonationMaxAge)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Verify request size limits
+	if cfg.MaxRequestSize <= 0 || cfg.MaxProposalRequestSize <= 0 ||
+		cfg.MaxCommentRequestSize <= 0 || cfg.MaxBallotRequestSize <= 0 {
+		err := fmt.Errorf("maxrequestsize, maxproposalrequestsize, " +
+			"maxcommentrequestsize and maxballotrequestsize must be " +
+			"positive")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Verify rate limits
+	if cfg.UsernameAvailableRateLimit <= 0 {
+		err := fmt.Errorf("usernameavailableratelimit must be positive")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	_, err = parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.CommentRateLimit <= 0 {
+		err := fmt.Errorf("commentratelimit must be positive")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.CommentRateInterval <= 0 {
+		err := fmt.Errorf("commentrateinterval must be positive")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Verify paywall polling
+	if cfg.PaywallPollInterval <= 0 {
+		err := fmt.Errorf("paywallpollinterval must be positive")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.PaywallPollMaxInterval < cfg.PaywallPollInterval {
+		err := fmt.Errorf("paywallpollmaxinterval must be greater than " +
+			"or equal to paywallpollinterval")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.PaywallPollBackoff < 1 {
+		err := fmt.Errorf("paywallpollbackoff must be at least 1")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Verify unverified user purging
+	if cfg.PurgeUnverifiedAge < 0 {
+		err := fmt.Errorf("purgeunverifiedage cannot be negative")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Verify comment reactions
+	err = validateCommentReactions(cfg.CommentReactions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Verify password policy
+	if cfg.PasswordMinLength == 0 {
+		err := fmt.Errorf("passwordminlength must be positive")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.PasswordMinLength > passwordDigestLength {
+		err := fmt.Errorf("passwordminlength cannot be greater than %v",
+			passwordDigestLength)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+	if cfg.PasswordMinStrength > util.PasswordStrengthMax {
+		err := fmt.Errorf("passwordminstrength cannot be greater than %v",
+			util.PasswordStrengthMax)
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
+	// Verify shutdown timeout
+	if cfg.ShutdownTimeout < 0 {
+		err := fmt.Errorf("shutdowntimeout cannot be negative")
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
+
 	// Create the home directory if it doesn't already exist.
 	funcName := "loadConfig"
 	err = os.MkdirAll(sharedconfig.DefaultHomeDir, 0700)
@@ -554,6 +793,8 @@ func loadConfig() (*config, []string, error) {
 	cfg.HTTPSKey = cleanAndExpandPath(cfg.HTTPSKey)
 	cfg.HTTPSCert = cleanAndExpandPath(cfg.HTTPSCert)
 	cfg.RPCCert = cleanAndExpandPath(cfg.RPCCert)
+	cfg.EmailTemplatesDir = cleanAndExpandPath(cfg.EmailTemplatesDir)
+	cfg.PasswordBannedFile = cleanAndExpandPath(cfg.PasswordBannedFile)
 
 	// Validate cache options.
 	switch {
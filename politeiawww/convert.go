@@ -5,6 +5,8 @@
 package main
 
 import (
+	"sort"
+
 	"github.com/decred/politeia/decredplugin"
 	pd "github.com/decred/politeia/politeiad/api/v1"
 	"github.com/decred/politeia/politeiad/cache"
@@ -206,6 +208,23 @@ func convertPropStatusFromCache(s cache.RecordStatusT) www.PropStatusT {
 	return www.PropStatusInvalid
 }
 
+// convertMetadataStreamsFromCache converts the metadata streams of a cache
+// record into the metadata streams that are returned by the API, ordered by
+// stream ID.
+func convertMetadataStreamsFromCache(mds []cache.MetadataStream) []www.MetadataStream {
+	m := make([]www.MetadataStream, 0, len(mds))
+	for _, v := range mds {
+		m = append(m, www.MetadataStream{
+			ID:      v.ID,
+			Payload: v.Payload,
+		})
+	}
+	sort.Slice(m, func(i, j int) bool {
+		return m[i].ID < m[j].ID
+	})
+	return m
+}
+
 func convertPropFromCache(r cache.Record) www.ProposalRecord {
 	// Decode markdown stream payloads
 	var bpm *BackendProposalMetadata
@@ -287,6 +306,8 @@ func convertPropFromCache(r cache.Record) www.ProposalRecord {
 		PublishedAt:         publishedAt,
 		CensoredAt:          censoredAt,
 		AbandonedAt:         abandonedAt,
+		LinkTo:              bpm.LinkTo,
+		LinkBy:              bpm.LinkBy,
 		CensorshipRecord: www.CensorshipRecord{
 			Token:     r.CensorshipRecord.Token,
 			Merkle:    r.CensorshipRecord.Merkle,
@@ -295,6 +316,38 @@ func convertPropFromCache(r cache.Record) www.ProposalRecord {
 	}
 }
 
+func convertCommentFilesToDecredPlugin(files []www.File) []decredplugin.CommentFile {
+	if len(files) == 0 {
+		return nil
+	}
+	cf := make([]decredplugin.CommentFile, 0, len(files))
+	for _, f := range files {
+		cf = append(cf, decredplugin.CommentFile{
+			Name:    f.Name,
+			MIME:    f.MIME,
+			Digest:  f.Digest,
+			Payload: f.Payload,
+		})
+	}
+	return cf
+}
+
+func convertCommentFilesFromDecred(files []decredplugin.CommentFile) []www.File {
+	if len(files) == 0 {
+		return nil
+	}
+	wf := make([]www.File, 0, len(files))
+	for _, f := range files {
+		wf = append(wf, www.File{
+			Name:    f.Name,
+			MIME:    f.MIME,
+			Digest:  f.Digest,
+			Payload: f.Payload,
+		})
+	}
+	return wf
+}
+
 func convertNewCommentToDecredPlugin(nc www.NewComment) decredplugin.NewComment {
 	return decredplugin.NewComment{
 		Token:     nc.Token,
@@ -302,6 +355,7 @@ func convertNewCommentToDecredPlugin(nc www.NewComment) decredplugin.NewComment
 		Comment:   nc.Comment,
 		Signature: nc.Signature,
 		PublicKey: nc.PublicKey,
+		Files:     convertCommentFilesToDecredPlugin(nc.Files),
 	}
 }
 
@@ -335,22 +389,56 @@ func convertCensorCommentToDecred(cc www.CensorComment) decredplugin.CensorComme
 	}
 }
 
+func convertEditCommentToDecred(ec www.EditComment) decredplugin.EditComment {
+	return decredplugin.EditComment{
+		Token:     ec.Token,
+		CommentID: ec.CommentID,
+		Comment:   ec.Comment,
+		Signature: ec.Signature,
+		PublicKey: ec.PublicKey,
+	}
+}
+
+func convertDeleteCommentToDecred(dc www.DeleteComment) decredplugin.DeleteComment {
+	return decredplugin.DeleteComment{
+		Token:     dc.Token,
+		CommentID: dc.CommentID,
+		Signature: dc.Signature,
+		PublicKey: dc.PublicKey,
+	}
+}
+
+func convertReactCommentToDecred(rc www.ReactComment) decredplugin.ReactComment {
+	return decredplugin.ReactComment{
+		Token:     rc.Token,
+		CommentID: rc.CommentID,
+		Reaction:  rc.Reaction,
+		Action:    rc.Action,
+		Signature: rc.Signature,
+		PublicKey: rc.PublicKey,
+	}
+}
+
 func convertCommentFromDecred(c decredplugin.Comment) www.Comment {
 	// ResultVotes, UserID, and Username are filled in as zero
 	// values since a cache comment does not contain this data.
 	return www.Comment{
-		Token:       c.Token,
-		ParentID:    c.ParentID,
-		Comment:     c.Comment,
-		Signature:   c.Signature,
-		PublicKey:   c.PublicKey,
-		CommentID:   c.CommentID,
-		Receipt:     c.Receipt,
-		Timestamp:   c.Timestamp,
-		ResultVotes: 0,
-		UserID:      "",
-		Username:    "",
-		Censored:    c.Censored,
+		Token:        c.Token,
+		ParentID:     c.ParentID,
+		Comment:      c.Comment,
+		Signature:    c.Signature,
+		PublicKey:    c.PublicKey,
+		Files:        convertCommentFilesFromDecred(c.Files),
+		CommentID:    c.CommentID,
+		Receipt:      c.Receipt,
+		Timestamp:    c.Timestamp,
+		ResultVotes:  0,
+		UserID:       "",
+		Username:     "",
+		Censored:     c.Censored,
+		Edited:       c.Edited,
+		CensorReason: c.CensorReason,
+		Deleted:      c.Deleted,
 	}
 }
 
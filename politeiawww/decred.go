@@ -112,6 +112,40 @@ func (p *politeiawww) decredCommentLikes(token, commentID string) ([]decredplugi
 	return clr.CommentLikes, nil
 }
 
+// decredCommentReactions sends the decred plugin commentreactions command to
+// the cache and returns all of the reactions to the passed in comment.
+func (p *politeiawww) decredCommentReactions(token, commentID string) ([]decredplugin.ReactComment, error) {
+	// Setup plugin command
+	cr := decredplugin.CommentReactions{
+		Token:     token,
+		CommentID: commentID,
+	}
+
+	payload, err := decredplugin.EncodeCommentReactions(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := cache.PluginCommand{
+		ID:             decredplugin.ID,
+		Command:        decredplugin.CmdCommentReactions,
+		CommandPayload: string(payload),
+	}
+
+	// Get comment reactions from cache
+	reply, err := p.cache.PluginExec(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	crr, err := decredplugin.DecodeCommentReactionsReply([]byte(reply.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	return crr.CommentReactions, nil
+}
+
 // decredPropCommentLikes sends the decred plugin proposalcommentslikes command
 // to the cache and returns all of the comment likes for the passed in proposal
 // token.
@@ -0,0 +1,343 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// draftsDirname is the name of the directory, relative to the data
+	// directory, that the proposal drafts are stored in.  The drafts of
+	// a user are stored in a single file that is named after the user
+	// ID.
+	draftsDirname = "drafts"
+
+	// draftIDSize is the size of a draft ID in bytes.
+	draftIDSize = 16
+)
+
+// draftsFile returns the path of the file that the drafts of the passed in
+// user are stored in.
+func (p *politeiawww) draftsFile(userID uuid.UUID) string {
+	return filepath.Join(p.cfg.DataDir, draftsDirname,
+		userID.String()+".json")
+}
+
+// _loadDrafts reads the drafts of the passed in user from disk.  A user that
+// has never saved a draft has no drafts file.
+//
+// This function must be called WITH the drafts mutex held.
+func (p *politeiawww) _loadDrafts(userID uuid.UUID) ([]www.Draft, error) {
+	b, err := ioutil.ReadFile(p.draftsFile(userID))
+	if os.IsNotExist(err) {
+		return []www.Draft{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var drafts []www.Draft
+	err = json.Unmarshal(b, &drafts)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal %v: %v", p.draftsFile(userID), err)
+	}
+	return drafts, nil
+}
+
+// _saveDrafts writes the drafts of the passed in user to disk.  The drafts
+// file is removed once the user has no drafts left.
+//
+// This function must be called WITH the drafts mutex held.
+func (p *politeiawww) _saveDrafts(userID uuid.UUID, drafts []www.Draft) error {
+	fp := p.draftsFile(userID)
+	if len(drafts) == 0 {
+		err := os.Remove(fp)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(fp), 0700)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(drafts)
+	if err != nil {
+		return err
+	}
+
+	return util.WriteFileAtomic(fp, b, 0600)
+}
+
+// validateDraftFiles verifies the files of a proposal draft and sets their
+// digest and MIME type.  Drafts are work in progress so only the limits that
+// protect the server are enforced; the proposal policy is enforced once the
+// draft is submitted as a proposal.
+func validateDraftFiles(files []www.File) error {
+	if len(files) == 0 {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusProposalMissingFiles,
+		}
+	}
+	if len(files) > www.PolicyMaxMDs+www.PolicyMaxImages {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusMaxImagesExceededPolicy,
+		}
+	}
+
+	var size int64
+	filenames := make(map[string]struct{}, len(files))
+	for i, f := range files {
+		// Filenames may not contain a path and must be unique
+		if f.Name == "" || filepath.Base(f.Name) != f.Name {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidFilename,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		if _, ok := filenames[f.Name]; ok {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusProposalDuplicateFilenames,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		filenames[f.Name] = struct{}{}
+
+		data, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidBase64,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		size += int64(len(data))
+		if size > www.PolicyMaxDraftSize {
+			return www.UserError{
+				ErrorCode: www.ErrorStatusMaxDraftSizeExceeded,
+			}
+		}
+
+		detected := mime.DetectMimeType(data)
+		if !mime.MimeValid(detected) {
+			return www.UserError{
+				ErrorCode:    www.ErrorStatusUnsupportedMIMEType,
+				ErrorContext: []string{f.Name, detected},
+			}
+		}
+		files[i].MIME = detected
+		files[i].Digest = hex.EncodeToString(util.Digest(data))
+	}
+
+	return nil
+}
+
+// convertDraftToSummary returns the summary of the passed in draft.
+func convertDraftToSummary(d www.Draft) www.DraftSummary {
+	// The payloads have been validated so the decoded size can be
+	// derived from the padded payload length.
+	var size int64
+	for _, f := range d.Files {
+		n := base64.StdEncoding.DecodedLen(len(f.Payload))
+		n -= len(f.Payload) - len(strings.TrimRight(f.Payload, "="))
+		size += int64(n)
+	}
+	return www.DraftSummary{
+		DraftID:   d.DraftID,
+		Name:      d.Name,
+		NumFiles:  len(d.Files),
+		Size:      size,
+		Timestamp: d.Timestamp,
+		Updated:   d.Updated,
+	}
+}
+
+// draftName returns the proposal name of a draft.  Drafts do not need to
+// have an index file yet so a draft without a name is not an error.
+func draftName(files []www.File) string {
+	name, err := getProposalName(files)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// processNewDraft saves a new proposal draft for the passed in user.
+func (p *politeiawww) processNewDraft(nd www.NewDraft, u *user.User) (*www.NewDraftReply, error) {
+	log.Tracef("processNewDraft: %v", u.ID)
+
+	err := validateDraftFiles(nd.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := util.Random(draftIDSize)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	d := www.Draft{
+		DraftID:   hex.EncodeToString(id),
+		Name:      draftName(nd.Files),
+		Files:     nd.Files,
+		Timestamp: now,
+		Updated:   now,
+	}
+
+	p.draftsMtx.Lock()
+	defer p.draftsMtx.Unlock()
+
+	drafts, err := p._loadDrafts(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(drafts) >= www.PolicyMaxDrafts {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusMaxDraftsExceeded,
+		}
+	}
+	err = p._saveDrafts(u.ID, append(drafts, d))
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.NewDraftReply{
+		Draft: convertDraftToSummary(d),
+	}, nil
+}
+
+// processEditDraft replaces the files of a draft of the passed in user.
+func (p *politeiawww) processEditDraft(ed www.EditDraft, u *user.User) (*www.EditDraftReply, error) {
+	log.Tracef("processEditDraft: %v %v", u.ID, ed.DraftID)
+
+	err := validateDraftFiles(ed.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	p.draftsMtx.Lock()
+	defer p.draftsMtx.Unlock()
+
+	drafts, err := p._loadDrafts(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range drafts {
+		if d.DraftID != ed.DraftID {
+			continue
+		}
+
+		d.Name = draftName(ed.Files)
+		d.Files = ed.Files
+		d.Updated = time.Now().Unix()
+		drafts[i] = d
+		err = p._saveDrafts(u.ID, drafts)
+		if err != nil {
+			return nil, err
+		}
+
+		return &www.EditDraftReply{
+			Draft: convertDraftToSummary(d),
+		}, nil
+	}
+
+	return nil, www.UserError{
+		ErrorCode: www.ErrorStatusDraftNotFound,
+	}
+}
+
+// processDeleteDraft deletes a draft of the passed in user.
+func (p *politeiawww) processDeleteDraft(dd www.DeleteDraft, u *user.User) (*www.DeleteDraftReply, error) {
+	log.Tracef("processDeleteDraft: %v %v", u.ID, dd.DraftID)
+
+	p.draftsMtx.Lock()
+	defer p.draftsMtx.Unlock()
+
+	drafts, err := p._loadDrafts(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range drafts {
+		if d.DraftID != dd.DraftID {
+			continue
+		}
+
+		drafts = append(drafts[:i], drafts[i+1:]...)
+		err = p._saveDrafts(u.ID, drafts)
+		if err != nil {
+			return nil, err
+		}
+		return &www.DeleteDraftReply{}, nil
+	}
+
+	return nil, www.UserError{
+		ErrorCode: www.ErrorStatusDraftNotFound,
+	}
+}
+
+// processDraftDetails returns a draft of the passed in user along with its
+// files.  Drafts of other users are reported as not found.
+func (p *politeiawww) processDraftDetails(dd www.DraftDetails, u *user.User) (*www.DraftDetailsReply, error) {
+	log.Tracef("processDraftDetails: %v %v", u.ID, dd.DraftID)
+
+	p.draftsMtx.Lock()
+	defer p.draftsMtx.Unlock()
+
+	drafts, err := p._loadDrafts(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range drafts {
+		if d.DraftID == dd.DraftID {
+			return &www.DraftDetailsReply{
+				Draft: d,
+			}, nil
+		}
+	}
+
+	return nil, www.UserError{
+		ErrorCode: www.ErrorStatusDraftNotFound,
+	}
+}
+
+// processUserDrafts returns the drafts of the passed in user, most recently
+// updated first.
+func (p *politeiawww) processUserDrafts(u *user.User) (*www.UserDraftsReply, error) {
+	log.Tracef("processUserDrafts: %v", u.ID)
+
+	p.draftsMtx.Lock()
+	drafts, err := p._loadDrafts(u.ID)
+	p.draftsMtx.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]www.DraftSummary, 0, len(drafts))
+	for _, d := range drafts {
+		summaries = append(summaries, convertDraftToSummary(d))
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Updated > summaries[j].Updated
+	})
+
+	return &www.UserDraftsReply{
+		Drafts: summaries,
+	}, nil
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestValidateDraftFiles(t *testing.T) {
+	md := createFileMD(t, 64, "Draft proposal title")
+	png := createFilePNG(t, false)
+
+	// The digest and MIME type are set by politeiawww
+	unset := *md
+	unset.MIME = ""
+	unset.Digest = ""
+
+	dup := *png
+	dup.Name = md.Name
+
+	badName := *png
+	badName.Name = "../" + png.Name
+
+	badPayload := *png
+	badPayload.Payload = "not base64"
+
+	pdf := *png
+	pdf.Payload = base64.StdEncoding.EncodeToString([]byte("%PDF-1.4"))
+
+	large := createFileMD(t, www.PolicyMaxDraftSize, "Draft proposal title")
+
+	tooMany := make([]www.File, 0, www.PolicyMaxMDs+www.PolicyMaxImages+1)
+	tooMany = append(tooMany, *md)
+	for i := 0; i < www.PolicyMaxMDs+www.PolicyMaxImages; i++ {
+		tooMany = append(tooMany, *createFilePNG(t, false))
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name    string
+		files   []www.File
+		wantErr error
+	}{
+		{"no files", nil,
+			www.UserError{
+				ErrorCode: www.ErrorStatusProposalMissingFiles,
+			}},
+
+		{"too many files", tooMany,
+			www.UserError{
+				ErrorCode: www.ErrorStatusMaxImagesExceededPolicy,
+			}},
+
+		{"invalid filename", []www.File{*md, badName},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidFilename,
+			}},
+
+		{"duplicate filenames", []www.File{*md, dup},
+			www.UserError{
+				ErrorCode: www.ErrorStatusProposalDuplicateFilenames,
+			}},
+
+		{"invalid base64", []www.File{*md, badPayload},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidBase64,
+			}},
+
+		{"draft too large", []www.File{*large},
+			www.UserError{
+				ErrorCode: www.ErrorStatusMaxDraftSizeExceeded,
+			}},
+
+		{"unsupported mime type", []www.File{*md, pdf},
+			www.UserError{
+				ErrorCode: www.ErrorStatusUnsupportedMIMEType,
+			}},
+
+		{"valid draft", []www.File{*md, *png}, nil},
+
+		{"digest and mime type are set", []www.File{unset}, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := validateDraftFiles(v.files)
+			got := errToStr(err)
+			want := errToStr(v.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, f := range v.files {
+				if f.Name == md.Name &&
+					(f.MIME != md.MIME || f.Digest != md.Digest) {
+					t.Errorf("got mime %v digest %v, want %v %v",
+						f.MIME, f.Digest, md.MIME, md.Digest)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessDrafts(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	author, _ := newUser(t, p, false)
+	other, _ := newUser(t, p, false)
+
+	md := createFileMD(t, 64, "Draft proposal title")
+	png := createFilePNG(t, false)
+
+	// Create a draft
+	ndr, err := p.processNewDraft(www.NewDraft{
+		Files: []www.File{*md},
+	}, author)
+	if err != nil {
+		t.Fatalf("processNewDraft: %v", err)
+	}
+	draftID := ndr.Draft.DraftID
+	if ndr.Draft.Name != "Draft proposal title" || ndr.Draft.NumFiles != 1 {
+		t.Fatalf("unexpected draft %v", ndr.Draft)
+	}
+
+	// Drafts are only visible to their author
+	_, err = p.processDraftDetails(www.DraftDetails{
+		DraftID: draftID,
+	}, other)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusDraftNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+	_, err = p.processEditDraft(www.EditDraft{
+		DraftID: draftID,
+		Files:   []www.File{*md},
+	}, other)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusDraftNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+	udr, err := p.processUserDrafts(other)
+	if err != nil {
+		t.Fatalf("processUserDrafts: %v", err)
+	}
+	if len(udr.Drafts) != 0 {
+		t.Fatalf("got %v drafts of other user, want 0", len(udr.Drafts))
+	}
+
+	// Edit the draft
+	edr, err := p.processEditDraft(www.EditDraft{
+		DraftID: draftID,
+		Files:   []www.File{*md, *png},
+	}, author)
+	if err != nil {
+		t.Fatalf("processEditDraft: %v", err)
+	}
+	if edr.Draft.NumFiles != 2 {
+		t.Fatalf("got %v files, want 2", edr.Draft.NumFiles)
+	}
+	ddr, err := p.processDraftDetails(www.DraftDetails{
+		DraftID: draftID,
+	}, author)
+	if err != nil {
+		t.Fatalf("processDraftDetails: %v", err)
+	}
+	if len(ddr.Draft.Files) != 2 ||
+		ddr.Draft.Files[1].Payload != png.Payload {
+		t.Fatalf("draft files were not updated")
+	}
+	payload, _ := base64.StdEncoding.DecodeString(md.Payload)
+	image, _ := base64.StdEncoding.DecodeString(png.Payload)
+	if edr.Draft.Size != int64(len(payload)+len(image)) {
+		t.Fatalf("got size %v, want %v", edr.Draft.Size,
+			len(payload)+len(image))
+	}
+
+	// The number of drafts is limited
+	for i := 1; i < www.PolicyMaxDrafts; i++ {
+		_, err := p.processNewDraft(www.NewDraft{
+			Files: []www.File{*md},
+		}, author)
+		if err != nil {
+			t.Fatalf("processNewDraft: %v", err)
+		}
+	}
+	_, err = p.processNewDraft(www.NewDraft{
+		Files: []www.File{*md},
+	}, author)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusMaxDraftsExceeded]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+
+	// Delete all drafts.  The drafts file is removed along with the
+	// last draft.
+	udr, err = p.processUserDrafts(author)
+	if err != nil {
+		t.Fatalf("processUserDrafts: %v", err)
+	}
+	if len(udr.Drafts) != www.PolicyMaxDrafts {
+		t.Fatalf("got %v drafts, want %v", len(udr.Drafts),
+			www.PolicyMaxDrafts)
+	}
+	for _, v := range udr.Drafts {
+		_, err := p.processDeleteDraft(www.DeleteDraft{
+			DraftID: v.DraftID,
+		}, author)
+		if err != nil {
+			t.Fatalf("processDeleteDraft: %v", err)
+		}
+	}
+	_, err = p.processDeleteDraft(www.DeleteDraft{
+		DraftID: draftID,
+	}, author)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusDraftNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+	_, err = os.Stat(p.draftsFile(author.ID))
+	if !os.IsNotExist(err) {
+		t.Fatalf("drafts file was not removed: %v", err)
+	}
+}
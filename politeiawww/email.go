@@ -50,6 +50,10 @@ func (p *politeiawww) sendEmailTo(subject, body, toAddress string) error {
 	if p.smtp.disabled {
 		return nil
 	}
+	if p.smtp.sentTo != nil {
+		p.smtp.sentTo(subject, body, toAddress)
+		return nil
+	}
 	return p.smtp.sendEmail(subject, body, func(msg *goemail.Message) error {
 		msg.AddTo(toAddress)
 		return nil
@@ -58,7 +62,7 @@ func (p *politeiawww) sendEmailTo(subject, body, toAddress string) error {
 
 // emailNewUserVerificationLink emails the link with the new user verification
 // token if the email server is set up.
-func (p *politeiawww) emailNewUserVerificationLink(email, token, username string) error {
+func (p *politeiawww) emailNewUserVerificationLink(email, token, username, locale string) error {
 	if p.smtp.disabled {
 		return nil
 	}
@@ -75,8 +79,8 @@ func (p *politeiawww) emailNewUserVerificationLink(email, token, username string
 		Link:     link,
 	}
 
-	subject := "Verify Your Email"
-	body, err := createBody(templateNewUserEmail, &tplData)
+	subject, body, err := p.createLocalizedEmail(locale,
+		templateNewUserEmail, "Verify Your Email", &tplData)
 	if err != nil {
 		return err
 	}
@@ -86,7 +90,7 @@ func (p *politeiawww) emailNewUserVerificationLink(email, token, username string
 
 // emailResetPasswordVerificationLink emails the link with the reset password
 // verification token if the email server is set up.
-func (p *politeiawww) emailResetPasswordVerificationLink(email, token string) error {
+func (p *politeiawww) emailResetPasswordVerificationLink(email, token, locale string) error {
 	if p.smtp.disabled {
 		return nil
 	}
@@ -102,8 +106,8 @@ func (p *politeiawww) emailResetPasswordVerificationLink(email, token string) er
 		Link:  link,
 	}
 
-	subject := "Reset Your Password"
-	body, err := createBody(templateResetPasswordEmail, &tplData)
+	subject, body, err := p.createLocalizedEmail(locale,
+		templateResetPasswordEmail, "Reset Your Password", &tplData)
 	if err != nil {
 		return err
 	}
@@ -474,7 +478,7 @@ func (p *politeiawww) emailAuthorForCommentOnComment(proposal *v1.ProposalRecord
 
 // emailUpdateUserKeyVerificationLink emails the link with the verification
 // token used for setting a new key pair if the email server is set up.
-func (p *politeiawww) emailUpdateUserKeyVerificationLink(email, publicKey, token string) error {
+func (p *politeiawww) emailUpdateUserKeyVerificationLink(email, publicKey, token, locale string) error {
 	if p.smtp.disabled {
 		return nil
 	}
@@ -490,8 +494,58 @@ func (p *politeiawww) emailUpdateUserKeyVerificationLink(email, publicKey, token
 		Link:      link,
 	}
 
-	subject := "Verify Your New Identity"
-	body, err := createBody(templateUpdateUserKeyEmail, &tplData)
+	subject, body, err := p.createLocalizedEmail(locale,
+		templateUpdateUserKeyEmail, "Verify Your New Identity", &tplData)
+	if err != nil {
+		return err
+	}
+
+	return p.sendEmailTo(subject, body, email)
+}
+
+// emailChangeEmailVerificationLink emails the link with the verification token
+// used for changing the email address of a user to the new email address if
+// the email server is set up.
+func (p *politeiawww) emailChangeEmailVerificationLink(email, newEmail, token, locale string) error {
+	if p.smtp.disabled {
+		return nil
+	}
+
+	link, err := p.createEmailLink(v1.RouteVerifyChangeEmail, "", token)
+	if err != nil {
+		return err
+	}
+
+	tplData := changeEmailTemplateData{
+		Email:    email,
+		NewEmail: newEmail,
+		Link:     link,
+	}
+
+	subject, body, err := p.createLocalizedEmail(locale,
+		templateChangeEmail, "Verify Your New Email Address", &tplData)
+	if err != nil {
+		return err
+	}
+
+	return p.sendEmailTo(subject, body, newEmail)
+}
+
+// emailChangeEmailNotice notifies the current email address of a user that a
+// change of the email address was requested, so that the owner of the account
+// learns about changes that they did not make.
+func (p *politeiawww) emailChangeEmailNotice(email, newEmail, locale string) error {
+	if p.smtp.disabled {
+		return nil
+	}
+
+	tplData := changeEmailTemplateData{
+		Email:    email,
+		NewEmail: newEmail,
+	}
+
+	subject, body, err := p.createLocalizedEmail(locale,
+		templateChangeEmailNotice, "Email Address Change Requested", &tplData)
 	if err != nil {
 		return err
 	}
@@ -501,7 +555,7 @@ func (p *politeiawww) emailUpdateUserKeyVerificationLink(email, publicKey, token
 
 // emailUserPasswordChanged notifies the user that his password was changed,
 // and verifies if he was the author of this action, for security purposes.
-func (p *politeiawww) emailUserPasswordChanged(email string) error {
+func (p *politeiawww) emailUserPasswordChanged(email, locale string) error {
 	if p.smtp.disabled {
 		return nil
 	}
@@ -510,8 +564,8 @@ func (p *politeiawww) emailUserPasswordChanged(email string) error {
 		Email: email,
 	}
 
-	subject := "Password Changed - Security Verification"
-	body, err := createBody(templateUserPasswordChanged, &tplData)
+	subject, body, err := p.createLocalizedEmail(locale,
+		templateUserPasswordChanged, "Password Changed - Security Verification", &tplData)
 	if err != nil {
 		return err
 	}
@@ -522,7 +576,7 @@ func (p *politeiawww) emailUserPasswordChanged(email string) error {
 // emailUserLocked notifies the user its account has been locked and emails the
 // link with the reset password verification token if the email server is set
 // up.
-func (p *politeiawww) emailUserLocked(email string) error {
+func (p *politeiawww) emailUserLocked(email, locale string) error {
 	if p.smtp.disabled {
 		return nil
 	}
@@ -538,8 +592,8 @@ func (p *politeiawww) emailUserLocked(email string) error {
 		Link:  link,
 	}
 
-	subject := "Locked Account - Reset Your Password"
-	body, err := createBody(templateUserLockedResetPassword, &tplData)
+	subject, body, err := p.createLocalizedEmail(locale,
+		templateUserLockedResetPassword, "Locked Account - Reset Your Password", &tplData)
 	if err != nil {
 		return err
 	}
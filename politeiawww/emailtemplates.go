@@ -0,0 +1,183 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+const (
+	// defaultLocale is the locale of the built in email templates.  An
+	// email is sent in the default locale when no template exists for the
+	// locale of the recipient.
+	defaultLocale = "en"
+
+	// emailTemplateExt is the file extension of the email templates that
+	// are loaded from the email templates directory.
+	emailTemplateExt = ".tmpl"
+
+	// emailSubjectTemplate is the name of the template that an email
+	// template can define to translate the subject of the email.
+	emailSubjectTemplate = "subject"
+)
+
+var (
+	// validLocale matches a language code that is optionally followed by
+	// region or script subtags, e.g. "en", "pt-BR" or "zh-Hant".
+	validLocale = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+	// localizedTemplates contains the built in templates of the emails
+	// that are sent in the locale of the recipient.  Translations can only
+	// be supplied for these templates.
+	localizedTemplates = []*template.Template{
+		templateNewUserEmail,
+		templateResetPasswordEmail,
+		templateUpdateUserKeyEmail,
+		templateUserLockedResetPassword,
+		templateUserPasswordChanged,
+		templateChangeEmail,
+		templateChangeEmailNotice,
+	}
+)
+
+// validateLocale returns an error if the passed in locale is not a well
+// formed locale.  Locales that no translation exists for are valid; emails
+// are sent in the default locale to their users.
+func validateLocale(locale string) error {
+	if !validLocale.MatchString(locale) {
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidLocale,
+			ErrorContext: []string{locale},
+		}
+	}
+	return nil
+}
+
+// initEmailTemplates registers the built in email templates under the default
+// locale and loads the translations from the email templates directory.  The
+// directory contains a directory for every locale, which contains the
+// translated templates named after the built in templates, e.g.
+// fr/new_user_email_template.tmpl.
+func (p *politeiawww) initEmailTemplates() error {
+	p.tmplMtx.Lock()
+	p.templates = make(map[string]map[string]*template.Template)
+	p.templates[defaultLocale] = make(map[string]*template.Template)
+	for _, v := range localizedTemplates {
+		p.templates[defaultLocale][v.Name()] = v
+	}
+	p.tmplMtx.Unlock()
+
+	if p.cfg.EmailTemplatesDir == "" {
+		return nil
+	}
+
+	dirs, err := ioutil.ReadDir(p.cfg.EmailTemplatesDir)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		locale := dir.Name()
+		err := validateLocale(locale)
+		if err != nil {
+			return fmt.Errorf("invalid locale directory %v", locale)
+		}
+
+		path := filepath.Join(p.cfg.EmailTemplatesDir, locale)
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if f.IsDir() || filepath.Ext(f.Name()) != emailTemplateExt {
+				continue
+			}
+			b, err := ioutil.ReadFile(filepath.Join(path, f.Name()))
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSuffix(f.Name(), emailTemplateExt)
+			err = p.addTemplate(locale, name, string(b))
+			if err != nil {
+				return fmt.Errorf("%v/%v: %v", locale, f.Name(), err)
+			}
+			log.Infof("Loaded email template %v/%v", locale, name)
+		}
+	}
+
+	return nil
+}
+
+// addTemplate registers the translation of the built in template with the
+// passed in name for the passed in locale.
+func (p *politeiawww) addTemplate(locale, name, content string) error {
+	p.tmplMtx.Lock()
+	defer p.tmplMtx.Unlock()
+
+	if _, ok := p.templates[defaultLocale][name]; !ok {
+		return fmt.Errorf("unknown email template %v", name)
+	}
+	tpl, err := template.New(name).Parse(content)
+	if err != nil {
+		return err
+	}
+	if _, ok := p.templates[locale]; !ok {
+		p.templates[locale] = make(map[string]*template.Template)
+	}
+	p.templates[locale][name] = tpl
+
+	return nil
+}
+
+// getTemplate returns the template with the passed in name for the passed in
+// locale.  When no translation exists for the locale, the translation for its
+// language is returned, e.g. "pt" for "pt-BR", and the template of the default
+// locale otherwise.
+func (p *politeiawww) getTemplate(locale, name string) *template.Template {
+	p.tmplMtx.RLock()
+	defer p.tmplMtx.RUnlock()
+
+	locales := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	for _, v := range locales {
+		if tpl, ok := p.templates[v][name]; ok {
+			return tpl
+		}
+	}
+	return p.templates[defaultLocale][name]
+}
+
+// createLocalizedEmail executes the passed in template in the passed in
+// locale and returns the subject and body of the email.  The passed in
+// subject is returned unless the translation defines a subject template.
+func (p *politeiawww) createLocalizedEmail(locale string, tpl *template.Template, subject string, tplData interface{}) (string, string, error) {
+	t := p.getTemplate(locale, tpl.Name())
+	if t == nil {
+		t = tpl
+	}
+	body, err := createBody(t, tplData)
+	if err != nil {
+		return "", "", err
+	}
+	if st := t.Lookup(emailSubjectTemplate); st != nil {
+		s, err := createBody(st, tplData)
+		if err != nil {
+			return "", "", err
+		}
+		subject = strings.TrimSpace(s)
+	}
+	return subject, body, nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitEmailTemplates(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// writeTemplate writes a translated template to the email templates
+	// directory.
+	dir := filepath.Join(p.cfg.DataDir, "emailtemplates")
+	writeTemplate := func(locale, name, content string) {
+		t.Helper()
+		err := os.MkdirAll(filepath.Join(dir, locale), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, locale,
+			name+emailTemplateExt), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTemplate("fr", templateNewUserEmail.Name(),
+		`{{define "subject"}}Vérifiez votre email{{end}}`+
+			`Bonjour {{.Username}}`)
+
+	p.cfg.EmailTemplatesDir = dir
+	err := p.initEmailTemplates()
+	if err != nil {
+		t.Fatalf("initEmailTemplates: %v", err)
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name        string
+		locale      string
+		wantSubject string
+		wantBody    string
+	}{
+		{"translation", "fr", "Vérifiez votre email", "Bonjour user"},
+		{"language of region", "fr-CA", "Vérifiez votre email",
+			"Bonjour user"},
+		{"missing translation", "de", "Verify Your Email",
+			"Thanks for joining Politeia, user!"},
+		{"no locale", "", "Verify Your Email",
+			"Thanks for joining Politeia, user!"},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			subject, body, err := p.createLocalizedEmail(v.locale,
+				templateNewUserEmail, "Verify Your Email",
+				&newUserEmailTemplateData{Username: "user"})
+			if err != nil {
+				t.Fatalf("createLocalizedEmail: %v", err)
+			}
+			if subject != v.wantSubject {
+				t.Errorf("got subject %q, want %q", subject,
+					v.wantSubject)
+			}
+			if !strings.Contains(body, v.wantBody) {
+				t.Errorf("got body %q, want %q", body, v.wantBody)
+			}
+		})
+	}
+
+	// Translations can only be supplied for the built in templates
+	writeTemplate("es", "unknown_template", "Hola")
+	err = p.initEmailTemplates()
+	if err == nil {
+		t.Fatalf("got nil error, want unknown template error")
+	}
+}
@@ -31,6 +31,7 @@ const (
 	EventTypeProposalVoteFinished
 	EventTypeComment
 	EventTypeUserManage
+	EventTypeProposalWithdrawn
 )
 
 type EventDataProposalSubmitted struct {
@@ -45,6 +46,12 @@ type EventDataProposalStatusChange struct {
 	AdminUser         *user.User
 }
 
+type EventDataProposalWithdrawn struct {
+	Proposal        *v1.ProposalRecord
+	AbandonProposal *v1.AbandonProposal
+	User            *user.User
+}
+
 type EventDataProposalEdited struct {
 	Proposal *v1.ProposalRecord
 }
@@ -59,6 +66,11 @@ type EventDataProposalVoteAuthorized struct {
 	User          *user.User
 }
 
+type EventDataProposalVoteFinished struct {
+	Proposal   *v1.ProposalRecord
+	VoteStatus *v1.VoteStatusReply
+}
+
 type EventDataComment struct {
 	Comment *v1.Comment
 }
@@ -135,6 +147,8 @@ func (p *politeiawww) initEventManager() {
 	p._setupProposalStatusChangeLogging()
 	p._setupProposalVoteStartedLogging()
 	p._setupUserManageLogging()
+	p._setupWebhookNotifications()
+	p._setupInventoryStream()
 
 	if p.smtp.disabled {
 		return
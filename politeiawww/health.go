@@ -0,0 +1,62 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/decred/politeia/politeiad/cache"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+// checkUserDB verifies that the user database can be queried.  Looking up a
+// user that can not exist is expected to fail with ErrUserNotFound; any other
+// error means that the database is not usable.
+func checkUserDB(db user.Database) error {
+	_, err := db.UserGetById(uuid.Nil)
+	if err == user.ErrUserNotFound {
+		return nil
+	}
+	return err
+}
+
+// checkCache verifies that the records cache can be queried.
+func checkCache(c cache.Cache) error {
+	_, err := c.InventoryStats()
+	return err
+}
+
+// processHealth checks whether the services that politeiawww depends on are
+// reachable.  The reason a component is unhealthy is logged but not returned
+// so that internal details are not leaked to unauthenticated callers.
+func (p *politeiawww) processHealth() *www.HealthReply {
+	log.Tracef("processHealth")
+
+	checks := []struct {
+		name  string
+		check func() error
+	}{
+		{www.HealthComponentUserDB, func() error { return checkUserDB(p.db) }},
+		{www.HealthComponentCache, func() error { return checkCache(p.cache) }},
+	}
+
+	hr := www.HealthReply{
+		Healthy:    true,
+		Components: make([]www.ComponentHealth, 0, len(checks)),
+	}
+	for _, v := range checks {
+		err := v.check()
+		if err != nil {
+			log.Errorf("processHealth: %v: %v", v.name, err)
+			hr.Healthy = false
+		}
+		hr.Components = append(hr.Components, www.ComponentHealth{
+			Name:    v.name,
+			Healthy: err == nil,
+		})
+	}
+
+	return &hr
+}
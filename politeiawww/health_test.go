@@ -0,0 +1,95 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/cache"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+// testHealthCache is a records cache that only implements InventoryStats,
+// which fails with err when it is set.
+type testHealthCache struct {
+	cache.Cache
+	err error
+}
+
+func (c *testHealthCache) InventoryStats() (*cache.InventoryStats, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &cache.InventoryStats{}, nil
+}
+
+// testHealthDB wraps a user database and fails user lookups with err when it
+// is set.
+type testHealthDB struct {
+	user.Database
+	err error
+}
+
+func (db *testHealthDB) UserGetById(id uuid.UUID) (*user.User, error) {
+	if db.err != nil {
+		return nil, db.err
+	}
+	return db.Database.UserGetById(id)
+}
+
+func TestProcessHealth(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+	db := p.db
+	defer func() {
+		p.db = db
+	}()
+
+	// Setup tests
+	var tests = []struct {
+		name      string
+		cacheErr  error
+		dbErr     error
+		want      bool
+		wantCache bool
+		wantDB    bool
+	}{
+		{"healthy", nil, nil, true, true, true},
+		{"cache unreachable", errors.New("connection refused"), nil,
+			false, false, true},
+		{"userdb shutdown", nil, user.ErrShutdown, false, true, false},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			p.cache = &testHealthCache{err: v.cacheErr}
+			p.db = &testHealthDB{Database: db, err: v.dbErr}
+
+			hr := p.processHealth()
+			if hr.Healthy != v.want {
+				t.Errorf("got healthy %v, want %v", hr.Healthy, v.want)
+			}
+			for _, c := range hr.Components {
+				var want bool
+				switch c.Name {
+				case www.HealthComponentUserDB:
+					want = v.wantDB
+				case www.HealthComponentCache:
+					want = v.wantCache
+				default:
+					t.Fatalf("unexpected component %v", c.Name)
+				}
+				if c.Healthy != want {
+					t.Errorf("got %v healthy %v, want %v",
+						c.Name, c.Healthy, want)
+				}
+			}
+		})
+	}
+}
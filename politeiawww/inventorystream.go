@@ -0,0 +1,221 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+const (
+	// inventoryStreamBuffer is the number of events that are buffered for
+	// a subscriber.  Events are dropped for subscribers that fall further
+	// behind so that a slow client can not block the others.
+	inventoryStreamBuffer = 64
+
+	// inventoryStreamKeepAlive is the interval at which a comment is sent
+	// on idle streams so that proxies do not close the connection.
+	inventoryStreamKeepAlive = 30 * time.Second
+)
+
+// inventoryStream fans out inventory events to the clients that are streaming
+// the proposal inventory.
+type inventoryStream struct {
+	sync.Mutex
+	subscribers map[chan www.InventoryEvent]bool // [subscriber]isAdmin
+	done        chan struct{}                    // Closed on shutdown
+	closed      bool
+}
+
+// newInventoryStream returns an inventory stream without subscribers.
+func newInventoryStream() *inventoryStream {
+	return &inventoryStream{
+		subscribers: make(map[chan www.InventoryEvent]bool),
+		done:        make(chan struct{}),
+	}
+}
+
+// close ends all streams so that they do not hold up the server shutdown.
+func (s *inventoryStream) close() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return
+	}
+	close(s.done)
+	s.closed = true
+}
+
+// subscribe returns a channel that receives the inventory events that the
+// subscriber is allowed to see.
+func (s *inventoryStream) subscribe(isAdmin bool) chan www.InventoryEvent {
+	s.Lock()
+	defer s.Unlock()
+
+	ch := make(chan www.InventoryEvent, inventoryStreamBuffer)
+	s.subscribers[ch] = isAdmin
+	return ch
+}
+
+// unsubscribe stops sending inventory events to the passed in channel.
+func (s *inventoryStream) unsubscribe(ch chan www.InventoryEvent) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.subscribers, ch)
+}
+
+// inventoryEventIsPublic returns whether the passed in inventory event can be
+// sent to users that are not admins.  Only status changes that leave the
+// proposal vetted are public; unvetted proposals are only visible to admins.
+func inventoryEventIsPublic(e www.InventoryEvent) bool {
+	if e.Event != www.InventoryEventProposalStatusChange {
+		return false
+	}
+	switch e.Status {
+	case www.PropStatusPublic, www.PropStatusAbandoned:
+		return true
+	}
+	return false
+}
+
+// publish sends the passed in inventory event to all subscribers that are
+// allowed to see it.
+func (s *inventoryStream) publish(e www.InventoryEvent) {
+	public := inventoryEventIsPublic(e)
+
+	s.Lock()
+	defer s.Unlock()
+
+	for ch, isAdmin := range s.subscribers {
+		if !public && !isAdmin {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+			log.Debugf("inventoryStream: dropping %v %v for slow "+
+				"subscriber", e.Event, e.Token)
+		}
+	}
+}
+
+// _setupInventoryStream registers the event listeners that publish proposal
+// submissions and status changes, including withdrawals by the author, on the
+// inventory stream.
+//
+// This function must be called WITH the mutex held.
+func (p *politeiawww) _setupInventoryStream() {
+	ch := make(chan interface{})
+	go func() {
+		for data := range ch {
+			switch e := data.(type) {
+			case EventDataProposalSubmitted:
+				p.inventory.publish(www.InventoryEvent{
+					Event:     www.InventoryEventProposalSubmitted,
+					Timestamp: time.Now().Unix(),
+					Token:     e.CensorshipRecord.Token,
+					Name:      e.ProposalName,
+					Status:    www.PropStatusNotReviewed,
+				})
+
+			case EventDataProposalStatusChange:
+				p.inventory.publish(www.InventoryEvent{
+					Event:     www.InventoryEventProposalStatusChange,
+					Timestamp: time.Now().Unix(),
+					Token:     e.Proposal.CensorshipRecord.Token,
+					Name:      e.Proposal.Name,
+					Status:    e.SetProposalStatus.ProposalStatus,
+				})
+
+			case EventDataProposalWithdrawn:
+				p.inventory.publish(www.InventoryEvent{
+					Event:     www.InventoryEventProposalStatusChange,
+					Timestamp: time.Now().Unix(),
+					Token:     e.Proposal.CensorshipRecord.Token,
+					Name:      e.Proposal.Name,
+					Status:    e.Proposal.Status,
+				})
+
+			default:
+				log.Errorf("invalid event data")
+			}
+		}
+	}()
+	p.eventManager._register(EventTypeProposalSubmitted, ch)
+	p.eventManager._register(EventTypeProposalStatusChange, ch)
+	p.eventManager._register(EventTypeProposalWithdrawn, ch)
+}
+
+// writeInventoryEvent writes the passed in inventory event to w as a
+// server-sent event.
+func writeInventoryEvent(w http.ResponseWriter, e www.InventoryEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %v\ndata: %s\n\n", e.Event, b)
+	return err
+}
+
+// handleInventoryStream streams proposal submissions and status changes to
+// the client as server-sent events until the client disconnects.
+func (p *politeiawww) handleInventoryStream(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleInventoryStream")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		RespondWithError(w, r, 0,
+			"handleInventoryStream: streaming not supported")
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		if err != ErrSessionUUIDNotFound && err != ErrSessionExpired {
+			RespondWithError(w, r, 0,
+				"handleInventoryStream: getSessionUser %v", err)
+			return
+		}
+	}
+	isAdmin := user != nil && user.Admin
+
+	ch := p.inventory.subscribe(isAdmin)
+	defer p.inventory.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(inventoryStreamKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-p.inventory.done:
+			return
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		case e := <-ch:
+			err = writeInventoryEvent(w, e)
+		}
+		if err != nil {
+			requestLog(r).Debugf("handleInventoryStream: write: %v",
+				err)
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestInventoryStreamPublish(t *testing.T) {
+	s := newInventoryStream()
+	user := s.subscribe(false)
+	admin := s.subscribe(true)
+
+	submitted := www.InventoryEvent{
+		Event:  www.InventoryEventProposalSubmitted,
+		Token:  "a",
+		Status: www.PropStatusNotReviewed,
+	}
+	censored := www.InventoryEvent{
+		Event:  www.InventoryEventProposalStatusChange,
+		Token:  "b",
+		Status: www.PropStatusCensored,
+	}
+	public := www.InventoryEvent{
+		Event:  www.InventoryEventProposalStatusChange,
+		Token:  "c",
+		Status: www.PropStatusPublic,
+	}
+	s.publish(submitted)
+	s.publish(censored)
+	s.publish(public)
+
+	// Admins receive all events while users only receive the events
+	// of vetted proposals.
+	for _, want := range []string{"a", "b", "c"} {
+		if e := <-admin; e.Token != want {
+			t.Errorf("admin: got %v, want %v", e.Token, want)
+		}
+	}
+	if e := <-user; e.Token != "c" {
+		t.Errorf("user: got %v, want c", e.Token)
+	}
+	if len(user) != 0 {
+		t.Errorf("user: got %v queued events, want 0", len(user))
+	}
+
+	// Unsubscribed channels no longer receive events
+	s.unsubscribe(user)
+	s.publish(public)
+	if len(user) != 0 {
+		t.Errorf("unsubscribed: got %v queued events, want 0", len(user))
+	}
+}
+
+func TestHandleInventoryStream(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	s := httptest.NewServer(http.HandlerFunc(p.handleInventoryStream))
+	defer s.Close()
+
+	r, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if ct := r.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("got content type %v, want text/event-stream", ct)
+	}
+
+	// The stream has subscribed once the headers have been received.
+	// Submissions are not sent to users that are not logged in.
+	p.inventory.publish(www.InventoryEvent{
+		Event: www.InventoryEventProposalSubmitted,
+		Token: "unvetted",
+	})
+	want := www.InventoryEvent{
+		Event:  www.InventoryEventProposalStatusChange,
+		Token:  "vetted",
+		Status: www.PropStatusPublic,
+	}
+	p.inventory.publish(want)
+
+	scanner := bufio.NewScanner(r.Body)
+	if !scanner.Scan() {
+		t.Fatalf("read event: %v", scanner.Err())
+	}
+	if got := scanner.Text(); got != "event: "+want.Event {
+		t.Fatalf("got %q, want event: %v", got, want.Event)
+	}
+	if !scanner.Scan() {
+		t.Fatalf("read data: %v", scanner.Err())
+	}
+	var got www.InventoryEvent
+	err = json.Unmarshal([]byte(strings.TrimPrefix(scanner.Text(),
+		"data: ")), &got)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -5,63 +5,244 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/util"
+	"github.com/decred/slog"
+	"github.com/google/uuid"
 )
 
+// contextKey is the type of the keys of the values that the middleware
+// attaches to the request context.
+type contextKey int
+
+const (
+	// contextKeySessionUser is the key of the session user.  See
+	// getContextUser.
+	contextKeySessionUser contextKey = iota
+
+	// contextKeyRequestID is the key of the request correlation ID.  See
+	// requestID.
+	contextKeyRequestID
+)
+
+// maxRequestIDLength is the maximum length of a request ID that is provided by
+// the client.  Longer IDs are replaced by a generated ID.
+const maxRequestIDLength = 64
+
+// withRequestID attaches a correlation ID to the request context before
+// calling the next function.  The ID that the client sent in the request ID
+// header is used when it is valid; otherwise a new ID is generated.  The ID
+// is echoed back in the response header so that it can be quoted when
+// reporting a problem.
+func withRequestID(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(v1.RequestID)
+		if !isValidRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(v1.RequestID, id)
+
+		ctx := context.WithValue(r.Context(), contextKeyRequestID, id)
+		f(w, r.WithContext(ctx))
+	}
+}
+
+// isValidRequestID returns whether the passed in client provided request ID
+// is safe to log.  Only IDs made of letters, digits, dashes and underscores
+// are accepted.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z',
+			c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestID returns the correlation ID of the passed in request.  A dash is
+// returned for requests that were not routed through withRequestID.
+func requestID(r *http.Request) string {
+	id, ok := r.Context().Value(contextKeyRequestID).(string)
+	if !ok {
+		return "-"
+	}
+	return id
+}
+
+// requestLogger is a logger that prefixes the lines that it logs with the
+// correlation ID of a request.
+type requestLogger struct {
+	slog.Logger
+	id string
+}
+
+// prefix returns the passed in log parameters prefixed with the request ID.
+func (l requestLogger) prefix(params []interface{}) []interface{} {
+	return append([]interface{}{l.id}, params...)
+}
+
+// Tracef formats a message prefixed with the request ID using the default
+// formats for its operands and writes to log with LevelTrace.
+func (l requestLogger) Tracef(format string, params ...interface{}) {
+	l.Logger.Tracef("%v "+format, l.prefix(params)...)
+}
+
+// Debugf formats a message prefixed with the request ID using the default
+// formats for its operands and writes to log with LevelDebug.
+func (l requestLogger) Debugf(format string, params ...interface{}) {
+	l.Logger.Debugf("%v "+format, l.prefix(params)...)
+}
+
+// Infof formats a message prefixed with the request ID using the default
+// formats for its operands and writes to log with LevelInfo.
+func (l requestLogger) Infof(format string, params ...interface{}) {
+	l.Logger.Infof("%v "+format, l.prefix(params)...)
+}
+
+// Warnf formats a message prefixed with the request ID using the default
+// formats for its operands and writes to log with LevelWarn.
+func (l requestLogger) Warnf(format string, params ...interface{}) {
+	l.Logger.Warnf("%v "+format, l.prefix(params)...)
+}
+
+// Errorf formats a message prefixed with the request ID using the default
+// formats for its operands and writes to log with LevelError.
+func (l requestLogger) Errorf(format string, params ...interface{}) {
+	l.Logger.Errorf("%v "+format, l.prefix(params)...)
+}
+
+// Criticalf formats a message prefixed with the request ID using the default
+// formats for its operands and writes to log with LevelCritical.
+func (l requestLogger) Criticalf(format string, params ...interface{}) {
+	l.Logger.Criticalf("%v "+format, l.prefix(params)...)
+}
+
+// requestLog returns the logger of the passed in request.  The lines that it
+// logs are prefixed with the correlation ID of the request so that all log
+// lines of a request can be found by its ID.
+func requestLog(r *http.Request) slog.Logger {
+	return requestLogger{
+		Logger: log,
+		id:     requestID(r),
+	}
+}
+
 // isLoggedIn ensures that a user is logged in before calling the next
-// function.
+// function.  The session user is attached to the request context.
 func (p *politeiawww) isLoggedIn(f http.HandlerFunc) http.HandlerFunc {
+	return p.withSessionUser(f, false)
+}
+
+// isLoggedInAsAdmin ensures that a user is logged in as an admin user
+// before calling the next function.  The session user is attached to the
+// request context.
+func (p *politeiawww) isLoggedInAsAdmin(f http.HandlerFunc) http.HandlerFunc {
+	return p.withSessionUser(f, true)
+}
+
+// withSessionUser looks up the session user and attaches it to the request
+// context before calling the next function.  Requests without a valid session
+// are rejected with 401 Unauthorized.  If admin is set, requests from users
+// that are not admins are rejected with 403 Forbidden.  Impersonation sessions
+// are read-only; requests that are not GET requests are rejected by
+// getSessionUser and answered with 403 Forbidden.  Requests that carry an API
+// token are handled by withAPITokenUser instead.
+func (p *politeiawww) withSessionUser(f http.HandlerFunc, admin bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Debugf("isLoggedIn: %v %v %v %v", remoteAddr(r), r.Method,
-			r.URL, r.Proto)
+		requestLog(r).Debugf("withSessionUser: %v %v %v %v",
+			remoteAddr(r), r.Method, r.URL, r.Proto)
+
+		if hasAPIToken(r) {
+			p.withAPITokenUser(f, admin)(w, r)
+			return
+		}
 
-		id, err := p.getSessionUUID(r)
+		u, err := p.getSessionUser(w, r)
 		if err != nil {
-			util.RespondWithJSON(w, http.StatusUnauthorized, v1.ErrorReply{
-				ErrorCode: int64(v1.ErrorStatusNotLoggedIn),
+			ue, ok := convertSessionError(err).(v1.UserError)
+			if !ok {
+				RespondWithError(w, r, 0,
+					"withSessionUser: getSessionUser %v", err)
+				return
+			}
+			status := http.StatusUnauthorized
+			if ue.ErrorCode == v1.ErrorStatusImpersonatedSession {
+				status = http.StatusForbidden
+			}
+			util.RespondWithJSON(w, status, v1.ErrorReply{
+				ErrorCode: int64(ue.ErrorCode),
 			})
 			return
 		}
 
-		// Check if user is authenticated
-		if id == "" {
-			util.RespondWithJSON(w, http.StatusUnauthorized, v1.ErrorReply{
-				ErrorCode: int64(v1.ErrorStatusNotLoggedIn),
-			})
+		if admin && !u.Admin {
+			requestLog(r).Debugf("withSessionUser: user is not an "+
+				"admin: %v", u.ID)
+			util.RespondWithJSON(w, http.StatusForbidden, v1.ErrorReply{})
 			return
 		}
 
-		f(w, r)
+		ctx := context.WithValue(r.Context(), contextKeySessionUser, u)
+		f(w, r.WithContext(ctx))
 	}
 }
 
-// isLoggedInAsAdmin ensures that a user is logged in as an admin user
-// before calling the next function.
-func (p *politeiawww) isLoggedInAsAdmin(f http.HandlerFunc) http.HandlerFunc {
+// withAPITokenUser looks up the user of the API token that the request
+// carries and attaches it to the request context before calling the next
+// function.  Requests with an invalid API token are rejected with 401
+// Unauthorized.  Requests that the scope of the token does not allow and, if
+// admin is set, requests from users that are not admins are rejected with 403
+// Forbidden.
+func (p *politeiawww) withAPITokenUser(f http.HandlerFunc, admin bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Debugf("isLoggedInAsAdmin: %v %v %v %v", remoteAddr(r),
-			r.Method, r.URL, r.Proto)
-
-		// Check if user is admin
-		isAdmin, err := p.isAdmin(w, r)
+		t, u, err := p.getAPITokenUser(r)
 		if err != nil {
-			log.Errorf("isLoggedInAsAdmin: isAdmin %v", err)
+			ue, ok := err.(v1.UserError)
+			if !ok {
+				RespondWithError(w, r, 0,
+					"withAPITokenUser: getAPITokenUser %v", err)
+				return
+			}
 			util.RespondWithJSON(w, http.StatusUnauthorized, v1.ErrorReply{
-				ErrorCode: int64(v1.ErrorStatusNotLoggedIn),
+				ErrorCode: int64(ue.ErrorCode),
 			})
 			return
 		}
-		if !isAdmin {
+
+		if admin && !u.Admin {
+			requestLog(r).Debugf("withAPITokenUser: user is not an "+
+				"admin: %v", u.ID)
 			util.RespondWithJSON(w, http.StatusForbidden, v1.ErrorReply{})
 			return
 		}
 
-		f(w, r)
+		if !apiTokenAllows(t.Scope, r.Method, admin) {
+			requestLog(r).Debugf("withAPITokenUser: API token %v with "+
+				"scope %v blocked %v %v", t.ID,
+				v1.APITokenScope[t.Scope], r.Method, r.URL)
+			util.RespondWithJSON(w, http.StatusForbidden, v1.ErrorReply{
+				ErrorCode: int64(v1.ErrorStatusAPITokenScopeNotAllowed),
+			})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeySessionUser, u)
+		f(w, r.WithContext(ctx))
 	}
 }
 
@@ -71,7 +252,7 @@ func (p *politeiawww) isLoggedInAsAdmin(f http.HandlerFunc) http.HandlerFunc {
 func logging(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Trace incoming request
-		log.Tracef("%v", newLogClosure(func() string {
+		requestLog(r).Tracef("%v", newLogClosure(func() string {
 			trace, err := httputil.DumpRequest(r, true)
 			if err != nil {
 				trace = []byte(fmt.Sprintf("logging: "+
@@ -81,7 +262,8 @@ func logging(f http.HandlerFunc) http.HandlerFunc {
 		}))
 
 		// Log incoming connection
-		log.Infof("%v %v %v %v", remoteAddr(r), r.Method, r.URL, r.Proto)
+		requestLog(r).Infof("%v %v %v %v", remoteAddr(r), r.Method, r.URL,
+			r.Proto)
 		f(w, r)
 	}
 }
@@ -94,6 +276,94 @@ func closeBody(f http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// limitBody reads the request body up front and rejects requests whose body
+// is larger than maxBytes before the next function is called.
+func limitBody(f http.HandlerFunc, maxBytes int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+		r.Body.Close()
+		if err != nil {
+			RespondWithError(w, r, 0, "limitBody: read body %v", err,
+				v1.UserError{
+					ErrorCode: v1.ErrorStatusInvalidInput,
+				})
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			RespondWithError(w, r, 0, "limitBody: %v %v: request too large",
+				r.Method, r.URL,
+				v1.UserError{
+					ErrorCode: v1.ErrorStatusInvalidInput,
+					ErrorContext: []string{fmt.Sprintf("request too "+
+						"large; maximum size is %v bytes", maxBytes)},
+				})
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		f(w, r)
+	}
+}
+
+// validateBody decodes the request body into the passed in request type and
+// validates it against the struct tags of the request before the next function
+// is called.  Bodies with unknown fields, which are usually typos in API
+// clients, or with fields that fail validation are rejected; the field errors
+// are returned in the error context.
+func validateBody(f http.HandlerFunc, rt requestType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			RespondWithError(w, r, 0, "validateBody: read body %v", err,
+				v1.UserError{
+					ErrorCode: v1.ErrorStatusInvalidInput,
+				})
+			return
+		}
+
+		req := rt.newRequest()
+		d := json.NewDecoder(bytes.NewReader(body))
+		d.DisallowUnknownFields()
+		err = d.Decode(req)
+		if fe, ok := unknownFieldError(err); ok {
+			RespondWithError(w, r, 0, "validateBody: %v %v: %v",
+				r.Method, r.URL, err,
+				v1.UserError{
+					ErrorCode:    rt.errorCode([]fieldError{fe}),
+					ErrorContext: []string{fe.String()},
+				})
+			return
+		}
+		if err != nil {
+			RespondWithError(w, r, 0, "validateBody: decode %v", err,
+				v1.UserError{
+					ErrorCode:    v1.ErrorStatusInvalidInput,
+					ErrorContext: []string{err.Error()},
+				})
+			return
+		}
+		errs, err := validateStruct(req)
+		if err != nil {
+			RespondWithError(w, r, 0, "validateBody: validateStruct "+
+				"%v %v: %v", r.Method, r.URL, err)
+			return
+		}
+		if len(errs) > 0 {
+			RespondWithError(w, r, 0, "validateBody: %v %v: invalid "+
+				"request", r.Method, r.URL,
+				v1.UserError{
+					ErrorCode:    rt.errorCode(errs),
+					ErrorContext: fieldErrorStrings(errs),
+				})
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		f(w, r)
+	}
+}
+
 func remoteAddr(r *http.Request) string {
 	via := r.RemoteAddr
 	xff := r.Header.Get(v1.Forward)
@@ -0,0 +1,136 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"unicode"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+	"golang.org/x/crypto/sha3"
+)
+
+// passwordDigestLength is the length of the hex encoded SHA3-256 digest that
+// clients send as the password when they log in.
+const passwordDigestLength = 64
+
+// passwordDigest matches passwords that look like a client side digest.  New
+// passwords must be sent in plain text so that the password policy can be
+// enforced; a digest is rejected instead of being accepted without checks.
+var passwordDigest = regexp.MustCompile(
+	fmt.Sprintf(`^[0-9a-f]{%v}$`, passwordDigestLength))
+
+// digestPassword returns the hex encoded SHA3-256 digest of a password, which
+// is what clients send as the password when they log in.
+func digestPassword(password string) string {
+	h := sha3.Sum256([]byte(password))
+	return hex.EncodeToString(h[:])
+}
+
+// hashNewPassword returns the hash that is stored for the passed in plain text
+// password.  The digest of the password is hashed, so that the password can
+// be verified against the digest that clients send when they log in.
+func (p *politeiawww) hashNewPassword(password string) ([]byte, error) {
+	return p.hashPassword(digestPassword(password))
+}
+
+// initPasswordPolicy loads the banned passwords file.  The file contains a
+// password per line; empty lines and lines starting with # are ignored.
+// Banned passwords are indexed in lower case.
+func (p *politeiawww) initPasswordPolicy() error {
+	p.bannedPasswords = make(map[string]struct{})
+	if p.cfg.PasswordBannedFile == "" {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(p.cfg.PasswordBannedFile)
+	if err != nil {
+		return err
+	}
+	var count int
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.bannedPasswords[strings.ToLower(line)] = struct{}{}
+		count++
+	}
+
+	log.Infof("Loaded %v banned passwords", count)
+
+	return nil
+}
+
+// validatePassword verifies that a new plain text password adheres to the
+// password policy.  The errors of the character class and strength
+// requirements include the estimated strength of the password in their
+// context.  Passwords that look like a client side digest are rejected since
+// the policy can not be verified for them.
+func (p *politeiawww) validatePassword(password string) error {
+	if len(password) < int(p.cfg.PasswordMinLength) {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusMalformedPassword,
+		}
+	}
+
+	if passwordDigest.MatchString(password) {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusMalformedPassword,
+			ErrorContext: []string{"new passwords must be sent in " +
+				"plain text"},
+		}
+	}
+
+	_, banned := p.bannedPasswords[strings.ToLower(password)]
+	if banned {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusPasswordBanned,
+		}
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || r == ' ':
+			symbol = true
+		}
+	}
+
+	strength := util.PasswordStrength(password)
+	context := []string{fmt.Sprintf("strength %v/%v", strength,
+		util.PasswordStrengthMax)}
+	var errorCode www.ErrorStatusT
+	switch {
+	case p.cfg.PasswordRequireUpper && !upper:
+		errorCode = www.ErrorStatusPasswordMissingUpper
+	case p.cfg.PasswordRequireLower && !lower:
+		errorCode = www.ErrorStatusPasswordMissingLower
+	case p.cfg.PasswordRequireDigit && !digit:
+		errorCode = www.ErrorStatusPasswordMissingDigit
+	case p.cfg.PasswordRequireSymbol && !symbol:
+		errorCode = www.ErrorStatusPasswordMissingSymbol
+	case strength < int(p.cfg.PasswordMinStrength):
+		errorCode = www.ErrorStatusPasswordTooWeak
+	default:
+		return nil
+	}
+
+	return www.UserError{
+		ErrorCode:    errorCode,
+		ErrorContext: context,
+	}
+}
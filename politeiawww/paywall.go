@@ -23,6 +23,12 @@ type paywallPoolMember struct {
 	txID            string // ID of the pending payment tx
 	txAmount        uint64 // Amount of the pending payment tx
 	txConfirmations uint64 // Number of confirmations of the pending payment tx
+
+	// The paywall address is polled with an exponential backoff.  A zero
+	// nextPoll means that the address is polled on the next pass of the
+	// paywall checker.
+	pollInterval time.Duration // Interval that was used to schedule nextPoll
+	nextPoll     time.Time     // Earliest time of the next poll
 }
 
 const (
@@ -32,7 +38,8 @@ const (
 	paywallExpiryDuration = time.Hour * 24
 
 	// paywallCheckGap is the amount of time the server sleeps after polling for
-	// a paywall address.
+	// a paywall address.  It is also the amount of time between passes over
+	// the paywall pool; only the addresses that are due are polled on a pass.
 	paywallCheckGap = time.Second * 1
 
 	// paywallTypeUser and paywallTypeProposal are used to signify whether a
@@ -47,6 +54,63 @@ func paywallHasExpired(pollExpiry int64) bool {
 	return time.Now().After(time.Unix(pollExpiry, 0))
 }
 
+// paywallPollDue returns whether the pool member's paywall address is due to
+// be polled.
+func paywallPollDue(poolMember paywallPoolMember, now time.Time) bool {
+	return !now.Before(poolMember.nextPoll)
+}
+
+// backoffPaywallPoll schedules the next poll of the pool member's paywall
+// address after a poll that did not find a payment.  The first interval is
+// the configured poll interval and every following interval is multiplied by
+// the backoff, up to the max poll interval.
+func (p *politeiawww) backoffPaywallPoll(poolMember paywallPoolMember, now time.Time) paywallPoolMember {
+	interval := p.cfg.PaywallPollInterval
+	if poolMember.pollInterval != 0 {
+		interval = time.Duration(float64(poolMember.pollInterval) *
+			p.cfg.PaywallPollBackoff)
+	}
+	if interval > p.cfg.PaywallPollMaxInterval || interval <= 0 {
+		interval = p.cfg.PaywallPollMaxInterval
+	}
+
+	poolMember.pollInterval = interval
+	poolMember.nextPoll = now.Add(interval)
+	return poolMember
+}
+
+// setPaywallPoolMember updates the pool member of the passed in user.  The
+// update is dropped if the user has been removed from the pool while its
+// paywall address was being polled.
+//
+// This function must be called WITHOUT the mutex held.
+func (p *politeiawww) setPaywallPoolMember(userID uuid.UUID, poolMember paywallPoolMember) {
+	p.Lock()
+	defer p.Unlock()
+
+	if _, ok := p.userPaywallPool[userID]; !ok {
+		return
+	}
+	p.userPaywallPool[userID] = poolMember
+}
+
+// resetPaywallPoll resets the poll backoff of the user's paywall address so
+// that the address is polled on the next pass of the paywall checker.
+//
+// This function must be called WITHOUT the mutex held.
+func (p *politeiawww) resetPaywallPoll(userID uuid.UUID) {
+	p.Lock()
+	defer p.Unlock()
+
+	poolMember, ok := p.userPaywallPool[userID]
+	if !ok {
+		return
+	}
+	poolMember.pollInterval = 0
+	poolMember.nextPoll = time.Time{}
+	p.userPaywallPool[userID] = poolMember
+}
+
 // paywallIsEnabled returns true if paywall is enabled for the server, false
 // otherwise.
 func (p *politeiawww) paywallIsEnabled() bool {
@@ -65,6 +129,11 @@ func (p *politeiawww) checkForProposalPayments(pool map[uuid.UUID]paywallPoolMem
 	// poolMembers from the pool while in the middle of polling poolMember
 	// addresses.
 	for userID, poolMember := range pool {
+		if poolMember.paywallType != paywallTypeProposal ||
+			!paywallPollDue(poolMember, time.Now()) {
+			continue
+		}
+
 		u, err := p.db.UserGetById(userID)
 		if err != nil {
 			if err == user.ErrShutdown {
@@ -76,10 +145,6 @@ func (p *politeiawww) checkForProposalPayments(pool map[uuid.UUID]paywallPoolMem
 			continue
 		}
 
-		if poolMember.paywallType != paywallTypeProposal {
-			continue
-		}
-
 		log.Tracef("Checking proposal paywall address for user %v...", u.Email)
 
 		paywall := p.mostRecentProposalPaywall(u)
@@ -97,6 +162,8 @@ func (p *politeiawww) checkForProposalPayments(pool map[uuid.UUID]paywallPoolMem
 			}
 
 			log.Errorf("cannot update user with id %v: %v", u.ID, err)
+			p.setPaywallPoolMember(userID,
+				p.backoffPaywallPoll(poolMember, time.Now()))
 			continue
 		}
 
@@ -107,14 +174,18 @@ func (p *politeiawww) checkForProposalPayments(pool map[uuid.UUID]paywallPoolMem
 			log.Tracef("  removing from polling, user just paid")
 		} else if tx != nil {
 			// Update pool member if payment tx was found but
-			// does not have enough confimrations.
+			// does not have enough confimrations.  The backoff
+			// is reset so that the confirmations are picked up
+			// quickly.
 			poolMember.txID = tx.TxID
 			poolMember.txAmount = tx.Amount
 			poolMember.txConfirmations = tx.Confirmations
-
-			p.Lock()
-			p.userPaywallPool[userID] = poolMember
-			p.Unlock()
+			poolMember.pollInterval = 0
+			p.setPaywallPoolMember(userID,
+				p.backoffPaywallPoll(poolMember, time.Now()))
+		} else {
+			p.setPaywallPoolMember(userID,
+				p.backoffPaywallPoll(poolMember, time.Now()))
 		}
 
 		time.Sleep(paywallCheckGap)
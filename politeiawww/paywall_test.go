@@ -0,0 +1,84 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffPaywallPoll(t *testing.T) {
+	p := &politeiawww{
+		cfg: &config{
+			PaywallPollInterval:    10 * time.Second,
+			PaywallPollMaxInterval: time.Minute,
+			PaywallPollBackoff:     2,
+		},
+	}
+
+	// A new pool member is polled right away
+	now := time.Now()
+	var pm paywallPoolMember
+	if !paywallPollDue(pm, now) {
+		t.Fatalf("new pool member is not due")
+	}
+
+	// The interval doubles after every poll up to the max interval
+	want := []time.Duration{
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		time.Minute,
+		time.Minute,
+	}
+	for i, w := range want {
+		pm = p.backoffPaywallPoll(pm, now)
+		if pm.pollInterval != w {
+			t.Fatalf("poll %v: got interval %v, want %v", i,
+				pm.pollInterval, w)
+		}
+		if !pm.nextPoll.Equal(now.Add(w)) {
+			t.Fatalf("poll %v: got next poll %v, want %v", i,
+				pm.nextPoll, now.Add(w))
+		}
+		if paywallPollDue(pm, now) {
+			t.Fatalf("poll %v: pool member is due before its "+
+				"next poll", i)
+		}
+		if !paywallPollDue(pm, now.Add(w)) {
+			t.Fatalf("poll %v: pool member is not due at its "+
+				"next poll", i)
+		}
+	}
+
+	// A backoff of 1 polls at a fixed interval
+	p.cfg.PaywallPollBackoff = 1
+	pm = p.backoffPaywallPoll(paywallPoolMember{}, now)
+	pm = p.backoffPaywallPoll(pm, now)
+	if pm.pollInterval != 10*time.Second {
+		t.Fatalf("got interval %v, want %v", pm.pollInterval,
+			10*time.Second)
+	}
+}
+
+func TestResetPaywallPoll(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	u, _ := newUser(t, p, false)
+	p.addUserToPaywallPool(u, paywallTypeUser)
+
+	pm := p.backoffPaywallPoll(p.userPaywallPool[u.ID], time.Now())
+	p.setPaywallPoolMember(u.ID, pm)
+	if paywallPollDue(p.userPaywallPool[u.ID], time.Now()) {
+		t.Fatalf("pool member is due after backoff")
+	}
+
+	p.resetPaywallPoll(u.ID)
+	pm = p.userPaywallPool[u.ID]
+	if !paywallPollDue(pm, time.Now()) || pm.pollInterval != 0 {
+		t.Fatalf("pool member backoff was not reset: %v", pm)
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"net"
 	"net/http"
 	"sync"
 	"text/template"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg"
 	"github.com/decred/politeia/politeiad/cache"
@@ -61,9 +63,13 @@ type politeiawww struct {
 	// SMTP client
 	smtp *smtp
 
-	templates map[string]*template.Template
+	templates map[string]map[string]*template.Template // [locale][name]template
 	tmplMtx   sync.RWMutex
 
+	// bannedPasswords contains the banned passwords in lower case and
+	// their digests.  It is only written by initPasswordPolicy.
+	bannedPasswords map[string]struct{}
+
 	// XXX This needs to be abstracted away
 	sync.RWMutex // XXX This needs to be the first entry in struct
 
@@ -71,36 +77,61 @@ type politeiawww struct {
 	params       *chaincfg.Params
 	eventManager *EventManager
 
-	// These properties are only used for testing.
-	test bool
+	// commentLimiter limits the number of comments that a user can
+	// submit on a single proposal.
+	commentLimiter *rateLimiter
 
-	// Following entries require locks
-	userPubkeys     map[string]string               // [pubkey][userid]
-	userPaywallPool map[uuid.UUID]paywallPoolMember // [userid][paywallPoolMember]
-	commentScores   map[string]int64                // [token+commentID]resultVotes
-}
+	// trustedProxies are the reverse proxies whose X-Forwarded-For
+	// header is honored by the rate limited routes.
+	trustedProxies []*net.IPNet
 
-// XXX rig this up
-func (p *politeiawww) addTemplate(templateName, templateContent string) {
-	p.tmplMtx.Lock()
-	defer p.tmplMtx.Unlock()
+	// inventory streams proposal submissions and status changes to
+	// the clients that are watching the proposal inventory.
+	inventory *inventoryStream
 
-	p.templates[templateName] = template.Must(
-		template.New(templateName).Parse(templateContent))
-}
+	// draftsMtx serializes access to the files that the proposal
+	// drafts are stored in.
+	draftsMtx sync.Mutex
+
+	// uploadsMtx protects the staged uploads and serializes access to
+	// the files that their content is stored in.
+	uploadsMtx sync.Mutex
+	uploads    map[string]*upload // [uploadid]upload
+
+	// userPurgeMtx serializes the deletion of unverified users with the
+	// requests that verify them or renew their verification token so
+	// that a user is never deleted once it has been verified.
+	userPurgeMtx sync.Mutex
+
+	// rescansMtx protects the user payments rescans.
+	rescansMtx sync.Mutex
+	rescans    map[string]*v1.UserPaymentsRescanStatusReply // [jobid]status
+
+	// bestBlockMtx protects the last best block that politeiad reported
+	// and the time at which it was first reported.
+	bestBlockMtx  sync.Mutex
+	bestBlock     uint64
+	bestBlockSeen time.Time
 
-// XXX rig this up
-func (p *politeiawww) getTemplate(templateName string) *template.Template {
-	p.tmplMtx.RLock()
-	defer p.tmplMtx.RUnlock()
-	return p.templates[templateName]
+	// stats caches the events that the stats history is built from.
+	stats statsCache
+
+	// These properties are only used for testing.
+	test bool
+
+	// Following entries require locks
+	userPubkeys      map[string]string               // [pubkey][userid]
+	userPaywallPool  map[uuid.UUID]paywallPoolMember // [userid][paywallPoolMember]
+	commentScores    map[string]int64                // [token+commentID]resultVotes
+	commentReactions map[string]map[string]uint64    // [token+commentID][reaction]count
+	userSessions     map[string]map[string]struct{}  // [userid][sessionid]
+	webhooks         map[string]webhook              // [webhookid]webhook
+	apiTokens        map[string]apiToken             // [tokenhash]apiToken
+	billing          map[string]user.ProposalBilling // [token]ProposalBilling
+	voteEnds         map[string]uint64               // [token]endHeight
 }
 
 func (p *politeiawww) setPoliteiaWWWRoutes() {
-	// Templates
-	//p.addTemplate(templateNewProposalSubmittedName,
-	//	templateNewProposalSubmittedRaw)
-
 	// Static content.
 	// XXX disable static for now.  This code is broken and it needs to
 	// point to a sane directory.  If a directory is not set it SHALL be
@@ -109,19 +140,31 @@ func (p *politeiawww) setPoliteiaWWWRoutes() {
 	//	http.FileServer(http.Dir("."))))
 
 	// Public routes.
-	p.router.HandleFunc("/", closeBody(logging(p.handleVersion))).Methods(http.MethodGet)
-	p.router.NotFoundHandler = closeBody(p.handleNotFound)
+	p.router.HandleFunc("/", withRequestID(closeBody(logging(p.handleVersion)))).Methods(http.MethodGet)
+	p.router.NotFoundHandler = withRequestID(closeBody(p.handleNotFound))
 	p.addRoute(http.MethodGet, v1.RouteVersion, p.handleVersion,
 		permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteHealth, p.handleHealth,
+		permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteBlockHeight, p.handleBlockHeight,
+		permissionPublic)
 
 	p.addRoute(http.MethodGet, v1.RouteAllVetted, p.handleAllVetted,
 		permissionPublic)
 	p.addRoute(http.MethodGet, v1.RouteProposalDetails,
 		p.handleProposalDetails, permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteProposalMetadata,
+		p.handleProposalMetadata, permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteProposalBundle,
+		p.handleProposalBundle, permissionPublic)
 	p.addRoute(http.MethodGet, v1.RoutePolicy, p.handlePolicy,
 		permissionPublic)
 	p.addRoute(http.MethodGet, v1.RouteCommentsGet, p.handleCommentsGet,
 		permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteCommentCount, p.handleCommentCount,
+		permissionPublic)
+	p.addRoute(http.MethodPost, v1.RouteBatchCommentCounts,
+		p.handleBatchCommentCounts, permissionPublic)
 	p.addRoute(http.MethodGet, v1.RouteUserProposals, p.handleUserProposals,
 		permissionPublic)
 	p.addRoute(http.MethodGet, v1.RouteActiveVote, p.handleActiveVote,
@@ -136,6 +179,12 @@ func (p *politeiawww) setPoliteiaWWWRoutes() {
 		p.handleVoteStatus, permissionPublic)
 	p.addRoute(http.MethodGet, v1.RoutePropsStats,
 		p.handleProposalsStats, permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteStatsHistory,
+		p.handleStatsHistory, permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteInventoryStream,
+		p.handleInventoryStream, permissionPublic)
+	p.addRoute(http.MethodGet, v1.RouteProposalBilling,
+		p.handleProposalBilling, permissionPublic)
 
 	// Routes that require being logged in.
 	p.addRoute(http.MethodGet, v1.RouteProposalPaywallDetails,
@@ -146,16 +195,44 @@ func (p *politeiawww) setPoliteiaWWWRoutes() {
 		p.handleNewComment, permissionLogin)
 	p.addRoute(http.MethodPost, v1.RouteLikeComment,
 		p.handleLikeComment, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteEditComment,
+		p.handleEditComment, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteDeleteComment,
+		p.handleDeleteComment, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteReactComment,
+		p.handleReactComment, permissionLogin)
 	p.addRoute(http.MethodGet, v1.RouteUserCommentsLikes,
 		p.handleUserCommentsLikes, permissionLogin)
+	p.addRoute(http.MethodGet, v1.RouteUserComments,
+		p.handleUserComments, permissionLogin)
+	p.addRoute(http.MethodGet, v1.RouteUserCommentedProposals,
+		p.handleUserCommentedProposals, permissionLogin)
 	p.addRoute(http.MethodGet, v1.RouteUserProposalCredits,
 		p.handleUserProposalCredits, permissionLogin)
 	p.addRoute(http.MethodPost, v1.RouteEditProposal,
 		p.handleEditProposal, permissionLogin)
 	p.addRoute(http.MethodPost, v1.RouteAuthorizeVote,
 		p.handleAuthorizeVote, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteAbandonProposal,
+		p.handleAbandonProposal, permissionLogin)
 	p.addRoute(http.MethodGet, v1.RouteProposalPaywallPayment,
 		p.handleProposalPaywallPayment, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteNewDraft,
+		p.handleNewDraft, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteEditDraft,
+		p.handleEditDraft, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteDeleteDraft,
+		p.handleDeleteDraft, permissionLogin)
+	p.addRoute(http.MethodGet, v1.RouteDraftDetails,
+		p.handleDraftDetails, permissionLogin)
+	p.addRoute(http.MethodGet, v1.RouteUserDrafts,
+		p.handleUserDrafts, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteNewUpload,
+		p.handleNewUpload, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteUploadChunk,
+		p.handleUploadChunk, permissionLogin)
+	p.addRoute(http.MethodGet, v1.RouteUploadStatus,
+		p.handleUploadStatus, permissionLogin)
 
 	// Unauthenticated websocket
 	p.addRoute("", v1.RouteUnauthenticatedWebSocket,
@@ -169,8 +246,20 @@ func (p *politeiawww) setPoliteiaWWWRoutes() {
 		permissionAdmin)
 	p.addRoute(http.MethodPost, v1.RouteSetProposalStatus,
 		p.handleSetProposalStatus, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteBatchSetProposalStatus,
+		p.handleBatchSetProposalStatus, permissionAdmin)
 	p.addRoute(http.MethodPost, v1.RouteStartVote,
 		p.handleStartVote, permissionAdmin)
 	p.addRoute(http.MethodPost, v1.RouteCensorComment,
 		p.handleCensorComment, permissionAdmin)
+	p.addRoute(http.MethodGet, v1.RouteWebhooks,
+		p.handleWebhooks, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteNewWebhook,
+		p.handleNewWebhook, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteDeleteWebhook,
+		p.handleDeleteWebhook, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteSetProposalBudget,
+		p.handleSetProposalBudget, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteRecordProposalSpend,
+		p.handleRecordProposalSpend, permissionAdmin)
 }
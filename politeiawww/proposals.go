@@ -38,6 +38,7 @@ type proposalsFilter struct {
 	Before   string
 	UserID   string
 	StateMap map[www.PropStateT]bool
+	Limit    uint // Page size; 0 means www.ProposalListPageSize
 }
 
 // getProp gets the most recent verions of the given proposal from the cache
@@ -186,6 +187,7 @@ func filterProps(filter proposalsFilter, all []www.ProposalRecord) []www.Proposa
 	// supplied, we must find the beginning (or end) of the page first.
 	pageStarted := (filter.After == "" && filter.Before == "")
 	beforeIdx := -1
+	pageSize := listPageSize(filter.Limit, www.ProposalListPageSize)
 	proposals := make([]www.ProposalRecord, 0, len(all))
 
 	// Iterate in reverse order because they're sorted by oldest
@@ -205,7 +207,7 @@ func filterProps(filter proposalsFilter, all []www.ProposalRecord) []www.Proposa
 
 		if pageStarted {
 			proposals = append(proposals, proposal)
-			if len(proposals) >= www.ProposalListPageSize {
+			if len(proposals) >= pageSize {
 				break
 			}
 		} else if filter.After != "" {
@@ -242,7 +244,7 @@ func filterProps(filter proposalsFilter, all []www.ProposalRecord) []www.Proposa
 			// the result will be newest -> oldest.
 			proposals = append([]www.ProposalRecord{proposal},
 				proposals...)
-			if len(proposals) >= www.ProposalListPageSize {
+			if len(proposals) >= pageSize {
 				break
 			}
 		}
@@ -343,6 +345,9 @@ func (p *politeiawww) getPropComments(token string) ([]www.Comment, error) {
 		}
 		c.ResultVotes = score
 
+		// Fill in reactions
+		c.Reactions = p.commentReactions[c.Token+c.CommentID]
+
 		comments = append(comments, c)
 	}
 
@@ -365,7 +370,19 @@ func (p *politeiawww) ProcessNewProposal(np www.NewProposal, user *user.User) (*
 		}
 	}
 
-	err := validateProposal(np, user)
+	// Replace the staged uploads with their content
+	files, uploads, err := p.assembleUploads(np.Files, user)
+	if err != nil {
+		return nil, err
+	}
+	np.Files = files
+
+	err = validateProposal(np, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.validateProposalLinks(np, user)
 	if err != nil {
 		return nil, err
 	}
@@ -381,6 +398,8 @@ func (p *politeiawww) ProcessNewProposal(np www.NewProposal, user *user.User) (*
 		Name:      name,
 		PublicKey: np.PublicKey,
 		Signature: np.Signature,
+		LinkTo:    np.LinkTo,
+		LinkBy:    np.LinkBy,
 	})
 	if err != nil {
 		return nil, err
@@ -412,6 +431,7 @@ func (p *politeiawww) ProcessNewProposal(np www.NewProposal, user *user.User) (*
 				Token: hex.EncodeToString(tokenBytes),
 			},
 		}
+		p.deleteUploads(uploads)
 
 		return &www.NewProposalReply{
 			CensorshipRecord: convertPropCensorFromPD(testReply.CensorshipRecord),
@@ -444,6 +464,10 @@ func (p *politeiawww) ProcessNewProposal(np www.NewProposal, user *user.User) (*
 
 	cr := convertPropCensorFromPD(pdReply.CensorshipRecord)
 
+	// The staged uploads are no longer needed once the proposal has been
+	// submitted
+	p.deleteUploads(uploads)
+
 	// Deduct proposal credit from user account
 	err = p.SpendProposalCredit(user, cr.Token)
 	if err != nil {
@@ -516,27 +540,60 @@ func (p *politeiawww) ProcessProposalDetails(propDetails www.ProposalsDetails, u
 	return &reply, nil
 }
 
-// ProcessSetProposalStatus changes the status of an existing proposal.
-func (p *politeiawww) ProcessSetProposalStatus(sps www.SetProposalStatus, u *user.User) (*www.SetProposalStatusReply, error) {
-	log.Tracef("ProcessSetProposalStatus %v", sps.Token)
+// processProposalMetadata returns all metadata streams of the latest version
+// of a proposal.  The metadata of vetted proposals is viewable by everyone.
+// The metadata of unvetted and censored proposals contains the proposal name
+// so it is only viewable by admins and the proposal author.
+func (p *politeiawww) processProposalMetadata(token string, u *user.User) (*www.ProposalMetadataReply, error) {
+	log.Tracef("processProposalMetadata: %v", token)
 
-	err := checkPublicKeyAndSignature(u, sps.PublicKey, sps.Signature,
-		sps.Token, strconv.FormatUint(uint64(sps.ProposalStatus), 10),
-		sps.StatusChangeMessage)
+	r, err := p.cache.Record(token)
 	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusProposalNotFound,
+			}
+		}
 		return nil, err
 	}
 
-	// Ensure the status change message is not blank if the proposal
-	// is being censored or abandoned
-	if sps.StatusChangeMessage == "" &&
-		(sps.ProposalStatus == www.PropStatusCensored ||
-			sps.ProposalStatus == www.PropStatusAbandoned) {
-		return nil, www.UserError{
-			ErrorCode: www.ErrorStatusChangeMessageCannotBeBlank,
+	pr := convertPropFromCache(*r)
+	if pr.State == www.PropStateUnvetted {
+		var isAuthor bool
+		var isAdmin bool
+		// This is a public route so a user may not exist
+		if u != nil {
+			p.RLock()
+			authorID := p.userPubkeys[pr.PublicKey]
+			p.RUnlock()
+
+			isAdmin = u.Admin
+			isAuthor = (authorID == u.ID.String())
+		}
+
+		// Do not reveal the existence of the proposal to users
+		// that are not allowed to view it
+		if !isAuthor && !isAdmin {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusProposalNotFound,
+			}
 		}
 	}
 
+	return &www.ProposalMetadataReply{
+		Metadata: convertMetadataStreamsFromCache(r.Metadata),
+	}, nil
+}
+
+// ProcessSetProposalStatus changes the status of an existing proposal.
+func (p *politeiawww) ProcessSetProposalStatus(sps www.SetProposalStatus, u *user.User) (*www.SetProposalStatusReply, error) {
+	log.Tracef("ProcessSetProposalStatus %v", sps.Token)
+
+	err := validateSetProposalStatus(sps, u)
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure user is an admin. Only admins are allowed to change
 	// a proposal status.
 	adminPubKey, ok := user.ActiveIdentityString(u.Identities)
@@ -578,31 +635,9 @@ func (p *politeiawww) ProcessSetProposalStatus(sps www.SetProposalStatus, u *use
 		}
 	}
 
-	// Create change record
-	newStatus := convertPropStatusFromWWW(sps.ProposalStatus)
-	blob, err := json.Marshal(MDStreamChanges{
-		Version:             VersionMDStreamChanges,
-		Timestamp:           time.Now().Unix(),
-		NewStatus:           newStatus,
-		AdminPubKey:         adminPubKey,
-		StatusChangeMessage: sps.StatusChangeMessage,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// Create challenge
-	challenge, err := util.Random(pd.ChallengeSize)
-	if err != nil {
-		return nil, err
-	}
-
-	var challengeResponse string
+	// Verify status transition is valid
 	switch {
 	case pr.State == www.PropStateUnvetted:
-		// Unvetted status change
-
-		// Verify status transition is valid
 		switch {
 		case pr.Status == www.PropStatusNotReviewed &&
 			(sps.ProposalStatus == www.PropStatusCensored ||
@@ -618,72 +653,309 @@ func (p *politeiawww) ProcessSetProposalStatus(sps www.SetProposalStatus, u *use
 			}
 		}
 
-		// Setup request
-		sus := pd.SetUnvettedStatus{
-			Token:     sps.Token,
-			Status:    newStatus,
-			Challenge: hex.EncodeToString(challenge),
-			MDAppend: []pd.MetadataStream{
-				{
-					ID:      mdStreamChanges,
-					Payload: string(blob),
-				},
-			},
+	case pr.State == www.PropStateVetted:
+		// We only allow a transition from public to abandoned
+		if pr.Status != www.PropStatusPublic ||
+			sps.ProposalStatus != www.PropStatusAbandoned {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusInvalidPropStatusTransition,
+			}
 		}
 
-		// Send unvetted status change request
-		responseBody, err := p.makeRequest(http.MethodPost,
-			pd.SetUnvettedStatusRoute, sus)
+		// Ensure voting has not been started or authorized yet
+		err = p.checkVoteNotAuthorized(pr.CensorshipRecord.Token)
 		if err != nil {
 			return nil, err
 		}
 
-		var susr pd.SetUnvettedStatusReply
-		err = json.Unmarshal(responseBody, &susr)
-		if err != nil {
-			return nil, fmt.Errorf("could not unmarshal "+
-				"SetUnvettedStatusReply: %v", err)
-		}
-		challengeResponse = susr.Response
+	default:
+		return nil, fmt.Errorf("invalid proposal state %v: %v",
+			pr.State, pr.CensorshipRecord.Token)
+	}
 
-	case pr.State == www.PropStateVetted:
-		// Vetted status change
+	updatedProp, err := p.setProposalStatus(pr, sps.ProposalStatus,
+		MDStreamChanges{
+			AdminPubKey:         adminPubKey,
+			StatusChangeMessage: sps.StatusChangeMessage,
+		})
+	if err != nil {
+		return nil, err
+	}
 
-		// We only allow a transition from public to abandoned
-		if pr.Status != www.PropStatusPublic ||
-			sps.ProposalStatus != www.PropStatusAbandoned {
+	// Fire off proposal status change event
+	p.eventManager._fireEvent(EventTypeProposalStatusChange,
+		EventDataProposalStatusChange{
+			Proposal:          updatedProp,
+			AdminUser:         u,
+			SetProposalStatus: &sps,
+		},
+	)
+
+	return &www.SetProposalStatusReply{
+		Proposal: *updatedProp,
+	}, nil
+}
+
+// ProcessBatchSetProposalStatus sets the status of multiple proposals.  Each
+// status change goes through ProcessSetProposalStatus so that it is validated
+// and announced exactly like a single status change.
+//
+// politeiad does not support changing the status of multiple records
+// atomically.  The batch is rejected as a whole when it is malformed or when
+// any of the status changes has an invalid signature or message, so that a
+// bad request does not leave the batch partially applied.  Failures that
+// depend on the state of a proposal are only detected once the status change
+// is applied and are reported in the result of that status change without
+// undoing the status changes that succeeded.
+func (p *politeiawww) ProcessBatchSetProposalStatus(bsps www.BatchSetProposalStatus, u *user.User) (*www.BatchSetProposalStatusReply, error) {
+	log.Tracef("ProcessBatchSetProposalStatus")
+
+	if len(bsps.StatusChanges) == 0 ||
+		len(bsps.StatusChanges) > www.ProposalStatusBatchSize {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("batch must contain "+
+				"between 1 and %v status changes",
+				www.ProposalStatusBatchSize)},
+		}
+	}
+
+	// Validate the batch before any status is changed
+	tokens := make(map[string]struct{}, len(bsps.StatusChanges))
+	for _, v := range bsps.StatusChanges {
+		if _, ok := tokens[v.Token]; ok {
 			return nil, www.UserError{
-				ErrorCode: www.ErrorStatusInvalidPropStatusTransition,
+				ErrorCode:    www.ErrorStatusInvalidInput,
+				ErrorContext: []string{"duplicate token " + v.Token},
 			}
 		}
+		tokens[v.Token] = struct{}{}
 
-		// Ensure voting has not been started or authorized yet
-		vdr, err := p.decredVoteDetails(pr.CensorshipRecord.Token)
+		err := validateSetProposalStatus(v, u)
 		if err != nil {
-			return nil, fmt.Errorf("decredVoteDetails: %v", err)
+			if e, ok := err.(www.UserError); ok {
+				e.ErrorContext = append(e.ErrorContext, v.Token)
+				err = e
+			}
+			return nil, err
 		}
-		vd := convertVoteDetailsReplyFromDecred(*vdr)
-		if vd.StartVoteReply.StartBlockHeight != "" ||
-			voteIsAuthorized(vd.AuthorizeVoteReply) {
-			return nil, www.UserError{
-				ErrorCode: www.ErrorStatusWrongVoteStatus,
+	}
+
+	// Apply the status changes
+	results := make([]www.SetProposalStatusResult, 0, len(bsps.StatusChanges))
+	for _, v := range bsps.StatusChanges {
+		result := www.SetProposalStatusResult{
+			Token: v.Token,
+		}
+		reply, err := p.ProcessSetProposalStatus(v, u)
+		switch e := err.(type) {
+		case nil:
+			result.Proposal = &reply.Proposal
+		case www.UserError:
+			result.ErrorCode = e.ErrorCode
+			result.Error = www.ErrorStatus[e.ErrorCode]
+		default:
+			log.Errorf("ProcessBatchSetProposalStatus: "+
+				"ProcessSetProposalStatus %v: %v", v.Token, err)
+			result.Error = www.ErrorMessageInternal
+		}
+		results = append(results, result)
+	}
+
+	return &www.BatchSetProposalStatusReply{
+		Results: results,
+	}, nil
+}
+
+// validateSetProposalStatus verifies the parts of a set proposal status
+// command that do not depend on the current state of the proposal.
+func validateSetProposalStatus(sps www.SetProposalStatus, u *user.User) error {
+	err := checkPublicKeyAndSignature(u, sps.PublicKey, sps.Signature,
+		sps.Token, strconv.FormatUint(uint64(sps.ProposalStatus), 10),
+		sps.StatusChangeMessage)
+	if err != nil {
+		return err
+	}
+
+	// Ensure the status change message is not blank if the proposal
+	// is being censored or abandoned
+	if sps.StatusChangeMessage == "" &&
+		(sps.ProposalStatus == www.PropStatusCensored ||
+			sps.ProposalStatus == www.PropStatusAbandoned) {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusChangeMessageCannotBeBlank,
+		}
+	}
+
+	return nil
+}
+
+// ProcessAbandonProposal allows the author of a proposal to withdraw it.  A
+// public proposal is abandoned as long as its vote has not been authorized or
+// started.  politeiad only allows unvetted records to be made public or to be
+// censored, so an unvetted proposal is withdrawn by censoring it.  The status
+// change records the key of the author instead of an admin key and a proposal
+// withdrawn event is fired instead of the admin status change event.
+func (p *politeiawww) ProcessAbandonProposal(ap www.AbandonProposal, u *user.User) (*www.AbandonProposalReply, error) {
+	log.Tracef("ProcessAbandonProposal %v", ap.Token)
+
+	err := checkPublicKeyAndSignature(u, ap.PublicKey, ap.Signature,
+		ap.Token, ap.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	if ap.Message == "" {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusChangeMessageCannotBeBlank,
+		}
+	}
+
+	// Handle test case
+	if p.test {
+		var reply www.AbandonProposalReply
+		reply.Proposal.Status = www.PropStatusAbandoned
+		return &reply, nil
+	}
+
+	// Get proposal from cache
+	pr, err := p.getProp(ap.Token)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusProposalNotFound,
 			}
 		}
+		return nil, err
+	}
+
+	// Ensure the user is the proposal author
+	if pr.UserId != u.ID.String() {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserNotAuthor,
+		}
+	}
 
-		// Setup request
-		svs := pd.SetVettedStatus{
-			Token:     sps.Token,
+	// Determine the new status
+	var newStatus www.PropStatusT
+	switch pr.Status {
+	case www.PropStatusNotReviewed, www.PropStatusUnreviewedChanges:
+		newStatus = www.PropStatusCensored
+	case www.PropStatusPublic:
+		err = p.checkVoteNotAuthorized(pr.CensorshipRecord.Token)
+		if err != nil {
+			return nil, err
+		}
+		newStatus = www.PropStatusAbandoned
+	default:
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidPropStatusTransition,
+		}
+	}
+
+	// The author is recorded as the author of the status change so
+	// that the withdrawal is not mistaken for a moderation action.
+	updatedProp, err := p.setProposalStatus(pr, newStatus,
+		MDStreamChanges{
+			AuthorPubKey:        ap.PublicKey,
+			StatusChangeMessage: ap.Message,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// Fire off proposal withdrawn event
+	p.fireEvent(EventTypeProposalWithdrawn,
+		EventDataProposalWithdrawn{
+			Proposal:        updatedProp,
+			AbandonProposal: &ap,
+			User:            u,
+		},
+	)
+
+	return &www.AbandonProposalReply{
+		Proposal: *updatedProp,
+	}, nil
+}
+
+// checkVoteNotAuthorized returns ErrorStatusWrongVoteStatus if the vote of the
+// proposal has been authorized or started.
+func (p *politeiawww) checkVoteNotAuthorized(token string) error {
+	vdr, err := p.decredVoteDetails(token)
+	if err != nil {
+		return fmt.Errorf("decredVoteDetails: %v", err)
+	}
+	vd := convertVoteDetailsReplyFromDecred(*vdr)
+	if vd.StartVoteReply.StartBlockHeight != "" ||
+		voteIsAuthorized(vd.AuthorizeVoteReply) {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusWrongVoteStatus,
+		}
+	}
+	return nil
+}
+
+// setProposalStatus sends a status change for the proposal to politeiad and
+// returns the updated proposal.  The status change is recorded in the changes
+// metadata stream.  The caller sets the public key of the user that made the
+// change and the status change message in c; the remaining fields are filled
+// in here.  The caller must verify that the status transition is valid.
+func (p *politeiawww) setProposalStatus(pr *www.ProposalRecord, status www.PropStatusT, c MDStreamChanges) (*www.ProposalRecord, error) {
+	// Create change record
+	newStatus := convertPropStatusFromWWW(status)
+	c.Version = VersionMDStreamChanges
+	c.Timestamp = time.Now().Unix()
+	c.NewStatus = newStatus
+	blob, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create challenge
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	mdAppend := []pd.MetadataStream{
+		{
+			ID:      mdStreamChanges,
+			Payload: string(blob),
+		},
+	}
+
+	var challengeResponse string
+	switch pr.State {
+	case www.PropStateUnvetted:
+		// Send unvetted status change request
+		sus := pd.SetUnvettedStatus{
+			Token:     pr.CensorshipRecord.Token,
 			Status:    newStatus,
 			Challenge: hex.EncodeToString(challenge),
-			MDAppend: []pd.MetadataStream{
-				{
-					ID:      mdStreamChanges,
-					Payload: string(blob),
-				},
-			},
+			MDAppend:  mdAppend,
+		}
+		responseBody, err := p.makeRequest(http.MethodPost,
+			pd.SetUnvettedStatusRoute, sus)
+		if err != nil {
+			return nil, err
+		}
+
+		var susr pd.SetUnvettedStatusReply
+		err = json.Unmarshal(responseBody, &susr)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal "+
+				"SetUnvettedStatusReply: %v", err)
 		}
+		challengeResponse = susr.Response
 
+	case www.PropStateVetted:
 		// Send vetted status change request
+		svs := pd.SetVettedStatus{
+			Token:     pr.CensorshipRecord.Token,
+			Status:    newStatus,
+			Challenge: hex.EncodeToString(challenge),
+			MDAppend:  mdAppend,
+		}
 		responseBody, err := p.makeRequest(http.MethodPost,
 			pd.SetVettedStatusRoute, svs)
 		if err != nil {
@@ -711,23 +983,7 @@ func (p *politeiawww) ProcessSetProposalStatus(sps www.SetProposalStatus, u *use
 	}
 
 	// Get record from the cache
-	updatedProp, err := p.getPropVersion(pr.CensorshipRecord.Token, pr.Version)
-	if err != nil {
-		return nil, err
-	}
-
-	// Fire off proposal status change event
-	p.eventManager._fireEvent(EventTypeProposalStatusChange,
-		EventDataProposalStatusChange{
-			Proposal:          updatedProp,
-			AdminUser:         u,
-			SetProposalStatus: &sps,
-		},
-	)
-
-	return &www.SetProposalStatusReply{
-		Proposal: *updatedProp,
-	}, nil
+	return p.getPropVersion(pr.CensorshipRecord.Token, pr.Version)
 }
 
 // ProcessEditProposal attempts to edit a proposal on politeiad.
@@ -779,29 +1035,43 @@ func (p *politeiawww) ProcessEditProposal(ep www.EditProposal, u *user.User) (*w
 	}
 
 	// Validate proposal. Convert it to www.NewProposal so that
-	// we can reuse the function validateProposal.
+	// we can reuse the function validateProposal.  The RFP links of
+	// the proposal can not be edited but are covered by the signature
+	// of the edit.
 	np := www.NewProposal{
 		Files:     ep.Files,
 		PublicKey: ep.PublicKey,
 		Signature: ep.Signature,
+		LinkTo:    cachedProp.LinkTo,
+		LinkBy:    cachedProp.LinkBy,
 	}
 	err = validateProposal(np, u)
 	if err != nil {
 		return nil, err
 	}
 
+	// Ensure the edit continues the current version
+	err = validateProposalEdit(ep, *cachedProp)
+	if err != nil {
+		return nil, err
+	}
+
 	// Assemble metadata record
 	name, err := getProposalName(ep.Files)
 	if err != nil {
 		return nil, err
 	}
 
+	// The RFP links are set when the proposal is submitted and can not
+	// be edited.
 	backendMetadata := BackendProposalMetadata{
 		Version:   BackendProposalMetadataVersion,
 		Timestamp: time.Now().Unix(),
 		Name:      name,
 		PublicKey: ep.PublicKey,
 		Signature: ep.Signature,
+		LinkTo:    cachedProp.LinkTo,
+		LinkBy:    cachedProp.LinkBy,
 	}
 	md, err := encodeBackendProposalMetadata(backendMetadata)
 	if err != nil {
@@ -877,6 +1147,16 @@ func (p *politeiawww) ProcessEditProposal(ep www.EditProposal, u *user.User) (*w
 		return nil, err
 	}
 
+	// The edit has been validated before it was submitted and has
+	// already been saved by politeiad, so a broken version chain can
+	// only be reported.  The edit is not undone and the edit event is
+	// still fired.
+	err = verifyProposalEditChain(ep, *cachedProp, *updatedProp)
+	if err != nil {
+		log.Criticalf("ProcessEditProposal: proposal %v edit: %v",
+			ep.Token, err)
+	}
+
 	// Fire off edit proposal event
 	p.eventManager._fireEvent(EventTypeProposalEdited,
 		EventDataProposalEdited{
@@ -889,8 +1169,19 @@ func (p *politeiawww) ProcessEditProposal(ep www.EditProposal, u *user.User) (*w
 	}, nil
 }
 
+// listPageSize returns the number of items of a list page given the limit
+// that was requested by the client.  A limit of zero or above the maximum
+// page size results in the maximum page size.
+func listPageSize(limit uint, max int) int {
+	if limit == 0 || limit > uint(max) {
+		return max
+	}
+	return int(limit)
+}
+
 // ProcessAllVetted returns an array of vetted proposals. The maximum number
-// of proposals returned is dictated by www.ProposalListPageSize.
+// of proposals returned is dictated by www.ProposalListPageSize, or by the
+// requested limit if it is lower.
 func (p *politeiawww) ProcessAllVetted(v www.GetAllVetted) (*www.GetAllVettedReply, error) {
 	log.Tracef("ProcessAllVetted")
 
@@ -904,6 +1195,7 @@ func (p *politeiawww) ProcessAllVetted(v www.GetAllVetted) (*www.GetAllVettedRep
 	filter := proposalsFilter{
 		After:  v.After,
 		Before: v.Before,
+		Limit:  v.Limit,
 		StateMap: map[www.PropStateT]bool{
 			www.PropStateVetted: true,
 		},
@@ -975,17 +1267,29 @@ func (p *politeiawww) ProcessProposalsStats() (*www.ProposalsStatsReply, error)
 	}, nil
 }
 
-// ProcessCommentsGet returns all comments for a given proposal. If the user is
-// logged in the user's last access time for the given comments will also be
-// returned.
-func (p *politeiawww) ProcessCommentsGet(token string, u *user.User) (*www.GetCommentsReply, error) {
-	log.Tracef("ProcessCommentGet: %v", token)
+// ProcessCommentsGet returns all comments for a given proposal. If a since
+// timestamp is provided, only the comments that were created or edited after
+// it are returned. If the user is logged in the user's last access time for
+// the given comments will also be returned.
+func (p *politeiawww) ProcessCommentsGet(gc www.GetComments, u *user.User) (*www.GetCommentsReply, error) {
+	log.Tracef("ProcessCommentGet: %v %v", gc.Token, gc.Since)
+
+	if gc.Since < 0 {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"since cannot be negative"},
+		}
+	}
+	token := gc.Token
 
 	// Fetch proposal comments from cache
 	c, err := p.getPropComments(token)
 	if err != nil {
 		return nil, err
 	}
+	if gc.Since > 0 {
+		c = filterCommentsSince(c, gc.Since)
+	}
 
 	// Get the last time the user accessed these comments. This is
 	// a public route so a user may not exist.
@@ -1008,6 +1312,51 @@ func (p *politeiawww) ProcessCommentsGet(token string, u *user.User) (*www.GetCo
 	}, nil
 }
 
+// filterCommentsSince returns the comments that were created, edited or
+// deleted after the given UNIX timestamp.
+func filterCommentsSince(comments []www.Comment, since int64) []www.Comment {
+	filtered := make([]www.Comment, 0, len(comments))
+	for _, c := range comments {
+		if c.Timestamp > since || c.Edited > since || c.Deleted > since {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// voteBitApprove is the vote bit of the option that approves a proposal.
+// Proposal votes are cast and tallied by their bits; the option IDs are only
+// labels.
+const voteBitApprove = 0x02
+
+// voteApproved returns whether the passed in vote has finished and has been
+// approved.  The votes are counted using the option results of the vote,
+// see voteResults.  A vote is approved when the votes for its options reach
+// the quorum and the option with the approve bit received at least the pass
+// percentage of them.
+func voteApproved(vs *www.VoteStatusReply) bool {
+	if vs.Status != www.PropVoteStatusFinished {
+		return false
+	}
+
+	var total, approve uint64
+	for _, v := range vs.OptionsResult {
+		total += v.VotesReceived
+		if v.Option.Bits == voteBitApprove {
+			approve = v.VotesReceived
+		}
+	}
+	if total == 0 {
+		return false
+	}
+
+	quorum := uint64(vs.NumOfEligibleVotes) * uint64(vs.QuorumPercentage) / 100
+	if total < quorum {
+		return false
+	}
+	return approve*100 >= total*uint64(vs.PassPercentage)
+}
+
 func voteResults(sv www.StartVote, cv []www.CastVote) []www.VoteOptionResult {
 	log.Tracef("voteResults: %v", sv.Vote.Token)
 
@@ -16,6 +16,7 @@ import (
 	"math/rand"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/decred/dcrtime/merkle"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
@@ -192,6 +193,20 @@ func TestValidateProposal(t *testing.T) {
 	mdBadTitle := createFileMD(t, 8, "{invalid-title}")
 	propBadTitle := createNewProposal(t, id, []www.File{*mdBadTitle})
 
+	// RFP link that is not covered by the signature
+	propUnsignedLink := *np
+	propUnsignedLink.LinkTo = "token"
+
+	// RFP link that is covered by the signature
+	root, err := proposalMerkleRoot(np.Files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	propSignedLink := propUnsignedLink
+	linkSig := id.SignMessage([]byte(www.ProposalSignatureMessage(root,
+		propSignedLink.LinkTo, propSignedLink.LinkBy)))
+	propSignedLink.Signature = hex.EncodeToString(linkSig[:])
+
 	// Setup test cases
 	var tests = []struct {
 		name        string
@@ -250,6 +265,13 @@ func TestValidateProposal(t *testing.T) {
 			www.UserError{
 				ErrorCode: www.ErrorStatusProposalInvalidTitle,
 			}},
+
+		{"unsigned rfp link", propUnsignedLink, usr,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidSignature,
+			}},
+
+		{"signed rfp link", propSignedLink, usr, nil},
 	}
 
 	// Run test cases
@@ -265,6 +287,213 @@ func TestValidateProposal(t *testing.T) {
 	}
 }
 
+func TestValidateProposalEdit(t *testing.T) {
+	id, err := identity.New()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	md := createFileMD(t, 8, "Valid Proposal Name")
+	np := createNewProposal(t, id, []www.File{*md})
+	mr, err := proposalMerkleRoot(np.Files)
+	if err != nil {
+		t.Fatalf("proposalMerkleRoot: %v", err)
+	}
+	cur := www.ProposalRecord{
+		Files:     np.Files,
+		PublicKey: np.PublicKey,
+		Signature: np.Signature,
+		Version:   "1",
+		CensorshipRecord: www.CensorshipRecord{
+			Token:  "token",
+			Merkle: mr,
+		},
+	}
+
+	// A valid edit changes the files
+	edited := createNewProposal(t, id,
+		[]www.File{*createFileMD(t, 8, "Valid Proposal Name")})
+	edit := www.EditProposal{
+		Token:     "token",
+		Files:     edited.Files,
+		PublicKey: edited.PublicKey,
+		Signature: edited.Signature,
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		ep   www.EditProposal
+		want error
+	}{
+		{"unchanged files",
+			www.EditProposal{
+				Token:     "token",
+				Files:     np.Files,
+				PublicKey: np.PublicKey,
+				Signature: np.Signature,
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidProposalEdit,
+			}},
+
+		{"valid edit", edit, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := validateProposalEdit(v.ep, cur)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v", got, want)
+			}
+		})
+	}
+
+	// The updated proposal must be the version created by the edit
+	emr, err := proposalMerkleRoot(edit.Files)
+	if err != nil {
+		t.Fatalf("proposalMerkleRoot: %v", err)
+	}
+	updated := www.ProposalRecord{
+		Files:     edit.Files,
+		PublicKey: edit.PublicKey,
+		Signature: edit.Signature,
+		Version:   "2",
+		CensorshipRecord: www.CensorshipRecord{
+			Token:  "token",
+			Merkle: emr,
+		},
+	}
+	err = verifyProposalEditChain(edit, cur, updated)
+	if err != nil {
+		t.Errorf("verifyProposalEditChain: %v", err)
+	}
+
+	stale := updated
+	stale.CensorshipRecord.Merkle = mr
+	err = verifyProposalEditChain(edit, cur, stale)
+	if err == nil {
+		t.Errorf("got nil error for stale merkle root, want error")
+	}
+
+	older := updated
+	older.Version = "0"
+	err = verifyProposalEditChain(edit, cur, older)
+	if err == nil {
+		t.Errorf("got nil error for older version, want error")
+	}
+
+	same := updated
+	same.Version = cur.Version
+	err = verifyProposalEditChain(edit, cur, same)
+	if err == nil {
+		t.Errorf("got nil error for unchanged version, want error")
+	}
+
+	skipped := updated
+	skipped.Version = "3"
+	err = verifyProposalEditChain(edit, cur, skipped)
+	if err == nil {
+		t.Errorf("got nil error for skipped version, want error")
+	}
+}
+
+func TestValidateProposalLinks(t *testing.T) {
+	now := time.Now().Unix()
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"linkby in the past", validateLinkBy(now-1, now),
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidLinkBy,
+			}},
+		{"linkby now", validateLinkBy(now, now),
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidLinkBy,
+			}},
+		{"linkby in the future", validateLinkBy(now+1, now), nil},
+		{"linked proposal is not an rfp",
+			validateLinkTo(www.ProposalRecord{
+				Status: www.PropStatusPublic,
+			}, now),
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidLinkTo,
+			}},
+		{"rfp not public",
+			validateLinkTo(www.ProposalRecord{
+				Status: www.PropStatusNotReviewed,
+				LinkBy: now + 1,
+			}, now),
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidLinkTo,
+			}},
+		{"rfp submissions closed",
+			validateLinkTo(www.ProposalRecord{
+				Status: www.PropStatusPublic,
+				LinkBy: now,
+			}, now),
+			www.UserError{
+				ErrorCode: www.ErrorStatusRFPSubmissionsClosed,
+			}},
+		{"open rfp",
+			validateLinkTo(www.ProposalRecord{
+				Status: www.PropStatusPublic,
+				LinkBy: now + 1,
+			}, now), nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := errToStr(v.err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v", got, want)
+			}
+		})
+	}
+
+	// A proposal can not be both an RFP and a submission to an RFP
+	var p politeiawww
+	admin := &user.User{Admin: true}
+	err := p.validateProposalLinks(www.NewProposal{
+		LinkTo: "token",
+		LinkBy: now + 1,
+	}, admin)
+	got := errToStr(err)
+	want := errToStr(www.UserError{
+		ErrorCode: www.ErrorStatusInvalidLinkTo,
+	})
+	if got != want {
+		t.Errorf("got error %v, want %v", got, want)
+	}
+
+	// Only admins can submit an RFP
+	err = p.validateProposalLinks(www.NewProposal{
+		LinkBy: now + 60,
+	}, &user.User{})
+	got = errToStr(err)
+	want = errToStr(www.UserError{
+		ErrorCode: www.ErrorStatusInvalidLinkBy,
+	})
+	if got != want {
+		t.Errorf("got error %v, want %v", got, want)
+	}
+	err = p.validateProposalLinks(www.NewProposal{
+		LinkBy: now + 60,
+	}, admin)
+	if err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+}
+
 func TestFilterProposals(t *testing.T) {
 	// Test proposal page size. Only a single page of proposals
 	// should be returned.
@@ -295,6 +524,19 @@ func TestFilterProposals(t *testing.T) {
 		if len(out) != www.ProposalListPageSize {
 			t.Errorf("got %v, want %v", len(out), www.ProposalListPageSize)
 		}
+
+		// A limit lowers the page size but can not raise it
+		pq.Limit = 5
+		out = filterProps(pq, propsPageTest)
+		if len(out) != 5 {
+			t.Errorf("got %v with limit 5, want 5", len(out))
+		}
+		pq.Limit = www.ProposalListPageSize + 1
+		out = filterProps(pq, propsPageTest)
+		if len(out) != www.ProposalListPageSize {
+			t.Errorf("got %v with limit above max, want %v", len(out),
+				www.ProposalListPageSize)
+		}
 	})
 
 	// Create data for test table. We use simplified userIDs,
@@ -435,3 +677,266 @@ func TestFilterProposals(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterCommentsSince(t *testing.T) {
+	// Comment 1 was created before the since timestamp and never
+	// edited, comment 2 was created before it but edited after it,
+	// comment 3 was created at it, comment 4 was created after it and
+	// comment 5 was created before it but deleted after it.
+	comments := []www.Comment{
+		{CommentID: "1", Timestamp: 100},
+		{CommentID: "2", Timestamp: 100, Edited: 300},
+		{CommentID: "3", Timestamp: 200},
+		{CommentID: "4", Timestamp: 300},
+		{CommentID: "5", Timestamp: 100, Deleted: 300},
+	}
+
+	var tests = []struct {
+		name  string
+		since int64
+		want  []string
+	}{
+		{"all comments", 50, []string{"1", "2", "3", "4", "5"}},
+		{"created, edited or deleted after", 200,
+			[]string{"2", "4", "5"}},
+		{"no comments", 300, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := filterCommentsSince(comments, test.since)
+
+			got := make([]string, 0, len(out))
+			for _, c := range out {
+				got = append(got, c.CommentID)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+			for i, w := range test.want {
+				if w != got[i] {
+					t.Fatalf("got %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestVoteApproved(t *testing.T) {
+	voteStatus := func(status www.PropVoteStatusT, yes, no uint64) *www.VoteStatusReply {
+		return &www.VoteStatusReply{
+			Status:     status,
+			TotalVotes: yes + no,
+			OptionsResult: []www.VoteOptionResult{
+				{
+					Option: www.VoteOption{
+						Id:   "reject",
+						Bits: 0x01,
+					},
+					VotesReceived: no,
+				},
+				{
+					Option: www.VoteOption{
+						Id:   "approve",
+						Bits: voteBitApprove,
+					},
+					VotesReceived: yes,
+				},
+			},
+			NumOfEligibleVotes: 100,
+			QuorumPercentage:   20,
+			PassPercentage:     60,
+		}
+	}
+
+	var tests = []struct {
+		name string
+		vs   *www.VoteStatusReply
+		want bool
+	}{
+		{"approved", voteStatus(www.PropVoteStatusFinished, 15, 10), true},
+		{"exactly pass percentage",
+			voteStatus(www.PropVoteStatusFinished, 12, 8), true},
+		{"rejected", voteStatus(www.PropVoteStatusFinished, 10, 15), false},
+		{"quorum not reached",
+			voteStatus(www.PropVoteStatusFinished, 19, 0), false},
+		{"vote not finished",
+			voteStatus(www.PropVoteStatusStarted, 25, 0), false},
+		{"no votes", voteStatus(www.PropVoteStatusFinished, 0, 0), false},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := voteApproved(v.vs)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestProcessAbandonProposal(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, id := newUser(t, p, false)
+	token := "a5ed7ab14bb59ec8b48b5d25b8f1e5fc8fd0bb4ba3c81d22dfa2c25b2ab4e8c1"
+	msg := "no longer pursuing this"
+	sig := id.SignMessage([]byte(token + msg))
+	blankSig := id.SignMessage([]byte(token))
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		ap   www.AbandonProposal
+		want error
+	}{
+		{"invalid signing key",
+			www.AbandonProposal{
+				Token:     token,
+				Message:   msg,
+				Signature: hex.EncodeToString(sig[:]),
+				PublicKey: "",
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidSigningKey,
+			}},
+		{"invalid signature",
+			www.AbandonProposal{
+				Token:     token,
+				Message:   "different message",
+				Signature: hex.EncodeToString(sig[:]),
+				PublicKey: id.Public.String(),
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidSignature,
+			}},
+		{"blank message",
+			www.AbandonProposal{
+				Token:     token,
+				Message:   "",
+				Signature: hex.EncodeToString(blankSig[:]),
+				PublicKey: id.Public.String(),
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusChangeMessageCannotBeBlank,
+			}},
+		{"success",
+			www.AbandonProposal{
+				Token:     token,
+				Message:   msg,
+				Signature: hex.EncodeToString(sig[:]),
+				PublicKey: id.Public.String(),
+			}, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.ProcessAbandonProposal(v.ap, usr)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+}
+
+func TestProcessBatchSetProposalStatus(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, id := newUser(t, p, true)
+	statusChange := func(token string, status www.PropStatusT, msg string) www.SetProposalStatus {
+		s := strconv.FormatUint(uint64(status), 10)
+		sig := id.SignMessage([]byte(token + s + msg))
+		return www.SetProposalStatus{
+			Token:               token,
+			ProposalStatus:      status,
+			StatusChangeMessage: msg,
+			Signature:           hex.EncodeToString(sig[:]),
+			PublicKey:           id.Public.String(),
+		}
+	}
+	token1 := "a5ed7ab14bb59ec8b48b5d25b8f1e5fc8fd0bb4ba3c81d22dfa2c25b2ab4e8c1"
+	token2 := "b5ed7ab14bb59ec8b48b5d25b8f1e5fc8fd0bb4ba3c81d22dfa2c25b2ab4e8c1"
+	tooMany := make([]www.SetProposalStatus, www.ProposalStatusBatchSize+1)
+
+	// Setup tests
+	var tests = []struct {
+		name    string
+		changes []www.SetProposalStatus
+		want    error
+	}{
+		{"empty batch", nil,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			}},
+		{"batch too large", tooMany,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			}},
+		{"duplicate token",
+			[]www.SetProposalStatus{
+				statusChange(token1, www.PropStatusPublic, ""),
+				statusChange(token1, www.PropStatusPublic, ""),
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			}},
+		{"blank censor message",
+			[]www.SetProposalStatus{
+				statusChange(token1, www.PropStatusPublic, ""),
+				statusChange(token2, www.PropStatusCensored, ""),
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusChangeMessageCannotBeBlank,
+			}},
+		{"success",
+			[]www.SetProposalStatus{
+				statusChange(token1, www.PropStatusPublic, ""),
+				statusChange(token2, www.PropStatusCensored, "spam"),
+			}, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			reply, err := p.ProcessBatchSetProposalStatus(
+				www.BatchSetProposalStatus{
+					StatusChanges: v.changes,
+				}, admin)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Fatalf("got error %v, want %v",
+					got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(reply.Results) != len(v.changes) {
+				t.Fatalf("got %v results, want %v",
+					len(reply.Results), len(v.changes))
+			}
+			for i, r := range reply.Results {
+				if r.Token != v.changes[i].Token {
+					t.Errorf("result %v: got token %v, want %v",
+						i, r.Token, v.changes[i].Token)
+				}
+				if r.Proposal == nil || r.Error != "" {
+					t.Errorf("result %v: got error %v, want success",
+						i, r.Error)
+					continue
+				}
+				if r.Proposal.Status != v.changes[i].ProposalStatus {
+					t.Errorf("result %v: got status %v, want %v", i,
+						r.Proposal.Status, v.changes[i].ProposalStatus)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+// rateLimiter limits the number of requests that a client can make during a
+// fixed time window.  All clients share the same window so that the request
+// counts can be discarded in one go when the window ends.
+type rateLimiter struct {
+	sync.Mutex
+	limit    int              // Requests allowed per client per window
+	interval time.Duration    // Length of a window
+	start    time.Time        // Start of the current window
+	counts   map[string]int   // [client]requests in the current window
+	now      func() time.Time // Current time; replaced in tests
+}
+
+// newRateLimiter returns a rate limiter that allows limit requests per client
+// during each interval.
+func newRateLimiter(limit int, interval time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:    limit,
+		interval: interval,
+		counts:   make(map[string]int),
+		now:      time.Now,
+	}
+}
+
+// allow records a request from the passed in client and returns whether the
+// request is allowed.  When it is not, the amount of time until the client is
+// allowed to make requests again is also returned.
+func (rl *rateLimiter) allow(client string) (bool, time.Duration) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	if now.Sub(rl.start) >= rl.interval {
+		rl.start = now
+		rl.counts = make(map[string]int)
+	}
+
+	if rl.counts[client] >= rl.limit {
+		return false, rl.start.Add(rl.interval).Sub(now)
+	}
+	rl.counts[client]++
+
+	return true, 0
+}
+
+// parseTrustedProxies parses the passed in trusted proxies.  A proxy is
+// either an IP address or a network in CIDR notation.
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, v := range proxies {
+		if strings.Contains(v, "/") {
+			_, n, err := net.ParseCIDR(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %v: %v",
+					v, err)
+			}
+			nets = append(nets, n)
+			continue
+		}
+
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid trusted proxy %v", v)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		nets = append(nets, &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(bits, bits),
+		})
+	}
+	return nets, nil
+}
+
+// isTrustedProxy returns whether the passed in address belongs to one of the
+// trusted proxies.
+func isTrustedProxy(addr string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, v := range trusted {
+		if v.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitClient returns the key that identifies the client of a request for
+// rate limiting.  The X-Forwarded-For header is only honored when the request
+// was made by one of the trusted proxies since anyone else can set it to an
+// arbitrary value.  The header is walked from the end, skipping the addresses
+// of trusted proxies, and the first untrusted address is used.  Addresses
+// earlier in the header were added by the client itself and can be forged.
+func rateLimitClient(r *http.Request, trusted []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, trusted) {
+		return host
+	}
+
+	xff := r.Header.Get(v1.Forward)
+	if xff == "" {
+		return host
+	}
+	addrs := strings.Split(xff, ",")
+	for i := len(addrs) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(addrs[i])
+		if !isTrustedProxy(addr, trusted) || i == 0 {
+			return addr
+		}
+	}
+	return host
+}
+
+// rateLimit rejects requests from clients that have exceeded the request
+// limit of the passed in rate limiter before calling the next function.
+// Rejected requests receive a 429 Too Many Requests reply with a Retry-After
+// header.  trusted contains the reverse proxies whose X-Forwarded-For header is
+// honored when identifying the client.
+func rateLimit(f http.HandlerFunc, rl *rateLimiter, trusted []*net.IPNet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := rateLimitClient(r, trusted)
+		ok, wait := rl.allow(client)
+		if !ok {
+			requestLog(r).Debugf("rateLimit: %v %v: rate limit "+
+				"exceeded by %v",
+				r.Method, r.URL, client)
+			secs := int64(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+			util.RespondWithJSON(w, http.StatusTooManyRequests,
+				v1.ErrorReply{
+					ErrorCode: int64(v1.ErrorStatusRateLimitExceeded),
+				})
+			return
+		}
+
+		f(w, r)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/decred/politeia/decredplugin"
+	pd "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiad/cache"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+// validCommentReaction matches the names that can be configured as comment
+// reaction types.
+var validCommentReaction = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// validateCommentReactions verifies the configured comment reaction types.
+// The up and down reactions are reserved for the comment likes so they can
+// not be configured.
+func validateCommentReactions(reactions []string) error {
+	seen := make(map[string]struct{}, len(reactions))
+	for _, v := range reactions {
+		if !validCommentReaction.MatchString(v) {
+			return fmt.Errorf("invalid comment reaction '%v'", v)
+		}
+		if v == www.ReactionUp || v == www.ReactionDown {
+			return fmt.Errorf("comment reaction '%v' is reserved", v)
+		}
+		if _, ok := seen[v]; ok {
+			return fmt.Errorf("duplicate comment reaction '%v'", v)
+		}
+		seen[v] = struct{}{}
+	}
+	return nil
+}
+
+// commentReactionAllowed returns whether the passed in reaction is one of the
+// configured comment reaction types.
+func (p *politeiawww) commentReactionAllowed(reaction string) bool {
+	for _, v := range p.cfg.CommentReactions {
+		if v == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// replayCommentReactions replays the passed in comment reactions in order and
+// returns the reactions that each user currently has on the comment.  Adding
+// a reaction that a user already has or removing a reaction that a user does
+// not have has no effect.
+func replayCommentReactions(reactions []decredplugin.ReactComment, userPubkeys map[string]string) (map[string]map[string]struct{}, error) {
+	users := make(map[string]map[string]struct{}) // [userID][reaction]
+	for _, v := range reactions {
+		// Lookup the userID of the reaction author
+		userID, ok := userPubkeys[v.PublicKey]
+		if !ok {
+			return nil, fmt.Errorf("userID lookup failed for pubkey %v",
+				v.PublicKey)
+		}
+
+		switch v.Action {
+		case decredplugin.ReactionActionAdd:
+			if _, ok := users[userID]; !ok {
+				users[userID] = make(map[string]struct{})
+			}
+			users[userID][v.Reaction] = struct{}{}
+		case decredplugin.ReactionActionRemove:
+			delete(users[userID], v.Reaction)
+		default:
+			return nil, fmt.Errorf("invalid action '%v' on commentID %v",
+				v.Action, v.CommentID)
+		}
+	}
+	return users, nil
+}
+
+// countCommentReactions returns the number of users that have each reaction
+// type.  Reaction types that no user has are not included.
+func countCommentReactions(users map[string]map[string]struct{}) map[string]uint64 {
+	counts := make(map[string]uint64)
+	for _, reactions := range users {
+		for r := range reactions {
+			counts[r]++
+		}
+	}
+	return counts
+}
+
+// fetchCommentReactions returns the reactions to the specified comment from
+// the cache in chronological order.
+func (p *politeiawww) fetchCommentReactions(token, commentID string) ([]decredplugin.ReactComment, error) {
+	reactions, err := p.decredCommentReactions(token, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("decredCommentReactions: %v", err)
+	}
+
+	// Sanity check. Comment reactions should already be sorted in
+	// chronological order.
+	sort.SliceStable(reactions, func(i, j int) bool {
+		return reactions[i].Timestamp < reactions[j].Timestamp
+	})
+
+	return reactions, nil
+}
+
+// updateCommentReactions calculates the reaction counts for the specified
+// comment then updates the in-memory comment reactions cache.  The counts
+// are replaced, never modified, so they can be handed out without copying.
+func (p *politeiawww) updateCommentReactions(token, commentID string) (map[string]uint64, error) {
+	log.Tracef("updateCommentReactions: %v %v", token, commentID)
+
+	reactions, err := p.fetchCommentReactions(token, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	users, err := replayCommentReactions(reactions, p.userPubkeys)
+	if err != nil {
+		return nil, err
+	}
+	counts := countCommentReactions(users)
+	if len(counts) == 0 {
+		delete(p.commentReactions, token+commentID)
+	} else {
+		p.commentReactions[token+commentID] = counts
+	}
+
+	return counts, nil
+}
+
+// ProcessReactComment adds or removes a reaction of a user to a comment.  A
+// user can not add a reaction that they already have or remove a reaction
+// that they do not have.
+func (p *politeiawww) ProcessReactComment(rc www.ReactComment, u *user.User) (*www.ReactCommentReply, error) {
+	log.Debugf("ProcessReactComment: %v %v %v %v", rc.Token, rc.CommentID,
+		rc.Reaction, u.ID)
+
+	// Pay up sucker!
+	if !p.HasUserPaid(u) {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserNotPaid,
+		}
+	}
+
+	// Verify authenticity
+	err := checkPublicKeyAndSignature(u, rc.PublicKey, rc.Signature,
+		rc.Token, rc.CommentID, rc.Reaction, rc.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate reaction and action
+	if !p.commentReactionAllowed(rc.Reaction) ||
+		(rc.Action != www.ReactionActionAdd &&
+			rc.Action != www.ReactionActionRemove) {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidCommentReaction,
+		}
+	}
+
+	// Ensure proposal exists and is public
+	pr, err := p.getProp(rc.Token)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusProposalNotFound,
+			}
+		}
+		return nil, err
+	}
+
+	if pr.Status != www.PropStatusPublic {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongStatus,
+		}
+	}
+
+	// Ensure proposal voting has not ended
+	vdr, err := p.decredVoteDetails(rc.Token)
+	if err != nil {
+		return nil, fmt.Errorf("decredVoteDetails: %v", err)
+	}
+	vd := convertVoteDetailsReplyFromDecred(*vdr)
+
+	bb, err := p.getBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("getBestBlock: %v", err)
+	}
+
+	s := getVoteStatus(vd.AuthorizeVoteReply, vd.StartVoteReply, bb)
+	if s == www.PropVoteStatusFinished {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWrongVoteStatus,
+		}
+	}
+
+	// Ensure comment exists
+	_, err = p.decredGetComment(rc.Token, rc.CommentID)
+	if err != nil {
+		if err == cache.ErrRecordNotFound {
+			err = www.UserError{
+				ErrorCode: www.ErrorStatusCommentNotFound,
+			}
+		}
+		return nil, err
+	}
+
+	// Ensure the reaction changes the reactions of the user
+	reactions, err := p.fetchCommentReactions(rc.Token, rc.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	p.RLock()
+	users, err := replayCommentReactions(reactions, p.userPubkeys)
+	p.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	_, ok := users[u.ID.String()][rc.Reaction]
+	if ok == (rc.Action == www.ReactionActionAdd) {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidCommentReaction,
+		}
+	}
+
+	// Setup plugin command
+	challenge, err := util.Random(pd.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	drc := convertReactCommentToDecred(rc)
+	payload, err := decredplugin.EncodeReactComment(drc)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := pd.PluginCommand{
+		Challenge: hex.EncodeToString(challenge),
+		ID:        decredplugin.ID,
+		Command:   decredplugin.CmdReactComment,
+		CommandID: decredplugin.CmdReactComment,
+		Payload:   string(payload),
+	}
+
+	// Send plugin command to politeiad
+	responseBody, err := p.makeRequest(http.MethodPost,
+		pd.PluginCommandRoute, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle response
+	var reply pd.PluginCommandReply
+	err = json.Unmarshal(responseBody, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal "+
+			"PluginCommandReply: %v", err)
+	}
+
+	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	rcr, err := decredplugin.DecodeReactCommentReply([]byte(reply.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	// Update comment reactions in the in-memory cache
+	counts, err := p.updateCommentReactions(rc.Token, rc.CommentID)
+	if err != nil {
+		log.Criticalf("ProcessReactComment: update comment reactions "+
+			"failed token:%v commentID:%v error:%v", rc.Token,
+			rc.CommentID, err)
+	}
+
+	return &www.ReactCommentReply{
+		Reactions: counts,
+		Receipt:   rcr.Receipt,
+	}, nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/decred/politeia/decredplugin"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestValidateCommentReactions(t *testing.T) {
+	var tests = []struct {
+		name      string
+		reactions []string
+		wantErr   bool
+	}{
+		{"no reactions", nil, false},
+		{"valid reactions", []string{"heart", "thumbs_up", "party-1"}, false},
+		{"upper case", []string{"Heart"}, true},
+		{"empty", []string{""}, true},
+		{"reserved up", []string{"up"}, true},
+		{"reserved down", []string{"down"}, true},
+		{"duplicate", []string{"heart", "heart"}, true},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := validateCommentReactions(v.reactions)
+			if (err != nil) != v.wantErr {
+				t.Errorf("got error %v, want error %v", err, v.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplayCommentReactions(t *testing.T) {
+	userPubkeys := map[string]string{
+		"pubkey1": "user1",
+		"pubkey2": "user2",
+	}
+	react := func(pubkey, reaction, action string) decredplugin.ReactComment {
+		return decredplugin.ReactComment{
+			CommentID: "1",
+			Reaction:  reaction,
+			Action:    action,
+			PublicKey: pubkey,
+		}
+	}
+	add := decredplugin.ReactionActionAdd
+	remove := decredplugin.ReactionActionRemove
+
+	// Setup tests
+	var tests = []struct {
+		name      string
+		reactions []decredplugin.ReactComment
+		want      map[string]uint64
+		wantErr   bool
+	}{
+		{"no reactions", nil, map[string]uint64{}, false},
+		{"reactions of different users",
+			[]decredplugin.ReactComment{
+				react("pubkey1", "heart", add),
+				react("pubkey2", "heart", add),
+				react("pubkey2", "laugh", add),
+			},
+			map[string]uint64{"heart": 2, "laugh": 1}, false},
+		{"removed reaction",
+			[]decredplugin.ReactComment{
+				react("pubkey1", "heart", add),
+				react("pubkey2", "heart", add),
+				react("pubkey1", "heart", remove),
+			},
+			map[string]uint64{"heart": 1}, false},
+		{"repeated add counts once",
+			[]decredplugin.ReactComment{
+				react("pubkey1", "heart", add),
+				react("pubkey1", "heart", add),
+			},
+			map[string]uint64{"heart": 1}, false},
+		{"remove without add",
+			[]decredplugin.ReactComment{
+				react("pubkey1", "heart", remove),
+			},
+			map[string]uint64{}, false},
+		{"unknown pubkey",
+			[]decredplugin.ReactComment{
+				react("pubkey3", "heart", add),
+			},
+			nil, true},
+		{"invalid action",
+			[]decredplugin.ReactComment{
+				react("pubkey1", "heart", "toggle"),
+			},
+			nil, true},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			users, err := replayCommentReactions(v.reactions, userPubkeys)
+			if (err != nil) != v.wantErr {
+				t.Fatalf("got error %v, want error %v", err, v.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := countCommentReactions(users)
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestProcessReactComment(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	p.cfg.CommentReactions = []string{"heart"}
+
+	u, id := newUser(t, p, false)
+
+	token := "abf0fd1fc1b8c1c9535685373dce6c54948b7eb018e17e3a8cea26a3c9b85684"
+	pubkey := hex.EncodeToString(id.Public.Key[:])
+	sign := func(msg string) string {
+		sig := id.SignMessage([]byte(msg))
+		return hex.EncodeToString(sig[:])
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		rc   www.ReactComment
+		want error
+	}{
+		{"signature of other reaction",
+			www.ReactComment{
+				Token:     token,
+				CommentID: "1",
+				Reaction:  "heart",
+				Action:    www.ReactionActionAdd,
+				Signature: sign(token + "1" + "laugh" +
+					www.ReactionActionAdd),
+				PublicKey: pubkey,
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidSignature,
+			}},
+		{"reaction not configured",
+			www.ReactComment{
+				Token:     token,
+				CommentID: "1",
+				Reaction:  "laugh",
+				Action:    www.ReactionActionAdd,
+				Signature: sign(token + "1" + "laugh" +
+					www.ReactionActionAdd),
+				PublicKey: pubkey,
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidCommentReaction,
+			}},
+		{"reserved reaction",
+			www.ReactComment{
+				Token:     token,
+				CommentID: "1",
+				Reaction:  www.ReactionUp,
+				Action:    www.ReactionActionAdd,
+				Signature: sign(token + "1" + www.ReactionUp +
+					www.ReactionActionAdd),
+				PublicKey: pubkey,
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidCommentReaction,
+			}},
+		{"invalid action",
+			www.ReactComment{
+				Token:     token,
+				CommentID: "1",
+				Reaction:  "heart",
+				Action:    "toggle",
+				Signature: sign(token + "1" + "heart" + "toggle"),
+				PublicKey: pubkey,
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidCommentReaction,
+			}},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.ProcessReactComment(v.rc, u)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+}
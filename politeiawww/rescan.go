@@ -0,0 +1,339 @@
+// Copyright (c) 2017-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// rescanJobIDSize is the size of a rescan job ID in bytes.
+	rescanJobIDSize = 16
+
+	// rescanExpiry is how long the status of a rescan is kept after the
+	// rescan has ended.
+	rescanExpiry = time.Hour
+)
+
+// fetchTxsFunc fetches the transactions that were sent to an address after
+// the notBefore timestamp.
+type fetchTxsFunc func(address string, notBefore int64) ([]util.TxDetails, error)
+
+// _pruneRescans removes the rescans that ended more than rescanExpiry ago.
+//
+// This function must be called WITH the rescans mutex held.
+func (p *politeiawww) _pruneRescans(now time.Time) {
+	for id, v := range p.rescans {
+		if v.Ended != 0 &&
+			now.Sub(time.Unix(v.Ended, 0)) > rescanExpiry {
+			delete(p.rescans, id)
+		}
+	}
+}
+
+// updateRescan applies the passed in update to the status of a rescan.
+func (p *politeiawww) updateRescan(jobID string, update func(*www.UserPaymentsRescanStatusReply)) {
+	p.rescansMtx.Lock()
+	defer p.rescansMtx.Unlock()
+
+	status, ok := p.rescans[jobID]
+	if !ok {
+		return
+	}
+	update(status)
+}
+
+// paywallAddresses returns the distinct paywall addresses of a user.
+func paywallAddresses(u *user.User) []string {
+	addresses := make([]string, 0, len(u.ProposalPaywalls)+1)
+	seen := make(map[string]struct{}, len(u.ProposalPaywalls)+1)
+	add := func(address string) {
+		if address == "" {
+			return
+		}
+		if _, ok := seen[address]; ok {
+			return
+		}
+		seen[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+	add(u.NewUserPaywallAddress)
+	for _, v := range u.ProposalPaywalls {
+		add(v.Address)
+	}
+	return addresses
+}
+
+// processUserPaymentsRescan allows an admin to rescan a user's paywall address
+// to check for any payments that may have been missed by paywall polling.  The
+// rescan runs in the background and its progress is reported by
+// processUserPaymentsRescanStatus.  Rescans are only kept in memory so they
+// are lost when politeiawww restarts.  Only one rescan of a user runs at a
+// time; the job ID of the running rescan is returned when the user is already
+// being rescanned.  The rescan fetches the payments immediately; it is not
+// subject to the paywall poll backoff.  The backoff of the user's paywall in
+// the paywall pool is reset as well so that the poller picks up pending
+// payments right away.
+func (p *politeiawww) processUserPaymentsRescan(upr www.UserPaymentsRescan) (*www.UserPaymentsRescanReply, error) {
+	// Ensure paywall is enabled
+	if !p.paywallIsEnabled() {
+		return &www.UserPaymentsRescanReply{}, nil
+	}
+
+	// Lookup user
+	u, err := p.getUserByIDStr(upr.UserID)
+	if err != nil {
+		return nil, err
+	}
+	p.resetPaywallPoll(u.ID)
+
+	jobID, started, err := p.startRescan(u)
+	if err != nil {
+		return nil, err
+	}
+	if started {
+		go p.rescanUserPayments(jobID, u, util.FetchTxsForAddressNotBefore)
+	}
+
+	return &www.UserPaymentsRescanReply{
+		JobID: jobID,
+	}, nil
+}
+
+// startRescan registers a new rescan of the passed in user and returns its
+// job ID.  The job ID of the running rescan is returned, with started unset,
+// when the user is already being rescanned.
+func (p *politeiawww) startRescan(u *user.User) (string, bool, error) {
+	p.rescansMtx.Lock()
+	defer p.rescansMtx.Unlock()
+
+	now := time.Now()
+	p._pruneRescans(now)
+
+	userID := u.ID.String()
+	for id, v := range p.rescans {
+		if v.UserID == userID && v.Status == www.RescanStatusRunning {
+			return id, false, nil
+		}
+	}
+
+	b, err := util.Random(rescanJobIDSize)
+	if err != nil {
+		return "", false, err
+	}
+	jobID := hex.EncodeToString(b)
+	p.rescans[jobID] = &www.UserPaymentsRescanStatusReply{
+		JobID:          jobID,
+		UserID:         userID,
+		Status:         www.RescanStatusRunning,
+		AddressesTotal: uint64(len(paywallAddresses(u))),
+		Started:        now.Unix(),
+	}
+
+	return jobID, true, nil
+}
+
+// rescanUserPayments fetches the payments that were sent to the paywall
+// addresses of a user, credits the payments that were missed by paywall
+// polling and records the progress in the status of the rescan.
+func (p *politeiawww) rescanUserPayments(jobID string, u *user.User, fetchTxs fetchTxsFunc) {
+	log.Tracef("rescanUserPayments: %v %v", jobID, u.ID)
+
+	// Fetch user payments.  A payment is only counted once when it was
+	// sent to more than one of the addresses.
+	payments := make([]util.TxDetails, 0)
+	seen := make(map[string]struct{})
+	for _, address := range paywallAddresses(u) {
+		txs, err := fetchTxs(address, u.NewUserPaywallTxNotBefore)
+		if err != nil {
+			p.endRescan(jobID, nil,
+				fmt.Errorf("FetchTxsForAddressNotBefore: %v", err))
+			return
+		}
+		for _, tx := range txs {
+			if _, ok := seen[tx.TxID]; ok {
+				continue
+			}
+			seen[tx.TxID] = struct{}{}
+			payments = append(payments, tx)
+		}
+
+		p.updateRescan(jobID, func(s *www.UserPaymentsRescanStatusReply) {
+			s.AddressesScanned++
+			s.PaymentsFound = uint64(len(payments))
+		})
+	}
+
+	credits, err := p.creditMissedPayments(u.Email, payments)
+	p.endRescan(jobID, credits, err)
+}
+
+// endRescan records the result of a rescan.
+func (p *politeiawww) endRescan(jobID string, credits []user.ProposalCredit, err error) {
+	if err != nil {
+		log.Errorf("rescanUserPayments %v: %v", jobID, err)
+	}
+
+	p.updateRescan(jobID, func(s *www.UserPaymentsRescanStatusReply) {
+		s.Ended = time.Now().Unix()
+		if err != nil {
+			s.Status = www.RescanStatusFailed
+			s.Error = err.Error()
+			return
+		}
+		s.Status = www.RescanStatusComplete
+		s.NewCredits = make([]www.ProposalCredit, 0, len(credits))
+		for _, v := range credits {
+			s.NewCredits = append(s.NewCredits,
+				convertWWWPropCreditFromDatabasePropCredit(v))
+		}
+	})
+}
+
+// creditMissedPayments creates proposal credits for the passed in payments
+// that were missed by paywall polling and adds them to the account of the
+// user.  Payments that credits already exist for are skipped so rescanning a
+// user's payments any number of times never credits a payment twice.  The
+// credits that were created are returned.
+func (p *politeiawww) creditMissedPayments(email string, payments []util.TxDetails) ([]user.ProposalCredit, error) {
+	// The user record is looked up right before it is updated so that
+	// credits that were created or spent while the payments were being
+	// fetched are taken into account.
+	u, err := p.db.UserGet(email)
+	if err != nil {
+		return nil, fmt.Errorf("UserGet %v", err)
+	}
+
+	// Paywalls are in chronological order so sort txs into chronological
+	// order to make them easier to work with
+	sort.SliceStable(payments, func(i, j int) bool {
+		return payments[i].Timestamp < payments[j].Timestamp
+	})
+
+	// Sanity check. Paywalls should already be in chronological order.
+	paywalls := u.ProposalPaywalls
+	sort.SliceStable(paywalls, func(i, j int) bool {
+		return paywalls[i].TxNotBefore < paywalls[j].TxNotBefore
+	})
+
+	// Payments that have already been credited
+	credited := make(map[string]struct{},
+		len(u.SpentProposalCredits)+len(u.UnspentProposalCredits))
+	for _, credit := range u.SpentProposalCredits {
+		credited[credit.TxID] = struct{}{}
+	}
+	for _, credit := range u.UnspentProposalCredits {
+		credited[credit.TxID] = struct{}{}
+	}
+
+	// Check for payments that were missed by paywall polling
+	newCredits := make([]user.ProposalCredit, 0, len(payments))
+	for _, payment := range payments {
+		// Check if the payment transaction corresponds to a user
+		// registration payment. A user registration payment may not
+		// exist if the registration paywall was cleared by an admin.
+		if payment.TxID == u.NewUserPaywallTx {
+			continue
+		}
+
+		// If credits are found for the payment it means that this
+		// payment was not missed by paywall polling and we can continue
+		// onto the next payment.
+		if _, ok := credited[payment.TxID]; ok {
+			continue
+		}
+
+		// Credits were not found for this payment which means that it
+		// was missed by paywall polling. Create new credits using the
+		// paywall details that correspond to the payment timestamp. If
+		// a paywall had not yet been issued, use the current proposal
+		// credit price.
+		var pp user.ProposalPaywall
+		for _, paywall := range paywalls {
+			if payment.Timestamp < paywall.TxNotBefore {
+				continue
+			}
+			if payment.Timestamp > paywall.TxNotBefore {
+				// Corresponding paywall found
+				pp = paywall
+				break
+			}
+		}
+
+		if pp == (user.ProposalPaywall{}) {
+			// Paywall not found. This means the tx occurred before
+			// any paywalls were issued. Use current credit price.
+			pp.CreditPrice = p.cfg.PaywallAmount
+		}
+
+		// Don't add credits if the paywall is in the paywall pool
+		if pp.TxID == "" && !paywallHasExpired(pp.PollExpiry) {
+			continue
+		}
+
+		// Ensure payment has minimum number of confirmations
+		if payment.Confirmations < p.cfg.MinConfirmationsRequired {
+			continue
+		}
+
+		// Create proposal credits
+		numCredits := payment.Amount / pp.CreditPrice
+		c := make([]user.ProposalCredit, numCredits)
+		for i := uint64(0); i < numCredits; i++ {
+			c[i] = user.ProposalCredit{
+				PaywallID:     pp.ID,
+				Price:         pp.CreditPrice,
+				DatePurchased: time.Now().Unix(),
+				TxID:          payment.TxID,
+			}
+		}
+		newCredits = append(newCredits, c...)
+		credited[payment.TxID] = struct{}{}
+	}
+	if len(newCredits) == 0 {
+		return newCredits, nil
+	}
+
+	// Update user record
+	u.UnspentProposalCredits = append(u.UnspentProposalCredits,
+		newCredits...)
+
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, fmt.Errorf("UserUpdate %v", err)
+	}
+
+	return newCredits, nil
+}
+
+// processUserPaymentsRescanStatus returns the progress of a user payments
+// rescan.
+func (p *politeiawww) processUserPaymentsRescanStatus(uprs www.UserPaymentsRescanStatus) (*www.UserPaymentsRescanStatusReply, error) {
+	log.Tracef("processUserPaymentsRescanStatus: %v", uprs.JobID)
+
+	p.rescansMtx.Lock()
+	defer p.rescansMtx.Unlock()
+
+	p._pruneRescans(time.Now())
+
+	status, ok := p.rescans[uprs.JobID]
+	if !ok {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusRescanNotFound,
+		}
+	}
+
+	// Return a copy.  The credits are only set once the rescan has
+	// ended and are not modified afterwards.
+	s := *status
+	return &s, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+func TestRescanUserPayments(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	u, _ := newUser(t, p, false)
+	u.NewUserPaywallAddress = "address"
+	u.NewUserPaywallTx = "registration"
+	err := p.db.UserUpdate(*u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fetchTxs returns the registration payment and a payment of two
+	// proposal credits that was missed by paywall polling.
+	fetchTxs := func(address string, notBefore int64) ([]util.TxDetails, error) {
+		return []util.TxDetails{
+			{
+				Address:   address,
+				TxID:      "registration",
+				Amount:    p.cfg.PaywallAmount,
+				Timestamp: 1,
+			},
+			{
+				Address:   address,
+				TxID:      "missed",
+				Amount:    2 * p.cfg.PaywallAmount,
+				Timestamp: 2,
+			},
+		}, nil
+	}
+	failTxs := func(address string, notBefore int64) ([]util.TxDetails, error) {
+		return nil, errors.New("block explorer unavailable")
+	}
+
+	// Setup tests.  The tests depend on each other; every rescan runs
+	// against the user record that the previous rescan updated.
+	var tests = []struct {
+		name        string
+		fetchTxs    fetchTxsFunc
+		wantStatus  www.RescanStatusT
+		wantCredits int
+	}{
+		{"missed payment", fetchTxs, www.RescanStatusComplete, 2},
+		{"rescan is idempotent", fetchTxs, www.RescanStatusComplete, 0},
+		{"fetch failure", failTxs, www.RescanStatusFailed, 0},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			jobID, started, err := p.startRescan(u)
+			if err != nil {
+				t.Fatalf("startRescan: %v", err)
+			}
+			if !started {
+				t.Fatalf("rescan was not started")
+			}
+
+			// A rescan of a user that is being rescanned returns
+			// the running rescan
+			id, started, err := p.startRescan(u)
+			if err != nil {
+				t.Fatalf("startRescan: %v", err)
+			}
+			if started || id != jobID {
+				t.Fatalf("got job %v started %v, want running job %v",
+					id, started, jobID)
+			}
+
+			p.rescanUserPayments(jobID, u, v.fetchTxs)
+
+			s, err := p.processUserPaymentsRescanStatus(
+				www.UserPaymentsRescanStatus{
+					JobID: jobID,
+				})
+			if err != nil {
+				t.Fatalf("processUserPaymentsRescanStatus: %v", err)
+			}
+			if s.Status != v.wantStatus {
+				t.Errorf("got status %v, want %v",
+					www.RescanStatus[s.Status],
+					www.RescanStatus[v.wantStatus])
+			}
+			if len(s.NewCredits) != v.wantCredits {
+				t.Errorf("got %v new credits, want %v",
+					len(s.NewCredits), v.wantCredits)
+			}
+			if s.Ended == 0 {
+				t.Errorf("rescan has not ended")
+			}
+		})
+	}
+
+	// The missed payment must only have been credited once
+	ur, err := p.db.UserGet(u.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ur.UnspentProposalCredits) != 2 {
+		t.Errorf("got %v unspent credits, want 2",
+			len(ur.UnspentProposalCredits))
+	}
+
+	// Unknown job
+	_, err = p.processUserPaymentsRescanStatus(www.UserPaymentsRescanStatus{
+		JobID: "00000000000000000000000000000000",
+	})
+	got := errToStr(err)
+	want := errToStr(www.UserError{
+		ErrorCode: www.ErrorStatusRescanNotFound,
+	})
+	if got != want {
+		t.Errorf("got error %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+const (
+	// sessionsDirname is the name of the directory, relative to the data
+	// directory, that sessions are stored in.
+	sessionsDirname = "sessions"
+
+	// sessionFilePrefix is the prefix that the gorilla FilesystemStore
+	// uses for the session files that it writes to disk.
+	sessionFilePrefix = "session_"
+)
+
+// sessionExpired returns whether the session has expired.  Sessions that do
+// not have an expiration are considered expired.
+func sessionExpired(session *sessions.Session) bool {
+	expiresAt, ok := session.Values["expiresat"].(int64)
+	if !ok {
+		return true
+	}
+	return time.Now().UnixNano() >= expiresAt
+}
+
+// setSessionExpiry sets the session to expire maxAge seconds from now, but no
+// later than absMaxAge seconds after the session was created.  It returns
+// false if the session has already reached its absolute max age.
+func setSessionExpiry(session *sessions.Session, now time.Time, maxAge, absMaxAge int64) bool {
+	createdAt, ok := session.Values["createdat"].(int64)
+	if !ok {
+		return false
+	}
+
+	expiresAt := now.Add(time.Duration(maxAge) * time.Second)
+	absExpiresAt := time.Unix(0, createdAt).Add(time.Duration(absMaxAge) *
+		time.Second)
+	if expiresAt.After(absExpiresAt) {
+		expiresAt = absExpiresAt
+	}
+	if !expiresAt.After(now) {
+		return false
+	}
+
+	// The cookie max age is rounded up so that the cookie does not
+	// expire before the session does.
+	session.Values["expiresat"] = expiresAt.UnixNano()
+	session.Options.MaxAge = int((expiresAt.Sub(now) + time.Second - 1) /
+		time.Second)
+
+	return true
+}
+
+// sessionImpersonator returns the user ID of the admin that started the
+// passed in session if it is an impersonation session.
+func sessionImpersonator(session *sessions.Session) (string, bool) {
+	id, ok := session.Values["impersonatorid"].(string)
+	return id, ok
+}
+
+// isImpersonatedSession returns whether the session of the passed in request
+// is an impersonation session.
+func (p *politeiawww) isImpersonatedSession(r *http.Request) bool {
+	session, err := p.getSession(r)
+	if err != nil {
+		return false
+	}
+	_, ok := sessionImpersonator(session)
+	return ok
+}
+
+// renewSession implements sliding expiration by extending the expiration of
+// the current session by the session max age, up to the absolute session max
+// age.  Impersonation sessions are never kept alive for longer than the
+// impersonation max age.  ErrSessionExpired is returned if the session has
+// reached its absolute max age.
+func (p *politeiawww) renewSession(w http.ResponseWriter, r *http.Request) error {
+	session, err := p.getSession(r)
+	if err != nil {
+		return err
+	}
+
+	maxAge := p.cfg.SessionMaxAge
+	absMaxAge := p.cfg.SessionAbsoluteMaxAge
+	if _, ok := sessionImpersonator(session); ok {
+		absMaxAge = p.cfg.ImpersonationMaxAge
+		if maxAge > absMaxAge {
+			maxAge = absMaxAge
+		}
+	}
+	ok := setSessionExpiry(session, time.Now(), maxAge, absMaxAge)
+	if !ok {
+		p.removeSession(w, r)
+		return ErrSessionExpired
+	}
+
+	return session.Save(r, w)
+}
+
+// startImpersonationSession replaces the session of the admin with a new
+// session for the passed in user that is flagged as impersonated by the admin.
+// The session of the admin is deleted so that the admin must log in again once
+// the impersonation ends.  The impersonation session is not extended beyond
+// the impersonation max age.  The expiration time of the session is returned.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) startImpersonationSession(w http.ResponseWriter, r *http.Request, adminID, userID string) (time.Time, error) {
+	session, err := p.getSession(r)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Delete the session of the admin
+	if session.ID != "" {
+		fp := filepath.Join(p.sessionsDir(), sessionFilePrefix+session.ID)
+		err := os.Remove(fp)
+		if err != nil && !os.IsNotExist(err) {
+			return time.Time{}, err
+		}
+		p.removeUserSession(adminID, session.ID)
+	}
+
+	// An empty session ID makes the store generate a new one so that
+	// the impersonation session can not be confused with the session of
+	// the admin.
+	now := time.Now()
+	session.ID = ""
+	session.Values = map[interface{}]interface{}{
+		"uuid":           userID,
+		"impersonatorid": adminID,
+		"createdat":      now.UnixNano(),
+	}
+	maxAge := p.cfg.ImpersonationMaxAge
+	if p.cfg.SessionMaxAge < maxAge {
+		maxAge = p.cfg.SessionMaxAge
+	}
+	setSessionExpiry(session, now, maxAge, p.cfg.ImpersonationMaxAge)
+	err = session.Save(r, w)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	p.setUserSession(userID, session.ID)
+	expiresAt, _ := session.Values["expiresat"].(int64)
+	return time.Unix(0, expiresAt), nil
+}
+
+// sessionsDir returns the path of the directory that the session store writes
+// the sessions to.
+func (p *politeiawww) sessionsDir() string {
+	return filepath.Join(p.cfg.DataDir, sessionsDirname)
+}
+
+// addUserSession adds a session id to the userSessions cache.
+//
+// This function must be called WITH the lock held.
+func (p *politeiawww) addUserSession(userID, sessionID string) {
+	s, ok := p.userSessions[userID]
+	if !ok {
+		s = make(map[string]struct{})
+		p.userSessions[userID] = s
+	}
+	s[sessionID] = struct{}{}
+}
+
+// setUserSession associates a session id with a user id in the userSessions
+// cache.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) setUserSession(userID, sessionID string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.addUserSession(userID, sessionID)
+}
+
+// removeUserSession removes a session id from the userSessions cache.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) removeUserSession(userID, sessionID string) {
+	p.Lock()
+	defer p.Unlock()
+
+	s, ok := p.userSessions[userID]
+	if !ok {
+		return
+	}
+	delete(s, sessionID)
+	if len(s) == 0 {
+		delete(p.userSessions, userID)
+	}
+}
+
+// removeUserSessions deletes all of the sessions that belong to the provided
+// user id from the session store.  The user is logged out of all sessions
+// immediately.  The number of sessions that were removed is returned.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) removeUserSessions(userID string) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	var removed int
+	s := p.userSessions[userID]
+	for sessionID := range s {
+		fp := filepath.Join(p.sessionsDir(), sessionFilePrefix+sessionID)
+		err := os.Remove(fp)
+		if err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		delete(s, sessionID)
+		removed++
+	}
+	delete(p.userSessions, userID)
+
+	return removed, nil
+}
+
+// initUserSessions initializes the userSessions cache with all of the
+// sessions that are found in the session store.  Sessions that can no longer
+// be decoded, e.g. expired sessions, and sessions that are not associated with
+// a user are skipped.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) initUserSessions() error {
+	p.Lock()
+	defer p.Unlock()
+
+	files, err := ioutil.ReadDir(p.sessionsDir())
+	if err != nil {
+		return err
+	}
+
+	for _, v := range files {
+		if v.IsDir() || !strings.HasPrefix(v.Name(), sessionFilePrefix) {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(p.sessionsDir(), v.Name()))
+		if err != nil {
+			return err
+		}
+
+		values := make(map[interface{}]interface{})
+		err = securecookie.DecodeMulti(v1.CookieSession, string(b),
+			&values, p.store.Codecs...)
+		if err != nil {
+			log.Debugf("initUserSessions: skipping %v: %v", v.Name(), err)
+			continue
+		}
+
+		userID, ok := values["uuid"].(string)
+		if !ok {
+			continue
+		}
+		sessionID := strings.TrimPrefix(v.Name(), sessionFilePrefix)
+		p.addUserSession(userID, sessionID)
+	}
+
+	return nil
+}
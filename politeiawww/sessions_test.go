@@ -0,0 +1,253 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// newSessionCookies creates a logged in session for the provided user id and
+// returns the session cookies.
+func newSessionCookies(t *testing.T, p *politeiawww, userID string) []*http.Cookie {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, v1.RouteLogin, nil)
+	w := httptest.NewRecorder()
+	err := p.setSessionUserID(w, r, userID)
+	if err != nil {
+		t.Fatalf("setSessionUserID: %v", err)
+	}
+
+	return w.Result().Cookies()
+}
+
+// sessionUserRequest makes a request using the provided session cookies and
+// returns the error from getSessionUser.  If the session was renewed, the
+// renewed session cookies are returned.
+func sessionUserRequest(p *politeiawww, cookies []*http.Cookie) ([]*http.Cookie, error) {
+	r := httptest.NewRequest(http.MethodGet, v1.RouteUserMe, nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+
+	_, err := p.getSessionUser(w, r)
+	if c := w.Result().Cookies(); len(c) > 0 {
+		cookies = c
+	}
+
+	return cookies, err
+}
+
+func TestSessionSlidingExpiration(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+	userID := usr.ID.String()
+
+	p.cfg.SessionMaxAge = 1
+	p.cfg.SessionAbsoluteMaxAge = 60
+
+	t.Run("active session does not expire", func(t *testing.T) {
+		cookies := newSessionCookies(t, p, userID)
+
+		// Keep the session active for longer than the max age
+		var err error
+		for i := 0; i < 4; i++ {
+			time.Sleep(400 * time.Millisecond)
+			cookies, err = sessionUserRequest(p, cookies)
+			if err != nil {
+				t.Fatalf("request %v: got error %v, want nil", i, err)
+			}
+		}
+	})
+
+	t.Run("idle session expires", func(t *testing.T) {
+		cookies := newSessionCookies(t, p, userID)
+
+		time.Sleep(1100 * time.Millisecond)
+		_, err := sessionUserRequest(p, cookies)
+		if err != ErrSessionExpired {
+			t.Fatalf("got error %v, want %v", err, ErrSessionExpired)
+		}
+
+		// The expired session is deleted from the store
+		_, err = sessionUserRequest(p, cookies)
+		if err != ErrSessionExpired {
+			t.Fatalf("got error %v, want %v", err, ErrSessionExpired)
+		}
+	})
+}
+
+func TestSessionAbsoluteExpiration(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+
+	p.cfg.SessionMaxAge = 1
+	p.cfg.SessionAbsoluteMaxAge = 1
+
+	cookies := newSessionCookies(t, p, usr.ID.String())
+
+	// Activity must not extend the session past the absolute max age
+	var err error
+	for i := 0; i < 4; i++ {
+		time.Sleep(400 * time.Millisecond)
+		cookies, err = sessionUserRequest(p, cookies)
+		if err != nil {
+			break
+		}
+	}
+	if err != ErrSessionExpired {
+		t.Fatalf("got error %v, want %v", err, ErrSessionExpired)
+	}
+}
+
+func TestImpersonationSession(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+	usr, _ := newUser(t, p, false)
+
+	p.cfg.ImpersonationMaxAge = 1
+
+	// Replace the session of the admin with an impersonation session
+	adminCookies := newSessionCookies(t, p, admin.ID.String())
+	r := httptest.NewRequest(http.MethodPost, v1.RouteImpersonateUser, nil)
+	for _, c := range adminCookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	expiresAt, err := p.startImpersonationSession(w, r,
+		admin.ID.String(), usr.ID.String())
+	if err != nil {
+		t.Fatalf("startImpersonationSession: %v", err)
+	}
+	if d := time.Until(expiresAt); d > time.Second {
+		t.Fatalf("session expires in %v, want at most 1s", d)
+	}
+	cookies := w.Result().Cookies()
+
+	// The admin session no longer exists
+	_, err = sessionUserRequest(p, adminCookies)
+	if err == nil {
+		t.Fatalf("admin session: got error nil, want error")
+	}
+
+	// Impersonation sessions are read-only
+	handler := p.isLoggedIn(func(w http.ResponseWriter, r *http.Request) {
+		if u := getContextUser(r); u.ID != usr.ID {
+			t.Errorf("got user %v, want %v", u.ID, usr.ID)
+		}
+	})
+	for _, v := range []struct {
+		method string
+		want   int
+	}{
+		{http.MethodGet, http.StatusOK},
+		{http.MethodPost, http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(v.method, v1.RouteUserMe, nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != v.want {
+			t.Errorf("%v: got status %v, want %v", v.method, w.Code,
+				v.want)
+		}
+	}
+
+	// Activity must not extend the session past the impersonation max
+	// age
+	for i := 0; i < 4; i++ {
+		time.Sleep(400 * time.Millisecond)
+		cookies, err = sessionUserRequest(p, cookies)
+		if err != nil {
+			break
+		}
+	}
+	if err != ErrSessionExpired {
+		t.Fatalf("got error %v, want %v", err, ErrSessionExpired)
+	}
+}
+
+func TestImpersonationSessionPublicRoutes(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+	usr, _ := newUser(t, p, false)
+
+	r := httptest.NewRequest(http.MethodPost, v1.RouteImpersonateUser, nil)
+	for _, c := range newSessionCookies(t, p, admin.ID.String()) {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	_, err := p.startImpersonationSession(w, r,
+		admin.ID.String(), usr.ID.String())
+	if err != nil {
+		t.Fatalf("startImpersonationSession: %v", err)
+	}
+	cookies := w.Result().Cookies()
+
+	serve := func(route string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest(http.MethodPost,
+			v1.PoliteiaWWWAPIRoute+route, bytes.NewReader(b))
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		p.router.ServeHTTP(w, r)
+		return w
+	}
+
+	// Public routes that are not GET requests do not act as the
+	// impersonated user, so the private fields of the user are not
+	// returned.
+	w = serve(v1.RouteBatchUserDetails, v1.BatchUserDetails{
+		UserIDs: []string{usr.ID.String()},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("batch user details: got status %v, want %v: %s",
+			w.Code, http.StatusOK, w.Body.Bytes())
+	}
+	var budr v1.BatchUserDetailsReply
+	err = json.Unmarshal(w.Body.Bytes(), &budr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, ok := budr.Users[usr.ID.String()]; !ok || u.Email != "" {
+		t.Fatalf("batch user details: got user %+v, want public "+
+			"fields only", u)
+	}
+
+	// Logging out ends the impersonation session
+	w = serve(v1.RouteLogout, v1.Logout{})
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout: got status %v, want %v: %s", w.Code,
+			http.StatusOK, w.Body.Bytes())
+	}
+	_, err = sessionUserRequest(p, cookies)
+	if err == nil {
+		t.Fatalf("got nil error after logout, want session error")
+	}
+}
@@ -15,6 +15,10 @@ type smtp struct {
 	mailName    string        // Email address name
 	mailAddress string        // Email address
 	disabled    bool          // Has email been disabled
+
+	// sentTo is called in place of sending the emails that are sent to a
+	// single address.  It is only set by tests.
+	sentTo func(subject, body, toAddress string)
 }
 
 // sendEmail sends an email with the given subject and body, and the caller
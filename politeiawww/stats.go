@@ -0,0 +1,269 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// statsEventT represents a type of event that is counted by the stats
+// history.
+type statsEventT int
+
+const (
+	statsEventSubmitted statsEventT = iota // Proposal was submitted
+	statsEventCommented                    // Comment was submitted
+	statsEventApproved                     // Proposal vote passed
+	statsEventRejected                     // Proposal vote failed
+)
+
+// statsCacheTTL is the amount of time that the stats events are cached for.
+// Building the stats events requires a scan of all proposals, their comments
+// and their votes.
+const statsCacheTTL = 5 * time.Minute
+
+// statsEvent is a timestamped event that is counted in the stats bucket that
+// the timestamp falls into.
+type statsEvent struct {
+	Type      statsEventT
+	Timestamp int64  // UNIX timestamp of the event
+	UserID    string // User that caused the event; empty for votes
+}
+
+// statsCache caches the stats events so that the stats history route does not
+// scan all proposals on every request.  The zero value is an empty cache.
+type statsCache struct {
+	sync.Mutex
+	events    []statsEvent
+	updatedAt time.Time
+}
+
+// get returns the cached stats events when they were built less than
+// statsCacheTTL before now.  Otherwise the events are rebuilt using build.
+// The cache is locked while the events are built so that concurrent requests
+// result in a single scan.  The events are replaced, never modified, so they
+// can be handed out without copying.
+func (c *statsCache) get(now time.Time, build func() ([]statsEvent, error)) ([]statsEvent, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.updatedAt.IsZero() && now.Sub(c.updatedAt) < statsCacheTTL {
+		return c.events, nil
+	}
+
+	events, err := build()
+	if err != nil {
+		return nil, err
+	}
+	c.events = events
+	c.updatedAt = now
+
+	return events, nil
+}
+
+// validateStatsHistory returns an error if the passed in stats history range
+// is not valid.
+func validateStatsHistory(sh www.StatsHistory) error {
+	switch {
+	case sh.Interval <= 0:
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"interval must be positive"},
+		}
+	case sh.From < 0:
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"from cannot be negative"},
+		}
+	case sh.From >= sh.To:
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"from must be before to"},
+		}
+	case (sh.To-sh.From-1)/sh.Interval >= www.StatsHistoryMaxBuckets:
+		return www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("range exceeds %v "+
+				"intervals", www.StatsHistoryMaxBuckets)},
+		}
+	}
+	return nil
+}
+
+// statsHistory buckets the passed in events by the interval of the stats
+// history.  Events outside of the range are ignored.  A user is counted as
+// active in a bucket if they submitted a proposal or a comment during it.
+func statsHistory(sh www.StatsHistory, events []statsEvent) []www.StatsBucket {
+	// The number of buckets is rounded up without adding the interval to
+	// the range so that large intervals do not overflow.
+	n := (sh.To-sh.From-1)/sh.Interval + 1
+	buckets := make([]www.StatsBucket, n)
+	activeUsers := make([]map[string]struct{}, n)
+	for i := range buckets {
+		start := sh.From + int64(i)*sh.Interval
+		end := sh.To
+		if sh.To-start > sh.Interval {
+			end = start + sh.Interval
+		}
+		buckets[i].Start = start
+		buckets[i].End = end
+		activeUsers[i] = make(map[string]struct{})
+	}
+
+	for _, v := range events {
+		if v.Timestamp < sh.From || v.Timestamp >= sh.To {
+			continue
+		}
+		i := (v.Timestamp - sh.From) / sh.Interval
+		switch v.Type {
+		case statsEventSubmitted:
+			buckets[i].NumOfSubmitted++
+		case statsEventApproved:
+			buckets[i].NumOfApproved++
+		case statsEventRejected:
+			buckets[i].NumOfRejected++
+		}
+		if v.UserID != "" {
+			activeUsers[i][v.UserID] = struct{}{}
+		}
+	}
+
+	for i, b := range buckets {
+		votes := b.NumOfApproved + b.NumOfRejected
+		if votes > 0 {
+			buckets[i].ApprovalRate = float64(b.NumOfApproved) /
+				float64(votes)
+		}
+		buckets[i].NumOfActiveUsers = len(activeUsers[i])
+	}
+
+	return buckets
+}
+
+// propSubmittedAt returns the UNIX timestamp of when the proposal with the
+// passed in token was submitted.  This is the timestamp of the first version
+// of the proposal.
+func (p *politeiawww) propSubmittedAt(token string) (int64, error) {
+	r, err := p.cache.RecordVersion(token, "1")
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range r.Metadata {
+		if v.ID != mdStreamGeneral {
+			continue
+		}
+		md, err := decodeBackendProposalMetadata([]byte(v.Payload))
+		if err != nil {
+			return 0, err
+		}
+		return md.Timestamp, nil
+	}
+	return r.Timestamp, nil
+}
+
+// voteEndedAt estimates the UNIX timestamp of when a vote that ended at the
+// passed in block height ended, using the target time per block.
+func voteEndedAt(endHeight, bestBlock uint64, now time.Time) int64 {
+	if endHeight >= bestBlock {
+		return now.Unix()
+	}
+	d := time.Duration(bestBlock-endHeight) *
+		activeNetParams.Params.TargetTimePerBlock
+	return now.Add(-d).Unix()
+}
+
+// statsEvents returns the proposal submissions, comments and finished votes
+// of all proposals as stats events.
+func (p *politeiawww) statsEvents() ([]statsEvent, error) {
+	bestBlock, err := p.getBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("bestBlock: %v", err)
+	}
+	now := time.Now()
+
+	props, err := p.getAllProps()
+	if err != nil {
+		return nil, fmt.Errorf("getAllProps: %v", err)
+	}
+
+	events := make([]statsEvent, 0, len(props))
+	for _, pr := range props {
+		token := pr.CensorshipRecord.Token
+		ts, err := p.propSubmittedAt(token)
+		if err != nil {
+			return nil, fmt.Errorf("propSubmittedAt %v: %v", token, err)
+		}
+		events = append(events, statsEvent{
+			Type:      statsEventSubmitted,
+			Timestamp: ts,
+			UserID:    pr.UserId,
+		})
+
+		// Only public proposals can be commented on and voted on
+		if pr.Status != www.PropStatusPublic {
+			continue
+		}
+
+		comments, err := p.getPropComments(token)
+		if err != nil {
+			return nil, fmt.Errorf("getPropComments %v: %v", token, err)
+		}
+		for _, c := range comments {
+			events = append(events, statsEvent{
+				Type:      statsEventCommented,
+				Timestamp: c.Timestamp,
+				UserID:    c.UserID,
+			})
+		}
+
+		vs, err := p.getVoteStatus(token, bestBlock)
+		if err != nil {
+			return nil, fmt.Errorf("getVoteStatus %v: %v", token, err)
+		}
+		if vs.Status != www.PropVoteStatusFinished {
+			continue
+		}
+		endHeight, err := strconv.ParseUint(vs.EndHeight, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse end height %v: %v", token, err)
+		}
+		e := statsEvent{
+			Type:      statsEventRejected,
+			Timestamp: voteEndedAt(endHeight, bestBlock, now),
+		}
+		if voteApproved(vs) {
+			e.Type = statsEventApproved
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// ProcessStatsHistory returns the proposal submissions, vote outcomes and
+// active users of the requested time range bucketed by interval.  The stats
+// events are cached for statsCacheTTL.
+func (p *politeiawww) ProcessStatsHistory(sh www.StatsHistory) (*www.StatsHistoryReply, error) {
+	log.Tracef("ProcessStatsHistory: %v %v %v", sh.From, sh.To, sh.Interval)
+
+	err := validateStatsHistory(sh)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := p.stats.get(time.Now(), p.statsEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.StatsHistoryReply{
+		Buckets: statsHistory(sh, events),
+	}, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestValidateStatsHistory(t *testing.T) {
+	var tests = []struct {
+		name    string
+		sh      www.StatsHistory
+		wantErr bool
+	}{
+		{"valid", www.StatsHistory{From: 0, To: 100, Interval: 10}, false},
+		{"max buckets", www.StatsHistory{
+			From:     0,
+			To:       www.StatsHistoryMaxBuckets,
+			Interval: 1,
+		}, false},
+		{"too many buckets", www.StatsHistory{
+			From:     0,
+			To:       www.StatsHistoryMaxBuckets + 1,
+			Interval: 1,
+		}, true},
+		{"zero interval", www.StatsHistory{From: 0, To: 100}, true},
+		{"negative from", www.StatsHistory{From: -1, To: 100,
+			Interval: 10}, true},
+		{"empty range", www.StatsHistory{From: 100, To: 100,
+			Interval: 10}, true},
+		{"max interval", www.StatsHistory{From: 1, To: 100,
+			Interval: math.MaxInt64}, false},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := validateStatsHistory(v.sh)
+			if (err != nil) != v.wantErr {
+				t.Errorf("got error %v, want error %v", err, v.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatsHistory(t *testing.T) {
+	sh := www.StatsHistory{
+		From:     100,
+		To:       125,
+		Interval: 10,
+	}
+	events := []statsEvent{
+		// Outside of the range
+		{Type: statsEventSubmitted, Timestamp: 99, UserID: "a"},
+		{Type: statsEventSubmitted, Timestamp: 125, UserID: "a"},
+
+		// First bucket
+		{Type: statsEventSubmitted, Timestamp: 100, UserID: "a"},
+		{Type: statsEventSubmitted, Timestamp: 105, UserID: "b"},
+		{Type: statsEventCommented, Timestamp: 109, UserID: "a"},
+		{Type: statsEventApproved, Timestamp: 101},
+		{Type: statsEventRejected, Timestamp: 102},
+		{Type: statsEventApproved, Timestamp: 103},
+		{Type: statsEventRejected, Timestamp: 104},
+
+		// Second bucket
+		{Type: statsEventCommented, Timestamp: 110, UserID: "c"},
+
+		// Last, shorter bucket
+		{Type: statsEventRejected, Timestamp: 124},
+	}
+
+	want := []www.StatsBucket{
+		{
+			Start:            100,
+			End:              110,
+			NumOfSubmitted:   2,
+			NumOfApproved:    2,
+			NumOfRejected:    2,
+			ApprovalRate:     0.5,
+			NumOfActiveUsers: 2,
+		},
+		{
+			Start:            110,
+			End:              120,
+			NumOfActiveUsers: 1,
+		},
+		{
+			Start:         120,
+			End:           125,
+			NumOfRejected: 1,
+		},
+	}
+
+	got := statsHistory(sh, events)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// An interval that exceeds the range must not overflow
+	sh = www.StatsHistory{
+		From:     1,
+		To:       100,
+		Interval: math.MaxInt64,
+	}
+	got = statsHistory(sh, events)
+	want = []www.StatsBucket{
+		{
+			Start:            1,
+			End:              100,
+			NumOfSubmitted:   1,
+			NumOfActiveUsers: 1,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	var builds int
+	build := func() ([]statsEvent, error) {
+		builds++
+		return []statsEvent{{Timestamp: int64(builds)}}, nil
+	}
+	fail := func() ([]statsEvent, error) {
+		return nil, fmt.Errorf("build failed")
+	}
+
+	var c statsCache
+	now := time.Unix(1000000, 0)
+
+	// The first request builds the events
+	events, err := c.get(now, build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builds != 1 || events[0].Timestamp != 1 {
+		t.Fatalf("got %v builds, events %v; want 1 build", builds, events)
+	}
+
+	// Requests within the TTL are served from the cache
+	events, err = c.get(now.Add(statsCacheTTL-time.Second), build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builds != 1 || events[0].Timestamp != 1 {
+		t.Fatalf("got %v builds, events %v; want 1 build", builds, events)
+	}
+
+	// A failed rebuild keeps the expired events out of the reply
+	_, err = c.get(now.Add(statsCacheTTL), fail)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+
+	// Expired events are rebuilt
+	events, err = c.get(now.Add(statsCacheTTL), build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builds != 2 || events[0].Timestamp != 2 {
+		t.Fatalf("got %v builds, events %v; want 2 builds", builds, events)
+	}
+}
+
+func TestVoteEndedAt(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	blockTime := int64(activeNetParams.Params.TargetTimePerBlock /
+		time.Second)
+
+	got := voteEndedAt(90, 100, now)
+	want := now.Unix() - 10*blockTime
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// A vote that ends at the best block ended now
+	got = voteEndedAt(100, 100, now)
+	if got != now.Unix() {
+		t.Errorf("got %v, want %v", got, now.Unix())
+	}
+}
@@ -16,6 +16,12 @@ type updateUserKeyEmailTemplateData struct {
 	Email     string
 }
 
+type changeEmailTemplateData struct {
+	Link     string
+	Email    string
+	NewEmail string
+}
+
 type resetPasswordEmailTemplateData struct {
 	Link  string
 	Email string
@@ -114,6 +120,25 @@ was generated for {{.Email}} on Politeia. If you did not perform this action,
 please contact Politeia administrators.
 `
 
+const templateChangeEmailRaw = `
+Click the link below to verify your new email address:
+
+{{.Link}}
+
+You are receiving this email because a request was made to change the email
+address of the Politeia account {{.Email}} to {{.NewEmail}}. If you did not
+perform this action, please ignore this email.
+`
+
+const templateChangeEmailNoticeRaw = `
+You are receiving this email because a request was made to change the email
+address of your Politeia account from {{.Email}} to {{.NewEmail}}. The email
+address is changed once the link that was sent to {{.NewEmail}} is clicked. If
+you did not perform this action, it is possible that your account has been
+compromised. Please contact Politeia administrators through Slack on the
+#politeia channel.
+`
+
 const templateUserLockedResetPasswordRaw = `
 Your account was locked due to too many login attempts. You need to reset your
 password in order to unlock your account:
@@ -161,6 +161,24 @@ func newTestPoliteiawww(t *testing.T) *politeiawww {
 		PaywallAmount: 1e7,
 		PaywallXpub:   "tpubVobLtToNtTq6TZNw4raWQok35PRPZou53vegZqNubtBTJMMFmuMpWybFCfweJ52N8uZJPZZdHE5SRnBBuuRPfC5jdNstfKjiAs8JtbYG9jx",
 		TestNet:       true,
+
+		SessionMaxAge:         defaultSessionMaxAge,
+		SessionAbsoluteMaxAge: defaultSessionAbsoluteMaxAge,
+		ImpersonationMaxAge:   defaultImpersonationMaxAge,
+
+		MaxRequestSize:         defaultMaxRequestSize,
+		MaxProposalRequestSize: defaultMaxProposalRequestSize,
+		MaxCommentRequestSize:  defaultMaxCommentRequestSize,
+		MaxBallotRequestSize:   defaultMaxBallotRequestSize,
+
+		CommentRateLimit:    defaultCommentRateLimit,
+		CommentRateInterval: defaultCommentRateInterval,
+
+		PaywallPollInterval:    defaultPaywallPollInterval,
+		PaywallPollMaxInterval: defaultPaywallPollMaxInterval,
+		PaywallPollBackoff:     defaultPaywallPollBackoff,
+
+		PasswordMinLength: www.PolicyMinPasswordLength,
 	}
 
 	// Setup database
@@ -180,7 +198,7 @@ func newTestPoliteiawww(t *testing.T) *politeiawww {
 	if err != nil {
 		t.Fatalf("create cookie key: %v", err)
 	}
-	sessionsDir := filepath.Join(cfg.DataDir, "sessions")
+	sessionsDir := filepath.Join(cfg.DataDir, sessionsDirname)
 	err = os.MkdirAll(sessionsDir, 0700)
 	if err != nil {
 		t.Fatalf("make sessions dir: %v", err)
@@ -188,7 +206,7 @@ func newTestPoliteiawww(t *testing.T) *politeiawww {
 	store := sessions.NewFilesystemStore(sessionsDir, cookieKey)
 	store.Options = &sessions.Options{
 		Path:     "/",
-		MaxAge:   sessionMaxAge,
+		MaxAge:   defaultSessionMaxAge,
 		Secure:   true,
 		HttpOnly: true,
 		SameSite: http.SameSiteStrictMode,
@@ -200,16 +218,39 @@ func newTestPoliteiawww(t *testing.T) *politeiawww {
 
 	// Init politeiawww context
 	p := politeiawww{
-		cfg:             cfg,
-		db:              db,
-		params:          &chaincfg.TestNet3Params,
-		router:          mux.NewRouter(),
-		store:           store,
-		smtp:            smtp,
-		test:            true,
-		userPubkeys:     make(map[string]string),
-		userPaywallPool: make(map[uuid.UUID]paywallPoolMember),
-		commentScores:   make(map[string]int64),
+		cfg:              cfg,
+		db:               db,
+		params:           &chaincfg.TestNet3Params,
+		router:           mux.NewRouter(),
+		store:            store,
+		smtp:             smtp,
+		test:             true,
+		userPubkeys:      make(map[string]string),
+		userPaywallPool:  make(map[uuid.UUID]paywallPoolMember),
+		commentScores:    make(map[string]int64),
+		commentReactions: make(map[string]map[string]uint64),
+		userSessions:     make(map[string]map[string]struct{}),
+		webhooks:         make(map[string]webhook),
+		apiTokens:        make(map[string]apiToken),
+		uploads:          make(map[string]*upload),
+		billing:          make(map[string]user.ProposalBilling),
+		voteEnds:         make(map[string]uint64),
+		rescans:          make(map[string]*www.UserPaymentsRescanStatusReply),
+		commentLimiter: newRateLimiter(cfg.CommentRateLimit,
+			cfg.CommentRateInterval),
+		inventory: newInventoryStream(),
+	}
+
+	// Setup email templates
+	err = p.initEmailTemplates()
+	if err != nil {
+		t.Fatalf("init email templates: %v", err)
+	}
+
+	// Setup password policy
+	err = p.initPasswordPolicy()
+	if err != nil {
+		t.Fatalf("init password policy: %v", err)
 	}
 
 	// Setup routes
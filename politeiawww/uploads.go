@@ -0,0 +1,377 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// uploadsDirname is the name of the directory, relative to the data
+	// directory, that the staged uploads are stored in.  The content of
+	// an upload is stored in a single file that is named after the
+	// upload ID.
+	uploadsDirname = "uploads"
+
+	// uploadIDSize is the size of an upload ID in bytes.
+	uploadIDSize = 16
+
+	// uploadMaxPerUser is the maximum number of staged uploads that a
+	// user can have at the same time.  It is enough to stage every file
+	// of a proposal.
+	uploadMaxPerUser = www.PolicyMaxMDs + www.PolicyMaxImages
+)
+
+// upload is a proposal file that is being uploaded in chunks.
+type upload struct {
+	ID       string
+	UserID   string
+	Name     string
+	MIME     string
+	Digest   string
+	Size     int64
+	Received int64
+	Expires  int64
+}
+
+// complete returns whether all bytes of the upload have been received.
+func (u *upload) complete() bool {
+	return u.Received == u.Size
+}
+
+// uploadFile returns the path of the file that the content of the passed in
+// upload is stored in.
+func (p *politeiawww) uploadFile(uploadID string) string {
+	return filepath.Join(p.cfg.DataDir, uploadsDirname, uploadID)
+}
+
+// initUploads removes the uploads that were staged before politeiawww was
+// restarted.  The progress of the uploads is only kept in memory so they can
+// not be resumed.
+func (p *politeiawww) initUploads() error {
+	p.uploadsMtx.Lock()
+	defer p.uploadsMtx.Unlock()
+
+	p.uploads = make(map[string]*upload)
+	return os.RemoveAll(filepath.Join(p.cfg.DataDir, uploadsDirname))
+}
+
+// _deleteUpload removes a staged upload along with its content.
+//
+// This function must be called WITH the uploads mutex held.
+func (p *politeiawww) _deleteUpload(uploadID string) {
+	delete(p.uploads, uploadID)
+	err := os.Remove(p.uploadFile(uploadID))
+	if err != nil && !os.IsNotExist(err) {
+		log.Errorf("remove upload %v: %v", uploadID, err)
+	}
+}
+
+// _pruneUploads removes the staged uploads that have expired.
+//
+// This function must be called WITH the uploads mutex held.
+func (p *politeiawww) _pruneUploads(now time.Time) {
+	for id, v := range p.uploads {
+		if v.Expires < now.Unix() {
+			p._deleteUpload(id)
+		}
+	}
+}
+
+// _getUpload returns the staged upload with the passed in ID.  Uploads of
+// other users are treated as if they do not exist.
+//
+// This function must be called WITH the uploads mutex held.
+func (p *politeiawww) _getUpload(uploadID string, u *user.User) (*upload, error) {
+	up, ok := p.uploads[uploadID]
+	if !ok || up.UserID != u.ID.String() ||
+		up.Expires < time.Now().Unix() {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusUploadNotFound,
+			ErrorContext: []string{uploadID},
+		}
+	}
+	return up, nil
+}
+
+// assembleUploads replaces the upload IDs of the passed in proposal files with
+// the content of the staged uploads.  The IDs of the uploads that were used
+// are returned so that they can be removed once the proposal has been
+// submitted.
+func (p *politeiawww) assembleUploads(files []www.File, u *user.User) ([]www.File, []string, error) {
+	p.uploadsMtx.Lock()
+	defer p.uploadsMtx.Unlock()
+
+	var ids []string
+	assembled := make([]www.File, 0, len(files))
+	for _, f := range files {
+		if f.UploadID == "" {
+			assembled = append(assembled, f)
+			continue
+		}
+
+		up, err := p._getUpload(f.UploadID, u)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !up.complete() {
+			return nil, nil, www.UserError{
+				ErrorCode:    www.ErrorStatusUploadIncomplete,
+				ErrorContext: []string{f.Name},
+			}
+		}
+		if f.Payload != "" || f.Name != up.Name || f.MIME != up.MIME {
+			return nil, nil, www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+				ErrorContext: []string{fmt.Sprintf("file %v does not "+
+					"match upload %v", f.Name, up.ID)},
+			}
+		}
+		if f.Digest != up.Digest {
+			return nil, nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidUploadDigest,
+				ErrorContext: []string{f.Name},
+			}
+		}
+
+		b, err := ioutil.ReadFile(p.uploadFile(up.ID))
+		if err != nil {
+			return nil, nil, err
+		}
+		assembled = append(assembled, www.File{
+			Name:    f.Name,
+			MIME:    f.MIME,
+			Digest:  f.Digest,
+			Payload: base64.StdEncoding.EncodeToString(b[:up.Size]),
+		})
+		ids = append(ids, up.ID)
+	}
+
+	return assembled, ids, nil
+}
+
+// deleteUploads removes the staged uploads with the passed in IDs.
+func (p *politeiawww) deleteUploads(uploadIDs []string) {
+	p.uploadsMtx.Lock()
+	defer p.uploadsMtx.Unlock()
+
+	for _, v := range uploadIDs {
+		p._deleteUpload(v)
+	}
+}
+
+// processNewUpload stages a new chunked upload for the passed in user.
+func (p *politeiawww) processNewUpload(nu www.NewUpload, u *user.User) (*www.NewUploadReply, error) {
+	log.Tracef("processNewUpload: %v %v %v", u.ID, nu.Name, nu.Size)
+
+	// Filenames may not contain a path
+	if nu.Name == "" || filepath.Base(nu.Name) != nu.Name {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidFilename,
+			ErrorContext: []string{nu.Name},
+		}
+	}
+	if !mime.MimeValid(nu.MIME) {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusUnsupportedMIMEType,
+			ErrorContext: []string{nu.Name, nu.MIME},
+		}
+	}
+	d, err := hex.DecodeString(nu.Digest)
+	if err != nil || len(d) != sha256.Size {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid digest"},
+		}
+	}
+	if nu.Size <= 0 {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid size"},
+		}
+	}
+
+	// The proposal policy limits the size of the files
+	switch {
+	case strings.HasPrefix(nu.MIME, "image/"):
+		if nu.Size > www.PolicyMaxImageSize {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusMaxImageSizeExceededPolicy,
+			}
+		}
+	default:
+		if nu.Size > www.PolicyMaxMDSize {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusMaxMDSizeExceededPolicy,
+			}
+		}
+	}
+
+	id, err := util.Random(uploadIDSize)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	up := upload{
+		ID:      hex.EncodeToString(id),
+		UserID:  u.ID.String(),
+		Name:    nu.Name,
+		MIME:    nu.MIME,
+		Digest:  nu.Digest,
+		Size:    nu.Size,
+		Expires: now.Unix() + www.UploadExpiry,
+	}
+
+	p.uploadsMtx.Lock()
+	defer p.uploadsMtx.Unlock()
+
+	p._pruneUploads(now)
+	var n int
+	for _, v := range p.uploads {
+		if v.UserID == up.UserID {
+			n++
+		}
+	}
+	if n >= uploadMaxPerUser {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusMaxUploadsExceeded,
+		}
+	}
+
+	fp := p.uploadFile(up.ID)
+	err = os.MkdirAll(filepath.Dir(fp), 0700)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(fp, []byte{}, 0600)
+	if err != nil {
+		return nil, err
+	}
+	p.uploads[up.ID] = &up
+
+	return &www.NewUploadReply{
+		UploadID:  up.ID,
+		ChunkSize: www.UploadChunkSize,
+		Expires:   up.Expires,
+	}, nil
+}
+
+// processUploadChunk appends a chunk to a staged upload of the passed in user.
+// The digest of the upload is verified once the last chunk has been received;
+// an upload with a digest that does not match is removed.
+func (p *politeiawww) processUploadChunk(uc www.UploadChunk, u *user.User) (*www.UploadChunkReply, error) {
+	log.Tracef("processUploadChunk: %v %v", uc.UploadID, uc.Offset)
+
+	b, err := base64.StdEncoding.DecodeString(uc.Payload)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidBase64,
+		}
+	}
+	if len(b) == 0 || len(b) > www.UploadChunkSize {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("chunk size must be "+
+				"between 1 and %v bytes", www.UploadChunkSize)},
+		}
+	}
+
+	p.uploadsMtx.Lock()
+	defer p.uploadsMtx.Unlock()
+
+	up, err := p._getUpload(uc.UploadID, u)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chunks must be sent in order.  The expected offset is returned so
+	// that a client that lost track of the progress can resume.
+	if uc.Offset != up.Received {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidUploadOffset,
+			ErrorContext: []string{fmt.Sprintf("%v", up.Received)},
+		}
+	}
+	if up.Received+int64(len(b)) > up.Size {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"chunk exceeds upload size"},
+		}
+	}
+
+	// Write the chunk at the offset instead of appending it so that a
+	// chunk that was only partially written is overwritten.
+	f, err := os.OpenFile(p.uploadFile(up.ID), os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.WriteAt(b, up.Received)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	err = f.Close()
+	if err != nil {
+		return nil, err
+	}
+	up.Received += int64(len(b))
+	up.Expires = time.Now().Unix() + www.UploadExpiry
+
+	if up.complete() {
+		data, err := ioutil.ReadFile(p.uploadFile(up.ID))
+		if err != nil {
+			return nil, err
+		}
+		digest := hex.EncodeToString(util.Digest(data[:up.Size]))
+		if digest != up.Digest {
+			p._deleteUpload(up.ID)
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidUploadDigest,
+				ErrorContext: []string{up.Name},
+			}
+		}
+	}
+
+	return &www.UploadChunkReply{
+		Received: up.Received,
+		Complete: up.complete(),
+	}, nil
+}
+
+// processUploadStatus returns the progress of a staged upload of the passed
+// in user.
+func (p *politeiawww) processUploadStatus(us www.UploadStatus, u *user.User) (*www.UploadStatusReply, error) {
+	log.Tracef("processUploadStatus: %v", us.UploadID)
+
+	p.uploadsMtx.Lock()
+	defer p.uploadsMtx.Unlock()
+
+	up, err := p._getUpload(us.UploadID, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.UploadStatusReply{
+		UploadID: up.ID,
+		Name:     up.Name,
+		Size:     up.Size,
+		Received: up.Received,
+		Complete: up.complete(),
+		Expires:  up.Expires,
+	}, nil
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+// stageUpload stages the passed in file and uploads the first n bytes of its
+// content, or all of its content when n is negative.  The ID of the upload is
+// returned.
+func stageUpload(t *testing.T, p *politeiawww, u *user.User, f www.File, n int) string {
+	t.Helper()
+
+	data, err := base64.StdEncoding.DecodeString(f.Payload)
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	nur, err := p.processNewUpload(www.NewUpload{
+		Name:   f.Name,
+		MIME:   f.MIME,
+		Digest: f.Digest,
+		Size:   int64(len(data)),
+	}, u)
+	if err != nil {
+		t.Fatalf("processNewUpload: %v", err)
+	}
+
+	if n < 0 {
+		n = len(data)
+	}
+	for offset := 0; offset < n; offset += www.UploadChunkSize {
+		end := offset + www.UploadChunkSize
+		if end > n {
+			end = n
+		}
+		_, err := p.processUploadChunk(www.UploadChunk{
+			UploadID: nur.UploadID,
+			Offset:   int64(offset),
+			Payload:  base64.StdEncoding.EncodeToString(data[offset:end]),
+		}, u)
+		if err != nil {
+			t.Fatalf("processUploadChunk %v: %v", offset, err)
+		}
+	}
+
+	return nur.UploadID
+}
+
+func TestProcessNewUpload(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+	md := createFileMD(t, 8, "Valid Title")
+
+	// Setup tests
+	var tests = []struct {
+		name    string
+		nu      www.NewUpload
+		wantErr www.ErrorStatusT
+	}{
+		{"path in filename",
+			www.NewUpload{
+				Name:   "../index.md",
+				MIME:   md.MIME,
+				Digest: md.Digest,
+				Size:   10,
+			},
+			www.ErrorStatusInvalidFilename},
+		{"unsupported mime type",
+			www.NewUpload{
+				Name:   "budget.xls",
+				MIME:   "application/vnd.ms-excel",
+				Digest: md.Digest,
+				Size:   10,
+			},
+			www.ErrorStatusUnsupportedMIMEType},
+		{"invalid digest",
+			www.NewUpload{
+				Name:   md.Name,
+				MIME:   md.MIME,
+				Digest: "00",
+				Size:   10,
+			},
+			www.ErrorStatusInvalidInput},
+		{"invalid size",
+			www.NewUpload{
+				Name:   md.Name,
+				MIME:   md.MIME,
+				Digest: md.Digest,
+			},
+			www.ErrorStatusInvalidInput},
+		{"markdown too large",
+			www.NewUpload{
+				Name:   md.Name,
+				MIME:   md.MIME,
+				Digest: md.Digest,
+				Size:   www.PolicyMaxMDSize + 1,
+			},
+			www.ErrorStatusMaxMDSizeExceededPolicy},
+		{"image too large",
+			www.NewUpload{
+				Name:   "a.png",
+				MIME:   "image/png",
+				Digest: md.Digest,
+				Size:   www.PolicyMaxImageSize + 1,
+			},
+			www.ErrorStatusMaxImageSizeExceededPolicy},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processNewUpload(v.nu, usr)
+			got := errToStr(err)
+			want := www.ErrorStatus[v.wantErr]
+			if got != want {
+				t.Errorf("got error %v, want %v", got, want)
+			}
+		})
+	}
+
+	// Users can only stage a limited number of uploads
+	for i := 0; i < uploadMaxPerUser; i++ {
+		stageUpload(t, p, usr, *md, 0)
+	}
+	_, err := p.processNewUpload(www.NewUpload{
+		Name:   md.Name,
+		MIME:   md.MIME,
+		Digest: md.Digest,
+		Size:   10,
+	}, usr)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusMaxUploadsExceeded]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+}
+
+func TestProcessUploadChunk(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+	other, _ := newUser(t, p, false)
+
+	// The markdown file spans multiple chunks
+	md := createFileMD(t, 2*www.UploadChunkSize, "Valid Title")
+	data, err := base64.StdEncoding.DecodeString(md.Payload)
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	id := stageUpload(t, p, usr, *md, www.UploadChunkSize)
+
+	// The upload can only be resumed at the received offset
+	_, err = p.processUploadChunk(www.UploadChunk{
+		UploadID: id,
+		Offset:   0,
+		Payload:  base64.StdEncoding.EncodeToString(data[:10]),
+	}, usr)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusInvalidUploadOffset]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+	sr, err := p.processUploadStatus(www.UploadStatus{UploadID: id}, usr)
+	if err != nil {
+		t.Fatalf("processUploadStatus: %v", err)
+	}
+	if sr.Received != www.UploadChunkSize || sr.Complete {
+		t.Fatalf("got received %v complete %v, want %v false",
+			sr.Received, sr.Complete, www.UploadChunkSize)
+	}
+
+	// Uploads of other users do not exist
+	_, err = p.processUploadStatus(www.UploadStatus{UploadID: id}, other)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusUploadNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+
+	// Finish the upload
+	var ucr *www.UploadChunkReply
+	for offset := www.UploadChunkSize; offset < len(data); offset += www.UploadChunkSize {
+		end := offset + www.UploadChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		ucr, err = p.processUploadChunk(www.UploadChunk{
+			UploadID: id,
+			Offset:   int64(offset),
+			Payload:  base64.StdEncoding.EncodeToString(data[offset:end]),
+		}, usr)
+		if err != nil {
+			t.Fatalf("processUploadChunk %v: %v", offset, err)
+		}
+	}
+	if ucr.Received != int64(len(data)) || !ucr.Complete {
+		t.Fatalf("got received %v complete %v, want %v true",
+			ucr.Received, ucr.Complete, len(data))
+	}
+
+	// An upload with content that does not match the digest is removed
+	r, err := util.Random(8)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	nur, err := p.processNewUpload(www.NewUpload{
+		Name:   md.Name,
+		MIME:   md.MIME,
+		Digest: hex.EncodeToString(util.Digest(r)),
+		Size:   10,
+	}, usr)
+	if err != nil {
+		t.Fatalf("processNewUpload: %v", err)
+	}
+	_, err = p.processUploadChunk(www.UploadChunk{
+		UploadID: nur.UploadID,
+		Payload:  base64.StdEncoding.EncodeToString(data[:10]),
+	}, usr)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusInvalidUploadDigest]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+	_, err = p.processUploadStatus(www.UploadStatus{
+		UploadID: nur.UploadID,
+	}, usr)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusUploadNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+}
+
+func TestNewProposalWithUploads(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, id := newUser(t, p, false)
+
+	md := createFileMD(t, 2*www.UploadChunkSize, "Valid Title")
+	png := createFilePNG(t, false)
+	np := createNewProposal(t, id, []www.File{*md, *png})
+
+	// Replace the payload of the markdown file with an incomplete upload
+	uploadID := stageUpload(t, p, usr, *md, www.UploadChunkSize)
+	np.Files[0].Payload = ""
+	np.Files[0].UploadID = uploadID
+	_, err := p.ProcessNewProposal(*np, usr)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusUploadIncomplete]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+
+	// Submit the proposal with a completed upload
+	uploadID = stageUpload(t, p, usr, *md, -1)
+	np.Files[0].UploadID = uploadID
+	_, err = p.ProcessNewProposal(*np, usr)
+	if err != nil {
+		t.Fatalf("ProcessNewProposal: %v", err)
+	}
+
+	// The upload is removed once the proposal has been submitted
+	_, err = p.processUploadStatus(www.UploadStatus{UploadID: uploadID}, usr)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusUploadNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+}
+
+func TestHandleNewProposalWithUploads(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, id := newUser(t, p, false)
+
+	md := createFileMD(t, 2*www.UploadChunkSize, "Valid Title")
+	png := createFilePNG(t, false)
+	np := createNewProposal(t, id, []www.File{*md, *png})
+
+	// The file that refers to an upload has no payload, which the
+	// request body validation of the route must accept
+	uploadID := stageUpload(t, p, usr, *md, -1)
+	np.Files[0].Payload = ""
+	np.Files[0].UploadID = uploadID
+
+	b, err := json.Marshal(np)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost,
+		www.PoliteiaWWWAPIRoute+www.RouteNewProposal, bytes.NewReader(b))
+	for _, c := range newSessionCookies(t, p, usr.ID.String()) {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	p.router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status code %v, want %v: %s", res.StatusCode,
+			http.StatusOK, body)
+	}
+
+	// The upload is removed once the proposal has been submitted
+	_, err = p.processUploadStatus(www.UploadStatus{UploadID: uploadID}, usr)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusUploadNotFound]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+}
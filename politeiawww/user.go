@@ -15,7 +15,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/badoux/checkmail"
 	"github.com/btcsuite/golangcrypto/bcrypt"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
 	www "github.com/decred/politeia/politeiawww/api/v1"
@@ -77,6 +79,7 @@ func convertWWWUserFromDatabaseUser(user *user.User) www.User {
 		Identities:                      convertWWWIdentitiesFromDatabaseIdentities(user.Identities),
 		ProposalCredits:                 ProposalCreditBalance(user),
 		EmailNotifications:              user.EmailNotifications,
+		Locale:                          user.Locale,
 	}
 }
 
@@ -95,8 +98,10 @@ func convertWWWIdentitiesFromDatabaseIdentities(identities []user.Identity) []ww
 // Identity.
 func convertWWWIdentityFromDatabaseIdentity(identity user.Identity) www.UserIdentity {
 	return www.UserIdentity{
-		Pubkey: hex.EncodeToString(identity.Key[:]),
-		Active: user.IsIdentityActive(identity),
+		Pubkey:      hex.EncodeToString(identity.Key[:]),
+		Active:      user.IsIdentityActive(identity),
+		Activated:   identity.Activated,
+		Deactivated: identity.Deactivated,
 	}
 }
 
@@ -144,17 +149,6 @@ func validateUsername(username string) error {
 	return nil
 }
 
-// validatePassword verifies that a password adheres to required policy.
-func validatePassword(password string) error {
-	if len(password) < www.PolicyMinPasswordLength {
-		return www.UserError{
-			ErrorCode: www.ErrorStatusMalformedPassword,
-		}
-	}
-
-	return nil
-}
-
 // validatePassword verifies that a pubkey is valid and not set to all zeros.
 func validatePubkey(publicKey string) ([]byte, error) {
 	pk, err := hex.DecodeString(publicKey)
@@ -422,11 +416,69 @@ func (p *politeiawww) processUserDetails(ud *www.UserDetails, isCurrentUser bool
 	return &udr, nil
 }
 
+// processBatchUserDetails returns the details of multiple users.  The fields
+// of each user are filtered the same way as processUserDetails.  Users that do
+// not exist are left out of the reply so that a single deleted account does
+// not fail the whole batch.  The session user is nil when the caller is not
+// logged in.
+func (p *politeiawww) processBatchUserDetails(bud www.BatchUserDetails, sessionUser *user.User) (*www.BatchUserDetailsReply, error) {
+	log.Tracef("processBatchUserDetails: %v", len(bud.UserIDs))
+
+	if len(bud.UserIDs) == 0 ||
+		len(bud.UserIDs) > www.UserDetailsBatchSize {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+			ErrorContext: []string{fmt.Sprintf("batch must contain "+
+				"between 1 and %v user ids", www.UserDetailsBatchSize)},
+		}
+	}
+
+	users := make(map[string]www.User, len(bud.UserIDs))
+	for _, v := range bud.UserIDs {
+		if _, ok := users[v]; ok {
+			continue
+		}
+
+		u, err := p.getUserByIDStr(v)
+		if err != nil {
+			if e, ok := err.(www.UserError); ok &&
+				e.ErrorCode == www.ErrorStatusUserNotFound {
+				continue
+			}
+			if e, ok := err.(www.UserError); ok {
+				e.ErrorContext = append(e.ErrorContext, v)
+				err = e
+			}
+			return nil, err
+		}
+
+		wwwUser := convertWWWUserFromDatabaseUser(u)
+		if sessionUser == nil ||
+			(!sessionUser.Admin && sessionUser.ID != u.ID) {
+			wwwUser = filterUserPublicFields(wwwUser)
+		}
+		users[v] = wwwUser
+	}
+
+	return &www.BatchUserDetailsReply{
+		Users: users,
+	}, nil
+}
+
 // processEditUser edits a user's preferences.
 func (p *politeiawww) processEditUser(eu *www.EditUser, user *user.User) (*www.EditUserReply, error) {
 	if eu.EmailNotifications != nil {
 		user.EmailNotifications = *eu.EmailNotifications
 	}
+	if eu.Locale != nil {
+		if *eu.Locale != "" {
+			err := validateLocale(*eu.Locale)
+			if err != nil {
+				return nil, err
+			}
+		}
+		user.Locale = *eu.Locale
+	}
 
 	// Update the user in the database.
 	err := p.db.UserUpdate(*user)
@@ -558,7 +610,7 @@ func (p *politeiawww) login(l *www.Login) loginReplyWithError {
 			if checkUserIsLocked(u.FailedLoginAttempts) && !p.test {
 				// This is conditional on the email server
 				// being setup.
-				err := p.emailUserLocked(u.Email)
+				err := p.emailUserLocked(u.Email, u.Locale)
 				if err != nil {
 					return loginReplyWithError{
 						reply: nil,
@@ -659,7 +711,7 @@ func (p *politeiawww) emailResetPassword(u *user.User, rp www.ResetPassword, rpr
 	if !p.test {
 		// This is conditional on the email server being setup.
 		err := p.emailResetPasswordVerificationLink(rp.Email,
-			hex.EncodeToString(token))
+			hex.EncodeToString(token), u.Locale)
 		if err != nil {
 			return err
 		}
@@ -673,22 +725,25 @@ func (p *politeiawww) emailResetPassword(u *user.User, rp www.ResetPassword, rpr
 	return nil
 }
 
-// verifyResetPassword verifies the reset password command.
-func (p *politeiawww) verifyResetPassword(u *user.User, rp www.ResetPassword, rpr *www.ResetPasswordReply) error {
+// checkResetPasswordToken checks that the passed in reset password
+// verification token matches the token of the user and has not expired.  The
+// user is not modified.
+func checkResetPasswordToken(u *user.User, email, verificationToken string) error {
 	// Decode the verification token.
-	token, err := hex.DecodeString(rp.VerificationToken)
+	token, err := hex.DecodeString(verificationToken)
 	if err != nil {
 		log.Debugf("VerifyResetPassword failure for %v: verification "+
-			"token could not be decoded: %v", rp.Email, err)
+			"token could not be decoded: %v", email, err)
 		return www.UserError{
 			ErrorCode: www.ErrorStatusVerificationTokenInvalid,
 		}
 	}
 
 	// Check that the verification token matches.
-	if !bytes.Equal(token, u.ResetPasswordVerificationToken) {
+	if u.ResetPasswordVerificationToken == nil ||
+		!bytes.Equal(token, u.ResetPasswordVerificationToken) {
 		log.Debugf("VerifyResetPassword failure for %v: verification "+
-			"token doesn't match, expected %v", rp.Email,
+			"token doesn't match, expected %v", email,
 			u.ResetPasswordVerificationToken)
 		return www.UserError{
 			ErrorCode: www.ErrorStatusVerificationTokenInvalid,
@@ -698,20 +753,30 @@ func (p *politeiawww) verifyResetPassword(u *user.User, rp www.ResetPassword, rp
 	// Check that the token hasn't expired.
 	if u.ResetPasswordVerificationExpiry < time.Now().Unix() {
 		log.Debugf("VerifyResetPassword failure for %v: verification "+
-			"token not expired yet", rp.Email)
+			"token expired", email)
 		return www.UserError{
 			ErrorCode: www.ErrorStatusVerificationTokenExpired,
 		}
 	}
 
+	return nil
+}
+
+// verifyResetPassword verifies the reset password command.
+func (p *politeiawww) verifyResetPassword(u *user.User, rp www.ResetPassword, rpr *www.ResetPasswordReply) error {
+	err := checkResetPasswordToken(u, rp.Email, rp.VerificationToken)
+	if err != nil {
+		return err
+	}
+
 	// Validate the new password.
-	err = validatePassword(rp.NewPassword)
+	err = p.validatePassword(rp.NewPassword)
 	if err != nil {
 		return err
 	}
 
 	// Hash the new password.
-	hashedPassword, err := p.hashPassword(rp.NewPassword)
+	hashedPassword, err := p.hashNewPassword(rp.NewPassword)
 	if err != nil {
 		return err
 	}
@@ -742,6 +807,7 @@ func (p *politeiawww) createLoginReply(u *user.User, lastLoginTime int64) (*www.
 		PaywallTxID:     u.NewUserPaywallTx,
 		ProposalCredits: ProposalCreditBalance(u),
 		LastLoginTime:   lastLoginTime,
+		Locale:          u.Locale,
 	}
 
 	if !p.HasUserPaid(u) {
@@ -814,11 +880,20 @@ func (p *politeiawww) processNewUser(u www.NewUser) (*www.NewUserReply, error) {
 	}
 
 	// Validate the password.
-	err = validatePassword(u.Password)
+	err = p.validatePassword(u.Password)
 	if err != nil {
 		return nil, err
 	}
 
+	// Validate the locale.  Emails are sent in the default locale
+	// when no locale is set.
+	if u.Locale != "" {
+		err = validateLocale(u.Locale)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate that the pubkey isn't already taken.
 	err = p.validatePubkeyIsUnique(u.PublicKey, existingUser)
 	if err != nil {
@@ -826,7 +901,7 @@ func (p *politeiawww) processNewUser(u www.NewUser) (*www.NewUserReply, error) {
 	}
 
 	// Hash the user's password.
-	hashedPassword, err := p.hashPassword(u.Password)
+	hashedPassword, err := p.hashNewPassword(u.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -843,6 +918,7 @@ func (p *politeiawww) processNewUser(u www.NewUser) (*www.NewUserReply, error) {
 		Username:       username,
 		HashedPassword: hashedPassword,
 		Admin:          false,
+		Locale:         u.Locale,
 	}
 	setNewUserVerificationAndIdentity(&newUser, token, expiry, false, pk)
 
@@ -851,7 +927,7 @@ func (p *politeiawww) processNewUser(u www.NewUser) (*www.NewUserReply, error) {
 		// the new user won't be created.
 		//
 		// This is conditional on the email server being setup.
-		err := p.emailNewUserVerificationLink(u.Email, hex.EncodeToString(token), u.Username)
+		err := p.emailNewUserVerificationLink(u.Email, hex.EncodeToString(token), u.Username, u.Locale)
 		if err != nil {
 			log.Errorf("Email new user verification link failed %v, %v", u.Email, err)
 			return &reply, nil
@@ -917,6 +993,10 @@ func (p *politeiawww) processNewUser(u www.NewUser) (*www.NewUserReply, error) {
 // user.  It ensures that the token matches with the input and that the token
 // hasn't expired.  On success it returns database user record.
 func (p *politeiawww) processVerifyNewUser(usr www.VerifyNewUser) (*user.User, error) {
+	// The user must not be purged while it is being verified.
+	p.userPurgeMtx.Lock()
+	defer p.userPurgeMtx.Unlock()
+
 	// Check that the user already exists.
 	u, err := p.db.UserGet(usr.Email)
 	if err != nil {
@@ -1012,6 +1092,11 @@ func (p *politeiawww) processVerifyNewUser(usr www.VerifyNewUser) (*user.User, e
 func (p *politeiawww) processResendVerification(rv *www.ResendVerification) (*www.ResendVerificationReply, error) {
 	rvr := www.ResendVerificationReply{}
 
+	// The user must not be purged while its verification token is
+	// renewed.
+	p.userPurgeMtx.Lock()
+	defer p.userPurgeMtx.Unlock()
+
 	// Get user from db.
 	u, err := p.db.UserGet(rv.Email)
 	if err != nil {
@@ -1074,7 +1159,7 @@ func (p *politeiawww) processResendVerification(rv *www.ResendVerification) (*ww
 	if !p.test {
 		// This is conditional on the email server being setup.
 		err := p.emailNewUserVerificationLink(u.Email,
-			hex.EncodeToString(token), u.Username)
+			hex.EncodeToString(token), u.Username, u.Locale)
 		if err != nil {
 			return nil, err
 		}
@@ -1128,6 +1213,7 @@ func (p *politeiawww) processUpdateUserKey(usr *user.User, u www.UpdateUserKey)
 	// Add the updated user information to the db.
 	usr.UpdateKeyVerificationToken = token
 	usr.UpdateKeyVerificationExpiry = expiry
+	usr.ResendUpdateKeyExpiry = 0
 
 	identity := user.Identity{}
 	copy(identity.Key[:], pk)
@@ -1141,7 +1227,7 @@ func (p *politeiawww) processUpdateUserKey(usr *user.User, u www.UpdateUserKey)
 	if !p.test {
 		// This is conditional on the email server being setup.
 		err := p.emailUpdateUserKeyVerificationLink(usr.Email, u.PublicKey,
-			hex.EncodeToString(token))
+			hex.EncodeToString(token), usr.Locale)
 		if err != nil {
 			return nil, err
 		}
@@ -1154,6 +1240,66 @@ func (p *politeiawww) processUpdateUserKey(usr *user.User, u www.UpdateUserKey)
 	return &reply, nil
 }
 
+// processResendUpdateUserKey resends the verification email of the pending key
+// update of the user.  The existing verification token is sent again if it has
+// not expired; otherwise a new token is generated.  Like new user verification
+// emails, the email can only be resent once until the resent token expires.
+func (p *politeiawww) processResendUpdateUserKey(usr *user.User) (*www.ResendUpdateUserKeyReply, error) {
+	var reply www.ResendUpdateUserKeyReply
+
+	// Ensure there is a key update pending verification.
+	if usr.UpdateKeyVerificationToken == nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusNoPendingUpdateUserKey,
+		}
+	}
+
+	// Don't resend the email more than once per verification period.
+	now := time.Now().Unix()
+	if usr.ResendUpdateKeyExpiry > now {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusVerificationTokenUnexpired,
+			ErrorContext: []string{
+				strconv.FormatInt(usr.ResendUpdateKeyExpiry, 10),
+			},
+		}
+	}
+
+	// Generate a new verification token if the existing one has
+	// expired.
+	if usr.UpdateKeyVerificationExpiry <= now {
+		token, expiry, err := generateVerificationTokenAndExpiry()
+		if err != nil {
+			return nil, err
+		}
+		usr.UpdateKeyVerificationToken = token
+		usr.UpdateKeyVerificationExpiry = expiry
+	}
+	usr.ResendUpdateKeyExpiry = usr.UpdateKeyVerificationExpiry
+
+	err := p.db.UserUpdate(*usr)
+	if err != nil {
+		return nil, err
+	}
+
+	token := hex.EncodeToString(usr.UpdateKeyVerificationToken)
+	if !p.test {
+		// The pending key is the most recently added identity.
+		id := usr.Identities[len(usr.Identities)-1]
+		err := p.emailUpdateUserKeyVerificationLink(usr.Email,
+			hex.EncodeToString(id.Key[:]), token, usr.Locale)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Only set the token if email verification is disabled.
+	if p.smtp.disabled {
+		reply.VerificationToken = token
+	}
+	return &reply, nil
+}
+
 // processVerifyUpdateUserKey verifies the token generated for the recently
 // generated key pair. It ensures that the token matches with the input and
 // that the token hasn't expired.
@@ -1264,6 +1410,33 @@ func (p *politeiawww) processLogin(l www.Login) (*www.LoginReply, error) {
 	return r.reply, r.err
 }
 
+// processUsernameAvailable checks whether the passed in username adheres to
+// the username policy and is not already taken.  The username is normalized
+// the same way that it is when a new user is created.
+func (p *politeiawww) processUsernameAvailable(ua www.UsernameAvailable) (*www.UsernameAvailableReply, error) {
+	username := formatUsername(ua.Username)
+	err := validateUsername(username)
+	if err != nil {
+		return &www.UsernameAvailableReply{
+			Reason: www.ErrorStatusMalformedUsername,
+		}, nil
+	}
+
+	_, err = p.db.UserGetByUsername(username)
+	switch err {
+	case nil:
+		return &www.UsernameAvailableReply{
+			Reason: www.ErrorStatusDuplicateUsername,
+		}, nil
+	case user.ErrUserNotFound:
+		return &www.UsernameAvailableReply{
+			Available: true,
+		}, nil
+	default:
+		return nil, err
+	}
+}
+
 // processChangeUsername checks that the password matches the one
 // in the database, then checks that the username is valid and not
 // already taken, then changes the user record in the database to
@@ -1339,13 +1512,13 @@ func (p *politeiawww) processChangePassword(email string, cp www.ChangePassword)
 	}
 
 	// Validate the new password.
-	err = validatePassword(cp.NewPassword)
+	err = p.validatePassword(cp.NewPassword)
 	if err != nil {
 		return nil, err
 	}
 
 	// Hash the user's password.
-	hashedPassword, err := p.hashPassword(cp.NewPassword)
+	hashedPassword, err := p.hashNewPassword(cp.NewPassword)
 	if err != nil {
 		return nil, err
 	}
@@ -1357,7 +1530,7 @@ func (p *politeiawww) processChangePassword(email string, cp www.ChangePassword)
 		return nil, err
 	}
 
-	err = p.emailUserPasswordChanged(email)
+	err = p.emailUserPasswordChanged(email, u.Locale)
 	if err != nil {
 		return nil, err
 	}
@@ -1365,6 +1538,138 @@ func (p *politeiawww) processChangePassword(email string, cp www.ChangePassword)
 	return &reply, nil
 }
 
+// processChangeEmail checks that the password matches the one in the database
+// and that the new email address is valid and not already in use.  It then
+// sets a verification token and expiry on the user and sends the token to the
+// new email address.  The email address is not changed until the token is
+// verified.  A new request replaces any pending email address change.
+func (p *politeiawww) processChangeEmail(u *user.User, ce www.ChangeEmail) (*www.ChangeEmailReply, error) {
+	var reply www.ChangeEmailReply
+
+	// Check the user's password.
+	err := bcrypt.CompareHashAndPassword(u.HashedPassword,
+		[]byte(ce.Password))
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidEmailOrPassword,
+		}
+	}
+
+	// Format and validate the new email.
+	newEmail := strings.ToLower(strings.TrimSpace(ce.NewEmail))
+	err = checkmail.ValidateFormat(newEmail)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusMalformedEmail,
+		}
+	}
+
+	// Check for duplicate email
+	_, err = p.db.UserGet(newEmail)
+	switch err {
+	case nil:
+		// Duplicate
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusDuplicateEmail,
+		}
+	case user.ErrUserNotFound:
+		// Doesn't exist, continue with the email change.
+	default:
+		// All other errors
+		return nil, err
+	}
+
+	// Generate a new verification token and expiry.
+	token, expiry, err := generateVerificationTokenAndExpiry()
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the updated user information to the db.
+	u.NewEmail = newEmail
+	u.ChangeEmailVerificationToken = token
+	u.ChangeEmailVerificationExpiry = expiry
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	// This is conditional on the email server being setup.
+	err = p.emailChangeEmailVerificationLink(u.Email, newEmail,
+		hex.EncodeToString(token), u.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notify the current email address so that a change that the user
+	// did not make does not go unnoticed.
+	err = p.emailChangeEmailNotice(u.Email, newEmail, u.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only set the token if email verification is disabled.
+	if p.smtp.disabled {
+		reply.VerificationToken = hex.EncodeToString(token)
+	}
+	return &reply, nil
+}
+
+// processVerifyChangeEmail verifies the token that was sent to the new email
+// address of a pending email address change.  If the token matches and has
+// not expired, the user's email address is changed to the new email address.
+func (p *politeiawww) processVerifyChangeEmail(u *user.User, vce www.VerifyChangeEmail) (*www.VerifyChangeEmailReply, error) {
+	// Decode the verification token.
+	token, err := hex.DecodeString(vce.VerificationToken)
+	if err != nil {
+		log.Debugf("VerifyChangeEmail failure for %v: verification "+
+			"token could not be decoded: %v", u.Email, err)
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusVerificationTokenInvalid,
+		}
+	}
+
+	// Check that there is a pending email change and that the
+	// verification token matches.
+	if u.NewEmail == "" ||
+		!bytes.Equal(token, u.ChangeEmailVerificationToken) {
+		log.Debugf("VerifyChangeEmail failure for %v: verification "+
+			"token doesn't match", u.Email)
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusVerificationTokenInvalid,
+		}
+	}
+
+	// Check that the token hasn't expired.
+	if u.ChangeEmailVerificationExpiry < time.Now().Unix() {
+		log.Debugf("VerifyChangeEmail failure for %v: verification "+
+			"token expired", u.Email)
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusVerificationTokenExpired,
+		}
+	}
+
+	// Change the email and clear out the verification fields. The
+	// new email may have been registered since the change was
+	// requested so the database checks for duplicates again.
+	oldEmail := u.Email
+	u.Email = u.NewEmail
+	u.NewEmail = ""
+	u.ChangeEmailVerificationToken = nil
+	u.ChangeEmailVerificationExpiry = 0
+	err = p.db.UserUpdateEmail(oldEmail, *u)
+	if err != nil {
+		if err == user.ErrUserExists {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusDuplicateEmail,
+			}
+		}
+		return nil, err
+	}
+
+	return &www.VerifyChangeEmailReply{}, nil
+}
+
 // processResetPassword is intended to be called twice; in the first call, an
 // email is provided and the function checks if the user exists. If the user exists, it
 // generates a verification token and stores it in the database. In the second
@@ -1401,6 +1706,37 @@ func (p *politeiawww) processResetPassword(rp www.ResetPassword) (*www.ResetPass
 	return &reply, nil
 }
 
+// processVerifyResetPassword checks that a reset password verification token
+// is valid without consuming it so that clients can ask the user to request a
+// new token before the user enters a new password.  An unknown email is
+// reported as an invalid token so that the route can not be used to find out
+// which email addresses are registered.
+func (p *politeiawww) processVerifyResetPassword(vrp www.VerifyResetPassword) (*www.VerifyResetPasswordReply, error) {
+	u, err := p.db.UserGet(vrp.Email)
+	if err != nil {
+		if err == user.ErrInvalidEmail {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusMalformedEmail,
+			}
+		} else if err == user.ErrUserNotFound {
+			log.Debugf("VerifyResetPassword failure for %v: user not "+
+				"found", vrp.Email)
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusVerificationTokenInvalid,
+			}
+		}
+
+		return nil, err
+	}
+
+	err = checkResetPasswordToken(u, vrp.Email, vrp.VerificationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &www.VerifyResetPasswordReply{}, nil
+}
+
 // ProcessUserProposalCredits returns a list of the user's unspent proposal
 // credits and a list of the user's spent proposal credits.
 func ProcessUserProposalCredits(u *user.User) (*www.UserProposalCreditsReply, error) {
@@ -1574,14 +1910,186 @@ func (p *politeiawww) processManageUser(mu *v1.ManageUser, adminUser *user.User)
 	return &v1.ManageUserReply{}, nil
 }
 
-// processUsers returns a list of users given a set of filters.
+// processUserLogoutAll logs the specified user out of all of their sessions by
+// deleting the sessions from the session store.  Unlike deactivating a user,
+// which only removes a session once it is used again, the sessions are
+// revoked immediately.
+func (p *politeiawww) processUserLogoutAll(ula *v1.UserLogoutAll, adminUser *user.User) (*v1.UserLogoutAllReply, error) {
+	// Fetch the database user.
+	user, err := p.getUserByIDStr(ula.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := p.removeUserSessions(user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Admin %v logged user %v out of %v sessions",
+		adminUser.Username, user.Username, removed)
+
+	return &v1.UserLogoutAllReply{
+		SessionsRemoved: removed,
+	}, nil
+}
+
+// processResendUserEmail regenerates the verification token of a new user
+// verification or reset password email and sends the email to the address of
+// the user on behalf of an admin.  The cooldown that applies when the user
+// requests the email is ignored.  Since the token grants access to the
+// account it is only ever sent to the email address of the user and never
+// returned to the admin.  Every resend is recorded in the admin log.
+func (p *politeiawww) processResendUserEmail(rue *v1.ResendUserEmail, adminUser *user.User) (*v1.ResendUserEmailReply, error) {
+	// Fetch the database user.
+	u, err := p.getUserByIDStr(rue.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate that the reason is supplied.
+	rue.Reason = strings.TrimSpace(rue.Reason)
+	if len(rue.Reason) == 0 {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"reason cannot be blank"},
+		}
+	}
+
+	if u.Deactivated {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusUserDeactivated,
+		}
+	}
+
+	token, expiry, err := generateVerificationTokenAndExpiry()
+	if err != nil {
+		return nil, err
+	}
+
+	switch rue.Email {
+	case v1.UserEmailNewUserVerification:
+		if u.NewUserVerificationToken == nil {
+			return nil, v1.UserError{
+				ErrorCode: v1.ErrorStatusEmailAlreadyVerified,
+			}
+		}
+		u.NewUserVerificationToken = token
+		u.NewUserVerificationExpiry = expiry
+	case v1.UserEmailResetPassword:
+		u.ResetPasswordVerificationToken = token
+		u.ResetPasswordVerificationExpiry = expiry
+	default:
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidUserEmailType,
+		}
+	}
+
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.test {
+		// This is conditional on the email server being setup.
+		switch rue.Email {
+		case v1.UserEmailNewUserVerification:
+			err = p.emailNewUserVerificationLink(u.Email,
+				hex.EncodeToString(token), u.Username, u.Locale)
+		case v1.UserEmailResetPassword:
+			err = p.emailResetPasswordVerificationLink(u.Email,
+				hex.EncodeToString(token), u.Locale)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Infof("Admin %v resent the %v email of user %v",
+		adminUser.Username, v1.UserEmail[rue.Email], u.Username)
+
+	err = p.logAdminAction(adminUser, fmt.Sprintf("resend user email,%v,%v,%v,%v",
+		v1.UserEmail[rue.Email], u.ID, u.Username, rue.Reason))
+	if err != nil {
+		log.Errorf("could not log action to file: %v", err)
+	}
+
+	return &v1.ResendUserEmailReply{}, nil
+}
+
+// processImpersonateUser validates an admin request to impersonate a user and
+// returns the login reply of the user.  Admins and deactivated users can not
+// be impersonated.  The impersonation is recorded in the admin log before the
+// impersonation session is started and is refused if it can not be recorded.
+func (p *politeiawww) processImpersonateUser(iu *v1.ImpersonateUser, adminUser *user.User) (*v1.ImpersonateUserReply, error) {
+	// Fetch the database user.
+	u, err := p.getUserByIDStr(iu.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate that the reason is supplied.
+	iu.Reason = strings.TrimSpace(iu.Reason)
+	if len(iu.Reason) == 0 {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"reason cannot be blank"},
+		}
+	}
+
+	if u.Admin {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusUserActionNotAllowed,
+			ErrorContext: []string{"admins cannot be impersonated"},
+		}
+	}
+	if u.Deactivated {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusUserDeactivated,
+		}
+	}
+
+	err = p.logAdminAction(adminUser, fmt.Sprintf("impersonate user,%v,%v,%v",
+		u.ID, u.Username, iu.Reason))
+	if err != nil {
+		return nil, fmt.Errorf("could not log action to file: %v", err)
+	}
+
+	lr, err := p.createLoginReply(u, u.LastLoginTime)
+	if err != nil {
+		return nil, err
+	}
+	lr.SessionMaxAge = p.cfg.ImpersonationMaxAge
+	lr.ImpersonatedBy = adminUser.ID.String()
+
+	return &v1.ImpersonateUserReply{
+		User: *lr,
+	}, nil
+}
+
+// processUsers returns a list of users given a set of filters.  At most
+// v1.UserListPageSize users are returned, or the requested limit if it is
+// lower.
 func (p *politeiawww) processUsers(users *v1.Users) (*v1.UsersReply, error) {
 	var reply v1.UsersReply
 	reply.Users = make([]v1.AbridgedUser, 0)
+	pageSize := listPageSize(users.Limit, v1.UserListPageSize)
 
 	emailQuery := strings.ToLower(users.Email)
 	usernameQuery := formatUsername(users.Username)
 
+	// Lookup the owner of the public key.  The userPubkeys cache
+	// contains every identity of every user, so users are also found
+	// by keys that they have since replaced.
+	var pubkeyUserID string
+	if users.PublicKey != "" {
+		_, err := validatePubkey(users.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		pubkeyUserID, _ = p.getUserIDByPubKey(users.PublicKey)
+	}
+
 	err := p.db.AllUsers(func(user *user.User) {
 		reply.TotalUsers++
 		userMatches := true
@@ -1602,9 +2110,13 @@ func (p *politeiawww) processUsers(users *v1.Users) (*v1.UsersReply, error) {
 			}
 		}
 
+		if users.PublicKey != "" && user.ID.String() != pubkeyUserID {
+			userMatches = false
+		}
+
 		if userMatches {
 			reply.TotalMatches++
-			if reply.TotalMatches < v1.UserListPageSize {
+			if len(reply.Users) < pageSize {
 				reply.Users = append(reply.Users, v1.AbridgedUser{
 					ID:       user.ID.String(),
 					Email:    user.Email,
@@ -1625,149 +2137,6 @@ func (p *politeiawww) processUsers(users *v1.Users) (*v1.UsersReply, error) {
 	return &reply, nil
 }
 
-// processUserPaymentsRescan allows an admin to rescan a user's paywall address
-// to check for any payments that may have been missed by paywall polling.
-func (p *politeiawww) processUserPaymentsRescan(upr v1.UserPaymentsRescan) (*v1.UserPaymentsRescanReply, error) {
-	// Ensure paywall is enabled
-	if !p.paywallIsEnabled() {
-		return &v1.UserPaymentsRescanReply{}, nil
-	}
-
-	// Lookup user
-	u, err := p.getUserByIDStr(upr.UserID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Fetch user payments
-	payments, err := util.FetchTxsForAddressNotBefore(u.NewUserPaywallAddress,
-		u.NewUserPaywallTxNotBefore)
-	if err != nil {
-		return nil, fmt.Errorf("FetchTxsForAddressNotBefore: %v", err)
-	}
-
-	// Paywalls are in chronological order so sort txs into chronological
-	// order to make them easier to work with
-	sort.SliceStable(payments, func(i, j int) bool {
-		return payments[i].Timestamp < payments[j].Timestamp
-	})
-
-	// Sanity check. Paywalls should already be in chronological order.
-	paywalls := u.ProposalPaywalls
-	sort.SliceStable(paywalls, func(i, j int) bool {
-		return paywalls[i].TxNotBefore < paywalls[j].TxNotBefore
-	})
-
-	// Check for payments that were missed by paywall polling
-	newCredits := make([]user.ProposalCredit, 0, len(payments))
-	for _, payment := range payments {
-		// Check if the payment transaction corresponds to a user
-		// registration payment. A user registration payment may not
-		// exist if the registration paywall was cleared by an admin.
-		if payment.TxID == u.NewUserPaywallTx {
-			continue
-		}
-
-		// Check for credits that correspond to the payment.  If a
-		// credit is found it means that this payment was not missed by
-		// paywall polling and we can continue onto the next payment.
-		var found bool
-		for _, credit := range u.SpentProposalCredits {
-			if credit.TxID == payment.TxID {
-				found = true
-				break
-			}
-		}
-		if found {
-			continue
-		}
-
-		for _, credit := range u.UnspentProposalCredits {
-			if credit.TxID == payment.TxID {
-				found = true
-				break
-			}
-		}
-		if found {
-			continue
-		}
-
-		// Credits were not found for this payment which means that it
-		// was missed by paywall polling. Create new credits using the
-		// paywall details that correspond to the payment timestamp. If
-		// a paywall had not yet been issued, use the current proposal
-		// credit price.
-		var pp user.ProposalPaywall
-		for _, paywall := range paywalls {
-			if payment.Timestamp < paywall.TxNotBefore {
-				continue
-			}
-			if payment.Timestamp > paywall.TxNotBefore {
-				// Corresponding paywall found
-				pp = paywall
-				break
-			}
-		}
-
-		if pp == (user.ProposalPaywall{}) {
-			// Paywall not found. This means the tx occurred before
-			// any paywalls were issued. Use current credit price.
-			pp.CreditPrice = p.cfg.PaywallAmount
-		}
-
-		// Don't add credits if the paywall is in the paywall pool
-		if pp.TxID == "" && !paywallHasExpired(pp.PollExpiry) {
-			continue
-		}
-
-		// Ensure payment has minimum number of confirmations
-		if payment.Confirmations < p.cfg.MinConfirmationsRequired {
-			continue
-		}
-
-		// Create proposal credits
-		numCredits := payment.Amount / pp.CreditPrice
-		c := make([]user.ProposalCredit, numCredits)
-		for i := uint64(0); i < numCredits; i++ {
-			c[i] = user.ProposalCredit{
-				PaywallID:     pp.ID,
-				Price:         pp.CreditPrice,
-				DatePurchased: time.Now().Unix(),
-				TxID:          payment.TxID,
-			}
-		}
-		newCredits = append(newCredits, c...)
-	}
-
-	// Update user record
-	// We relookup the user record here in case the user has spent proposal
-	// credits since the start of this request. Failure to relookup the
-	// user record here could result in adding proposal credits to the
-	// user's account that have already been spent.
-	u, err = p.db.UserGet(u.Email)
-	if err != nil {
-		return nil, fmt.Errorf("UserGet %v", err)
-	}
-
-	u.UnspentProposalCredits = append(u.UnspentProposalCredits,
-		newCredits...)
-
-	err = p.db.UserUpdate(*u)
-	if err != nil {
-		return nil, fmt.Errorf("UserUpdate %v", err)
-	}
-
-	// Convert database credits to www credits
-	newCreditsWWW := make([]v1.ProposalCredit, len(newCredits))
-	for i, credit := range newCredits {
-		newCreditsWWW[i] = convertWWWPropCreditFromDatabasePropCredit(credit)
-	}
-
-	return &v1.UserPaymentsRescanReply{
-		NewCredits: newCreditsWWW,
-	}, nil
-}
-
 // processVerifyUserPayment verifies that the provided transaction
 // meets the minimum requirements to mark the user as paid, and then does
 // that in the user database.
@@ -1809,12 +2178,122 @@ func (p *politeiawww) processVerifyUserPayment(u *user.User, vupt v1.VerifyUserP
 			return nil, err
 		}
 	} else {
+		// Report the payment if it has been sent but does not have
+		// enough confirmations yet.  Failing to look it up is not fatal
+		// since the user simply hasn't paid yet as far as we know.
+		txs, err := util.FetchTxsForAddressNotBefore(
+			u.NewUserPaywallAddress, u.NewUserPaywallTxNotBefore)
+		if err != nil {
+			log.Errorf("processVerifyUserPayment: "+
+				"FetchTxsForAddressNotBefore %v: %v", u.ID, err)
+		}
+		for _, v := range txs {
+			if v.Amount >= u.NewUserPaywallAmount {
+				reply.TxID = v.TxID
+				reply.Confirmations = v.Confirmations
+				break
+			}
+		}
+
 		// TODO: Add the user to the in-memory pool.
 	}
 
 	return &reply, nil
 }
 
+// paymentTxErrors returns the paywall requirements that the provided
+// transaction does not meet.  An empty slice is returned when the transaction
+// can be accepted as the registration payment.
+func paymentTxErrors(tx util.TxDetails, amount uint64, txNotBefore int64, minConfirmations uint64) []string {
+	errs := make([]string, 0, 3)
+	if tx.Amount < amount {
+		errs = append(errs, fmt.Sprintf("amount paid %v is less than "+
+			"the paywall amount %v", tx.Amount, amount))
+	}
+	if tx.Timestamp < txNotBefore {
+		errs = append(errs, fmt.Sprintf("transaction timestamp %v is "+
+			"before the paywall was created at %v", tx.Timestamp,
+			txNotBefore))
+	}
+	if tx.Confirmations < minConfirmations {
+		errs = append(errs, fmt.Sprintf("transaction has %v "+
+			"confirmations; %v are required", tx.Confirmations,
+			minConfirmations))
+	}
+	return errs
+}
+
+// processVerifyUserPaymentTx verifies that the provided transaction pays the
+// registration fee of the user and marks the user as paid if it does.  A
+// breakdown of the transaction is returned either way so that the user can
+// see why a payment was not accepted.
+func (p *politeiawww) processVerifyUserPaymentTx(u *user.User, vupt v1.VerifyUserPaymentTx) (*v1.VerifyUserPaymentTxReply, error) {
+	b, err := hex.DecodeString(vupt.TxID)
+	if err != nil || len(b) != chainhash.HashSize {
+		return nil, v1.UserError{
+			ErrorCode:    v1.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid txid"},
+		}
+	}
+
+	reply := v1.VerifyUserPaymentTxReply{
+		TxID:               vupt.TxID,
+		PaywallAddress:     u.NewUserPaywallAddress,
+		PaywallAmount:      u.NewUserPaywallAmount,
+		PaywallTxNotBefore: u.NewUserPaywallTxNotBefore,
+		MinConfirmations:   p.cfg.MinConfirmationsRequired,
+	}
+	if p.HasUserPaid(u) {
+		reply.HasPaid = true
+		return &reply, nil
+	}
+
+	// Look up the tx among all txs that were sent to the paywall
+	// address so that a tx that was sent too early can be reported
+	// as such.
+	txs, err := util.FetchTxsForAddressNotBefore(u.NewUserPaywallAddress, 0)
+	if err != nil {
+		log.Errorf("processVerifyUserPaymentTx: "+
+			"FetchTxsForAddressNotBefore %v: %v", u.ID, err)
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusCannotVerifyPayment,
+		}
+	}
+	var (
+		tx    util.TxDetails
+		found bool
+	)
+	for _, v := range txs {
+		if v.TxID == vupt.TxID {
+			tx = v
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusPaymentTxNotFound,
+		}
+	}
+
+	reply.AmountPaid = tx.Amount
+	reply.Timestamp = tx.Timestamp
+	reply.Confirmations = tx.Confirmations
+	reply.Errors = paymentTxErrors(tx, u.NewUserPaywallAmount,
+		u.NewUserPaywallTxNotBefore, p.cfg.MinConfirmationsRequired)
+	if len(reply.Errors) > 0 {
+		return &reply, nil
+	}
+
+	err = p.updateUserAsPaid(u, tx.TxID)
+	if err != nil {
+		return nil, err
+	}
+	reply.HasPaid = true
+
+	return &reply, nil
+}
+
 // removeUsersFromPool removes provided user IDs from the the poll pool.
 //
 // This function must be called WITHOUT the mutex held.
@@ -1931,6 +2410,11 @@ func (p *politeiawww) checkForUserPayments(pool map[uuid.UUID]paywallPoolMember)
 	var userIDsToRemove []uuid.UUID
 
 	for userID, poolMember := range pool {
+		if poolMember.paywallType != paywallTypeUser ||
+			!paywallPollDue(poolMember, time.Now()) {
+			continue
+		}
+
 		u, err := p.db.UserGetById(userID)
 		if err != nil {
 			if err == user.ErrShutdown {
@@ -1944,10 +2428,6 @@ func (p *politeiawww) checkForUserPayments(pool map[uuid.UUID]paywallPoolMember)
 			continue
 		}
 
-		if poolMember.paywallType != paywallTypeUser {
-			continue
-		}
-
 		log.Tracef("Checking the user paywall address for user %v...",
 			u.Email)
 
@@ -1971,6 +2451,8 @@ func (p *politeiawww) checkForUserPayments(pool map[uuid.UUID]paywallPoolMember)
 			p.cfg.MinConfirmationsRequired)
 		if err != nil {
 			log.Errorf("cannot fetch tx: %v\n", err)
+			p.setPaywallPoolMember(userID,
+				p.backoffPaywallPoll(poolMember, time.Now()))
 			continue
 		}
 
@@ -1992,6 +2474,9 @@ func (p *politeiawww) checkForUserPayments(pool map[uuid.UUID]paywallPoolMember)
 			// Remove this user from the in-memory pool.
 			userIDsToRemove = append(userIDsToRemove, userID)
 			log.Tracef("  removing from polling, user just paid")
+		} else {
+			p.setPaywallPoolMember(userID,
+				p.backoffPaywallPoll(poolMember, time.Now()))
 		}
 
 		time.Sleep(paywallCheckGap)
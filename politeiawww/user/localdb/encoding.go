@@ -79,3 +79,25 @@ func DecodeUser(payload []byte) (*user.User, error) {
 
 	return &u, nil
 }
+
+// EncodeProposalBilling encodes ProposalBilling into a JSON byte slice.
+func EncodeProposalBilling(b user.ProposalBilling) ([]byte, error) {
+	payload, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// DecodeProposalBilling decodes a JSON byte slice into a ProposalBilling.
+func DecodeProposalBilling(payload []byte) (*user.ProposalBilling, error) {
+	var b user.ProposalBilling
+
+	err := json.Unmarshal(payload, &b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
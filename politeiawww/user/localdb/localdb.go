@@ -10,12 +10,17 @@ import (
 	"github.com/decred/politeia/politeiawww/user"
 	"github.com/google/uuid"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const (
 	UserdbPath              = "users"
 	LastPaywallAddressIndex = "lastpaywallindex"
 
+	// ProposalBillingPrefix is prepended to the censorship token to form
+	// the key of a proposal billing record.
+	ProposalBillingPrefix = "proposalbilling:"
+
 	UserVersion    uint32 = 1
 	UserVersionKey        = "userversion"
 )
@@ -43,7 +48,8 @@ type Version struct {
 // and false otherwise. This is helpful when iterating the user records
 // because the DB contains some non-user records.
 func isUserRecord(key string) bool {
-	return key != UserVersionKey && key != LastPaywallAddressIndex
+	return key != UserVersionKey && key != LastPaywallAddressIndex &&
+		!strings.HasPrefix(key, ProposalBillingPrefix)
 }
 
 // Store new user.
@@ -240,6 +246,80 @@ func (l *localdb) UserUpdate(u user.User) error {
 	return l.userdb.Put([]byte(u.Email), payload, nil)
 }
 
+// UserUpdateEmail updates an existing user whose email address has changed.
+// The user record is moved from the old email key to the new email key that
+// is set on the user.  ErrUserExists is returned if the new email address is
+// already in use.
+//
+// UserUpdateEmail satisfies the backend interface.
+func (l *localdb) UserUpdateEmail(oldEmail string, u user.User) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	log.Debugf("UserUpdateEmail: %v %v", oldEmail, u)
+
+	if err := checkmail.ValidateFormat(u.Email); err != nil {
+		return user.ErrInvalidEmail
+	}
+
+	// Make sure user already exists
+	exists, err := l.userdb.Has([]byte(oldEmail), nil)
+	if err != nil {
+		return err
+	} else if !exists {
+		return user.ErrUserNotFound
+	}
+
+	// Make sure the new email is not in use
+	exists, err = l.userdb.Has([]byte(u.Email), nil)
+	if err != nil {
+		return err
+	} else if exists {
+		return user.ErrUserExists
+	}
+
+	payload, err := EncodeUser(u)
+	if err != nil {
+		return err
+	}
+
+	// Move the user record atomically
+	batch := new(leveldb.Batch)
+	batch.Delete([]byte(oldEmail))
+	batch.Put([]byte(u.Email), payload)
+	return l.userdb.Write(batch, nil)
+}
+
+// UserDelete permanently deletes the user record with the given email
+// address.
+//
+// UserDelete satisfies the backend interface.
+func (l *localdb) UserDelete(email string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	log.Debugf("UserDelete: %v", email)
+
+	// Make sure user exists
+	key := []byte(email)
+	exists, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	} else if !exists {
+		return user.ErrUserNotFound
+	}
+
+	return l.userdb.Delete(key, nil)
+}
+
 // Update existing user.
 //
 // UserUpdate satisfies the backend interface.
@@ -274,6 +354,55 @@ func (l *localdb) AllUsers(callbackFn func(u *user.User)) error {
 	return iter.Error()
 }
 
+// ProposalBillingSave creates or updates the billing record of a proposal.
+//
+// ProposalBillingSave satisfies the backend interface.
+func (l *localdb) ProposalBillingSave(b user.ProposalBilling) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	log.Debugf("ProposalBillingSave: %v", b.Token)
+
+	payload, err := EncodeProposalBilling(b)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(ProposalBillingPrefix+b.Token), payload, nil)
+}
+
+// AllProposalBillings iterates all proposal billing records.
+//
+// AllProposalBillings satisfies the backend interface.
+func (l *localdb) AllProposalBillings(callbackFn func(b *user.ProposalBilling)) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	log.Debugf("AllProposalBillings")
+
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(ProposalBillingPrefix)), nil)
+	for iter.Next() {
+		b, err := DecodeProposalBilling(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+
+		callbackFn(b)
+	}
+	iter.Release()
+
+	return iter.Error()
+}
+
 // Close shuts down the database.  All interface functions MUST return with
 // errShutdown if the backend is shutting down.
 //
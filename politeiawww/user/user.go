@@ -106,12 +106,17 @@ type User struct {
 	ResendNewUserVerificationExpiry int64     // Resend request for new user registration verification expiration
 	UpdateKeyVerificationToken      []byte    // Verification token for updating keypair
 	UpdateKeyVerificationExpiry     int64     // Verification expiration
+	ResendUpdateKeyExpiry           int64     // Resend request for update key verification expiration
 	ResetPasswordVerificationToken  []byte    // Reset password token
 	ResetPasswordVerificationExpiry int64     // Reset password token expiration
+	NewEmail                        string    // New email address awaiting verification
+	ChangeEmailVerificationToken    []byte    // Change email verification token
+	ChangeEmailVerificationExpiry   int64     // Change email verification expiration
 	LastLoginTime                   int64     // Unix timestamp of when the user last logged in
 	FailedLoginAttempts             uint64    // Number of failed login a user has made in a row
 	Deactivated                     bool      // Whether the account is deactivated or not
 	EmailNotifications              uint64    // Notify the user via emails
+	Locale                          string    // Locale that emails are sent in
 
 	// Access times for proposal comments that have been accessed by the user.
 	// Each string represents a proposal token, and the int64 represents the
@@ -142,6 +147,24 @@ type User struct {
 	SpentProposalCredits []ProposalCredit
 }
 
+// ProposalSpend is an amount that was spent against the approved budget of a
+// proposal.
+type ProposalSpend struct {
+	ID          string    // Unique spend ID
+	Amount      uint64    // Amount spent in US cents
+	Description string    // What the amount was spent on
+	AdminID     uuid.UUID // ID of the admin that recorded the spend
+	Timestamp   int64     // Unix timestamp of when the spend was recorded
+}
+
+// ProposalBilling is the approved budget of a proposal along with the spends
+// that were recorded against it.
+type ProposalBilling struct {
+	Token  string          // Censorship token
+	Budget uint64          // Approved budget in US cents
+	Spends []ProposalSpend // Spends, oldest first
+}
+
 // Database interface that is required by the web server.
 type Database interface {
 	// User functions
@@ -150,8 +173,14 @@ type Database interface {
 	UserGetById(uuid.UUID) (*User, error)    // Return user record given its id
 	UserNew(User) error                      // Add new user
 	UserUpdate(User) error                   // Update existing user
+	UserUpdateEmail(string, User) error      // Update existing user and move it from the old email
+	UserDelete(string) error                 // Delete user record, key is email
 	AllUsers(callbackFn func(u *User)) error // Iterate all users
 
+	// Proposal billing functions
+	ProposalBillingSave(ProposalBilling) error                     // Create or update proposal billing record
+	AllProposalBillings(callbackFn func(b *ProposalBilling)) error // Iterate all proposal billing records
+
 	// Close performs cleanup of the backend.
 	Close() error
 }
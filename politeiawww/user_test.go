@@ -5,12 +5,21 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/golangcrypto/bcrypt"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
 )
 
 func TestValidatePubkey(t *testing.T) {
@@ -179,7 +188,7 @@ func TestValidatePassword(t *testing.T) {
 	// Run tests
 	for _, v := range tests {
 		t.Run(v.name, func(t *testing.T) {
-			err := validatePassword(v.password)
+			err := p.validatePassword(v.password)
 			got := errToStr(err)
 			want := errToStr(v.want)
 			if got != want {
@@ -190,6 +199,108 @@ func TestValidatePassword(t *testing.T) {
 	}
 }
 
+func TestValidatePasswordPolicy(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	bannedFile := filepath.Join(p.cfg.DataDir, "bannedpasswords.txt")
+	err := ioutil.WriteFile(bannedFile,
+		[]byte("# Banned passwords\ncorrecthorse\n\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.cfg.PasswordBannedFile = bannedFile
+	p.cfg.PasswordRequireUpper = true
+	p.cfg.PasswordRequireLower = true
+	p.cfg.PasswordRequireDigit = true
+	p.cfg.PasswordRequireSymbol = true
+	p.cfg.PasswordMinStrength = 3
+	err = p.initPasswordPolicy()
+	if err != nil {
+		t.Fatalf("initPasswordPolicy: %v", err)
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{"missing upper", "tr0ub4dor&3",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusPasswordMissingUpper,
+			}},
+		{"missing lower", "TR0UB4DOR&3",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusPasswordMissingLower,
+			}},
+		{"missing digit", "Troubador&!",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusPasswordMissingDigit,
+			}},
+		{"missing symbol", "Tr0ub4dor33",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusPasswordMissingSymbol,
+			}},
+		{"too weak", "Password1!",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusPasswordTooWeak,
+			}},
+		{"banned", "CorrectHorse",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusPasswordBanned,
+			}},
+		{"digest", digestPassword("Tr0ub4dor&3"),
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusMalformedPassword,
+			}},
+		{"valid", "Tr0ub4dor&3", nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := p.validatePassword(v.password)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+
+	// The estimated strength is returned in the error context
+	err = p.validatePassword("Password1!")
+	want := []string{fmt.Sprintf("strength %v/%v",
+		util.PasswordStrength("Password1!"), util.PasswordStrengthMax)}
+	if e, ok := err.(v1.UserError); !ok ||
+		!reflect.DeepEqual(e.ErrorContext, want) {
+		t.Errorf("got error %v, want context %v", err, want)
+	}
+}
+
+func TestHashNewPassword(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// New passwords are sent in plain text but verified against the
+	// digest that clients send when they log in
+	hashed, err := p.hashNewPassword("Tr0ub4dor&3")
+	if err != nil {
+		t.Fatalf("hashNewPassword: %v", err)
+	}
+	err = bcrypt.CompareHashAndPassword(hashed,
+		[]byte(digestPassword("Tr0ub4dor&3")))
+	if err != nil {
+		t.Errorf("digest does not match: %v", err)
+	}
+	err = bcrypt.CompareHashAndPassword(hashed, []byte("Tr0ub4dor&3"))
+	if err == nil {
+		t.Errorf("plain text password matches, want digest only")
+	}
+}
+
 func TestProcessUserDetails(t *testing.T) {
 	p := newTestPoliteiawww(t)
 	defer cleanupTestPoliteiawww(t, p)
@@ -264,6 +375,95 @@ func TestProcessUserDetails(t *testing.T) {
 	}
 }
 
+func TestProcessBatchUserDetails(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	u1, _ := newUser(t, p, false)
+	u2, _ := newUser(t, p, false)
+	admin, _ := newUser(t, p, true)
+
+	full1 := convertWWWUserFromDatabaseUser(u1)
+	full2 := convertWWWUserFromDatabaseUser(u2)
+	public1 := filterUserPublicFields(full1)
+	public2 := filterUserPublicFields(full2)
+
+	ids := []string{
+		full1.ID,
+		full2.ID,
+		full1.ID,
+		"aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa",
+	}
+	tooMany := make([]string, v1.UserDetailsBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = full1.ID
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name        string
+		userIDs     []string
+		sessionUser *user.User
+		want        map[string]v1.User
+		wantErr     error
+	}{
+		{"empty batch", nil, nil, nil,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			}},
+
+		{"batch too large", tooMany, nil, nil,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			}},
+
+		{"invalid uuid", []string{"invalid"}, nil, nil,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidUUID,
+			}},
+
+		{"logged out", ids, nil,
+			map[string]v1.User{
+				full1.ID: public1,
+				full2.ID: public2,
+			}, nil},
+
+		{"user requesting their own details", ids, u1,
+			map[string]v1.User{
+				full1.ID: full1,
+				full2.ID: public2,
+			}, nil},
+
+		{"admin", ids, admin,
+			map[string]v1.User{
+				full1.ID: full1,
+				full2.ID: full2,
+			}, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			reply, err := p.processBatchUserDetails(
+				v1.BatchUserDetails{UserIDs: v.userIDs},
+				v.sessionUser)
+			got := errToStr(err)
+			want := errToStr(v.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(reply.Users, v.want) {
+				t.Errorf("got users %v, want %v", reply.Users,
+					v.want)
+			}
+		})
+	}
+}
+
 func TestProcessEditUser(t *testing.T) {
 	p := newTestPoliteiawww(t)
 	defer cleanupTestPoliteiawww(t, p)
@@ -330,3 +530,817 @@ func TestProcessEditUser(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessEditUserLocale(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	user, _ := newUser(t, p, false)
+
+	// Setup test cases
+	tests := []struct {
+		name    string
+		locale  string
+		want    string
+		wantErr error
+	}{
+		{"language", "fr", "fr", nil},
+		{"language and region", "pt-BR", "pt-BR", nil},
+		{"reset", "", "", nil},
+		{"invalid locale", "../fr", "",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidLocale,
+			}},
+	}
+
+	// Run test cases
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := p.processEditUser(&v1.EditUser{
+				Locale: &test.locale,
+			}, user)
+			got := errToStr(err)
+			want := errToStr(test.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+
+			u, err := p.db.UserGet(user.Email)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if u.Locale != test.want {
+				t.Errorf("got locale %q, want %q", u.Locale, test.want)
+			}
+		})
+	}
+}
+
+func TestProcessChangeEmail(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// The password of a test user is the same as its username.
+	usr, _ := newUser(t, p, false)
+	other, _ := newUser(t, p, false)
+
+	// Setup tests
+	var tests = []struct {
+		name     string
+		newEmail string
+		password string
+		want     error
+	}{
+		{"wrong password", "new@example.com", "wrongpassword",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidEmailOrPassword,
+			}},
+		{"malformed email", "example.com", usr.Username,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusMalformedEmail,
+			}},
+		{"duplicate email", other.Email, usr.Username,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusDuplicateEmail,
+			}},
+		{"current email", usr.Email, usr.Username,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusDuplicateEmail,
+			}},
+		{"success", "New@Example.com", usr.Username, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processChangeEmail(usr, v1.ChangeEmail{
+				NewEmail: v.newEmail,
+				Password: v.password,
+			})
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+
+	// The email must not change until it has been verified
+	u, err := p.db.UserGet(usr.Email)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if u.NewEmail != "new@example.com" {
+		t.Fatalf("got new email %v, want new@example.com", u.NewEmail)
+	}
+}
+
+func TestProcessChangeEmailNotifiesOldAddress(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// Capture the emails instead of sending them
+	sent := make(map[string]string) // [toAddress]body
+	p.smtp = &smtp{
+		sentTo: func(subject, body, toAddress string) {
+			sent[toAddress] = body
+		},
+	}
+
+	// The password of a test user is the same as its username.
+	usr, _ := newUser(t, p, false)
+	oldEmail := usr.Email
+	_, err := p.processChangeEmail(usr, v1.ChangeEmail{
+		NewEmail: "new@example.com",
+		Password: usr.Username,
+	})
+	if err != nil {
+		t.Fatalf("processChangeEmail: %v", err)
+	}
+
+	if len(sent) != 2 {
+		t.Fatalf("got %v emails, want 2", len(sent))
+	}
+	if _, ok := sent["new@example.com"]; !ok {
+		t.Errorf("verification link was not sent to the new address")
+	}
+	body, ok := sent[oldEmail]
+	if !ok {
+		t.Fatalf("old address %v was not notified", oldEmail)
+	}
+	if !strings.Contains(body, "new@example.com") {
+		t.Errorf("notice does not contain the new address: %v", body)
+	}
+}
+
+func TestProcessVerifyChangeEmail(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// changeEmail requests an email change for a new user and
+	// returns the user and the verification token.
+	changeEmail := func(newEmail string) (*user.User, string) {
+		t.Helper()
+
+		usr, _ := newUser(t, p, false)
+		cer, err := p.processChangeEmail(usr, v1.ChangeEmail{
+			NewEmail: newEmail,
+			Password: usr.Username,
+		})
+		if err != nil {
+			t.Fatalf("processChangeEmail: %v", err)
+		}
+		return usr, cer.VerificationToken
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		usr, _ := changeEmail("invalid@example.com")
+		_, err := p.processVerifyChangeEmail(usr, v1.VerifyChangeEmail{
+			VerificationToken: hex.EncodeToString([]byte("invalid")),
+		})
+		want := v1.UserError{
+			ErrorCode: v1.ErrorStatusVerificationTokenInvalid,
+		}
+		if errToStr(err) != errToStr(want) {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		usr, token := changeEmail("expired@example.com")
+		usr.ChangeEmailVerificationExpiry = time.Now().Unix() - 1
+		_, err := p.processVerifyChangeEmail(usr, v1.VerifyChangeEmail{
+			VerificationToken: token,
+		})
+		want := v1.UserError{
+			ErrorCode: v1.ErrorStatusVerificationTokenExpired,
+		}
+		if errToStr(err) != errToStr(want) {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	})
+
+	t.Run("email registered after request", func(t *testing.T) {
+		usr, token := changeEmail("taken@example.com")
+		err := p.db.UserNew(user.User{
+			Email:    "taken@example.com",
+			Username: "taken",
+		})
+		if err != nil {
+			t.Fatalf("UserNew: %v", err)
+		}
+		_, err = p.processVerifyChangeEmail(usr, v1.VerifyChangeEmail{
+			VerificationToken: token,
+		})
+		want := v1.UserError{
+			ErrorCode: v1.ErrorStatusDuplicateEmail,
+		}
+		if errToStr(err) != errToStr(want) {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		usr, token := changeEmail("success@example.com")
+		oldEmail := usr.Email
+		_, err := p.processVerifyChangeEmail(usr, v1.VerifyChangeEmail{
+			VerificationToken: token,
+		})
+		if err != nil {
+			t.Fatalf("got error %v, want nil", err)
+		}
+
+		// The user record is moved to the new email
+		_, err = p.db.UserGet(oldEmail)
+		if err != user.ErrUserNotFound {
+			t.Fatalf("old email: got error %v, want %v", err,
+				user.ErrUserNotFound)
+		}
+		u, err := p.db.UserGet("success@example.com")
+		if err != nil {
+			t.Fatalf("new email: %v", err)
+		}
+		if u.ID != usr.ID || u.NewEmail != "" ||
+			u.ChangeEmailVerificationToken != nil {
+			t.Fatalf("unexpected user record %v", u)
+		}
+	})
+}
+
+func TestProcessVerifyResetPassword(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+	rpr, err := p.processResetPassword(v1.ResetPassword{
+		Email: usr.Email,
+	})
+	if err != nil {
+		t.Fatalf("processResetPassword: %v", err)
+	}
+	token := rpr.VerificationToken
+
+	// Setup tests
+	var tests = []struct {
+		name  string
+		email string
+		token string
+		want  error
+	}{
+		{"unknown user", "unknown@example.com", token,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusVerificationTokenInvalid,
+			}},
+		{"malformed token", usr.Email, "zz",
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusVerificationTokenInvalid,
+			}},
+		{"wrong token", usr.Email, hex.EncodeToString([]byte("invalid")),
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusVerificationTokenInvalid,
+			}},
+		{"valid token", usr.Email, token, nil},
+		{"token is not consumed", usr.Email, token, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processVerifyResetPassword(
+				v1.VerifyResetPassword{
+					Email:             v.email,
+					VerificationToken: v.token,
+				})
+			if errToStr(err) != errToStr(v.want) {
+				t.Fatalf("got error %v, want %v", err, v.want)
+			}
+		})
+	}
+
+	// The token can still be used to reset the password
+	_, err = p.processResetPassword(v1.ResetPassword{
+		Email:             usr.Email,
+		VerificationToken: token,
+		NewPassword:       "newpassword",
+	})
+	if err != nil {
+		t.Fatalf("processResetPassword: %v", err)
+	}
+
+	// The token is consumed by the password reset
+	_, err = p.processVerifyResetPassword(v1.VerifyResetPassword{
+		Email:             usr.Email,
+		VerificationToken: token,
+	})
+	want := v1.UserError{
+		ErrorCode: v1.ErrorStatusVerificationTokenInvalid,
+	}
+	if errToStr(err) != errToStr(want) {
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+
+	// An expired token is reported as expired
+	rpr, err = p.processResetPassword(v1.ResetPassword{
+		Email: usr.Email,
+	})
+	if err != nil {
+		t.Fatalf("processResetPassword: %v", err)
+	}
+	u, err := p.db.UserGet(usr.Email)
+	if err != nil {
+		t.Fatalf("UserGet: %v", err)
+	}
+	u.ResetPasswordVerificationExpiry = time.Now().Unix() - 1
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		t.Fatalf("UserUpdate: %v", err)
+	}
+	_, err = p.processVerifyResetPassword(v1.VerifyResetPassword{
+		Email:             usr.Email,
+		VerificationToken: rpr.VerificationToken,
+	})
+	want = v1.UserError{
+		ErrorCode: v1.ErrorStatusVerificationTokenExpired,
+	}
+	if errToStr(err) != errToStr(want) {
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+}
+
+func TestProcessUsernameAvailable(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	u, _ := newUser(t, p, false)
+
+	var tests = []struct {
+		name     string
+		username string
+		want     v1.UsernameAvailableReply
+	}{
+		{"available", "availableusername",
+			v1.UsernameAvailableReply{Available: true}},
+		{"taken", u.Username,
+			v1.UsernameAvailableReply{
+				Reason: v1.ErrorStatusDuplicateUsername,
+			}},
+		{"taken with different case", strings.ToUpper(u.Username),
+			v1.UsernameAvailableReply{
+				Reason: v1.ErrorStatusDuplicateUsername,
+			}},
+		{"malformed", "a",
+			v1.UsernameAvailableReply{
+				Reason: v1.ErrorStatusMalformedUsername,
+			}},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			uar, err := p.processUsernameAvailable(v1.UsernameAvailable{
+				Username: v.username,
+			})
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if *uar != v.want {
+				t.Errorf("got %+v, want %+v", *uar, v.want)
+			}
+		})
+	}
+}
+
+func TestProcessResendUpdateUserKey(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// updateUserKey requests a key update for a new user and returns
+	// the user and the verification token.
+	updateUserKey := func() (*user.User, string) {
+		t.Helper()
+
+		usr, _ := newUser(t, p, false)
+		id, err := identity.New()
+		if err != nil {
+			t.Fatalf("identity.New: %v", err)
+		}
+		uukr, err := p.processUpdateUserKey(usr, v1.UpdateUserKey{
+			PublicKey: hex.EncodeToString(id.Public.Key[:]),
+		})
+		if err != nil {
+			t.Fatalf("processUpdateUserKey: %v", err)
+		}
+		return usr, uukr.VerificationToken
+	}
+
+	t.Run("no pending key update", func(t *testing.T) {
+		usr, _ := newUser(t, p, false)
+		_, err := p.processResendUpdateUserKey(usr)
+		want := v1.UserError{
+			ErrorCode: v1.ErrorStatusNoPendingUpdateUserKey,
+		}
+		if errToStr(err) != errToStr(want) {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	})
+
+	t.Run("unexpired token", func(t *testing.T) {
+		usr, token := updateUserKey()
+		ruukr, err := p.processResendUpdateUserKey(usr)
+		if err != nil {
+			t.Fatalf("got error %v, want nil", err)
+		}
+		if ruukr.VerificationToken != token {
+			t.Fatalf("got token %v, want %v", ruukr.VerificationToken,
+				token)
+		}
+
+		// Resending again is not allowed until the token expires
+		_, err = p.processResendUpdateUserKey(usr)
+		want := v1.UserError{
+			ErrorCode: v1.ErrorStatusVerificationTokenUnexpired,
+		}
+		if errToStr(err) != errToStr(want) {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		usr, token := updateUserKey()
+		usr.UpdateKeyVerificationExpiry = time.Now().Unix() - 1
+		ruukr, err := p.processResendUpdateUserKey(usr)
+		if err != nil {
+			t.Fatalf("got error %v, want nil", err)
+		}
+		if ruukr.VerificationToken == token {
+			t.Fatalf("expired token was not regenerated")
+		}
+
+		// The new token is saved
+		u, err := p.db.UserGetById(usr.ID)
+		if err != nil {
+			t.Fatalf("UserGetById: %v", err)
+		}
+		if hex.EncodeToString(u.UpdateKeyVerificationToken) !=
+			ruukr.VerificationToken {
+			t.Fatalf("new token was not saved")
+		}
+	})
+}
+
+func TestProcessResendUserEmail(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+	usr, _ := newUser(t, p, false)
+
+	// Setup a user that has not verified their email
+	unverified, _ := newUser(t, p, false)
+	token, expiry, err := generateVerificationTokenAndExpiry()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	unverified.NewUserVerificationToken = token
+	unverified.NewUserVerificationExpiry = expiry
+	err = p.db.UserUpdate(*unverified)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// Setup a deactivated user
+	deactivated, _ := newUser(t, p, false)
+	deactivated.Deactivated = true
+	err = p.db.UserUpdate(*deactivated)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var tests = []struct {
+		name    string
+		rue     v1.ResendUserEmail
+		wantErr error
+	}{
+		{"blank reason",
+			v1.ResendUserEmail{
+				UserID: usr.ID.String(),
+				Email:  v1.UserEmailResetPassword,
+				Reason: " ",
+			},
+			v1.UserError{
+				ErrorCode:    v1.ErrorStatusInvalidInput,
+				ErrorContext: []string{"reason cannot be blank"},
+			}},
+
+		{"invalid email type",
+			v1.ResendUserEmail{
+				UserID: usr.ID.String(),
+				Email:  v1.UserEmailInvalid,
+				Reason: "reason",
+			},
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidUserEmailType,
+			}},
+
+		{"deactivated user",
+			v1.ResendUserEmail{
+				UserID: deactivated.ID.String(),
+				Email:  v1.UserEmailResetPassword,
+				Reason: "reason",
+			},
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusUserDeactivated,
+			}},
+
+		{"email already verified",
+			v1.ResendUserEmail{
+				UserID: usr.ID.String(),
+				Email:  v1.UserEmailNewUserVerification,
+				Reason: "reason",
+			},
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusEmailAlreadyVerified,
+			}},
+
+		{"new user verification",
+			v1.ResendUserEmail{
+				UserID: unverified.ID.String(),
+				Email:  v1.UserEmailNewUserVerification,
+				Reason: "reason",
+			}, nil},
+
+		{"reset password",
+			v1.ResendUserEmail{
+				UserID: usr.ID.String(),
+				Email:  v1.UserEmailResetPassword,
+				Reason: "reason",
+			}, nil},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			before, err := p.getUserByIDStr(v.rue.UserID)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			_, err = p.processResendUserEmail(&v.rue, admin)
+			got := errToStr(err)
+			want := errToStr(v.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			// A new token is generated and saved
+			after, err := p.db.UserGetById(before.ID)
+			if err != nil {
+				t.Fatalf("UserGetById: %v", err)
+			}
+			switch v.rue.Email {
+			case v1.UserEmailNewUserVerification:
+				if after.NewUserVerificationToken == nil ||
+					bytes.Equal(after.NewUserVerificationToken,
+						before.NewUserVerificationToken) {
+					t.Fatalf("new user verification token was " +
+						"not regenerated")
+				}
+			case v1.UserEmailResetPassword:
+				if after.ResetPasswordVerificationToken == nil ||
+					bytes.Equal(after.ResetPasswordVerificationToken,
+						before.ResetPasswordVerificationToken) {
+					t.Fatalf("reset password token was not " +
+						"regenerated")
+				}
+			}
+		})
+	}
+}
+
+func TestProcessUsersPublicKey(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	newUser(t, p, false)
+	usr, oldID := newUser(t, p, false)
+	oldKey := hex.EncodeToString(oldID.Public.Key[:])
+
+	// Rotate the user's key.  The old key must still be associated
+	// with the user.
+	newID, err := identity.New()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	newKey := hex.EncodeToString(newID.Public.Key[:])
+	p.setUserPubkeyAssociaton(usr, newKey)
+
+	unknownID, err := identity.New()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	unknownKey := hex.EncodeToString(unknownID.Public.Key[:])
+
+	var tests = []struct {
+		name      string
+		users     v1.Users
+		wantUsers []string
+		wantErr   error
+	}{
+		{"current key", v1.Users{PublicKey: newKey},
+			[]string{usr.ID.String()}, nil},
+		{"old key", v1.Users{PublicKey: oldKey},
+			[]string{usr.ID.String()}, nil},
+		{"unknown key", v1.Users{PublicKey: unknownKey},
+			[]string{}, nil},
+		{"key and other username", v1.Users{
+			PublicKey: oldKey,
+			Username:  "nomatch",
+		}, []string{}, nil},
+		{"invalid key", v1.Users{PublicKey: "zz"}, nil,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidPublicKey,
+			}},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			ur, err := p.processUsers(&v.users)
+			got := errToStr(err)
+			want := errToStr(v.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			if ur.TotalUsers != 2 {
+				t.Errorf("got %v total users, want 2", ur.TotalUsers)
+			}
+			ids := make([]string, 0, len(ur.Users))
+			for _, u := range ur.Users {
+				ids = append(ids, u.ID)
+			}
+			if !reflect.DeepEqual(ids, v.wantUsers) {
+				t.Errorf("got users %v, want %v", ids, v.wantUsers)
+			}
+		})
+	}
+}
+
+func TestPaymentTxErrors(t *testing.T) {
+	var tests = []struct {
+		name     string
+		tx       util.TxDetails
+		wantErrs int
+	}{
+		{"valid", util.TxDetails{
+			Amount:        10,
+			Timestamp:     100,
+			Confirmations: 2,
+		}, 0},
+		{"amount too low", util.TxDetails{
+			Amount:        9,
+			Timestamp:     100,
+			Confirmations: 2,
+		}, 1},
+		{"sent too early", util.TxDetails{
+			Amount:        10,
+			Timestamp:     99,
+			Confirmations: 2,
+		}, 1},
+		{"not enough confirmations", util.TxDetails{
+			Amount:        10,
+			Timestamp:     100,
+			Confirmations: 1,
+		}, 1},
+		{"all requirements missed", util.TxDetails{
+			Amount:        9,
+			Timestamp:     99,
+			Confirmations: 1,
+		}, 3},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			errs := paymentTxErrors(v.tx, 10, 100, 2)
+			if len(errs) != v.wantErrs {
+				t.Errorf("got errors %v, want %v errors", errs,
+					v.wantErrs)
+			}
+		})
+	}
+}
+
+func TestProcessVerifyUserPaymentTxInvalid(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+
+	var tests = []struct {
+		name string
+		txid string
+	}{
+		{"empty", ""},
+		{"not hex", strings.Repeat("z", 64)},
+		{"too short", strings.Repeat("ab", 31)},
+		{"too long", strings.Repeat("ab", 33)},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processVerifyUserPaymentTx(usr,
+				v1.VerifyUserPaymentTx{TxID: v.txid})
+			got := errToStr(err)
+			want := errToStr(v1.UserError{
+				ErrorCode:    v1.ErrorStatusInvalidInput,
+				ErrorContext: []string{"invalid txid"},
+			})
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestProcessImpersonateUser(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+	otherAdmin, _ := newUser(t, p, true)
+	usr, _ := newUser(t, p, false)
+
+	// Setup a deactivated user
+	deactivated, _ := newUser(t, p, false)
+	deactivated.Deactivated = true
+	err := p.db.UserUpdate(*deactivated)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var tests = []struct {
+		name    string
+		iu      v1.ImpersonateUser
+		wantErr error
+	}{
+		{"blank reason",
+			v1.ImpersonateUser{
+				UserID: usr.ID.String(),
+				Reason: " ",
+			},
+			v1.UserError{
+				ErrorCode:    v1.ErrorStatusInvalidInput,
+				ErrorContext: []string{"reason cannot be blank"},
+			}},
+
+		{"admin user",
+			v1.ImpersonateUser{
+				UserID: otherAdmin.ID.String(),
+				Reason: "reason",
+			},
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusUserActionNotAllowed,
+			}},
+
+		{"deactivated user",
+			v1.ImpersonateUser{
+				UserID: deactivated.ID.String(),
+				Reason: "reason",
+			},
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusUserDeactivated,
+			}},
+
+		{"success",
+			v1.ImpersonateUser{
+				UserID: usr.ID.String(),
+				Reason: "reason",
+			}, nil},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			reply, err := p.processImpersonateUser(&v.iu, admin)
+			got := errToStr(err)
+			want := errToStr(v.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			if reply.User.UserID != usr.ID.String() {
+				t.Errorf("got user %v, want %v", reply.User.UserID,
+					usr.ID)
+			}
+			if reply.User.ImpersonatedBy != admin.ID.String() {
+				t.Errorf("got impersonated by %v, want %v",
+					reply.User.ImpersonatedBy, admin.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+// userPurgeInterval is the interval at which unverified users are purged
+// when purging is enabled.
+const userPurgeInterval = 24 * time.Hour
+
+// isPurgeableUser returns whether the passed in user is an unverified user
+// that can be purged.  A user can be purged when the email address was never
+// verified and the verification token expired more than maxAge before now.
+// Admins and users that made a payment are never purged.  Whether the user
+// submitted proposals or comments is not checked.
+func isPurgeableUser(u *user.User, maxAge time.Duration, now time.Time) bool {
+	switch {
+	case u.Admin:
+		return false
+	case u.NewUserVerificationToken == nil:
+		// Email address has been verified
+		return false
+	case u.NewUserPaywallTx != "", len(u.UnspentProposalCredits) > 0,
+		len(u.SpentProposalCredits) > 0:
+		return false
+	}
+
+	expired := time.Unix(u.NewUserVerificationExpiry, 0)
+	return now.Sub(expired) > maxAge
+}
+
+// contentAuthors returns the ids of all users that have submitted a proposal
+// or a comment.
+func (p *politeiawww) contentAuthors() (map[string]struct{}, error) {
+	props, err := p.getAllProps()
+	if err != nil {
+		return nil, fmt.Errorf("getAllProps: %v", err)
+	}
+
+	authors := make(map[string]struct{}) // [userID]
+	for _, pr := range props {
+		authors[pr.UserId] = struct{}{}
+
+		// Comments can only be made on vetted proposals
+		if pr.State != www.PropStateVetted {
+			continue
+		}
+		pc, err := p.getPropComments(pr.CensorshipRecord.Token)
+		if err != nil {
+			return nil, fmt.Errorf("getPropComments %v: %v",
+				pr.CensorshipRecord.Token, err)
+		}
+		for _, c := range pc {
+			authors[c.UserID] = struct{}{}
+		}
+	}
+
+	return authors, nil
+}
+
+// deleteUnverifiedUser permanently deletes the passed in user if it can still
+// be purged.  The user is looked up again under the purge lock since it may
+// have been verified or sent a new verification token after the users were
+// iterated.  Users can only submit content once they have been verified, so
+// a user that is still purgeable has not submitted content since the content
+// authors were looked up.  Nil is returned if the user was not deleted.
+//
+// This function must be called WITHOUT the user purge lock held.
+func (p *politeiawww) deleteUnverifiedUser(candidate user.User, maxAge time.Duration) (*user.User, error) {
+	p.userPurgeMtx.Lock()
+	defer p.userPurgeMtx.Unlock()
+
+	u, err := p.db.UserGet(candidate.Email)
+	if err == user.ErrUserNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if u.ID != candidate.ID || !isPurgeableUser(u, maxAge, time.Now()) {
+		return nil, nil
+	}
+
+	err = p.db.UserDelete(u.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// purgeUnverifiedUsers permanently deletes all users that can be purged
+// according to isPurgeableUser and that have not submitted any proposals or
+// comments.  The users that were deleted are returned.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) purgeUnverifiedUsers() ([]www.AbridgedUser, error) {
+	maxAge := p.cfg.PurgeUnverifiedAge
+	now := time.Now()
+
+	var candidates []user.User
+	err := p.db.AllUsers(func(u *user.User) {
+		if isPurgeableUser(u, maxAge, now) {
+			candidates = append(candidates, *u)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []www.AbridgedUser{}, nil
+	}
+
+	authors, err := p.contentAuthors()
+	if err != nil {
+		return nil, err
+	}
+
+	purged := make([]www.AbridgedUser, 0, len(candidates))
+	for _, v := range candidates {
+		if _, ok := authors[v.ID.String()]; ok {
+			continue
+		}
+
+		u, err := p.deleteUnverifiedUser(v, maxAge)
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			continue
+		}
+
+		// Remove the user from the in-memory caches
+		p.removeUsersFromPool([]uuid.UUID{u.ID})
+		for _, id := range u.Identities {
+			p.removeUserPubkeyAssociaton(u, hex.EncodeToString(id.Key[:]))
+		}
+
+		log.Infof("Purged unverified user %v %v %v", u.ID, u.Username,
+			u.Email)
+
+		purged = append(purged, www.AbridgedUser{
+			ID:       u.ID.String(),
+			Email:    u.Email,
+			Username: u.Username,
+		})
+	}
+
+	return purged, nil
+}
+
+// checkForUnverifiedUsers purges the unverified users once every
+// userPurgeInterval.
+func (p *politeiawww) checkForUnverifiedUsers() {
+	ticker := time.NewTicker(userPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.purgeUnverifiedUsers()
+		if err != nil {
+			log.Errorf("purgeUnverifiedUsers: %v", err)
+		} else if len(purged) > 0 {
+			log.Infof("Purged %v unverified users", len(purged))
+		}
+
+		<-ticker.C
+	}
+}
+
+// initUserPurge starts the thread that purges the unverified users.  Purging
+// is disabled when the unverified user age is not set.
+func (p *politeiawww) initUserPurge() {
+	if p.cfg.PurgeUnverifiedAge == 0 {
+		log.Infof("Purging of unverified users is disabled")
+		return
+	}
+
+	go p.checkForUnverifiedUsers()
+}
+
+// processPurgeUnverifiedUsers purges the unverified users on behalf of an
+// admin.  Every purged user is recorded in the admin log.
+func (p *politeiawww) processPurgeUnverifiedUsers(adminUser *user.User) (*www.PurgeUnverifiedUsersReply, error) {
+	if p.cfg.PurgeUnverifiedAge == 0 {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusUserPurgeDisabled,
+		}
+	}
+
+	purged, err := p.purgeUnverifiedUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range purged {
+		err := p.logAdminAction(adminUser, fmt.Sprintf("purge unverified "+
+			"user,%v,%v", v.ID, v.Username))
+		if err != nil {
+			log.Errorf("could not log action to file: %v", err)
+		}
+	}
+
+	return &www.PurgeUnverifiedUsersReply{
+		Users: purged,
+	}, nil
+}
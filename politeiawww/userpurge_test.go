@@ -0,0 +1,185 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/cache"
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+// testPurgeCache is a records cache that only implements Inventory and does
+// not contain any records.
+type testPurgeCache struct {
+	cache.Cache
+}
+
+func (c *testPurgeCache) Inventory() ([]cache.Record, error) {
+	return []cache.Record{}, nil
+}
+
+func TestIsPurgeableUser(t *testing.T) {
+	now := time.Now()
+	maxAge := 24 * time.Hour
+	unverified := func(expired time.Duration) *user.User {
+		return &user.User{
+			NewUserVerificationToken:  []byte{0x01},
+			NewUserVerificationExpiry: now.Add(-expired).Unix(),
+		}
+	}
+
+	admin := unverified(2 * maxAge)
+	admin.Admin = true
+
+	paid := unverified(2 * maxAge)
+	paid.NewUserPaywallTx = "cleared_by_admin"
+
+	credits := unverified(2 * maxAge)
+	credits.UnspentProposalCredits = []user.ProposalCredit{{}}
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		u    *user.User
+		want bool
+	}{
+		{"verified user", &user.User{}, false},
+		{"token not expired", unverified(-time.Hour), false},
+		{"token expired recently", unverified(time.Hour), false},
+		{"token expired long ago", unverified(2 * maxAge), true},
+		{"admin", admin, false},
+		{"paid user", paid, false},
+		{"user with credits", credits, false},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := isPurgeableUser(v.u, maxAge, now)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestProcessPurgeUnverifiedUsers(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+	p.cache = &testPurgeCache{}
+
+	admin, _ := newUser(t, p, true)
+	verified, _ := newUser(t, p, false)
+	expired, id := newUser(t, p, false)
+	recent, _ := newUser(t, p, false)
+
+	// Purging is disabled by default in tests
+	_, err := p.processPurgeUnverifiedUsers(admin)
+	if got, want := errToStr(err),
+		www.ErrorStatus[www.ErrorStatusUserPurgeDisabled]; got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+	p.cfg.PurgeUnverifiedAge = 24 * time.Hour
+
+	// Mark users as unverified
+	expired.NewUserVerificationToken = []byte{0x01}
+	expired.NewUserVerificationExpiry = time.Now().Add(-48 * time.Hour).Unix()
+	recent.NewUserVerificationToken = []byte{0x01}
+	recent.NewUserVerificationExpiry = time.Now().Add(-time.Hour).Unix()
+	for _, u := range []*user.User{expired, recent} {
+		err := p.db.UserUpdate(*u)
+		if err != nil {
+			t.Fatalf("UserUpdate: %v", err)
+		}
+	}
+
+	pur, err := p.processPurgeUnverifiedUsers(admin)
+	if err != nil {
+		t.Fatalf("processPurgeUnverifiedUsers: %v", err)
+	}
+	if len(pur.Users) != 1 || pur.Users[0].ID != expired.ID.String() {
+		t.Fatalf("got purged users %v, want %v", pur.Users, expired.ID)
+	}
+
+	// The purged user is removed from the database and the caches
+	_, err = p.db.UserGet(expired.Email)
+	if err != user.ErrUserNotFound {
+		t.Fatalf("got error %v, want %v", err, user.ErrUserNotFound)
+	}
+	_, ok := p.getUserIDByPubKey(hex.EncodeToString(id.Public.Key[:]))
+	if ok {
+		t.Fatalf("public key of purged user is still associated")
+	}
+	for _, u := range []*user.User{admin, verified, recent} {
+		_, err := p.db.UserGet(u.Email)
+		if err != nil {
+			t.Fatalf("UserGet %v: %v", u.Username, err)
+		}
+	}
+
+	// Purging again does not delete any users
+	pur, err = p.processPurgeUnverifiedUsers(admin)
+	if err != nil {
+		t.Fatalf("processPurgeUnverifiedUsers: %v", err)
+	}
+	if len(pur.Users) != 0 {
+		t.Fatalf("got purged users %v, want none", pur.Users)
+	}
+}
+
+func TestDeleteUnverifiedUser(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	maxAge := 24 * time.Hour
+	u, _ := newUser(t, p, false)
+	u.NewUserVerificationToken = []byte{0x01}
+	u.NewUserVerificationExpiry = time.Now().Add(-2 * maxAge).Unix()
+	err := p.db.UserUpdate(*u)
+	if err != nil {
+		t.Fatalf("UserUpdate: %v", err)
+	}
+	candidate := *u
+
+	// The user is verified after it was selected for purging
+	u.NewUserVerificationToken = nil
+	u.NewUserVerificationExpiry = 0
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		t.Fatalf("UserUpdate: %v", err)
+	}
+	deleted, err := p.deleteUnverifiedUser(candidate, maxAge)
+	if err != nil {
+		t.Fatalf("deleteUnverifiedUser: %v", err)
+	}
+	if deleted != nil {
+		t.Fatalf("verified user was deleted")
+	}
+	_, err = p.db.UserGet(u.Email)
+	if err != nil {
+		t.Fatalf("UserGet: %v", err)
+	}
+
+	// A user that is still unverified is deleted
+	err = p.db.UserUpdate(candidate)
+	if err != nil {
+		t.Fatalf("UserUpdate: %v", err)
+	}
+	deleted, err = p.deleteUnverifiedUser(candidate, maxAge)
+	if err != nil {
+		t.Fatalf("deleteUnverifiedUser: %v", err)
+	}
+	if deleted == nil || deleted.ID != u.ID {
+		t.Fatalf("got deleted user %v, want %v", deleted, u.ID)
+	}
+	_, err = p.db.UserGet(u.Email)
+	if err != user.ErrUserNotFound {
+		t.Fatalf("got error %v, want %v", err, user.ErrUserNotFound)
+	}
+}
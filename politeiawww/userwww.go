@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"text/template"
+	"time"
 
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
 	"github.com/decred/politeia/politeiawww/user"
@@ -25,6 +26,10 @@ var (
 		template.New("user_locked_reset_password").Parse(templateUserLockedResetPasswordRaw))
 	templateUserPasswordChanged = template.Must(
 		template.New("user_changed_password").Parse(templateUserPasswordChangedRaw))
+	templateChangeEmail = template.Must(
+		template.New("change_email_template").Parse(templateChangeEmailRaw))
+	templateChangeEmailNotice = template.Must(
+		template.New("change_email_notice_template").Parse(templateChangeEmailNoticeRaw))
 )
 
 // getSession returns the active cookie session.
@@ -32,76 +37,204 @@ func (p *politeiawww) getSession(r *http.Request) (*sessions.Session, error) {
 	return p.store.Get(r, v1.CookieSession)
 }
 
-// isAdmin returns true if the current session has admin privileges.
-func (p *politeiawww) isAdmin(w http.ResponseWriter, r *http.Request) (bool, error) {
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		return false, err
-	}
-
-	return user.Admin, nil
-}
-
 // getSessionUUID returns the uuid address of the currently logged in user from
 // the session store.
 func (p *politeiawww) getSessionUUID(r *http.Request) (string, error) {
 	session, err := p.getSession(r)
 	if err != nil {
-		return "", err
+		// The session cookie is present but the session could not
+		// be loaded from the session store.
+		requestLog(r).Debugf("getSessionUUID: %v", err)
+		return "", ErrSessionExpired
 	}
 
 	id, ok := session.Values["uuid"].(string)
 	if !ok {
 		return "", ErrSessionUUIDNotFound
 	}
-	log.Tracef("getSessionUUID: %v", session.ID)
+	requestLog(r).Tracef("getSessionUUID: %v", session.ID)
+
+	if sessionExpired(session) {
+		return "", ErrSessionExpired
+	}
 
 	return id, nil
 }
 
-// getSessionUser retrieves the current session user from the database.
+// getSessionUser retrieves the current session user from the database.  The
+// user of the API token is returned for requests that carry an API token;
+// the session cookie of these requests is ignored.  Impersonation sessions are
+// read-only; ErrorStatusImpersonatedSession is returned for requests that are
+// not GET requests so that no handler acts as the impersonated user.
 func (p *politeiawww) getSessionUser(w http.ResponseWriter, r *http.Request) (*user.User, error) {
+	u, err := p.lookupSessionUser(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Method != http.MethodGet && !hasAPIToken(r) &&
+		p.isImpersonatedSession(r) {
+		requestLog(r).Warnf("getSessionUser: blocked %v %v in "+
+			"impersonation session of user %v", r.Method, r.URL, u.ID)
+		return nil, v1.UserError{
+			ErrorCode: v1.ErrorStatusImpersonatedSession,
+		}
+	}
+
+	return u, nil
+}
+
+// lookupSessionUser retrieves the current session user from the database
+// without enforcing that impersonation sessions are read-only.  It must only
+// be used by handlers that end the session.
+func (p *politeiawww) lookupSessionUser(w http.ResponseWriter, r *http.Request) (*user.User, error) {
+	if hasAPIToken(r) {
+		_, u, err := p.getAPITokenUser(r)
+		return u, err
+	}
+
 	id, err := p.getSessionUUID(r)
 	if err != nil {
+		if err == ErrSessionExpired {
+			// Delete the expired session from the store
+			p.removeSession(w, r)
+		}
 		return nil, err
 	}
 
-	log.Tracef("getSessionUser: %v", id)
+	requestLog(r).Tracef("lookupSessionUser: %v", id)
 	pid, err := uuid.Parse(id)
 	if err != nil {
-		return nil, err
+		requestLog(r).Debugf("lookupSessionUser: invalid session uuid "+
+			"%v: %v", id, err)
+		p.removeSession(w, r)
+		return nil, ErrSessionExpired
 	}
 
-	user, err := p.db.UserGetById(pid)
+	u, err := p.db.UserGetById(pid)
 	if err != nil {
+		if err == user.ErrUserNotFound {
+			// The session belongs to a user that no longer exists.
+			// Treat it the same as an expired session.
+			requestLog(r).Debugf("lookupSessionUser: session user not "+
+				"found: %v", id)
+			p.removeSession(w, r)
+			return nil, ErrSessionExpired
+		}
 		return nil, err
 	}
 
-	if user.Deactivated {
+	if u.Deactivated {
 		p.removeSession(w, r)
 		return nil, v1.UserError{
 			ErrorCode: v1.ErrorStatusNotLoggedIn,
 		}
 	}
 
-	return user, nil
+	// Impersonation sessions end as soon as the admin that started them
+	// loses admin privileges.  Every request that is made using an
+	// impersonation session is logged along with the admin.
+	admin, err := p.getSessionImpersonator(r)
+	if err != nil {
+		return nil, err
+	}
+	if admin != nil {
+		if !admin.Admin || admin.Deactivated {
+			p.removeSession(w, r)
+			return nil, v1.UserError{
+				ErrorCode: v1.ErrorStatusNotLoggedIn,
+			}
+		}
+		requestLog(r).Infof("admin %v impersonating user %v: %v %v",
+			admin.Username, u.Username, r.Method, r.URL)
+	}
+
+	// Activity extends the session
+	err = p.renewSession(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// getSessionImpersonator returns the admin that started the current session
+// if the session is an impersonation session.  Nil is returned for sessions
+// that were started by logging in.
+func (p *politeiawww) getSessionImpersonator(r *http.Request) (*user.User, error) {
+	session, err := p.getSession(r)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := sessionImpersonator(session)
+	if !ok {
+		return nil, nil
+	}
+
+	pid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return p.db.UserGetById(pid)
+}
+
+// getContextUser returns the session user that the isLoggedIn and
+// isLoggedInAsAdmin middleware attached to the request context.  It must only
+// be called by handlers of routes that require a login.
+func getContextUser(r *http.Request) *user.User {
+	u, _ := r.Context().Value(contextKeySessionUser).(*user.User)
+	return u
+}
+
+// sessionErrorStatus returns the error status that is returned to the client
+// when the session user could not be retrieved.  An expired session is
+// reported distinctly from a user that never logged in.
+func sessionErrorStatus(err error) v1.ErrorStatusT {
+	if err == ErrSessionExpired {
+		return v1.ErrorStatusSessionExpired
+	}
+	return v1.ErrorStatusNotLoggedIn
+}
+
+// convertSessionError converts an error that was returned while looking up
+// the session user into the user error that is returned to the client.
+// Errors that are not session errors are returned unchanged.
+func convertSessionError(err error) error {
+	switch err {
+	case ErrSessionUUIDNotFound, ErrSessionExpired:
+		return v1.UserError{
+			ErrorCode: sessionErrorStatus(err),
+		}
+	}
+	return err
 }
 
 // setSessionUserID sets the "uuid" session key to the provided value.
 func (p *politeiawww) setSessionUserID(w http.ResponseWriter, r *http.Request, id string) error {
-	log.Tracef("setSessionUserID: %v %v", id, v1.CookieSession)
+	requestLog(r).Tracef("setSessionUserID: %v %v", id, v1.CookieSession)
 	session, err := p.getSession(r)
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
 	session.Values["uuid"] = id
-	return session.Save(r, w)
+	session.Values["createdat"] = now.UnixNano()
+	delete(session.Values, "impersonatorid")
+	setSessionExpiry(session, now, p.cfg.SessionMaxAge,
+		p.cfg.SessionAbsoluteMaxAge)
+	err = session.Save(r, w)
+	if err != nil {
+		return err
+	}
+
+	p.setUserSession(id, session.ID)
+	return nil
 }
 
 // removeSession deletes the session from the filesystem.
 func (p *politeiawww) removeSession(w http.ResponseWriter, r *http.Request) error {
-	log.Tracef("removeSession: %v", v1.CookieSession)
+	requestLog(r).Tracef("removeSession: %v", v1.CookieSession)
 	session, err := p.getSession(r)
 	if err != nil {
 		return err
@@ -115,20 +248,28 @@ func (p *politeiawww) removeSession(w http.ResponseWriter, r *http.Request) erro
 	// Saving the session with a negative MaxAge will cause it to be deleted
 	// from the filesystem.
 	session.Options.MaxAge = -1
-	return session.Save(r, w)
+	err = session.Save(r, w)
+	if err != nil {
+		return err
+	}
+
+	if id, ok := session.Values["uuid"].(string); ok {
+		p.removeUserSession(id, session.ID)
+	}
+	return nil
 }
 
 // handleNewUser handles the incoming new user command. It verifies that the new user
 // doesn't already exist, and then creates a new user in the db and generates a random
 // code used for verification. The code is intended to be sent to the specified email.
 func (p *politeiawww) handleNewUser(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleNewUser")
+	requestLog(r).Tracef("handleNewUser")
 
 	// Get the new user command.
 	var u v1.NewUser
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&u); err != nil {
-		RespondWithError(w, r, 0, "handleNewUser: unmarshal", v1.UserError{
+		RespondWithError(w, r, 0, "handleNewUser: unmarshal %v: %v", err, v1.UserError{
 			ErrorCode: v1.ErrorStatusInvalidInput,
 		})
 		return
@@ -148,13 +289,13 @@ func (p *politeiawww) handleNewUser(w http.ResponseWriter, r *http.Request) {
 // that the user with the provided email has a verification token that matches
 // the provided token and that the verification token has not yet expired.
 func (p *politeiawww) handleVerifyNewUser(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleVerifyNewUser")
+	requestLog(r).Tracef("handleVerifyNewUser")
 
 	// Get the new user verify command.
 	var vnu v1.VerifyNewUser
 	err := util.ParseGetParams(r, &vnu)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleVerifyNewUser: ParseGetParams",
+		RespondWithError(w, r, 0, "handleVerifyNewUser: ParseGetParams %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -174,13 +315,13 @@ func (p *politeiawww) handleVerifyNewUser(w http.ResponseWriter, r *http.Request
 // handleResendVerification sends another verification email for new user
 // signup, if there is an existing verification token and it is expired.
 func (p *politeiawww) handleResendVerification(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleResendVerification")
+	requestLog(r).Tracef("handleResendVerification")
 
 	// Get the resend verification command.
 	var rv v1.ResendVerification
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&rv); err != nil {
-		RespondWithError(w, r, 0, "handleResendVerification: unmarshal",
+		RespondWithError(w, r, 0, "handleResendVerification: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -202,13 +343,16 @@ func (p *politeiawww) handleResendVerification(w http.ResponseWriter, r *http.Re
 // exists and the accompanying password.  On success a cookie is added to the
 // gorilla sessions that must be returned on subsequent calls.
 func (p *politeiawww) handleLogin(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleLogin")
+	requestLog(r).Tracef("handleLogin")
 
 	// Get the login command.
 	var l v1.Login
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&l); err != nil {
-		RespondWithError(w, r, 0, "handleLogin: failed to decode: %v", err)
+		RespondWithError(w, r, 0, "handleLogin: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
 		return
 	}
 
@@ -228,7 +372,7 @@ func (p *politeiawww) handleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set session max age
-	reply.SessionMaxAge = sessionMaxAge
+	reply.SessionMaxAge = p.cfg.SessionMaxAge
 
 	// Reply with the user information.
 	util.RespondWithJSON(w, http.StatusOK, reply)
@@ -236,15 +380,22 @@ func (p *politeiawww) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 // handleLogout logs the user out.
 func (p *politeiawww) handleLogout(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleLogout")
+	requestLog(r).Tracef("handleLogout")
 
-	_, err := p.getSessionUser(w, r)
+	// Logging out is how an impersonation session ends so it is allowed in
+	// impersonation sessions.
+	u, err := p.lookupSessionUser(w, r)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleLogout: getSessionUser", v1.UserError{
-			ErrorCode: v1.ErrorStatusNotLoggedIn,
-		})
+		RespondWithError(w, r, 0, "handleLogout: lookupSessionUser %v: %v", err,
+			v1.UserError{
+				ErrorCode: sessionErrorStatus(err),
+			})
 		return
 	}
+	if p.isImpersonatedSession(r) {
+		requestLog(r).Infof("impersonation of user %v ended",
+			u.Username)
+	}
 
 	err = p.removeSession(w, r)
 	if err != nil {
@@ -266,7 +417,7 @@ func (p *politeiawww) handleResetPassword(w http.ResponseWriter, r *http.Request
 	var rp v1.ResetPassword
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&rp); err != nil {
-		RespondWithError(w, r, 0, "handleResetPassword: unmarshal",
+		RespondWithError(w, r, 0, "handleResetPassword: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -284,17 +435,42 @@ func (p *politeiawww) handleResetPassword(w http.ResponseWriter, r *http.Request
 	util.RespondWithJSON(w, http.StatusOK, rpr)
 }
 
+// handleVerifyResetPassword handles checking a reset password verification
+// token without consuming it.
+func (p *politeiawww) handleVerifyResetPassword(w http.ResponseWriter, r *http.Request) {
+	log.Trace("handleVerifyResetPassword")
+
+	var vrp v1.VerifyResetPassword
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&vrp); err != nil {
+		RespondWithError(w, r, 0, "handleVerifyResetPassword: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	vrpr, err := p.processVerifyResetPassword(vrp)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifyResetPassword: processVerifyResetPassword %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, vrpr)
+}
+
 // handleUserDetails handles fetching user details by user id.
 func (p *politeiawww) handleUserDetails(w http.ResponseWriter, r *http.Request) {
 	// Add the path param to the struct.
-	log.Tracef("handleUserDetails")
+	requestLog(r).Tracef("handleUserDetails")
 	pathParams := mux.Vars(r)
 	var ud v1.UserDetails
 	ud.UserID = pathParams["userid"]
 
 	userID, err := uuid.Parse(ud.UserID)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleUserDetails: ParseUint",
+		RespondWithError(w, r, 0, "handleUserDetails: uuid.Parse %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -304,7 +480,8 @@ func (p *politeiawww) handleUserDetails(w http.ResponseWriter, r *http.Request)
 	user, err := p.getSessionUser(w, r)
 	if err != nil {
 		// This is a public route so a logged in user is not required
-		log.Debugf("handleUserDetails: could not get session user: %v", err)
+		requestLog(r).Debugf("handleUserDetails: could not get "+
+			"session user: %v", err)
 	}
 
 	udr, err := p.processUserDetails(&ud,
@@ -322,23 +499,49 @@ func (p *politeiawww) handleUserDetails(w http.ResponseWriter, r *http.Request)
 	util.RespondWithJSON(w, http.StatusOK, udr)
 }
 
+// handleBatchUserDetails handles fetching the details of multiple users.
+func (p *politeiawww) handleBatchUserDetails(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleBatchUserDetails")
+
+	var bud v1.BatchUserDetails
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&bud); err != nil {
+		RespondWithError(w, r, 0, "handleBatchUserDetails: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		// This is a public route so a logged in user is not required
+		requestLog(r).Debugf("handleBatchUserDetails: could not get session "+
+			"user: %v", err)
+	}
+
+	reply, err := p.processBatchUserDetails(bud, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleBatchUserDetails: processBatchUserDetails %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 // handleSecret is a mock handler to test privileged routes.
 func (p *politeiawww) handleSecret(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleSecret")
+	requestLog(r).Tracef("handleSecret")
 
 	fmt.Fprintf(w, "secret sauce")
 }
 
 // handleMe returns logged in user information.
 func (p *politeiawww) handleMe(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleMe")
+	requestLog(r).Tracef("handleMe")
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleMe: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	reply, err := p.createLoginReply(user, user.LastLoginTime)
 	if err != nil {
@@ -348,7 +551,18 @@ func (p *politeiawww) handleMe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set session max age
-	reply.SessionMaxAge = sessionMaxAge
+	reply.SessionMaxAge = p.cfg.SessionMaxAge
+
+	// Flag impersonation sessions so that clients can make it obvious
+	session, err := p.getSession(r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleMe: getSession %v", err)
+		return
+	}
+	if adminID, ok := sessionImpersonator(session); ok {
+		reply.SessionMaxAge = p.cfg.ImpersonationMaxAge
+		reply.ImpersonatedBy = adminID
+	}
 
 	util.RespondWithJSON(w, http.StatusOK, *reply)
 }
@@ -357,24 +571,19 @@ func (p *politeiawww) handleMe(w http.ResponseWriter, r *http.Request) {
 // a random code used for verification. The code is intended to be sent to the
 // email of the logged in user.
 func (p *politeiawww) handleUpdateUserKey(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleUpdateUserKey")
+	requestLog(r).Tracef("handleUpdateUserKey")
 
 	// Get the update user key command.
 	var u v1.UpdateUserKey
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&u); err != nil {
-		RespondWithError(w, r, 0, "handleUpdateUserKey: unmarshal", v1.UserError{
+		RespondWithError(w, r, 0, "handleUpdateUserKey: unmarshal %v: %v", err, v1.UserError{
 			ErrorCode: v1.ErrorStatusInvalidInput,
 		})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleUpdateUserKey: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	reply, err := p.processUpdateUserKey(user, u)
 	if err != nil {
@@ -390,27 +599,22 @@ func (p *politeiawww) handleUpdateUserKey(w http.ResponseWriter, r *http.Request
 // that the user with the provided email has a verification token that matches
 // the provided token and that the verification token has not yet expired.
 func (p *politeiawww) handleVerifyUpdateUserKey(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleVerifyUpdateUserKey")
+	requestLog(r).Tracef("handleVerifyUpdateUserKey")
 
 	// Get the new user verify command.
 	var vuu v1.VerifyUpdateUserKey
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&vuu); err != nil {
-		RespondWithError(w, r, 0, "handleVerifyUpdateUserKey: unmarshal",
+		RespondWithError(w, r, 0, "handleVerifyUpdateUserKey: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleVerifyUpdateUserKey: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
-	_, err = p.processVerifyUpdateUserKey(user, vuu)
+	_, err := p.processVerifyUpdateUserKey(user, vuu)
 	if err != nil {
 		RespondWithError(w, r, 0, "handleVerifyUpdateUserKey: "+
 			"processVerifyUpdateUserKey %v", err)
@@ -420,27 +624,50 @@ func (p *politeiawww) handleVerifyUpdateUserKey(w http.ResponseWriter, r *http.R
 	util.RespondWithJSON(w, http.StatusOK, v1.VerifyUpdateUserKeyReply{})
 }
 
+// handleResendUpdateUserKey handles the incoming resend update user key
+// command.  It resends the verification email of the pending key update of the
+// logged in user.
+func (p *politeiawww) handleResendUpdateUserKey(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleResendUpdateUserKey")
+
+	var ruuk v1.ResendUpdateUserKey
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ruuk); err != nil {
+		RespondWithError(w, r, 0, "handleResendUpdateUserKey: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	reply, err := p.processResendUpdateUserKey(user)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleResendUpdateUserKey: "+
+			"processResendUpdateUserKey %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 // handleChangeUsername handles the change user name command.
 func (p *politeiawww) handleChangeUsername(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleChangeUsername")
+	requestLog(r).Tracef("handleChangeUsername")
 
 	// Get the change username command.
 	var cu v1.ChangeUsername
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&cu); err != nil {
-		RespondWithError(w, r, 0, "handleChangeUsername: unmarshal",
+		RespondWithError(w, r, 0, "handleChangeUsername: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleChangeUsername: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	reply, err := p.processChangeUsername(user.Email, cu)
 	if err != nil {
@@ -455,34 +682,87 @@ func (p *politeiawww) handleChangeUsername(w http.ResponseWriter, r *http.Reques
 
 // handleChangePassword handles the change password command.
 func (p *politeiawww) handleChangePassword(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleChangePassword")
+	requestLog(r).Tracef("handleChangePassword")
 
 	// Get the change password command.
 	var cp v1.ChangePassword
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&cp); err != nil {
-		RespondWithError(w, r, 0, "handleChangePassword: unmarshal",
+		RespondWithError(w, r, 0, "handleChangePassword: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
+	user := getContextUser(r)
+
+	reply, err := p.processChangePassword(user.Email, cp)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleChangePassword: getSessionUser %v", err)
+			"handleChangePassword: processChangePassword %v", err)
 		return
 	}
 
-	reply, err := p.processChangePassword(user.Email, cp)
+	// Reply with the error code.
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleChangeEmail handles the change email command. It generates a
+// verification token that is sent to the new email address.
+func (p *politeiawww) handleChangeEmail(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleChangeEmail")
+
+	// Get the change email command.
+	var ce v1.ChangeEmail
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ce); err != nil {
+		RespondWithError(w, r, 0, "handleChangeEmail: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	reply, err := p.processChangeEmail(user, ce)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleChangePassword: processChangePassword %v", err)
+			"handleChangeEmail: processChangeEmail %v", err)
+		return
+	}
+
+	// Reply with the verification token.
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleVerifyChangeEmail handles the verify change email command. It
+// verifies the token that was sent to the new email address and changes the
+// email address of the logged in user.
+func (p *politeiawww) handleVerifyChangeEmail(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleVerifyChangeEmail")
+
+	// Get the verify change email command.
+	var vce v1.VerifyChangeEmail
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&vce); err != nil {
+		RespondWithError(w, r, 0, "handleVerifyChangeEmail: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	reply, err := p.processVerifyChangeEmail(user, vce)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifyChangeEmail: processVerifyChangeEmail %v", err)
 		return
 	}
 
-	// Reply with the error code.
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
@@ -490,30 +770,54 @@ func (p *politeiawww) handleChangePassword(w http.ResponseWriter, r *http.Reques
 // is on the blockchain and meets the requirements to consider the user
 // registration fee as paid.
 func (p *politeiawww) handleVerifyUserPayment(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleVerifyUserPayment")
+	requestLog(r).Tracef("handleVerifyUserPayment")
 
 	// Get the verify user payment tx command.
 	var vupt v1.VerifyUserPayment
 	err := util.ParseGetParams(r, &vupt)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleVerifyUserPayment: ParseGetParams",
+		RespondWithError(w, r, 0, "handleVerifyUserPayment: ParseGetParams %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
+	user := getContextUser(r)
+
+	vuptr, err := p.processVerifyUserPayment(user, vupt)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleVerifyUserPayment: getSessionUser %v", err)
+			"handleVerifyUserPayment: processVerifyUserPayment %v",
+			err)
 		return
 	}
 
-	vuptr, err := p.processVerifyUserPayment(user, vupt)
+	util.RespondWithJSON(w, http.StatusOK, vuptr)
+}
+
+// handleVerifyUserPaymentTx checks whether the provided transaction pays the
+// registration fee of the logged in user.
+func (p *politeiawww) handleVerifyUserPaymentTx(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleVerifyUserPaymentTx")
+
+	var vupt v1.VerifyUserPaymentTx
+	err := util.ParseGetParams(r, &vupt)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleVerifyUserPaymentTx: "+
+			"ParseGetParams %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	vuptr, err := p.processVerifyUserPaymentTx(user, vupt)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleVerifyUserPayment: processVerifyUserPayment %v",
+			"handleVerifyUserPaymentTx: processVerifyUserPaymentTx %v",
 			err)
 		return
 	}
@@ -523,24 +827,19 @@ func (p *politeiawww) handleVerifyUserPayment(w http.ResponseWriter, r *http.Req
 
 // handleEditUser handles editing a user's preferences.
 func (p *politeiawww) handleEditUser(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleEditUser")
+	requestLog(r).Tracef("handleEditUser")
 
 	var eu v1.EditUser
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&eu); err != nil {
-		RespondWithError(w, r, 0, "handleEditUser: unmarshal",
+		RespondWithError(w, r, 0, "handleEditUser: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	adminUser, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0, "handleEditUser: getSessionUser %v",
-			err)
-		return
-	}
+	adminUser := getContextUser(r)
 
 	eur, err := p.processEditUser(&eu, adminUser)
 	if err != nil {
@@ -554,12 +853,12 @@ func (p *politeiawww) handleEditUser(w http.ResponseWriter, r *http.Request) {
 
 // handleUsers handles fetching a list of users.
 func (p *politeiawww) handleUsers(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleUsers")
+	requestLog(r).Tracef("handleUsers")
 
 	var u v1.Users
 	err := util.ParseGetParams(r, &u)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleUsers: ParseGetParams",
+		RespondWithError(w, r, 0, "handleUsers: ParseGetParams %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -579,12 +878,12 @@ func (p *politeiawww) handleUsers(w http.ResponseWriter, r *http.Request) {
 // handleUserPaymentsRescan allows an admin to rescan a user's paywall address
 // to check for any payments that may have been missed by paywall polling.
 func (p *politeiawww) handleUserPaymentsRescan(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleUserPaymentsRescan")
+	requestLog(r).Tracef("handleUserPaymentsRescan")
 
 	var upr v1.UserPaymentsRescan
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&upr); err != nil {
-		RespondWithError(w, r, 0, "handleUserPaymentsRescan: unmarshal",
+		RespondWithError(w, r, 0, "handleUserPaymentsRescan: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -602,26 +901,42 @@ func (p *politeiawww) handleUserPaymentsRescan(w http.ResponseWriter, r *http.Re
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
+// handleUserPaymentsRescanStatus returns the progress of a user payments
+// rescan.
+func (p *politeiawww) handleUserPaymentsRescanStatus(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUserPaymentsRescanStatus")
+
+	pathParams := mux.Vars(r)
+	uprs := v1.UserPaymentsRescanStatus{
+		JobID: pathParams["jobid"],
+	}
+
+	reply, err := p.processUserPaymentsRescanStatus(uprs)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleUserPaymentsRescanStatus: "+
+				"processUserPaymentsRescanStatus: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 // handleManageUser handles editing a user's details.
 func (p *politeiawww) handleManageUser(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleManageUser")
+	requestLog(r).Tracef("handleManageUser")
 
 	var mu v1.ManageUser
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&mu); err != nil {
-		RespondWithError(w, r, 0, "handleManageUser: unmarshal",
+		RespondWithError(w, r, 0, "handleManageUser: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	adminUser, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0, "handleManageUser: getSessionUser %v",
-			err)
-		return
-	}
+	adminUser := getContextUser(r)
 
 	mur, err := p.processManageUser(&mu, adminUser)
 	if err != nil {
@@ -633,6 +948,220 @@ func (p *politeiawww) handleManageUser(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, mur)
 }
 
+// handleUserLogoutAll handles logging a user out of all of their sessions.
+func (p *politeiawww) handleUserLogoutAll(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUserLogoutAll")
+
+	var ula v1.UserLogoutAll
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ula); err != nil {
+		RespondWithError(w, r, 0, "handleUserLogoutAll: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	ulr, err := p.processUserLogoutAll(&ula, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleUserLogoutAll: processUserLogoutAll %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ulr)
+}
+
+// handleResendUserEmail handles resending the verification or reset password
+// email of a user on behalf of an admin.
+func (p *politeiawww) handleResendUserEmail(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleResendUserEmail")
+
+	var rue v1.ResendUserEmail
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rue); err != nil {
+		RespondWithError(w, r, 0, "handleResendUserEmail: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	rur, err := p.processResendUserEmail(&rue, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleResendUserEmail: processResendUserEmail %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rur)
+}
+
+// handlePurgeUnverifiedUsers handles permanently deleting the users that
+// never verified their email address.
+func (p *politeiawww) handlePurgeUnverifiedUsers(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handlePurgeUnverifiedUsers")
+
+	var puu v1.PurgeUnverifiedUsers
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&puu); err != nil {
+		RespondWithError(w, r, 0, "handlePurgeUnverifiedUsers: unmarshal "+
+			"%v: %v", err, v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidInput,
+		})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	pur, err := p.processPurgeUnverifiedUsers(adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handlePurgeUnverifiedUsers: processPurgeUnverifiedUsers %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, pur)
+}
+
+// handleAPITokens handles the incoming API tokens command.  It returns all of
+// the API tokens.
+func (p *politeiawww) handleAPITokens(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleAPITokens")
+
+	atr, err := p.processAPITokens()
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleAPITokens: processAPITokens %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, atr)
+}
+
+// handleNewAPIToken handles the incoming new API token command.  It issues an
+// API token for a user.
+func (p *politeiawww) handleNewAPIToken(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleNewAPIToken")
+
+	var nat v1.NewAPIToken
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&nat); err != nil {
+		RespondWithError(w, r, 0, "handleNewAPIToken: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	natr, err := p.processNewAPIToken(nat, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNewAPIToken: processNewAPIToken %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, natr)
+}
+
+// handleRevokeAPIToken handles the incoming revoke API token command.
+func (p *politeiawww) handleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleRevokeAPIToken")
+
+	var rat v1.RevokeAPIToken
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rat); err != nil {
+		RespondWithError(w, r, 0, "handleRevokeAPIToken: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	ratr, err := p.processRevokeAPIToken(rat, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRevokeAPIToken: processRevokeAPIToken %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ratr)
+}
+
+// handleImpersonateUser replaces the session of the admin with a read-only
+// impersonation session for the requested user.
+func (p *politeiawww) handleImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleImpersonateUser")
+
+	var iu v1.ImpersonateUser
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&iu); err != nil {
+		RespondWithError(w, r, 0, "handleImpersonateUser: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	reply, err := p.processImpersonateUser(&iu, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleImpersonateUser: processImpersonateUser %v", err)
+		return
+	}
+
+	expiresAt, err := p.startImpersonationSession(w, r,
+		adminUser.ID.String(), reply.User.UserID)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleImpersonateUser: startImpersonationSession %v", err)
+		return
+	}
+	reply.ExpiresAt = expiresAt.Unix()
+
+	requestLog(r).Infof("admin %v started impersonating user %v until %v",
+		adminUser.Username, reply.User.Username,
+		expiresAt.UTC().Format(time.RFC3339))
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleUsernameAvailable checks whether a username can be used to register a
+// new user.
+func (p *politeiawww) handleUsernameAvailable(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUsernameAvailable")
+
+	var ua v1.UsernameAvailable
+	err := util.ParseGetParams(r, &ua)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleUsernameAvailable: "+
+			"ParseGetParams %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	uar, err := p.processUsernameAvailable(ua)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleUsernameAvailable: processUsernameAvailable %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, uar)
+}
+
 // setUserWWWRoutes setsup the user routes.
 func (p *politeiawww) setUserWWWRoutes() {
 	// Public routes
@@ -648,8 +1177,21 @@ func (p *politeiawww) setUserWWWRoutes() {
 		permissionPublic)
 	p.addRoute(http.MethodPost, v1.RouteResetPassword,
 		p.handleResetPassword, permissionPublic)
+	p.addRoute(http.MethodPost, v1.RouteVerifyResetPassword,
+		p.handleVerifyResetPassword, permissionPublic)
 	p.addRoute(http.MethodGet, v1.RouteUserDetails,
 		p.handleUserDetails, permissionPublic)
+	p.addRoute(http.MethodPost, v1.RouteBatchUserDetails,
+		p.handleBatchUserDetails, permissionPublic)
+
+	// The username availability route is rate limited so that it can
+	// not be used to quickly enumerate the registered usernames.
+	usernameLimiter := newRateLimiter(p.cfg.UsernameAvailableRateLimit,
+		time.Minute)
+	p.addRoute(http.MethodGet, v1.RouteUsernameAvailable,
+		rateLimit(p.handleUsernameAvailable, usernameLimiter,
+			p.trustedProxies),
+		permissionPublic)
 
 	// Routes that require being logged in.
 	p.addRoute(http.MethodPost, v1.RouteSecret, p.handleSecret,
@@ -659,12 +1201,20 @@ func (p *politeiawww) setUserWWWRoutes() {
 		p.handleUpdateUserKey, permissionLogin)
 	p.addRoute(http.MethodPost, v1.RouteVerifyUpdateUserKey,
 		p.handleVerifyUpdateUserKey, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteResendUpdateUserKey,
+		p.handleResendUpdateUserKey, permissionLogin)
 	p.addRoute(http.MethodPost, v1.RouteChangeUsername,
 		p.handleChangeUsername, permissionLogin)
 	p.addRoute(http.MethodPost, v1.RouteChangePassword,
 		p.handleChangePassword, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteChangeEmail,
+		p.handleChangeEmail, permissionLogin)
+	p.addRoute(http.MethodPost, v1.RouteVerifyChangeEmail,
+		p.handleVerifyChangeEmail, permissionLogin)
 	p.addRoute(http.MethodGet, v1.RouteVerifyUserPayment,
 		p.handleVerifyUserPayment, permissionLogin)
+	p.addRoute(http.MethodGet, v1.RouteVerifyUserPaymentTx,
+		p.handleVerifyUserPaymentTx, permissionLogin)
 	p.addRoute(http.MethodPost, v1.RouteEditUser,
 		p.handleEditUser, permissionLogin)
 
@@ -673,6 +1223,22 @@ func (p *politeiawww) setUserWWWRoutes() {
 		p.handleUsers, permissionAdmin)
 	p.addRoute(http.MethodPut, v1.RouteUserPaymentsRescan,
 		p.handleUserPaymentsRescan, permissionAdmin)
+	p.addRoute(http.MethodGet, v1.RouteUserPaymentsRescanStatus,
+		p.handleUserPaymentsRescanStatus, permissionAdmin)
 	p.addRoute(http.MethodPost, v1.RouteManageUser,
 		p.handleManageUser, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteUserLogoutAll,
+		p.handleUserLogoutAll, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteResendUserEmail,
+		p.handleResendUserEmail, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteImpersonateUser,
+		p.handleImpersonateUser, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RoutePurgeUnverifiedUsers,
+		p.handlePurgeUnverifiedUsers, permissionAdmin)
+	p.addRoute(http.MethodGet, v1.RouteAPITokens,
+		p.handleAPITokens, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteNewAPIToken,
+		p.handleNewAPIToken, permissionAdmin)
+	p.addRoute(http.MethodPost, v1.RouteRevokeAPIToken,
+		p.handleRevokeAPIToken, permissionAdmin)
 }
@@ -0,0 +1,262 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// requestType describes the request body of a route that is validated before
+// it reaches its handler.
+type requestType struct {
+	// newRequest returns a new request of the type that the route
+	// expects.
+	newRequest func() interface{}
+
+	// errorCodes maps the JSON names of fields to the error code that is
+	// returned when the field is invalid.  Fields that are not in the map
+	// are reported as ErrorStatusInvalidInput.  It allows routes to keep
+	// the error codes that their handlers returned before the request
+	// bodies were validated.
+	errorCodes map[string]www.ErrorStatusT
+}
+
+// requestTypes maps the routes whose request bodies are validated before they
+// reach their handler to their request type.
+var requestTypes = map[string]requestType{
+	www.RouteNewUser: {
+		newRequest: func() interface{} { return new(www.NewUser) },
+		errorCodes: map[string]www.ErrorStatusT{
+			"email":     www.ErrorStatusMalformedEmail,
+			"password":  www.ErrorStatusMalformedPassword,
+			"publickey": www.ErrorStatusInvalidPublicKey,
+			"username":  www.ErrorStatusMalformedUsername,
+		},
+	},
+	www.RouteLogin: {
+		newRequest: func() interface{} { return new(www.Login) },
+		errorCodes: map[string]www.ErrorStatusT{
+			"email":    www.ErrorStatusInvalidEmailOrPassword,
+			"password": www.ErrorStatusInvalidEmailOrPassword,
+		},
+	},
+	www.RouteChangePassword: {newRequest: func() interface{} { return new(www.ChangePassword) }},
+	www.RouteUpdateUserKey:  {newRequest: func() interface{} { return new(www.UpdateUserKey) }},
+	www.RouteNewProposal:    {newRequest: func() interface{} { return new(www.NewProposal) }},
+	www.RouteEditProposal:   {newRequest: func() interface{} { return new(www.EditProposal) }},
+	www.RouteNewComment:     {newRequest: func() interface{} { return new(www.NewComment) }},
+	www.RouteEditComment:    {newRequest: func() interface{} { return new(www.EditComment) }},
+	www.RouteDeleteComment:  {newRequest: func() interface{} { return new(www.DeleteComment) }},
+	www.RouteLikeComment:    {newRequest: func() interface{} { return new(www.LikeComment) }},
+	www.RouteReactComment:   {newRequest: func() interface{} { return new(www.ReactComment) }},
+	www.RouteCensorComment:  {newRequest: func() interface{} { return new(www.CensorComment) }},
+	www.RouteAuthorizeVote:  {newRequest: func() interface{} { return new(www.AuthorizeVote) }},
+}
+
+// errorCode returns the error code that is returned for the passed in field
+// errors.  The error code of the first field that has a specific error code is
+// used.
+func (rt requestType) errorCode(errs []fieldError) www.ErrorStatusT {
+	for _, v := range errs {
+		if code, ok := rt.errorCodes[v.field]; ok {
+			return code
+		}
+	}
+	return www.ErrorStatusInvalidInput
+}
+
+// fieldError is a field that failed validation along with the reason.
+type fieldError struct {
+	field  string // JSON name of the field
+	reason string // Reason the field is invalid
+}
+
+// String returns the field error in the form "field: reason".
+func (e fieldError) String() string {
+	return e.field + ": " + e.reason
+}
+
+// unknownFieldPrefix is the prefix of the errors that a JSON decoder that
+// disallows unknown fields returns for an unknown field.
+const unknownFieldPrefix = "json: unknown field "
+
+// unknownFieldError returns the field error of the passed in JSON decode error
+// if it is the error of an unknown field.
+func unknownFieldError(err error) (fieldError, bool) {
+	if err == nil || !strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+		return fieldError{}, false
+	}
+	name, uerr := strconv.Unquote(strings.TrimPrefix(err.Error(),
+		unknownFieldPrefix))
+	if uerr != nil {
+		name = strings.TrimPrefix(err.Error(), unknownFieldPrefix)
+	}
+	return fieldError{
+		field:  name,
+		reason: "unknown field",
+	}, true
+}
+
+// fieldErrorStrings returns the passed in field errors as strings.
+func fieldErrorStrings(errs []fieldError) []string {
+	s := make([]string, 0, len(errs))
+	for _, v := range errs {
+		s = append(s, v.String())
+	}
+	return s
+}
+
+// validateStruct validates the fields of the passed in struct, or pointer to
+// a struct, against the rules in their validate struct tags.  Fields that are
+// structs or slices of structs are validated recursively.  A field error is
+// returned for every invalid field, where the field is named by its JSON
+// name.
+//
+// The supported rules are:
+//
+//	required           the field must not be empty
+//	required_without=F the field must not be empty when the field F of
+//	                   the same struct, given by its Go name, is empty
+//	hex                the field must be hex encoded
+//	len=N              the field must be exactly N characters long
+//
+// The rules of a field are separated by commas.  Only the first rule that a
+// field fails is reported and rules other than required are not checked for
+// empty fields.  An error is returned for rules that are unknown or that do
+// not apply to the kind of the field since they are programming errors.
+func validateStruct(v interface{}) ([]fieldError, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	return validateFields("", rv)
+}
+
+// validateFields validates the fields of the passed in struct value.  The
+// prefix is prepended to the names of the fields in the returned errors.
+func validateFields(prefix string, v reflect.Value) ([]fieldError, error) {
+	var errs []fieldError
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		name := prefix + jsonFieldName(field)
+
+		tag := field.Tag.Get("validate")
+		if tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				reason, err := checkRule(v, fv, rule)
+				if err != nil {
+					return nil, fmt.Errorf("%v: %v", name, err)
+				}
+				if reason != "" {
+					errs = append(errs, fieldError{
+						field:  name,
+						reason: reason,
+					})
+					break
+				}
+			}
+		}
+
+		switch {
+		case fv.Kind() == reflect.Struct:
+			e, err := validateFields(name+".", fv)
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, e...)
+		case fv.Kind() == reflect.Slice &&
+			fv.Type().Elem().Kind() == reflect.Struct:
+			for j := 0; j < fv.Len(); j++ {
+				e, err := validateFields(
+					fmt.Sprintf("%v[%v].", name, j), fv.Index(j))
+				if err != nil {
+					return nil, err
+				}
+				errs = append(errs, e...)
+			}
+		}
+	}
+	return errs, nil
+}
+
+// jsonFieldName returns the name that the passed in field is encoded with.
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// isEmpty returns whether the passed in value is empty.  An error is returned
+// if emptiness is not defined for the kind of the value.
+func isEmpty(v reflect.Value) (bool, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == "", nil
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0, nil
+	default:
+		return false, fmt.Errorf("kind %v can not be empty", v.Kind())
+	}
+}
+
+// checkRule checks the passed in value, which is a field of the passed in
+// struct value, against a single validation rule.  The reason the value is
+// invalid is returned; an empty string means it is valid.  An error is
+// returned if the rule is unknown or does not apply to the kind of the value.
+func checkRule(parent, v reflect.Value, rule string) (string, error) {
+	empty, err := isEmpty(v)
+	if err != nil {
+		return "", fmt.Errorf("rule %q does not apply to kind %v", rule,
+			v.Kind())
+	}
+
+	switch {
+	case rule == "required":
+		if empty {
+			return "is required", nil
+		}
+	case strings.HasPrefix(rule, "required_without="):
+		name := strings.TrimPrefix(rule, "required_without=")
+		other := parent.FieldByName(name)
+		if !other.IsValid() {
+			return "", fmt.Errorf("rule %q: unknown field %v", rule, name)
+		}
+		otherEmpty, err := isEmpty(other)
+		if err != nil {
+			return "", fmt.Errorf("rule %q: %v", rule, err)
+		}
+		if empty && otherEmpty {
+			return "is required", nil
+		}
+	case rule == "hex":
+		if v.Kind() != reflect.String {
+			return "", fmt.Errorf("rule %q only applies to strings", rule)
+		}
+		if empty {
+			break
+		}
+		if _, err := hex.DecodeString(v.String()); err != nil {
+			return "must be hex encoded", nil
+		}
+	case strings.HasPrefix(rule, "len="):
+		n, err := strconv.Atoi(strings.TrimPrefix(rule, "len="))
+		if err != nil {
+			return "", fmt.Errorf("invalid rule %q", rule)
+		}
+		if !empty && v.Len() != n {
+			return fmt.Sprintf("must be %v characters long", n), nil
+		}
+	default:
+		return "", fmt.Errorf("unknown rule %q", rule)
+	}
+	return "", nil
+}
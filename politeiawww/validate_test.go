@@ -0,0 +1,216 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+func TestRequestTypes(t *testing.T) {
+	// Every request type must only use known rules and an empty
+	// request must fail validation.
+	for route, rt := range requestTypes {
+		t.Run(route, func(t *testing.T) {
+			errs, err := validateStruct(rt.newRequest())
+			if err != nil {
+				t.Fatalf("validateStruct: %v", err)
+			}
+			if len(errs) == 0 {
+				t.Errorf("empty request passed validation")
+			}
+		})
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	token := strings.Repeat("ab", 32)
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		req  interface{}
+		want []string
+	}{
+		{"valid",
+			www.AuthorizeVote{
+				Action:    www.AuthVoteActionAuthorize,
+				Token:     token,
+				Signature: "00",
+				PublicKey: "00",
+			}, nil},
+		{"missing fields",
+			&www.AuthorizeVote{
+				Action: " ",
+				Token:  token,
+			},
+			[]string{
+				"action: is required",
+				"signature: is required",
+				"publickey: is required",
+			}},
+		{"invalid hex and length",
+			www.AuthorizeVote{
+				Action:    www.AuthVoteActionAuthorize,
+				Token:     "abcd",
+				Signature: "zz",
+				PublicKey: "00",
+			},
+			[]string{
+				"token: must be 64 characters long",
+				"signature: must be hex encoded",
+			}},
+		{"nested files",
+			www.NewProposal{
+				Files: []www.File{
+					{
+						Name:    "index.md",
+						MIME:    "text/plain; charset=utf-8",
+						Digest:  "00",
+						Payload: "00",
+					},
+					{
+						Name:   "image.png",
+						Digest: "zz",
+					},
+				},
+				PublicKey: "00",
+				Signature: "00",
+			},
+			[]string{
+				"files[1].mime: is required",
+				"files[1].digest: must be hex encoded",
+				"files[1].payload: is required",
+			}},
+		{"file with upload id",
+			www.NewProposal{
+				Files: []www.File{
+					{
+						Name:     "index.md",
+						MIME:     "text/plain; charset=utf-8",
+						Digest:   "00",
+						UploadID: "upload",
+					},
+				},
+				PublicKey: "00",
+				Signature: "00",
+			}, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			errs, err := validateStruct(v.req)
+			if err != nil {
+				t.Fatalf("validateStruct: %v", err)
+			}
+			got := fieldErrorStrings(errs)
+			if len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestValidateStructInvalidRules(t *testing.T) {
+	var tests = []struct {
+		name string
+		req  interface{}
+	}{
+		{"unknown rule", struct {
+			Files []www.File `validate:"dive"`
+		}{}},
+		{"rule of other kind", struct {
+			Count int `validate:"required"`
+		}{}},
+		{"hex slice", struct {
+			Files []www.File `validate:"hex"`
+		}{}},
+		{"invalid length", struct {
+			Token string `validate:"len=x"`
+		}{}},
+		{"unknown required without field", struct {
+			Payload string `validate:"required_without=Other"`
+		}{}},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := validateStruct(v.req)
+			if err == nil {
+				t.Errorf("got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestRequestTypeErrorCode(t *testing.T) {
+	rt := requestTypes[www.RouteNewUser]
+
+	var tests = []struct {
+		name string
+		req  www.NewUser
+		want www.ErrorStatusT
+	}{
+		{"malformed email",
+			www.NewUser{
+				Password:  "password",
+				PublicKey: "00",
+				Username:  "user",
+			}, www.ErrorStatusMalformedEmail},
+		{"invalid public key",
+			www.NewUser{
+				Email:     "user@example.com",
+				Password:  "password",
+				PublicKey: "zz",
+				Username:  "user",
+			}, www.ErrorStatusInvalidPublicKey},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			errs, err := validateStruct(v.req)
+			if err != nil {
+				t.Fatalf("validateStruct: %v", err)
+			}
+			got := rt.errorCode(errs)
+			if got != v.want {
+				t.Errorf("got error code %v, want %v", got, v.want)
+			}
+		})
+	}
+
+	// Fields without a specific error code are invalid input
+	errs := []fieldError{{field: "other", reason: "is required"}}
+	if got := rt.errorCode(errs); got != www.ErrorStatusInvalidInput {
+		t.Errorf("got error code %v, want %v", got,
+			www.ErrorStatusInvalidInput)
+	}
+}
+
+func TestUnknownFieldError(t *testing.T) {
+	d := json.NewDecoder(strings.NewReader(`{"emial":"user@example.com"}`))
+	d.DisallowUnknownFields()
+	err := d.Decode(new(www.Login))
+
+	fe, ok := unknownFieldError(err)
+	if !ok {
+		t.Fatalf("got no unknown field error for %v", err)
+	}
+	if got, want := fe.String(), "emial: unknown field"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Other decode errors are not unknown field errors
+	err = json.Unmarshal([]byte(`{"email":`), new(www.Login))
+	if _, ok := unknownFieldError(err); ok {
+		t.Errorf("got unknown field error for %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// voteEndsFilename is the name of the file, relative to the data
+	// directory, that the end heights of the running proposal votes are
+	// stored in.
+	voteEndsFilename = "voteends.json"
+)
+
+// voteFinishedInterval is the amount of time between the checks for proposal
+// votes that have finished.
+var voteFinishedInterval = time.Minute
+
+// voteEndsFile returns the path of the file that the end heights of the
+// running proposal votes are stored in.
+func (p *politeiawww) voteEndsFile() string {
+	return filepath.Join(p.cfg.DataDir, voteEndsFilename)
+}
+
+// initVoteEnds loads the end heights of the running proposal votes from disk.
+// If they have never been stored, the running votes are looked up in the
+// cache once; votes that are started afterwards are added by addVoteEnd.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) initVoteEnds() error {
+	b, err := ioutil.ReadFile(p.voteEndsFile())
+	if err == nil {
+		ends := make(map[string]uint64)
+		err = json.Unmarshal(b, &ends)
+		if err != nil {
+			return fmt.Errorf("unmarshal %v: %v", p.voteEndsFile(), err)
+		}
+
+		p.Lock()
+		p.voteEnds = ends
+		p.Unlock()
+
+		log.Infof("Loaded %v running proposal votes", len(ends))
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	bestBlock, err := p.getBestBlock()
+	if err != nil {
+		return fmt.Errorf("getBestBlock: %v", err)
+	}
+	props, err := p.getAllProps()
+	if err != nil {
+		return fmt.Errorf("getAllProps: %v", err)
+	}
+
+	ends := make(map[string]uint64)
+	for _, v := range props {
+		// Only public proposals can be voted on
+		if v.Status != www.PropStatusPublic {
+			continue
+		}
+
+		token := v.CensorshipRecord.Token
+		vdr, err := p.decredVoteDetails(token)
+		if err != nil {
+			return fmt.Errorf("decredVoteDetails %v: %v", token, err)
+		}
+		vd := convertVoteDetailsReplyFromDecred(*vdr)
+		if getVoteStatus(vd.AuthorizeVoteReply, vd.StartVoteReply,
+			bestBlock) != www.PropVoteStatusStarted {
+			continue
+		}
+
+		end, err := strconv.ParseUint(vd.StartVoteReply.EndHeight, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid end height %v: %v", token, err)
+		}
+		ends[token] = end
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.voteEnds = ends
+	return p._saveVoteEnds()
+}
+
+// _saveVoteEnds writes the end heights of the running proposal votes to disk.
+//
+// This function must be called WITH the lock held.
+func (p *politeiawww) _saveVoteEnds() error {
+	b, err := json.Marshal(p.voteEnds)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(p.voteEndsFile(), b, 0600)
+}
+
+// addVoteEnd records the end height of a proposal vote that has been started
+// so that the vote finished event is fired once the vote ends.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) addVoteEnd(token, endHeight string) error {
+	end, err := strconv.ParseUint(endHeight, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid end height %v: %v", endHeight, err)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.voteEnds[token] = end
+	return p._saveVoteEnds()
+}
+
+// checkVotesFinished fires a vote finished event for every running proposal
+// vote whose end height has been reached by the current best block.  A vote
+// stops being tracked once its event has been fired, so votes that finished
+// while politeiawww was not running are reported by the first check.
+func (p *politeiawww) checkVotesFinished() error {
+	bestBlock, err := p.getBestBlock()
+	if err != nil {
+		return fmt.Errorf("getBestBlock: %v", err)
+	}
+
+	p.RLock()
+	finished := make([]string, 0, len(p.voteEnds))
+	for token, end := range p.voteEnds {
+		if bestBlock >= end {
+			finished = append(finished, token)
+		}
+	}
+	p.RUnlock()
+
+	for _, token := range finished {
+		pr, err := p.getProp(token)
+		if err != nil {
+			return fmt.Errorf("getProp %v: %v", token, err)
+		}
+		vs, err := p.getVoteStatus(token, bestBlock)
+		if err != nil {
+			return fmt.Errorf("getVoteStatus %v: %v", token, err)
+		}
+		p.fireEvent(EventTypeProposalVoteFinished,
+			EventDataProposalVoteFinished{
+				Proposal:   pr,
+				VoteStatus: vs,
+			})
+
+		p.Lock()
+		delete(p.voteEnds, token)
+		err = p._saveVoteEnds()
+		p.Unlock()
+		if err != nil {
+			return fmt.Errorf("_saveVoteEnds: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// checkForFinishedVotes checks for proposal votes that have finished once
+// every voteFinishedInterval.
+func (p *politeiawww) checkForFinishedVotes() {
+	ticker := time.NewTicker(voteFinishedInterval)
+	defer ticker.Stop()
+
+	for {
+		err := p.checkVotesFinished()
+		if err != nil {
+			log.Errorf("checkVotesFinished: %v", err)
+		}
+
+		<-ticker.C
+	}
+}
+
+// initVoteFinished loads the running proposal votes and starts the thread
+// that fires the vote finished events.  It must be called after the event
+// manager has been setup.
+func (p *politeiawww) initVoteFinished() error {
+	err := p.initVoteEnds()
+	if err != nil {
+		return err
+	}
+
+	go p.checkForFinishedVotes()
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestVoteEndsPersisted(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	token := "abf0fd1fc1b8c1c9535685373dce6c54948b7eb018e17e3a8cea26a3c9b85684"
+	err := p.addVoteEnd(token, "invalid")
+	if err == nil {
+		t.Fatalf("invalid end height: got nil error, want error")
+	}
+	err = p.addVoteEnd(token, "1000")
+	if err != nil {
+		t.Fatalf("addVoteEnd: %v", err)
+	}
+
+	// The running votes are loaded from disk so that a vote that ends
+	// while politeiawww is not running is still reported.
+	p.voteEnds = make(map[string]uint64)
+	err = p.initVoteEnds()
+	if err != nil {
+		t.Fatalf("initVoteEnds: %v", err)
+	}
+	if got := p.voteEnds[token]; got != 1000 {
+		t.Fatalf("got end height %v, want 1000", got)
+	}
+}
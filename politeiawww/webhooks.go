@@ -0,0 +1,426 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// webhooksFilename is the name of the file, relative to the data
+	// directory, that the webhook subscriptions are stored in.
+	webhooksFilename = "webhooks.json"
+
+	// webhookSecretSize is the size of the webhook HMAC secret in bytes.
+	webhookSecretSize = 32
+
+	// webhookTimeout is the amount of time a webhook endpoint has to
+	// respond to a delivery.
+	webhookTimeout = 10 * time.Second
+
+	// webhookMaxAttempts is the number of times a delivery is attempted
+	// before it is dropped.
+	webhookMaxAttempts = 4
+)
+
+var (
+	// webhookRetryDelay is the amount of time to wait before the first
+	// retry of a failed delivery.  The delay is doubled after each
+	// failed attempt.
+	webhookRetryDelay = 5 * time.Second
+
+	// webhookClient is the http client that is used to deliver webhooks.
+	webhookClient = &http.Client{
+		Timeout: webhookTimeout,
+	}
+
+	// validWebhookEvents contains the events that a webhook can subscribe
+	// to.
+	validWebhookEvents = map[string]struct{}{
+		www.WebhookEventProposalSubmitted: {},
+		www.WebhookEventProposalPublic:    {},
+		www.WebhookEventProposalCensored:  {},
+		www.WebhookEventProposalAbandoned: {},
+		www.WebhookEventProposalWithdrawn: {},
+		www.WebhookEventVoteStarted:       {},
+		www.WebhookEventProposalApproved:  {},
+	}
+)
+
+// webhook is a webhook subscription along with the secret that is used to
+// sign its deliveries.
+type webhook struct {
+	www.Webhook
+	Secret string `json:"secret"`
+}
+
+// subscribed returns whether the webhook is subscribed to the given event.
+func (w *webhook) subscribed(event string) bool {
+	for _, v := range w.Events {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookSignature returns the hex encoded HMAC-SHA256 of the body, keyed with
+// the webhook secret.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhooksFile returns the path of the file that the webhook subscriptions are
+// stored in.
+func (p *politeiawww) webhooksFile() string {
+	return filepath.Join(p.cfg.DataDir, webhooksFilename)
+}
+
+// initWebhooks loads the webhook subscriptions from disk.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) initWebhooks() error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.webhooks = make(map[string]webhook)
+	b, err := ioutil.ReadFile(p.webhooksFile())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var webhooks []webhook
+	err = json.Unmarshal(b, &webhooks)
+	if err != nil {
+		return fmt.Errorf("unmarshal %v: %v", p.webhooksFile(), err)
+	}
+	for _, v := range webhooks {
+		p.webhooks[v.ID] = v
+	}
+
+	log.Infof("Loaded %v webhooks", len(p.webhooks))
+
+	return nil
+}
+
+// _saveWebhooks writes the webhook subscriptions to disk.
+//
+// This function must be called WITH the lock held.
+func (p *politeiawww) _saveWebhooks() error {
+	webhooks := make([]webhook, 0, len(p.webhooks))
+	for _, v := range p.webhooks {
+		webhooks = append(webhooks, v)
+	}
+	b, err := json.Marshal(webhooks)
+	if err != nil {
+		return err
+	}
+
+	return util.WriteFileAtomic(p.webhooksFile(), b, 0600)
+}
+
+// processNewWebhook registers a new webhook subscription.  The secret that is
+// used to sign the webhook deliveries is only returned in the reply.
+func (p *politeiawww) processNewWebhook(nw www.NewWebhook, adminUser *user.User) (*www.NewWebhookReply, error) {
+	log.Tracef("processNewWebhook: %v", nw.URL)
+
+	u, err := url.Parse(nw.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+		u.Host == "" {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidWebhookURL,
+		}
+	}
+
+	if len(nw.Events) == 0 {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidWebhookEvent,
+		}
+	}
+	events := make([]string, 0, len(nw.Events))
+	seen := make(map[string]struct{}, len(nw.Events))
+	for _, v := range nw.Events {
+		if _, ok := validWebhookEvents[v]; !ok {
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidWebhookEvent,
+				ErrorContext: []string{v},
+			}
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		events = append(events, v)
+	}
+
+	secret, err := util.Random(webhookSecretSize)
+	if err != nil {
+		return nil, err
+	}
+	wh := webhook{
+		Webhook: www.Webhook{
+			ID:        uuid.New().String(),
+			URL:       u.String(),
+			Events:    events,
+			Timestamp: time.Now().Unix(),
+		},
+		Secret: hex.EncodeToString(secret),
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.webhooks[wh.ID] = wh
+	err = p._saveWebhooks()
+	if err != nil {
+		delete(p.webhooks, wh.ID)
+		return nil, err
+	}
+
+	err = p._logAdminAction(adminUser, fmt.Sprintf("new webhook,%v,%v",
+		wh.ID, wh.URL))
+	if err != nil {
+		log.Errorf("could not log action to file: %v", err)
+	}
+
+	return &www.NewWebhookReply{
+		Webhook: wh.Webhook,
+		Secret:  wh.Secret,
+	}, nil
+}
+
+// processWebhooks returns all webhook subscriptions ordered by creation time.
+// The webhook secrets are not returned.
+func (p *politeiawww) processWebhooks() (*www.WebhooksReply, error) {
+	log.Tracef("processWebhooks")
+
+	p.RLock()
+	webhooks := make([]www.Webhook, 0, len(p.webhooks))
+	for _, v := range p.webhooks {
+		webhooks = append(webhooks, v.Webhook)
+	}
+	p.RUnlock()
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		if webhooks[i].Timestamp != webhooks[j].Timestamp {
+			return webhooks[i].Timestamp < webhooks[j].Timestamp
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return &www.WebhooksReply{
+		Webhooks: webhooks,
+	}, nil
+}
+
+// processDeleteWebhook deletes a webhook subscription.
+func (p *politeiawww) processDeleteWebhook(dw www.DeleteWebhook, adminUser *user.User) (*www.DeleteWebhookReply, error) {
+	log.Tracef("processDeleteWebhook: %v", dw.ID)
+
+	p.Lock()
+	defer p.Unlock()
+
+	wh, ok := p.webhooks[dw.ID]
+	if !ok {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWebhookNotFound,
+		}
+	}
+
+	delete(p.webhooks, dw.ID)
+	err := p._saveWebhooks()
+	if err != nil {
+		p.webhooks[dw.ID] = wh
+		return nil, err
+	}
+
+	err = p._logAdminAction(adminUser, fmt.Sprintf("delete webhook,%v,%v",
+		wh.ID, wh.URL))
+	if err != nil {
+		log.Errorf("could not log action to file: %v", err)
+	}
+
+	return &www.DeleteWebhookReply{}, nil
+}
+
+// dispatchWebhooks delivers the event to all webhooks that are subscribed to
+// it.  Deliveries are made asynchronously.
+//
+// This function must be called WITHOUT the lock held.
+func (p *politeiawww) dispatchWebhooks(d www.WebhookDelivery) {
+	p.RLock()
+	webhooks := make([]webhook, 0, len(p.webhooks))
+	for _, v := range p.webhooks {
+		if v.subscribed(d.Event) {
+			webhooks = append(webhooks, v)
+		}
+	}
+	p.RUnlock()
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	d.ID = uuid.New().String()
+	d.Timestamp = time.Now().Unix()
+	body, err := json.Marshal(d)
+	if err != nil {
+		log.Errorf("dispatchWebhooks: marshal %v: %v", d.Event, err)
+		return
+	}
+
+	for _, v := range webhooks {
+		go p.deliverWebhook(v, d.Event, body)
+	}
+}
+
+// deliverWebhook sends the body to the webhook endpoint.  Failed deliveries
+// are retried with an exponential backoff until webhookMaxAttempts is reached.
+func (p *politeiawww) deliverWebhook(wh webhook, event string, body []byte) {
+	delay := webhookRetryDelay
+	for i := 1; i <= webhookMaxAttempts; i++ {
+		err := sendWebhook(wh, event, body)
+		if err == nil {
+			log.Debugf("Webhook %v delivered %v", wh.ID, event)
+			return
+		}
+
+		log.Errorf("webhook %v: attempt %v of %v: %v", wh.ID, i,
+			webhookMaxAttempts, err)
+		if i < webhookMaxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	log.Errorf("webhook %v: dropping %v delivery", wh.ID, event)
+}
+
+// sendWebhook makes a single webhook delivery attempt.  Any response status
+// other than 2xx is considered a failure.
+func sendWebhook(wh webhook, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(www.WebhookEventHeader, event)
+	req.Header.Set(www.WebhookSignatureHeader,
+		webhookSignature(wh.Secret, body))
+
+	r, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %v", r.StatusCode)
+	}
+
+	return nil
+}
+
+// _setupWebhookNotifications registers the event listeners that deliver the
+// proposal lifecycle events to the webhook subscribers.
+//
+// This function must be called WITH the mutex held.
+func (p *politeiawww) _setupWebhookNotifications() {
+	ch := make(chan interface{})
+	go func() {
+		for data := range ch {
+			switch e := data.(type) {
+			case EventDataProposalSubmitted:
+				p.dispatchWebhooks(www.WebhookDelivery{
+					Event:  www.WebhookEventProposalSubmitted,
+					Token:  e.CensorshipRecord.Token,
+					Name:   e.ProposalName,
+					Status: www.PropStatusNotReviewed,
+				})
+
+			case EventDataProposalStatusChange:
+				var event string
+				switch e.SetProposalStatus.ProposalStatus {
+				case www.PropStatusPublic:
+					event = www.WebhookEventProposalPublic
+				case www.PropStatusCensored:
+					event = www.WebhookEventProposalCensored
+				case www.PropStatusAbandoned:
+					event = www.WebhookEventProposalAbandoned
+				default:
+					continue
+				}
+				p.dispatchWebhooks(www.WebhookDelivery{
+					Event:  event,
+					Token:  e.Proposal.CensorshipRecord.Token,
+					Name:   e.Proposal.Name,
+					Status: e.SetProposalStatus.ProposalStatus,
+				})
+
+			case EventDataProposalWithdrawn:
+				p.dispatchWebhooks(www.WebhookDelivery{
+					Event:  www.WebhookEventProposalWithdrawn,
+					Token:  e.Proposal.CensorshipRecord.Token,
+					Name:   e.Proposal.Name,
+					Status: e.Proposal.Status,
+				})
+
+			case EventDataProposalVoteStarted:
+				token := e.StartVote.Vote.Token
+				pr, err := p.getProp(token)
+				if err != nil {
+					log.Errorf("webhook: getProp %v: %v", token, err)
+					continue
+				}
+				p.dispatchWebhooks(www.WebhookDelivery{
+					Event:  www.WebhookEventVoteStarted,
+					Token:  token,
+					Name:   pr.Name,
+					Status: pr.Status,
+				})
+
+			case EventDataProposalVoteFinished:
+				if !voteApproved(e.VoteStatus) {
+					continue
+				}
+				p.dispatchWebhooks(www.WebhookDelivery{
+					Event:  www.WebhookEventProposalApproved,
+					Token:  e.Proposal.CensorshipRecord.Token,
+					Name:   e.Proposal.Name,
+					Status: e.Proposal.Status,
+				})
+
+			default:
+				log.Errorf("invalid event data")
+			}
+		}
+	}()
+	p.eventManager._register(EventTypeProposalSubmitted, ch)
+	p.eventManager._register(EventTypeProposalStatusChange, ch)
+	p.eventManager._register(EventTypeProposalWithdrawn, ch)
+	p.eventManager._register(EventTypeProposalVoteStarted, ch)
+	p.eventManager._register(EventTypeProposalVoteFinished, ch)
+}
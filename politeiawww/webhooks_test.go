@@ -0,0 +1,293 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/v1"
+)
+
+// webhookRequest is a webhook delivery that was received by a test server.
+type webhookRequest struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+// newWebhookServer returns a test server that responds to the first failures
+// requests with a 500 and to all requests after that with a 200.  Every
+// request that is received is sent on the returned channel.
+func newWebhookServer(failures int) (*httptest.Server, chan webhookRequest) {
+	ch := make(chan webhookRequest, 10)
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests++
+			if requests <= failures {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			ch <- webhookRequest{
+				event:     r.Header.Get(v1.WebhookEventHeader),
+				signature: r.Header.Get(v1.WebhookSignatureHeader),
+				body:      body,
+			}
+		}))
+	return s, ch
+}
+
+// receiveWebhook returns the next webhook request that is received on the
+// channel or fails the test if none is received.
+func receiveWebhook(t *testing.T, ch chan webhookRequest) webhookRequest {
+	t.Helper()
+
+	select {
+	case wr := <-ch:
+		return wr
+	case <-time.After(5 * time.Second):
+		t.Fatalf("webhook was not delivered")
+	}
+	return webhookRequest{}
+}
+
+func TestProcessNewWebhook(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+
+	// Setup tests
+	var tests = []struct {
+		name   string
+		url    string
+		events []string
+		want   error
+	}{
+		{"invalid url", "://example.com",
+			[]string{v1.WebhookEventProposalPublic},
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidWebhookURL,
+			}},
+		{"invalid scheme", "ftp://example.com",
+			[]string{v1.WebhookEventProposalPublic},
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidWebhookURL,
+			}},
+		{"no events", "https://example.com/hook", nil,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidWebhookEvent,
+			}},
+		{"invalid event", "https://example.com/hook",
+			[]string{"proposaldeleted"},
+			v1.UserError{
+				ErrorCode:    v1.ErrorStatusInvalidWebhookEvent,
+				ErrorContext: []string{"proposaldeleted"},
+			}},
+		{"success", "https://example.com/hook",
+			[]string{v1.WebhookEventProposalPublic,
+				v1.WebhookEventVoteStarted}, nil},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processNewWebhook(v1.NewWebhook{
+				URL:    v.url,
+				Events: v.events,
+			}, admin)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+
+	// The webhook must be persisted across restarts
+	err := p.initWebhooks()
+	if err != nil {
+		t.Fatalf("initWebhooks: %v", err)
+	}
+	wr, err := p.processWebhooks()
+	if err != nil {
+		t.Fatalf("processWebhooks: %v", err)
+	}
+	if len(wr.Webhooks) != 1 {
+		t.Fatalf("got %v webhooks, want 1", len(wr.Webhooks))
+	}
+	if wr.Webhooks[0].URL != "https://example.com/hook" {
+		t.Fatalf("got url %v, want https://example.com/hook",
+			wr.Webhooks[0].URL)
+	}
+}
+
+func TestProcessDeleteWebhook(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	admin, _ := newUser(t, p, true)
+	nwr, err := p.processNewWebhook(v1.NewWebhook{
+		URL:    "https://example.com/hook",
+		Events: []string{v1.WebhookEventProposalSubmitted},
+	}, admin)
+	if err != nil {
+		t.Fatalf("processNewWebhook: %v", err)
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name string
+		id   string
+		want error
+	}{
+		{"success", nwr.Webhook.ID, nil},
+		{"not found", nwr.Webhook.ID,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusWebhookNotFound,
+			}},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := p.processDeleteWebhook(v1.DeleteWebhook{
+				ID: v.id,
+			}, admin)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Errorf("got error %v, want %v",
+					got, want)
+			}
+		})
+	}
+}
+
+func TestDispatchWebhooks(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	defer func(d time.Duration) {
+		webhookRetryDelay = d
+	}(webhookRetryDelay)
+	webhookRetryDelay = time.Millisecond
+
+	// The first delivery attempt fails and must be retried
+	s, ch := newWebhookServer(1)
+	defer s.Close()
+
+	admin, _ := newUser(t, p, true)
+	nwr, err := p.processNewWebhook(v1.NewWebhook{
+		URL:    s.URL,
+		Events: []string{v1.WebhookEventProposalPublic},
+	}, admin)
+	if err != nil {
+		t.Fatalf("processNewWebhook: %v", err)
+	}
+
+	// Events that the webhook is not subscribed to are not delivered
+	p.dispatchWebhooks(v1.WebhookDelivery{
+		Event: v1.WebhookEventVoteStarted,
+	})
+	p.dispatchWebhooks(v1.WebhookDelivery{
+		Event:  v1.WebhookEventProposalPublic,
+		Token:  "token",
+		Status: v1.PropStatusPublic,
+	})
+
+	for i := 0; i < 2; i++ {
+		wr := receiveWebhook(t, ch)
+		if wr.event != v1.WebhookEventProposalPublic {
+			t.Fatalf("attempt %v: got event %v, want %v", i, wr.event,
+				v1.WebhookEventProposalPublic)
+		}
+		sig := webhookSignature(nwr.Secret, wr.body)
+		if wr.signature != sig {
+			t.Fatalf("attempt %v: got signature %v, want %v", i,
+				wr.signature, sig)
+		}
+	}
+
+	select {
+	case wr := <-ch:
+		t.Fatalf("unexpected delivery of %v", wr.event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookProposalApproved(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	s, ch := newWebhookServer(0)
+	defer s.Close()
+
+	admin, _ := newUser(t, p, true)
+	_, err := p.processNewWebhook(v1.NewWebhook{
+		URL:    s.URL,
+		Events: []string{v1.WebhookEventProposalApproved},
+	}, admin)
+	if err != nil {
+		t.Fatalf("processNewWebhook: %v", err)
+	}
+	p.initEventManager()
+
+	voteFinished := func(token string, approve uint64) EventDataProposalVoteFinished {
+		return EventDataProposalVoteFinished{
+			Proposal: &v1.ProposalRecord{
+				Name:   "name",
+				Status: v1.PropStatusPublic,
+				CensorshipRecord: v1.CensorshipRecord{
+					Token: token,
+				},
+			},
+			VoteStatus: &v1.VoteStatusReply{
+				Token:  token,
+				Status: v1.PropVoteStatusFinished,
+				OptionsResult: []v1.VoteOptionResult{
+					{
+						Option:        v1.VoteOption{Bits: 0x01},
+						VotesReceived: 10 - approve,
+					},
+					{
+						Option:        v1.VoteOption{Bits: voteBitApprove},
+						VotesReceived: approve,
+					},
+				},
+				NumOfEligibleVotes: 10,
+				QuorumPercentage:   20,
+				PassPercentage:     60,
+			},
+		}
+	}
+
+	// Only the approved vote is delivered
+	p.fireEvent(EventTypeProposalVoteFinished, voteFinished("rejected", 2))
+	p.fireEvent(EventTypeProposalVoteFinished, voteFinished("approved", 8))
+
+	wr := receiveWebhook(t, ch)
+	var d v1.WebhookDelivery
+	err = json.Unmarshal(wr.body, &d)
+	if err != nil {
+		t.Fatalf("unmarshal delivery: %v", err)
+	}
+	if wr.event != v1.WebhookEventProposalApproved || d.Token != "approved" {
+		t.Fatalf("got event %v for %v, want %v for approved", wr.event,
+			d.Token, v1.WebhookEventProposalApproved)
+	}
+
+	select {
+	case wr := <-ch:
+		t.Fatalf("unexpected delivery of %v", wr.event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
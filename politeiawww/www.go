@@ -7,6 +7,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/elliptic"
 	"crypto/tls"
 	_ "encoding/gob"
@@ -24,7 +25,6 @@ import (
 	"strings"
 	"sync"
 	"syscall"
-	"text/template"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -50,13 +50,18 @@ const (
 	permissionAdmin
 
 	csrfKeyLength = 32
-	sessionMaxAge = 86400 //One day
 )
 
 var (
 	// ErrSessionUUIDNotFound is emitted when a UUID value is not found
 	// in a session and indicates that the user is not logged in.
 	ErrSessionUUIDNotFound = errors.New("session UUID not found")
+
+	// ErrSessionExpired is emitted when the request contains a session
+	// cookie but the session can no longer be found in the session store,
+	// which indicates that the user was logged in but the session has
+	// expired or has been revoked.
+	ErrSessionExpired = errors.New("session expired")
 )
 
 // wsContext is the websocket context. If uuid == "" then it is an
@@ -126,81 +131,98 @@ func (p *politeiawww) getIdentity() error {
 
 // RespondWithError returns an HTTP error status to the client. If it's a user
 // error, it returns a 4xx HTTP status and the specific user error code. If it's
-// an internal server error, it returns 500 and an error code which is also
-// outputted to the logs so that it can be correlated later if the user
+// an internal server error, it returns 500 and an error tracking code which is
+// also outputted to the logs so that it can be correlated later if the user
 // files a complaint.
+//
+// The error that is responded with must be the last argument.  The format and
+// args describe where the error occurred and are only written to the server
+// log, so internal error details never reach the client.  The client only
+// receives the error code, the error context of user errors and the generic
+// message of the error code.
 func RespondWithError(w http.ResponseWriter, r *http.Request, userHttpCode int, format string, args ...interface{}) {
-	// XXX this function needs to get an error in and a format + args
-	// instead of what it is doing now.
-	// So inError error, format string, args ...interface{}
-	// if err == nil -> internal error using format + args
-	// if err != nil -> if defined error -> return defined error + log.Errorf format+args
-	// if err != nil -> if !defined error -> return + log.Errorf format+args
-	if userErr, ok := args[0].(v1.UserError); ok {
+	var err error
+	if len(args) > 0 {
+		err, _ = args[len(args)-1].(error)
+	}
+	msg := fmt.Sprintf(format, args...)
+
+	switch e := err.(type) {
+	case v1.UserError:
 		if userHttpCode == 0 {
 			userHttpCode = http.StatusBadRequest
 		}
 
-		if len(userErr.ErrorContext) == 0 {
-			log.Errorf("RespondWithError: %v %v %v",
+		if len(e.ErrorContext) == 0 {
+			requestLog(r).Errorf("RespondWithError: %v %v %v: %v",
 				remoteAddr(r),
-				int64(userErr.ErrorCode),
-				v1.ErrorStatus[userErr.ErrorCode])
+				int64(e.ErrorCode),
+				v1.ErrorStatus[e.ErrorCode],
+				msg)
 		} else {
-			log.Errorf("RespondWithError: %v %v %v: %v",
+			requestLog(r).Errorf("RespondWithError: %v %v %v: %v: %v",
 				remoteAddr(r),
-				int64(userErr.ErrorCode),
-				v1.ErrorStatus[userErr.ErrorCode],
-				strings.Join(userErr.ErrorContext, ", "))
+				int64(e.ErrorCode),
+				v1.ErrorStatus[e.ErrorCode],
+				strings.Join(e.ErrorContext, ", "),
+				msg)
 		}
 
 		util.RespondWithJSON(w, userHttpCode,
 			v1.ErrorReply{
-				ErrorCode:    int64(userErr.ErrorCode),
-				ErrorContext: userErr.ErrorContext,
+				ErrorCode:    int64(e.ErrorCode),
+				ErrorContext: e.ErrorContext,
+				ErrorMessage: v1.ErrorStatus[e.ErrorCode],
 			})
 		return
-	}
 
-	if pdError, ok := args[0].(v1.PDError); ok {
-		pdErrorCode := convertErrorStatusFromPD(pdError.ErrorReply.ErrorCode)
+	case v1.PDError:
+		pdErrorCode := convertErrorStatusFromPD(e.ErrorReply.ErrorCode)
 		if pdErrorCode == v1.ErrorStatusInvalid {
-			errorCode := time.Now().Unix()
-			log.Errorf("%v %v %v %v Internal error %v: error "+
-				"code from politeiad: %v", remoteAddr(r),
-				r.Method, r.URL, r.Proto, errorCode,
-				pdError.ErrorReply.ErrorCode)
-			util.RespondWithJSON(w, http.StatusInternalServerError,
-				v1.ErrorReply{
-					ErrorCode: errorCode,
-				})
-			return
+			// politeiad errors that do not map to a user error
+			// are internal errors
+			break
 		}
 
-		util.RespondWithJSON(w, pdError.HTTPCode,
+		requestLog(r).Errorf("RespondWithError: %v %v %v: %v",
+			remoteAddr(r),
+			int64(pdErrorCode),
+			v1.ErrorStatus[pdErrorCode],
+			msg)
+
+		util.RespondWithJSON(w, e.HTTPCode,
 			v1.ErrorReply{
 				ErrorCode:    int64(pdErrorCode),
-				ErrorContext: pdError.ErrorReply.ErrorContext,
+				ErrorContext: e.ErrorReply.ErrorContext,
+				ErrorMessage: v1.ErrorStatus[pdErrorCode],
 			})
 		return
 	}
 
-	errorCode := time.Now().Unix()
-	ec := fmt.Sprintf("%v %v %v %v Internal error %v: ", remoteAddr(r),
-		r.Method, r.URL, r.Proto, errorCode)
-	log.Errorf(ec+format, args...)
-	log.Errorf("Stacktrace (NOT A REAL CRASH): %s", debug.Stack())
+	errorCode := newErrorTrackingCode()
+	requestLog(r).Errorf("%v %v %v %v Internal error %v: %v",
+		remoteAddr(r), r.Method, r.URL, r.Proto, errorCode, msg)
+	requestLog(r).Errorf("Stacktrace (NOT A REAL CRASH): %s", debug.Stack())
 
 	util.RespondWithJSON(w, http.StatusInternalServerError,
 		v1.ErrorReply{
-			ErrorCode: errorCode,
+			ErrorCode:    errorCode,
+			ErrorMessage: v1.ErrorMessageInternal,
 		})
 }
 
+// newErrorTrackingCode returns the code that identifies an internal server
+// error in both the reply and the server log.  It is the current unix time in
+// microseconds, which keeps concurrent errors distinguishable while staying
+// within the range of integers that JSON clients can represent exactly.
+func newErrorTrackingCode() int64 {
+	return time.Now().UnixNano() / int64(time.Microsecond)
+}
+
 // version is an HTTP GET to determine what version and API route this backend
 // is using.  Additionally it is used to obtain a CSRF token.
 func (p *politeiawww) handleVersion(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleVersion")
+	requestLog(r).Tracef("handleVersion")
 
 	versionReply, err := json.Marshal(v1.VersionReply{
 		Version: v1.PoliteiaWWWAPIVersion,
@@ -236,18 +258,42 @@ func (p *politeiawww) handleVersion(w http.ResponseWriter, r *http.Request) {
 	w.Write(versionReply)
 }
 
-// handleProposalPaywallDetails returns paywall details that allows the user to
-// purchase proposal credits.
-func (p *politeiawww) handleProposalPaywallDetails(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleProposalPaywallDetails")
+// handleHealth reports whether politeiawww and the services it depends on are
+// reachable.  It replies with 503 Service Unavailable if any of them is not.
+func (p *politeiawww) handleHealth(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleHealth")
 
-	user, err := p.getSessionUser(w, r)
+	hr := p.processHealth()
+	status := http.StatusOK
+	if !hr.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	util.RespondWithJSON(w, status, hr)
+}
+
+// handleBlockHeight replies with the best block as seen by the server and
+// whether it is current.
+func (p *politeiawww) handleBlockHeight(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleBlockHeight")
+
+	bhr, err := p.processBlockHeight()
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleProposalPaywallDetails: getSessionUser %v", err)
+			"handleBlockHeight: processBlockHeight: %v", err)
 		return
 	}
 
+	util.RespondWithJSON(w, http.StatusOK, bhr)
+}
+
+// handleProposalPaywallDetails returns paywall details that allows the user to
+// purchase proposal credits.
+func (p *politeiawww) handleProposalPaywallDetails(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleProposalPaywallDetails")
+
+	user := getContextUser(r)
+
 	reply, err := p.ProcessProposalPaywallDetails(user)
 	if err != nil {
 		RespondWithError(w, r, 0,
@@ -261,14 +307,9 @@ func (p *politeiawww) handleProposalPaywallDetails(w http.ResponseWriter, r *htt
 // handleProposalPaywallPayment returns the payment details for a pending
 // proposal paywall payment.
 func (p *politeiawww) handleProposalPaywallPayment(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleProposalPaywallPayment")
+	requestLog(r).Tracef("handleProposalPaywallPayment")
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleProposalPaywallPayment: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	reply, err := p.ProcessProposalPaywallPayment(user)
 	if err != nil {
@@ -408,8 +449,8 @@ func (p *politeiawww) handleWebsocketWrite(wc *wsContext) {
 }
 
 func (p *politeiawww) handleWebsocket(w http.ResponseWriter, r *http.Request, id string) {
-	log.Tracef("handleWebsocket: %v", id)
-	defer log.Tracef("handleWebsocket exit: %v", id)
+	requestLog(r).Tracef("handleWebsocket: %v", id)
+	defer requestLog(r).Tracef("handleWebsocket exit: %v", id)
 
 	// Setup context
 	wc := wsContext{
@@ -483,7 +524,8 @@ func (p *politeiawww) handleUnauthenticatedWebsocket(w http.ResponseWriter, r *h
 	// We are retrieving the uuid here to make sure it is NOT set. This
 	// check looks backwards but is correct.
 	id, err := p.getSessionUUID(r)
-	if err != nil && err != ErrSessionUUIDNotFound {
+	if err != nil && err != ErrSessionUUIDNotFound &&
+		err != ErrSessionExpired {
 		http.Error(w, "Could not get session uuid",
 			http.StatusBadRequest)
 		return
@@ -492,22 +534,17 @@ func (p *politeiawww) handleUnauthenticatedWebsocket(w http.ResponseWriter, r *h
 		http.Error(w, "Invalid session uuid", http.StatusBadRequest)
 		return
 	}
-	log.Tracef("handleUnauthenticatedWebsocket: %v", id)
-	defer log.Tracef("handleUnauthenticatedWebsocket exit: %v", id)
+	requestLog(r).Tracef("handleUnauthenticatedWebsocket: %v", id)
+	defer requestLog(r).Tracef("handleUnauthenticatedWebsocket exit: %v", id)
 
 	p.handleWebsocket(w, r, id)
 }
 
 func (p *politeiawww) handleAuthenticatedWebsocket(w http.ResponseWriter, r *http.Request) {
-	id, err := p.getSessionUUID(r)
-	if err != nil {
-		http.Error(w, "Could not get session uuid",
-			http.StatusBadRequest)
-		return
-	}
+	id := getContextUser(r).ID.String()
 
-	log.Tracef("handleAuthenticatedWebsocket: %v", id)
-	defer log.Tracef("handleAuthenticatedWebsocket exit: %v", id)
+	requestLog(r).Tracef("handleAuthenticatedWebsocket: %v", id)
+	defer requestLog(r).Tracef("handleAuthenticatedWebsocket exit: %v", id)
 
 	p.handleWebsocket(w, r, id)
 }
@@ -515,22 +552,17 @@ func (p *politeiawww) handleAuthenticatedWebsocket(w http.ResponseWriter, r *htt
 // handleNewProposal handles the incoming new proposal command.
 func (p *politeiawww) handleNewProposal(w http.ResponseWriter, r *http.Request) {
 	// Get the new proposal command.
-	log.Tracef("handleNewProposal")
+	requestLog(r).Tracef("handleNewProposal")
 	var np v1.NewProposal
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&np); err != nil {
-		RespondWithError(w, r, 0, "handleNewProposal: unmarshal", v1.UserError{
+		RespondWithError(w, r, 0, "handleNewProposal: unmarshal %v: %v", err, v1.UserError{
 			ErrorCode: v1.ErrorStatusInvalidInput,
 		})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleNewProposal: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	reply, err := p.ProcessNewProposal(np, user)
 	if err != nil {
@@ -547,22 +579,17 @@ func (p *politeiawww) handleNewProposal(w http.ResponseWriter, r *http.Request)
 // It's used for either publishing or censoring a proposal.
 func (p *politeiawww) handleSetProposalStatus(w http.ResponseWriter, r *http.Request) {
 	// Get the proposal status command.
-	log.Tracef("handleSetProposalStatus")
+	requestLog(r).Tracef("handleSetProposalStatus")
 	var sps v1.SetProposalStatus
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&sps); err != nil {
-		RespondWithError(w, r, 0, "handleSetProposalStatus: unmarshal", v1.UserError{
+		RespondWithError(w, r, 0, "handleSetProposalStatus: unmarshal %v: %v", err, v1.UserError{
 			ErrorCode: v1.ErrorStatusInvalidInput,
 		})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleSetProposalStatus: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	// Set status
 	reply, err := p.ProcessSetProposalStatus(sps, user)
@@ -576,17 +603,73 @@ func (p *politeiawww) handleSetProposalStatus(w http.ResponseWriter, r *http.Req
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
+// handleBatchSetProposalStatus handles the incoming batch set proposal
+// status command.  It allows an admin to change the status of multiple
+// proposals with a single request.
+func (p *politeiawww) handleBatchSetProposalStatus(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleBatchSetProposalStatus")
+
+	var bsps v1.BatchSetProposalStatus
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&bsps); err != nil {
+		RespondWithError(w, r, 0, "handleBatchSetProposalStatus: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	reply, err := p.ProcessBatchSetProposalStatus(bsps, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleBatchSetProposalStatus: "+
+				"ProcessBatchSetProposalStatus %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleAbandonProposal handles the incoming abandon proposal command.  It
+// allows the author of a proposal to withdraw it.
+func (p *politeiawww) handleAbandonProposal(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleAbandonProposal")
+
+	var ap v1.AbandonProposal
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ap); err != nil {
+		RespondWithError(w, r, 0, "handleAbandonProposal: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	apr, err := p.ProcessAbandonProposal(ap, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleAbandonProposal: ProcessAbandonProposal %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, apr)
+}
+
 // handleProposalDetails handles the incoming proposal details command. It fetches
 // the complete details for an existing proposal.
 func (p *politeiawww) handleProposalDetails(w http.ResponseWriter, r *http.Request) {
 	// Add the path param to the struct.
-	log.Tracef("handleProposalDetails")
+	requestLog(r).Tracef("handleProposalDetails")
 	var pd v1.ProposalsDetails
 
 	// get version from query string parameters
 	err := util.ParseGetParams(r, &pd)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleProposalDetails: ParseGetParams",
+		RespondWithError(w, r, 0, "handleProposalDetails: ParseGetParams %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -599,7 +682,7 @@ func (p *politeiawww) handleProposalDetails(w http.ResponseWriter, r *http.Reque
 
 	user, err := p.getSessionUser(w, r)
 	if err != nil {
-		if err != ErrSessionUUIDNotFound {
+		if err != ErrSessionUUIDNotFound && err != ErrSessionExpired {
 			RespondWithError(w, r, 0,
 				"handleProposalDetails: getSessionUser %v", err)
 			return
@@ -616,11 +699,57 @@ func (p *politeiawww) handleProposalDetails(w http.ResponseWriter, r *http.Reque
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
+// handleProposalMetadata handles the incoming proposal metadata command.  It
+// returns all metadata streams of a proposal.
+func (p *politeiawww) handleProposalMetadata(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleProposalMetadata")
+
+	pathParams := mux.Vars(r)
+
+	user, err := p.getSessionUser(w, r)
+	if err != nil {
+		if err != ErrSessionUUIDNotFound && err != ErrSessionExpired {
+			RespondWithError(w, r, 0,
+				"handleProposalMetadata: getSessionUser %v", err)
+			return
+		}
+	}
+	pmr, err := p.processProposalMetadata(pathParams["token"], user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleProposalMetadata: processProposalMetadata %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, pmr)
+}
+
+// handleProposalBundle handles the incoming proposal bundle command.  It
+// returns a public proposal as an archive that can be verified offline.
+func (p *politeiawww) handleProposalBundle(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleProposalBundle")
+
+	pathParams := mux.Vars(r)
+	pbr, err := p.processProposalBundle(pathParams["token"])
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleProposalBundle: processProposalBundle %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, pbr)
+}
+
 func (p *politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 	// Get the policy command.
-	log.Tracef("handlePolicy")
+	requestLog(r).Tracef("handlePolicy")
 	reply := &v1.PolicyReply{
-		MinPasswordLength:          v1.PolicyMinPasswordLength,
+		MinPasswordLength:          p.cfg.PasswordMinLength,
+		PasswordRequireUpper:       p.cfg.PasswordRequireUpper,
+		PasswordRequireLower:       p.cfg.PasswordRequireLower,
+		PasswordRequireDigit:       p.cfg.PasswordRequireDigit,
+		PasswordRequireSymbol:      p.cfg.PasswordRequireSymbol,
+		PasswordMinStrength:        p.cfg.PasswordMinStrength,
 		MinUsernameLength:          v1.PolicyMinUsernameLength,
 		MaxUsernameLength:          v1.PolicyMaxUsernameLength,
 		UsernameSupportedChars:     v1.PolicyUsernameSupportedChars,
@@ -635,19 +764,28 @@ func (p *politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 		MaxProposalNameLength:      v1.PolicyMaxProposalNameLength,
 		ProposalNameSupportedChars: v1.PolicyProposalNameSupportedChars,
 		MaxCommentLength:           v1.PolicyMaxCommentLength,
+		CommentEditPeriod:          v1.PolicyCommentEditPeriod,
+		CommentDeletePeriod:        v1.PolicyCommentDeletePeriod,
+		CommentReactions:           p.cfg.CommentReactions,
+		MinCensorReasonLength:      v1.PolicyMinCensorReasonLength,
+		MaxCommentFiles:            v1.PolicyMaxCommentFiles,
+		MaxCommentFileSize:         v1.PolicyMaxCommentFileSize,
+		MaxDrafts:                  v1.PolicyMaxDrafts,
+		MaxDraftSize:               v1.PolicyMaxDraftSize,
+		ListLimit:                  true,
 	}
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
 // handleAllVetted replies with the list of vetted proposals.
 func (p *politeiawww) handleAllVetted(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleAllVetted")
+	requestLog(r).Tracef("handleAllVetted")
 
 	// Get the all vetted command.
 	var v v1.GetAllVetted
 	err := util.ParseGetParams(r, &v)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleAllVetted: ParseGetParams",
+		RespondWithError(w, r, 0, "handleAllVetted: ParseGetParams %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -661,18 +799,20 @@ func (p *politeiawww) handleAllVetted(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, vr)
+	// Clients that poll the inventory can avoid refetching an unchanged
+	// page by sending the ETag of their cached copy.
+	util.RespondWithJSONETag(w, r, vr)
 }
 
 // handleAllUnvetted replies with the list of unvetted proposals.
 func (p *politeiawww) handleAllUnvetted(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleAllUnvetted")
+	requestLog(r).Tracef("handleAllUnvetted")
 
 	// Get the all unvetted command.
 	var u v1.GetAllUnvetted
 	err := util.ParseGetParams(r, &u)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleAllUnvetted: ParseGetParams",
+		RespondWithError(w, r, 0, "handleAllUnvetted: ParseGetParams %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -686,60 +826,136 @@ func (p *politeiawww) handleAllUnvetted(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, ur)
+	util.RespondWithJSONETag(w, r, ur)
 }
 
 // handleNewComment handles incomming comments.
 func (p *politeiawww) handleNewComment(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleNewComment")
+	requestLog(r).Tracef("handleNewComment")
 
 	var sc v1.NewComment
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&sc); err != nil {
-		RespondWithError(w, r, 0, "handleNewComment: unmarshal",
+		RespondWithError(w, r, 0, "handleNewComment: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
+	user := getContextUser(r)
+
+	cr, err := p.ProcessNewComment(sc, user)
+	if err != nil {
+		// Rate limited comments are rejected the same way as rate
+		// limited requests so that clients can wait and retry.
+		var code int
+		e, ok := err.(v1.UserError)
+		if ok && e.ErrorCode == v1.ErrorStatusRateLimitExceeded {
+			code = http.StatusTooManyRequests
+			w.Header().Set("Retry-After", e.ErrorContext[0])
+		}
+		RespondWithError(w, r, code,
+			"handleNewComment: ProcessNewComment: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, cr)
+}
+
+// handleEditComment handles the editing of a comment by its author.
+func (p *politeiawww) handleEditComment(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleEditComment")
+
+	var ec v1.EditComment
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ec); err != nil {
+		RespondWithError(w, r, 0, "handleEditComment: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	ecr, err := p.ProcessEditComment(ec, user)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleNewComment: getSessionUser %v", err)
+			"handleEditComment: ProcessEditComment: %v", err)
 		return
 	}
 
-	cr, err := p.ProcessNewComment(sc, user)
+	util.RespondWithJSON(w, http.StatusOK, ecr)
+}
+
+// handleDeleteComment handles the deletion of a comment by its author.
+func (p *politeiawww) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleDeleteComment")
+
+	var dc v1.DeleteComment
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&dc); err != nil {
+		RespondWithError(w, r, 0, "handleDeleteComment: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	dcr, err := p.ProcessDeleteComment(dc, user)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleNewComment: ProcessNewComment: %v", err)
+			"handleDeleteComment: ProcessDeleteComment: %v", err)
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, cr)
+	util.RespondWithJSON(w, http.StatusOK, dcr)
+}
+
+// handleReactComment handles adding and removing comment reactions.
+func (p *politeiawww) handleReactComment(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleReactComment")
+
+	var rc v1.ReactComment
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rc); err != nil {
+		RespondWithError(w, r, 0, "handleReactComment: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	rcr, err := p.ProcessReactComment(rc, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleReactComment: ProcessReactComment: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rcr)
 }
 
 // handleLikeComment handles up or down voting of commentd.
 func (p *politeiawww) handleLikeComment(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleLikeComment")
+	requestLog(r).Tracef("handleLikeComment")
 
 	var lc v1.LikeComment
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&lc); err != nil {
-		RespondWithError(w, r, 0, "handleLikeComment: unmarshal",
+		RespondWithError(w, r, 0, "handleLikeComment: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleLikeComment: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	cr, err := p.ProcessLikeComment(lc, user)
 	if err != nil {
@@ -753,24 +969,19 @@ func (p *politeiawww) handleLikeComment(w http.ResponseWriter, r *http.Request)
 
 // handleCensorComment handles the censoring of a comment by an admin.
 func (p *politeiawww) handleCensorComment(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleCensorComment")
+	requestLog(r).Tracef("handleCensorComment")
 
 	var cc v1.CensorComment
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&cc); err != nil {
-		RespondWithError(w, r, 0, "handleCensorComment: unmarshal",
+		RespondWithError(w, r, 0, "handleCensorComment: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleCensorComment: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
 	cr, err := p.ProcessCensorComment(cc, user)
 	if err != nil {
@@ -784,20 +995,30 @@ func (p *politeiawww) handleCensorComment(w http.ResponseWriter, r *http.Request
 
 // handleCommentsGet handles batched comments get.
 func (p *politeiawww) handleCommentsGet(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleCommentsGet")
+	requestLog(r).Tracef("handleCommentsGet")
+
+	var gc v1.GetComments
+	err := util.ParseGetParams(r, &gc)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleCommentsGet: ParseGetParams %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
 
 	pathParams := mux.Vars(r)
-	token := pathParams["token"]
+	gc.Token = pathParams["token"]
 
 	user, err := p.getSessionUser(w, r)
 	if err != nil {
-		if err != ErrSessionUUIDNotFound {
+		if err != ErrSessionUUIDNotFound && err != ErrSessionExpired {
 			RespondWithError(w, r, 0,
 				"handleCommentsGet: getSessionUser %v", err)
 			return
 		}
 	}
-	gcr, err := p.ProcessCommentsGet(token, user)
+	gcr, err := p.ProcessCommentsGet(gc, user)
 	if err != nil {
 		RespondWithError(w, r, 0,
 			"handleCommentsGet: ProcessCommentsGet %v", err)
@@ -806,17 +1027,50 @@ func (p *politeiawww) handleCommentsGet(w http.ResponseWriter, r *http.Request)
 	util.RespondWithJSON(w, http.StatusOK, gcr)
 }
 
-// handleUserProposalCredits returns the spent and unspent proposal credits for
-// the logged in user.
-func (p *politeiawww) handleUserProposalCredits(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleUserProposalCredits")
+// handleCommentCount handles fetching the comment count of a proposal.
+func (p *politeiawww) handleCommentCount(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleCommentCount")
 
-	user, err := p.getSessionUser(w, r)
+	pathParams := mux.Vars(r)
+	ccr, err := p.processCommentCount(pathParams["token"])
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleCommentCount: processCommentCount %v", err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, ccr)
+}
+
+// handleBatchCommentCounts handles fetching the comment counts of multiple
+// proposals.
+func (p *politeiawww) handleBatchCommentCounts(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleBatchCommentCounts")
+
+	var bcc v1.BatchCommentCounts
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&bcc); err != nil {
+		RespondWithError(w, r, 0, "handleBatchCommentCounts: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	bccr, err := p.processBatchCommentCounts(bcc)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleUserProposalCredits: getSessionUser %v", err)
+			"handleBatchCommentCounts: processBatchCommentCounts %v", err)
 		return
 	}
+	util.RespondWithJSON(w, http.StatusOK, bccr)
+}
+
+// handleUserProposalCredits returns the spent and unspent proposal credits for
+// the logged in user.
+func (p *politeiawww) handleUserProposalCredits(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUserProposalCredits")
+
+	user := getContextUser(r)
 
 	reply, err := ProcessUserProposalCredits(user)
 	if err != nil {
@@ -830,13 +1084,13 @@ func (p *politeiawww) handleUserProposalCredits(w http.ResponseWriter, r *http.R
 
 // handleUserProposals returns the proposals for the given user.
 func (p *politeiawww) handleUserProposals(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleUserProposals")
+	requestLog(r).Tracef("handleUserProposals")
 
 	// Get the user proposals command.
 	var up v1.UserProposals
 	err := util.ParseGetParams(r, &up)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleUserProposals: ParseGetParams",
+		RespondWithError(w, r, 0, "handleUserProposals: ParseGetParams %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -845,7 +1099,7 @@ func (p *politeiawww) handleUserProposals(w http.ResponseWriter, r *http.Request
 
 	userId, err := uuid.Parse(up.UserId)
 	if err != nil {
-		RespondWithError(w, r, 0, "handleUserProposals: ParseUint",
+		RespondWithError(w, r, 0, "handleUserProposals: uuid.Parse %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
@@ -855,7 +1109,8 @@ func (p *politeiawww) handleUserProposals(w http.ResponseWriter, r *http.Request
 	user, err := p.getSessionUser(w, r)
 	if err != nil {
 		// since having a logged in user isn't required, simply log the error
-		log.Infof("handleUserProposals: could not get session user %v", err)
+		requestLog(r).Infof("handleUserProposals: could not get "+
+			"session user %v", err)
 	}
 
 	upr, err := p.ProcessUserProposals(
@@ -873,7 +1128,7 @@ func (p *politeiawww) handleUserProposals(w http.ResponseWriter, r *http.Request
 
 // handleActiveVote returns all active proposals that have an active vote.
 func (p *politeiawww) handleActiveVote(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleActiveVote")
+	requestLog(r).Tracef("handleActiveVote")
 
 	avr, err := p.ProcessActiveVote()
 	if err != nil {
@@ -887,12 +1142,12 @@ func (p *politeiawww) handleActiveVote(w http.ResponseWriter, r *http.Request) {
 
 // handleCastVotes records the user votes in politeiad.
 func (p *politeiawww) handleCastVotes(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleCastVotes")
+	requestLog(r).Tracef("handleCastVotes")
 
 	var cv v1.Ballot
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&cv); err != nil {
-		RespondWithError(w, r, 0, "handleCastVotes: unmarshal", v1.UserError{
+		RespondWithError(w, r, 0, "handleCastVotes: unmarshal %v: %v", err, v1.UserError{
 			ErrorCode: v1.ErrorStatusInvalidInput,
 		})
 		return
@@ -910,7 +1165,7 @@ func (p *politeiawww) handleCastVotes(w http.ResponseWriter, r *http.Request) {
 
 // handleVoteResults returns a proposal + all voting action.
 func (p *politeiawww) handleVoteResults(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleVoteResults")
+	requestLog(r).Tracef("handleVoteResults")
 
 	pathParams := mux.Vars(r)
 	token := pathParams["token"]
@@ -928,25 +1183,20 @@ func (p *politeiawww) handleVoteResults(w http.ResponseWriter, r *http.Request)
 
 // handleAuthorizeVote handles authorizing a proposal vote.
 func (p *politeiawww) handleAuthorizeVote(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleAuthorizeVote")
+	requestLog(r).Tracef("handleAuthorizeVote")
 	var av v1.AuthorizeVote
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&av); err != nil {
-		RespondWithError(w, r, 0, "handleAuthorizeVote: unmarshal", v1.UserError{
+		RespondWithError(w, r, 0, "handleAuthorizeVote: unmarshal %v: %v", err, v1.UserError{
 			ErrorCode: v1.ErrorStatusInvalidInput,
 		})
 		return
 	}
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleStartVote: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 	avr, err := p.ProcessAuthorizeVote(av, user)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleStartVote: ProcessAuthorizeVote %v", err)
+			"handleAuthorizeVote: ProcessAuthorizeVote %v", err)
 		return
 	}
 	util.RespondWithJSON(w, http.StatusOK, avr)
@@ -954,30 +1204,18 @@ func (p *politeiawww) handleAuthorizeVote(w http.ResponseWriter, r *http.Request
 
 // handleStartVote handles starting a vote.
 func (p *politeiawww) handleStartVote(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleStartVote")
+	requestLog(r).Tracef("handleStartVote")
 
 	var sv v1.StartVote
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&sv); err != nil {
-		RespondWithError(w, r, 0, "handleStartVote: unmarshal", v1.UserError{
+		RespondWithError(w, r, 0, "handleStartVote: unmarshal %v: %v", err, v1.UserError{
 			ErrorCode: v1.ErrorStatusInvalidInput,
 		})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleStartVote: getSessionUser %v", err)
-		return
-	}
-
-	// Sanity
-	if !user.Admin {
-		RespondWithError(w, r, 0,
-			"handleStartVote: admin %v", user.Admin)
-		return
-	}
+	user := getContextUser(r)
 
 	svr, err := p.ProcessStartVote(sv, user)
 	if err != nil {
@@ -994,7 +1232,8 @@ func (p *politeiawww) handleGetAllVoteStatus(w http.ResponseWriter, r *http.Requ
 	gasvr, err := p.ProcessGetAllVoteStatus()
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleProposalsVotingStatus: ProcessProposalsVotingStatus %v", err)
+			"handleGetAllVoteStatus: ProcessGetAllVoteStatus %v", err)
+		return
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, gasvr)
@@ -1006,7 +1245,7 @@ func (p *politeiawww) handleVoteStatus(w http.ResponseWriter, r *http.Request) {
 	vsr, err := p.ProcessVoteStatus(pathParams["token"])
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleCommentsGet: ProcessCommentGet %v", err)
+			"handleVoteStatus: ProcessVoteStatus %v", err)
 		return
 	}
 	util.RespondWithJSON(w, http.StatusOK, vsr)
@@ -1014,26 +1253,74 @@ func (p *politeiawww) handleVoteStatus(w http.ResponseWriter, r *http.Request) {
 
 // handleUserCommentsLikes returns the user votes on comments of a given proposal.
 func (p *politeiawww) handleUserCommentsLikes(w http.ResponseWriter, r *http.Request) {
-	log.Tracef("handleUserCommentsLikes")
+	requestLog(r).Tracef("handleUserCommentsLikes")
 
 	pathParams := mux.Vars(r)
 	token := pathParams["token"]
 
-	user, err := p.getSessionUser(w, r)
+	user := getContextUser(r)
+
+	uclr, err := p.ProcessUserCommentsLikes(user, token)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleUserCommentsLikes: getSessionUser %v", err)
+			"handleUserCommentsLikes: processUserCommentsLikes %v", err)
 		return
 	}
 
-	uclr, err := p.ProcessUserCommentsLikes(user, token)
+	util.RespondWithJSON(w, http.StatusOK, uclr)
+}
+
+// handleUserComments returns a page of the comments that a user has made.
+func (p *politeiawww) handleUserComments(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUserComments")
+
+	var uc v1.UserComments
+	err := util.ParseGetParams(r, &uc)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleUserComments: ParseGetParams %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	ucr, err := p.ProcessUserComments(uc, user)
 	if err != nil {
 		RespondWithError(w, r, 0,
-			"handleUserCommentsLikes: processUserCommentsLikes %v", err)
+			"handleUserComments: ProcessUserComments %v", err)
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, uclr)
+	util.RespondWithJSON(w, http.StatusOK, ucr)
+}
+
+// handleUserCommentedProposals returns the proposals that a user has
+// commented on.
+func (p *politeiawww) handleUserCommentedProposals(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUserCommentedProposals")
+
+	var ucp v1.UserCommentedProposals
+	err := util.ParseGetParams(r, &ucp)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleUserCommentedProposals: "+
+			"ParseGetParams %v: %v", err, v1.UserError{
+			ErrorCode: v1.ErrorStatusInvalidInput,
+		})
+		return
+	}
+
+	user := getContextUser(r)
+
+	ucpr, err := p.ProcessUserCommentedProposals(ucp, user)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleUserCommentedProposals: "+
+			"ProcessUserCommentedProposals %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ucpr)
 }
 
 // handleEditProposal attempts to edit a proposal
@@ -1041,21 +1328,16 @@ func (p *politeiawww) handleEditProposal(w http.ResponseWriter, r *http.Request)
 	var ep v1.EditProposal
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&ep); err != nil {
-		RespondWithError(w, r, 0, "handleEditProposal: unmarshal",
+		RespondWithError(w, r, 0, "handleEditProposal: unmarshal %v: %v", err,
 			v1.UserError{
 				ErrorCode: v1.ErrorStatusInvalidInput,
 			})
 		return
 	}
 
-	user, err := p.getSessionUser(w, r)
-	if err != nil {
-		RespondWithError(w, r, 0,
-			"handleEditProposal: getSessionUser %v", err)
-		return
-	}
+	user := getContextUser(r)
 
-	log.Debugf("handleEditProposal: %v", ep.Token)
+	requestLog(r).Debugf("handleEditProposal: %v", ep.Token)
 
 	epr, err := p.ProcessEditProposal(ep, user)
 	if err != nil {
@@ -1078,17 +1360,392 @@ func (p *politeiawww) handleProposalsStats(w http.ResponseWriter, r *http.Reques
 	util.RespondWithJSON(w, http.StatusOK, psr)
 }
 
-// handleNotFound is a generic handler for an invalid route.
-func (p *politeiawww) handleNotFound(w http.ResponseWriter, r *http.Request) {
-	// Log incoming connection
-	log.Debugf("Invalid route: %v %v %v %v", remoteAddr(r), r.Method, r.URL,
-		r.Proto)
+// handleStatsHistory returns the proposal and user activity stats of a time
+// range bucketed by interval.
+func (p *politeiawww) handleStatsHistory(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleStatsHistory")
 
-	// Trace incoming request
-	log.Tracef("%v", newLogClosure(func() string {
-		trace, err := httputil.DumpRequest(r, true)
-		if err != nil {
-			trace = []byte(fmt.Sprintf("logging: "+
+	var sh v1.StatsHistory
+	err := util.ParseGetParams(r, &sh)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleStatsHistory: ParseGetParams %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	shr, err := p.ProcessStatsHistory(sh)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleStatsHistory: ProcessStatsHistory %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, shr)
+}
+
+// handleWebhooks handles the incoming webhooks command.  It returns all of the
+// registered webhooks.
+func (p *politeiawww) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleWebhooks")
+
+	wr, err := p.processWebhooks()
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleWebhooks: processWebhooks %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, wr)
+}
+
+// handleNewWebhook handles the incoming new webhook command.  It registers a
+// webhook that is notified of proposal lifecycle events.
+func (p *politeiawww) handleNewWebhook(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleNewWebhook")
+
+	var nw v1.NewWebhook
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&nw); err != nil {
+		RespondWithError(w, r, 0, "handleNewWebhook: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	nwr, err := p.processNewWebhook(nw, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNewWebhook: processNewWebhook %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, nwr)
+}
+
+// handleDeleteWebhook handles the incoming delete webhook command.
+func (p *politeiawww) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleDeleteWebhook")
+
+	var dw v1.DeleteWebhook
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&dw); err != nil {
+		RespondWithError(w, r, 0, "handleDeleteWebhook: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	dwr, err := p.processDeleteWebhook(dw, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleDeleteWebhook: processDeleteWebhook %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, dwr)
+}
+
+// handleNewDraft handles the incoming new draft command.  It saves a proposal
+// draft for the logged in user.
+func (p *politeiawww) handleNewDraft(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleNewDraft")
+
+	var nd v1.NewDraft
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&nd); err != nil {
+		RespondWithError(w, r, 0, "handleNewDraft: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	ndr, err := p.processNewDraft(nd, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNewDraft: processNewDraft %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ndr)
+}
+
+// handleEditDraft handles the incoming edit draft command.  It replaces the
+// files of a draft of the logged in user.
+func (p *politeiawww) handleEditDraft(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleEditDraft")
+
+	var ed v1.EditDraft
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ed); err != nil {
+		RespondWithError(w, r, 0, "handleEditDraft: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	edr, err := p.processEditDraft(ed, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleEditDraft: processEditDraft %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, edr)
+}
+
+// handleDeleteDraft handles the incoming delete draft command.
+func (p *politeiawww) handleDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleDeleteDraft")
+
+	var dd v1.DeleteDraft
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&dd); err != nil {
+		RespondWithError(w, r, 0, "handleDeleteDraft: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	ddr, err := p.processDeleteDraft(dd, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleDeleteDraft: processDeleteDraft %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ddr)
+}
+
+// handleDraftDetails handles the incoming draft details command.  It returns
+// a draft of the logged in user along with its files.
+func (p *politeiawww) handleDraftDetails(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleDraftDetails")
+
+	// Drafts are private to their author, including from an admin
+	// that is impersonating the author.
+	if p.isImpersonatedSession(r) {
+		util.RespondWithJSON(w, http.StatusForbidden, v1.ErrorReply{
+			ErrorCode: int64(v1.ErrorStatusImpersonatedSession),
+		})
+		return
+	}
+
+	pathParams := mux.Vars(r)
+	dd := v1.DraftDetails{
+		DraftID: pathParams["draftid"],
+	}
+
+	user := getContextUser(r)
+
+	ddr, err := p.processDraftDetails(dd, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleDraftDetails: processDraftDetails %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ddr)
+}
+
+// handleNewUpload handles the incoming new upload command.  It stages a
+// proposal file that is uploaded in chunks.
+func (p *politeiawww) handleNewUpload(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleNewUpload")
+
+	var nu v1.NewUpload
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&nu); err != nil {
+		RespondWithError(w, r, 0, "handleNewUpload: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	nur, err := p.processNewUpload(nu, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNewUpload: processNewUpload %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, nur)
+}
+
+// handleUploadChunk handles the incoming upload chunk command.
+func (p *politeiawww) handleUploadChunk(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUploadChunk")
+
+	var uc v1.UploadChunk
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&uc); err != nil {
+		RespondWithError(w, r, 0, "handleUploadChunk: unmarshal %v: %v", err,
+			v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	user := getContextUser(r)
+
+	ucr, err := p.processUploadChunk(uc, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleUploadChunk: processUploadChunk %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ucr)
+}
+
+// handleUploadStatus handles the incoming upload status command.  It returns
+// the progress of a staged upload of the logged in user.
+func (p *politeiawww) handleUploadStatus(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUploadStatus")
+
+	pathParams := mux.Vars(r)
+	us := v1.UploadStatus{
+		UploadID: pathParams["uploadid"],
+	}
+
+	user := getContextUser(r)
+
+	usr, err := p.processUploadStatus(us, user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleUploadStatus: processUploadStatus %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, usr)
+}
+
+// handleUserDrafts handles the incoming user drafts command.  It returns the
+// drafts of the logged in user.
+func (p *politeiawww) handleUserDrafts(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleUserDrafts")
+
+	// Drafts are private to their author, including from an admin
+	// that is impersonating the author.
+	if p.isImpersonatedSession(r) {
+		util.RespondWithJSON(w, http.StatusForbidden, v1.ErrorReply{
+			ErrorCode: int64(v1.ErrorStatusImpersonatedSession),
+		})
+		return
+	}
+
+	user := getContextUser(r)
+
+	udr, err := p.processUserDrafts(user)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleUserDrafts: processUserDrafts %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, udr)
+}
+
+// handleProposalBilling handles the incoming proposal billing command.  It
+// returns the approved budget of a proposal and the spends recorded against
+// it.
+func (p *politeiawww) handleProposalBilling(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleProposalBilling")
+
+	pathParams := mux.Vars(r)
+	pbr, err := p.processProposalBilling(pathParams["token"])
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleProposalBilling: processProposalBilling %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, pbr)
+}
+
+// handleSetProposalBudget handles the incoming set proposal budget command.
+func (p *politeiawww) handleSetProposalBudget(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleSetProposalBudget")
+
+	var spb v1.SetProposalBudget
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&spb); err != nil {
+		RespondWithError(w, r, 0, "handleSetProposalBudget: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	spbr, err := p.processSetProposalBudget(spb, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSetProposalBudget: processSetProposalBudget %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, spbr)
+}
+
+// handleRecordProposalSpend handles the incoming record proposal spend
+// command.
+func (p *politeiawww) handleRecordProposalSpend(w http.ResponseWriter, r *http.Request) {
+	requestLog(r).Tracef("handleRecordProposalSpend")
+
+	var rps v1.RecordProposalSpend
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rps); err != nil {
+		RespondWithError(w, r, 0, "handleRecordProposalSpend: unmarshal %v: %v",
+			err, v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser := getContextUser(r)
+
+	rpsr, err := p.processRecordProposalSpend(rps, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRecordProposalSpend: processRecordProposalSpend %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rpsr)
+}
+
+// handleNotFound is a generic handler for an invalid route.
+func (p *politeiawww) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	// Log incoming connection
+	requestLog(r).Debugf("Invalid route: %v %v %v %v", remoteAddr(r),
+		r.Method, r.URL, r.Proto)
+
+	// Trace incoming request
+	requestLog(r).Tracef("%v", newLogClosure(func() string {
+		trace, err := httputil.DumpRequest(r, true)
+		if err != nil {
+			trace = []byte(fmt.Sprintf("logging: "+
 				"DumpRequest %v", err))
 		}
 		return string(trace)
@@ -1102,6 +1759,12 @@ func (p *politeiawww) handleNotFound(w http.ResponseWriter, r *http.Request) {
 func (p *politeiawww) addRoute(method string, route string, handler http.HandlerFunc, perm permission) {
 	fullRoute := v1.PoliteiaWWWAPIRoute + route
 
+	// Validate the request body of routes with a known request type
+	// before it reaches the handler.
+	if rt, ok := requestTypes[route]; ok {
+		handler = validateBody(handler, rt)
+	}
+
 	switch perm {
 	case permissionAdmin:
 		handler = logging(p.isLoggedInAsAdmin(handler))
@@ -1111,9 +1774,19 @@ func (p *politeiawww) addRoute(method string, route string, handler http.Handler
 		handler = logging(handler)
 	}
 
+	// Limit the request body size before it is logged or decoded.
+	// Websockets are not limited since they never carry a body.
+	if method != "" {
+		handler = limitBody(handler, p.maxRequestSize(route))
+	}
+
 	// All handlers need to close the body
 	handler = closeBody(handler)
 
+	// Attach the request ID first so that it is included in all log
+	// lines of the request.
+	handler = withRequestID(handler)
+
 	if method == "" {
 		// Websocket
 		log.Tracef("Adding websocket: %v", fullRoute)
@@ -1123,6 +1796,22 @@ func (p *politeiawww) addRoute(method string, route string, handler http.Handler
 	}
 }
 
+// maxRequestSize returns the maximum request body size in bytes for the
+// passed in route.
+func (p *politeiawww) maxRequestSize(route string) int64 {
+	switch route {
+	case v1.RouteNewProposal, v1.RouteEditProposal,
+		v1.RouteNewDraft, v1.RouteEditDraft, v1.RouteUploadChunk:
+		return p.cfg.MaxProposalRequestSize
+	case v1.RouteNewComment:
+		return p.cfg.MaxCommentRequestSize
+	case v1.RouteCastVotes:
+		return p.cfg.MaxBallotRequestSize
+	default:
+		return p.cfg.MaxRequestSize
+	}
+}
+
 // makeRequest makes an http request to the method and route provided,
 // serializing the provided object as the request body.
 //
@@ -1177,6 +1866,45 @@ func (p *politeiawww) makeRequest(method string, route string, v interface{}) ([
 	return responseBody, nil
 }
 
+// shutdownServers gracefully shuts down the passed in servers.  The servers
+// stop accepting new connections immediately and in-flight requests are given
+// until the timeout expires to complete.  The connections of the requests that
+// are still active once the timeout expires are closed.  Hijacked
+// connections, such as websockets, are not waited for.
+func shutdownServers(servers []*http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mtx      sync.Mutex
+		firstErr error
+	)
+	for _, srv := range servers {
+		wg.Add(1)
+		go func(srv *http.Server) {
+			defer wg.Done()
+
+			err := srv.Shutdown(ctx)
+			if err == context.DeadlineExceeded {
+				log.Infof("Shutdown timeout expired, closing "+
+					"active connections: %v", srv.Addr)
+				err = srv.Close()
+			}
+			if err != nil {
+				mtx.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mtx.Unlock()
+			}
+		}(srv)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
 func _main() error {
 	// Load configuration and parse command line.  This function also
 	// initializes logging and configures it accordingly.
@@ -1232,15 +1960,26 @@ func _main() error {
 
 	// Setup application context.
 	p := &politeiawww{
-		cfg:       loadedCfg,
-		ws:        make(map[string]map[string]*wsContext),
-		templates: make(map[string]*template.Template),
+		cfg: loadedCfg,
+		ws:  make(map[string]map[string]*wsContext),
 
 		// XXX reevaluate where this goes
-		userPubkeys:     make(map[string]string),
-		userPaywallPool: make(map[uuid.UUID]paywallPoolMember),
-		commentScores:   make(map[string]int64),
-		params:          activeNetParams.Params,
+		userPubkeys:      make(map[string]string),
+		userPaywallPool:  make(map[uuid.UUID]paywallPoolMember),
+		commentScores:    make(map[string]int64),
+		commentReactions: make(map[string]map[string]uint64),
+		userSessions:     make(map[string]map[string]struct{}),
+		rescans:          make(map[string]*v1.UserPaymentsRescanStatusReply),
+		params:           activeNetParams.Params,
+
+		commentLimiter: newRateLimiter(loadedCfg.CommentRateLimit,
+			loadedCfg.CommentRateInterval),
+		inventory: newInventoryStream(),
+	}
+
+	p.trustedProxies, err = parseTrustedProxies(loadedCfg.TrustedProxies)
+	if err != nil {
+		return err
 	}
 
 	// Check if this command is being run to fetch the identity.
@@ -1312,15 +2051,60 @@ func _main() error {
 		return fmt.Errorf("initCommentScore: %v", err)
 	}
 
+	// Load webhook subscriptions
+	err = p.initWebhooks()
+	if err != nil {
+		return fmt.Errorf("initWebhooks: %v", err)
+	}
+
+	// Load API tokens
+	err = p.initAPITokens()
+	if err != nil {
+		return fmt.Errorf("initAPITokens: %v", err)
+	}
+
+	// Remove stale staged uploads
+	err = p.initUploads()
+	if err != nil {
+		return fmt.Errorf("initUploads: %v", err)
+	}
+
+	// Load email templates
+	err = p.initEmailTemplates()
+	if err != nil {
+		return fmt.Errorf("initEmailTemplates: %v", err)
+	}
+
+	// Load banned passwords
+	err = p.initPasswordPolicy()
+	if err != nil {
+		return fmt.Errorf("initPasswordPolicy: %v", err)
+	}
+
+	// Load proposal billing records
+	err = p.initBilling()
+	if err != nil {
+		return fmt.Errorf("initBilling: %v", err)
+	}
+
 	// Setup events
 	p.initEventManager()
 
+	// Set up the code that fires the vote finished events.
+	err = p.initVoteFinished()
+	if err != nil {
+		return fmt.Errorf("initVoteFinished: %v", err)
+	}
+
 	// Set up the code that checks for paywall payments.
 	err = p.initPaywallChecker()
 	if err != nil {
 		return err
 	}
 
+	// Set up the code that purges unverified users.
+	p.initUserPurge()
+
 	// Load or create new CSRF key
 	log.Infof("Load CSRF key")
 	csrfKeyFilename := filepath.Join(p.cfg.DataDir, "csrf.key")
@@ -1388,7 +2172,7 @@ func _main() error {
 		}
 		log.Infof("Cookie key generated.")
 	}
-	sessionsDir := filepath.Join(p.cfg.DataDir, "sessions")
+	sessionsDir := p.sessionsDir()
 	err = os.MkdirAll(sessionsDir, 0700)
 	if err != nil {
 		return err
@@ -1396,40 +2180,47 @@ func _main() error {
 	p.store = sessions.NewFilesystemStore(sessionsDir, cookieKey)
 	p.store.Options = &sessions.Options{
 		Path:     "/",
-		MaxAge:   sessionMaxAge,
+		MaxAge:   int(p.cfg.SessionMaxAge),
 		Secure:   true,
 		HttpOnly: true,
 		SameSite: http.SameSiteStrictMode,
 	}
 
+	// Index the existing sessions by user id
+	err = p.initUserSessions()
+	if err != nil {
+		return err
+	}
+
 	// Bind to a port and pass our router in
-	listenC := make(chan error)
+	listenC := make(chan error, len(loadedCfg.Listeners))
+	servers := make([]*http.Server, 0, len(loadedCfg.Listeners))
 	for _, listener := range loadedCfg.Listeners {
-		listen := listener
-		go func() {
-			cfg := &tls.Config{
-				MinVersion: tls.VersionTLS12,
-				CurvePreferences: []tls.CurveID{
-					tls.CurveP256, // BLAME CHROME, NOT ME!
-					tls.CurveP521,
-					tls.X25519},
-				PreferServerCipherSuites: true,
-				CipherSuites: []uint16{
-					tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
-					tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
-					tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
-					tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
-				},
-			}
-			srv := &http.Server{
-				Handler:   csrfHandle(p.router),
-				Addr:      listen,
-				TLSConfig: cfg,
-				TLSNextProto: make(map[string]func(*http.Server,
-					*tls.Conn, http.Handler)),
-			}
+		cfg := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			CurvePreferences: []tls.CurveID{
+				tls.CurveP256, // BLAME CHROME, NOT ME!
+				tls.CurveP521,
+				tls.X25519},
+			PreferServerCipherSuites: true,
+			CipherSuites: []uint16{
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+			},
+		}
+		srv := &http.Server{
+			Handler:   csrfExemptAPIToken(csrfHandle(p.router), p.router),
+			Addr:      listener,
+			TLSConfig: cfg,
+			TLSNextProto: make(map[string]func(*http.Server,
+				*tls.Conn, http.Handler)),
+		}
+		servers = append(servers, srv)
 
-			log.Infof("Listen: %v", listen)
+		go func() {
+			log.Infof("Listen: %v", srv.Addr)
 			listenC <- srv.ListenAndServeTLS(loadedCfg.HTTPSCert,
 				loadedCfg.HTTPSKey)
 		}()
@@ -1454,6 +2245,21 @@ func _main() error {
 	}
 done:
 
+	// Stop accepting new connections and wait for the in-flight
+	// requests to complete before closing the databases that they use.
+	log.Infof("Draining requests")
+	p.inventory.close()
+	err = shutdownServers(servers, p.cfg.ShutdownTimeout)
+	if err != nil {
+		log.Errorf("shutdownServers: %v", err)
+	}
+
+	p.cache.Close()
+	err = p.db.Close()
+	if err != nil {
+		log.Errorf("db close: %v", err)
+	}
+
 	log.Infof("Exiting")
 
 	return nil
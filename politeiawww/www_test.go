@@ -13,10 +13,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	v1 "github.com/decred/politeia/politeiawww/api/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/decred/slog"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
@@ -316,3 +322,622 @@ func TestHandleUserDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleMeProposalCredits(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// Give two users a different number of proposal credits
+	addCredits := func(usr *user.User, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			usr.UnspentProposalCredits = append(usr.UnspentProposalCredits,
+				user.ProposalCredit{
+					PaywallID: uint64(i),
+				})
+		}
+		err := p.db.UserUpdate(*usr)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	usr, _ := newUser(t, p, false)
+	addCredits(usr, 2)
+	other, _ := newUser(t, p, false)
+	addCredits(other, 5)
+
+	// Setup request from the first user's session
+	cookies := newSessionCookies(t, p, usr.ID.String())
+	r := httptest.NewRequest(http.MethodGet, v1.RouteUserMe, nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+
+	// Run test
+	p.isLoggedIn(p.handleMe)(w, r)
+	res := w.Result()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status code %v, want %v",
+			res.StatusCode, http.StatusOK)
+	}
+
+	var lr v1.LoginReply
+	err := json.NewDecoder(res.Body).Decode(&lr)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// The reply must contain the session user's balance
+	if lr.UserID != usr.ID.String() {
+		t.Errorf("got user id %v, want %v", lr.UserID, usr.ID.String())
+	}
+	if lr.ProposalCredits != 2 {
+		t.Errorf("got %v proposal credits, want 2", lr.ProposalCredits)
+	}
+}
+
+func TestRespondWithError(t *testing.T) {
+	// Setup tests
+	var tests = []struct {
+		name        string
+		format      string
+		args        []interface{}
+		wantStatus  int
+		wantCode    int64 // Zero means a tracking code is expected
+		wantMessage string
+	}{
+		{"user error", "handleTest: processTest %v",
+			[]interface{}{v1.UserError{
+				ErrorCode: v1.ErrorStatusProposalNotFound,
+			}},
+			http.StatusBadRequest,
+			int64(v1.ErrorStatusProposalNotFound),
+			v1.ErrorStatus[v1.ErrorStatusProposalNotFound]},
+		{"user error after decode error", "handleTest: unmarshal %v: %v",
+			[]interface{}{fmt.Errorf("unexpected EOF"), v1.UserError{
+				ErrorCode: v1.ErrorStatusInvalidInput,
+			}},
+			http.StatusBadRequest,
+			int64(v1.ErrorStatusInvalidInput),
+			v1.ErrorStatus[v1.ErrorStatusInvalidInput]},
+		{"internal error", "handleTest: processTest %v",
+			[]interface{}{fmt.Errorf("secret internal detail")},
+			http.StatusInternalServerError, 0, v1.ErrorMessageInternal},
+		{"no error", "handleTest: no error", nil,
+			http.StatusInternalServerError, 0, v1.ErrorMessageInternal},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			RespondWithError(w, r, 0, v.format, v.args...)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+
+			if res.StatusCode != v.wantStatus {
+				t.Errorf("got status code %v, want %v",
+					res.StatusCode, v.wantStatus)
+			}
+			if bytes.Contains(body, []byte("secret")) {
+				t.Errorf("internal error detail returned to client: %s",
+					body)
+			}
+
+			var er v1.ErrorReply
+			err := json.Unmarshal(body, &er)
+			if err != nil {
+				t.Fatalf("unmarshal ErrorReply: %v", err)
+			}
+			if er.ErrorMessage != v.wantMessage {
+				t.Errorf("got error message %q, want %q",
+					er.ErrorMessage, v.wantMessage)
+			}
+			switch {
+			case v.wantCode != 0 && er.ErrorCode != v.wantCode:
+				t.Errorf("got error code %v, want %v",
+					er.ErrorCode, v.wantCode)
+			case v.wantCode == 0 && er.ErrorCode == 0:
+				t.Errorf("got no error tracking code")
+			}
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	const maxBytes = 16
+	handler := limitBody(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		w.Write(body)
+	}, maxBytes)
+
+	// Setup tests
+	var tests = []struct {
+		name       string
+		body       []byte
+		wantStatus int
+	}{
+		{"empty body", nil, http.StatusOK},
+		{"max size", bytes.Repeat([]byte("a"), maxBytes), http.StatusOK},
+		{"too large", bytes.Repeat([]byte("a"), maxBytes+1),
+			http.StatusBadRequest},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/",
+				bytes.NewReader(v.body))
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+
+			if res.StatusCode != v.wantStatus {
+				t.Fatalf("got status code %v, want %v",
+					res.StatusCode, v.wantStatus)
+			}
+			if res.StatusCode == http.StatusOK {
+				if !bytes.Equal(body, v.body) {
+					t.Errorf("got body %q, want %q", body, v.body)
+				}
+				return
+			}
+
+			var er v1.ErrorReply
+			err := json.Unmarshal(body, &er)
+			if err != nil {
+				t.Fatalf("unmarshal ErrorReply: %v", err)
+			}
+			if er.ErrorCode != int64(v1.ErrorStatusInvalidInput) {
+				t.Errorf("got error code %v, want %v",
+					er.ErrorCode, v1.ErrorStatusInvalidInput)
+			}
+			if len(er.ErrorContext) == 0 {
+				t.Errorf("got no error context")
+			}
+		})
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	handler := validateBody(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		w.Write(body)
+	}, requestTypes[v1.RouteLogin])
+
+	// Setup tests
+	var tests = []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   v1.ErrorStatusT
+		wantErrors int
+	}{
+		{"valid", `{"email":"user@example.com","password":"password"}`,
+			http.StatusOK, 0, 0},
+		{"unknown field",
+			`{"email":"user@example.com","password":"password","x":1}`,
+			http.StatusBadRequest, v1.ErrorStatusInvalidInput, 1},
+		{"missing fields", `{"email":" "}`, http.StatusBadRequest,
+			v1.ErrorStatusInvalidEmailOrPassword, 2},
+		{"malformed", `{"email":`, http.StatusBadRequest,
+			v1.ErrorStatusInvalidInput, 1},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/",
+				bytes.NewReader([]byte(v.body)))
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+
+			if res.StatusCode != v.wantStatus {
+				t.Fatalf("got status code %v, want %v",
+					res.StatusCode, v.wantStatus)
+			}
+			if res.StatusCode == http.StatusOK {
+				if string(body) != v.body {
+					t.Errorf("got body %q, want %q", body, v.body)
+				}
+				return
+			}
+
+			var er v1.ErrorReply
+			err := json.Unmarshal(body, &er)
+			if err != nil {
+				t.Fatalf("unmarshal ErrorReply: %v", err)
+			}
+			if er.ErrorCode != int64(v.wantCode) {
+				t.Errorf("got error code %v, want %v",
+					er.ErrorCode, v.wantCode)
+			}
+			if len(er.ErrorContext) != v.wantErrors {
+				t.Errorf("got error context %v, want %v errors",
+					er.ErrorContext, v.wantErrors)
+			}
+		})
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	// The handler replies with the request id of the context
+	handler := withRequestID(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(requestID(r)))
+	})
+
+	// Setup tests
+	var tests = []struct {
+		name      string
+		requestID string
+		want      string // Empty means a generated ID
+	}{
+		{"client id", "abc-123_DEF", "abc-123_DEF"},
+		{"no id", "", ""},
+		{"invalid characters", "abc\ndef", ""},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), ""},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if v.requestID != "" {
+				r.Header.Set(v1.RequestID, v.requestID)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+
+			got := res.Header.Get(v1.RequestID)
+			if got != string(body) {
+				t.Fatalf("got response header %q, context id %q",
+					got, body)
+			}
+			switch {
+			case v.want != "" && got != v.want:
+				t.Fatalf("got request id %q, want %q", got, v.want)
+			case v.want == "" && (got == v.requestID ||
+				!isValidRequestID(got)):
+				t.Fatalf("got request id %q, want a generated id", got)
+			}
+		})
+	}
+}
+
+func TestRequestLog(t *testing.T) {
+	// Log to a buffer for the duration of the test
+	var buf bytes.Buffer
+	backend := slog.NewBackend(&buf)
+	logger := backend.Logger("TEST")
+	logger.SetLevel(slog.LevelTrace)
+	defer func(l slog.Logger) { log = l }(log)
+	log = logger
+
+	handler := withRequestID(func(w http.ResponseWriter, r *http.Request) {
+		requestLog(r).Infof("hello %v", 1)
+		requestLog(r).Errorf("world")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(v1.RequestID, "abc")
+	handler(httptest.NewRecorder(), r)
+
+	for _, want := range []string{"abc hello 1", "abc world"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q does not contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestRoutesRequestID(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	// Every route, including the ones that are not registered through
+	// addRoute, must reply with the request ID
+	for _, route := range []string{"/", v1.PoliteiaWWWAPIRoute +
+		v1.RouteVersion} {
+		r := httptest.NewRequest(http.MethodGet, route, nil)
+		r.Header.Set(v1.RequestID, "abc")
+		w := httptest.NewRecorder()
+		p.router.ServeHTTP(w, r)
+
+		got := w.Result().Header.Get(v1.RequestID)
+		if got != "abc" {
+			t.Errorf("route %v: got request id %q, want %q", route,
+				got, "abc")
+		}
+	}
+}
+
+func TestWithSessionUser(t *testing.T) {
+	p := newTestPoliteiawww(t)
+	defer cleanupTestPoliteiawww(t, p)
+
+	usr, _ := newUser(t, p, false)
+	admin, _ := newUser(t, p, true)
+
+	// A session of a user that is not in the database
+	unknown := &user.User{ID: uuid.New()}
+
+	// The handler replies with the id of the context user
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		util.RespondWithJSON(w, http.StatusOK, getContextUser(r).ID)
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name       string
+		user       *user.User // Nil means not logged in
+		admin      bool
+		wantStatus int
+		wantCode   v1.ErrorStatusT
+	}{
+		{"not logged in", nil, false, http.StatusUnauthorized,
+			v1.ErrorStatusNotLoggedIn},
+		{"user not found", unknown, false, http.StatusUnauthorized,
+			v1.ErrorStatusSessionExpired},
+		{"logged in", usr, false, http.StatusOK, 0},
+		{"not an admin", usr, true, http.StatusForbidden, 0},
+		{"admin", admin, true, http.StatusOK, 0},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, v1.RouteUserMe, nil)
+			if v.user != nil {
+				cookies := newSessionCookies(t, p, v.user.ID.String())
+				for _, c := range cookies {
+					r.AddCookie(c)
+				}
+			}
+			w := httptest.NewRecorder()
+			p.withSessionUser(handler, v.admin)(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+
+			if res.StatusCode != v.wantStatus {
+				t.Fatalf("got status code %v, want %v",
+					res.StatusCode, v.wantStatus)
+			}
+			switch res.StatusCode {
+			case http.StatusOK:
+				var id string
+				err := json.Unmarshal(body, &id)
+				if err != nil {
+					t.Fatalf("unmarshal user id: %v", err)
+				}
+				if id != v.user.ID.String() {
+					t.Errorf("got context user %v, want %v",
+						id, v.user.ID)
+				}
+			case http.StatusUnauthorized:
+				var er v1.ErrorReply
+				err := json.Unmarshal(body, &er)
+				if err != nil {
+					t.Fatalf("unmarshal ErrorReply: %v", err)
+				}
+				if er.ErrorCode != int64(v.wantCode) {
+					t.Errorf("got error code %v, want %v",
+						er.ErrorCode, v.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestShutdownServers(t *testing.T) {
+	// newBlockingServer returns a started server whose handler signals
+	// that a request has started and then blocks until release is
+	// closed.
+	newBlockingServer := func(started chan<- struct{}, release <-chan struct{}) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				started <- struct{}{}
+				<-release
+				w.WriteHeader(http.StatusOK)
+			}))
+	}
+
+	t.Run("drain", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		s := newBlockingServer(started, release)
+
+		reqErr := make(chan error, 1)
+		go func() {
+			resp, err := http.Get(s.URL)
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					err = fmt.Errorf("got status %v", resp.StatusCode)
+				}
+			}
+			reqErr <- err
+		}()
+		<-started
+
+		shutdownErr := make(chan error, 1)
+		go func() {
+			shutdownErr <- shutdownServers([]*http.Server{s.Config},
+				5*time.Second)
+		}()
+
+		// Shutdown waits for the in-flight request
+		select {
+		case err := <-shutdownErr:
+			t.Fatalf("shutdown returned before the request completed: %v",
+				err)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		// New connections are refused while draining
+		_, err := http.Get(s.URL)
+		if err == nil {
+			t.Fatalf("got nil error, want connection error")
+		}
+
+		close(release)
+		err = <-reqErr
+		if err != nil {
+			t.Fatalf("in-flight request: %v", err)
+		}
+		err = <-shutdownErr
+		if err != nil {
+			t.Fatalf("shutdownServers: %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		defer close(release)
+		s := newBlockingServer(started, release)
+
+		reqErr := make(chan error, 1)
+		go func() {
+			resp, err := http.Get(s.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			reqErr <- err
+		}()
+		<-started
+
+		err := shutdownServers([]*http.Server{s.Config},
+			50*time.Millisecond)
+		if err != nil {
+			t.Fatalf("shutdownServers: %v", err)
+		}
+
+		// The connection of the request is closed once the timeout
+		// expires.
+		err = <-reqErr
+		if err == nil {
+			t.Fatalf("got nil error, want closed connection error")
+		}
+	})
+}
+
+func TestRateLimitClient(t *testing.T) {
+	trusted, err := parseTrustedProxies([]string{"10.0.0.1", "10.1.0.0/16"})
+	if err != nil {
+		t.Fatalf("parseTrustedProxies: %v", err)
+	}
+
+	var tests = []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		want       string
+	}{
+		{"no header", "10.2.0.1:1000", "", "10.2.0.1"},
+		{"untrusted proxy", "10.2.0.1:1000", "1.1.1.1", "10.2.0.1"},
+		{"trusted proxy", "10.0.0.1:1000", "1.1.1.1", "1.1.1.1"},
+		{"trusted proxy without header", "10.0.0.1:1000", "", "10.0.0.1"},
+		{"forged address", "10.0.0.1:1000", "2.2.2.2, 1.1.1.1", "1.1.1.1"},
+		{"chained proxies", "10.0.0.1:1000", "2.2.2.2, 1.1.1.1, 10.1.2.3",
+			"1.1.1.1"},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = v.remoteAddr
+			if v.xff != "" {
+				r.Header.Set(v1.Forward, v.xff)
+			}
+			got := rateLimitClient(r, trusted)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+
+	// Invalid proxies are rejected
+	_, err = parseTrustedProxies([]string{"proxy"})
+	if err == nil {
+		t.Fatalf("got nil error for invalid proxy")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Now()
+	rl := newRateLimiter(2, time.Minute)
+	rl.now = func() time.Time {
+		return now
+	}
+	handler := rateLimit(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, rl, nil)
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	// The limit is tracked per client
+	for i := 0; i < 2; i++ {
+		w := request("10.0.0.1:1000")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %v: got status %v, want %v", i, w.Code,
+				http.StatusOK)
+		}
+	}
+	w := request("10.0.0.1:2000")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %v, want %v", w.Code,
+			http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Fatalf("got Retry-After %v, want 60", got)
+	}
+	var er v1.ErrorReply
+	err := json.NewDecoder(w.Body).Decode(&er)
+	if err != nil {
+		t.Fatalf("decode ErrorReply: %v", err)
+	}
+	if er.ErrorCode != int64(v1.ErrorStatusRateLimitExceeded) {
+		t.Fatalf("got error code %v, want %v", er.ErrorCode,
+			v1.ErrorStatusRateLimitExceeded)
+	}
+	w = request("10.0.0.2:1000")
+	if w.Code != http.StatusOK {
+		t.Fatalf("other client: got status %v, want %v", w.Code,
+			http.StatusOK)
+	}
+
+	// The limit is reset once the window ends
+	now = now.Add(time.Minute)
+	w = request("10.0.0.1:1000")
+	if w.Code != http.StatusOK {
+		t.Fatalf("next window: got status %v, want %v", w.Code,
+			http.StatusOK)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writefileatomic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fp := filepath.Join(dir, "data.json")
+	for _, data := range []string{"old", "new"} {
+		err = WriteFileAtomic(fp, []byte(data), 0600)
+		if err != nil {
+			t.Fatalf("WriteFileAtomic: %v", err)
+		}
+		b, err := ioutil.ReadFile(fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != data {
+			t.Fatalf("got %q, want %q", b, data)
+		}
+	}
+
+	fi, err := os.Stat(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("got mode %v, want 0600", fi.Mode().Perm())
+	}
+
+	// No temporary files are left behind
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 1 {
+		t.Fatalf("got %v files, want 1", len(fis))
+	}
+
+	// Writing to a missing directory fails without creating the file
+	err = WriteFileAtomic(filepath.Join(dir, "missing", "data.json"),
+		[]byte("data"), 0600)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
@@ -5,10 +5,13 @@
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"github.com/gorilla/schema"
 	"io"
 	"net/http"
+	"strings"
 )
 
 func RespondWithError(w http.ResponseWriter, code int, message string) {
@@ -23,6 +26,40 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
+// RespondWithJSONETag responds with the JSON encoded payload and an ETag that
+// is derived from it.  If the If-None-Match header of the request matches the
+// ETag, a 304 Not Modified without a body is returned instead so that clients
+// can reuse their cached copy of the payload.
+func RespondWithJSONETag(w http.ResponseWriter, r *http.Request, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	digest := sha256.Sum256(response)
+	etag := `"` + hex.EncodeToString(digest[:]) + `"`
+	w.Header().Set("ETag", etag)
+	if ETagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// ETagMatch returns whether the passed in If-None-Match header value matches
+// the ETag.  The header may contain a comma separated list of ETags or "*".
+// Weak ETags are compared using the weak comparison.
+func ETagMatch(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func RespondWithCopy(w http.ResponseWriter, code int, contentType string, body []byte) error {
 	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(code)
@@ -0,0 +1,80 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/decred/politeia/util"
+)
+
+func TestETagMatch(t *testing.T) {
+	testCases := []struct {
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{"", `"a"`, false},
+		{`"a"`, `"a"`, true},
+		{`"b"`, `"a"`, false},
+		{`"b", "a"`, `"a"`, true},
+		{`W/"a"`, `"a"`, true},
+		{`"a"`, `W/"a"`, true},
+		{"*", `"a"`, true},
+	}
+
+	for _, tc := range testCases {
+		got := util.ETagMatch(tc.ifNoneMatch, tc.etag)
+		if got != tc.want {
+			t.Errorf("ETagMatch(%q, %q): got %v, want %v",
+				tc.ifNoneMatch, tc.etag, got, tc.want)
+		}
+	}
+}
+
+func TestRespondWithJSONETag(t *testing.T) {
+	payload := map[string]string{"hello": "world"}
+
+	// The first request does not have an ETag
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	util.RespondWithJSONETag(w, r, payload)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %v, want %v", w.Code, http.StatusOK)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("ETag header not set")
+	}
+	if w.Body.Len() == 0 {
+		t.Fatalf("got empty body")
+	}
+
+	// The payload has not changed
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	util.RespondWithJSONETag(w, r, payload)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("got status %v, want %v", w.Code, http.StatusNotModified)
+	}
+	if w.Body.Len() != 0 {
+		t.Fatalf("got body %q, want empty body", w.Body.String())
+	}
+
+	// The payload has changed
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	util.RespondWithJSONETag(w, r, map[string]string{"hello": "there"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %v, want %v", w.Code, http.StatusOK)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Fatalf("ETag did not change")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// PasswordStrengthMax is the highest score that PasswordStrength returns.
+const PasswordStrengthMax = 4
+
+var (
+	// passwordStrengthBits contains the minimum number of bits of entropy
+	// of the scores returned by PasswordStrength.  The thresholds
+	// correspond to 10^3, 10^6, 10^8 and 10^10 guesses, like the scores
+	// of zxcvbn.
+	passwordStrengthBits = []float64{
+		math.Log2(1e3),
+		math.Log2(1e6),
+		math.Log2(1e8),
+		math.Log2(1e10),
+	}
+
+	// commonPasswords contains passwords and words that are among the
+	// first to be guessed.
+	commonPasswords = map[string]struct{}{
+		"password":   {},
+		"qwerty":     {},
+		"qwertyuiop": {},
+		"asdfgh":     {},
+		"letmein":    {},
+		"welcome":    {},
+		"admin":      {},
+		"login":      {},
+		"iloveyou":   {},
+		"monkey":     {},
+		"dragon":     {},
+		"master":     {},
+		"shadow":     {},
+		"sunshine":   {},
+		"princess":   {},
+		"football":   {},
+		"baseball":   {},
+		"superman":   {},
+		"starwars":   {},
+		"trustno":    {},
+		"freedom":    {},
+		"whatever":   {},
+		"secret":     {},
+		"hello":      {},
+		"abc":        {},
+		"decred":     {},
+		"politeia":   {},
+	}
+
+	// passwordSubstitutions replaces the digits and symbols that are
+	// commonly used in place of letters.
+	passwordSubstitutions = strings.NewReplacer("0", "o", "1", "i",
+		"3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+)
+
+// PasswordStrength estimates the strength of a password on a scale from 0,
+// too guessable, to PasswordStrengthMax, very unguessable.  A password that
+// consists of a common password followed by digits or symbols is estimated
+// as a guess from the common passwords plus a brute force search of the
+// suffix.  Other passwords are estimated as a brute force search over the
+// character classes that they use, where characters that repeat the previous
+// character or continue a sequence such as "abc" or "321" only count for a
+// single bit.
+func PasswordStrength(password string) int {
+	var bits float64
+	core := strings.TrimRightFunc(password, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	word := passwordSubstitutions.Replace(strings.ToLower(core))
+	if _, ok := commonPasswords[word]; ok {
+		// One bit for the capitalization and substitutions
+		bits = math.Log2(float64(len(commonPasswords))) + 1 +
+			bruteForceBits(password[len(core):])
+	} else {
+		bits = bruteForceBits(password)
+	}
+
+	score := 0
+	for _, v := range passwordStrengthBits {
+		if bits < v {
+			break
+		}
+		score++
+	}
+	return score
+}
+
+// bruteForceBits returns the number of bits of entropy of a brute force search
+// for the passed in password.
+func bruteForceBits(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	var charset int
+	for _, v := range []struct {
+		used bool
+		size int
+	}{
+		{lower, 26},
+		{upper, 26},
+		{digit, 10},
+		{symbol, 33},
+		{other, 100},
+	} {
+		if v.used {
+			charset += v.size
+		}
+	}
+
+	var (
+		bits  float64
+		prev  rune
+		delta rune
+	)
+	for i, r := range password {
+		d := r - prev
+		seq := (d == 1 || d == -1) && (delta == d ||
+			(delta != 1 && delta != -1))
+		if i > 0 && (d == 0 || seq) {
+			bits++
+		} else {
+			bits += math.Log2(float64(charset))
+		}
+		if i > 0 {
+			delta = d
+		}
+		prev = r
+	}
+	return bits
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/decred/politeia/util"
+)
+
+func TestPasswordStrength(t *testing.T) {
+	testCases := []struct {
+		password string
+		want     int
+	}{
+		{"", 0},
+		{"password", 0},
+		{"p@ssw0rd", 0},
+		{"Password1!", 1},
+		{"12345678", 1},
+		{"aaaaaaaa", 1},
+		{"Tr0ub4dor&3", util.PasswordStrengthMax},
+		{"correct horse battery staple", util.PasswordStrengthMax},
+	}
+
+	for _, tc := range testCases {
+		got := util.PasswordStrength(tc.password)
+		if got != tc.want {
+			t.Errorf("PasswordStrength(%q): got %v, want %v",
+				tc.password, got, tc.want)
+		}
+	}
+}
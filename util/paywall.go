@@ -550,8 +550,13 @@ func FetchTxsForAddressNotBefore(address string, notBefore int64) ([]TxDetails,
 			return nil, fmt.Errorf("fetchDcrdataAddress: %v", err)
 		}
 
+		// An empty page means that there are no more txs
+		if len(dcrdataTxs) == 0 {
+			break
+		}
+
 		// Convert transactions to TxDetails
-		txs := make([]TxDetails, len(dcrdataTxs))
+		txs := make([]TxDetails, 0, len(dcrdataTxs))
 		for _, tx := range dcrdataTxs {
 			txDetails, err := convertBEPrimaryTransactionToTxDetails(address, tx)
 			if err != nil {